@@ -72,6 +72,7 @@ type ServiceInterface interface {
 	RollBack(w http.ResponseWriter, r *http.Request)
 	AddVolume(w http.ResponseWriter, r *http.Request)
 	UpdVolume(w http.ResponseWriter, r *http.Request)
+	ResizeVolume(w http.ResponseWriter, r *http.Request)
 	DeleteVolume(w http.ResponseWriter, r *http.Request)
 	Pods(w http.ResponseWriter, r *http.Request)
 	VolumeDependency(w http.ResponseWriter, r *http.Request)
@@ -83,6 +84,10 @@ type ServiceInterface interface {
 	GetDeployVersion(w http.ResponseWriter, r *http.Request)
 	AutoscalerRules(w http.ResponseWriter, r *http.Request)
 	ScalingRecords(w http.ResponseWriter, r *http.Request)
+	ScalingSchedules(w http.ResponseWriter, r *http.Request)
+	ScalingSchedule(w http.ResponseWriter, r *http.Request)
+	ResourceRecommendation(w http.ResponseWriter, r *http.Request)
+	ResourcePolicy(w http.ResponseWriter, r *http.Request)
 	AddServiceMonitors(w http.ResponseWriter, r *http.Request)
 	DeleteServiceMonitors(w http.ResponseWriter, r *http.Request)
 	UpdateServiceMonitors(w http.ResponseWriter, r *http.Request)
@@ -204,4 +209,16 @@ type AppRestoreInterface interface {
 // PodInterface defines api methods about k8s pods.
 type PodInterface interface {
 	PodDetail(w http.ResponseWriter, r *http.Request)
+	TerminalTicket(w http.ResponseWriter, r *http.Request)
+	TerminalSessions(w http.ResponseWriter, r *http.Request)
+	TerminalSession(w http.ResponseWriter, r *http.Request)
+	TerminalSessionCast(w http.ResponseWriter, r *http.Request)
+}
+
+// WebhookInterface defines api methods about git push webhooks of components.
+type WebhookInterface interface {
+	GetWebhook(w http.ResponseWriter, r *http.Request)
+	UpdateWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	TriggerWebhook(w http.ResponseWriter, r *http.Request)
 }
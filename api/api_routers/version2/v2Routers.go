@@ -47,6 +47,8 @@ func (v2 *V2) Routes() chi.Router {
 	r.Mount("/app", v2.appRouter())
 	r.Get("/health", controller.GetManager().Health)
 	r.Post("/alertmanager-webhook", controller.GetManager().AlertManagerWebHook)
+	// git push webhooks, authenticated by the signature
+	r.Post("/webhooks/{service_id}", controller.GetManager().TriggerWebhook)
 	r.Get("/version", controller.GetManager().Version)
 	// deprecated use /gateway/ports
 	r.Mount("/port", v2.portRouter())
@@ -151,6 +153,11 @@ func (v2 *V2) tenantNameRouter() chi.Router {
 	r.Delete("/groupapp/backups/{backup_id}", controller.DeleteBackup)
 	r.Post("/groupapp/backups/{backup_id}/restore", controller.Restore)
 	r.Get("/groupapp/backups/{backup_id}/restore/{restore_id}", controller.RestoreResult)
+	r.Get("/groupapp/backup-schedules", controller.BackupSchedules)
+	r.Post("/groupapp/backup-schedules", controller.NewBackupSchedule)
+	r.Get("/groupapp/backup-schedules/{schedule_id}", controller.GetBackupSchedule)
+	r.Put("/groupapp/backup-schedules/{schedule_id}", controller.UpdateBackupSchedule)
+	r.Delete("/groupapp/backup-schedules/{schedule_id}", controller.DeleteBackupSchedule)
 	r.Post("/deployversions", controller.GetManager().GetManyDeployVersion)
 	//团队资源限制
 	r.Post("/limit_memory", controller.GetManager().LimitTenantMemory)
@@ -167,6 +174,7 @@ func (v2 *V2) tenantNameRouter() chi.Router {
 
 	//batch operation
 	r.Post("/batchoperation", controller.BatchOperation)
+	r.Get("/orchestrations/{event_id}", controller.GetOrchestration)
 
 	return r
 }
@@ -240,6 +248,7 @@ func (v2 *V2) serviceRouter() chi.Router {
 	r.Put("/volumes", middleware.WrapEL(controller.GetManager().UpdVolume, dbmodel.TargetTypeService, "update-service-volume", dbmodel.SYNEVENTTYPE))
 	r.Get("/volumes", controller.GetVolume)
 	r.Delete("/volumes/{volume_name}", middleware.WrapEL(controller.DeleteVolume, dbmodel.TargetTypeService, "delete-service-volume", dbmodel.SYNEVENTTYPE))
+	r.Put("/volumes/{volume_name}/capacity", middleware.WrapEL(controller.GetManager().ResizeVolume, dbmodel.TargetTypeService, "resize-service-volume", dbmodel.ASYNEVENTTYPE))
 	r.Post("/depvolumes", middleware.WrapEL(controller.AddVolumeDependency, dbmodel.TargetTypeService, "add-service-depvolume", dbmodel.SYNEVENTTYPE))
 	r.Delete("/depvolumes", middleware.WrapEL(controller.DeleteVolumeDependency, dbmodel.TargetTypeService, "delete-service-depvolume", dbmodel.SYNEVENTTYPE))
 	r.Get("/depvolumes", controller.GetDepVolume)
@@ -287,11 +296,29 @@ func (v2 *V2) serviceRouter() chi.Router {
 	r.Post("/app-restore/plugins", middleware.WrapEL(controller.GetManager().RestorePlugins, dbmodel.TargetTypeService, "app-restore-plugins", dbmodel.SYNEVENTTYPE))
 
 	r.Get("/pods/{pod_name}/detail", controller.GetManager().PodDetail)
+	r.Post("/pods/{pod_name}/terminal-ticket", controller.GetManager().TerminalTicket)
+	r.Get("/terminal-sessions", controller.GetManager().TerminalSessions)
+	r.Get("/terminal-sessions/{session_id}", controller.GetManager().TerminalSession)
+	r.Get("/terminal-sessions/{session_id}/cast", controller.GetManager().TerminalSessionCast)
+
+	// git push webhook
+	r.Get("/webhook", controller.GetManager().GetWebhook)
+	r.Put("/webhook", controller.GetManager().UpdateWebhook)
+	r.Delete("/webhook", controller.GetManager().DeleteWebhook)
 
 	// autoscaler
 	r.Post("/xparules", middleware.WrapEL(controller.GetManager().AutoscalerRules, dbmodel.TargetTypeService, "add-app-autoscaler-rule", dbmodel.SYNEVENTTYPE))
 	r.Put("/xparules", middleware.WrapEL(controller.GetManager().AutoscalerRules, dbmodel.TargetTypeService, "update-app-autoscaler-rule", dbmodel.SYNEVENTTYPE))
 	r.Get("/xparecords", controller.GetManager().ScalingRecords)
+	r.Get("/xpaschedules", controller.GetManager().ScalingSchedules)
+	r.Post("/xpaschedules", middleware.WrapEL(controller.GetManager().ScalingSchedules, dbmodel.TargetTypeService, "add-app-scaling-schedule", dbmodel.SYNEVENTTYPE))
+	r.Put("/xpaschedules/{schedule_id}", middleware.WrapEL(controller.GetManager().ScalingSchedule, dbmodel.TargetTypeService, "update-app-scaling-schedule", dbmodel.SYNEVENTTYPE))
+	r.Delete("/xpaschedules/{schedule_id}", middleware.WrapEL(controller.GetManager().ScalingSchedule, dbmodel.TargetTypeService, "delete-app-scaling-schedule", dbmodel.SYNEVENTTYPE))
+
+	// resource recommendation
+	r.Get("/resource-recommendation", controller.GetManager().ResourceRecommendation)
+	r.Get("/resource-policy", controller.GetManager().ResourcePolicy)
+	r.Put("/resource-policy", middleware.WrapEL(controller.GetManager().ResourcePolicy, dbmodel.TargetTypeService, "update-app-resource-policy", dbmodel.SYNEVENTTYPE))
 
 	//service monitor
 	r.Post("/service-monitors", middleware.WrapEL(controller.GetManager().AddServiceMonitors, dbmodel.TargetTypeService, "add-app-service-monitor", dbmodel.SYNEVENTTYPE))
@@ -22,12 +22,14 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/go-chi/chi"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 
 	"github.com/goodrain/rainbond/api/handler"
 	"github.com/goodrain/rainbond/api/middleware"
 	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
 	"github.com/goodrain/rainbond/db/errors"
 	httputil "github.com/goodrain/rainbond/util/http"
 )
@@ -56,6 +58,10 @@ func (t *TenantStruct) addAutoscalerRule(w http.ResponseWriter, r *http.Request)
 			httputil.ReturnError(r, w, 400, err.Error())
 			return
 		}
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
 		logrus.Errorf("add autoscaler rule: %v", err)
 		httputil.ReturnError(r, w, 500, err.Error())
 		return
@@ -76,6 +82,10 @@ func (t *TenantStruct) updAutoscalerRule(w http.ResponseWriter, r *http.Request)
 			httputil.ReturnError(r, w, 400, err.Error())
 			return
 		}
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
 		if err == gorm.ErrRecordNotFound {
 			httputil.ReturnError(r, w, 404, err.Error())
 			return
@@ -128,3 +138,106 @@ func (t *TenantStruct) listScalingRecords(w http.ResponseWriter, r *http.Request
 		"data":  records,
 	})
 }
+
+// ScalingSchedules -
+func (t *TenantStruct) ScalingSchedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		t.listScalingSchedules(w, r)
+	case "POST":
+		t.addScalingSchedule(w, r)
+	}
+}
+
+func (t *TenantStruct) listScalingSchedules(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	schedules, err := handler.GetServiceManager().ListScalingSchedules(serviceID)
+	if err != nil {
+		logrus.Errorf("list scaling schedules: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, schedules)
+}
+
+func (t *TenantStruct) addScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.ScalingScheduleReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil)
+	if !ok {
+		return
+	}
+
+	req.ServiceID = r.Context().Value(middleware.ContextKey("service_id")).(string)
+	schedule, err := handler.GetServiceManager().AddScalingSchedule(&req)
+	if err != nil {
+		if err == errors.ErrRecordAlreadyExist {
+			httputil.ReturnError(r, w, 400, err.Error())
+			return
+		}
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
+		logrus.Errorf("add scaling schedule: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+
+	httputil.ReturnSuccess(r, w, schedule)
+}
+
+// ScalingSchedule -
+func (t *TenantStruct) ScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PUT":
+		t.updScalingSchedule(w, r)
+	case "DELETE":
+		t.delScalingSchedule(w, r)
+	}
+}
+
+func (t *TenantStruct) updScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.ScalingScheduleReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil)
+	if !ok {
+		return
+	}
+
+	req.ServiceID = r.Context().Value(middleware.ContextKey("service_id")).(string)
+	req.ScheduleID = chi.URLParam(r, "schedule_id")
+	schedule, err := handler.GetServiceManager().UpdScalingSchedule(&req)
+	if err != nil {
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			httputil.ReturnError(r, w, 404, err.Error())
+			return
+		}
+		logrus.Errorf("update scaling schedule: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+
+	httputil.ReturnSuccess(r, w, schedule)
+}
+
+func (t *TenantStruct) delScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	if err := handler.GetServiceManager().DelScalingSchedule(serviceID, chi.URLParam(r, "schedule_id")); err != nil {
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			httputil.ReturnError(r, w, 404, err.Error())
+			return
+		}
+		logrus.Errorf("delete scaling schedule: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+
+	httputil.ReturnSuccess(r, w, nil)
+}
@@ -22,7 +22,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/goodrain/rainbond/api/handler"
 	"github.com/goodrain/rainbond/api/middleware"
 	"github.com/goodrain/rainbond/api/model"
@@ -33,6 +35,7 @@ import (
 
 //BatchOperation batch operation for tenant
 //support operation is : start,build,stop,update
+//start and upgrade can be orchestrated in the order of the dependencies with orchestrate
 func BatchOperation(w http.ResponseWriter, r *http.Request) {
 	var build model.BatchOperationReq
 	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &build.Body, nil)
@@ -74,6 +77,23 @@ func BatchOperation(w http.ResponseWriter, r *http.Request) {
 	if len(batchOpReqs) > 1024 {
 		batchOpReqs = batchOpReqs[0:1024]
 	}
+	if build.Body.Orchestrate {
+		if build.Body.Operation != "start" && build.Body.Operation != "upgrade" {
+			httputil.ReturnError(r, w, 400, fmt.Sprintf("operation %s do not support orchestration", build.Body.Operation))
+			return
+		}
+		layerTimeout := time.Duration(build.Body.LayerTimeout) * time.Second
+		res, plan, err := handler.GetBatchOperationHandler().Orchestrate(r.Context(), tenant, build.Operator, build.Body.Operation, batchOpReqs, layerTimeout)
+		if err != nil {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
+		httputil.ReturnSuccess(r, w, map[string]interface{}{
+			"batch_result": res,
+			"plan":         plan,
+		})
+		return
+	}
 	res, err := f(r.Context(), tenant, build.Operator, batchOpReqs)
 	if err != nil {
 		httputil.ReturnBcodeError(r, w, err)
@@ -85,3 +105,15 @@ func BatchOperation(w http.ResponseWriter, r *http.Request) {
 		"batch_result": res,
 	})
 }
+
+//GetOrchestration get the plan of the orchestration with the statuses of the components
+func GetOrchestration(w http.ResponseWriter, r *http.Request) {
+	tenant := r.Context().Value(middleware.ContextKey("tenant")).(*dbmodel.Tenants)
+	eventID := chi.URLParam(r, "event_id")
+	plan, err := handler.GetBatchOperationHandler().GetOrchestration(tenant.UUID, eventID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, plan)
+}
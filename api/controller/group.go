@@ -130,3 +130,68 @@ func DeleteBackup(w http.ResponseWriter, r *http.Request) {
 	}
 	httputil.ReturnSuccess(r, w, nil)
 }
+
+//BackupSchedules list all backup schedules of group app
+func BackupSchedules(w http.ResponseWriter, r *http.Request) {
+	groupID := r.FormValue("group_id")
+	if groupID == "" {
+		httputil.ReturnError(r, w, 400, "group id can not be empty")
+		return
+	}
+	list, err := handler.GetAPPBackupHandler().ListBackupSchedules(groupID)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, list)
+}
+
+//NewBackupSchedule create group app backup schedule
+func NewBackupSchedule(w http.ResponseWriter, r *http.Request) {
+	var bs group.BackupSchedule
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &bs.Body, nil)
+	if !ok {
+		return
+	}
+	bean, err := handler.GetAPPBackupHandler().CreateBackupSchedule(bs)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
+
+//GetBackupSchedule get group app backup schedule
+func GetBackupSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID := chi.URLParam(r, "schedule_id")
+	bean, err := handler.GetAPPBackupHandler().GetBackupSchedule(scheduleID)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
+
+//UpdateBackupSchedule update group app backup schedule
+func UpdateBackupSchedule(w http.ResponseWriter, r *http.Request) {
+	var bs group.BackupSchedule
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &bs.Body, nil)
+	if !ok {
+		return
+	}
+	bean, err := handler.GetAPPBackupHandler().UpdateBackupSchedule(chi.URLParam(r, "schedule_id"), bs)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
+
+//DeleteBackupSchedule delete group app backup schedule
+func DeleteBackupSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := handler.GetAPPBackupHandler().DeleteBackupSchedule(chi.URLParam(r, "schedule_id")); err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
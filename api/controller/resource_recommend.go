@@ -0,0 +1,89 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/goodrain/rainbond/api/handler"
+	"github.com/goodrain/rainbond/api/middleware"
+	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	httputil "github.com/goodrain/rainbond/util/http"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// ResourceRecommendation returns the cpu and memory recommendations of component
+func (t *TenantStruct) ResourceRecommendation(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	rec, err := handler.GetServiceManager().RecommendResources(serviceID)
+	if err != nil {
+		logrus.Errorf("recommend resources of component %s: %v", serviceID, err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, rec)
+}
+
+// ResourcePolicy -
+func (t *TenantStruct) ResourcePolicy(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		t.getResourcePolicy(w, r)
+	case "PUT":
+		t.updResourcePolicy(w, r)
+	}
+}
+
+func (t *TenantStruct) getResourcePolicy(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	policy, err := handler.GetServiceManager().GetResourcePolicy(serviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			httputil.ReturnError(r, w, 404, err.Error())
+			return
+		}
+		logrus.Errorf("get resource policy: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, policy)
+}
+
+func (t *TenantStruct) updResourcePolicy(w http.ResponseWriter, r *http.Request) {
+	var req model.ResourcePolicyReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil)
+	if !ok {
+		return
+	}
+
+	req.ServiceID = r.Context().Value(middleware.ContextKey("service_id")).(string)
+	policy, err := handler.GetServiceManager().UpdResourcePolicy(&req)
+	if err != nil {
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
+		logrus.Errorf("update resource policy: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, policy)
+}
@@ -59,6 +59,7 @@ type V2Routes struct {
 	AppRestoreController
 	PodController
 	ApplicationController
+	WebhookController
 }
 
 //Show test
@@ -0,0 +1,89 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/goodrain/rainbond/api/handler"
+	"github.com/goodrain/rainbond/api/middleware"
+	"github.com/goodrain/rainbond/api/model"
+	httputil "github.com/goodrain/rainbond/util/http"
+	"github.com/sirupsen/logrus"
+)
+
+// TerminalTicket issues a short-lived ticket to open the terminal of the pod by webcli.
+func (p *PodController) TerminalTicket(w http.ResponseWriter, r *http.Request) {
+	var req model.TerminalTicketReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenantID := r.Context().Value(middleware.ContextKey("tenant_id")).(string)
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	ticket, err := handler.GetTerminalHandler().CreateTicket(tenantID, serviceID, chi.URLParam(r, "pod_name"), &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, ticket)
+}
+
+// TerminalSessions lists the terminal session records of the component.
+func (p *PodController) TerminalSessions(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(middleware.ContextKey("tenant_id")).(string)
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	sessions, err := handler.GetTerminalHandler().ListSessions(tenantID, serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, sessions)
+}
+
+// TerminalSession returns the metadata of the terminal session record.
+func (p *PodController) TerminalSession(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(middleware.ContextKey("tenant_id")).(string)
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	session, err := handler.GetTerminalHandler().GetSession(tenantID, serviceID, chi.URLParam(r, "session_id"))
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, session)
+}
+
+// TerminalSessionCast returns the asciicast v2 file of the terminal session record, it can be replayed by asciinema player.
+func (p *PodController) TerminalSessionCast(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(middleware.ContextKey("tenant_id")).(string)
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	sessionID := chi.URLParam(r, "session_id")
+	file, err := handler.GetTerminalHandler().OpenSessionCast(tenantID, serviceID, sessionID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	defer file.Close()
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", "attachment; filename="+sessionID+".cast")
+	if _, err := io.Copy(w, file); err != nil {
+		logrus.Warningf("write terminal session cast failure %s", err.Error())
+	}
+}
@@ -193,6 +193,24 @@ func (t *TenantStruct) UpdVolume(w http.ResponseWriter, r *http.Request) {
 	httputil.ReturnSuccess(r, w, "success")
 }
 
+// ResizeVolume expands the capacity of a component volume online.
+func (t *TenantStruct) ResizeVolume(w http.ResponseWriter, r *http.Request) {
+	var req api_model.ResizeVolumeReq
+	if ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil); !ok {
+		return
+	}
+
+	tenantID := r.Context().Value(middleware.ContextKey("tenant_id")).(string)
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	sEvent := r.Context().Value(middleware.ContextKey("event")).(*dbmodel.ServiceEvent)
+	volumeName := chi.URLParam(r, "volume_name")
+	if err := handler.GetServiceManager().ResizeVolume(tenantID, serviceID, volumeName, sEvent.EventID, req.VolumeCapacity); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, sEvent)
+}
+
 //DeleteVolume DeleteVolume
 func (t *TenantStruct) DeleteVolume(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation DELETE /v2/tenants/{tenant_name}/services/{service_alias}/volume v2 deleteVolume
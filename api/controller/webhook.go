@@ -0,0 +1,89 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/goodrain/rainbond/api/handler"
+	"github.com/goodrain/rainbond/api/middleware"
+	"github.com/goodrain/rainbond/api/model"
+	httputil "github.com/goodrain/rainbond/util/http"
+)
+
+//maxWebhookBodySize the max size of webhook payload
+const maxWebhookBodySize = 10 << 20
+
+// WebhookController is an implementation of WebhookInterface
+type WebhookController struct{}
+
+// GetWebhook returns the git push webhook of the component.
+func (c *WebhookController) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	res, err := handler.GetWebhookHandler().GetWebhook(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, res)
+}
+
+// UpdateWebhook creates or updates the git push webhook of the component.
+func (c *WebhookController) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req model.WebhookReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenantID := r.Context().Value(middleware.ContextKey("tenant_id")).(string)
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	res, err := handler.GetWebhookHandler().UpdateWebhook(tenantID, serviceID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, res)
+}
+
+// DeleteWebhook deletes the git push webhook of the component.
+func (c *WebhookController) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	if err := handler.GetWebhookHandler().DeleteWebhook(serviceID); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// TriggerWebhook receives the push events of GitHub, GitLab, Gitea and Bitbucket.
+// The request is authenticated by the signature of the webhook secret.
+func (c *WebhookController) TriggerWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		httputil.ReturnError(r, w, http.StatusBadRequest, "read webhook payload failure")
+		return
+	}
+	res, err := handler.GetWebhookHandler().TriggerWebhook(chi.URLParam(r, "service_id"), r.Header, body)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, res)
+}
@@ -47,9 +47,12 @@ type ConDB struct {
 //TODO: need to try when happened error, try 4 times
 func CreateDBManager(conf option.Config) error {
 	dbCfg := config.Config{
-		MysqlConnectionInfo: conf.DBConnectionInfo,
-		DBType:              conf.DBType,
-		ShowSQL:             conf.ShowSQL,
+		MysqlConnectionInfo:    conf.DBConnectionInfo,
+		DBType:                 conf.DBType,
+		ShowSQL:                conf.ShowSQL,
+		MasterKeyFile:          conf.DBMasterKeyFile,
+		PreviousMasterKeyFiles: conf.DBPreviousMasterKeyFiles,
+		KMSEndpoint:            conf.DBKMSEndpoint,
 	}
 	if err := db.CreateManager(dbCfg); err != nil {
 		logrus.Errorf("get db manager failed,%s", err.Error())
@@ -52,7 +52,9 @@ func (a *AppAction) Complete(tr *model.ExportAppStruct) error {
 		return err
 	}
 
-	if tr.Body.Format != "rainbond-app" && tr.Body.Format != "docker-compose" {
+	switch tr.Body.Format {
+	case "rainbond-app", "docker-compose", "helm-chart", "kubernetes":
+	default:
 		err := errors.New("Unsupported the format: " + tr.Body.Format)
 		logrus.Error(err)
 		return err
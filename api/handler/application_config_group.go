@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"path"
+	"strings"
+
 	"github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/api/util/bcode"
 	"github.com/goodrain/rainbond/db"
@@ -10,6 +13,23 @@ import (
 
 // AddConfigGroup -
 func (a *ApplicationAction) AddConfigGroup(appID string, req *model.ApplicationConfigGroup) (*model.ApplicationConfigGroupResp, error) {
+	if req.SourceType == "" {
+		req.SourceType = dbmodel.ConfigGroupSourceDB
+	}
+	config := &dbmodel.ApplicationConfigGroup{
+		AppID:           appID,
+		ConfigGroupName: req.ConfigGroupName,
+		DeployType:      req.DeployType,
+		Enable:          req.Enable,
+		SourceType:      req.SourceType,
+		SourcePath:      req.SourcePath,
+		RefreshInterval: req.RefreshInterval,
+		MountPath:       req.MountPath,
+		RestartOnChange: req.RestartOnChange,
+	}
+	if err := checkConfigGroup(config); err != nil {
+		return nil, err
+	}
 	services, err := db.GetManager().TenantServiceDao().GetServicesByServiceIDs(req.ServiceIDs)
 	if err != nil {
 		return nil, err
@@ -40,8 +60,11 @@ func (a *ApplicationAction) AddConfigGroup(appID string, req *model.ApplicationC
 		}
 	}
 
-	// Create application configGroup-configItem
+	// Create application configGroup-configItem, the items of external source are not stored
 	for _, it := range req.ConfigItems {
+		if config.SourceType != dbmodel.ConfigGroupSourceDB {
+			break
+		}
 		configItem := &dbmodel.ConfigGroupItem{
 			AppID:           appID,
 			ConfigGroupName: req.ConfigGroupName,
@@ -59,12 +82,6 @@ func (a *ApplicationAction) AddConfigGroup(appID string, req *model.ApplicationC
 	}
 
 	// Create application configGroup
-	config := &dbmodel.ApplicationConfigGroup{
-		AppID:           appID,
-		ConfigGroupName: req.ConfigGroupName,
-		DeployType:      req.DeployType,
-		Enable:          req.Enable,
-	}
 	if err := db.GetManager().AppConfigGroupDaoTransactions(tx).AddModel(config); err != nil {
 		tx.Rollback()
 		return nil, err
@@ -95,6 +112,11 @@ func (a *ApplicationAction) AddConfigGroup(appID string, req *model.ApplicationC
 		ConfigItems:     configGroupItems,
 		Services:        configGroupServices,
 		Enable:          appconfig.Enable,
+		SourceType:      appconfig.SourceType,
+		SourcePath:      appconfig.SourcePath,
+		RefreshInterval: appconfig.RefreshInterval,
+		MountPath:       appconfig.MountPath,
+		RestartOnChange: appconfig.RestartOnChange,
 	}
 	return resp, nil
 }
@@ -105,6 +127,14 @@ func (a *ApplicationAction) UpdateConfigGroup(appID, configGroupName string, req
 	if err != nil {
 		return nil, err
 	}
+	appconfig.Enable = req.Enable
+	appconfig.SourcePath = req.SourcePath
+	appconfig.RefreshInterval = req.RefreshInterval
+	appconfig.MountPath = req.MountPath
+	appconfig.RestartOnChange = req.RestartOnChange
+	if err := checkConfigGroup(appconfig); err != nil {
+		return nil, err
+	}
 	services, err := db.GetManager().TenantServiceDao().GetServicesByServiceIDs(req.ServiceIDs)
 	if err != nil {
 		return nil, err
@@ -117,8 +147,7 @@ func (a *ApplicationAction) UpdateConfigGroup(appID, configGroupName string, req
 			tx.Rollback()
 		}
 	}()
-	// Update effective status
-	appconfig.Enable = req.Enable
+	// Update effective status and the settings of source
 	if err := db.GetManager().AppConfigGroupDaoTransactions(tx).UpdateModel(appconfig); err != nil {
 		tx.Rollback()
 		return nil, err
@@ -151,6 +180,9 @@ func (a *ApplicationAction) UpdateConfigGroup(appID, configGroupName string, req
 		return nil, err
 	}
 	for _, it := range req.ConfigItems {
+		if appconfig.SourceType != "" && appconfig.SourceType != dbmodel.ConfigGroupSourceDB {
+			break
+		}
 		configItem := &dbmodel.ConfigGroupItem{
 			AppID:           appID,
 			ConfigGroupName: configGroupName,
@@ -189,6 +221,11 @@ func (a *ApplicationAction) UpdateConfigGroup(appID, configGroupName string, req
 		ConfigItems:     configGroupItems,
 		Services:        configGroupServices,
 		Enable:          appconfig.Enable,
+		SourceType:      appconfig.SourceType,
+		SourcePath:      appconfig.SourcePath,
+		RefreshInterval: appconfig.RefreshInterval,
+		MountPath:       appconfig.MountPath,
+		RestartOnChange: appconfig.RestartOnChange,
 	}
 	return resp, nil
 }
@@ -240,6 +277,11 @@ func (a *ApplicationAction) ListConfigGroups(appID string, page, pageSize int) (
 			ConfigGroupName: c.ConfigGroupName,
 			DeployType:      c.DeployType,
 			Enable:          c.Enable,
+			SourceType:      c.SourceType,
+			SourcePath:      c.SourcePath,
+			RefreshInterval: c.RefreshInterval,
+			MountPath:       c.MountPath,
+			RestartOnChange: c.RestartOnChange,
 		}
 
 		configGroupServices, err := db.GetManager().AppConfigGroupServiceDao().GetConfigGroupServicesByID(c.AppID, c.ConfigGroupName)
@@ -261,3 +303,23 @@ func (a *ApplicationAction) ListConfigGroups(appID string, page, pageSize int) (
 	resp.PageSize = pageSize
 	return &resp, nil
 }
+
+// checkConfigGroup checks the settings of the source and the deploy type
+func checkConfigGroup(config *dbmodel.ApplicationConfigGroup) error {
+	switch config.SourceType {
+	case "", dbmodel.ConfigGroupSourceDB:
+	case dbmodel.ConfigGroupSourceVault:
+		if i := strings.Index(strings.Trim(config.SourcePath, "/"), "/"); i <= 0 {
+			return bcode.NewBadRequest("source_path of vault should be <mount>/<secret path>")
+		}
+	default:
+		return bcode.NewBadRequest("unsupported source type " + config.SourceType)
+	}
+	if config.RefreshInterval < 0 {
+		return bcode.NewBadRequest("refresh_interval can not be negative")
+	}
+	if config.DeployType == dbmodel.ConfigGroupDeployTypeFile && !path.IsAbs(config.MountPath) {
+		return bcode.NewBadRequest("mount_path should be an absolute path if deploy type is file")
+	}
+	return nil
+}
@@ -308,3 +308,48 @@ func TestListConfigGroups(t *testing.T) {
 	}
 
 }
+
+func TestCheckConfigGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *dbmodel.ApplicationConfigGroup
+		wanterr bool
+	}{
+		{
+			name:   "env from db",
+			config: &dbmodel.ApplicationConfigGroup{DeployType: "env"},
+		},
+		{
+			name:   "file from vault",
+			config: &dbmodel.ApplicationConfigGroup{DeployType: "file", MountPath: "/etc/app", SourceType: "vault", SourcePath: "secret/app/db"},
+		},
+		{
+			name:    "vault path without mount",
+			config:  &dbmodel.ApplicationConfigGroup{DeployType: "env", SourceType: "vault", SourcePath: "app"},
+			wanterr: true,
+		},
+		{
+			name:    "unsupported source",
+			config:  &dbmodel.ApplicationConfigGroup{DeployType: "env", SourceType: "consul"},
+			wanterr: true,
+		},
+		{
+			name:    "file without mount path",
+			config:  &dbmodel.ApplicationConfigGroup{DeployType: "file"},
+			wanterr: true,
+		},
+		{
+			name:    "relative mount path",
+			config:  &dbmodel.ApplicationConfigGroup{DeployType: "file", MountPath: "etc/app"},
+			wanterr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkConfigGroup(tc.config)
+			if (err != nil) != tc.wanterr {
+				t.Errorf("expected error %v, but got %v", tc.wanterr, err)
+			}
+		})
+	}
+}
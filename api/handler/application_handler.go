@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/goodrain/rainbond/db"
 	dbmodel "github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/tracing"
 	"github.com/goodrain/rainbond/worker/client"
 	"github.com/goodrain/rainbond/worker/server/pb"
 	"github.com/sirupsen/logrus"
@@ -118,12 +120,51 @@ func (a *ApplicationAction) UpdateApp(srcApp *dbmodel.Application, req model.Upd
 		}
 		srcApp.GovernanceMode = req.GovernanceMode
 	}
+	if err := updateAppTracing(srcApp, req); err != nil {
+		return nil, err
+	}
 	if err := db.GetManager().ApplicationDao().UpdateModel(srcApp); err != nil {
 		return nil, err
 	}
 	return srcApp, nil
 }
 
+func updateAppTracing(app *dbmodel.Application, req model.UpdateAppRequest) error {
+	switch req.TracingProvider {
+	case "":
+	case "none":
+		app.TracingProvider = ""
+	default:
+		if !tracing.IsProviderValid(req.TracingProvider) {
+			return bcode.NewBadRequest(fmt.Sprintf("tracing provider '%s' is invalid", req.TracingProvider))
+		}
+		app.TracingProvider = req.TracingProvider
+	}
+	if req.TracingEndpoint != "" {
+		u, err := url.Parse(req.TracingEndpoint)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return bcode.NewBadRequest(fmt.Sprintf("tracing endpoint '%s' is invalid", req.TracingEndpoint))
+		}
+		app.TracingEndpoint = req.TracingEndpoint
+	}
+	if req.TracingSampleRate != nil {
+		if *req.TracingSampleRate < 0 || *req.TracingSampleRate > 100 {
+			return bcode.NewBadRequest("tracing sample rate should be between 0 and 100")
+		}
+		app.TracingSampleRate = *req.TracingSampleRate
+	}
+	if req.TracingOpenCensusPort != nil {
+		if *req.TracingOpenCensusPort <= 0 || *req.TracingOpenCensusPort > 65535 {
+			return bcode.NewBadRequest("tracing opencensus port should be between 1 and 65535")
+		}
+		app.TracingOpenCensusPort = *req.TracingOpenCensusPort
+	}
+	if app.TracingProvider != "" && app.TracingEndpoint == "" {
+		return bcode.NewBadRequest("tracing endpoint is required when the tracing is enabled")
+	}
+	return nil
+}
+
 // ListApps -
 func (a *ApplicationAction) ListApps(tenantID, appName string, page, pageSize int) (*model.ListAppResponse, error) {
 	var resp model.ListAppResponse
@@ -0,0 +1,113 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"fmt"
+	"regexp"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/worker/appm/conversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+//validateAutoscalerRule validates the rule and the metrics, the service monitors of component are required by
+//the pods and object metrics, which are scraped by the service monitor and served by the custom metrics api.
+func validateAutoscalerRule(serviceID string, req *api_model.AutoscalerRuleReq) error {
+	var monitors map[string]bool
+	for _, metric := range req.Metrics {
+		if metric.MetricsType != dbmodel.PodsMetrics && metric.MetricsType != dbmodel.ObjectMetrics {
+			continue
+		}
+		tsms, err := db.GetManager().TenantServiceMonitorDao().GetByServiceID(serviceID)
+		if err != nil {
+			return err
+		}
+		monitors = make(map[string]bool, len(tsms))
+		for _, tsm := range tsms {
+			monitors[tsm.Name] = true
+		}
+		break
+	}
+	return checkAutoscalerRule(req, monitors)
+}
+
+func checkAutoscalerRule(req *api_model.AutoscalerRuleReq, monitors map[string]bool) error {
+	if req.MaxReplicas <= 0 || req.MinReplicas > req.MaxReplicas {
+		return bcode.NewBadRequest("max_replicas must be greater than 0 and not less than min_replicas")
+	}
+	for _, metric := range req.Metrics {
+		if metric.MetricTargetValue <= 0 {
+			return bcode.NewBadRequest(fmt.Sprintf("the target value of metric %s must be greater than 0", metric.MetricsName))
+		}
+		targetTypes := []string{"value", "average_value"}
+		switch metric.MetricsType {
+		case dbmodel.ResourceMetrics:
+			if metric.MetricsName != "cpu" && metric.MetricsName != "memory" {
+				return bcode.NewBadRequest(fmt.Sprintf("unsupported resource metric %s", metric.MetricsName))
+			}
+			targetTypes = []string{"utilization", "average_value"}
+		case dbmodel.PodsMetrics, dbmodel.ObjectMetrics:
+			if len(monitors) == 0 {
+				return bcode.NewBadRequest(fmt.Sprintf("%s requires the service monitor of component", metric.MetricsType))
+			}
+			if metric.MetricsType == dbmodel.PodsMetrics {
+				targetTypes = []string{"average_value"}
+			} else if metric.MetricObject != "" && !monitors[metric.MetricObject] {
+				return bcode.NewBadRequest(fmt.Sprintf("service monitor %s not found", metric.MetricObject))
+			} else if metric.MetricObject == "" && len(monitors) > 1 {
+				return bcode.NewBadRequest("metric_object is required when the component has multiple service monitors")
+			}
+		case dbmodel.ExternalMetrics:
+		case dbmodel.GatewayMetrics:
+			if _, ok := conversion.GatewayExternalMetrics[metric.MetricsName]; !ok {
+				return bcode.NewBadRequest(fmt.Sprintf("unsupported gateway metric %s", metric.MetricsName))
+			}
+		default:
+			return bcode.NewBadRequest(fmt.Sprintf("unsupported metric type %s", metric.MetricsType))
+		}
+		if metric.MetricsType != dbmodel.ResourceMetrics && metric.MetricsType != dbmodel.GatewayMetrics &&
+			!metricNameRegexp.MatchString(metric.MetricsName) {
+			return bcode.NewBadRequest(fmt.Sprintf("invalid metric name %s", metric.MetricsName))
+		}
+		if metric.MetricSelector != "" {
+			if _, err := metav1.ParseToLabelSelector(metric.MetricSelector); err != nil {
+				return bcode.NewBadRequest(fmt.Sprintf("invalid metric selector %s: %v", metric.MetricSelector, err))
+			}
+		}
+		if !containsString(targetTypes, metric.MetricTargetType) {
+			return bcode.NewBadRequest(fmt.Sprintf("the target type of %s %s must be one of %v", metric.MetricsType, metric.MetricsName, targetTypes))
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+)
+
+func TestCheckAutoscalerRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  string
+		monitors map[string]bool
+		wantErr  bool
+	}{
+		{
+			name:    "resource metric",
+			metrics: `[{"metric_type":"resource_metrics","metric_name":"cpu","metric_target_type":"utilization","metric_target_value":50}]`,
+		},
+		{
+			name:    "resource metric with value target",
+			metrics: `[{"metric_type":"resource_metrics","metric_name":"cpu","metric_target_type":"value","metric_target_value":50}]`,
+			wantErr: true,
+		},
+		{
+			name:     "pods metric",
+			metrics:  `[{"metric_type":"pods_metrics","metric_name":"http_requests_per_second","metric_target_type":"average_value","metric_target_value":100}]`,
+			monitors: map[string]bool{"web": true},
+		},
+		{
+			name:    "pods metric without service monitor",
+			metrics: `[{"metric_type":"pods_metrics","metric_name":"http_requests_per_second","metric_target_type":"average_value","metric_target_value":100}]`,
+			wantErr: true,
+		},
+		{
+			name:     "object metric of unknown service monitor",
+			metrics:  `[{"metric_type":"object_metrics","metric_name":"queue_length","metric_object":"db","metric_target_type":"value","metric_target_value":10}]`,
+			monitors: map[string]bool{"web": true},
+			wantErr:  true,
+		},
+		{
+			name:     "object metric without service monitor name",
+			metrics:  `[{"metric_type":"object_metrics","metric_name":"queue_length","metric_target_type":"value","metric_target_value":10}]`,
+			monitors: map[string]bool{"web": true, "admin": true},
+			wantErr:  true,
+		},
+		{
+			name:    "external metric",
+			metrics: `[{"metric_type":"external_metrics","metric_name":"rabbitmq_queue_messages","metric_selector":"queue=orders","metric_target_type":"average_value","metric_target_value":30}]`,
+		},
+		{
+			name:    "external metric with invalid name",
+			metrics: `[{"metric_type":"external_metrics","metric_name":"queue-messages","metric_target_type":"average_value","metric_target_value":30}]`,
+			wantErr: true,
+		},
+		{
+			name:    "gateway metric",
+			metrics: `[{"metric_type":"gateway_metrics","metric_name":"response_time","metric_target_type":"value","metric_target_value":200}]`,
+		},
+		{
+			name:    "unknown gateway metric",
+			metrics: `[{"metric_type":"gateway_metrics","metric_name":"bytes_sent","metric_target_type":"value","metric_target_value":200}]`,
+			wantErr: true,
+		},
+		{
+			name:    "zero target value",
+			metrics: `[{"metric_type":"gateway_metrics","metric_name":"response_time","metric_target_type":"value","metric_target_value":0}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown metric type",
+			metrics: `[{"metric_type":"foo_metrics","metric_name":"foo","metric_target_type":"value","metric_target_value":1}]`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := api_model.AutoscalerRuleReq{MinReplicas: 1, MaxReplicas: 3}
+			if err := json.Unmarshal([]byte(tc.metrics), &req.Metrics); err != nil {
+				t.Fatal(err)
+			}
+			err := checkAutoscalerRule(&req, tc.monitors)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/goodrain/rainbond/api/util"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	core_util "github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/cron"
+)
+
+//scheduleCheckInterval the interval of checking whether the backup schedules should be triggered
+var scheduleCheckInterval = 30 * time.Second
+
+//BackupSchedule group app backup schedule
+// swagger:parameters createBackupSchedule updateBackupSchedule
+type BackupSchedule struct {
+	Body struct {
+		GroupID string `json:"group_id" validate:"group_id|required"`
+		// optional, only recorded as the components of the group when the schedule is saved.
+		// every run backs up all the components in the group at that time, the components added later are included.
+		ServiceIDs []string `json:"service_ids" validate:"service_ids"`
+		// standard cron expression, such as "0 2 * * *"
+		Cron string `json:"cron" validate:"cron|required|cron"`
+		// the number of daily backups to keep, the backups are kept forever if both keep_daily and keep_weekly are 0
+		KeepDaily int `json:"keep_daily" validate:"keep_daily|min:0"`
+		// the number of weekly backups to keep
+		KeepWeekly int    `json:"keep_weekly" validate:"keep_weekly|min:0"`
+		Mode       string `json:"mode" validate:"mode|required|in:full-online,incremental-online"`
+		Force      bool   `json:"force"`
+		// console level metadata of the group app, used by every scheduled backup
+		Metadata string `json:"metadata,omitempty"`
+		Enable   bool   `json:"enable"`
+		S3Config struct {
+			Provider   string `json:"provider"`
+			Endpoint   string `json:"endpoint"`
+			AccessKey  string `json:"access_key"`
+			SecretKey  string `json:"secret_key"`
+			BucketName string `json:"bucket_name"`
+		} `json:"s3_config"`
+		Encryption Encryption `json:"encryption"`
+	}
+}
+
+func (s *BackupSchedule) fill(schedule *dbmodel.AppBackupSchedule) *util.APIHandleError {
+	if _, err := cron.Parse(s.Body.Cron); err != nil {
+		return util.CreateAPIHandleError(400, fmt.Errorf("invalid cron expression: %v", err))
+	}
+	if s.Body.KeepDaily < 0 || s.Body.KeepWeekly < 0 {
+		return util.CreateAPIHandleError(400, fmt.Errorf("keep_daily and keep_weekly can not be negative"))
+	}
+	if len(s.Body.ServiceIDs) > 0 {
+		if alias, err := db.GetManager().TenantServiceDao().GetServiceAliasByIDs(s.Body.ServiceIDs); len(alias) != len(s.Body.ServiceIDs) || err != nil {
+			return util.CreateAPIHandleError(400, fmt.Errorf("some services do not exist in need backup services"))
+		}
+	}
+	schedule.GroupID = s.Body.GroupID
+	if len(s.Body.ServiceIDs) > 0 {
+		schedule.ServiceIDs = strings.Join(s.Body.ServiceIDs, ",")
+	}
+	schedule.Cron = s.Body.Cron
+	schedule.KeepDaily = s.Body.KeepDaily
+	schedule.KeepWeekly = s.Body.KeepWeekly
+	schedule.Mode = s.Body.Mode
+	schedule.Force = s.Body.Force
+	schedule.Enable = s.Body.Enable
+	schedule.S3Provider = s.Body.S3Config.Provider
+	schedule.S3Endpoint = s.Body.S3Config.Endpoint
+	schedule.S3AccessKey = s.Body.S3Config.AccessKey
+	schedule.S3BucketName = s.Body.S3Config.BucketName
+	schedule.EncryptionSecretName = s.Body.Encryption.SecretName
+	schedule.EncryptionSecretKey = s.Body.Encryption.SecretKey
+	//keep the secret and metadata if not specified when update
+	if s.Body.S3Config.SecretKey != "" {
+		schedule.S3SecretKey = s.Body.S3Config.SecretKey
+	}
+	if s.Body.Metadata != "" {
+		schedule.Metadata = s.Body.Metadata
+	}
+	return nil
+}
+
+//CreateBackupSchedule create backup schedule for group app
+func (h *BackupHandle) CreateBackupSchedule(s BackupSchedule) (*dbmodel.AppBackupSchedule, *util.APIHandleError) {
+	schedule := &dbmodel.AppBackupSchedule{ScheduleID: core_util.NewUUID()}
+	if err := s.fill(schedule); err != nil {
+		return nil, err
+	}
+	if err := db.GetManager().AppBackupScheduleDao().AddModel(schedule); err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("create backup schedule", err)
+	}
+	return schedule, nil
+}
+
+//UpdateBackupSchedule update backup schedule
+func (h *BackupHandle) UpdateBackupSchedule(scheduleID string, s BackupSchedule) (*dbmodel.AppBackupSchedule, *util.APIHandleError) {
+	schedule, Aerr := h.GetBackupSchedule(scheduleID)
+	if Aerr != nil {
+		return nil, Aerr
+	}
+	if err := s.fill(schedule); err != nil {
+		return nil, err
+	}
+	if err := db.GetManager().AppBackupScheduleDao().UpdateModel(schedule); err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("update backup schedule", err)
+	}
+	return schedule, nil
+}
+
+//GetBackupSchedule get backup schedule
+func (h *BackupHandle) GetBackupSchedule(scheduleID string) (*dbmodel.AppBackupSchedule, *util.APIHandleError) {
+	schedule, err := db.GetManager().AppBackupScheduleDao().GetSchedule(scheduleID)
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("get backup schedule", err)
+	}
+	return schedule, nil
+}
+
+//ListBackupSchedules list the backup schedules of group app
+func (h *BackupHandle) ListBackupSchedules(groupID string) ([]*dbmodel.AppBackupSchedule, *util.APIHandleError) {
+	schedules, err := db.GetManager().AppBackupScheduleDao().ListSchedules(groupID)
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("list backup schedules", err)
+	}
+	return schedules, nil
+}
+
+//DeleteBackupSchedule delete backup schedule, the backups created by the schedule are not deleted
+func (h *BackupHandle) DeleteBackupSchedule(scheduleID string) *util.APIHandleError {
+	if _, err := h.GetBackupSchedule(scheduleID); err != nil {
+		return err
+	}
+	if err := db.GetManager().AppBackupScheduleDao().DeleteSchedule(scheduleID); err != nil {
+		return util.CreateAPIHandleErrorFromDBError("delete backup schedule", err)
+	}
+	return nil
+}
+
+//StartScheduler trigger the backups of enabled schedules until the ctx is done
+func (h *BackupHandle) StartScheduler(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(scheduleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				h.runSchedules(now)
+			}
+		}
+	}()
+}
+
+func (h *BackupHandle) runSchedules(now time.Time) {
+	schedules, err := db.GetManager().AppBackupScheduleDao().ListEnabledSchedules()
+	if err != nil {
+		logrus.Errorf("list backup schedules failure: %v", err)
+		return
+	}
+	for _, schedule := range schedules {
+		next, err := nextScheduleTime(schedule)
+		if err != nil {
+			logrus.Warningf("backup schedule %s is invalid: %v", schedule.ScheduleID, err)
+			continue
+		}
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+		//the schedule may be triggered by other api instance
+		lease, ok := h.lockSchedule(schedule.ScheduleID, next)
+		if !ok {
+			continue
+		}
+		if err := h.runSchedule(schedule, next, now); err != nil {
+			logrus.Errorf("start scheduled backup of group %s failure: %v", schedule.GroupID, err)
+			//the schedule is retried in the next check
+			h.unlockSchedule(lease)
+		}
+	}
+}
+
+//runSchedule start the backup of the components currently in the group,
+//the last schedule time is only updated after the backup is started, the missed runs are merged into one backup.
+func (h *BackupHandle) runSchedule(schedule *dbmodel.AppBackupSchedule, scheduled, now time.Time) error {
+	services, err := db.GetManager().TenantServiceDao().ListByAppID(schedule.GroupID)
+	if err != nil {
+		return fmt.Errorf("list components of group: %v", err)
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("there is no component in group")
+	}
+	serviceIDs := make([]string, 0, len(services))
+	for _, service := range services {
+		serviceIDs = append(serviceIDs, service.ServiceID)
+	}
+	schedule.ServiceIDs = strings.Join(serviceIDs, ",")
+	if _, err := h.NewBackup(scheduledBackup(schedule, scheduled)); err != nil {
+		return err
+	}
+	schedule.LastScheduleTime = &now
+	if err := db.GetManager().AppBackupScheduleDao().UpdateModel(schedule); err != nil {
+		logrus.Errorf("update last schedule time of backup schedule %s failure: %v", schedule.ScheduleID, err)
+	}
+	return nil
+}
+
+//nextScheduleTime return the next time the schedule should be triggered after the last triggered time
+func nextScheduleTime(schedule *dbmodel.AppBackupSchedule) (time.Time, error) {
+	s, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	last := schedule.CreatedAt
+	if schedule.LastScheduleTime != nil {
+		last = *schedule.LastScheduleTime
+	}
+	return s.Next(last), nil
+}
+
+//scheduledBackup build the backup request of the schedule,
+//the version is unique for every scheduled time.
+func scheduledBackup(schedule *dbmodel.AppBackupSchedule, scheduled time.Time) Backup {
+	b := Backup{Schedule: schedule}
+	b.Body.EventID = core_util.NewUUID()
+	b.Body.GroupID = schedule.GroupID
+	b.Body.ServiceIDs = strings.Split(schedule.ServiceIDs, ",")
+	b.Body.Metadata = scheduleMetadata(schedule.Metadata, b.Body.ServiceIDs)
+	b.Body.Version = fmt.Sprintf("auto-%s-%.8s", scheduled.Format("200601021504"), schedule.ScheduleID)
+	b.Body.Mode = schedule.Mode
+	b.Body.Force = schedule.Force
+	b.Body.S3Config.Provider = schedule.S3Provider
+	b.Body.S3Config.Endpoint = schedule.S3Endpoint
+	b.Body.S3Config.AccessKey = schedule.S3AccessKey
+	b.Body.S3Config.SecretKey = schedule.S3SecretKey
+	b.Body.S3Config.BucketName = schedule.S3BucketName
+	b.Body.Encryption.SecretName = schedule.EncryptionSecretName
+	b.Body.Encryption.SecretKey = schedule.EncryptionSecretKey
+	return b
+}
+
+//scheduleMetadata rebuild the console level metadata for the components to be backed up,
+//the apps of the components removed from the group are dropped.
+func scheduleMetadata(metadata string, serviceIDs []string) string {
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(metadata), &data); err != nil {
+		return metadata
+	}
+	var apps []json.RawMessage
+	if err := json.Unmarshal(data["apps"], &apps); err != nil {
+		return metadata
+	}
+	ids := make(map[string]bool, len(serviceIDs))
+	for _, id := range serviceIDs {
+		ids[id] = true
+	}
+	kept := make([]json.RawMessage, 0, len(apps))
+	for _, app := range apps {
+		var a struct {
+			ServiceBase struct {
+				ServiceID string `json:"service_id"`
+			} `json:"service_base"`
+		}
+		if err := json.Unmarshal(app, &a); err == nil && a.ServiceBase.ServiceID != "" && !ids[a.ServiceBase.ServiceID] {
+			continue
+		}
+		kept = append(kept, app)
+	}
+	body, err := json.Marshal(kept)
+	if err != nil {
+		return metadata
+	}
+	data["apps"] = body
+	result, err := json.Marshal(data)
+	if err != nil {
+		return metadata
+	}
+	return string(result)
+}
+
+//lockSchedule lock the scheduled time of the schedule, the lock is released by the lease
+func (h *BackupHandle) lockSchedule(scheduleID string, scheduled time.Time) (clientv3.LeaseID, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	lease, err := h.etcdCli.Grant(ctx, int64((24 * time.Hour).Seconds()))
+	if err != nil {
+		logrus.Errorf("grant lease for backup schedule failure: %v", err)
+		return 0, false
+	}
+	hostname, _ := os.Hostname()
+	key := fmt.Sprintf("/rainbond/backup_schedule/%s/%d", scheduleID, scheduled.Unix())
+	resp, err := h.etcdCli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, hostname, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		if err != nil {
+			logrus.Errorf("lock backup schedule %s failure: %v", scheduleID, err)
+		}
+		h.unlockSchedule(lease.ID)
+		return 0, false
+	}
+	return lease.ID, true
+}
+
+//unlockSchedule revoke the lease, the key of the lease is deleted
+func (h *BackupHandle) unlockSchedule(lease clientv3.LeaseID) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if _, err := h.etcdCli.Revoke(ctx, lease); err != nil {
+		logrus.Warningf("revoke lease of backup schedule failure: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"encoding/json"
+	"testing"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+func TestScheduleMetadata(t *testing.T) {
+	metadata := `{"group_info":{"group_id":1},"apps":[{"service_base":{"service_id":"s1"}},{"service_base":{"service_id":"s2"}}]}`
+	var got struct {
+		GroupInfo map[string]int `json:"group_info"`
+		Apps      []struct {
+			ServiceBase struct {
+				ServiceID string `json:"service_id"`
+			} `json:"service_base"`
+		} `json:"apps"`
+	}
+	if err := json.Unmarshal([]byte(scheduleMetadata(metadata, []string{"s2", "s3"})), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Apps) != 1 || got.Apps[0].ServiceBase.ServiceID != "s2" || got.GroupInfo["group_id"] != 1 {
+		t.Errorf("expect only the app of s2 kept, got %+v", got)
+	}
+	if got := scheduleMetadata("not json", []string{"s1"}); got != "not json" {
+		t.Errorf("expect the metadata kept if it can not be parsed, got %s", got)
+	}
+}
+
+func TestFillScheduleWithoutServiceIDs(t *testing.T) {
+	var s BackupSchedule
+	s.Body.GroupID = "g1"
+	s.Body.Cron = "0 2 * * *"
+	s.Body.Mode = "full-online"
+	schedule := &dbmodel.AppBackupSchedule{ServiceIDs: "s1,s2"}
+	if err := s.fill(schedule); err != nil {
+		t.Fatalf("expect the schedule without service ids accepted, got %v", err)
+	}
+	if schedule.ServiceIDs != "s1,s2" {
+		t.Errorf("expect the recorded service ids kept, got %s", schedule.ServiceIDs)
+	}
+}
@@ -48,7 +48,9 @@ type Backup struct {
 	// in: path
 	// required: true
 	TenantName string `json:"tenant_name"`
-	Body       struct {
+	//Schedule the schedule triggered the backup, nil if backup manually
+	Schedule *dbmodel.AppBackupSchedule `json:"-"`
+	Body     struct {
 		EventID    string   `json:"event_id" validate:"event_id|required"`
 		GroupID    string   `json:"group_id" validate:"group_name|required"`
 		Metadata   string   `json:"metadata,omitempty" validate:"metadata|required"`
@@ -57,8 +59,19 @@ type Backup struct {
 		SourceDir  string   `json:"source_dir"`
 		BackupID   string   `json:"backup_id,omitempty"`
 
-		Mode     string `json:"mode" validate:"mode|required|in:full-online,full-offline"`
-		Force    bool   `json:"force"`
+		Mode  string `json:"mode" validate:"mode|required|in:full-online,full-offline,incremental-online"`
+		Force bool   `json:"force"`
+		//the retention of schedule, set by the schedule
+		ScheduleID string `json:"schedule_id,omitempty"`
+		KeepDaily  int    `json:"keep_daily,omitempty"`
+		KeepWeekly int    `json:"keep_weekly,omitempty"`
+		//the volumes are backup by CSI volume snapshot if enabled and the storage class supports,
+		//otherwise the volume data is copied to the backup package or object storage
+		VolumeSnapshot struct {
+			Enable bool `json:"enable"`
+			//the quiesce hooks of components, key is service id
+			Hooks map[string]*SnapshotHook `json:"hooks,omitempty"`
+		} `json:"volume_snapshot"`
 		S3Config struct {
 			Provider   string `json:"provider"`
 			Endpoint   string `json:"endpoint"`
@@ -66,9 +79,28 @@ type Backup struct {
 			SecretKey  string `json:"secret_key"`
 			BucketName string `json:"bucket_name"`
 		} `json:"s3_config"`
+		//the client-side encryption of backups stored in object storage
+		Encryption Encryption `json:"encryption"`
 	}
 }
 
+//Encryption the client-side encryption config, the key is read from the kubernetes secret
+//in the namespace of rainbond components, the data is not encrypted if the secret name is empty.
+type Encryption struct {
+	SecretName string `json:"secret_name"`
+	//the key of secret data, default is "key"
+	SecretKey string `json:"secret_key,omitempty"`
+}
+
+//SnapshotHook the commands executed in the pods of component before and after the volumes are snapshotted
+type SnapshotHook struct {
+	//the container to execute the commands, default is the first container of pod
+	Container      string   `json:"container"`
+	Pre            []string `json:"pre"`
+	Post           []string `json:"post"`
+	TimeoutSeconds int      `json:"timeout_seconds"`
+}
+
 //BackupHandle group app backup handle
 type BackupHandle struct {
 	mqcli     mqclient.MQClient
@@ -91,6 +123,17 @@ func (h *BackupHandle) NewBackup(b Backup) (*dbmodel.AppBackup, *util.APIHandleE
 		Status:     "starting",
 		Version:    b.Body.Version,
 		BackupMode: b.Body.Mode,
+		Encrypted:  b.Body.Encryption.SecretName != "",
+	}
+	if appBackup.Encrypted && b.Body.Mode == "full-offline" {
+		return nil, util.CreateAPIHandleError(400, fmt.Errorf("encryption is only supported by the backups stored in object storage"))
+	}
+	b.Body.ScheduleID, b.Body.KeepDaily, b.Body.KeepWeekly = "", 0, 0
+	if b.Schedule != nil {
+		appBackup.ScheduleID = b.Schedule.ScheduleID
+		b.Body.ScheduleID = b.Schedule.ScheduleID
+		b.Body.KeepDaily = b.Schedule.KeepDaily
+		b.Body.KeepWeekly = b.Schedule.KeepWeekly
 	}
 	//check last backup task whether complete or version whether exist
 	if db.GetManager().AppBackupDao().CheckHistory(b.Body.GroupID, b.Body.Version) {
@@ -372,6 +415,8 @@ type BackupRestore struct {
 			SecretKey  string `json:"secret_key"`
 			BucketName string `json:"bucket_name"`
 		} `json:"s3_config"`
+		//the encryption of backup, required if the backup is encrypted
+		Encryption Encryption `json:"encryption"`
 	}
 }
 
@@ -408,6 +453,9 @@ func (h *BackupHandle) RestoreBackup(br BackupRestore) (*RestoreResult, *util.AP
 	if backup.Status != "success" || backup.SourceDir == "" || backup.SourceType == "" {
 		return nil, util.CreateAPIHandleErrorf(500, "backup can not be restored")
 	}
+	if backup.Encrypted && br.Body.Encryption.SecretName == "" {
+		return nil, util.CreateAPIHandleErrorf(400, "backup is encrypted, the encryption secret is required")
+	}
 	var restoreID string
 	if br.Body.EventID != "" {
 		restoreID = br.Body.EventID
@@ -419,6 +467,7 @@ func (h *BackupHandle) RestoreBackup(br BackupRestore) (*RestoreResult, *util.AP
 		"restore_id":   restoreID,
 		"restore_mode": br.Body.RestoreMode,
 		"s3_config":    br.Body.S3Config,
+		"encryption":   br.Body.Encryption,
 	}
 	err := h.mqcli.SendBuilderTopic(mqclient.TaskStruct{
 		TaskBody: dataMap,
@@ -73,9 +73,11 @@ func InitHandle(conf option.Config,
 	defaultGatewayHandler = CreateGatewayManager(dbmanager, mqClient, etcdcli)
 	def3rdPartySvcHandler = Create3rdPartySvcHandler(dbmanager, statusCli)
 	operationHandler = CreateOperationHandler(mqClient)
+	defWebhookHandler = NewWebhookHandler(operationHandler)
 	batchOperationHandler = CreateBatchOperationHandler(mqClient, statusCli, operationHandler)
 	defaultAppRestoreHandler = NewAppRestoreHandler()
 	defPodHandler = NewPodHandler(statusCli)
+	defTerminalHandler = NewTerminalHandler(conf)
 	defClusterHandler = NewClusterHandler(kubeClient, conf.RbdNamespace)
 	defaultVolumeTypeHandler = CreateVolumeTypeManger(statusCli)
 	defaultEtcdHandler = NewEtcdHandler(etcdcli)
@@ -194,6 +196,18 @@ func GetAppRestoreHandler() AppRestoreHandler {
 }
 
 var defPodHandler PodHandler
+var defTerminalHandler TerminalHandler
+var defWebhookHandler WebhookHandler
+
+// GetWebhookHandler returns the default WebhookHandler
+func GetWebhookHandler() WebhookHandler {
+	return defWebhookHandler
+}
+
+// GetTerminalHandler returns the default TerminalHandler
+func GetTerminalHandler() TerminalHandler {
+	return defTerminalHandler
+}
 
 // GetPodHandler returns the defalut PodHandler
 func GetPodHandler() PodHandler {
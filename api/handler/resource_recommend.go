@@ -0,0 +1,375 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	core_util "github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/cron"
+	"github.com/goodrain/rainbond/worker/discover/model"
+	"github.com/goodrain/rainbond/worker/master/podevent"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	recommendWindow = 7 * 24 * time.Hour
+	recommendStep   = 5 * time.Minute
+	//the recommendation is not applied with less than one day of samples
+	minRecommendSamples = 288
+	//the safety margin added to the percentiles of usage
+	recommendMargin    = 1.15
+	minCPURecommend    = 10
+	minMemoryRecommend = 64
+	memoryRecommendMB  = 32
+	//the memory is raised to oomBumpRatio of the current memory at least if the component is oom killed
+	oomBumpRatio = 1.2
+	//the recommendation is applied only if it differs from the current resources by more than applyThreshold
+	applyThreshold = 0.1
+)
+
+//resourcePolicyCheckInterval the interval of checking whether the recommendations should be applied
+var resourcePolicyCheckInterval = 30 * time.Second
+
+//percentile returns the p percentile of the samples by the nearest rank
+func percentile(samples []float64, p float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func roundMemory(mb float64) int {
+	memory := int(math.Ceil(mb/memoryRecommendMB)) * memoryRecommendMB
+	if memory < minMemoryRecommend {
+		memory = minMemoryRecommend
+	}
+	return memory
+}
+
+//recommendResources computes the recommendations from the cpu usage samples in cores and the memory
+//working set samples in bytes. The request is the p90 and the limit is the p99 of usage with the safety margin,
+//the memory limit is raised if the component is oom killed, which means the usage is cut by the current limit.
+func recommendResources(cpuSamples, memSamples []float64, oomKilled, currentCPU, currentMemory int) *api_model.ResourceRecommendation {
+	rec := &api_model.ResourceRecommendation{
+		CurrentCPU:    currentCPU,
+		CurrentMemory: currentMemory,
+		CPURequest:    currentCPU,
+		CPULimit:      currentCPU,
+		MemoryRequest: currentMemory,
+		MemoryLimit:   currentMemory,
+		OOMKilled:     oomKilled,
+		Samples:       len(cpuSamples),
+		Sufficient:    len(cpuSamples) >= minRecommendSamples && len(memSamples) >= minRecommendSamples,
+		Window:        recommendWindow.String(),
+	}
+	if len(cpuSamples) > 0 {
+		rec.CPURequest = int(math.Ceil(percentile(cpuSamples, 0.9) * 1000 * recommendMargin))
+		if rec.CPURequest < minCPURecommend {
+			rec.CPURequest = minCPURecommend
+		}
+		rec.CPULimit = int(math.Ceil(percentile(cpuSamples, 0.99) * 1000 * recommendMargin))
+		if rec.CPULimit < rec.CPURequest {
+			rec.CPULimit = rec.CPURequest
+		}
+	}
+	if len(memSamples) > 0 {
+		rec.MemoryRequest = roundMemory(percentile(memSamples, 0.9) / 1024 / 1024 * recommendMargin)
+		rec.MemoryLimit = roundMemory(percentile(memSamples, 0.99) / 1024 / 1024 * recommendMargin)
+	}
+	if oomKilled > 0 {
+		if bump := roundMemory(float64(currentMemory) * oomBumpRatio); rec.MemoryLimit < bump {
+			rec.MemoryLimit = bump
+		}
+	}
+	if rec.MemoryRequest > rec.MemoryLimit {
+		rec.MemoryRequest = rec.MemoryLimit
+	}
+	return rec
+}
+
+//shouldApplyRecommendation returns whether the recommendation differs from the current resources enough.
+//The cpu weight of component is set to the cpu request, and the memory of component, which is both the
+//request and limit of pods, is set to the memory limit.
+func shouldApplyRecommendation(rec *api_model.ResourceRecommendation) bool {
+	if !rec.Sufficient {
+		return false
+	}
+	differs := func(recommended, current int) bool {
+		return math.Abs(float64(recommended-current)) > float64(current)*applyThreshold
+	}
+	return differs(rec.CPURequest, rec.CurrentCPU) || differs(rec.MemoryLimit, rec.CurrentMemory)
+}
+
+func (s *ServiceAction) usageSamples(expr string, start, end time.Time) ([]float64, error) {
+	metric := s.prometheusCli.GetMetricOverTime(expr, start, end, recommendStep)
+	if metric.Error != "" {
+		return nil, fmt.Errorf("query %s: %s", expr, metric.Error)
+	}
+	var samples []float64
+	for _, mv := range metric.MetricValues {
+		for _, point := range mv.Series {
+			if !math.IsNaN(point[1]) {
+				samples = append(samples, point[1])
+			}
+		}
+	}
+	return samples, nil
+}
+
+// RecommendResources computes the resource recommendations of component from the usage history of all pods.
+func (s *ServiceAction) RecommendResources(serviceID string) (*api_model.ResourceRecommendation, error) {
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now()
+	start := end.Add(-recommendWindow)
+	// the name of main container is the component id
+	cpuSamples, err := s.usageSamples(fmt.Sprintf(`rate(container_cpu_usage_seconds_total{container="%s"}[5m])`, serviceID), start, end)
+	if err != nil {
+		return nil, err
+	}
+	memSamples, err := s.usageSamples(fmt.Sprintf(`container_memory_working_set_bytes{container="%s"}`, serviceID), start, end)
+	if err != nil {
+		return nil, err
+	}
+	oomKilled, err := db.GetManager().ServiceEventDao().CountByServiceIDAndOptType(serviceID, podevent.EventTypeOOMKilled.String(), start)
+	if err != nil {
+		return nil, err
+	}
+	rec := recommendResources(cpuSamples, memSamples, oomKilled, service.ContainerCPU, service.ContainerMemory)
+	rec.ServiceID = serviceID
+	return rec, nil
+}
+
+// GetResourcePolicy -
+func (s *ServiceAction) GetResourcePolicy(serviceID string) (*dbmodel.TenantServiceResourcePolicy, error) {
+	return db.GetManager().TenantServiceResourcePolicyDao().GetByServiceID(serviceID)
+}
+
+// UpdResourcePolicy creates or updates the right-sizing policy of component
+func (s *ServiceAction) UpdResourcePolicy(req *api_model.ResourcePolicyReq) (*dbmodel.TenantServiceResourcePolicy, error) {
+	if req.AutoApply || req.Cron != "" {
+		if _, err := cron.Parse(req.Cron); err != nil {
+			return nil, bcode.NewBadRequest(fmt.Sprintf("invalid cron %s: %v", req.Cron, err))
+		}
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return nil, bcode.NewBadRequest(fmt.Sprintf("invalid timezone %s", req.Timezone))
+	}
+	policy, err := db.GetManager().TenantServiceResourcePolicyDao().GetByServiceID(req.ServiceID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if err == gorm.ErrRecordNotFound {
+		policy = &dbmodel.TenantServiceResourcePolicy{ServiceID: req.ServiceID}
+	}
+	policy.AutoApply = req.AutoApply
+	policy.Cron = req.Cron
+	policy.Timezone = req.Timezone
+	// the windows before the update are not applied by the new policy
+	now := time.Now()
+	policy.LastApplyTime = &now
+	if policy.ID == 0 {
+		err = db.GetManager().TenantServiceResourcePolicyDao().AddModel(policy)
+	} else {
+		err = db.GetManager().TenantServiceResourcePolicyDao().UpdateModel(policy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+//StartResourceRecommender applies the recommendations of the auto apply policies until the ctx is done
+func (s *ServiceAction) StartResourceRecommender(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(resourcePolicyCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.runResourcePolicies(now)
+			}
+		}
+	}()
+}
+
+func (s *ServiceAction) runResourcePolicies(now time.Time) {
+	policies, err := db.GetManager().TenantServiceResourcePolicyDao().ListAutoApplyOnes()
+	if err != nil {
+		logrus.Errorf("list resource policies: %v", err)
+		return
+	}
+	for _, policy := range policies {
+		next, err := nextApplyTime(policy)
+		if err != nil {
+			logrus.Warningf("resource policy of component %s is invalid: %v", policy.ServiceID, err)
+			continue
+		}
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+		// the policy may be applied by other api instance
+		if !s.lockResourcePolicy(policy.ServiceID, next) {
+			continue
+		}
+		policy.LastApplyTime = &now
+		if err := db.GetManager().TenantServiceResourcePolicyDao().UpdateModel(policy); err != nil {
+			logrus.Errorf("update last apply time of resource policy %s: %v", policy.ServiceID, err)
+			continue
+		}
+		if err := s.applyRecommendation(policy.ServiceID); err != nil {
+			logrus.Errorf("apply resource recommendation of component %s: %v", policy.ServiceID, err)
+		}
+	}
+}
+
+//nextApplyTime returns the next low-traffic window after the last applied time in the timezone of policy
+func nextApplyTime(policy *dbmodel.TenantServiceResourcePolicy) (time.Time, error) {
+	sched, err := cron.Parse(policy.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc := time.UTC
+	if policy.Timezone != "" {
+		if loc, err = time.LoadLocation(policy.Timezone); err != nil {
+			return time.Time{}, err
+		}
+	}
+	last := policy.CreatedAt
+	if policy.LastApplyTime != nil {
+		last = *policy.LastApplyTime
+	}
+	return sched.Next(last.In(loc)), nil
+}
+
+func (s *ServiceAction) lockResourcePolicy(serviceID string, scheduled time.Time) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	lease, err := s.EtcdCli.Grant(ctx, int64((24 * time.Hour).Seconds()))
+	if err != nil {
+		logrus.Errorf("grant lease for resource policy: %v", err)
+		return false
+	}
+	hostname, _ := os.Hostname()
+	key := fmt.Sprintf("/rainbond/resource_policy/%s/%d", serviceID, scheduled.Unix())
+	resp, err := s.EtcdCli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, hostname, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil {
+		logrus.Errorf("lock resource policy of component %s: %v", serviceID, err)
+		return false
+	}
+	return resp.Succeeded
+}
+
+//hasResourceHPA returns whether the component is scaled by the cpu or memory of an enabled autoscaler rule,
+//the utilization of hpa is relative to the requests, so the requests are not changed under it.
+func hasResourceHPA(serviceID string) (bool, error) {
+	rules, err := db.GetManager().TenantServceAutoscalerRulesDao().ListEnableOnesByServiceID(serviceID)
+	if err != nil {
+		return false, err
+	}
+	for _, rule := range rules {
+		metrics, err := db.GetManager().TenantServceAutoscalerRuleMetricsDao().ListByRuleID(rule.RuleID)
+		if err != nil {
+			return false, err
+		}
+		for _, metric := range metrics {
+			if metric.MetricsType == dbmodel.ResourceMetrics {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (s *ServiceAction) applyRecommendation(serviceID string) error {
+	if ok, err := hasResourceHPA(serviceID); err != nil || ok {
+		if ok {
+			logrus.Infof("component %s is scaled by the cpu or memory of hpa, skip the resource recommendation", serviceID)
+		}
+		return err
+	}
+	rec, err := s.RecommendResources(serviceID)
+	if err != nil {
+		return err
+	}
+	if !shouldApplyRecommendation(rec) {
+		logrus.Debugf("the resource recommendation of component %s is not applied: %+v", serviceID, rec)
+		return nil
+	}
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return err
+	}
+	err = s.ServiceVertical(&model.VerticalScalingTaskBody{
+		TenantID:        service.TenantID,
+		ServiceID:       serviceID,
+		ContainerCPU:    rec.CPURequest,
+		ContainerMemory: rec.MemoryLimit,
+		EventID:         core_util.NewUUID(),
+	})
+	desc := fmt.Sprintf("the cpu is changed from %dm to %dm and the memory is changed from %dMB to %dMB by the resource recommendation, oom killed %d times in %s",
+		rec.CurrentCPU, rec.CPURequest, rec.CurrentMemory, rec.MemoryLimit, rec.OOMKilled, rec.Window)
+	reason := "SuccessfulRightsize"
+	if err != nil {
+		desc = fmt.Sprintf("%s: %v", desc, err)
+		reason = "FailedRightsize"
+	}
+	record := &dbmodel.TenantServiceScalingRecords{
+		ServiceID:   serviceID,
+		EventName:   core_util.NewUUID(),
+		RecordType:  "vertical",
+		Reason:      reason,
+		Count:       1,
+		Description: desc,
+		Operator:    dbmodel.UsernameSystem,
+		LastTime:    time.Now(),
+	}
+	if err := db.GetManager().TenantServiceScalingRecordsDao().AddModel(record); err != nil {
+		logrus.Warningf("save scaling record: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+func repeatSamples(value float64, n int) []float64 {
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = value
+	}
+	return samples
+}
+
+func TestPercentile(t *testing.T) {
+	samples := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+	if got := percentile(samples, 0.9); got != 9 {
+		t.Errorf("p90: want 9, got %v", got)
+	}
+	if got := percentile(samples, 0.99); got != 10 {
+		t.Errorf("p99: want 10, got %v", got)
+	}
+	if got := percentile(nil, 0.9); got != 0 {
+		t.Errorf("empty: want 0, got %v", got)
+	}
+}
+
+func TestRecommendResources(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		name           string
+		cpu, mem       []float64
+		oomKilled      int
+		wantCPU        int
+		wantMemory     int
+		wantSufficient bool
+		wantApply      bool
+	}{
+		{
+			name:           "over provisioned",
+			cpu:            repeatSamples(0.1, minRecommendSamples),
+			mem:            repeatSamples(200*mb, minRecommendSamples),
+			wantCPU:        115,
+			wantMemory:     256,
+			wantSufficient: true,
+			wantApply:      true,
+		},
+		{
+			name:           "oom killed",
+			cpu:            repeatSamples(0.4, minRecommendSamples),
+			mem:            repeatSamples(500*mb, minRecommendSamples),
+			oomKilled:      2,
+			wantCPU:        460,
+			wantMemory:     640,
+			wantSufficient: true,
+			wantApply:      true,
+		},
+		{
+			name:       "insufficient samples",
+			cpu:        repeatSamples(0.1, 10),
+			mem:        repeatSamples(200*mb, 10),
+			wantCPU:    115,
+			wantMemory: 256,
+		},
+		{
+			name:       "no samples",
+			wantCPU:    500,
+			wantMemory: 512,
+		},
+		{
+			name:           "right sized",
+			cpu:            repeatSamples(0.44, minRecommendSamples),
+			mem:            repeatSamples(440*mb, minRecommendSamples),
+			wantCPU:        506,
+			wantMemory:     512,
+			wantSufficient: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := recommendResources(tc.cpu, tc.mem, tc.oomKilled, 500, 512)
+			if rec.CPURequest != tc.wantCPU || rec.MemoryLimit != tc.wantMemory {
+				t.Errorf("want cpu %d memory %d, got cpu %d memory %d", tc.wantCPU, tc.wantMemory, rec.CPURequest, rec.MemoryLimit)
+			}
+			if rec.CPULimit < rec.CPURequest || rec.MemoryRequest > rec.MemoryLimit {
+				t.Errorf("the requests should not exceed the limits: %+v", rec)
+			}
+			if rec.Sufficient != tc.wantSufficient {
+				t.Errorf("want sufficient %v, got %v", tc.wantSufficient, rec.Sufficient)
+			}
+			if got := shouldApplyRecommendation(rec); got != tc.wantApply {
+				t.Errorf("want apply %v, got %v", tc.wantApply, got)
+			}
+		})
+	}
+}
+
+func TestNextApplyTime(t *testing.T) {
+	policy := &dbmodel.TenantServiceResourcePolicy{Cron: "0 3 * * *", Timezone: "Asia/Shanghai"}
+	last := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy.LastApplyTime = &last
+	next, err := nextApplyTime(policy)
+	if err != nil {
+		t.Skipf("timezone database is not available: %v", err)
+	}
+	// 03:00 in Shanghai is 19:00 of the previous day in UTC
+	if want := time.Date(2021, 3, 1, 19, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("want %v, got %v", want, next)
+	}
+}
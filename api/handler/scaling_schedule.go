@@ -0,0 +1,113 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/cron"
+)
+
+//checkScalingSchedule checks the cron, timezone and replicas of the scheduled scaling rule,
+//the timezone is UTC if it is empty.
+func checkScalingSchedule(req *api_model.ScalingScheduleReq) error {
+	if _, err := cron.Parse(req.Cron); err != nil {
+		return bcode.NewBadRequest(fmt.Sprintf("invalid cron %s: %v", req.Cron, err))
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return bcode.NewBadRequest(fmt.Sprintf("invalid timezone %s", req.Timezone))
+	}
+	if req.Replicas <= 0 {
+		return bcode.NewBadRequest("replicas must be greater than 0")
+	}
+	return nil
+}
+
+// AddScalingSchedule -
+func (s *ServiceAction) AddScalingSchedule(req *api_model.ScalingScheduleReq) (*dbmodel.TenantServiceScalingSchedule, error) {
+	if err := checkScalingSchedule(req); err != nil {
+		return nil, err
+	}
+	if req.ScheduleID == "" {
+		req.ScheduleID = util.NewUUID()
+	}
+	schedule := &dbmodel.TenantServiceScalingSchedule{
+		ScheduleID: req.ScheduleID,
+		ServiceID:  req.ServiceID,
+		Enable:     req.Enable,
+		Cron:       req.Cron,
+		Timezone:   req.Timezone,
+		Replicas:   req.Replicas,
+	}
+	if err := db.GetManager().TenantServiceScalingScheduleDao().AddModel(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// UpdScalingSchedule -
+func (s *ServiceAction) UpdScalingSchedule(req *api_model.ScalingScheduleReq) (*dbmodel.TenantServiceScalingSchedule, error) {
+	schedule, err := db.GetManager().TenantServiceScalingScheduleDao().GetByScheduleID(req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.ServiceID != req.ServiceID {
+		return nil, bcode.NewBadRequest(fmt.Sprintf("schedule %s does not belong to the component", req.ScheduleID))
+	}
+	if err := checkScalingSchedule(req); err != nil {
+		return nil, err
+	}
+	schedule.Enable = req.Enable
+	schedule.Cron = req.Cron
+	schedule.Timezone = req.Timezone
+	schedule.Replicas = req.Replicas
+	// the activations before the update are not executed by the new schedule
+	now := time.Now()
+	schedule.LastScheduleTime = &now
+	if err := db.GetManager().TenantServiceScalingScheduleDao().UpdateModel(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// ListScalingSchedules -
+func (s *ServiceAction) ListScalingSchedules(serviceID string) ([]*dbmodel.TenantServiceScalingSchedule, error) {
+	return db.GetManager().TenantServiceScalingScheduleDao().ListByServiceID(serviceID)
+}
+
+// DelScalingSchedule -
+func (s *ServiceAction) DelScalingSchedule(serviceID, scheduleID string) error {
+	schedule, err := db.GetManager().TenantServiceScalingScheduleDao().GetByScheduleID(scheduleID)
+	if err != nil {
+		return err
+	}
+	if schedule.ServiceID != serviceID {
+		return bcode.NewBadRequest(fmt.Sprintf("schedule %s does not belong to the component", scheduleID))
+	}
+	return db.GetManager().TenantServiceScalingScheduleDao().DeleteByScheduleID(scheduleID)
+}
@@ -0,0 +1,50 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"testing"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+)
+
+func TestCheckScalingSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     api_model.ScalingScheduleReq
+		wantErr bool
+	}{
+		{name: "weekdays", req: api_model.ScalingScheduleReq{Cron: "0 8 * * 1-5", Timezone: "UTC", Replicas: 6}},
+		{name: "default timezone", req: api_model.ScalingScheduleReq{Cron: "0 20 * * *", Replicas: 2}},
+		{name: "invalid cron", req: api_model.ScalingScheduleReq{Cron: "0 8 * *", Replicas: 2}, wantErr: true},
+		{name: "invalid timezone", req: api_model.ScalingScheduleReq{Cron: "0 8 * * *", Timezone: "Mars/Olympus", Replicas: 2}, wantErr: true},
+		{name: "zero replicas", req: api_model.ScalingScheduleReq{Cron: "0 8 * * *", Replicas: 0}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkScalingSchedule(&tc.req)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error: %v, got %v", tc.wantErr, err)
+			}
+			if err == nil && tc.req.Timezone == "" {
+				t.Errorf("the timezone should be defaulted")
+			}
+		})
+	}
+}
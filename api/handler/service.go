@@ -2204,6 +2204,9 @@ func (s *ServiceAction) delServiceMetadata(serviceID string) error {
 		db.GetManager().ServiceProbeDaoTransactions(tx).DELServiceProbesByServiceID,
 		db.GetManager().ServiceEventDaoTransactions(tx).DelEventByServiceID,
 		db.GetManager().TenantServiceMonitorDaoTransactions(tx).DeleteServiceMonitorByServiceID,
+		db.GetManager().TenantServiceWebhookDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServiceScalingScheduleDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServiceResourcePolicyDaoTransactions(tx).DeleteByServiceID,
 		db.GetManager().AppConfigGroupServiceDaoTransactions(tx).DeleteEffectiveServiceByServiceID,
 	}
 	if err := GetGatewayHandler().DeleteTCPRuleByServiceIDWithTransaction(serviceID, tx); err != nil {
@@ -2310,6 +2313,10 @@ func (s *ServiceAction) ListVersionInfo(serviceID string) (*api_model.BuildListR
 
 // AddAutoscalerRule -
 func (s *ServiceAction) AddAutoscalerRule(req *api_model.AutoscalerRuleReq) error {
+	if err := validateAutoscalerRule(req.ServiceID, req); err != nil {
+		return err
+	}
+
 	tx := db.GetManager().Begin()
 	defer db.GetManager().EnsureEndTransactionFunc()
 
@@ -2333,6 +2340,8 @@ func (s *ServiceAction) AddAutoscalerRule(req *api_model.AutoscalerRuleReq) erro
 			MetricsName:       metric.MetricsName,
 			MetricTargetType:  metric.MetricTargetType,
 			MetricTargetValue: metric.MetricTargetValue,
+			MetricSelector:    metric.MetricSelector,
+			MetricObject:      metric.MetricObject,
 		}
 		if err := db.GetManager().TenantServceAutoscalerRuleMetricsDaoTransactions(tx).AddModel(m); err != nil {
 			tx.Rollback()
@@ -2363,6 +2372,9 @@ func (s *ServiceAction) UpdAutoscalerRule(req *api_model.AutoscalerRuleReq) erro
 	if err != nil {
 		return err
 	}
+	if err := validateAutoscalerRule(rule.ServiceID, req); err != nil {
+		return err
+	}
 
 	rule.Enable = req.Enable
 	rule.XPAType = req.XPAType
@@ -2390,6 +2402,8 @@ func (s *ServiceAction) UpdAutoscalerRule(req *api_model.AutoscalerRuleReq) erro
 			MetricsName:       metric.MetricsName,
 			MetricTargetType:  metric.MetricTargetType,
 			MetricTargetValue: metric.MetricTargetValue,
+			MetricSelector:    metric.MetricSelector,
+			MetricObject:      metric.MetricObject,
 		}
 		if err := db.GetManager().TenantServceAutoscalerRuleMetricsDaoTransactions(tx).AddModel(m); err != nil {
 			tx.Rollback()
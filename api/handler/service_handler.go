@@ -55,6 +55,7 @@ type ServiceHandler interface {
 	PortInner(tenantName, serviceID, operation string, port int) error
 	VolumnVar(avs *dbmodel.TenantServiceVolume, tenantID, fileContent, action string) *util.APIHandleError
 	UpdVolume(sid string, req *api_model.UpdVolumeReq) error
+	ResizeVolume(tenantID, serviceID, volumeName, eventID string, capacity int64) error
 	VolumeDependency(tsr *dbmodel.TenantServiceMountRelation, action string) *util.APIHandleError
 	GetDepVolumes(serviceID string) ([]*dbmodel.TenantServiceMountRelation, *util.APIHandleError)
 	GetVolumes(serviceID string) ([]*api_model.VolumeWithStatusStruct, *util.APIHandleError)
@@ -83,6 +84,14 @@ type ServiceHandler interface {
 	AddAutoscalerRule(req *api_model.AutoscalerRuleReq) error
 	UpdAutoscalerRule(req *api_model.AutoscalerRuleReq) error
 	ListScalingRecords(serviceID string, page, pageSize int) ([]*dbmodel.TenantServiceScalingRecords, int, error)
+	AddScalingSchedule(req *api_model.ScalingScheduleReq) (*dbmodel.TenantServiceScalingSchedule, error)
+	UpdScalingSchedule(req *api_model.ScalingScheduleReq) (*dbmodel.TenantServiceScalingSchedule, error)
+	ListScalingSchedules(serviceID string) ([]*dbmodel.TenantServiceScalingSchedule, error)
+	DelScalingSchedule(serviceID, scheduleID string) error
+	RecommendResources(serviceID string) (*api_model.ResourceRecommendation, error)
+	GetResourcePolicy(serviceID string) (*dbmodel.TenantServiceResourcePolicy, error)
+	UpdResourcePolicy(req *api_model.ResourcePolicyReq) (*dbmodel.TenantServiceResourcePolicy, error)
+	StartResourceRecommender(ctx context.Context)
 
 	UpdateServiceMonitor(tenantID, serviceID, name string, update api_model.UpdateServiceMonitorRequestStruct) (*dbmodel.TenantServiceMonitor, error)
 	DeleteServiceMonitor(tenantID, serviceID, name string) (*dbmodel.TenantServiceMonitor, error)
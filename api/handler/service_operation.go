@@ -96,6 +96,17 @@ func (o *OperationHandler) build(batchOpReq model.ComponentOpReq) error {
 	if buildReq.CodeInfo.Cmd != "" {
 		version.Cmd = buildReq.CodeInfo.Cmd
 	}
+	if buildReq.Kind == model.FromCodeBuildKing {
+		version.CodeBranch = buildReq.CodeInfo.Branch
+	}
+	// record the triggering commit, the builder updates it with the commit actually built
+	if buildReq.CodeInfo.Commit != "" {
+		version.CodeVersion = buildReq.CodeInfo.Commit
+		version.CommitMsg = buildReq.CodeInfo.CommitMsg
+		if buildReq.CodeInfo.CommitAuthor != "" {
+			version.Author = buildReq.CodeInfo.CommitAuthor
+		}
+	}
 	if err = db.GetManager().VersionInfoDao().AddModel(&version); err != nil {
 		return err
 	}
@@ -345,6 +356,12 @@ func (o *OperationHandler) buildFromSourceCode(r *model.ComponentBuildReq, servi
 		body["user"] = r.CodeInfo.User
 		body["password"] = r.CodeInfo.Password
 	}
+	if r.CodeInfo.Commit != "" {
+		body["commit"] = r.CodeInfo.Commit
+	}
+	if r.CodeInfo.CloneOptions != nil {
+		body["clone_options"] = r.CodeInfo.CloneOptions
+	}
 	body["expire"] = 180
 	body["configs"] = r.Configs
 	return o.sendBuildTopic(service.ServiceID, "build_from_source_code", body)
@@ -0,0 +1,674 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/event"
+	"github.com/goodrain/rainbond/util"
+	typesv1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultOrchestrationLayerTimeout is the default time waiting for a layer of the orchestration to be ready
+const DefaultOrchestrationLayerTimeout = 300 * time.Second
+
+// orchestrationCheckInterval is the interval checking if the components of a layer are ready
+var orchestrationCheckInterval = 3 * time.Second
+
+// orchestrationHeartbeatInterval is the interval the running plan updates its heartbeat,
+// the unfinished plans without heartbeat for three intervals are failed by the sweeper.
+var orchestrationHeartbeatInterval = 30 * time.Second
+
+// orchestrationInterrupted is the reason of the components of the plans interrupted by the exit of the api
+const orchestrationInterrupted = "interrupted because the api running the orchestration exited"
+
+// dependencyGraph is the graph of the components and all the components they depend on, including the ones of other applications.
+type dependencyGraph struct {
+	// nodes is the sorted ids of components
+	nodes []string
+	// depends maps the component to the sorted ids of the components it depends on
+	depends map[string][]string
+}
+
+// newDependencyGraph creates the graph of the given components and the closure of their dependencies.
+func newDependencyGraph(serviceIDs []string) (*dependencyGraph, error) {
+	g := &dependencyGraph{depends: make(map[string][]string)}
+	seen := make(map[string]bool)
+	var frontier []string
+	for _, sid := range serviceIDs {
+		if !seen[sid] {
+			seen[sid] = true
+			frontier = append(frontier, sid)
+		}
+	}
+	edges := make(map[[2]string]bool)
+	for len(frontier) > 0 {
+		relations, err := db.GetManager().TenantServiceRelationDao().ListByServiceIDs(frontier)
+		if err != nil {
+			return nil, errors.WithMessage(err, "list relations")
+		}
+		frontier = nil
+		for _, relation := range relations {
+			edge := [2]string{relation.ServiceID, relation.DependServiceID}
+			if edges[edge] {
+				continue
+			}
+			edges[edge] = true
+			g.depends[relation.ServiceID] = append(g.depends[relation.ServiceID], relation.DependServiceID)
+			if !seen[relation.DependServiceID] {
+				seen[relation.DependServiceID] = true
+				frontier = append(frontier, relation.DependServiceID)
+			}
+		}
+	}
+	for sid := range seen {
+		g.nodes = append(g.nodes, sid)
+	}
+	sort.Strings(g.nodes)
+	for _, depends := range g.depends {
+		sort.Strings(depends)
+	}
+	return g, nil
+}
+
+// layers sorts the components topologically. The components of a layer only depend on the ones of the layers before it.
+func (g *dependencyGraph) layers() ([][]string, error) {
+	remaining := make(map[string]int, len(g.nodes))
+	dependents := make(map[string][]string)
+	var current []string
+	for _, sid := range g.nodes {
+		remaining[sid] = len(g.depends[sid])
+		for _, dep := range g.depends[sid] {
+			dependents[dep] = append(dependents[dep], sid)
+		}
+		if remaining[sid] == 0 {
+			current = append(current, sid)
+		}
+	}
+
+	var layers [][]string
+	var sorted int
+	for len(current) > 0 {
+		sort.Strings(current)
+		layers = append(layers, current)
+		sorted += len(current)
+		var next []string
+		for _, sid := range current {
+			for _, dependent := range dependents[sid] {
+				remaining[dependent]--
+				if remaining[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		current = next
+	}
+	if sorted < len(g.nodes) {
+		return nil, bcode.NewBadRequest("circular dependencies: " + strings.Join(g.cycle(remaining), " -> "))
+	}
+	return layers, nil
+}
+
+// cycle finds a cycle among the components which can not be sorted.
+// Every one of them depends on at least one unsorted component, so following them always ends in a cycle.
+func (g *dependencyGraph) cycle(remaining map[string]int) []string {
+	var sid string
+	for _, node := range g.nodes {
+		if remaining[node] > 0 {
+			sid = node
+			break
+		}
+	}
+	index := make(map[string]int)
+	var path []string
+	for {
+		if i, ok := index[sid]; ok {
+			return append(path[i:], sid)
+		}
+		index[sid] = len(path)
+		path = append(path, sid)
+		for _, dep := range g.depends[sid] {
+			if remaining[dep] > 0 {
+				sid = dep
+				break
+			}
+		}
+	}
+}
+
+// Orchestrate starts or upgrades the components layer by layer in the order of their dependencies across applications.
+// The components of a layer are operated in parallel once the components of the layers before it are ready.
+// The dependencies out of the request are not operated, but the components depending on them wait for them to be running.
+// The progress is reported through the parent event of the returned plan.
+func (b *BatchOperationHandler) Orchestrate(ctx context.Context, tenant *dbmodel.Tenants, operator, operation string, batchOpReqs model.BatchOpRequesters, layerTimeout time.Duration) (model.BatchOpResult, *model.OrchestrationPlan, error) {
+	if logrus.IsLevelEnabled(logrus.DebugLevel) {
+		defer util.Elapsed("[BatchOperationHandler] orchestrate components")()
+	}
+
+	var dispatch func(req model.ComponentOpReq) error
+	switch operation {
+	case "start":
+		dispatch = b.operationHandler.Start
+	case "upgrade":
+		dispatch = b.operationHandler.upgrade
+	default:
+		return nil, nil, bcode.NewBadRequest(fmt.Sprintf("operation %s do not support orchestration", operation))
+	}
+	if layerTimeout <= 0 {
+		layerTimeout = DefaultOrchestrationLayerTimeout
+	}
+
+	graph, err := newDependencyGraph(batchOpReqs.ComponentIDs())
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "new dependency graph")
+	}
+	layers, err := graph.layers()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// check allocatable memory
+	allocm, err := NewAllocMemory(ctx, b.statusCli, tenant, batchOpReqs)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "new alloc memory")
+	}
+	batchOpResult := allocm.BatchOpResult()
+	validReqs, batchOpResult2 := b.checkEvents(allocm.BatchOpRequests())
+	batchOpResult = append(batchOpResult, batchOpResult2...)
+
+	components, err := db.GetManager().TenantServiceDao().GetServiceByIDs(graph.nodes)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "list components")
+	}
+	plan := newOrchestrationPlan(operation, graph, layers, components, validReqs, batchOpResult)
+	// the components depending on the rejected ones are not operated
+	sid2req := make(map[string]model.ComponentOpReq, len(validReqs))
+	for _, req := range validReqs {
+		sid2req[req.GetComponentID()] = req
+	}
+	validReqs = nil
+	for _, c := range plan.Components {
+		req, ok := sid2req[c.ServiceID]
+		if !ok {
+			continue
+		}
+		if c.Status == model.OrchestrationComponentStatusSkipped {
+			item := req.BatchOpFailureItem()
+			item.ErrMsg = c.Message
+			batchOpResult = append(batchOpResult, item)
+			continue
+		}
+		validReqs = append(validReqs, req)
+	}
+
+	// the parent event is created first, so it is completed if the rest fails
+	parent := &dbmodel.ServiceEvent{
+		EventID:   plan.EventID,
+		TenantID:  tenant.UUID,
+		Target:    dbmodel.TargetTypeTenant,
+		TargetID:  tenant.UUID,
+		UserName:  operator,
+		StartTime: time.Now().Format(time.RFC3339),
+		SynType:   dbmodel.ASYNEVENTTYPE,
+		OptType:   "orchestrate-" + operation,
+	}
+	if err := db.GetManager().ServiceEventDao().AddModel(parent); err != nil {
+		return nil, nil, errors.WithMessage(err, "create parent event")
+	}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		finishEvent(plan.EventID, "failure", err.Error())
+		return nil, nil, err
+	}
+	record := &dbmodel.TenantOrchestration{
+		EventID:     plan.EventID,
+		TenantID:    tenant.UUID,
+		Operation:   operation,
+		Plan:        string(data),
+		HeartbeatAt: time.Now(),
+	}
+	if err := db.GetManager().TenantOrchestrationDao().AddModel(record); err != nil {
+		finishEvent(plan.EventID, "failure", err.Error())
+		return nil, nil, errors.WithMessage(err, "create orchestration")
+	}
+	// create events
+	if err := b.createEvents(tenant.UUID, operator, validReqs); err != nil {
+		finishEvent(plan.EventID, "failure", err.Error())
+		record.Finished = true
+		if err := db.GetManager().TenantOrchestrationDao().UpdateModel(record); err != nil {
+			logrus.Warningf("finish orchestration %s: %v", plan.EventID, err)
+		}
+		return nil, nil, err
+	}
+
+	o := &orchestration{
+		plan:       plan.DeepCopy(),
+		reqs:       make(map[string]model.ComponentOpReq),
+		thirdParty: make(map[string]bool),
+		stayClosed: make(map[string]bool),
+		timeout:    layerTimeout,
+		dispatch:   dispatch,
+		status:     b.statusCli.GetStatus,
+		closed:     b.statusCli.IsClosedStatus,
+		logger:     event.GetManager().GetLogger(plan.EventID),
+	}
+	for _, req := range validReqs {
+		o.reqs[req.GetComponentID()] = req
+	}
+	for _, component := range components {
+		if dbmodel.ServiceKind(component.Kind) == dbmodel.ServiceKindThirdParty {
+			o.thirdParty[component.ServiceID] = true
+		}
+	}
+	go func() {
+		o.run()
+		event.GetManager().ReleaseLogger(o.logger)
+	}()
+
+	return batchOpResult, plan, nil
+}
+
+// GetOrchestration returns the plan of the orchestration with the statuses of the components.
+func (b *BatchOperationHandler) GetOrchestration(tenantID, eventID string) (*model.OrchestrationPlan, error) {
+	record, err := db.GetManager().TenantOrchestrationDao().GetByEventID(tenantID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	var plan model.OrchestrationPlan
+	if err := json.Unmarshal([]byte(record.Plan), &plan); err != nil {
+		return nil, errors.Wrap(err, "decode plan")
+	}
+	return &plan, nil
+}
+
+// newOrchestrationPlan creates the plan of the layers. The requested components failed the checks are rejected
+// with the reason of the result, and the components depending on them are skipped. The components out of the
+// request are the external ones.
+func newOrchestrationPlan(operation string, graph *dependencyGraph, layers [][]string, components []*dbmodel.TenantServices,
+	validReqs model.BatchOpRequesters, results model.BatchOpResult) *model.OrchestrationPlan {
+	sid2component := make(map[string]*dbmodel.TenantServices, len(components))
+	for _, component := range components {
+		sid2component[component.ServiceID] = component
+	}
+	reqs := make(map[string]model.ComponentOpReq, len(validReqs))
+	for _, req := range validReqs {
+		reqs[req.GetComponentID()] = req
+	}
+
+	rejected := make(map[string]string)
+	for _, result := range results {
+		if result.Status == model.BatchOpResultItemStatusFailure {
+			rejected[result.ServiceID] = result.ErrMsg
+		}
+	}
+	// blocked is the rejected and skipped components
+	blocked := make(map[string]bool)
+
+	plan := &model.OrchestrationPlan{
+		EventID:   util.NewUUID(),
+		Operation: operation,
+		Layers:    len(layers),
+	}
+	for i, layer := range layers {
+		for _, sid := range layer {
+			oc := &model.OrchestrationComponent{
+				ServiceID: sid,
+				Layer:     i,
+				DependIDs: graph.depends[sid],
+				Status:    model.OrchestrationComponentStatusPending,
+			}
+			if component, ok := sid2component[sid]; ok {
+				oc.ServiceAlias = component.ServiceAlias
+				oc.AppID = component.AppID
+			}
+			if reason, ok := rejected[sid]; ok {
+				oc.Status = model.OrchestrationComponentStatusFailure
+				oc.Message = reason
+				blocked[sid] = true
+				plan.Components = append(plan.Components, oc)
+				continue
+			}
+			for _, dep := range graph.depends[sid] {
+				if blocked[dep] {
+					oc.Status = model.OrchestrationComponentStatusSkipped
+					oc.Message = "skipped because of the rejection of its dependencies"
+					blocked[sid] = true
+					break
+				}
+			}
+			if req, ok := reqs[sid]; ok {
+				if !blocked[sid] {
+					oc.EventID = req.GetEventID()
+				}
+				// the dependencies are also waited by the init container of the component
+				req.UpdateConfig("boot_seq_dep_service_ids", strings.Join(graph.depends[sid], ","))
+			} else {
+				oc.External = true
+			}
+			plan.Components = append(plan.Components, oc)
+		}
+	}
+	return plan
+}
+
+// orchestration runs the plan of orchestration.
+type orchestration struct {
+	plan       *model.OrchestrationPlan
+	reqs       map[string]model.ComponentOpReq
+	thirdParty map[string]bool
+	// stayClosed is the closed components being upgraded, they are ready once the upgrade is completed
+	stayClosed map[string]bool
+	timeout    time.Duration
+	// savedAt is the last time the plan is saved, finished is set once the plan is completed
+	savedAt  time.Time
+	finished bool
+
+	dispatch func(req model.ComponentOpReq) error
+	status   func(serviceID string) string
+	closed   func(status string) bool
+	logger   event.Logger
+}
+
+func (o *orchestration) run() {
+	var failure string
+	for i := 0; i < o.plan.Layers; i++ {
+		components := o.layer(i)
+		if failure != "" {
+			o.skip(components)
+			o.save()
+			continue
+		}
+		o.logger.Info(fmt.Sprintf("layer %d: %s", i, strings.Join(componentNames(components), ", ")), event.GetLoggerOption("starting"))
+		failure = o.dispatchLayer(components)
+		o.save()
+		if failure == "" {
+			failure = o.waitReady(i, components)
+			o.save()
+		}
+	}
+
+	if failure == "" {
+		var rejected []*model.OrchestrationComponent
+		for _, c := range o.plan.Components {
+			if settled(c) {
+				rejected = append(rejected, c)
+			}
+		}
+		if len(rejected) > 0 {
+			failure = fmt.Sprintf("%s are rejected or skipped", strings.Join(componentNames(rejected), ", "))
+		}
+	}
+	status, message := "success", fmt.Sprintf("%d layers are ready", o.plan.Layers)
+	if failure != "" {
+		status, message = "failure", failure
+		o.logger.Error(message, event.GetCallbackLoggerOption())
+	} else {
+		o.logger.Info(message, event.GetLastLoggerOption())
+	}
+	finishEvent(o.plan.EventID, status, message)
+	o.finished = true
+	o.save()
+}
+
+// dispatchLayer operates the components of the layer, stops at the first failure and skips the rest of the layer.
+func (o *orchestration) dispatchLayer(components []*model.OrchestrationComponent) string {
+	for j, c := range components {
+		if c.External || settled(c) {
+			continue
+		}
+		if o.plan.Operation == "upgrade" && o.closed(o.status(c.ServiceID)) {
+			o.stayClosed[c.ServiceID] = true
+		}
+		c.Status = model.OrchestrationComponentStatusOperating
+		if err := o.dispatch(o.reqs[c.ServiceID]); err != nil {
+			c.Status = model.OrchestrationComponentStatusFailure
+			finishEvent(c.EventID, "failure", err.Error())
+			failure := fmt.Sprintf("%s %s: %v", o.plan.Operation, componentName(c), err)
+			o.logger.Error(failure, event.GetLoggerOption("failure"))
+			if rest := components[j+1:]; len(rest) > 0 {
+				o.skip(rest)
+			}
+			return failure
+		}
+	}
+	return ""
+}
+
+// save persists the statuses of the components and the heartbeat, so the progress is kept after the api restarts.
+func (o *orchestration) save() {
+	o.savedAt = time.Now()
+	data, err := json.Marshal(o.plan)
+	if err != nil {
+		logrus.Warningf("encode plan %s: %v", o.plan.EventID, err)
+		return
+	}
+	record := &dbmodel.TenantOrchestration{EventID: o.plan.EventID, Plan: string(data), Finished: o.finished, HeartbeatAt: o.savedAt}
+	if err := db.GetManager().TenantOrchestrationDao().UpdateModel(record); err != nil {
+		logrus.Warningf("save plan %s: %v", o.plan.EventID, err)
+	}
+}
+
+func (o *orchestration) layer(i int) []*model.OrchestrationComponent {
+	var components []*model.OrchestrationComponent
+	for _, c := range o.plan.Components {
+		if c.Layer == i {
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// skip skips the components after a failed layer and completes their events.
+func (o *orchestration) skip(components []*model.OrchestrationComponent) {
+	var skipped []*model.OrchestrationComponent
+	for _, c := range components {
+		if settled(c) {
+			continue
+		}
+		c.Status = model.OrchestrationComponentStatusSkipped
+		if !c.External {
+			finishEvent(c.EventID, "failure", "skipped because of the failure of its dependencies")
+		}
+		skipped = append(skipped, c)
+	}
+	if len(skipped) > 0 {
+		o.logger.Info(fmt.Sprintf("skip %s", strings.Join(componentNames(skipped), ", ")), event.GetLoggerOption("failure"))
+	}
+}
+
+// settled reports whether the component is rejected or skipped already.
+func settled(c *model.OrchestrationComponent) bool {
+	return c.Status == model.OrchestrationComponentStatusFailure || c.Status == model.OrchestrationComponentStatusSkipped
+}
+
+// waitReady waits for the components to be ready, returns the reason if any of them fails or the layer timed out.
+func (o *orchestration) waitReady(i int, components []*model.OrchestrationComponent) string {
+	deadline := time.Now().Add(o.timeout)
+	for {
+		var pending []*model.OrchestrationComponent
+		for _, c := range components {
+			if c.Status == model.OrchestrationComponentStatusReady || settled(c) {
+				continue
+			}
+			ready, err := o.ready(c)
+			if err != nil {
+				c.Status = model.OrchestrationComponentStatusFailure
+				failure := fmt.Sprintf("%s: %v", componentName(c), err)
+				o.logger.Error(failure, event.GetLoggerOption("failure"))
+				return failure
+			}
+			if !ready {
+				pending = append(pending, c)
+				continue
+			}
+			c.Status = model.OrchestrationComponentStatusReady
+			o.logger.Info(fmt.Sprintf("%s is ready", componentName(c)), event.GetLoggerOption("running"))
+		}
+		if len(pending) == 0 {
+			return ""
+		}
+		if time.Now().After(deadline) {
+			for _, c := range pending {
+				c.Status = model.OrchestrationComponentStatusFailure
+			}
+			failure := fmt.Sprintf("layer %d is not ready in %s: %s", i, o.timeout, strings.Join(componentNames(pending), ", "))
+			o.logger.Error(failure, event.GetTimeoutLoggerOption())
+			return failure
+		}
+		if time.Since(o.savedAt) >= orchestrationHeartbeatInterval {
+			o.save()
+		}
+		time.Sleep(orchestrationCheckInterval)
+	}
+}
+
+// ready checks if the component is ready.
+// The external one is ready if it is running. The operated one is ready if its event succeeded and it is running.
+func (o *orchestration) ready(c *model.OrchestrationComponent) (bool, error) {
+	if c.External {
+		status := o.status(c.ServiceID)
+		if status == typesv1.RUNNING {
+			return true, nil
+		}
+		if o.closed(status) {
+			return false, fmt.Errorf("the dependency is not running")
+		}
+		return false, nil
+	}
+	// the upgrade of third-party components does not send any task
+	if o.thirdParty[c.ServiceID] && o.plan.Operation == "upgrade" {
+		return true, nil
+	}
+
+	evt, err := db.GetManager().ServiceEventDao().GetEventByEventID(c.EventID)
+	if err != nil {
+		logrus.Warningf("get event %s: %v", c.EventID, err)
+		return false, nil
+	}
+	if evt.FinalStatus == "" {
+		return false, nil
+	}
+	if evt.Status != "success" {
+		return false, fmt.Errorf("%s %s", o.plan.Operation, evt.Status)
+	}
+	if o.thirdParty[c.ServiceID] || o.stayClosed[c.ServiceID] {
+		return true, nil
+	}
+	return o.status(c.ServiceID) == typesv1.RUNNING, nil
+}
+
+// StartOrchestrationSweeper fails the plans interrupted by the exit of the api running them until the ctx is done.
+func (b *BatchOperationHandler) StartOrchestrationSweeper(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(orchestrationHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			b.sweepOrchestrations(time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sweepOrchestrations fails the unfinished plans without heartbeat. The components not ready are failed,
+// the events of the ones not operated and the parent event are completed.
+func (b *BatchOperationHandler) sweepOrchestrations(now time.Time) {
+	records, err := db.GetManager().TenantOrchestrationDao().ListStale(now.Add(-3 * orchestrationHeartbeatInterval))
+	if err != nil {
+		logrus.Errorf("list stale orchestrations: %v", err)
+		return
+	}
+	for _, record := range records {
+		var plan model.OrchestrationPlan
+		if err := json.Unmarshal([]byte(record.Plan), &plan); err != nil {
+			logrus.Warningf("decode plan %s: %v", record.EventID, err)
+		}
+		for _, c := range plan.Components {
+			if c.Status != model.OrchestrationComponentStatusPending && c.Status != model.OrchestrationComponentStatusOperating {
+				continue
+			}
+			// the events of the operating components are completed by the worker
+			if c.Status == model.OrchestrationComponentStatusPending && !c.External && c.EventID != "" {
+				finishEvent(c.EventID, "failure", orchestrationInterrupted)
+			}
+			c.Status = model.OrchestrationComponentStatusFailure
+			c.Message = orchestrationInterrupted
+		}
+		finishEvent(record.EventID, "failure", orchestrationInterrupted)
+		if data, err := json.Marshal(&plan); err == nil {
+			record.Plan = string(data)
+		}
+		record.Finished = true
+		record.HeartbeatAt = now
+		if err := db.GetManager().TenantOrchestrationDao().UpdateModel(record); err != nil {
+			logrus.Errorf("finish orchestration %s: %v", record.EventID, err)
+			continue
+		}
+		logrus.Infof("orchestration %s is %s", record.EventID, orchestrationInterrupted)
+	}
+}
+
+// finishEvent completes the event with the given status.
+func finishEvent(eventID, status, message string) {
+	evt, err := db.GetManager().ServiceEventDao().GetEventByEventID(eventID)
+	if err != nil {
+		logrus.Errorf("get event %s: %v", eventID, err)
+		return
+	}
+	if evt.FinalStatus != "" {
+		return
+	}
+	evt.FinalStatus = "complete"
+	evt.Status = status
+	evt.Message = message
+	evt.EndTime = time.Now().Format(time.RFC3339)
+	if err := db.GetManager().ServiceEventDao().UpdateModel(evt); err != nil {
+		logrus.Errorf("update event %s: %v", eventID, err)
+	}
+}
+
+func componentName(c *model.OrchestrationComponent) string {
+	if c.ServiceAlias != "" {
+		return c.ServiceAlias
+	}
+	return c.ServiceID
+}
+
+func componentNames(components []*model.OrchestrationComponent) []string {
+	names := make([]string, 0, len(components))
+	for _, c := range components {
+		names = append(names, componentName(c))
+	}
+	return names
+}
@@ -0,0 +1,465 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/db"
+	daomock "github.com/goodrain/rainbond/db/dao"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/event"
+	typesv1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+)
+
+func mockRelations(ctrl *gomock.Controller, manager *db.MockManager, relations map[string][]string) {
+	relationDao := daomock.NewMockTenantServiceRelationDao(ctrl)
+	relationDao.EXPECT().ListByServiceIDs(gomock.Any()).DoAndReturn(func(sids []string) ([]*dbmodel.TenantServiceRelation, error) {
+		var res []*dbmodel.TenantServiceRelation
+		for _, sid := range sids {
+			for _, dep := range relations[sid] {
+				res = append(res, &dbmodel.TenantServiceRelation{ServiceID: sid, DependServiceID: dep})
+			}
+		}
+		return res, nil
+	}).AnyTimes()
+	manager.EXPECT().TenantServiceRelationDao().Return(relationDao).AnyTimes()
+}
+
+func TestDependencyGraphLayers(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceIDs []string
+		relations  map[string][]string
+		want       [][]string
+		wantErr    string
+	}{
+		{
+			name:       "dependencies across applications",
+			serviceIDs: []string{"b", "a"},
+			relations: map[string][]string{
+				"a": {"c", "c"},
+				"b": {"c"},
+				"c": {"d"},
+			},
+			want: [][]string{{"d"}, {"c"}, {"a", "b"}},
+		},
+		{
+			name:       "no dependencies",
+			serviceIDs: []string{"b", "a"},
+			want:       [][]string{{"a", "b"}},
+		},
+		{
+			name:       "circular dependencies",
+			serviceIDs: []string{"a"},
+			relations: map[string][]string{
+				"a": {"b"},
+				"b": {"c"},
+				"c": {"b"},
+			},
+			wantErr: "circular dependencies: b -> c -> b",
+		},
+		{
+			name:       "depends on itself",
+			serviceIDs: []string{"a"},
+			relations: map[string][]string{
+				"a": {"a"},
+			},
+			wantErr: "circular dependencies: a -> a",
+		},
+	}
+	for i := range tests {
+		tc := tests[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			manager := db.NewMockManager(ctrl)
+			db.SetTestManager(manager)
+			mockRelations(ctrl, manager, tc.relations)
+
+			graph, err := newDependencyGraph(tc.serviceIDs)
+			if err != nil {
+				t.Fatalf("new dependency graph: %v", err)
+			}
+			layers, err := graph.layers()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("want error %q, but got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("layers: %v", err)
+			}
+			if !reflect.DeepEqual(layers, tc.want) {
+				t.Errorf("want layers %v, but got %v", tc.want, layers)
+			}
+		})
+	}
+}
+
+func TestNewOrchestrationPlanRejected(t *testing.T) {
+	// a -> b -> c, c is rejected, d does not depend on it
+	graph := &dependencyGraph{
+		nodes:   []string{"a", "b", "c", "d"},
+		depends: map[string][]string{"a": {"b"}, "b": {"c"}},
+	}
+	layers, err := graph.layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var validReqs model.BatchOpRequesters
+	for _, sid := range []string{"a", "b", "d"} {
+		validReqs = append(validReqs, &model.ComponentStartReq{ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: sid, EventID: "event-" + sid}})
+	}
+	results := model.BatchOpResult{
+		{ServiceID: "c", Status: model.BatchOpResultItemStatusFailure, ErrMsg: "insufficient memory"},
+	}
+	plan := newOrchestrationPlan("start", graph, layers, nil, validReqs, results)
+
+	want := map[string]model.OrchestrationComponentStatus{
+		"a": model.OrchestrationComponentStatusSkipped,
+		"b": model.OrchestrationComponentStatusSkipped,
+		"c": model.OrchestrationComponentStatusFailure,
+		"d": model.OrchestrationComponentStatusPending,
+	}
+	for _, c := range plan.Components {
+		if c.External {
+			t.Errorf("%s should not be external", c.ServiceID)
+		}
+		if c.Status != want[c.ServiceID] {
+			t.Errorf("want status %s of %s, but got %s", want[c.ServiceID], c.ServiceID, c.Status)
+		}
+		if c.ServiceID == "c" && c.Message != "insufficient memory" {
+			t.Errorf("want the reason of the rejection, but got %q", c.Message)
+		}
+		if c.ServiceID != "d" && c.EventID != "" {
+			t.Errorf("%s is not operated, but got event %s", c.ServiceID, c.EventID)
+		}
+	}
+}
+
+// mockOrchestrationDao decodes the saved plans into saved.
+func mockOrchestrationDao(ctrl *gomock.Controller, manager *db.MockManager, saved *model.OrchestrationPlan) {
+	orchestrationDao := daomock.NewMockTenantOrchestrationDao(ctrl)
+	orchestrationDao.EXPECT().UpdateModel(gomock.Any()).DoAndReturn(func(mo dbmodel.Interface) error {
+		*saved = model.OrchestrationPlan{}
+		return json.Unmarshal([]byte(mo.(*dbmodel.TenantOrchestration).Plan), saved)
+	}).AnyTimes()
+	manager.EXPECT().TenantOrchestrationDao().Return(orchestrationDao).AnyTimes()
+}
+
+func TestOrchestrationRun(t *testing.T) {
+	interval := orchestrationCheckInterval
+	orchestrationCheckInterval = time.Millisecond
+	defer func() { orchestrationCheckInterval = interval }()
+
+	tests := []struct {
+		name string
+		// externalStatus is the status of the dependency out of the request
+		externalStatus string
+		// results are the final status of the events of operated components
+		results    map[string]string
+		timeout    time.Duration
+		want       map[string]model.OrchestrationComponentStatus
+		wantStatus string
+	}{
+		{
+			name:           "all layers are ready",
+			externalStatus: typesv1.RUNNING,
+			results:        map[string]string{"c": "success", "a": "success"},
+			timeout:        time.Second,
+			want: map[string]model.OrchestrationComponentStatus{
+				"d": model.OrchestrationComponentStatusReady,
+				"c": model.OrchestrationComponentStatusReady,
+				"a": model.OrchestrationComponentStatusReady,
+			},
+			wantStatus: "success",
+		},
+		{
+			name:           "the dependency failed to start",
+			externalStatus: typesv1.RUNNING,
+			results:        map[string]string{"c": "failure", "a": "success"},
+			timeout:        time.Second,
+			want: map[string]model.OrchestrationComponentStatus{
+				"d": model.OrchestrationComponentStatusReady,
+				"c": model.OrchestrationComponentStatusFailure,
+				"a": model.OrchestrationComponentStatusSkipped,
+			},
+			wantStatus: "failure",
+		},
+		{
+			name:           "the external dependency is closed",
+			externalStatus: typesv1.CLOSED,
+			results:        map[string]string{"c": "success", "a": "success"},
+			timeout:        time.Second,
+			want: map[string]model.OrchestrationComponentStatus{
+				"d": model.OrchestrationComponentStatusFailure,
+				"c": model.OrchestrationComponentStatusSkipped,
+				"a": model.OrchestrationComponentStatusSkipped,
+			},
+			wantStatus: "failure",
+		},
+		{
+			name:           "the layer timed out",
+			externalStatus: typesv1.RUNNING,
+			results:        map[string]string{"a": "success"},
+			timeout:        20 * time.Millisecond,
+			want: map[string]model.OrchestrationComponentStatus{
+				"d": model.OrchestrationComponentStatusReady,
+				"c": model.OrchestrationComponentStatusFailure,
+				"a": model.OrchestrationComponentStatusSkipped,
+			},
+			wantStatus: "failure",
+		},
+	}
+	for i := range tests {
+		tc := tests[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			events := map[string]*dbmodel.ServiceEvent{
+				"parent":  {EventID: "parent"},
+				"event-c": {EventID: "event-c"},
+				"event-a": {EventID: "event-a"},
+			}
+			manager := db.NewMockManager(ctrl)
+			db.SetTestManager(manager)
+			eventDao := daomock.NewMockEventDao(ctrl)
+			eventDao.EXPECT().GetEventByEventID(gomock.Any()).DoAndReturn(func(eventID string) (*dbmodel.ServiceEvent, error) {
+				evt, ok := events[eventID]
+				if !ok {
+					return nil, fmt.Errorf("event %s not found", eventID)
+				}
+				copied := *evt
+				return &copied, nil
+			}).AnyTimes()
+			eventDao.EXPECT().UpdateModel(gomock.Any()).DoAndReturn(func(mo dbmodel.Interface) error {
+				evt := mo.(*dbmodel.ServiceEvent)
+				events[evt.EventID] = evt
+				return nil
+			}).AnyTimes()
+			manager.EXPECT().ServiceEventDao().Return(eventDao).AnyTimes()
+			var saved model.OrchestrationPlan
+			mockOrchestrationDao(ctrl, manager, &saved)
+
+			status := map[string]string{"d": tc.externalStatus, "c": typesv1.CLOSED, "a": typesv1.CLOSED}
+			o := &orchestration{
+				plan: &model.OrchestrationPlan{
+					EventID:   "parent",
+					Operation: "start",
+					Layers:    3,
+					Components: []*model.OrchestrationComponent{
+						{ServiceID: "d", Layer: 0, External: true, Status: model.OrchestrationComponentStatusPending},
+						{ServiceID: "c", Layer: 1, DependIDs: []string{"d"}, EventID: "event-c", Status: model.OrchestrationComponentStatusPending},
+						{ServiceID: "a", Layer: 2, DependIDs: []string{"c"}, EventID: "event-a", Status: model.OrchestrationComponentStatusPending},
+					},
+				},
+				reqs: map[string]model.ComponentOpReq{
+					"c": &model.ComponentStartReq{ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: "c", EventID: "event-c"}},
+					"a": &model.ComponentStartReq{ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: "a", EventID: "event-a"}},
+				},
+				thirdParty: map[string]bool{},
+				stayClosed: map[string]bool{},
+				timeout:    tc.timeout,
+				dispatch: func(req model.ComponentOpReq) error {
+					sid := req.GetComponentID()
+					if tc.results[sid] == "" {
+						return nil
+					}
+					evt := events[req.GetEventID()]
+					evt.FinalStatus = "complete"
+					evt.Status = tc.results[sid]
+					if evt.Status == "success" {
+						status[sid] = typesv1.RUNNING
+					}
+					return nil
+				},
+				status: func(sid string) string {
+					return status[sid]
+				},
+				closed: func(s string) bool {
+					return s == "" || s == typesv1.CLOSED
+				},
+				logger: event.GetTestLogger(),
+			}
+			o.run()
+
+			for _, c := range o.plan.Components {
+				if c.Status != tc.want[c.ServiceID] {
+					t.Errorf("want status %s of %s, but got %s", tc.want[c.ServiceID], c.ServiceID, c.Status)
+				}
+			}
+			if !reflect.DeepEqual(&saved, o.plan) {
+				t.Errorf("want the statuses saved, but got %+v", saved.Components)
+			}
+			parent := events["parent"]
+			if parent.FinalStatus != "complete" || parent.Status != tc.wantStatus {
+				t.Errorf("want parent event %s, but got %s/%s: %s", tc.wantStatus, parent.FinalStatus, parent.Status, parent.Message)
+			}
+			if tc.want["a"] == model.OrchestrationComponentStatusSkipped && events["event-a"].FinalStatus != "complete" {
+				t.Errorf("want the event of skipped component completed")
+			}
+		})
+	}
+}
+
+func TestOrchestrationDispatchFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := map[string]*dbmodel.ServiceEvent{
+		"parent":  {EventID: "parent"},
+		"event-b": {EventID: "event-b"},
+		"event-c": {EventID: "event-c"},
+		"event-a": {EventID: "event-a"},
+	}
+	manager := db.NewMockManager(ctrl)
+	db.SetTestManager(manager)
+	eventDao := daomock.NewMockEventDao(ctrl)
+	eventDao.EXPECT().GetEventByEventID(gomock.Any()).DoAndReturn(func(eventID string) (*dbmodel.ServiceEvent, error) {
+		copied := *events[eventID]
+		return &copied, nil
+	}).AnyTimes()
+	eventDao.EXPECT().UpdateModel(gomock.Any()).DoAndReturn(func(mo dbmodel.Interface) error {
+		evt := mo.(*dbmodel.ServiceEvent)
+		events[evt.EventID] = evt
+		return nil
+	}).AnyTimes()
+	manager.EXPECT().ServiceEventDao().Return(eventDao).AnyTimes()
+	var saved model.OrchestrationPlan
+	mockOrchestrationDao(ctrl, manager, &saved)
+
+	var dispatched []string
+	o := &orchestration{
+		plan: &model.OrchestrationPlan{
+			EventID:   "parent",
+			Operation: "start",
+			Layers:    2,
+			Components: []*model.OrchestrationComponent{
+				{ServiceID: "b", Layer: 0, EventID: "event-b", Status: model.OrchestrationComponentStatusPending},
+				{ServiceID: "c", Layer: 0, EventID: "event-c", Status: model.OrchestrationComponentStatusPending},
+				{ServiceID: "a", Layer: 1, DependIDs: []string{"b", "c"}, EventID: "event-a", Status: model.OrchestrationComponentStatusPending},
+			},
+		},
+		reqs: map[string]model.ComponentOpReq{
+			"b": &model.ComponentStartReq{ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: "b", EventID: "event-b"}},
+			"c": &model.ComponentStartReq{ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: "c", EventID: "event-c"}},
+			"a": &model.ComponentStartReq{ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: "a", EventID: "event-a"}},
+		},
+		thirdParty: map[string]bool{},
+		stayClosed: map[string]bool{},
+		timeout:    time.Second,
+		dispatch: func(req model.ComponentOpReq) error {
+			dispatched = append(dispatched, req.GetComponentID())
+			return errors.New("insufficient memory")
+		},
+		status: func(sid string) string { return typesv1.CLOSED },
+		closed: func(s string) bool { return s == typesv1.CLOSED },
+		logger: event.GetTestLogger(),
+	}
+	o.run()
+
+	if !reflect.DeepEqual(dispatched, []string{"b"}) {
+		t.Errorf("want the layer stopped at the first failure, but dispatched %v", dispatched)
+	}
+	want := map[string]model.OrchestrationComponentStatus{
+		"b": model.OrchestrationComponentStatusFailure,
+		"c": model.OrchestrationComponentStatusSkipped,
+		"a": model.OrchestrationComponentStatusSkipped,
+	}
+	for _, c := range saved.Components {
+		if c.Status != want[c.ServiceID] {
+			t.Errorf("want saved status %s of %s, but got %s", want[c.ServiceID], c.ServiceID, c.Status)
+		}
+	}
+	for eventID, evt := range events {
+		if evt.FinalStatus != "complete" || evt.Status != "failure" {
+			t.Errorf("want event %s completed with failure, but got %s/%s", eventID, evt.FinalStatus, evt.Status)
+		}
+	}
+}
+
+func TestSweepOrchestrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := map[string]*dbmodel.ServiceEvent{
+		"parent":  {EventID: "parent"},
+		"event-c": {EventID: "event-c"},
+		"event-a": {EventID: "event-a"},
+	}
+	manager := db.NewMockManager(ctrl)
+	db.SetTestManager(manager)
+	eventDao := daomock.NewMockEventDao(ctrl)
+	eventDao.EXPECT().GetEventByEventID(gomock.Any()).DoAndReturn(func(eventID string) (*dbmodel.ServiceEvent, error) {
+		copied := *events[eventID]
+		return &copied, nil
+	}).AnyTimes()
+	eventDao.EXPECT().UpdateModel(gomock.Any()).DoAndReturn(func(mo dbmodel.Interface) error {
+		evt := mo.(*dbmodel.ServiceEvent)
+		events[evt.EventID] = evt
+		return nil
+	}).AnyTimes()
+	manager.EXPECT().ServiceEventDao().Return(eventDao).AnyTimes()
+
+	plan := &model.OrchestrationPlan{
+		EventID:   "parent",
+		Operation: "start",
+		Layers:    3,
+		Components: []*model.OrchestrationComponent{
+			{ServiceID: "d", Layer: 0, External: true, Status: model.OrchestrationComponentStatusReady},
+			{ServiceID: "c", Layer: 1, EventID: "event-c", Status: model.OrchestrationComponentStatusOperating},
+			{ServiceID: "a", Layer: 2, EventID: "event-a", Status: model.OrchestrationComponentStatusPending},
+		},
+	}
+	data, _ := json.Marshal(plan)
+	now := time.Now()
+	var updated *dbmodel.TenantOrchestration
+	orchestrationDao := daomock.NewMockTenantOrchestrationDao(ctrl)
+	orchestrationDao.EXPECT().ListStale(now.Add(-3*orchestrationHeartbeatInterval)).Return([]*dbmodel.TenantOrchestration{
+		{EventID: "parent", Plan: string(data)},
+	}, nil)
+	orchestrationDao.EXPECT().UpdateModel(gomock.Any()).DoAndReturn(func(mo dbmodel.Interface) error {
+		updated = mo.(*dbmodel.TenantOrchestration)
+		return nil
+	})
+	manager.EXPECT().TenantOrchestrationDao().Return(orchestrationDao).AnyTimes()
+
+	(&BatchOperationHandler{}).sweepOrchestrations(now)
+
+	if updated == nil || !updated.Finished {
+		t.Fatalf("want the orchestration finished")
+	}
+	var saved model.OrchestrationPlan
+	if err := json.Unmarshal([]byte(updated.Plan), &saved); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]model.OrchestrationComponentStatus{
+		"d": model.OrchestrationComponentStatusReady,
+		"c": model.OrchestrationComponentStatusFailure,
+		"a": model.OrchestrationComponentStatusFailure,
+	}
+	for _, c := range saved.Components {
+		if c.Status != want[c.ServiceID] {
+			t.Errorf("want status %s of %s, but got %s", want[c.ServiceID], c.ServiceID, c.Status)
+		}
+	}
+	if events["parent"].FinalStatus != "complete" || events["parent"].Status != "failure" {
+		t.Errorf("want the parent event failed, but got %s/%s", events["parent"].FinalStatus, events["parent"].Status)
+	}
+	if events["event-a"].FinalStatus != "complete" {
+		t.Errorf("want the event of the pending component completed")
+	}
+	if events["event-c"].FinalStatus != "" {
+		t.Errorf("the event of the operating component is completed by the worker")
+	}
+}
@@ -0,0 +1,109 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/cmd/api/option"
+	"github.com/goodrain/rainbond/util/terminal"
+	"github.com/goodrain/rainbond/worker/server"
+	"github.com/pkg/errors"
+)
+
+// TerminalHandler issues terminal tickets and reads terminal session records.
+type TerminalHandler interface {
+	CreateTicket(tenantID, serviceID, podName string, req *model.TerminalTicketReq) (*model.TerminalTicket, error)
+	ListSessions(tenantID, serviceID string) ([]*terminal.SessionMeta, error)
+	GetSession(tenantID, serviceID, sessionID string) (*terminal.SessionMeta, error)
+	OpenSessionCast(tenantID, serviceID, sessionID string) (*os.File, error)
+}
+
+// NewTerminalHandler creates a new TerminalHandler.
+func NewTerminalHandler(conf option.Config) TerminalHandler {
+	return &TerminalAction{
+		secret:    []byte(conf.TerminalTicketSecret),
+		recordDir: conf.TerminalRecordDir,
+	}
+}
+
+// TerminalAction is an implementation of TerminalHandler
+type TerminalAction struct {
+	secret    []byte
+	recordDir string
+}
+
+// CreateTicket creates a signed ticket bound to the user, pod and container.
+func (t *TerminalAction) CreateTicket(tenantID, serviceID, podName string, req *model.TerminalTicketReq) (*model.TerminalTicket, error) {
+	if len(t.secret) == 0 {
+		return nil, bcode.ErrTerminalTicketDisabled
+	}
+	if _, err := GetPodHandler().PodDetail(serviceID, podName); err != nil {
+		if err == server.ErrPodNotFound {
+			return nil, bcode.NewBadRequest(fmt.Sprintf("pod %s not found in the component", podName))
+		}
+		return nil, errors.Wrap(err, "get pod detail")
+	}
+	ticket := terminal.NewTicket(req.User, tenantID, serviceID, podName, req.ContainerName, time.Duration(req.TTL)*time.Second)
+	token, err := ticket.Sign(t.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "sign terminal ticket")
+	}
+	return &model.TerminalTicket{
+		Ticket:    token,
+		ExpiresAt: ticket.Expiration(),
+	}, nil
+}
+
+// ListSessions lists the terminal session records of the component.
+func (t *TerminalAction) ListSessions(tenantID, serviceID string) ([]*terminal.SessionMeta, error) {
+	sessions, err := terminal.ListSessions(t.recordDir, tenantID, serviceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list terminal sessions")
+	}
+	return sessions, nil
+}
+
+// GetSession returns the metadata of the terminal session record.
+func (t *TerminalAction) GetSession(tenantID, serviceID, sessionID string) (*terminal.SessionMeta, error) {
+	session, err := terminal.GetSession(t.recordDir, tenantID, serviceID, sessionID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, bcode.ErrTerminalSessionNotFound
+		}
+		return nil, errors.Wrap(err, "get terminal session")
+	}
+	return session, nil
+}
+
+// OpenSessionCast opens the asciicast file of the terminal session record.
+func (t *TerminalAction) OpenSessionCast(tenantID, serviceID, sessionID string) (*os.File, error) {
+	file, err := terminal.OpenSessionCast(t.recordDir, tenantID, serviceID, sessionID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, bcode.ErrTerminalSessionNotFound
+		}
+		return nil, errors.Wrap(err, "open terminal session cast")
+	}
+	return file, nil
+}
@@ -0,0 +1,110 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	gclient "github.com/goodrain/rainbond/mq/client"
+	"github.com/goodrain/rainbond/worker/discover/model"
+	workerutil "github.com/goodrain/rainbond/worker/util"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+//checkVolumeResize checks whether the volume can be expanded to the given capacity.
+//Volumes of the rainbond storage classes can always be expanded, other volumes
+//depend on the allowVolumeExpansion of their storage class.
+func checkVolumeResize(volume *dbmodel.TenantServiceVolume, volumeType *dbmodel.TenantServiceVolumeType, capacity int64) error {
+	switch volume.VolumeType {
+	case dbmodel.ConfigFileVolumeType.String(), dbmodel.MemoryFSVolumeType.String():
+		return bcode.ErrVolumeExpansionNotAllowed
+	}
+	if capacity <= volume.VolumeCapacity {
+		return bcode.ErrVolumeCapacityNotIncreased
+	}
+	switch volume.VolumeType {
+	case dbmodel.ShareFileVolumeType.String(), dbmodel.LocalVolumeType.String():
+		return nil
+	}
+	if volumeType == nil || !storageClassAllowsExpansion(volumeType.StorageClassDetail) {
+		return bcode.ErrVolumeExpansionNotAllowed
+	}
+	if volumeType.CapacityValidation != "" {
+		if err := workerutil.ValidateVolumeCapacity(volumeType.CapacityValidation, capacity); err != nil {
+			return bcode.NewBadRequest(err.Error())
+		}
+	}
+	return nil
+}
+
+//storageClassAllowsExpansion parses the storage class detail of a volume type
+func storageClassAllowsExpansion(detail string) bool {
+	if detail == "" {
+		return false
+	}
+	var sc storagev1.StorageClass
+	if err := json.Unmarshal([]byte(detail), &sc); err != nil {
+		logrus.Warningf("unmarshal storage class detail: %v", err)
+		return false
+	}
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+}
+
+// ResizeVolume expands the capacity of a component volume. The claims of the volume
+// are resized by the worker, and the progress is reported to the event.
+func (s *ServiceAction) ResizeVolume(tenantID, serviceID, volumeName, eventID string, capacity int64) error {
+	volume, err := db.GetManager().TenantServiceVolumeDao().GetVolumeByServiceIDAndName(serviceID, volumeName)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return bcode.ErrVolumeNotFound
+		}
+		return err
+	}
+	volumeType, err := db.GetManager().VolumeTypeDao().GetVolumeTypeByType(volume.VolumeType)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	if err := checkVolumeResize(volume, volumeType, capacity); err != nil {
+		return err
+	}
+
+	err = s.MQClient.SendBuilderTopic(gclient.TaskStruct{
+		TaskType: "resize_volume",
+		TaskBody: model.ResizeVolumeTaskBody{
+			TenantID:   tenantID,
+			ServiceID:  serviceID,
+			VolumeID:   int(volume.ID),
+			VolumeName: volume.VolumeName,
+			Capacity:   capacity,
+			EventID:    eventID,
+		},
+		Topic: gclient.WorkerTopic,
+	})
+	if err != nil {
+		logrus.Errorf("equque mq error, %v", err)
+		return fmt.Errorf("resize volume %s: %v", volumeName, err)
+	}
+	return nil
+}
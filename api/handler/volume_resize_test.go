@@ -0,0 +1,60 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/goodrain/rainbond/api/util/bcode"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+func TestCheckVolumeResize(t *testing.T) {
+	expandable := &dbmodel.TenantServiceVolumeType{
+		VolumeType:         "ceph-rbd",
+		StorageClassDetail: `{"metadata":{"name":"ceph-rbd"},"provisioner":"rbd.csi.ceph.com","allowVolumeExpansion":true}`,
+	}
+	fixed := &dbmodel.TenantServiceVolumeType{
+		VolumeType:         "nfs",
+		StorageClassDetail: `{"metadata":{"name":"nfs"},"provisioner":"nfs"}`,
+	}
+	tests := []struct {
+		name       string
+		volume     *dbmodel.TenantServiceVolume
+		volumeType *dbmodel.TenantServiceVolumeType
+		capacity   int64
+		want       error
+	}{
+		{name: "share file", volume: &dbmodel.TenantServiceVolume{VolumeType: "share-file", VolumeCapacity: 10}, capacity: 20},
+		{name: "local", volume: &dbmodel.TenantServiceVolume{VolumeType: "local", VolumeCapacity: 10}, capacity: 20},
+		{name: "expandable storage class", volume: &dbmodel.TenantServiceVolume{VolumeType: "ceph-rbd", VolumeCapacity: 10}, volumeType: expandable, capacity: 20},
+		{name: "storage class without expansion", volume: &dbmodel.TenantServiceVolume{VolumeType: "nfs", VolumeCapacity: 10}, volumeType: fixed, capacity: 20, want: bcode.ErrVolumeExpansionNotAllowed},
+		{name: "unknown volume type", volume: &dbmodel.TenantServiceVolume{VolumeType: "nfs", VolumeCapacity: 10}, capacity: 20, want: bcode.ErrVolumeExpansionNotAllowed},
+		{name: "config file", volume: &dbmodel.TenantServiceVolume{VolumeType: "config-file"}, capacity: 20, want: bcode.ErrVolumeExpansionNotAllowed},
+		{name: "shrink", volume: &dbmodel.TenantServiceVolume{VolumeType: "share-file", VolumeCapacity: 10}, capacity: 5, want: bcode.ErrVolumeCapacityNotIncreased},
+		{name: "same capacity", volume: &dbmodel.TenantServiceVolume{VolumeType: "share-file", VolumeCapacity: 10}, capacity: 10, want: bcode.ErrVolumeCapacityNotIncreased},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkVolumeResize(tc.volume, tc.volumeType, tc.capacity); err != tc.want {
+				t.Errorf("want %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
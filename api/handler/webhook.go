@@ -0,0 +1,260 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	apiutil "github.com/goodrain/rainbond/api/util"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/webhook"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookHandler manages the git push webhooks of components and triggers builds.
+type WebhookHandler interface {
+	GetWebhook(serviceID string) (*api_model.WebhookResp, error)
+	UpdateWebhook(tenantID, serviceID string, req *api_model.WebhookReq) (*api_model.WebhookResp, error)
+	DeleteWebhook(serviceID string) error
+	TriggerWebhook(serviceID string, header http.Header, body []byte) (*api_model.WebhookTriggerResult, error)
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(operation *OperationHandler) WebhookHandler {
+	return &WebhookAction{operation: operation}
+}
+
+// WebhookAction is an implementation of WebhookHandler
+type WebhookAction struct {
+	operation *OperationHandler
+}
+
+func webhookResp(hook *dbmodel.TenantServiceWebhook) *api_model.WebhookResp {
+	return &api_model.WebhookResp{
+		ServiceID:     hook.ServiceID,
+		BranchPattern: hook.BranchPattern,
+		TagPattern:    hook.TagPattern,
+		RepoURL:       hook.RepoURL,
+		Branch:        hook.Branch,
+		Action:        hook.Action,
+		Enable:        hook.Enable,
+		WebhookPath:   "/v2/webhooks/" + hook.ServiceID,
+	}
+}
+
+// GetWebhook returns the webhook of the component, the secret is not returned.
+func (w *WebhookAction) GetWebhook(serviceID string) (*api_model.WebhookResp, error) {
+	hook, err := db.GetManager().TenantServiceWebhookDao().GetByServiceID(serviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrWebhookNotFound
+		}
+		return nil, errors.Wrap(err, "get webhook")
+	}
+	return webhookResp(hook), nil
+}
+
+// UpdateWebhook creates or updates the webhook of the component.
+// The stored secret is kept unless a secret is set or the rotation is requested.
+// The new secret is generated if not set, and only returned by this method.
+func (w *WebhookAction) UpdateWebhook(tenantID, serviceID string, req *api_model.WebhookReq) (*api_model.WebhookResp, error) {
+	if req.CodeInfo.RepoURL == "" || req.CodeInfo.Branch == "" {
+		return nil, bcode.NewBadRequest("repo url and branch of code info are required")
+	}
+	envs, err := json.Marshal(req.BuildENVs)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal build envs")
+	}
+	old, err := db.GetManager().TenantServiceWebhookDao().GetByServiceID(serviceID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.Wrap(err, "get webhook")
+	}
+	if err == gorm.ErrRecordNotFound {
+		old = nil
+	}
+	secret, enable := req.Secret, true
+	if old != nil {
+		enable = old.Enable
+	}
+	if req.Enable != nil {
+		enable = *req.Enable
+	}
+	if secret == "" {
+		if old != nil && !req.RotateSecret {
+			secret = old.Secret
+		} else {
+			secret = util.NewUUID()
+		}
+	}
+	hook := &dbmodel.TenantServiceWebhook{
+		TenantID:      tenantID,
+		ServiceID:     serviceID,
+		Secret:        secret,
+		BranchPattern: req.BranchPattern,
+		TagPattern:    req.TagPattern,
+		RepoURL:       req.CodeInfo.RepoURL,
+		Branch:        req.CodeInfo.Branch,
+		Lang:          req.CodeInfo.Lang,
+		Runtime:       req.CodeInfo.Runtime,
+		ServerType:    req.CodeInfo.ServerType,
+		User:          req.CodeInfo.User,
+		Password:      req.CodeInfo.Password,
+		BuildEnvs:     string(envs),
+		Action:        req.Action,
+		Enable:        enable,
+	}
+	if err := db.GetManager().TenantServiceWebhookDao().AddModel(hook); err != nil {
+		return nil, errors.Wrap(err, "save webhook")
+	}
+	resp := webhookResp(hook)
+	if old == nil || secret != old.Secret {
+		resp.Secret = secret
+	}
+	return resp, nil
+}
+
+// DeleteWebhook deletes the webhook of the component.
+func (w *WebhookAction) DeleteWebhook(serviceID string) error {
+	return db.GetManager().TenantServiceWebhookDao().DeleteByServiceID(serviceID)
+}
+
+// TriggerWebhook verifies the push event of git providers and enqueues the source code build.
+func (w *WebhookAction) TriggerWebhook(serviceID string, header http.Header, body []byte) (*api_model.WebhookTriggerResult, error) {
+	hook, err := db.GetManager().TenantServiceWebhookDao().GetByServiceID(serviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrWebhookNotFound
+		}
+		return nil, errors.Wrap(err, "get webhook")
+	}
+	if !hook.Enable {
+		return nil, bcode.ErrWebhookNotFound
+	}
+	provider := webhook.DetectProvider(header)
+	if provider == "" {
+		return nil, bcode.NewBadRequest(webhook.ErrUnsupportedProvider.Error())
+	}
+	if err := webhook.Verify(provider, header, body, hook.Secret); err != nil {
+		logrus.Warningf("verify %s webhook of component %s failure: %s", provider, serviceID, err.Error())
+		return nil, bcode.ErrWebhookSignature
+	}
+	push, err := webhook.ParsePush(provider, header, body)
+	if err != nil {
+		return nil, bcode.NewBadRequest(err.Error())
+	}
+	if push == nil {
+		return &api_model.WebhookTriggerResult{Message: "event is ignored"}, nil
+	}
+	if !matchWebhookRef(hook, push) {
+		return &api_model.WebhookTriggerResult{Message: fmt.Sprintf("%s %s is not matched", push.RefType, push.RefName), Commit: push.Commit}, nil
+	}
+	return w.build(hook, push)
+}
+
+func matchWebhookRef(hook *dbmodel.TenantServiceWebhook, push *webhook.PushEvent) bool {
+	if push.RefType == webhook.RefTypeTag {
+		return webhook.MatchPattern(hook.TagPattern, push.RefName)
+	}
+	if hook.BranchPattern == "" {
+		return push.RefName == hook.Branch
+	}
+	return webhook.MatchPattern(hook.BranchPattern, push.RefName)
+}
+
+func (w *WebhookAction) build(hook *dbmodel.TenantServiceWebhook, push *webhook.PushEvent) (*api_model.WebhookTriggerResult, error) {
+	// a shortened ref would build another branch or a missing one
+	if len(push.Branch()) > maxWebhookRefLength {
+		return nil, bcode.NewBadRequest(fmt.Sprintf("the name of %s %s is longer than %d characters", push.RefType, push.RefName, maxWebhookRefLength))
+	}
+	tenant, err := db.GetManager().TenantDao().GetTenantByUUID(hook.TenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get tenant")
+	}
+	if !apiutil.CanDoEvent("build-service", dbmodel.ASYNEVENTTYPE, dbmodel.TargetTypeService, hook.ServiceID) {
+		return nil, bcode.ErrSyncOperation
+	}
+	operator := truncate("webhook:"+push.Provider, 40)
+	reqBody, _ := json.Marshal(push)
+	event, err := apiutil.CreateEvent(dbmodel.TargetTypeService, "build-service", hook.ServiceID, hook.TenantID, string(reqBody), operator, dbmodel.ASYNEVENTTYPE)
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+	var envs map[string]string
+	if hook.BuildEnvs != "" {
+		if err := json.Unmarshal([]byte(hook.BuildEnvs), &envs); err != nil {
+			logrus.Warningf("unmarshal build envs of webhook %s failure %s", hook.ServiceID, err.Error())
+		}
+	}
+	buildReq := &api_model.ComponentBuildReq{
+		ComponentOpGeneralReq: api_model.ComponentOpGeneralReq{
+			EventID:   event.EventID,
+			ServiceID: hook.ServiceID,
+		},
+		Kind:      api_model.FromCodeBuildKing,
+		Action:    hook.Action,
+		Operator:  operator,
+		BuildENVs: envs,
+		CodeInfo: api_model.BuildCodeInfo{
+			RepoURL:      hook.RepoURL,
+			Branch:       push.Branch(),
+			Lang:         hook.Lang,
+			Runtime:      hook.Runtime,
+			ServerType:   hook.ServerType,
+			User:         hook.User,
+			Password:     hook.Password,
+			Commit:       push.Commit,
+			CommitMsg:    truncate(push.Message, 1024),
+			CommitAuthor: truncate(push.Author, 40),
+		},
+		TenantName: tenant.Name,
+	}
+	res, err := w.operation.Build(buildReq)
+	if err != nil || res.ErrMsg != "" {
+		apiutil.UpdateEvent(event.EventID, 500)
+		if err == nil {
+			err = errors.New(res.ErrMsg)
+		}
+		return nil, errors.Wrap(err, "build component")
+	}
+	logrus.Infof("%s push %s %s(%s) triggers the build of component %s", push.Provider, push.RefType, push.RefName, push.Commit, hook.ServiceID)
+	return &api_model.WebhookTriggerResult{
+		Triggered:     true,
+		EventID:       event.EventID,
+		DeployVersion: buildReq.DeployVersion,
+		Commit:        push.Commit,
+	}, nil
+}
+
+// maxWebhookRefLength is the size of the code branch of component versions
+const maxWebhookRefLength = 255
+
+func truncate(s string, size int) string {
+	if len(s) > size {
+		return s[:size]
+	}
+	return s
+}
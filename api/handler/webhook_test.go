@@ -0,0 +1,35 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util/webhook"
+)
+
+func TestWebhookBuildLongRef(t *testing.T) {
+	push := &webhook.PushEvent{Provider: "github", RefType: webhook.RefTypeBranch, RefName: "feature/" + strings.Repeat("a", maxWebhookRefLength)}
+	_, err := (&WebhookAction{}).build(&dbmodel.TenantServiceWebhook{ServiceID: "s1"}, push)
+	if err == nil || !strings.Contains(err.Error(), "longer than") {
+		t.Errorf("want the push of a long branch rejected, but got %v", err)
+	}
+}
@@ -128,6 +128,11 @@ func FullToken(next http.Handler) http.Handler {
 			return
 		}
 		//logrus.Debugf("request uri is %s", r.RequestURI)
+		// git push webhooks are authenticated by the signature of webhook secret
+		if isWebhookReceive(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
 		t := r.Header.Get("Authorization")
 		if tt := strings.Split(t, " "); len(tt) == 2 {
 			if handler.GetTokenIdenHandler().CheckToken(tt[1], r.RequestURI) {
@@ -140,3 +145,12 @@ func FullToken(next http.Handler) http.Handler {
 	}
 	return http.HandlerFunc(fn)
 }
+
+//isWebhookReceive only the request receiving the git push webhook, POST /v2/webhooks/{service_id}, is not authenticated by token
+func isWebhookReceive(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	serviceID := strings.TrimPrefix(r.URL.Path, "/v2/webhooks/")
+	return serviceID != r.URL.Path && serviceID != "" && !strings.Contains(serviceID, "/")
+}
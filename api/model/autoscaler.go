@@ -31,6 +31,8 @@ type AutoscalerRuleReq struct {
 		MetricsName       string `json:"metric_name"`
 		MetricTargetType  string `json:"metric_target_type"`
 		MetricTargetValue int    `json:"metric_target_value"`
+		MetricSelector    string `json:"metric_selector"`
+		MetricObject      string `json:"metric_object"`
 	} `json:"metrics"`
 }
 
@@ -47,5 +49,45 @@ type AutoscalerRuleResp struct {
 		MetricsName       string `json:"metric_name"`
 		MetricTargetType  string `json:"metric_target_type"`
 		MetricTargetValue int    `json:"metric_target_value"`
+		MetricSelector    string `json:"metric_selector"`
+		MetricObject      string `json:"metric_object"`
 	} `json:"metrics"`
 }
+
+// ScalingScheduleReq the scheduled scaling rule, the replicas of component are set to
+// replicas at the times of cron, such as "0 8 * * 1-5", in the timezone.
+type ScalingScheduleReq struct {
+	ScheduleID string `json:"schedule_id"`
+	ServiceID  string `json:"-"`
+	Enable     bool   `json:"enable"`
+	Cron       string `json:"cron" validate:"cron|required|cron"`
+	Timezone   string `json:"timezone"`
+	Replicas   int    `json:"replicas"`
+}
+
+// ResourceRecommendation the cpu and memory recommendations of component computed from the usage history.
+// The cpu is in millicores and the memory is in MB.
+type ResourceRecommendation struct {
+	ServiceID     string `json:"service_id"`
+	CurrentCPU    int    `json:"current_cpu"`
+	CurrentMemory int    `json:"current_memory"`
+	CPURequest    int    `json:"cpu_request"`
+	CPULimit      int    `json:"cpu_limit"`
+	MemoryRequest int    `json:"memory_request"`
+	MemoryLimit   int    `json:"memory_limit"`
+	// OOMKilled the number of oom killed events in the history window
+	OOMKilled int `json:"oom_killed"`
+	// Samples the number of cpu usage samples, the recommendation is not applied if the samples are insufficient
+	Samples    int    `json:"samples"`
+	Sufficient bool   `json:"sufficient"`
+	Window     string `json:"window"`
+}
+
+// ResourcePolicyReq the right-sizing policy of component, the recommendations are applied
+// automatically at the low-traffic windows of cron in the timezone if auto_apply is true.
+type ResourcePolicyReq struct {
+	ServiceID string `json:"-"`
+	AutoApply bool   `json:"auto_apply"`
+	Cron      string `json:"cron"`
+	Timezone  string `json:"timezone"`
+}
@@ -80,6 +80,56 @@ func (b *ComponentOpResult) Success() {
 	b.Status = BatchOpResultItemStatusSuccess
 }
 
+// OrchestrationComponentStatus is the status of OrchestrationComponent.
+type OrchestrationComponentStatus string
+
+// OrchestrationComponentStatus -
+var (
+	OrchestrationComponentStatusPending   OrchestrationComponentStatus = "pending"
+	OrchestrationComponentStatusOperating OrchestrationComponentStatus = "operating"
+	OrchestrationComponentStatusReady     OrchestrationComponentStatus = "ready"
+	OrchestrationComponentStatusFailure   OrchestrationComponentStatus = "failure"
+	OrchestrationComponentStatusSkipped   OrchestrationComponentStatus = "skipped"
+)
+
+// OrchestrationComponent is a component in the plan of orchestration.
+type OrchestrationComponent struct {
+	ServiceID    string   `json:"service_id"`
+	ServiceAlias string   `json:"service_alias"`
+	AppID        string   `json:"app_id"`
+	Layer        int      `json:"layer"`
+	DependIDs    []string `json:"depend_ids"`
+	// External means the component is not operated, it is a dependency out of the request,
+	// the components depending on it wait for it to be ready.
+	External bool                         `json:"external"`
+	EventID  string                       `json:"event_id,omitempty"`
+	Status   OrchestrationComponentStatus `json:"status"`
+	// Message is the reason of the rejected or skipped component
+	Message string `json:"message,omitempty"`
+}
+
+// OrchestrationPlan is the plan of starting or upgrading components in the order of their dependencies.
+// The components of a layer are operated in parallel after the ones of the layers before it are ready.
+type OrchestrationPlan struct {
+	// EventID is the parent event reporting the progress of the plan
+	EventID    string                    `json:"event_id"`
+	Operation  string                    `json:"operation"`
+	Layers     int                       `json:"layers"`
+	Components []*OrchestrationComponent `json:"components"`
+}
+
+// DeepCopy returns a copy of the plan whose components are copied too.
+func (o *OrchestrationPlan) DeepCopy() *OrchestrationPlan {
+	plan := *o
+	plan.Components = make([]*OrchestrationComponent, 0, len(o.Components))
+	for _, c := range o.Components {
+		component := *c
+		component.DependIDs = append([]string(nil), c.DependIDs...)
+		plan.Components = append(plan.Components, &component)
+	}
+	return &plan
+}
+
 // ComponentOpGeneralReq -
 type ComponentOpGeneralReq struct {
 	EventID   string            `json:"event_id"`
@@ -952,13 +952,15 @@ type ServiceCheckStruct struct {
 		//检测来源类型
 		// in: body
 		// required: true
-		SourceType string `json:"source_type" validate:"source_type|required|in:docker-run,docker-compose,sourcecode,third-party-service"`
+		SourceType string `json:"source_type" validate:"source_type|required|in:docker-run,docker-compose,sourcecode,third-party-service,kubernetes,helm-chart"`
 
 		CheckOS string `json:"check_os"`
 		// 检测来源定义，
 		// 代码： https://github.com/goodrain/rainbond.git master
 		// docker-run: docker run --name xxx nginx:latest nginx
 		// docker-compose: compose全文
+		// kubernetes: kubernetes manifests
+		// helm-chart: {"chart": "chart name in repo or oci reference", "repo_url": "", "version": "", "values": ""}
 		// in: body
 		// required: true
 		SourceBody string `json:"source_body"`
@@ -1423,11 +1425,21 @@ type ExportAppStruct struct {
 		EventID       string `json:"event_id"`
 		GroupKey      string `json:"group_key"` // TODO 考虑去掉
 		Version       string `json:"version"`   // TODO 考虑去掉
-		Format        string `json:"format"`    // only rainbond-app/docker-compose
+		Format        string `json:"format"`    // only rainbond-app/docker-compose/helm-chart/kubernetes
 		GroupMetadata string `json:"group_metadata"`
+		// the package is encrypted if the secret name of encryption is set
+		Encryption AppEncryption `json:"encryption"`
 	}
 }
 
+//AppEncryption the client-side encryption of app package,
+//the key is read from the kubernetes secret in the namespace of rainbond components.
+type AppEncryption struct {
+	SecretName string `json:"secret_name"`
+	// the key of secret data, default is "key"
+	SecretKey string `json:"secret_key,omitempty"`
+}
+
 // BatchOperationReq beatch operation request body
 type BatchOperationReq struct {
 	Operator   string `json:"operator"`
@@ -1438,6 +1450,11 @@ type BatchOperationReq struct {
 		Starts    []*ComponentStartReq   `json:"start_infos,omitempty"`
 		Stops     []*ComponentStopReq    `json:"stop_infos,omitempty"`
 		Upgrades  []*ComponentUpgradeReq `json:"upgrade_infos,omitempty"`
+		// Orchestrate starts or upgrades the components in the order of their dependencies, every layer of
+		// the dependencies waits for the layers before it to be ready. Only start and upgrade support it.
+		Orchestrate bool `json:"orchestrate"`
+		// LayerTimeout is the seconds waiting for a layer to be ready, 300 by default
+		LayerTimeout int `json:"layer_timeout"`
 	}
 }
 
@@ -1475,6 +1492,26 @@ type BuildCodeInfo struct {
 	Password   string `json:"password" validate:"password"`
 	//for .netcore source type, need cmd
 	Cmd string `json:"cmd"`
+	// the commit which triggers the build, eg. the pushed commit of webhook
+	Commit       string `json:"commit"`
+	CommitMsg    string `json:"commit_msg"`
+	CommitAuthor string `json:"commit_author"`
+	// git clone options
+	CloneOptions *CodeCloneOptions `json:"clone_options,omitempty"`
+}
+
+//CodeCloneOptions git clone options
+type CodeCloneOptions struct {
+	// clone depth, 0 means 1 and negative means the full history
+	Depth int `json:"depth"`
+	// do not clone submodules recursively
+	SkipSubmodules bool `json:"skip_submodules"`
+	// fetch git lfs objects
+	LFS bool `json:"lfs"`
+	// only the paths are kept in the work tree
+	SparsePaths []string `json:"sparse_paths"`
+	// the commit, tag(tag:v1.0) or branch to build
+	Ref string `json:"ref"`
 }
 
 //BuildSlugInfo -
@@ -1652,21 +1689,23 @@ type RollbackInfoRequestStruct struct {
 //BuildMQBodyFrom -
 func BuildMQBodyFrom(app *ExportAppStruct) *MQBody {
 	return &MQBody{
-		EventID:   app.Body.EventID,
-		GroupKey:  app.Body.GroupKey,
-		Version:   app.Body.Version,
-		Format:    app.Body.Format,
-		SourceDir: app.SourceDir,
+		EventID:    app.Body.EventID,
+		GroupKey:   app.Body.GroupKey,
+		Version:    app.Body.Version,
+		Format:     app.Body.Format,
+		SourceDir:  app.SourceDir,
+		Encryption: app.Body.Encryption,
 	}
 }
 
 //MQBody -
 type MQBody struct {
-	EventID   string `json:"event_id"`
-	GroupKey  string `json:"group_key"`
-	Version   string `json:"version"`
-	Format    string `json:"format"` // only rainbond-app/docker-compose
-	SourceDir string `json:"source_dir"`
+	EventID    string        `json:"event_id"`
+	GroupKey   string        `json:"group_key"`
+	Version    string        `json:"version"`
+	Format     string        `json:"format"` // only rainbond-app/docker-compose/helm-chart/kubernetes
+	SourceDir  string        `json:"source_dir"`
+	Encryption AppEncryption `json:"encryption"`
 }
 
 //NewAppStatusFromExport -
@@ -1687,6 +1726,8 @@ type ImportAppStruct struct {
 	Format       string       `json:"format"`
 	ServiceImage ServiceImage `json:"service_image"`
 	ServiceSlug  ServiceSlug  `json:"service_slug"`
+	// the encryption of the app packages end with .enc
+	Encryption AppEncryption `json:"encryption"`
 }
 
 //ServiceImage -
@@ -1767,6 +1808,12 @@ type ListServiceResponse struct {
 type UpdateAppRequest struct {
 	AppName        string `json:"app_name"`
 	GovernanceMode string `json:"governance_mode"`
+	// TracingProvider zipkin or otlp, set it to "none" to disable the tracing
+	TracingProvider   string `json:"tracing_provider"`
+	TracingEndpoint   string `json:"tracing_endpoint"`
+	TracingSampleRate *int   `json:"tracing_sample_rate"`
+	// TracingOpenCensusPort the port of the opencensus receiver of the otlp collector, 55678 by default
+	TracingOpenCensusPort *int `json:"tracing_opencensus_port"`
 }
 
 // BindServiceRequest -
@@ -1794,10 +1841,16 @@ type ConfigItem struct {
 type ApplicationConfigGroup struct {
 	AppID           string       `json:"app_id"`
 	ConfigGroupName string       `json:"config_group_name" validate:"required,alphanum,min=2,max=64"`
-	DeployType      string       `json:"deploy_type" validate:"required,oneof=env configfile"`
+	DeployType      string       `json:"deploy_type" validate:"required,oneof=env configfile file"`
 	ServiceIDs      []string     `json:"service_ids"`
 	ConfigItems     []ConfigItem `json:"config_items"`
 	Enable          bool         `json:"enable"`
+	// SourceType db or vault, the config items are ignored if the source is vault
+	SourceType      string `json:"source_type" validate:"omitempty,oneof=db vault"`
+	SourcePath      string `json:"source_path" validate:"max=255"`
+	RefreshInterval int    `json:"refresh_interval" validate:"min=0"`
+	MountPath       string `json:"mount_path" validate:"max=255"`
+	RestartOnChange bool   `json:"restart_on_change"`
 }
 
 // ApplicationConfigGroupResp -
@@ -1809,13 +1862,22 @@ type ApplicationConfigGroupResp struct {
 	Services        []*dbmodel.ConfigGroupService `json:"services"`
 	ConfigItems     []*dbmodel.ConfigGroupItem    `json:"config_items"`
 	Enable          bool                          `json:"enable"`
+	SourceType      string                        `json:"source_type"`
+	SourcePath      string                        `json:"source_path"`
+	RefreshInterval int                           `json:"refresh_interval"`
+	MountPath       string                        `json:"mount_path"`
+	RestartOnChange bool                          `json:"restart_on_change"`
 }
 
 // UpdateAppConfigGroupReq -
 type UpdateAppConfigGroupReq struct {
-	ServiceIDs  []string     `json:"service_ids"`
-	ConfigItems []ConfigItem `json:"config_items" validate:"required"`
-	Enable      bool         `json:"enable"`
+	ServiceIDs      []string     `json:"service_ids"`
+	ConfigItems     []ConfigItem `json:"config_items" validate:"required"`
+	Enable          bool         `json:"enable"`
+	SourcePath      string       `json:"source_path" validate:"max=255"`
+	RefreshInterval int          `json:"refresh_interval" validate:"min=0"`
+	MountPath       string       `json:"mount_path" validate:"max=255"`
+	RestartOnChange bool         `json:"restart_on_change"`
 }
 
 // ListApplicationConfigGroupResp -
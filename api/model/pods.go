@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // PodDetail -
 type PodDetail struct {
 	Name           string          `json:"name,omitempty"`
@@ -38,3 +40,18 @@ type PodEvent struct {
 	Age     string `json:"age,omitempty"`
 	Message string `json:"message,omitempty"`
 }
+
+// TerminalTicketReq -
+type TerminalTicketReq struct {
+	// the user who opens the terminal, recorded in the session record
+	User          string `json:"user" validate:"required"`
+	ContainerName string `json:"container_name"`
+	// time to live of the ticket in seconds, default 60, max 600
+	TTL int `json:"ttl"`
+}
+
+// TerminalTicket -
+type TerminalTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -245,6 +245,12 @@ type UpdVolumeReq struct {
 	VolumePath  string `json:"volume_path" validate:"volume_path|required"`
 }
 
+// ResizeVolumeReq is a value struct holding request for resizing volume.
+type ResizeVolumeReq struct {
+	// VolumeCapacity the new capacity of the volume, in GiB
+	VolumeCapacity int64 `json:"volume_capacity" validate:"required"`
+}
+
 // VolumeWithStatusResp volume status
 type VolumeWithStatusResp struct {
 	ServiceID string `json:"service_id"`
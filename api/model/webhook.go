@@ -0,0 +1,59 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// WebhookReq the git push webhook config of component
+type WebhookReq struct {
+	// secret to verify the webhook request, the stored secret is kept if empty
+	Secret string `json:"secret"`
+	// generate a new secret if no secret is set
+	RotateSecret  bool   `json:"rotate_secret"`
+	BranchPattern string `json:"branch_pattern"`
+	TagPattern    string `json:"tag_pattern"`
+	// the code info used to build the component
+	CodeInfo  BuildCodeInfo     `json:"code_info" validate:"required"`
+	BuildENVs map[string]string `json:"envs"`
+	// the action after build, eg. upgrade
+	Action string `json:"action"`
+	// enabled if not set when creating, kept if not set when updating
+	Enable *bool `json:"enable"`
+}
+
+// WebhookResp -
+type WebhookResp struct {
+	ServiceID     string `json:"service_id"`
+	Secret        string `json:"secret,omitempty"`
+	BranchPattern string `json:"branch_pattern"`
+	TagPattern    string `json:"tag_pattern"`
+	RepoURL       string `json:"repo_url"`
+	Branch        string `json:"branch"`
+	Action        string `json:"action"`
+	Enable        bool   `json:"enable"`
+	// the path to receive the webhook requests of git providers
+	WebhookPath string `json:"webhook_path"`
+}
+
+// WebhookTriggerResult -
+type WebhookTriggerResult struct {
+	Triggered     bool   `json:"triggered"`
+	Message       string `json:"message,omitempty"`
+	EventID       string `json:"event_id,omitempty"`
+	DeployVersion string `json:"deploy_version,omitempty"`
+	Commit        string `json:"commit,omitempty"`
+}
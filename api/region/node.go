@@ -239,6 +239,17 @@ func (nl *nodeConditionImpl) Delete(k client.NodeConditionType) ([]client.NodeCo
 	return decode, handleAPIResult(code, res)
 }
 
+func (n *node) Events(nid string) ([]*client.NodeEvent, *util.APIHandleError) {
+	var decode []*client.NodeEvent
+	var res utilhttp.ResponseBody
+	res.List = &decode
+	code, err := n.DoRequest(n.prefix+"/"+nid+"/events", "GET", nil, &res)
+	if err != nil {
+		return nil, util.CreateAPIHandleError(code, err)
+	}
+	return decode, handleAPIResult(code, res)
+}
+
 func (n *node) Delete(nid string) *util.APIHandleError {
 	var res utilhttp.ResponseBody
 	code, err := n.DoRequest(n.prefix+"/"+nid, "DELETE", nil, &res)
@@ -345,6 +356,7 @@ type NodeInterface interface {
 	Delete(nid string) *util.APIHandleError
 	Label(nid string) NodeLabelInterface
 	Condition(nid string) NodeConditionInterface
+	Events(nid string) ([]*client.NodeEvent, *util.APIHandleError)
 	Install(nid string) *util.APIHandleError
 	UpdateNodeStatus(nid, status string) (*client.HostNode, *util.APIHandleError)
 }
@@ -20,7 +20,9 @@ package region
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+
 	"github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/api/util"
 	dbmodel "github.com/goodrain/rainbond/db/model"
@@ -43,6 +45,18 @@ type ServiceInterface interface {
 	Stop(eventID string) (string, *util.APIHandleError)
 	Start(eventID string) (string, *util.APIHandleError)
 	EventLog(eventID, level string) ([]*model.MessageData, *util.APIHandleError)
+	Build(req *model.ComponentBuildReq) (*model.ComponentOpResult, *util.APIHandleError)
+	Upgrade(req *model.ComponentUpgradeReq) (*model.ComponentOpResult, *util.APIHandleError)
+	RollBack(req *model.RollbackInfoRequestStruct) (*model.ComponentOpResult, *util.APIHandleError)
+	Horizontal(replicas int32) (string, *util.APIHandleError)
+	Vertical(cpu, memory int) (string, *util.APIHandleError)
+	BuildList() (*BuildList, *util.APIHandleError)
+}
+
+//BuildList the build versions of service
+type BuildList struct {
+	DeployVersion string                `json:"deploy_version"`
+	List          []*model.BuildVersion `json:"list"`
 }
 
 func (s *services) Pods() ([]*podInfo, *util.APIHandleError) {
@@ -132,3 +146,79 @@ func (s *services) GetDeployInfo() (*ServiceDeployInfo, *util.APIHandleError) {
 	code, err := s.DoRequest(s.prefix+"/deploy-info", "GET", nil, &decode)
 	return &deployInfo, handleErrAndCode(err, code)
 }
+
+//Build build the service from image or source code, the event id is generated by the api
+func (s *services) Build(req *model.ComponentBuildReq) (*model.ComponentOpResult, *util.APIHandleError) {
+	return s.operate("/build", "POST", req)
+}
+
+//Upgrade upgrade the service to the build version
+func (s *services) Upgrade(req *model.ComponentUpgradeReq) (*model.ComponentOpResult, *util.APIHandleError) {
+	return s.operate("/upgrade", "POST", req)
+}
+
+//RollBack roll back the service to the build version
+func (s *services) RollBack(req *model.RollbackInfoRequestStruct) (*model.ComponentOpResult, *util.APIHandleError) {
+	return s.operate("/rollback", "POST", req)
+}
+
+func (s *services) operate(path, method string, req interface{}) (*model.ComponentOpResult, *util.APIHandleError) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, util.CreateAPIHandleError(400, err)
+	}
+	var result model.ComponentOpResult
+	var res utilhttp.ResponseBody
+	res.Bean = &result
+	code, err := s.DoRequest(s.prefix+path, method, bytes.NewBuffer(data), &res)
+	if err != nil {
+		return nil, handleErrAndCode(err, code)
+	}
+	if apiErr := handleAPIResult(code, res); apiErr != nil {
+		return nil, apiErr
+	}
+	if result.Status == model.BatchOpResultItemStatusFailure {
+		return &result, util.CreateAPIHandleErrorf(500, "%s failure: %s", result.Operation, result.ErrMsg)
+	}
+	return &result, nil
+}
+
+//Horizontal scale the replicas of service, returns the event id
+func (s *services) Horizontal(replicas int32) (string, *util.APIHandleError) {
+	return s.scale("/horizontal", map[string]interface{}{"node_num": replicas})
+}
+
+//Vertical scale the cpu(millicores) and memory(MB) of service, returns the event id
+func (s *services) Vertical(cpu, memory int) (string, *util.APIHandleError) {
+	return s.scale("/vertical", map[string]interface{}{"container_cpu": cpu, "container_memory": memory})
+}
+
+func (s *services) scale(path string, body map[string]interface{}) (string, *util.APIHandleError) {
+	data, _ := json.Marshal(body)
+	var event dbmodel.ServiceEvent
+	var res utilhttp.ResponseBody
+	res.Bean = &event
+	code, err := s.DoRequest(s.prefix+path, "PUT", bytes.NewBuffer(data), &res)
+	if err != nil {
+		return "", handleErrAndCode(err, code)
+	}
+	if apiErr := handleAPIResult(code, res); apiErr != nil {
+		return "", apiErr
+	}
+	return event.EventID, nil
+}
+
+//BuildList list the build versions of service
+func (s *services) BuildList() (*BuildList, *util.APIHandleError) {
+	var list BuildList
+	var res utilhttp.ResponseBody
+	res.Bean = &list
+	code, err := s.DoRequest(s.prefix+"/build-list", "GET", nil, &res)
+	if err != nil {
+		return nil, handleErrAndCode(err, code)
+	}
+	if apiErr := handleAPIResult(code, res); apiErr != nil {
+		return nil, apiErr
+	}
+	return &list, nil
+}
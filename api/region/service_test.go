@@ -0,0 +1,115 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package region
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goodrain/rainbond/api/model"
+)
+
+func newTestRegion(handler http.HandlerFunc) (*regionImpl, func()) {
+	server := httptest.NewServer(handler)
+	return &regionImpl{APIConf: APIConf{Endpoints: []string{server.URL}}, Client: server.Client()}, server.Close
+}
+
+func TestServiceBuild(t *testing.T) {
+	r, closeServer := newTestRegion(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v2/tenants/goodrain/services/gr123456/build" || req.Method != "POST" {
+			w.WriteHeader(404)
+			return
+		}
+		var build model.ComponentBuildReq
+		json.NewDecoder(req.Body).Decode(&build)
+		w.Write([]byte(`{"bean":{"service_id":"` + build.ServiceID + `","operation":"build","event_id":"e1","status":"success","deploy_version":"v1"}}`))
+	})
+	defer closeServer()
+	res, err := r.Tenants("goodrain").Services("gr123456").Build(&model.ComponentBuildReq{
+		ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: "s1"},
+		Kind:                  model.FromImageBuildKing,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ServiceID != "s1" || res.EventID != "e1" || res.DeployVersion != "v1" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestServiceOperateFailure(t *testing.T) {
+	r, closeServer := newTestRegion(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"bean":{"operation":"rollback","event_id":"e1","status":"failure","err_message":"version not found"}}`))
+	})
+	defer closeServer()
+	res, err := r.Tenants("goodrain").Services("gr123456").RollBack(&model.RollbackInfoRequestStruct{ServiceID: "s1", RollBackVersion: "v0"})
+	if err == nil {
+		t.Fatal("expect failure of rollback")
+	}
+	if res == nil || res.EventID != "e1" {
+		t.Fatalf("the result should be returned with the event id, got %+v", res)
+	}
+}
+
+func TestServiceScale(t *testing.T) {
+	var body map[string]interface{}
+	r, closeServer := newTestRegion(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "PUT" {
+			w.WriteHeader(405)
+			return
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		if req.URL.Path == "/v2/tenants/goodrain/services/gr123456/vertical" {
+			w.WriteHeader(412)
+			w.Write([]byte(`{"msg":"tenant lack of memory"}`))
+			return
+		}
+		w.Write([]byte(`{"bean":{"EventID":"e2"}}`))
+	})
+	defer closeServer()
+	eventID, err := r.Tenants("goodrain").Services("gr123456").Horizontal(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eventID != "e2" || body["node_num"] != float64(3) {
+		t.Fatalf("unexpected event id %s or body %v", eventID, body)
+	}
+	if _, err := r.Tenants("goodrain").Services("gr123456").Vertical(500, 1024); err == nil || err.Code != 412 {
+		t.Fatalf("expect the error with code 412, got %v", err)
+	}
+	if body["container_cpu"] != float64(500) || body["container_memory"] != float64(1024) {
+		t.Fatalf("unexpected body %v", body)
+	}
+}
+
+func TestServiceBuildList(t *testing.T) {
+	r, closeServer := newTestRegion(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"bean":{"deploy_version":"v2","list":[{"build_version":"v2","final_status":"success"},{"build_version":"v1"}]}}`))
+	})
+	defer closeServer()
+	list, err := r.Tenants("goodrain").Services("gr123456").BuildList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.DeployVersion != "v2" || len(list.List) != 2 || list.List[1].BuildVersion != "v1" {
+		t.Fatalf("unexpected build list %+v", list)
+	}
+}
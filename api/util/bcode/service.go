@@ -12,4 +12,18 @@ var (
 	ErrSyncOperation = newByMessage(409, 10103, "The asynchronous operation is executing")
 	// ErrHorizontalDueToNoChange
 	ErrHorizontalDueToNoChange = newByMessage(400, 10104, "The number of components has not changed, no need to scale")
+	// ErrTerminalTicketDisabled -
+	ErrTerminalTicketDisabled = newByMessage(400, 10105, "terminal ticket secret is not configured")
+	// ErrTerminalSessionNotFound -
+	ErrTerminalSessionNotFound = newByMessage(404, 10106, "terminal session not found")
+	// ErrWebhookNotFound -
+	ErrWebhookNotFound = newByMessage(404, 10107, "webhook not found or disabled")
+	// ErrWebhookSignature -
+	ErrWebhookSignature = newByMessage(403, 10108, "webhook signature is invalid")
+	// ErrVolumeNotFound -
+	ErrVolumeNotFound = newByMessage(404, 10109, "volume not found")
+	// ErrVolumeExpansionNotAllowed -
+	ErrVolumeExpansionNotAllowed = newByMessage(400, 10110, "the storage class of the volume does not allow expansion")
+	// ErrVolumeCapacityNotIncreased -
+	ErrVolumeCapacityNotIncreased = newByMessage(400, 10111, "the new volume capacity must be larger than the current one")
 )
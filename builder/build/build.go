@@ -112,7 +112,10 @@ type HostAlias struct {
 type Commit struct {
 	User    string
 	Message string
-	Hash    string
+	// Hash the commit resolved from the branch or ref
+	Hash string
+	// Ref the ref specified by the clone options
+	Ref string
 }
 
 //GetBuild GetBuild
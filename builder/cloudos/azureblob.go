@@ -0,0 +1,200 @@
+package cloudos
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const azureAPIVersion = "2019-12-12"
+
+// the files larger than azureBlockSize are uploaded by blocks
+var azureBlockSize int64 = 64 * 1024 * 1024
+
+// azureBlob the driver of azure blob storage, it works with the shared key authorization.
+// The endpoint is the blob service url, such as http://127.0.0.1:10000/devstoreaccount1 of azurite,
+// the access key is the account name and the secret key is the base64 encoded account key.
+type azureBlob struct {
+	*Config
+	endpoint   *url.URL
+	accountKey []byte
+	client     *http.Client
+}
+
+func newAzureBlob(cfg *Config) (CloudOSer, error) {
+	if cfg.AccessKey == "" || cfg.BucketName == "" {
+		return nil, fmt.Errorf("the account name and container of azure blob storage are required")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", cfg.AccessKey)
+	} else if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+		if cfg.UseSSL {
+			endpoint = "https://" + cfg.Endpoint
+		}
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure blob endpoint %s: %v", cfg.Endpoint, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("the account key of azure blob storage must be base64 encoded: %v", err)
+	}
+	return &azureBlob{
+		Config:     cfg,
+		endpoint:   u,
+		accountKey: key,
+		client:     &http.Client{Timeout: 30 * time.Minute},
+	}, nil
+}
+
+func (a *azureBlob) blobURL(objkey string, query url.Values) *url.URL {
+	u := *a.endpoint
+	u.Path = u.Path + "/" + a.BucketName + "/" + objkey
+	u.RawPath = ""
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+func (a *azureBlob) do(method string, u *url.URL, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	if size == 0 {
+		//the empty body is sent as chunked if it is not nil, which is not supported by azure
+		body = nil
+	}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.ContentLength = size
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("Authorization", "SharedKey "+a.AccessKey+":"+a.sign(req))
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, httpError(resp, resp.Header.Get("x-ms-error-code"))
+	}
+	return resp, nil
+}
+
+// sign signs the request by the shared key,
+// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (a *azureBlob) sign(req *http.Request) string {
+	var length string
+	if req.ContentLength > 0 {
+		length = strconv.FormatInt(req.ContentLength, 10)
+	}
+	var msHeaders []string
+	for k := range req.Header {
+		if k := strings.ToLower(k); strings.HasPrefix(k, "x-ms-") {
+			msHeaders = append(msHeaders, k)
+		}
+	}
+	sort.Strings(msHeaders)
+	var buf bytes.Buffer
+	for _, v := range []string{req.Method, req.Header.Get("Content-Encoding"), req.Header.Get("Content-Language"), length,
+		req.Header.Get("Content-MD5"), req.Header.Get("Content-Type"), "", req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"), req.Header.Get("If-None-Match"), req.Header.Get("If-Unmodified-Since"), req.Header.Get("Range")} {
+		buf.WriteString(v + "\n")
+	}
+	for _, k := range msHeaders {
+		buf.WriteString(k + ":" + strings.TrimSpace(req.Header.Get(k)) + "\n")
+	}
+	buf.WriteString("/" + a.AccessKey + req.URL.EscapedPath())
+	query := req.URL.Query()
+	var params []string
+	for k := range query {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	for _, k := range params {
+		values := query[k]
+		sort.Strings(values)
+		buf.WriteString("\n" + strings.ToLower(k) + ":" + strings.Join(values, ","))
+	}
+	mac := hmac.New(sha256.New, a.accountKey)
+	mac.Write(buf.Bytes())
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (a *azureBlob) PutObject(objkey, filepath string) error {
+	fp, err := os.Open(filepath)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	info, err := fp.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() <= azureBlockSize {
+		header := http.Header{}
+		header.Set("x-ms-blob-type", "BlockBlob")
+		resp, err := a.do(http.MethodPut, a.blobURL(objkey, nil), header, fp, info.Size())
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	var blockList bytes.Buffer
+	blockList.WriteString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+	for i := int64(0); i*azureBlockSize < info.Size(); i++ {
+		size := info.Size() - i*azureBlockSize
+		if size > azureBlockSize {
+			size = azureBlockSize
+		}
+		//the block ids of blob must have the same length
+		blockID := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%010d", i)))
+		query := url.Values{"comp": {"block"}, "blockid": {blockID}}
+		resp, err := a.do(http.MethodPut, a.blobURL(objkey, query), nil, io.NewSectionReader(fp, i*azureBlockSize, size), size)
+		if err != nil {
+			return fmt.Errorf("put block %d: %v", i, err)
+		}
+		resp.Body.Close()
+		blockList.WriteString("<Latest>" + blockID + "</Latest>")
+	}
+	blockList.WriteString("</BlockList>")
+	resp, err := a.do(http.MethodPut, a.blobURL(objkey, url.Values{"comp": {"blocklist"}}), nil, &blockList, int64(blockList.Len()))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (a *azureBlob) GetObject(objkey, filePath string) error {
+	resp, err := a.do(http.MethodGet, a.blobURL(objkey, nil), nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return writeFile(filePath, resp.Body)
+}
+
+func (a *azureBlob) DeleteObject(objkey string) error {
+	resp, err := a.do(http.MethodDelete, a.blobURL(objkey, nil), nil, nil, 0)
+	if err != nil {
+		if s3err, ok := err.(S3SDKError); ok && s3err.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	return resp.Body.Close()
+}
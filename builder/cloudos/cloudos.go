@@ -7,6 +7,8 @@ import (
 var (
 	// ErrUnsupportedS3Provider -
 	ErrUnsupportedS3Provider = errors.New("unsupported s3 provider")
+	// ErrNotEncrypted is returned when getting an object which is not encrypted by client-side encryption.
+	ErrNotEncrypted = errors.New("object is not encrypted by client-side encryption")
 )
 
 // S3Provider -
@@ -17,6 +19,14 @@ var (
 	S3ProviderS3 S3Provider = "s3"
 	// S3ProviderAliOSS -
 	S3ProviderAliOSS S3Provider = "alioss"
+	// S3ProviderAzureBlob azure blob storage or the compatible storage, such as azurite.
+	S3ProviderAzureBlob S3Provider = "azureblob"
+	// S3ProviderGCS google cloud storage or the compatible storage, such as fake-gcs-server.
+	S3ProviderGCS S3Provider = "gcs"
+	// S3ProviderFilesystem local directory or the mounted NFS directory.
+	S3ProviderFilesystem S3Provider = "filesystem"
+	// S3ProviderSFTP sftp server.
+	S3ProviderSFTP S3Provider = "sftp"
 )
 
 func (p S3Provider) String() string {
@@ -30,6 +40,14 @@ func Str2S3Provider(value string) (S3Provider, error) {
 		return S3ProviderS3, nil
 	case S3ProviderAliOSS.String():
 		return S3ProviderAliOSS, nil
+	case S3ProviderAzureBlob.String():
+		return S3ProviderAzureBlob, nil
+	case S3ProviderGCS.String():
+		return S3ProviderGCS, nil
+	case S3ProviderFilesystem.String():
+		return S3ProviderFilesystem, nil
+	case S3ProviderSFTP.String():
+		return S3ProviderSFTP, nil
 	default:
 		return "", ErrUnsupportedS3Provider
 	}
@@ -42,16 +60,30 @@ type CloudOSer interface {
 	DeleteObject(objkey string) error
 }
 
-// New returns a new CloudOSer.
+// New returns a new CloudOSer, the objects are encrypted before putting if the encryption key is set.
 func New(cfg *Config) (CloudOSer, error) {
+	var cloudoser CloudOSer
+	var err error
 	switch cfg.ProviderType {
 	case S3ProviderAliOSS:
-		return newAliOSS(cfg)
+		cloudoser, err = newAliOSS(cfg)
 	case S3ProviderS3:
-		return newS3(cfg)
+		cloudoser, err = newS3(cfg)
+	case S3ProviderAzureBlob:
+		cloudoser, err = newAzureBlob(cfg)
+	case S3ProviderGCS:
+		cloudoser, err = newGCS(cfg)
+	case S3ProviderFilesystem:
+		cloudoser, err = newFilesystem(cfg)
+	case S3ProviderSFTP:
+		cloudoser, err = newSFTP(cfg)
 	default:
 		return nil, ErrUnsupportedS3Provider
 	}
+	if err != nil || len(cfg.EncryptionKey) == 0 {
+		return cloudoser, err
+	}
+	return NewEncrypted(cloudoser, cfg.EncryptionKey)
 }
 
 // Config configuration about cloud object storage.
@@ -63,6 +95,11 @@ type Config struct {
 	SecretKey string
 	UseSSL    bool
 
+	// BucketName is the container of azure blob storage,
+	// and the base directory of filesystem and sftp provider.
 	BucketName string
 	Location   string
+
+	// EncryptionKey the AES key used by client-side encryption, the objects are not encrypted if empty.
+	EncryptionKey []byte
 }
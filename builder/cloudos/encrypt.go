@@ -0,0 +1,223 @@
+package cloudos
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The encrypted object is the header followed by the sealed segments.
+// The header is the magic and a random nonce prefix, and it is the additional data of all segments.
+// The nonce of segment is the prefix, the big endian segment counter and the last segment flag,
+// so the reordered, truncated or appended segments can not be decrypted.
+const (
+	encryptMagic       = "RBDENC1\n"
+	encryptPrefixSize  = 7
+	encryptHeaderSize  = len(encryptMagic) + encryptPrefixSize
+	encryptSegmentSize = 64 * 1024
+)
+
+// EncryptionConfig the client-side encryption config, the key is read from the kubernetes secret.
+type EncryptionConfig struct {
+	// SecretName the name of secret in the namespace of rainbond components.
+	SecretName string `json:"secret_name"`
+	// SecretKey the key of secret data, default is "key".
+	SecretKey string `json:"secret_key,omitempty"`
+}
+
+// Enabled returns whether the client-side encryption is enabled.
+func (e *EncryptionConfig) Enabled() bool {
+	return e != nil && e.SecretName != ""
+}
+
+// LoadEncryptionKey reads the AES key from the kubernetes secret.
+// The secret data can be the raw key of 16, 24 or 32 bytes, or the base64 encoded key.
+func LoadEncryptionKey(ctx context.Context, kubeClient kubernetes.Interface, namespace string, cfg *EncryptionConfig) ([]byte, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	dataKey := cfg.SecretKey
+	if dataKey == "" {
+		dataKey = "key"
+	}
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, cfg.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get encryption secret %s/%s: %v", namespace, cfg.SecretName, err)
+	}
+	key, ok := secret.Data[dataKey]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in encryption secret %s/%s", dataKey, namespace, cfg.SecretName)
+	}
+	if !validKeySize(len(key)) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(key)))
+		if err != nil || !validKeySize(len(decoded)) {
+			return nil, fmt.Errorf("the key of encryption secret %s/%s must be 16, 24 or 32 bytes", namespace, cfg.SecretName)
+		}
+		key = decoded
+	}
+	return key, nil
+}
+
+func validKeySize(size int) bool {
+	return size == 16 || size == 24 || size == 32
+}
+
+type encryptedOS struct {
+	CloudOSer
+	aead cipher.AEAD
+}
+
+// NewEncrypted returns a CloudOSer which encrypts the objects by AES-GCM before putting,
+// and decrypts the objects after getting.
+func NewEncrypted(cloudoser CloudOSer, key []byte) (CloudOSer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedOS{CloudOSer: cloudoser, aead: aead}, nil
+}
+
+func (e *encryptedOS) PutObject(objkey, filepath string) error {
+	encrypted := filepath + ".enc"
+	defer os.Remove(encrypted)
+	if err := e.encryptFile(filepath, encrypted); err != nil {
+		return fmt.Errorf("encrypt file %s: %v", filepath, err)
+	}
+	return e.CloudOSer.PutObject(objkey, encrypted)
+}
+
+func (e *encryptedOS) GetObject(objkey, filePath string) error {
+	encrypted := filePath + ".enc"
+	defer os.Remove(encrypted)
+	if err := e.CloudOSer.GetObject(objkey, encrypted); err != nil {
+		return err
+	}
+	if err := e.decryptFile(encrypted, filePath); err != nil {
+		os.Remove(filePath)
+		if err == ErrNotEncrypted {
+			return err
+		}
+		return fmt.Errorf("decrypt object %s: %v", objkey, err)
+	}
+	return nil
+}
+
+func (e *encryptedOS) nonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, e.aead.NonceSize())
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encryptPrefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+func (e *encryptedOS) encryptFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	header := make([]byte, encryptHeaderSize)
+	copy(header, encryptMagic)
+	if _, err := io.ReadFull(rand.Reader, header[len(encryptMagic):]); err != nil {
+		return err
+	}
+	if _, err := out.Write(header); err != nil {
+		return err
+	}
+	prefix := header[len(encryptMagic):]
+	reader := bufio.NewReaderSize(in, encryptSegmentSize)
+	plain := make([]byte, encryptSegmentSize)
+	sealed := make([]byte, 0, encryptSegmentSize+e.aead.Overhead())
+	for counter := uint32(0); ; counter++ {
+		n, err := io.ReadFull(reader, plain)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		last := err != nil
+		if !last {
+			//the segment is the last one if there is no more data
+			if _, perr := reader.Peek(1); perr == io.EOF {
+				last = true
+			}
+		}
+		sealed = e.aead.Seal(sealed[:0], e.nonce(prefix, counter, last), plain[:n], header)
+		if _, err := out.Write(sealed); err != nil {
+			return err
+		}
+		if last {
+			return out.Close()
+		}
+		if counter == ^uint32(0) {
+			return fmt.Errorf("file is too large to encrypt")
+		}
+	}
+}
+
+func (e *encryptedOS) decryptFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	reader := bufio.NewReaderSize(in, encryptSegmentSize+e.aead.Overhead())
+	header := make([]byte, encryptHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil || !bytes.HasPrefix(header, []byte(encryptMagic)) {
+		return ErrNotEncrypted
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	prefix := header[len(encryptMagic):]
+	sealed := make([]byte, encryptSegmentSize+e.aead.Overhead())
+	plain := make([]byte, 0, encryptSegmentSize)
+	for counter := uint32(0); ; counter++ {
+		n, err := io.ReadFull(reader, sealed)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			if err == io.EOF {
+				return fmt.Errorf("object is truncated")
+			}
+			return err
+		}
+		last := err != nil
+		if !last {
+			if _, perr := reader.Peek(1); perr == io.EOF {
+				last = true
+			}
+		}
+		plain, err = e.aead.Open(plain[:0], e.nonce(prefix, counter, last), sealed[:n], header)
+		if err != nil {
+			return fmt.Errorf("object is corrupted or the encryption key is wrong")
+		}
+		if _, err := out.Write(plain); err != nil {
+			return err
+		}
+		if last {
+			return out.Close()
+		}
+	}
+}
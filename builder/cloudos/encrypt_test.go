@@ -0,0 +1,103 @@
+package cloudos
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEncryptedStorage(t *testing.T) {
+	base, err := ioutil.TempDir("", "encrypted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+	key := bytes.Repeat([]byte("k"), 32)
+	cfg := &Config{ProviderType: S3ProviderFilesystem, BucketName: filepath.Join(base, "objects"), EncryptionKey: key}
+	cloudoser, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := newFilesystem(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, dst := filepath.Join(base, "src"), filepath.Join(base, "dst")
+	for _, size := range []int{0, 1, encryptSegmentSize, 2*encryptSegmentSize + 5} {
+		content := bytes.Repeat([]byte("a"), size)
+		ioutil.WriteFile(src, content, 0644)
+		if err := cloudoser.PutObject("object", src); err != nil {
+			t.Fatal(err)
+		}
+		stored, _ := ioutil.ReadFile(filepath.Join(cfg.BucketName, "object"))
+		if len(stored) == 0 || bytes.Contains(stored, []byte("aaaa")) {
+			t.Errorf("the object of size %d is not encrypted", size)
+		}
+		if err := cloudoser.GetObject("object", dst); err != nil {
+			t.Fatalf("get object of size %d: %v", size, err)
+		}
+		if body, _ := ioutil.ReadFile(dst); !bytes.Equal(body, content) {
+			t.Errorf("expect decrypted object of size %d, got %d", size, len(body))
+		}
+	}
+
+	stored, _ := ioutil.ReadFile(filepath.Join(cfg.BucketName, "object"))
+	corrupt := func(name string, body []byte, expect error) {
+		ioutil.WriteFile(filepath.Join(cfg.BucketName, name), body, 0644)
+		err := cloudoser.GetObject(name, dst)
+		if err == nil || (expect != nil && err != expect) {
+			t.Errorf("expect error for %s object, got %v", name, err)
+		}
+		if _, err := os.Stat(dst); !os.IsNotExist(err) {
+			t.Errorf("the output of %s object should be removed", name)
+		}
+	}
+	tampered := append([]byte{}, stored...)
+	tampered[encryptHeaderSize+10] ^= 1
+	corrupt("tampered", tampered, nil)
+	corrupt("truncated", stored[:encryptHeaderSize+encryptSegmentSize+16], nil)
+	corrupt("plain", []byte("plain object"), ErrNotEncrypted)
+
+	wrong, _ := NewEncrypted(plain, bytes.Repeat([]byte("w"), 32))
+	if err := wrong.GetObject("object", dst); err == nil {
+		t.Errorf("expect error for wrong key")
+	}
+	if _, err := NewEncrypted(plain, []byte("short")); err == nil {
+		t.Errorf("expect error for invalid key")
+	}
+}
+
+func TestLoadEncryptionKey(t *testing.T) {
+	raw := bytes.Repeat([]byte("r"), 32)
+	kubeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-key", Namespace: "rbd-system"},
+		Data: map[string][]byte{
+			"key":     raw,
+			"encoded": []byte(base64.StdEncoding.EncodeToString(raw) + "\n"),
+			"invalid": []byte("invalid"),
+		},
+	})
+	ctx := context.Background()
+	for _, dataKey := range []string{"", "encoded"} {
+		key, err := LoadEncryptionKey(ctx, kubeClient, "rbd-system", &EncryptionConfig{SecretName: "backup-key", SecretKey: dataKey})
+		if err != nil || !bytes.Equal(key, raw) {
+			t.Errorf("expect key of %q, got %v %v", dataKey, key, err)
+		}
+	}
+	for _, cfg := range []*EncryptionConfig{{SecretName: "backup-key", SecretKey: "invalid"}, {SecretName: "backup-key", SecretKey: "none"}, {SecretName: "none"}} {
+		if _, err := LoadEncryptionKey(ctx, kubeClient, "rbd-system", cfg); err == nil {
+			t.Errorf("expect error for %+v", cfg)
+		}
+	}
+	if key, err := LoadEncryptionKey(ctx, kubeClient, "rbd-system", nil); key != nil || err != nil {
+		t.Errorf("expect no key if encryption is disabled, got %v %v", key, err)
+	}
+}
@@ -1,6 +1,11 @@
 package cloudos
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
 
 // S3SDKError -
 type S3SDKError struct {
@@ -15,3 +20,17 @@ func (e S3SDKError) Error() string {
 	return fmt.Sprintf("s3: service returned error: StatusCode=%d, ErrorCode=%s, ErrorMessage=\"%s\"",
 		e.StatusCode, e.Code, e.Message)
 }
+
+// httpError converts the failed response of the rest api to S3SDKError.
+func httpError(resp *http.Response, code string) S3SDKError {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	if code == "" {
+		code = http.StatusText(resp.StatusCode)
+	}
+	return S3SDKError{
+		Code:       code,
+		Message:    resp.Status,
+		RawMessage: string(body),
+		StatusCode: resp.StatusCode,
+	}
+}
@@ -0,0 +1,103 @@
+package cloudos
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// filesystem the driver stores the objects in the local directory, such as the mounted NFS directory.
+// The bucket name is the base directory.
+type filesystem struct {
+	*Config
+}
+
+func newFilesystem(cfg *Config) (CloudOSer, error) {
+	if !filepath.IsAbs(cfg.BucketName) {
+		return nil, fmt.Errorf("the directory of filesystem storage must be absolute path")
+	}
+	if err := os.MkdirAll(cfg.BucketName, 0755); err != nil {
+		return nil, err
+	}
+	return &filesystem{Config: cfg}, nil
+}
+
+// objectPath returns the path of object relative to base,
+// the object key can not be out of the base directory.
+func objectPath(base, objkey string) (string, error) {
+	clean := path.Clean("/" + objkey)
+	if clean == "/" || strings.HasSuffix(objkey, "/") {
+		return "", fmt.Errorf("invalid object key %s", objkey)
+	}
+	return path.Join(base, clean), nil
+}
+
+func (f *filesystem) PutObject(objkey, filepath string) error {
+	dst, err := objectPath(f.BucketName, objkey)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(filepath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err := os.MkdirAll(path.Dir(dst), 0755); err != nil {
+		return err
+	}
+	//write to the temporary file and rename, the object is never partially written
+	tmp := dst + ".uploading"
+	if err := writeFile(tmp, src); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+func (f *filesystem) GetObject(objkey, filePath string) error {
+	src, err := objectPath(f.BucketName, objkey)
+	if err != nil {
+		return err
+	}
+	fp, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return S3SDKError{Code: "NoSuchKey", Message: err.Error(), StatusCode: http.StatusNotFound}
+		}
+		return err
+	}
+	defer fp.Close()
+	return writeFile(filePath, fp)
+}
+
+func (f *filesystem) DeleteObject(objkey string) error {
+	dst, err := objectPath(f.BucketName, objkey)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// writeFile writes the content of reader to the file, the file is removed if failure.
+func writeFile(filePath string, reader io.Reader) error {
+	fp, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fp, reader); err != nil {
+		fp.Close()
+		os.Remove(filePath)
+		return err
+	}
+	if err := fp.Close(); err != nil {
+		os.Remove(filePath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cloudos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	gcsDefaultEndpoint = "https://storage.googleapis.com"
+	gcsScope           = "https://www.googleapis.com/auth/devstorage.read_write"
+	gcsTokenURL        = "https://oauth2.googleapis.com/token"
+)
+
+// gcs the driver of google cloud storage by the json api.
+// The secret key is the json key of service account or the oauth2 access token,
+// the requests are anonymous if the secret key is empty, which is used by the emulators.
+type gcs struct {
+	*Config
+	endpoint string
+	client   *http.Client
+}
+
+type gcsServiceAccount struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+func newGCS(cfg *Config) (CloudOSer, error) {
+	if cfg.BucketName == "" {
+		return nil, fmt.Errorf("the bucket of google cloud storage is required")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = gcsDefaultEndpoint
+	} else if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+		if cfg.UseSSL {
+			endpoint = "https://" + cfg.Endpoint
+		}
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Timeout: time.Minute})
+	client := &http.Client{}
+	secret := strings.TrimSpace(cfg.SecretKey)
+	switch {
+	case strings.HasPrefix(secret, "{"):
+		var account gcsServiceAccount
+		if err := json.Unmarshal([]byte(secret), &account); err != nil {
+			return nil, fmt.Errorf("invalid service account key of google cloud storage: %v", err)
+		}
+		if account.ClientEmail == "" || account.PrivateKey == "" {
+			return nil, fmt.Errorf("the client_email and private_key of service account key are required")
+		}
+		conf := &jwt.Config{
+			Email:        account.ClientEmail,
+			PrivateKey:   []byte(account.PrivateKey),
+			PrivateKeyID: account.PrivateKeyID,
+			Scopes:       []string{gcsScope},
+			TokenURL:     account.TokenURI,
+		}
+		if conf.TokenURL == "" {
+			conf.TokenURL = gcsTokenURL
+		}
+		client = conf.Client(ctx)
+	case secret != "":
+		client = oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: secret}))
+	}
+	client.Timeout = 30 * time.Minute
+	return &gcs{
+		Config:   cfg,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   client,
+	}, nil
+}
+
+func (g *gcs) objectURL(objkey string) string {
+	return fmt.Sprintf("%s/storage/v1/b/%s/o/%s", g.endpoint, url.PathEscape(g.BucketName), url.PathEscape(objkey))
+}
+
+func (g *gcs) do(req *http.Request) (*http.Response, error) {
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, httpError(resp, "")
+	}
+	return resp, nil
+}
+
+func (g *gcs) PutObject(objkey, filepath string) error {
+	fp, err := os.Open(filepath)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	info, err := fp.Stat()
+	if err != nil {
+		return err
+	}
+	query := url.Values{"uploadType": {"media"}, "name": {objkey}}
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", g.endpoint, url.PathEscape(g.BucketName), query.Encode())
+	var body io.Reader = fp
+	if info.Size() == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := g.do(req)
+	if err != nil {
+		return err
+	}
+	ioutil.ReadAll(resp.Body)
+	return resp.Body.Close()
+}
+
+func (g *gcs) GetObject(objkey, filePath string) error {
+	req, err := http.NewRequest(http.MethodGet, g.objectURL(objkey)+"?alt=media", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := g.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return writeFile(filePath, resp.Body)
+}
+
+func (g *gcs) DeleteObject(objkey string) error {
+	req, err := http.NewRequest(http.MethodDelete, g.objectURL(objkey), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := g.do(req)
+	if err != nil {
+		if s3err, ok := err.(S3SDKError); ok && s3err.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,210 @@
+package cloudos
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func testCloudOSer(t *testing.T, cloudoser CloudOSer, content string) {
+	dir, err := ioutil.TempDir("", "cloudos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src")
+	if err := ioutil.WriteFile(src, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, objkey := range []string{"group/backup.zip", "empty"} {
+		if objkey == "empty" {
+			ioutil.WriteFile(src, nil, 0644)
+			content = ""
+		}
+		if err := cloudoser.PutObject(objkey, src); err != nil {
+			t.Fatalf("put object %s: %v", objkey, err)
+		}
+		dst := filepath.Join(dir, "dst")
+		if err := cloudoser.GetObject(objkey, dst); err != nil {
+			t.Fatalf("get object %s: %v", objkey, err)
+		}
+		if body, _ := ioutil.ReadFile(dst); string(body) != content {
+			t.Errorf("expect object %s content %q, got %q", objkey, content, body)
+		}
+		if err := cloudoser.DeleteObject(objkey); err != nil {
+			t.Fatalf("delete object %s: %v", objkey, err)
+		}
+		err := cloudoser.GetObject(objkey, dst)
+		if s3err, ok := err.(S3SDKError); !ok || s3err.StatusCode != http.StatusNotFound {
+			t.Errorf("expect not found error after delete, got %v", err)
+		}
+		if err := cloudoser.DeleteObject(objkey); err != nil {
+			t.Errorf("delete not exist object %s: %v", objkey, err)
+		}
+	}
+}
+
+func TestFilesystemStorage(t *testing.T) {
+	base, err := ioutil.TempDir("", "filesystem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+	cloudoser, err := New(&Config{ProviderType: S3ProviderFilesystem, BucketName: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCloudOSer(t, cloudoser, "filesystem")
+
+	src := filepath.Join(base, "src")
+	ioutil.WriteFile(src, []byte("x"), 0644)
+	if err := cloudoser.PutObject("../../escape", src); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "escape")); err != nil {
+		t.Errorf("the object should be stored in base directory: %v", err)
+	}
+	if _, err := New(&Config{ProviderType: S3ProviderFilesystem, BucketName: "relative"}); err == nil {
+		t.Errorf("expect error for relative directory")
+	}
+}
+
+// memObjects the objects of fake storage server
+type memObjects struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+	blocks  map[string][]byte
+}
+
+func TestAzureBlobStorage(t *testing.T) {
+	defer func(size int64) { azureBlockSize = size }(azureBlockSize)
+	azureBlockSize = 4
+	mem := &memObjects{objects: make(map[string][]byte), blocks: make(map[string][]byte)}
+	var authorized bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mem.lock.Lock()
+		defer mem.lock.Unlock()
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devstoreaccount1:") || r.Header.Get("x-ms-date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		authorized = true
+		key := strings.TrimPrefix(r.URL.Path, "/devstoreaccount1/backups/")
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "block":
+			mem.blocks[r.URL.Query().Get("blockid")] = body
+		case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "blocklist":
+			var content []byte
+			for _, item := range strings.Split(string(body), "<Latest>")[1:] {
+				content = append(content, mem.blocks[strings.Split(item, "</Latest>")[0]]...)
+			}
+			mem.objects[key] = content
+		case r.Method == http.MethodPut:
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mem.objects[key] = body
+		case r.Method == http.MethodGet || r.Method == http.MethodDelete:
+			content, ok := mem.objects[key]
+			if !ok {
+				w.Header().Set("x-ms-error-code", "BlobNotFound")
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				delete(mem.objects, key)
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.Write(content)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	cloudoser, err := New(&Config{
+		ProviderType: S3ProviderAzureBlob,
+		Endpoint:     server.URL + "/devstoreaccount1",
+		AccessKey:    "devstoreaccount1",
+		SecretKey:    "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==",
+		BucketName:   "backups",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCloudOSer(t, cloudoser, "azure blob storage")
+	if !authorized {
+		t.Errorf("the requests should be signed by shared key")
+	}
+}
+
+func TestGCSStorage(t *testing.T) {
+	mem := &memObjects{objects: make(map[string][]byte)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mem.lock.Lock()
+		defer mem.lock.Unlock()
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/backups/o" && r.URL.Query().Get("uploadType") == "media" {
+			body, _ := ioutil.ReadAll(r.Body)
+			mem.objects[r.URL.Query().Get("name")] = body
+			w.Write([]byte(`{}`))
+			return
+		}
+		dir, escaped := path.Split(r.URL.EscapedPath())
+		key, err := url.PathUnescape(escaped)
+		if dir != "/storage/v1/b/backups/o/" || err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, ok := mem.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "No such object"}}`))
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(mem.objects, key)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Query().Get("alt") != "media" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+	cloudoser, err := New(&Config{
+		ProviderType: S3ProviderGCS,
+		Endpoint:     server.URL,
+		SecretKey:    "token",
+		BucketName:   "backups",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCloudOSer(t, cloudoser, "google cloud storage")
+}
+
+func TestStr2S3Provider(t *testing.T) {
+	for _, provider := range []S3Provider{S3ProviderS3, S3ProviderAliOSS, S3ProviderAzureBlob, S3ProviderGCS, S3ProviderFilesystem, S3ProviderSFTP} {
+		if p, err := Str2S3Provider(provider.String()); err != nil || p != provider {
+			t.Errorf("expect provider %s, got %s %v", provider, p, err)
+		}
+	}
+	if _, err := Str2S3Provider("ftp"); err != ErrUnsupportedS3Provider {
+		t.Errorf("expect unsupported provider error, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package cloudos
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goodrain/rainbond/builder/sources"
+)
+
+// sftpIdleTimeout the connection is closed if it is not used during the timeout.
+var sftpIdleTimeout = time.Minute
+
+// sftpStorage the driver stores the objects in the sftp server.
+// The endpoint is the address of server, the access key and secret key are the user name and password,
+// and the bucket name is the base directory.
+type sftpStorage struct {
+	*Config
+	host, port string
+
+	lock   sync.Mutex
+	client *sources.SFTPClient
+	idle   *time.Timer
+}
+
+func newSFTP(cfg *Config) (CloudOSer, error) {
+	if cfg.Endpoint == "" || cfg.BucketName == "" {
+		return nil, fmt.Errorf("the endpoint and directory of sftp storage are required")
+	}
+	host, port, err := net.SplitHostPort(cfg.Endpoint)
+	if err != nil {
+		host, port = cfg.Endpoint, "22"
+	}
+	return &sftpStorage{Config: cfg, host: host, port: port}, nil
+}
+
+// do runs the function with the connection, the connection is reused by the following operations
+// and closed when it is idle or broken.
+func (s *sftpStorage) do(f func(client *sources.SFTPClient) error) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.client == nil {
+		client, err := sources.NewSFTPClient(s.AccessKey, s.SecretKey, s.host, s.port)
+		if err != nil {
+			return err
+		}
+		s.client = client
+		s.idle = time.AfterFunc(sftpIdleTimeout, s.closeIdle)
+	}
+	s.idle.Reset(sftpIdleTimeout)
+	err := f(s.client)
+	if err != nil {
+		if _, statErr := s.client.FileExist("/"); statErr != nil {
+			s.close()
+		}
+	}
+	return err
+}
+
+func (s *sftpStorage) closeIdle() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.close()
+}
+
+func (s *sftpStorage) close() {
+	if s.client != nil {
+		s.idle.Stop()
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+func (s *sftpStorage) PutObject(objkey, filepath string) error {
+	dst, err := objectPath(s.BucketName, objkey)
+	if err != nil {
+		return err
+	}
+	return s.do(func(client *sources.SFTPClient) error {
+		return client.UploadFile(filepath, dst)
+	})
+}
+
+func (s *sftpStorage) GetObject(objkey, filePath string) error {
+	src, err := objectPath(s.BucketName, objkey)
+	if err != nil {
+		return err
+	}
+	return s.do(func(client *sources.SFTPClient) error {
+		if exist, err := client.FileExist(src); !exist {
+			if os.IsNotExist(err) {
+				return S3SDKError{Code: "NoSuchKey", Message: err.Error(), StatusCode: http.StatusNotFound}
+			}
+			return err
+		}
+		return client.DownloadFile(src, filePath, nil)
+	})
+}
+
+func (s *sftpStorage) DeleteObject(objkey string) error {
+	dst, err := objectPath(s.BucketName, objkey)
+	if err != nil {
+		return err
+	}
+	return s.do(func(client *sources.SFTPClient) error {
+		return client.RemoveFile(dst)
+	})
+}
@@ -0,0 +1,384 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package exector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/goodrain/rainbond/builder/cloudos"
+	"github.com/sirupsen/logrus"
+)
+
+//backupChunkSize the size of the content-addressed chunk of incremental backup
+var backupChunkSize = 4 * 1024 * 1024
+
+//backupManifest describe all files of an incremental backup,
+//the content of files is stored as chunks named by sha256 of the content.
+type backupManifest struct {
+	BackupID   string        `json:"backup_id"`
+	GroupID    string        `json:"group_id"`
+	CreateTime time.Time     `json:"create_time"`
+	Files      []*backupFile `json:"files"`
+}
+
+type backupFile struct {
+	//Path the slash separated relative path in backup
+	Path    string      `json:"path"`
+	Mode    os.FileMode `json:"mode"`
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"mod_time"`
+	UID     int         `json:"uid"`
+	GID     int         `json:"gid"`
+	//Link the target of symlink
+	Link   string   `json:"link,omitempty"`
+	Chunks []string `json:"chunks,omitempty"`
+}
+
+//chunkIndex the chunks already stored in object storage, key is chunk hash and value is chunk size.
+type chunkIndex struct {
+	Chunks map[string]int64 `json:"chunks"`
+}
+
+//chunkStore store the backup data as content-addressed chunks in object storage,
+//the chunk already exist would not be uploaded again.
+type chunkStore struct {
+	cloudoser cloudos.CloudOSer
+	prefix    string
+	tmpDir    string
+	index     chunkIndex
+	//uploaded the size of chunks uploaded by this store
+	uploaded int64
+}
+
+//chunkPrefix the object key prefix of chunk store,
+//the encrypted chunks are stored separately so that the plain chunks are never referenced by encrypted backups.
+func chunkPrefix(groupID string, encrypted bool) string {
+	if encrypted {
+		return path.Join(groupID, "encrypted")
+	}
+	return groupID
+}
+
+//newChunkStore create chunk store with the object key prefix of group app, the chunks of the same group app are shared between backups
+func newChunkStore(cloudoser cloudos.CloudOSer, prefix, tmpDir string) (*chunkStore, error) {
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, err
+	}
+	c := &chunkStore{
+		cloudoser: cloudoser,
+		prefix:    prefix,
+		tmpDir:    tmpDir,
+		index:     chunkIndex{Chunks: make(map[string]int64)},
+	}
+	indexFile := path.Join(tmpDir, "index.json")
+	if err := cloudoser.GetObject(c.indexKey(), indexFile); err != nil {
+		//the index does not exist before the first incremental backup,
+		//all chunks will be uploaded if the index is lost.
+		logrus.Warningf("get backup chunk index of group %s failure, all chunks will be uploaded: %v", prefix, err)
+		return c, nil
+	}
+	body, err := ioutil.ReadFile(indexFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &c.index); err != nil {
+		logrus.Warningf("backup chunk index of group %s is invalid, all chunks will be uploaded: %v", prefix, err)
+	}
+	if c.index.Chunks == nil {
+		c.index.Chunks = make(map[string]int64)
+	}
+	return c, nil
+}
+
+func (c *chunkStore) indexKey() string {
+	return path.Join(c.prefix, "chunks", "index.json")
+}
+
+func (c *chunkStore) chunkKey(hash string) string {
+	return path.Join(c.prefix, "chunks", hash[:2], hash)
+}
+
+func (c *chunkStore) manifestKey(backupID string) string {
+	return path.Join(c.prefix, "manifests", backupID+".json")
+}
+
+//addDir add all files in dir to manifest with the relative path prefix
+func (c *chunkStore) addDir(m *backupManifest, dir, prefix string) error {
+	return filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		return c.addFile(m, filePath, path.Join(prefix, filepath.ToSlash(rel)), info)
+	})
+}
+
+//addFile split the file into chunks and upload the chunks not exist
+func (c *chunkStore) addFile(m *backupManifest, filePath, rel string, info os.FileInfo) error {
+	file := &backupFile{
+		Path:    rel,
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		file.UID, file.GID = int(stat.Uid), int(stat.Gid)
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(filePath)
+		if err != nil {
+			return err
+		}
+		file.Link = link
+	case info.Mode().IsRegular():
+		fp, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+		buf := make([]byte, backupChunkSize)
+		for {
+			n, err := io.ReadFull(fp, buf)
+			if n > 0 {
+				hash, perr := c.putChunk(buf[:n])
+				if perr != nil {
+					return perr
+				}
+				file.Chunks = append(file.Chunks, hash)
+				file.Size += int64(n)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case !info.IsDir():
+		//socket, device and pipe can not be backup
+		logrus.Warningf("skip backup special file %s", filePath)
+		return nil
+	}
+	m.Files = append(m.Files, file)
+	return nil
+}
+
+func (c *chunkStore) putChunk(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if _, ok := c.index.Chunks[hash]; ok {
+		return hash, nil
+	}
+	tmpFile := path.Join(c.tmpDir, hash)
+	if err := ioutil.WriteFile(tmpFile, data, 0644); err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile)
+	if err := c.cloudoser.PutObject(c.chunkKey(hash), tmpFile); err != nil {
+		return "", fmt.Errorf("put chunk %s: %v", hash, err)
+	}
+	c.index.Chunks[hash] = int64(len(data))
+	c.uploaded += int64(len(data))
+	return hash, nil
+}
+
+//saveManifest upload the manifest and the chunk index, return the object key of manifest
+func (c *chunkStore) saveManifest(m *backupManifest) (string, error) {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	manifestFile := path.Join(c.tmpDir, "manifest.json")
+	if err := ioutil.WriteFile(manifestFile, body, 0644); err != nil {
+		return "", err
+	}
+	if err := c.saveIndex(); err != nil {
+		return "", err
+	}
+	key := c.manifestKey(m.BackupID)
+	if err := c.cloudoser.PutObject(key, manifestFile); err != nil {
+		return "", fmt.Errorf("put backup manifest: %v", err)
+	}
+	c.uploaded += int64(len(body))
+	return key, nil
+}
+
+func (c *chunkStore) saveIndex() error {
+	body, err := json.Marshal(c.index)
+	if err != nil {
+		return err
+	}
+	indexFile := path.Join(c.tmpDir, "index.json")
+	if err := ioutil.WriteFile(indexFile, body, 0644); err != nil {
+		return err
+	}
+	if err := c.cloudoser.PutObject(c.indexKey(), indexFile); err != nil {
+		return fmt.Errorf("put backup chunk index: %v", err)
+	}
+	return nil
+}
+
+//getManifest download the manifest of backup
+func (c *chunkStore) getManifest(backupID string) (*backupManifest, error) {
+	manifestFile := path.Join(c.tmpDir, backupID+".json")
+	if err := c.cloudoser.GetObject(c.manifestKey(backupID), manifestFile); err != nil {
+		return nil, fmt.Errorf("get manifest of backup %s: %v", backupID, err)
+	}
+	defer os.Remove(manifestFile)
+	body, err := ioutil.ReadFile(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var m backupManifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("manifest of backup %s is invalid: %v", backupID, err)
+	}
+	return &m, nil
+}
+
+//restore rebuild all files of the manifest into dst dir
+func (c *chunkStore) restore(m *backupManifest, dst string) error {
+	var dirs []*backupFile
+	//the sibling dirs with the same prefix, such as dst-other, are not in dst
+	root := filepath.Clean(dst) + string(os.PathSeparator)
+	for _, file := range m.Files {
+		target := filepath.Join(dst, filepath.FromSlash(file.Path))
+		if !strings.HasPrefix(target, root) {
+			return fmt.Errorf("invalid file path %s in backup manifest", file.Path)
+		}
+		switch {
+		case file.Mode.IsDir():
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			dirs = append(dirs, file)
+			continue
+		case file.Link != "":
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Symlink(file.Link, target); err != nil {
+				return err
+			}
+			os.Lchown(target, file.UID, file.GID)
+			continue
+		}
+		if err := c.restoreFile(file, target); err != nil {
+			return err
+		}
+	}
+	//set the mode of dir after all files are written, the dir may be readonly.
+	for _, dir := range dirs {
+		target := filepath.Join(dst, filepath.FromSlash(dir.Path))
+		os.Chmod(target, dir.Mode.Perm())
+		os.Chown(target, dir.UID, dir.GID)
+		os.Chtimes(target, dir.ModTime, dir.ModTime)
+	}
+	return nil
+}
+
+func (c *chunkStore) restoreFile(file *backupFile, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	fp, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, file.Mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	for _, hash := range file.Chunks {
+		data, err := c.getChunk(hash)
+		if err != nil {
+			return fmt.Errorf("restore file %s: %v", file.Path, err)
+		}
+		if _, err := fp.Write(data); err != nil {
+			return err
+		}
+	}
+	os.Chown(target, file.UID, file.GID)
+	return os.Chtimes(target, file.ModTime, file.ModTime)
+}
+
+func (c *chunkStore) getChunk(hash string) ([]byte, error) {
+	if len(hash) < 2 {
+		return nil, fmt.Errorf("invalid chunk hash %s", hash)
+	}
+	chunkFile := path.Join(c.tmpDir, hash)
+	if _, err := os.Stat(chunkFile); err != nil {
+		if err := c.cloudoser.GetObject(c.chunkKey(hash), chunkFile); err != nil {
+			return nil, fmt.Errorf("get chunk %s: %v", hash, err)
+		}
+	}
+	data, err := ioutil.ReadFile(chunkFile)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != hash {
+		os.Remove(chunkFile)
+		return nil, fmt.Errorf("chunk %s is corrupted", hash)
+	}
+	return data, nil
+}
+
+//prune delete the manifests of expired backups and the chunks not referenced by the live manifests
+func (c *chunkStore) prune(expired []string, live []*backupManifest) error {
+	for _, backupID := range expired {
+		if err := c.cloudoser.DeleteObject(c.manifestKey(backupID)); err != nil {
+			return fmt.Errorf("delete manifest of backup %s: %v", backupID, err)
+		}
+	}
+	referenced := make(map[string]struct{})
+	for _, m := range live {
+		for _, file := range m.Files {
+			for _, hash := range file.Chunks {
+				referenced[hash] = struct{}{}
+			}
+		}
+	}
+	var deleted int
+	for hash := range c.index.Chunks {
+		if _, ok := referenced[hash]; ok {
+			continue
+		}
+		if err := c.cloudoser.DeleteObject(c.chunkKey(hash)); err != nil {
+			logrus.Warningf("delete backup chunk %s failure: %v", hash, err)
+			continue
+		}
+		delete(c.index.Chunks, hash)
+		deleted++
+	}
+	logrus.Infof("pruned %d backups and %d chunks of group %s", len(expired), deleted, c.prefix)
+	return c.saveIndex()
+}
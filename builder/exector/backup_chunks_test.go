@@ -0,0 +1,229 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package exector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+//memCloudOS object storage in memory
+type memCloudOS struct {
+	objects map[string][]byte
+	puts    int
+}
+
+func (m *memCloudOS) PutObject(objkey, filepath string) error {
+	body, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+	m.objects[objkey] = body
+	m.puts++
+	return nil
+}
+
+func (m *memCloudOS) GetObject(objkey, filepath string) error {
+	body, ok := m.objects[objkey]
+	if !ok {
+		return fmt.Errorf("object %s not found", objkey)
+	}
+	return ioutil.WriteFile(filepath, body, 0644)
+}
+
+func (m *memCloudOS) DeleteObject(objkey string) error {
+	delete(m.objects, objkey)
+	return nil
+}
+
+func readTree(t *testing.T, dir string) map[string]string {
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, _ := os.Readlink(path)
+			files[rel] = "->" + link
+			return nil
+		}
+		body, err := ioutil.ReadFile(path)
+		files[rel] = string(body)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestChunkStoreIncremental(t *testing.T) {
+	defer func(size int) { backupChunkSize = size }(backupChunkSize)
+	backupChunkSize = 8
+	tmp, err := ioutil.TempDir("", "backup-chunks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(filepath.Join(src, "data", "empty"), 0755)
+	ioutil.WriteFile(filepath.Join(src, "data", "big"), []byte("0123456789abcdefghij"), 0644)
+	ioutil.WriteFile(filepath.Join(src, "data", "small"), []byte("v1"), 0600)
+	os.Symlink("big", filepath.Join(src, "data", "link"))
+
+	oss := &memCloudOS{objects: make(map[string][]byte)}
+	backup := func(backupID string) (map[string]string, int64) {
+		store, err := newChunkStore(oss, "group", filepath.Join(tmp, "cache", backupID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := &backupManifest{BackupID: backupID, GroupID: "group"}
+		if err := store.addDir(m, src, "data_s1"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.saveManifest(m); err != nil {
+			t.Fatal(err)
+		}
+		return readTree(t, src), store.uploaded
+	}
+	first, firstSize := backup("b1")
+	//only the changed chunk is uploaded
+	ioutil.WriteFile(filepath.Join(src, "data", "big"), []byte("0123456789abcdefXXXX"), 0644)
+	second, secondSize := backup("b2")
+	if secondSize >= firstSize || secondSize <= 0 {
+		t.Errorf("expect incremental backup uploads less data, first %d, second %d", firstSize, secondSize)
+	}
+
+	restore := func(backupID string) map[string]string {
+		store, err := newChunkStore(oss, "group", filepath.Join(tmp, "cache", "restore"+backupID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := store.getManifest(backupID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := filepath.Join(tmp, "restore", backupID)
+		if err := store.restore(m, dst); err != nil {
+			t.Fatal(err)
+		}
+		if info, err := os.Stat(filepath.Join(dst, "data_s1", "data", "empty")); err != nil || !info.IsDir() {
+			t.Errorf("empty dir is not restored: %v", err)
+		}
+		if info, err := os.Stat(filepath.Join(dst, "data_s1", "data", "small")); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("file mode is not restored: %v", err)
+		}
+		return readTree(t, filepath.Join(dst, "data_s1"))
+	}
+	if got := restore("b1"); !reflect.DeepEqual(got, first) {
+		t.Errorf("restore b1: expect %v, got %v", first, got)
+	}
+	if got := restore("b2"); !reflect.DeepEqual(got, second) {
+		t.Errorf("restore b2: expect %v, got %v", second, got)
+	}
+
+	//prune b1, the chunks only referenced by b1 are deleted
+	store, _ := newChunkStore(oss, "group", filepath.Join(tmp, "cache", "prune"))
+	live, _ := store.getManifest("b2")
+	before := len(store.index.Chunks)
+	if err := store.prune([]string{"b1"}, []*backupManifest{live}); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.index.Chunks) != before-1 {
+		t.Errorf("expect 1 chunk pruned, before %d, after %d", before, len(store.index.Chunks))
+	}
+	if _, ok := oss.objects["group/manifests/b1.json"]; ok {
+		t.Error("manifest of b1 should be deleted")
+	}
+	if got := restore("b2"); !reflect.DeepEqual(got, second) {
+		t.Errorf("restore b2 after prune: expect %v, got %v", second, got)
+	}
+
+	//corrupted chunk must be detected
+	for key := range oss.objects {
+		if strings.HasPrefix(key, "group/chunks/") && key != "group/chunks/index.json" {
+			oss.objects[key] = []byte("corrupted")
+		}
+	}
+	store, _ = newChunkStore(oss, "group", filepath.Join(tmp, "cache", "corrupted"))
+	if err := store.restore(live, filepath.Join(tmp, "corrupted")); err == nil {
+		t.Error("expect error when restore corrupted chunks")
+	}
+}
+
+func TestChunkStoreRestoreOutOfDir(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "backup-chunks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store, err := newChunkStore(&memCloudOS{objects: make(map[string][]byte)}, "group", filepath.Join(tmp, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(tmp, "restore")
+	for _, p := range []string{"../restore-other/data", "../cache", ".."} {
+		m := &backupManifest{BackupID: "b1", GroupID: "group", Files: []*backupFile{{Path: p, Mode: os.ModeDir | 0755}}}
+		if err := store.restore(m, dst); err == nil {
+			t.Errorf("expect error restoring %s out of the dir", p)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "restore-other")); !os.IsNotExist(err) {
+		t.Errorf("the dir out of restore dir should not be created: %v", err)
+	}
+}
+
+func TestExpiredBackups(t *testing.T) {
+	now := time.Date(2020, 3, 18, 2, 0, 0, 0, time.UTC)
+	var backups []*dbmodel.AppBackup
+	//two backups every day in the last 30 days
+	for i := 0; i < 60; i++ {
+		backup := &dbmodel.AppBackup{BackupID: fmt.Sprintf("b%d", i), Status: "success"}
+		backup.CreatedAt = now.Add(-time.Duration(i) * 12 * time.Hour)
+		backups = append(backups, backup)
+	}
+	backups[1].Status = "failed"
+	backups[2].Status = "starting"
+	expired := expiredBackups(backups, 3, 2)
+	kept := make(map[string]bool)
+	for _, backup := range backups {
+		kept[backup.BackupID] = true
+	}
+	for _, backup := range expired {
+		delete(kept, backup.BackupID)
+	}
+	//daily: b0 of 03-18, b3 of 03-16 and b5 of 03-15, there is no success backup on 03-17.
+	//weekly: b0 of this week and b5 of last week ends on sunday 03-15, b2 is running.
+	expect := map[string]bool{"b0": true, "b2": true, "b3": true, "b5": true}
+	if !reflect.DeepEqual(kept, expect) {
+		t.Errorf("expect kept %v, got %v", expect, kept)
+	}
+	if len(expiredBackups(backups[:1], 1, 0)) != 0 {
+		t.Error("the newest backup should be kept")
+	}
+}
@@ -98,6 +98,15 @@ func NewSouceCodeBuildItem(in []byte) *SourceCodeBuildItem {
 		TenantID:      gjson.GetBytes(in, "tenant_id").String(),
 		ServiceID:     gjson.GetBytes(in, "service_id").String(),
 	}
+	if options := gjson.GetBytes(in, "clone_options"); options.Exists() {
+		if err := ffjson.Unmarshal([]byte(options.Raw), &csi.CloneOptions); err != nil {
+			logrus.Errorf("unmarshal clone options error: %s", err.Error())
+		}
+	}
+	// the commit pushed by webhook is built if ref is not specified
+	if commit := gjson.GetBytes(in, "commit").String(); commit != "" && csi.CloneOptions.Ref == "" {
+		csi.CloneOptions.Ref = commit
+	}
 	envs := gjson.GetBytes(in, "envs").String()
 	be := make(map[string]string)
 	if err := ffjson.Unmarshal([]byte(envs), &be); err != nil {
@@ -171,7 +180,7 @@ func (i *SourceCodeBuildItem) Run(timeout time.Duration) error {
 			i.Logger.Error(fmt.Sprintf("拉取代码失败，请确保代码可以被正常下载"), map[string]string{"step": "builder-exector", "status": "failure"})
 			return err
 		}
-		//get last commit
+		//get the commit checked out, it is resolved from the ref of clone options if set
 		commit, err := sources.GetLastCommit(rs)
 		if err != nil || commit == nil {
 			logrus.Errorf("get code commit info error: %s", err.Error())
@@ -248,7 +257,7 @@ func (i *SourceCodeBuildItem) codeBuild() (*build.Response, error) {
 		Runtime:       i.Runtime,
 		Branch:        i.CodeSouceInfo.Branch,
 		DeployVersion: i.DeployVersion,
-		Commit:        build.Commit{User: i.commit.Author, Message: i.commit.Message, Hash: i.commit.Hash},
+		Commit:        build.Commit{User: i.commit.Author, Message: i.commit.Message, Hash: i.commit.Hash, Ref: i.CodeSouceInfo.CloneOptions.Ref},
 		Lang:          code.Lang(i.Lang),
 		BuildEnvs:     i.BuildEnvs,
 		Logger:        i.Logger,
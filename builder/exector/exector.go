@@ -25,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -86,6 +87,10 @@ func NewManager(conf option.Config, mqc mqclient.MQClient) (Manager, error) {
 	if err != nil {
 		return nil, err
 	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
 	etcdClientArgs := &etcdutil.ClientArgs{
 		Endpoints: conf.EtcdEndPoints,
 		CaFile:    conf.EtcdCaFile,
@@ -113,6 +118,8 @@ func NewManager(conf option.Config, mqc mqclient.MQClient) (Manager, error) {
 	return &exectorManager{
 		DockerClient:      dockerClient,
 		KubeClient:        kubeClient,
+		DynamicClient:     dynamicClient,
+		RestConfig:        restConfig,
 		EtcdCli:           etcdCli,
 		mqClient:          mqc,
 		tasks:             make(chan *pb.TaskMessage, maxConcurrentTask),
@@ -126,6 +133,8 @@ func NewManager(conf option.Config, mqc mqclient.MQClient) (Manager, error) {
 type exectorManager struct {
 	DockerClient      *client.Client
 	KubeClient        kubernetes.Interface
+	DynamicClient     dynamic.Interface
+	RestConfig        *rest.Config
 	EtcdCli           *clientv3.Client
 	tasks             chan *pb.TaskMessage
 	callback          func(*pb.TaskMessage)
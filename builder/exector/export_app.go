@@ -19,6 +19,7 @@
 package exector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -33,22 +34,28 @@ import (
 	"github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
 	ramv1alpha1 "github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
 	"github.com/goodrain/rainbond/builder"
+	"github.com/goodrain/rainbond/builder/cloudos"
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/event"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
+	"k8s.io/client-go/kubernetes"
 )
 
 var re = regexp.MustCompile(`\s`)
 
-//ExportApp Export app to specified format(rainbond-app or dockercompose)
+//ExportApp Export app to specified format(rainbond-app, dockercompose, helm-chart or kubernetes)
 type ExportApp struct {
 	EventID      string `json:"event_id"`
 	Format       string `json:"format"`
 	SourceDir    string `json:"source_dir"`
 	Logger       event.Logger
 	DockerClient *client.Client
+	//Encryption encrypt the package if the secret name is set
+	Encryption   cloudos.EncryptionConfig `json:"encryption"`
+	kubeClient   kubernetes.Interface
+	rbdNamespace string
 }
 
 func init() {
@@ -59,13 +66,21 @@ func init() {
 func NewExportApp(in []byte, m *exectorManager) (TaskWorker, error) {
 	eventID := gjson.GetBytes(in, "event_id").String()
 	logger := event.GetManager().GetLogger(eventID)
-	return &ExportApp{
+	exportApp := &ExportApp{
 		Format:       gjson.GetBytes(in, "format").String(),
 		SourceDir:    gjson.GetBytes(in, "source_dir").String(),
 		Logger:       logger,
 		EventID:      eventID,
 		DockerClient: m.DockerClient,
-	}, nil
+		kubeClient:   m.KubeClient,
+		rbdNamespace: m.cfg.RbdNamespace,
+	}
+	if encryption := gjson.GetBytes(in, "encryption"); encryption.Exists() {
+		if err := json.Unmarshal([]byte(encryption.Raw), &exportApp.Encryption); err != nil {
+			return nil, err
+		}
+	}
+	return exportApp, nil
 }
 
 //Run Run
@@ -100,14 +115,38 @@ func (i *ExportApp) Run(timeout time.Duration) error {
 			i.updateStatus("failed", "")
 			return err
 		}
+	} else if i.Format == "helm-chart" {
+		re, err = i.exportHelmChart(*ram)
+		if err != nil {
+			logrus.Errorf("export helm chart app package failure %s", err.Error())
+			i.updateStatus("failed", "")
+			return err
+		}
+	} else if i.Format == "kubernetes" {
+		re, err = i.exportKubernetes(*ram)
+		if err != nil {
+			logrus.Errorf("export kubernetes manifests failure %s", err.Error())
+			i.updateStatus("failed", "")
+			return err
+		}
 	} else {
 		return errors.New("Unsupported the format: " + i.Format)
 	}
 	if re != nil {
 		// move package file to download dir
 		downloadPath := path.Dir(i.SourceDir)
-		os.Rename(re.PackagePath, path.Join(downloadPath, re.PackageName))
-		packageDownloadPath := path.Join("/v2/app/download/", i.Format, re.PackageName)
+		packageName := re.PackageName
+		if i.Encryption.Enabled() {
+			packageName += ".enc"
+			if err := i.encryptPackage(re.PackagePath, downloadPath, packageName); err != nil {
+				logrus.Errorf("encrypt app package failure %s", err.Error())
+				i.updateStatus("failed", "")
+				return err
+			}
+		} else {
+			os.Rename(re.PackagePath, path.Join(downloadPath, re.PackageName))
+		}
+		packageDownloadPath := path.Join("/v2/app/download/", i.Format, packageName)
 		// update export event status
 		if err := i.updateStatus("success", packageDownloadPath); err != nil {
 			return err
@@ -132,6 +171,31 @@ func (i *ExportApp) handleDefaultRepo(ram *v1alpha1.RainbondApplicationConfig) {
 	}
 }
 
+//encryptPackage encrypt the package to the download dir
+func (i *ExportApp) encryptPackage(packagePath, downloadPath, packageName string) error {
+	crypter, err := newPackageCrypter(i.kubeClient, i.rbdNamespace, &i.Encryption, downloadPath)
+	if err != nil {
+		return err
+	}
+	if err := crypter.PutObject(packageName, packagePath); err != nil {
+		return err
+	}
+	return os.Remove(packagePath)
+}
+
+//newPackageCrypter create the storage encrypts the app packages in dir by the key of encryption secret
+func newPackageCrypter(kubeClient kubernetes.Interface, namespace string, encryption *cloudos.EncryptionConfig, dir string) (cloudos.CloudOSer, error) {
+	key, err := cloudos.LoadEncryptionKey(context.Background(), kubeClient, namespace, encryption)
+	if err != nil {
+		return nil, err
+	}
+	return cloudos.New(&cloudos.Config{
+		ProviderType:  cloudos.S3ProviderFilesystem,
+		BucketName:    dir,
+		EncryptionKey: key,
+	})
+}
+
 // create md5 file
 func (i *ExportApp) cacheMd5() {
 	metadataFile := fmt.Sprintf("%s/metadata.json", i.SourceDir)
@@ -154,6 +218,22 @@ func (i *ExportApp) exportDockerCompose(ram v1alpha1.RainbondApplicationConfig)
 	return ramExporter.Export()
 }
 
+// exportHelmChart export app to helm chart
+func (i *ExportApp) exportHelmChart(ram v1alpha1.RainbondApplicationConfig) (*export.Result, error) {
+	exporter := &helmChartExporter{
+		ram:        ram,
+		homePath:   i.SourceDir,
+		exportPath: path.Join(i.SourceDir, fmt.Sprintf("%s-%s-helm", ram.AppName, ram.AppVersion)),
+	}
+	return exporter.Export()
+}
+
+// exportKubernetes export app to plain kubernetes manifests
+func (i *ExportApp) exportKubernetes(ram v1alpha1.RainbondApplicationConfig) (*export.Result, error) {
+	exporter := &kubernetesExporter{ram: ram, homePath: i.SourceDir}
+	return exporter.Export()
+}
+
 //Stop stop
 func (i *ExportApp) Stop() error {
 	return nil
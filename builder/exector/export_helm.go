@@ -0,0 +1,372 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package exector
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/goodrain/rainbond-oam/pkg/export"
+	"github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
+	"github.com/sirupsen/logrus"
+)
+
+//helmTemplateFuncs the functions used to generate the helm templates,
+//the generator uses [[ ]] as delimiters so that the helm actions can be written directly.
+var helmTemplateFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+	"yaml": func(obj interface{}, indent int) (string, error) {
+		body, err := kubeYAML(obj)
+		if err != nil {
+			return "", err
+		}
+		// literal content must not be evaluated by helm
+		content := strings.Replace(strings.TrimSuffix(string(body), "\n"), "{{", `{{ "{{" }}`, -1)
+		pad := strings.Repeat(" ", indent)
+		return pad + strings.Replace(content, "\n", "\n"+pad, -1), nil
+	},
+}
+
+var helmComponentTemplate = template.Must(template.New("component").Delims("[[", "]]").Funcs(helmTemplateFuncs).Parse(`
+{{- $c := index .Values.components [[ quote .Name ]] }}
+[[- if .ConfigFiles ]]
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: [[ .ConfigMapName ]]
+  labels:
+    app.kubernetes.io/name: [[ .Name ]]
+    app.kubernetes.io/instance: {{ .Release.Name | quote }}
+data:
+  {{- toYaml $c.configFiles | nindent 2 }}
+[[- end ]]
+[[- range .StandaloneClaims ]]
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: [[ .ClaimName ]]
+  labels:
+    app.kubernetes.io/name: [[ $.Name ]]
+    app.kubernetes.io/instance: {{ .Release.Name | quote }}
+spec:
+  accessModes:
+    - [[ .AccessMode ]]
+  {{- with .Values.global.storageClass }}
+  storageClassName: {{ . | quote }}
+  {{- end }}
+  resources:
+    requests:
+      storage: {{ (index $c.persistence [[ quote .Name ]]).size | quote }}
+[[- end ]]
+[[- if .Ports ]]
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: [[ .Name ]]
+  labels:
+    app.kubernetes.io/name: [[ .Name ]]
+    app.kubernetes.io/instance: {{ .Release.Name | quote }}
+spec:
+[[- if .IsStateful ]]
+  clusterIP: None
+[[- end ]]
+  selector:
+    app.kubernetes.io/name: [[ .Name ]]
+    app.kubernetes.io/instance: {{ .Release.Name | quote }}
+  ports:
+[[ yaml .ServicePorts 4 ]]
+[[- end ]]
+[[- if .StreamPorts ]]
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: [[ .Name ]]-outer
+  labels:
+    app.kubernetes.io/name: [[ .Name ]]
+    app.kubernetes.io/instance: {{ .Release.Name | quote }}
+spec:
+  type: {{ .Values.streamService.type }}
+  selector:
+    app.kubernetes.io/name: [[ .Name ]]
+    app.kubernetes.io/instance: {{ .Release.Name | quote }}
+  ports:
+[[ yaml .StreamPorts 4 ]]
+[[- end ]]
+---
+apiVersion: apps/v1
+kind: [[ .Kind ]]
+metadata:
+  name: [[ .Name ]]
+  labels:
+    app.kubernetes.io/name: [[ .Name ]]
+    app.kubernetes.io/instance: {{ .Release.Name | quote }}
+spec:
+  replicas: {{ $c.replicas }}
+[[- if .IsStateful ]]
+  serviceName: [[ .Name ]]
+[[- end ]]
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: [[ .Name ]]
+      app.kubernetes.io/instance: {{ .Release.Name | quote }}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: [[ .Name ]]
+        app.kubernetes.io/instance: {{ .Release.Name | quote }}
+    spec:
+      containers:
+        - name: [[ .Name ]]
+          image: "{{ $c.image.repository }}{{ with $c.image.tag }}:{{ . }}{{ end }}"
+          imagePullPolicy: {{ $c.image.pullPolicy }}
+          {{- with $c.args }}
+          args:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with $c.env }}
+          env:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with $c.resources }}
+          resources:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+[[- with .Ports ]]
+          ports:
+[[ yaml . 12 ]]
+[[- end ]]
+[[- with .LivenessProbe ]]
+          livenessProbe:
+[[ yaml . 12 ]]
+[[- end ]]
+[[- with .ReadinessProbe ]]
+          readinessProbe:
+[[ yaml . 12 ]]
+[[- end ]]
+[[- with .VolumeMounts ]]
+          volumeMounts:
+[[ yaml . 12 ]]
+[[- end ]]
+[[- with .Volumes ]]
+      volumes:
+[[ yaml . 8 ]]
+[[- end ]]
+[[- with .ClaimTemplates ]]
+  volumeClaimTemplates:
+[[- range . ]]
+    - metadata:
+        name: [[ .Name ]]
+      spec:
+        accessModes:
+          - [[ .AccessMode ]]
+        {{- with $.Values.global.storageClass }}
+        storageClassName: {{ . | quote }}
+        {{- end }}
+        resources:
+          requests:
+            storage: {{ (index $c.persistence [[ quote .Name ]]).size | quote }}
+[[- end ]]
+[[- end ]]
+`))
+
+var helmIngressTemplate = template.Must(template.New("ingress").Delims("[[", "]]").Funcs(helmTemplateFuncs).Parse(`
+{{- if .Values.ingress.enabled }}
+[[- range .Ingresses ]]
+{{- $r := index $.Values.ingress.routes [[ quote .Name ]] }}
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: [[ .Name ]]
+  labels:
+    app.kubernetes.io/name: [[ .Component ]]
+    app.kubernetes.io/instance: {{ $.Release.Name | quote }}
+[[- with .Annotations ]]
+  annotations:
+[[ yaml . 4 ]]
+[[- end ]]
+spec:
+  {{- with $.Values.ingress.className }}
+  ingressClassName: {{ . | quote }}
+  {{- end }}
+  {{- if $r.tls }}
+  tls:
+    - secretName: {{ $r.tlsSecret | quote }}
+      {{- with $r.host }}
+      hosts:
+        - {{ . | quote }}
+      {{- end }}
+  {{- end }}
+  rules:
+    - http:
+        paths:
+          - path: {{ $r.path | quote }}
+            pathType: Prefix
+            backend:
+              service:
+                name: [[ .Component ]]
+                port:
+                  number: [[ .Port ]]
+      {{- with $r.host }}
+      host: {{ . | quote }}
+      {{- end }}
+[[- end ]]
+{{- end }}
+`))
+
+//helmChart the files of helm chart
+type helmChart struct {
+	Name      string
+	Chart     []byte
+	Values    []byte
+	Templates map[string][]byte
+}
+
+//newHelmChart generate the helm chart of application,
+//images, envs, replicas, resources, volume sizes and ingress rules can be changed by values.
+func newHelmChart(app *kubeApp, description string) (*helmChart, error) {
+	chart := &helmChart{Name: app.Name, Templates: make(map[string][]byte)}
+	var err error
+	chart.Chart, err = yaml.Marshal(map[string]interface{}{
+		"apiVersion":  "v2",
+		"name":        app.Name,
+		"description": description,
+		"type":        "application",
+		"version":     app.Version,
+		"appVersion":  app.AppVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	components := make(map[string]interface{}, len(app.Components))
+	for _, kc := range app.Components {
+		values := map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": kc.Repository,
+				"tag":        kc.Tag,
+				"pullPolicy": "IfNotPresent",
+			},
+			"replicas":  kc.Replicas,
+			"args":      kc.Args,
+			"env":       kc.Env,
+			"resources": kc.Resources,
+		}
+		if len(kc.ConfigFiles) > 0 {
+			values["configFiles"] = kc.ConfigFiles
+		}
+		if len(kc.Claims) > 0 {
+			persistence := make(map[string]interface{}, len(kc.Claims))
+			for _, claim := range kc.Claims {
+				persistence[claim.Name] = map[string]string{"size": claim.Size}
+			}
+			values["persistence"] = persistence
+		}
+		components[kc.Name] = values
+		var buffer bytes.Buffer
+		if err := helmComponentTemplate.Execute(&buffer, kc); err != nil {
+			return nil, fmt.Errorf("generate template of component %s failure %s", kc.Name, err.Error())
+		}
+		chart.Templates[kc.Name+".yaml"] = buffer.Bytes()
+	}
+	routes := make(map[string]interface{}, len(app.Ingresses))
+	for _, ing := range app.Ingresses {
+		routes[ing.Name] = map[string]interface{}{
+			"host":      "",
+			"path":      ing.Path,
+			"tls":       ing.TLS,
+			"tlsSecret": ing.Name + "-tls",
+		}
+	}
+	if len(routes) > 0 {
+		var buffer bytes.Buffer
+		if err := helmIngressTemplate.Execute(&buffer, app); err != nil {
+			return nil, fmt.Errorf("generate template of ingress failure %s", err.Error())
+		}
+		chart.Templates["ingress.yaml"] = buffer.Bytes()
+	}
+	chart.Values, err = yaml.Marshal(map[string]interface{}{
+		"global":        map[string]interface{}{"storageClass": ""},
+		"components":    components,
+		"ingress":       map[string]interface{}{"enabled": true, "className": "", "routes": routes},
+		"streamService": map[string]interface{}{"type": "NodePort"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chart, nil
+}
+
+//Write write the chart files to the dir
+func (h *helmChart) Write(dir string) error {
+	chartDir := path.Join(dir, h.Name)
+	if err := os.MkdirAll(path.Join(chartDir, "templates"), 0755); err != nil {
+		return err
+	}
+	files := map[string][]byte{"Chart.yaml": h.Chart, "values.yaml": h.Values}
+	for name, content := range h.Templates {
+		files[path.Join("templates", name)] = content
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(path.Join(chartDir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type helmChartExporter struct {
+	ram        v1alpha1.RainbondApplicationConfig
+	homePath   string
+	exportPath string
+}
+
+//Export export the application to the helm chart package
+func (h *helmChartExporter) Export() (*export.Result, error) {
+	logrus.Infof("start export app %s to helm chart", h.ram.AppName)
+	if err := export.PrepareExportDir(h.exportPath); err != nil {
+		return nil, fmt.Errorf("prepare export dir failure %s", err.Error())
+	}
+	chart, err := newHelmChart(newKubeApp(h.ram), fmt.Sprintf("%s exported from rainbond", h.ram.AppName))
+	if err != nil {
+		return nil, fmt.Errorf("build helm chart failure %s", err.Error())
+	}
+	if err := chart.Write(h.exportPath); err != nil {
+		return nil, fmt.Errorf("write helm chart failure %s", err.Error())
+	}
+	packageName := fmt.Sprintf("%s-%s-helm.tgz", h.ram.AppName, h.ram.AppVersion)
+	cmd := exec.Command("tar", "-czf", path.Join(h.homePath, packageName), chart.Name)
+	cmd.Dir = h.exportPath
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("Failed to package app %s: %s ", packageName, err.Error())
+	}
+	logrus.Infof("success export app %s to helm chart", h.ram.AppName)
+	return &export.Result{PackagePath: path.Join(h.homePath, packageName), PackageName: packageName}, nil
+}
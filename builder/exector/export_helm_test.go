@@ -0,0 +1,313 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package exector
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func testExportRAM() v1alpha1.RainbondApplicationConfig {
+	return v1alpha1.RainbondApplicationConfig{
+		AppName:    "Demo App",
+		AppVersion: "1.2",
+		Components: []*v1alpha1.Component{
+			{
+				ComponentKey:      "web",
+				ServiceShareID:    "web-share",
+				ServiceCname:      "Web",
+				DeployType:        v1alpha1.StatelessMultipleDeployType,
+				ExtendMethodRule:  v1alpha1.ComponentExtendMethodRule{MinNode: 2},
+				Memory:            512,
+				CPU:               500,
+				ShareImage:        "goodrain.me/web:v1",
+				Cmd:               "--db ${MYSQL_HOST}",
+				Ports:             []v1alpha1.ComponentPort{{PortAlias: "WEB", Protocol: "http", ContainerPort: 80}},
+				Envs:              []v1alpha1.ComponentEnv{{AttrName: "MODE", AttrValue: "prod"}},
+				DepServiceMapList: []v1alpha1.ComponentDep{{DepServiceKey: "mysql"}},
+				ServiceVolumeMapList: v1alpha1.ComponentVolumeList{
+					{VolumeName: "conf", VolumeType: v1alpha1.ConfigFileVolumeType, VolumeMountPath: "/etc/web.conf", FileConent: "title: {{ .Title }}"},
+					{VolumeName: "data", VolumeType: v1alpha1.ShareFileVolumeType, VolumeMountPath: "/data", VolumeCapacity: 5},
+				},
+				Probes: []v1alpha1.ComponentProbe{{IsUsed: true, Mode: "readiness", Scheme: "http", Port: 80, Path: "/health"}},
+			},
+			{
+				ComponentKey:              "mysql",
+				ServiceCname:              "MySQL",
+				DeployType:                v1alpha1.StateSingletonDeployType,
+				ShareImage:                "goodrain.me/mysql@sha256:abc",
+				Ports:                     []v1alpha1.ComponentPort{{Protocol: "mysql", ContainerPort: 3306}},
+				ServiceConnectInfoMapList: []v1alpha1.ComponentEnv{{AttrName: "MYSQL_HOST", AttrValue: "127.0.0.1"}},
+				ServiceVolumeMapList:      v1alpha1.ComponentVolumeList{{VolumeName: "data", VolumeType: v1alpha1.LocalVolumeType, VolumeMountPath: "/var/lib/mysql"}},
+				MntReleationList:          []v1alpha1.ComponentShareVolume{{VolumeName: "data", VolumeMountDir: "/web-data", ShareServiceUUID: "web-share"}},
+				Probes:                    []v1alpha1.ComponentProbe{{IsUsed: true, Mode: "liveness", Scheme: "tcp", Port: 3306}},
+			},
+		},
+		IngressHTTPRoutes: []*v1alpha1.IngressHTTPRoute{
+			{Location: "/", SSL: true, ConnectionTimeout: 75, TargetComponent: v1alpha1.TargetComponent{ComponentKey: "web", Port: 80}},
+			{Location: "/api", TargetComponent: v1alpha1.TargetComponent{ComponentKey: "web", Port: 80}},
+			{TargetComponent: v1alpha1.TargetComponent{ComponentKey: "web", Port: 8080}},
+		},
+		IngressSreamRoutes: []*v1alpha1.IngressSreamRoute{
+			{TargetComponent: v1alpha1.TargetComponent{ComponentKey: "mysql", Port: 3306}},
+		},
+	}
+}
+
+//helmFuncs the helm functions used by generated templates
+var helmFuncs = template.FuncMap{
+	"quote": func(v interface{}) string {
+		if s, ok := v.(string); ok {
+			return strconv.Quote(s)
+		}
+		body, _ := json.Marshal(v)
+		return strconv.Quote(string(body))
+	},
+	"toYaml": func(v interface{}) string {
+		body, _ := yaml.Marshal(v)
+		return strings.TrimSuffix(string(body), "\n")
+	},
+	"nindent": func(indent int, s string) string {
+		pad := strings.Repeat(" ", indent)
+		return "\n" + pad + strings.Replace(s, "\n", "\n"+pad, -1)
+	},
+}
+
+var semverRegexp = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+//lintChart check the chart as helm lint does and return the rendered documents
+func lintChart(t *testing.T, chart *helmChart, values map[string]interface{}) []map[string]interface{} {
+	var meta map[string]interface{}
+	if err := yaml.Unmarshal(chart.Chart, &meta); err != nil {
+		t.Fatalf("Chart.yaml is invalid: %v", err)
+	}
+	if meta["apiVersion"] != "v2" || meta["name"] != chart.Name {
+		t.Errorf("unexpected chart metadata %v", meta)
+	}
+	if errs := validation.IsDNS1123Label(chart.Name); len(errs) > 0 {
+		t.Errorf("chart name %s is invalid: %v", chart.Name, errs)
+	}
+	if version, _ := meta["version"].(string); !semverRegexp.MatchString(version) {
+		t.Errorf("chart version %v is not semver", meta["version"])
+	}
+	if values == nil {
+		if err := yaml.Unmarshal(chart.Values, &values); err != nil {
+			t.Fatalf("values.yaml is invalid: %v", err)
+		}
+	}
+	var names []string
+	for name := range chart.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var docs []map[string]interface{}
+	for _, name := range names {
+		tmpl, err := template.New(name).Funcs(helmFuncs).Option("missingkey=zero").Parse(string(chart.Templates[name]))
+		if err != nil {
+			t.Fatalf("parse template %s failure: %v", name, err)
+		}
+		var buffer bytes.Buffer
+		root := map[string]interface{}{"Values": values, "Release": map[string]interface{}{"Name": chart.Name}}
+		if err := tmpl.Execute(&buffer, root); err != nil {
+			t.Fatalf("render template %s failure: %v", name, err)
+		}
+		for _, doc := range strings.Split(buffer.String(), "\n---") {
+			if strings.TrimSpace(strings.TrimPrefix(doc, "---")) == "" {
+				continue
+			}
+			docs = append(docs, lintManifest(t, name, []byte(doc)))
+		}
+	}
+	return docs
+}
+
+//lintManifest check the manifest can be decoded to kubernetes resource strictly
+func lintManifest(t *testing.T, name string, doc []byte) map[string]interface{} {
+	body, err := yaml.YAMLToJSON(doc)
+	if err != nil {
+		t.Fatalf("template %s renders invalid yaml: %v\n%s", name, err, doc)
+	}
+	var manifest map[string]interface{}
+	json.Unmarshal(body, &manifest)
+	var obj interface{}
+	switch manifest["kind"] {
+	case "Deployment":
+		obj = &appsv1.Deployment{}
+	case "StatefulSet":
+		obj = &appsv1.StatefulSet{}
+	case "Service":
+		obj = &corev1.Service{}
+	case "ConfigMap":
+		obj = &corev1.ConfigMap{}
+	case "PersistentVolumeClaim":
+		obj = &corev1.PersistentVolumeClaim{}
+	case "Ingress":
+		obj = &networkingv1.Ingress{}
+	default:
+		t.Fatalf("template %s renders unexpected kind %v", name, manifest["kind"])
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		t.Fatalf("template %s renders invalid %v: %v\n%s", name, manifest["kind"], err, doc)
+	}
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	if errs := validation.IsDNS1123Label(metadata["name"].(string)); len(errs) > 0 {
+		t.Errorf("resource name %v is invalid: %v", metadata["name"], errs)
+	}
+	return manifest
+}
+
+func manifestKey(manifest map[string]interface{}) string {
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	return manifest["kind"].(string) + "/" + metadata["name"].(string)
+}
+
+func TestHelmChartLint(t *testing.T) {
+	app := newKubeApp(testExportRAM())
+	chart, err := newHelmChart(app, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := lintChart(t, chart, nil)
+	manifests, err := app.ManifestsYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expects := make(map[string]map[string]interface{})
+	for _, doc := range strings.Split(string(manifests), "---\n") {
+		if doc != "" {
+			manifest := lintManifest(t, "manifests", []byte(doc))
+			expects[manifestKey(manifest)] = manifest
+		}
+	}
+	if len(docs) != len(expects) {
+		t.Fatalf("chart renders %d resources, but manifests have %d", len(docs), len(expects))
+	}
+	for _, doc := range docs {
+		expect := expects[manifestKey(doc)]
+		if !reflect.DeepEqual(doc, expect) {
+			left, _ := yaml.Marshal(doc)
+			right, _ := yaml.Marshal(expect)
+			t.Errorf("chart renders different resource with manifests:\n%s\n---\n%s", left, right)
+		}
+	}
+}
+
+func TestKubeApp(t *testing.T) {
+	app := newKubeApp(testExportRAM())
+	if app.Name != "demo-app" || app.Version != "1.2.0" {
+		t.Errorf("unexpected app name %s or version %s", app.Name, app.Version)
+	}
+	web, mysql := app.Components[0], app.Components[1]
+	if web.Kind != "Deployment" || web.Replicas != 2 || web.Image() != "goodrain.me/web:v1" {
+		t.Errorf("unexpected web component %+v", web)
+	}
+	if mysql.Kind != "StatefulSet" || mysql.Replicas != 1 || mysql.Image() != "goodrain.me/mysql@sha256:abc" {
+		t.Errorf("unexpected mysql component %+v", mysql)
+	}
+	if len(web.Args) != 2 || web.Args[1] != "mysql" {
+		t.Errorf("dependency host should be the service name, got args %v", web.Args)
+	}
+	if len(mysql.ClaimTemplates()) != 1 || len(web.StandaloneClaims()) != 1 || web.Claims[0].AccessMode != corev1.ReadWriteMany {
+		t.Errorf("unexpected claims %v %v", web.Claims, mysql.Claims)
+	}
+	var shared bool
+	for _, vol := range mysql.Volumes {
+		shared = shared || (vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == "web-data")
+	}
+	if !shared {
+		t.Errorf("shared volume of web is not mounted by mysql: %v", mysql.Volumes)
+	}
+	if len(app.Ingresses) != 2 || app.Ingresses[1].Name != "web-80-2" || app.Ingresses[0].Annotations["nginx.ingress.kubernetes.io/proxy-connect-timeout"] != "75" {
+		t.Errorf("unexpected ingresses %v", app.Ingresses)
+	}
+	if len(mysql.StreamPorts) != 1 || mysql.StreamPorts[0].Port != 3306 {
+		t.Errorf("unexpected stream ports %v", mysql.StreamPorts)
+	}
+}
+
+func TestHelmChartValues(t *testing.T) {
+	chart, err := newHelmChart(newKubeApp(testExportRAM()), "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(chart.Values, &values); err != nil {
+		t.Fatal(err)
+	}
+	web := values["components"].(map[string]interface{})["web"].(map[string]interface{})
+	web["replicas"] = 5
+	web["image"].(map[string]interface{})["tag"] = "v2"
+	web["persistence"].(map[string]interface{})["data"] = map[string]interface{}{"size": "20Gi"}
+	values["global"] = map[string]interface{}{"storageClass": "fast"}
+	values["ingress"].(map[string]interface{})["routes"].(map[string]interface{})["web-80"].(map[string]interface{})["host"] = "demo.example.com"
+	docs := lintChart(t, chart, values)
+	var checked int
+	for _, doc := range docs {
+		body, _ := json.Marshal(doc)
+		switch doc["kind"] {
+		case "Deployment":
+			var deploy appsv1.Deployment
+			json.Unmarshal(body, &deploy)
+			if *deploy.Spec.Replicas != 5 || deploy.Spec.Template.Spec.Containers[0].Image != "goodrain.me/web:v2" {
+				t.Errorf("values are not applied to deployment: %s", body)
+			}
+			checked++
+		case "PersistentVolumeClaim":
+			var pvc corev1.PersistentVolumeClaim
+			json.Unmarshal(body, &pvc)
+			if storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; storage.String() != "20Gi" || *pvc.Spec.StorageClassName != "fast" {
+				t.Errorf("values are not applied to pvc: %s", body)
+			}
+			checked++
+		case "ConfigMap":
+			var cm corev1.ConfigMap
+			json.Unmarshal(body, &cm)
+			if cm.Data["conf"] != "title: {{ .Title }}" {
+				t.Errorf("config file is changed: %v", cm.Data)
+			}
+			checked++
+		case "Ingress":
+			var ing networkingv1.Ingress
+			json.Unmarshal(body, &ing)
+			if ing.Name == "web-80" && (ing.Spec.Rules[0].Host != "demo.example.com" || ing.Spec.TLS[0].Hosts[0] != "demo.example.com") {
+				t.Errorf("values are not applied to ingress: %s", body)
+			}
+			checked++
+		}
+	}
+	if checked != 5 {
+		t.Errorf("expect 5 resources checked, got %d", checked)
+	}
+}
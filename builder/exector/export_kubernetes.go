@@ -0,0 +1,799 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package exector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/goodrain/rainbond-oam/pkg/export"
+	"github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
+	"github.com/goodrain/rainbond/util"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+//labels of the exported kubernetes resources
+const (
+	kubeNameLabel     = "app.kubernetes.io/name"
+	kubeInstanceLabel = "app.kubernetes.io/instance"
+)
+
+//defaultKubeVolumeSize default size of the persistent volume, unit GB
+const defaultKubeVolumeSize = 1
+
+var kubeNameReg = regexp.MustCompile(`[^a-z0-9-]+`)
+
+//kubeName convert the name to the dns-1123 label which is no longer than max
+func kubeName(name string, max int) string {
+	name = kubeNameReg.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > max {
+		name = name[:max]
+	}
+	return strings.Trim(name, "-")
+}
+
+//uniqueName make sure the name is not in the set
+func uniqueName(set map[string]struct{}, name string) string {
+	unique := name
+	for i := 2; ; i++ {
+		if _, ok := set[unique]; !ok {
+			break
+		}
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	set[unique] = struct{}{}
+	return unique
+}
+
+//kubeApp the kubernetes resources of rainbond application,
+//it is shared by the helm chart and the plain kubernetes manifests exporter
+type kubeApp struct {
+	Name       string
+	Version    string
+	AppVersion string
+	Components []*kubeComponent
+	Ingresses  []*kubeIngress
+}
+
+//kubeComponent the workload of a component
+type kubeComponent struct {
+	Name           string
+	Key            string
+	Kind           string
+	Repository     string
+	Tag            string
+	Replicas       int32
+	Args           []string
+	Env            []corev1.EnvVar
+	Resources      corev1.ResourceRequirements
+	Ports          []corev1.ContainerPort
+	LivenessProbe  *corev1.Probe
+	ReadinessProbe *corev1.Probe
+	ConfigFiles    map[string]string
+	VolumeMounts   []corev1.VolumeMount
+	Volumes        []corev1.Volume
+	Claims         []*kubeVolumeClaim
+	StreamPorts    []corev1.ServicePort
+}
+
+//kubeVolumeClaim the persistent volume of a component
+type kubeVolumeClaim struct {
+	//Name the volume name in pod
+	Name string
+	//ClaimName the name of standalone pvc, it is empty if the claim is the volume claim template of statefulset
+	ClaimName  string
+	AccessMode corev1.PersistentVolumeAccessMode
+	Size       string
+}
+
+//kubeIngress the ingress of gateway http rule
+type kubeIngress struct {
+	Name        string
+	Component   string
+	Port        int32
+	Path        string
+	TLS         bool
+	Annotations map[string]string
+}
+
+//Image return the image of component
+func (k *kubeComponent) Image() string {
+	if k.Tag == "" {
+		return k.Repository
+	}
+	return k.Repository + ":" + k.Tag
+}
+
+//IsStateful whether the component is deployed by statefulset
+func (k *kubeComponent) IsStateful() bool {
+	return k.Kind == "StatefulSet"
+}
+
+//ConfigMapName the name of configmap that stores the config files
+func (k *kubeComponent) ConfigMapName() string {
+	return k.Name + "-config"
+}
+
+//ClaimTemplates the volume claim templates of statefulset
+func (k *kubeComponent) ClaimTemplates() (claims []*kubeVolumeClaim) {
+	for _, claim := range k.Claims {
+		if claim.ClaimName == "" {
+			claims = append(claims, claim)
+		}
+	}
+	return
+}
+
+//ServicePorts the ports of component service
+func (k *kubeComponent) ServicePorts() (ports []corev1.ServicePort) {
+	for _, port := range k.Ports {
+		ports = append(ports, servicePort(port))
+	}
+	return
+}
+
+//StandaloneClaims the pvc created out of workload
+func (k *kubeComponent) StandaloneClaims() (claims []*kubeVolumeClaim) {
+	for _, claim := range k.Claims {
+		if claim.ClaimName != "" {
+			claims = append(claims, claim)
+		}
+	}
+	return
+}
+
+func newKubeApp(ram v1alpha1.RainbondApplicationConfig) *kubeApp {
+	app := &kubeApp{
+		Name:       kubeName(ram.AppName, 40),
+		Version:    chartVersion(ram.AppVersion),
+		AppVersion: ram.AppVersion,
+	}
+	if app.Name == "" {
+		app.Name = "rainbond-app"
+	}
+	names := make(map[string]struct{})
+	byKey := make(map[string]*v1alpha1.Component)
+	componentNames := make(map[string]string)
+	for _, com := range ram.Components {
+		name := kubeName(com.ServiceCname, 40)
+		if name == "" {
+			name = kubeName(com.ServiceAlias, 40)
+		}
+		if name == "" || name[0] < 'a' {
+			name = "c-" + name
+		}
+		name = uniqueName(names, strings.TrimSuffix(name, "-"))
+		componentNames[com.ComponentKey] = name
+		byKey[com.ComponentKey] = com
+		if com.ServiceShareID != "" {
+			componentNames[com.ServiceShareID] = name
+			byKey[com.ServiceShareID] = com
+		}
+	}
+	// volumes mounted by other components must be standalone pvc
+	sharedVolumes := make(map[string]struct{})
+	for _, com := range ram.Components {
+		for _, mnt := range com.MntReleationList {
+			sharedVolumes[componentNames[mnt.ShareServiceUUID]+"/"+mnt.VolumeName] = struct{}{}
+		}
+	}
+	claimNames := make(map[string]string)
+	for _, com := range ram.Components {
+		kc := newKubeComponent(com, componentNames[com.ComponentKey], byKey, componentNames)
+		for _, vol := range com.ServiceVolumeMapList {
+			claim := kc.addVolume(vol, sharedVolumes)
+			if claim != nil && claim.ClaimName != "" {
+				claimNames[kc.Name+"/"+vol.VolumeName] = claim.ClaimName
+			}
+		}
+		app.Components = append(app.Components, kc)
+	}
+	for i, com := range ram.Components {
+		kc := app.Components[i]
+		for _, mnt := range com.MntReleationList {
+			claimName, ok := claimNames[componentNames[mnt.ShareServiceUUID]+"/"+mnt.VolumeName]
+			if !ok {
+				logrus.Warningf("dependent volume %s/%s not found, ignore it", mnt.ShareServiceUUID, mnt.VolumeName)
+				continue
+			}
+			name := kc.uniqueVolumeName("dep-" + mnt.VolumeName)
+			kc.Volumes = append(kc.Volumes, corev1.Volume{
+				Name: name,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+				},
+			})
+			kc.VolumeMounts = append(kc.VolumeMounts, corev1.VolumeMount{Name: name, MountPath: mnt.VolumeMountDir})
+		}
+	}
+	app.Ingresses = newKubeIngresses(ram, app.Components, componentNames)
+	return app
+}
+
+func newKubeComponent(com *v1alpha1.Component, name string, byKey map[string]*v1alpha1.Component, componentNames map[string]string) *kubeComponent {
+	kc := &kubeComponent{
+		Name:     name,
+		Key:      com.ComponentKey,
+		Kind:     "Deployment",
+		Replicas: int32(com.ExtendMethodRule.MinNode),
+	}
+	switch com.DeployType {
+	case v1alpha1.StateMultipleDeployType, v1alpha1.StateSingletonDeployType:
+		kc.Kind = "StatefulSet"
+	}
+	if kc.Replicas <= 0 || com.DeployType == v1alpha1.StateSingletonDeployType || com.DeployType == v1alpha1.StatelessSingletionDeployType {
+		kc.Replicas = 1
+	}
+	image := com.ShareImage
+	if image == "" {
+		image = com.Image
+	}
+	kc.Repository, kc.Tag = splitImage(image)
+	kc.Env = kubeComponentEnvs(com, byKey, componentNames)
+	configs := make(map[string]string, len(kc.Env))
+	for _, env := range kc.Env {
+		configs[env.Name] = env.Value
+	}
+	if com.Cmd != "" {
+		kc.Args = strings.Fields(util.ParseVariable(com.Cmd, configs))
+	}
+	memory := com.Memory
+	if memory <= 0 {
+		memory = com.ExtendMethodRule.InitMemory
+	}
+	if memory > 0 {
+		quantity := *resource.NewQuantity(int64(memory)*1024*1024, resource.BinarySI)
+		kc.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: quantity}
+		kc.Resources.Requests = corev1.ResourceList{corev1.ResourceMemory: quantity}
+	}
+	if com.CPU > 0 {
+		if kc.Resources.Limits == nil {
+			kc.Resources.Limits = corev1.ResourceList{}
+		}
+		kc.Resources.Limits[corev1.ResourceCPU] = *resource.NewMilliQuantity(int64(com.CPU), resource.DecimalSI)
+	}
+	portNames := make(map[string]struct{})
+	for _, port := range com.Ports {
+		protocol := corev1.ProtocolTCP
+		if strings.ToLower(port.Protocol) == "udp" {
+			protocol = corev1.ProtocolUDP
+		}
+		portName := kubeName(port.Protocol, 9)
+		if portName == "" || portName[0] < 'a' {
+			portName = strings.ToLower(string(protocol))
+		}
+		kc.Ports = append(kc.Ports, corev1.ContainerPort{
+			Name:          uniqueName(portNames, fmt.Sprintf("%s-%d", portName, port.ContainerPort)),
+			ContainerPort: int32(port.ContainerPort),
+			Protocol:      protocol,
+		})
+	}
+	for _, probe := range com.Probes {
+		if !probe.IsUsed {
+			continue
+		}
+		switch probe.Mode {
+		case "liveness":
+			kc.LivenessProbe = kubeProbe(probe)
+		case "readiness":
+			kc.ReadinessProbe = kubeProbe(probe)
+		}
+	}
+	return kc
+}
+
+//kubeComponentEnvs the envs of component, connection envs of dependent components are included
+func kubeComponentEnvs(com *v1alpha1.Component, byKey map[string]*v1alpha1.Component, componentNames map[string]string) []corev1.EnvVar {
+	var envs []corev1.EnvVar
+	index := make(map[string]int)
+	setEnv := func(name, value string) {
+		if i, ok := index[name]; ok {
+			envs[i].Value = value
+			return
+		}
+		index[name] = len(envs)
+		envs = append(envs, corev1.EnvVar{Name: name, Value: value})
+	}
+	for _, dep := range com.DepServiceMapList {
+		depCom, ok := byKey[dep.DepServiceKey]
+		if !ok {
+			continue
+		}
+		for _, env := range depCom.ServiceConnectInfoMapList {
+			value := env.AttrValue
+			// there is no service mesh out of rainbond, access the dependency by its service
+			if strings.HasSuffix(env.AttrName, "_HOST") && (value == "127.0.0.1" || value == "localhost") {
+				value = componentNames[dep.DepServiceKey]
+			}
+			setEnv(env.AttrName, value)
+		}
+	}
+	for _, env := range com.ServiceConnectInfoMapList {
+		setEnv(env.AttrName, env.AttrValue)
+	}
+	for _, env := range com.Envs {
+		setEnv(env.AttrName, env.AttrValue)
+	}
+	configs := make(map[string]string, len(envs))
+	for i := range envs {
+		if envs[i].Value == "**None**" {
+			envs[i].Value = util.NewUUID()[:8]
+		}
+		configs[envs[i].Name] = envs[i].Value
+	}
+	for i := range envs {
+		envs[i].Value = util.ParseVariable(envs[i].Value, configs)
+	}
+	return envs
+}
+
+func kubeProbe(probe v1alpha1.ComponentProbe) *corev1.Probe {
+	p := &corev1.Probe{
+		FailureThreshold:    int32(probe.FailureThreshold),
+		SuccessThreshold:    int32(probe.SuccessThreshold),
+		InitialDelaySeconds: int32(probe.InitialDelaySecond),
+		TimeoutSeconds:      int32(probe.TimeoutSecond),
+		PeriodSeconds:       int32(probe.PeriodSecond),
+	}
+	if probe.Mode == "liveness" {
+		p.SuccessThreshold = 1
+	}
+	if probe.Mode == "readiness" && p.FailureThreshold < 1 {
+		p.FailureThreshold = 3
+	}
+	switch probe.Scheme {
+	case "tcp":
+		p.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt(probe.Port)}
+	case "http":
+		action := &corev1.HTTPGetAction{Path: probe.Path, Port: intstr.FromInt(probe.Port)}
+		for _, hd := range strings.Split(probe.HTTPHeader, ",") {
+			kv := strings.SplitN(hd, "=", 2)
+			if kv[0] == "" {
+				continue
+			}
+			header := corev1.HTTPHeader{Name: kv[0]}
+			if len(kv) == 2 {
+				header.Value = kv[1]
+			}
+			action.HTTPHeaders = append(action.HTTPHeaders, header)
+		}
+		p.HTTPGet = action
+	case "cmd":
+		p.Exec = &corev1.ExecAction{Command: []string{"/bin/sh", "-c", probe.Cmd}}
+	default:
+		return nil
+	}
+	return p
+}
+
+func (k *kubeComponent) uniqueVolumeName(name string) string {
+	set := make(map[string]struct{}, len(k.Volumes)+len(k.Claims))
+	for _, vol := range k.Volumes {
+		set[vol.Name] = struct{}{}
+	}
+	for _, claim := range k.Claims {
+		set[claim.Name] = struct{}{}
+	}
+	name = kubeName(name, 20)
+	if name == "" {
+		name = "vol"
+	}
+	return uniqueName(set, name)
+}
+
+//addVolume add the volume of component, the persistent volume claim is returned
+func (k *kubeComponent) addVolume(vol v1alpha1.ComponentVolume, sharedVolumes map[string]struct{}) *kubeVolumeClaim {
+	name := k.uniqueVolumeName(vol.VolumeName)
+	switch vol.VolumeType {
+	case v1alpha1.ConfigFileVolumeType:
+		if k.ConfigFiles == nil {
+			k.ConfigFiles = make(map[string]string)
+		}
+		k.ConfigFiles[name] = vol.FileConent
+		k.VolumeMounts = append(k.VolumeMounts, corev1.VolumeMount{Name: "config", MountPath: vol.VolumeMountPath, SubPath: name})
+		if len(k.ConfigFiles) == 1 {
+			k.Volumes = append(k.Volumes, corev1.Volume{
+				Name: "config",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: k.ConfigMapName()},
+					},
+				},
+			})
+		}
+		return nil
+	case v1alpha1.MemoryFSVolumeType:
+		k.Volumes = append(k.Volumes, corev1.Volume{
+			Name:         name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+		})
+		k.VolumeMounts = append(k.VolumeMounts, corev1.VolumeMount{Name: name, MountPath: vol.VolumeMountPath})
+		return nil
+	}
+	claim := &kubeVolumeClaim{
+		Name:       name,
+		AccessMode: corev1.ReadWriteOnce,
+		Size:       fmt.Sprintf("%dGi", defaultKubeVolumeSize),
+	}
+	if vol.VolumeCapacity > 0 {
+		claim.Size = fmt.Sprintf("%dGi", vol.VolumeCapacity)
+	}
+	switch vol.AccessMode {
+	case v1alpha1.RWXAccessMode:
+		claim.AccessMode = corev1.ReadWriteMany
+	case v1alpha1.ROXAccessMode:
+		claim.AccessMode = corev1.ReadOnlyMany
+	case "":
+		if vol.VolumeType == v1alpha1.ShareFileVolumeType {
+			claim.AccessMode = corev1.ReadWriteMany
+		}
+	}
+	_, shared := sharedVolumes[k.Name+"/"+vol.VolumeName]
+	if !k.IsStateful() || shared {
+		claim.ClaimName = k.Name + "-" + name
+		k.Volumes = append(k.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim.ClaimName},
+			},
+		})
+	}
+	k.Claims = append(k.Claims, claim)
+	k.VolumeMounts = append(k.VolumeMounts, corev1.VolumeMount{Name: name, MountPath: vol.VolumeMountPath})
+	return claim
+}
+
+//newKubeIngresses create ingresses by http rules and stream ports by stream rules of gateway
+func newKubeIngresses(ram v1alpha1.RainbondApplicationConfig, components []*kubeComponent, componentNames map[string]string) []*kubeIngress {
+	byName := make(map[string]*kubeComponent, len(components))
+	for _, kc := range components {
+		byName[kc.Name] = kc
+	}
+	findPort := func(target v1alpha1.TargetComponent) (*kubeComponent, *corev1.ContainerPort) {
+		kc := byName[componentNames[target.ComponentKey]]
+		if kc == nil {
+			return nil, nil
+		}
+		for i := range kc.Ports {
+			if kc.Ports[i].ContainerPort == int32(target.Port) {
+				return kc, &kc.Ports[i]
+			}
+		}
+		return kc, nil
+	}
+	var ingresses []*kubeIngress
+	names := make(map[string]struct{})
+	for _, route := range ram.IngressHTTPRoutes {
+		kc, port := findPort(route.TargetComponent)
+		if port == nil {
+			logrus.Warningf("the target port %s/%d of http rule not found, ignore it", route.ComponentKey, route.Port)
+			continue
+		}
+		ing := &kubeIngress{
+			Name:        uniqueName(names, fmt.Sprintf("%s-%d", kc.Name, port.ContainerPort)),
+			Component:   kc.Name,
+			Port:        port.ContainerPort,
+			Path:        route.Location,
+			TLS:         route.SSL,
+			Annotations: make(map[string]string),
+		}
+		if ing.Path == "" {
+			ing.Path = "/"
+		}
+		setTimeout := func(key string, value int) {
+			if value > 0 {
+				ing.Annotations["nginx.ingress.kubernetes.io/"+key] = strconv.Itoa(value)
+			}
+		}
+		setTimeout("proxy-connect-timeout", route.ConnectionTimeout)
+		setTimeout("proxy-send-timeout", route.RequestTimeout)
+		setTimeout("proxy-read-timeout", route.ResponseTimeout)
+		if route.RequestBodySizeLimit > 0 {
+			ing.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"] = fmt.Sprintf("%dm", route.RequestBodySizeLimit)
+		}
+		if route.LoadBalancing == "cookie-session-affinity" {
+			ing.Annotations["nginx.ingress.kubernetes.io/affinity"] = "cookie"
+		}
+		ingresses = append(ingresses, ing)
+	}
+	for _, route := range ram.IngressSreamRoutes {
+		kc, port := findPort(route.TargetComponent)
+		if port == nil {
+			logrus.Warningf("the target port %s/%d of stream rule not found, ignore it", route.ComponentKey, route.Port)
+			continue
+		}
+		var exist bool
+		for _, sp := range kc.StreamPorts {
+			exist = exist || sp.Port == port.ContainerPort
+		}
+		if !exist {
+			kc.StreamPorts = append(kc.StreamPorts, servicePort(*port))
+		}
+	}
+	return ingresses
+}
+
+func servicePort(port corev1.ContainerPort) corev1.ServicePort {
+	return corev1.ServicePort{
+		Name:       port.Name,
+		Port:       port.ContainerPort,
+		TargetPort: intstr.FromInt(int(port.ContainerPort)),
+		Protocol:   port.Protocol,
+	}
+}
+
+//splitImage split the image to repository and tag
+func splitImage(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		return image, ""
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, "latest"
+	}
+	return image[:i], image[i+1:]
+}
+
+var semverReg = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+//chartVersion convert the app version to semver which is required by helm chart
+func chartVersion(version string) string {
+	match := semverReg.FindStringSubmatch(version)
+	if match == nil {
+		return "0.1.0"
+	}
+	for i := 2; i < len(match); i++ {
+		if match[i] == "" {
+			match[i] = "0"
+		}
+	}
+	return strings.Join(match[1:], ".")
+}
+
+func (k *kubeApp) labels(component string) map[string]string {
+	return map[string]string{kubeNameLabel: component, kubeInstanceLabel: k.Name}
+}
+
+//Objects return the kubernetes resources of application
+func (k *kubeApp) Objects() []interface{} {
+	var objects []interface{}
+	for _, kc := range k.Components {
+		labels := k.labels(kc.Name)
+		if len(kc.ConfigFiles) > 0 {
+			objects = append(objects, &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: kc.ConfigMapName(), Labels: labels},
+				Data:       kc.ConfigFiles,
+			})
+		}
+		for _, claim := range kc.StandaloneClaims() {
+			pvc := k.persistentVolumeClaim(claim, labels)
+			pvc.Name = claim.ClaimName
+			objects = append(objects, pvc)
+		}
+		if len(kc.Ports) > 0 {
+			objects = append(objects, k.service(kc))
+		}
+		if len(kc.StreamPorts) > 0 {
+			objects = append(objects, &corev1.Service{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+				ObjectMeta: metav1.ObjectMeta{Name: kc.Name + "-outer", Labels: labels},
+				Spec: corev1.ServiceSpec{
+					Type:     corev1.ServiceTypeNodePort,
+					Selector: labels,
+					Ports:    kc.StreamPorts,
+				},
+			})
+		}
+		objects = append(objects, k.workload(kc))
+	}
+	pathType := networkingv1.PathTypePrefix
+	for _, ing := range k.Ingresses {
+		ingress := &networkingv1.Ingress{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+			ObjectMeta: metav1.ObjectMeta{Name: ing.Name, Labels: k.labels(ing.Component)},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     ing.Path,
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: ing.Component,
+								Port: networkingv1.ServiceBackendPort{Number: ing.Port},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+		if len(ing.Annotations) > 0 {
+			ingress.Annotations = ing.Annotations
+		}
+		if ing.TLS {
+			ingress.Spec.TLS = []networkingv1.IngressTLS{{SecretName: ing.Name + "-tls"}}
+		}
+		objects = append(objects, ingress)
+	}
+	return objects
+}
+
+func (k *kubeApp) persistentVolumeClaim(claim *kubeVolumeClaim, labels map[string]string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{Name: claim.Name, Labels: labels},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{claim.AccessMode},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(claim.Size)},
+			},
+		},
+	}
+}
+
+func (k *kubeApp) service(kc *kubeComponent) *corev1.Service {
+	labels := k.labels(kc.Name)
+	svc := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: kc.Name, Labels: labels},
+		Spec:       corev1.ServiceSpec{Selector: labels},
+	}
+	if kc.IsStateful() {
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+	svc.Spec.Ports = kc.ServicePorts()
+	return svc
+}
+
+func (k *kubeApp) workload(kc *kubeComponent) interface{} {
+	labels := k.labels(kc.Name)
+	replicas := kc.Replicas
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:            kc.Name,
+				Image:           kc.Image(),
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Args:            kc.Args,
+				Env:             kc.Env,
+				Resources:       kc.Resources,
+				Ports:           kc.Ports,
+				LivenessProbe:   kc.LivenessProbe,
+				ReadinessProbe:  kc.ReadinessProbe,
+				VolumeMounts:    kc.VolumeMounts,
+			}},
+			Volumes: kc.Volumes,
+		},
+	}
+	meta := metav1.ObjectMeta{Name: kc.Name, Labels: labels}
+	selector := &metav1.LabelSelector{MatchLabels: labels}
+	if !kc.IsStateful() {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: meta,
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Selector: selector, Template: template},
+		}
+	}
+	sts := &appsv1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: meta,
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: kc.Name,
+			Selector:    selector,
+			Template:    template,
+		},
+	}
+	for _, claim := range kc.ClaimTemplates() {
+		pvc := k.persistentVolumeClaim(claim, nil)
+		pvc.TypeMeta = metav1.TypeMeta{}
+		sts.Spec.VolumeClaimTemplates = append(sts.Spec.VolumeClaimTemplates, *pvc)
+	}
+	return sts
+}
+
+//kubeYAML marshal the kubernetes resource to yaml,
+//the null fields such as creationTimestamp and the empty fields such as status are removed.
+func kubeYAML(obj interface{}) ([]byte, error) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	if m, ok := value.(map[string]interface{}); ok && m["kind"] != nil {
+		delete(m, "status")
+	}
+	return yaml.Marshal(pruneEmpty(value))
+}
+
+//pruneEmpty remove the null values and empty objects,
+//emptyDir is kept because an empty emptyDir volume is meaningful.
+func pruneEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			item = pruneEmpty(item)
+			if m, ok := item.(map[string]interface{}); item == nil || (ok && len(m) == 0 && key != "emptyDir") {
+				delete(v, key)
+				continue
+			}
+			v[key] = item
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = pruneEmpty(v[i])
+		}
+	}
+	return value
+}
+
+//ManifestsYAML return the multi-document yaml of application
+func (k *kubeApp) ManifestsYAML() ([]byte, error) {
+	var buffer bytes.Buffer
+	for _, obj := range k.Objects() {
+		body, err := kubeYAML(obj)
+		if err != nil {
+			return nil, err
+		}
+		buffer.WriteString("---\n")
+		buffer.Write(body)
+	}
+	return buffer.Bytes(), nil
+}
+
+type kubernetesExporter struct {
+	ram      v1alpha1.RainbondApplicationConfig
+	homePath string
+}
+
+//Export export the application to the plain kubernetes manifests
+func (k *kubernetesExporter) Export() (*export.Result, error) {
+	logrus.Infof("start export app %s to kubernetes manifests", k.ram.AppName)
+	content, err := newKubeApp(k.ram).ManifestsYAML()
+	if err != nil {
+		return nil, fmt.Errorf("build kubernetes manifests failure %s", err.Error())
+	}
+	if err := os.MkdirAll(k.homePath, 0755); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%s-%s-kubernetes.yaml", k.ram.AppName, k.ram.AppVersion)
+	if err := ioutil.WriteFile(path.Join(k.homePath, name), content, 0644); err != nil {
+		return nil, fmt.Errorf("write kubernetes manifests failure %s", err.Error())
+	}
+	logrus.Infof("success export app %s to kubernetes manifests", k.ram.AppName)
+	return &export.Result{PackagePath: path.Join(k.homePath, name), PackageName: name}, nil
+}
@@ -19,12 +19,14 @@
 package exector
 
 import (
+	"context"
 	"fmt"
 	"github.com/goodrain/rainbond/builder"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -41,6 +43,7 @@ import (
 	"github.com/pquerna/ffjson/ffjson"
 	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -66,15 +69,30 @@ type BackupAPPNew struct {
 	Logger       event.Logger
 	DockerClient *client.Client
 
-	//full-online,full-offline
-	Mode     string `json:"mode"`
-	S3Config struct {
+	//full-online,full-offline,incremental-online
+	Mode string `json:"mode"`
+	//ScheduleID the backup schedule, the expired backups of schedule will be pruned after backup success
+	ScheduleID string `json:"schedule_id"`
+	KeepDaily  int    `json:"keep_daily"`
+	KeepWeekly int    `json:"keep_weekly"`
+	//VolumeSnapshot backup the volumes by CSI volume snapshot if enabled and the storage class supports
+	VolumeSnapshot VolumeSnapshotConfig `json:"volume_snapshot"`
+	S3Config       struct {
 		Provider   string `json:"provider"`
 		Endpoint   string `json:"endpoint"`
 		AccessKey  string `json:"access_key"`
 		SecretKey  string `json:"secret_key"`
 		BucketName string `json:"bucket_name"`
 	} `json:"s3_config"`
+	//Encryption encrypt the backup data before uploading to object storage
+	Encryption cloudos.EncryptionConfig `json:"encryption"`
+
+	kubeClient    kubernetes.Interface
+	rbdNamespace  string
+	encryptionKey []byte
+	chunks        *chunkStore
+	manifest      *backupManifest
+	snapshotter   *volumeSnapshotter
 }
 
 func init() {
@@ -89,10 +107,20 @@ func BackupAPPNewCreater(in []byte, m *exectorManager) (TaskWorker, error) {
 		Logger:       logger,
 		EventID:      eventID,
 		DockerClient: m.DockerClient,
+		kubeClient:   m.KubeClient,
+		rbdNamespace: m.cfg.RbdNamespace,
 	}
 	if err := ffjson.Unmarshal(in, &backupNew); err != nil {
 		return nil, err
 	}
+	//the snapshotter of scheduled backups deletes the snapshots of expired backups, even though the snapshot is not enabled
+	if backupNew.VolumeSnapshot.Enable || backupNew.ScheduleID != "" {
+		snapshotter, err := newVolumeSnapshotter(m.KubeClient, m.DynamicClient, m.RestConfig, logger)
+		if err != nil && backupNew.VolumeSnapshot.Enable {
+			logrus.Infof("volume snapshot is not available, the volumes will be backup by copying files: %v", err)
+		}
+		backupNew.snapshotter = snapshotter
+	}
 	return backupNew, nil
 }
 
@@ -132,6 +160,18 @@ func (b *BackupAPPNew) Run(timeout time.Duration) error {
 	if err != nil {
 		return err
 	}
+	if b.Mode == "incremental-online" {
+		cloudoser, err := b.newCloudOSer()
+		if err != nil {
+			return err
+		}
+		b.chunks, err = newChunkStore(cloudoser, chunkPrefix(b.GroupID, b.Encryption.Enabled()), fmt.Sprintf("/grdata/cache/tmp/backup_chunks/%s", b.BackupID))
+		if err != nil {
+			return fmt.Errorf("create backup chunk store: %v", err)
+		}
+		defer os.RemoveAll(b.chunks.tmpDir)
+		b.manifest = &backupManifest{BackupID: b.BackupID, GroupID: b.GroupID, CreateTime: time.Now()}
+	}
 
 	metaVersion, err := judgeMetadataVersion(metadata)
 	if err != nil {
@@ -167,6 +207,9 @@ func (b *BackupAPPNew) Run(timeout time.Duration) error {
 	if strings.HasSuffix(b.SourceDir, "/") {
 		b.SourceDir = b.SourceDir[:len(b.SourceDir)-2]
 	}
+	if b.Mode == "incremental-online" {
+		return b.finishIncremental()
+	}
 	if err := util.Zip(b.SourceDir, fmt.Sprintf("%s.zip", b.SourceDir)); err != nil {
 		b.Logger.Info(fmt.Sprintf("Compressed backup metadata failed"), map[string]string{"step": "backup_builder", "status": "starting"})
 		return err
@@ -184,6 +227,9 @@ func (b *BackupAPPNew) Run(timeout time.Duration) error {
 	if err := b.updateBackupStatu("success"); err != nil {
 		return err
 	}
+	if err := b.pruneExpiredBackups(); err != nil {
+		logrus.Errorf("prune expired backups of schedule %s failure: %v", b.ScheduleID, err)
+	}
 	return nil
 }
 
@@ -198,10 +244,22 @@ func (b *BackupAPPNew) uploadPkg() error {
 		}
 	}()
 
-	s3Provider, err := cloudos.Str2S3Provider(b.S3Config.Provider)
+	cloudoser, err := b.newCloudOSer()
 	if err != nil {
 		return err
 	}
+	_, filename := filepath.Split(b.SourceDir)
+	if err := cloudoser.PutObject(filename, b.SourceDir); err != nil {
+		return fmt.Errorf("object key: %s; filepath: %s; error putting object: %v", filename, b.SourceDir, err)
+	}
+	return nil
+}
+
+func (b *BackupAPPNew) newCloudOSer() (cloudos.CloudOSer, error) {
+	s3Provider, err := cloudos.Str2S3Provider(b.S3Config.Provider)
+	if err != nil {
+		return nil, err
+	}
 	cfg := &cloudos.Config{
 		ProviderType: s3Provider,
 		Endpoint:     b.S3Config.Endpoint,
@@ -209,17 +267,192 @@ func (b *BackupAPPNew) uploadPkg() error {
 		SecretKey:    b.S3Config.SecretKey,
 		BucketName:   b.S3Config.BucketName,
 	}
+	if b.Encryption.Enabled() && b.encryptionKey == nil {
+		b.encryptionKey, err = cloudos.LoadEncryptionKey(context.Background(), b.kubeClient, b.rbdNamespace, &b.Encryption)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cfg.EncryptionKey = b.encryptionKey
 	cloudoser, err := cloudos.New(cfg)
 	if err != nil {
-		return fmt.Errorf("error creating cloudoser: %v", err)
+		return nil, fmt.Errorf("error creating cloudoser: %v", err)
 	}
-	_, filename := filepath.Split(b.SourceDir)
-	if err := cloudoser.PutObject(filename, b.SourceDir); err != nil {
-		return fmt.Errorf("object key: %s; filepath: %s; error putting object: %v", filename, b.SourceDir, err)
+	return cloudoser, nil
+}
+
+//finishIncremental store the metadata and the runtime packages as chunks and upload the manifest,
+//the SourceDir of incremental backup is the object key of manifest.
+func (b *BackupAPPNew) finishIncremental() error {
+	if err := b.chunks.addDir(b.manifest, b.SourceDir, ""); err != nil {
+		b.Logger.Error("upload backup data failure", map[string]string{"step": "backup_builder", "status": "failure"})
+		return fmt.Errorf("error upload backup chunks: %v", err)
+	}
+	key, err := b.chunks.saveManifest(b.manifest)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(b.SourceDir); err != nil {
+		logrus.Warningf("error removing temporary direcotry: %v", err)
+	}
+	b.SourceDir = key
+	b.SourceType = "s3"
+	b.BackupSize = b.chunks.uploaded
+	b.Logger.Info(fmt.Sprintf("Incremental backup uploaded %d bytes", b.BackupSize), map[string]string{"step": "backup_builder", "status": "success"})
+	if err := b.updateBackupStatu("success"); err != nil {
+		return err
+	}
+	if err := b.pruneExpiredBackups(); err != nil {
+		//the backup is success, the expired backups will be pruned next time
+		logrus.Errorf("prune expired backups of schedule %s failure: %v", b.ScheduleID, err)
 	}
 	return nil
 }
 
+//pruneExpiredBackups delete the backups expired by the retention of schedule,
+//and the chunks no longer referenced by any incremental backup of the group app.
+func (b *BackupAPPNew) pruneExpiredBackups() error {
+	if b.ScheduleID == "" || (b.KeepDaily <= 0 && b.KeepWeekly <= 0) {
+		return nil
+	}
+	scheduled, err := db.GetManager().AppBackupDao().GetAppBackupsBySchedule(b.ScheduleID)
+	if err != nil {
+		return err
+	}
+	var expired []*dbmodel.AppBackup
+	for _, backup := range expiredBackups(scheduled, b.KeepDaily, b.KeepWeekly) {
+		//the chunks of incremental backups are stored separately if encrypted,
+		//the backups of the other kind will be pruned by the next backup of the same kind.
+		if backup.BackupMode == "incremental-online" && backup.Status == "success" && backup.Encrypted != b.Encryption.Enabled() {
+			continue
+		}
+		expired = append(expired, backup)
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	var expiredIDs []string
+	isExpired := make(map[string]bool)
+	for _, backup := range expired {
+		isExpired[backup.BackupID] = true
+		if backup.Status != "success" {
+			continue
+		}
+		switch backup.BackupMode {
+		case "incremental-online":
+			expiredIDs = append(expiredIDs, backup.BackupID)
+		case "full-online":
+			cloudoser, err := b.newCloudOSer()
+			if err != nil {
+				return err
+			}
+			_, filename := filepath.Split(backup.SourceDir)
+			if err := cloudoser.DeleteObject(filename); err != nil {
+				return fmt.Errorf("delete backup package %s: %v", filename, err)
+			}
+		default:
+			os.RemoveAll(backup.SourceDir)
+		}
+	}
+	if b.chunks == nil && len(expiredIDs) > 0 {
+		cloudoser, err := b.newCloudOSer()
+		if err != nil {
+			return err
+		}
+		if b.chunks, err = newChunkStore(cloudoser, chunkPrefix(b.GroupID, b.Encryption.Enabled()), fmt.Sprintf("/grdata/cache/tmp/backup_chunks/%s", b.BackupID)); err != nil {
+			return err
+		}
+		defer os.RemoveAll(b.chunks.tmpDir)
+	}
+	//the chunks referenced by any live incremental backup of the group app must be kept
+	backups, err := db.GetManager().AppBackupDao().GetAppBackups(b.GroupID)
+	if err != nil {
+		return err
+	}
+	var live []*backupManifest
+	for _, backup := range backups {
+		if b.chunks == nil {
+			break
+		}
+		if isExpired[backup.BackupID] || backup.BackupMode != "incremental-online" || backup.Encrypted != b.Encryption.Enabled() {
+			continue
+		}
+		if backup.Status != "success" {
+			// the chunks of a running backup may be not in any manifest
+			if backup.Status == "starting" && backup.BackupID != b.BackupID {
+				return fmt.Errorf("backup %s is running", backup.BackupID)
+			}
+			continue
+		}
+		if backup.BackupID == b.BackupID && b.manifest != nil {
+			live = append(live, b.manifest)
+			continue
+		}
+		m, err := b.chunks.getManifest(backup.BackupID)
+		if err != nil {
+			return err
+		}
+		live = append(live, m)
+	}
+	if b.chunks != nil {
+		if err := b.chunks.prune(expiredIDs, live); err != nil {
+			return err
+		}
+	}
+	for _, backup := range expired {
+		if b.snapshotter != nil {
+			if err := b.snapshotter.deleteSnapshots(backup.BackupID); err != nil {
+				logrus.Warningf("delete volume snapshots of expired backup %s failure: %v", backup.BackupID, err)
+				continue
+			}
+		}
+		if err := db.GetManager().AppBackupDao().DeleteAppBackup(backup.BackupID); err != nil {
+			logrus.Warningf("delete expired backup %s failure: %v", backup.BackupID, err)
+		}
+	}
+	b.Logger.Info(fmt.Sprintf("Pruned %d expired backups", len(expired)), map[string]string{"step": "backup_builder", "status": "success"})
+	return nil
+}
+
+//expiredBackups select the backups not kept by the retention,
+//the newest backup of each day is kept for keepDaily days and the newest backup of each week is kept for keepWeekly weeks.
+//the backups are not finished will be kept.
+func expiredBackups(backups []*dbmodel.AppBackup, keepDaily, keepWeekly int) []*dbmodel.AppBackup {
+	sorted := make([]*dbmodel.AppBackup, len(backups))
+	copy(sorted, backups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	var expired []*dbmodel.AppBackup
+	for _, backup := range sorted {
+		if backup.Status == "starting" || backup.Status == "restore" {
+			continue
+		}
+		if backup.Status != "success" {
+			expired = append(expired, backup)
+			continue
+		}
+		keep := false
+		day := backup.CreatedAt.Format("2006-01-02")
+		if !days[day] && len(days) < keepDaily {
+			days[day] = true
+			keep = true
+		}
+		year, week := backup.CreatedAt.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%d", year, week)
+		if !weeks[weekKey] && len(weeks) < keepWeekly {
+			weeks[weekKey] = true
+			keep = true
+		}
+		if !keep {
+			expired = append(expired, backup)
+		}
+	}
+	return expired
+}
+
 // judging whether the metadata structure is old or new, the new version is v5.1.8 and later
 func judgeMetadataVersion(metadata []byte) (string, error) {
 	var appSnapshot AppSnapshot
@@ -277,6 +510,18 @@ func (b *BackupAPPNew) backupServiceInfo(serviceInfos []*RegionServiceSnapshot)
 		}
 
 		b.Logger.Info(fmt.Sprintf("Start backup application(%s) persistent data", app.Service.ServiceAlias), map[string]string{"step": "backup_builder", "status": "starting"})
+		snapshotted, err := b.snapshotVolumes(app)
+		if err != nil {
+			b.Logger.Error(fmt.Sprintf("Snapshot application(%s) volumes failure", app.Service.ServiceAlias), map[string]string{"step": "backup_builder", "status": "failure"})
+			return err
+		}
+		if b.Mode == "incremental-online" {
+			if err := b.backupServiceDataIncremental(app, snapshotted); err != nil {
+				return err
+			}
+			b.Logger.Info(fmt.Sprintf("Complete backup application(%s) persistent data", app.Service.ServiceAlias), map[string]string{"step": "backup_builder", "status": "success"})
+			continue
+		}
 		//backup app data,The overall data of the direct backup service
 		if len(app.ServiceVolume) > 0 {
 			dstDir := fmt.Sprintf("%s/data_%s/%s.zip", b.SourceDir, app.Service.ServiceID, "__all_data")
@@ -290,6 +535,9 @@ func (b *BackupAPPNew) backupServiceInfo(serviceInfos []*RegionServiceSnapshot)
 			}
 		}
 		for _, volume := range app.ServiceVolume {
+			if snapshotted[volume.ID] {
+				continue
+			}
 			dstDir := fmt.Sprintf("%s/data_%s/%s.zip", b.SourceDir, app.ServiceID, strings.Replace(volume.VolumeName, "/", "", -1))
 			hostPath := volume.HostPath
 			if hostPath != "" && !util.DirIsEmpty(hostPath) {
@@ -304,6 +552,67 @@ func (b *BackupAPPNew) backupServiceInfo(serviceInfos []*RegionServiceSnapshot)
 	return nil
 }
 
+//snapshotVolumes snapshot the volumes whose storage class supports CSI volume snapshot,
+//the snapshots are recorded in the backup package and the snapshotted volumes are not copied.
+func (b *BackupAPPNew) snapshotVolumes(app *RegionServiceSnapshot) (map[uint]bool, error) {
+	if !b.VolumeSnapshot.Enable || b.snapshotter == nil || len(app.ServiceVolume) == 0 {
+		return nil, nil
+	}
+	records, err := b.snapshotter.backupService(app, b.BackupID, b.VolumeSnapshot.Hooks[app.ServiceID])
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	dataDir := fmt.Sprintf("%s/data_%s", b.SourceDir, app.ServiceID)
+	if err := util.CheckAndCreateDir(dataDir); err != nil {
+		return nil, err
+	}
+	if err := saveVolumeSnapshots(dataDir, records); err != nil {
+		return nil, err
+	}
+	snapshotted := make(map[uint]bool)
+	for _, record := range records {
+		snapshotted[record.VolumeID] = true
+	}
+	b.Logger.Info(fmt.Sprintf("Snapshot %d volume claims of application(%s)", len(records), app.Service.ServiceAlias), map[string]string{"step": "backup_builder", "status": "running"})
+	return snapshotted, nil
+}
+
+//backupServiceDataIncremental store the volume data as chunks, the layout of files in manifest is same as the zip package
+func (b *BackupAPPNew) backupServiceDataIncremental(app *RegionServiceSnapshot, snapshotted map[uint]bool) error {
+	if len(app.ServiceVolume) == 0 {
+		return nil
+	}
+	_, sharepath := GetVolumeDir()
+	serviceVolumeData := path.Join(sharepath, "tenant", app.Service.TenantID, "service", app.Service.ServiceID)
+	allData := !util.DirIsEmpty(serviceVolumeData)
+	if allData {
+		prefix := fmt.Sprintf("data_%s/__all_data/%s", app.Service.ServiceID, filepath.Base(serviceVolumeData))
+		if err := b.chunks.addDir(b.manifest, serviceVolumeData, prefix); err != nil {
+			logrus.Errorf("backup service(%s) volume data error.%s", app.ServiceID, err.Error())
+			return err
+		}
+	}
+	for _, volume := range app.ServiceVolume {
+		hostPath := volume.HostPath
+		if hostPath == "" || snapshotted[volume.ID] || util.DirIsEmpty(hostPath) {
+			continue
+		}
+		//the volume data has been stored with all data
+		if allData && strings.HasPrefix(hostPath, serviceVolumeData+"/") {
+			continue
+		}
+		prefix := fmt.Sprintf("data_%s/%s/%s", app.ServiceID, strings.Replace(volume.VolumeName, "/", "", -1), filepath.Base(hostPath))
+		if err := b.chunks.addDir(b.manifest, hostPath, prefix); err != nil {
+			logrus.Errorf("backup service(%s) volume(%s) data error.%s", app.ServiceID, volume.VolumeName, err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *BackupAPPNew) backupPluginInfo(appSnapshot *AppSnapshot) error {
 	b.Logger.Info(fmt.Sprintf("Start backup plugin"), map[string]string{"step": "backup_builder", "status": "starting"})
 	for _, pv := range appSnapshot.PluginBuildVersions {
@@ -401,6 +710,11 @@ func (b *BackupAPPNew) ErrorCallBack(err error) {
 	if err != nil {
 		logrus.Errorf("backup group app failure %s", err)
 		b.Logger.Error(util.Translation("backup group app failure"), map[string]string{"step": "callback", "status": "failure"})
+		if b.snapshotter != nil {
+			if err := b.snapshotter.deleteSnapshots(b.BackupID); err != nil {
+				logrus.Warningf("delete volume snapshots of failed backup %s: %v", b.BackupID, err)
+			}
+		}
 		b.updateBackupStatu("failed")
 	}
 }
@@ -43,6 +43,9 @@ import (
 	"github.com/pquerna/ffjson/ffjson"
 	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 //BackupAPPRestore restrore the  group app backup
@@ -63,6 +66,9 @@ type BackupAPPRestore struct {
 	serviceChange map[string]*Info
 	volumeIDMap   map[uint]uint
 	etcdcli       *clientv3.Client
+	kubeClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+	restConfig    *rest.Config
 
 	S3Config struct {
 		Provider   string `json:"provider"`
@@ -71,6 +77,10 @@ type BackupAPPRestore struct {
 		SecretKey  string `json:"secret_key"`
 		BucketName string `json:"bucket_name"`
 	} `json:"s3_config"`
+	//Encryption the encryption of backup, the key is required if the backup is encrypted
+	Encryption   cloudos.EncryptionConfig `json:"encryption"`
+	rbdNamespace string
+	encrypted    bool
 }
 
 //Info service cache info
@@ -94,6 +104,10 @@ func BackupAPPRestoreCreater(in []byte, m *exectorManager) (TaskWorker, error) {
 		EventID:       eventID,
 		DockerClient:  m.DockerClient,
 		etcdcli:       m.EtcdCli,
+		kubeClient:    m.KubeClient,
+		dynamicClient: m.DynamicClient,
+		restConfig:    m.RestConfig,
+		rbdNamespace:  m.cfg.RbdNamespace,
 		serviceChange: make(map[string]*Info, 0),
 		volumeIDMap:   make(map[uint]uint),
 	}
@@ -113,6 +127,10 @@ func (b *BackupAPPRestore) Run(timeout time.Duration) error {
 	if backup.Status != "success" || backup.SourceDir == "" || backup.BackupMode == "" {
 		return fmt.Errorf("backup can not be restore")
 	}
+	if backup.Encrypted && !b.Encryption.Enabled() {
+		return fmt.Errorf("backup is encrypted, the encryption secret is required")
+	}
+	b.encrypted = backup.Encrypted
 
 	cacheDir := fmt.Sprintf("/grdata/cache/tmp/%s/%s", b.BackupID, util.NewUUID())
 	if err := util.CheckAndCreateDir(cacheDir); err != nil {
@@ -120,6 +138,10 @@ func (b *BackupAPPRestore) Run(timeout time.Duration) error {
 	}
 	b.cacheDir = cacheDir
 	switch backup.BackupMode {
+	case "incremental-online":
+		if err := b.downloadIncremental(backup); err != nil {
+			return fmt.Errorf("error downloading incremental backup: %v", err)
+		}
 	case "full-online":
 		if err := b.downloadFromS3(backup.SourceDir); err != nil {
 			return fmt.Errorf("error downloading file from s3: %v", err)
@@ -201,12 +223,19 @@ func (b *BackupAPPRestore) restoreVersionAndData(backup *dbmodel.AppBackup, appS
 		b.Logger.Info(fmt.Sprintf("完成恢复应用(%s)运行环境", app.Service.ServiceAlias), map[string]string{"step": "restore_builder", "status": "running"})
 
 		b.Logger.Info(fmt.Sprintf("开始恢复应用(%s)持久化数据", app.Service.ServiceAlias), map[string]string{"step": "restore_builder", "status": "starting"})
+		//restore the volumes from snapshots
+		snapshotted, err := b.restoreVolumeSnapshots(app)
+		if err != nil {
+			return err
+		}
 		//restore app data
 
 		//if all data backup file exist, restore all data directly
 		allDataFilePath := fmt.Sprintf("%s/data_%s/%s.zip", b.cacheDir, b.getOldServiceID(app.ServiceID), "__all_data")
 		allDataRestore := false
 		allTmpDir := fmt.Sprintf("/grdata/tmp/%s", app.ServiceID)
+		//the data of incremental backup is restored as directory
+		allDataDir := fmt.Sprintf("%s/data_%s/%s", b.cacheDir, b.getOldServiceID(app.ServiceID), "__all_data")
 		if exist, _ := util.FileExists(allDataFilePath); exist {
 			logrus.Infof("unzip all data from %s to %s", allDataFilePath, allTmpDir)
 			if err := util.Unzip(allDataFilePath, allTmpDir); err != nil {
@@ -214,17 +243,22 @@ func (b *BackupAPPRestore) restoreVersionAndData(backup *dbmodel.AppBackup, appS
 			} else {
 				allDataRestore = true
 			}
+		} else if exist, _ := util.FileExists(allDataDir); exist {
+			allTmpDir = allDataDir
+			allDataRestore = true
 		}
 		for _, volume := range app.ServiceVolume {
-			if volume.HostPath == "" {
+			if volume.HostPath == "" || snapshotted[volume.ID] {
 				continue
 			}
 			var tmpDir string
 			if !allDataRestore {
 				dstDir := fmt.Sprintf("%s/data_%s/%s.zip", b.cacheDir, b.getOldServiceID(app.ServiceID), strings.Replace(volume.VolumeName, "/", "", -1))
 				tmpDir = fmt.Sprintf("/grdata/tmp/%s_%d", volume.ServiceID, volume.ID)
-				logrus.Infof("unzip %s to %s", dstDir, tmpDir)
-				if err := util.Unzip(dstDir, tmpDir); err != nil {
+				volumeDataDir := strings.TrimSuffix(dstDir, ".zip")
+				if exist, _ := util.FileExists(volumeDataDir); exist {
+					tmpDir = volumeDataDir
+				} else if err := util.Unzip(dstDir, tmpDir); err != nil {
 					if !strings.Contains(err.Error(), "no such file") {
 						logrus.Errorf("restore service(%s) volume(%s) data error.%s", app.ServiceID, volume.VolumeName, err.Error())
 						return err
@@ -335,6 +369,35 @@ func (b *BackupAPPRestore) restoreVersionAndData(backup *dbmodel.AppBackup, appS
 	return nil
 }
 
+//restoreVolumeSnapshots create the claims of volumes from the snapshots, return the new volume ids restored
+func (b *BackupAPPRestore) restoreVolumeSnapshots(app *RegionServiceSnapshot) (map[uint]bool, error) {
+	records, err := readVolumeSnapshots(fmt.Sprintf("%s/data_%s", b.cacheDir, b.getOldServiceID(app.ServiceID)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read volume snapshots: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	if b.RestoreMode == "od" {
+		return nil, fmt.Errorf("the volumes of application(%s) are backup by snapshot, which can not be restored in other datacenter", app.Service.ServiceAlias)
+	}
+	snapshotter, err := newVolumeSnapshotter(b.kubeClient, b.dynamicClient, b.restConfig, b.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapshotter.restoreClaims(records, app, b.volumeIDMap, b.RestoreID); err != nil {
+		return nil, err
+	}
+	snapshotted := make(map[uint]bool)
+	for _, record := range records {
+		snapshotted[b.volumeIDMap[record.VolumeID]] = true
+	}
+	return snapshotted, nil
+}
+
 func (b *BackupAPPRestore) getOldServiceID(new string) string {
 	for k, v := range b.serviceChange {
 		if v.ServiceID == new {
@@ -698,11 +761,33 @@ func (b *BackupAPPRestore) downloadFromLocal(backup *dbmodel.AppBackup) error {
 	return nil
 }
 
-func (b *BackupAPPRestore) downloadFromS3(sourceDir string) error {
-	s3Provider, err := cloudos.Str2S3Provider(b.S3Config.Provider)
+//downloadIncremental rebuild the backup files from the chunks of incremental backup
+func (b *BackupAPPRestore) downloadIncremental(backup *dbmodel.AppBackup) error {
+	cloudoser, err := b.newCloudOSer()
+	if err != nil {
+		return err
+	}
+	chunks, err := newChunkStore(cloudoser, chunkPrefix(backup.GroupID, backup.Encrypted), b.cacheDir+"_chunks")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(chunks.tmpDir)
+	manifest, err := chunks.getManifest(backup.BackupID)
 	if err != nil {
 		return err
 	}
+	if err := chunks.restore(manifest, b.cacheDir); err != nil {
+		return err
+	}
+	logrus.Debugf("successfully restore %d files of incremental backup %s", len(manifest.Files), backup.BackupID)
+	return nil
+}
+
+func (b *BackupAPPRestore) newCloudOSer() (cloudos.CloudOSer, error) {
+	s3Provider, err := cloudos.Str2S3Provider(b.S3Config.Provider)
+	if err != nil {
+		return nil, err
+	}
 	cfg := &cloudos.Config{
 		ProviderType: s3Provider,
 		Endpoint:     b.S3Config.Endpoint,
@@ -710,9 +795,23 @@ func (b *BackupAPPRestore) downloadFromS3(sourceDir string) error {
 		SecretKey:    b.S3Config.SecretKey,
 		BucketName:   b.S3Config.BucketName,
 	}
+	if b.encrypted {
+		cfg.EncryptionKey, err = cloudos.LoadEncryptionKey(context.Background(), b.kubeClient, b.rbdNamespace, &b.Encryption)
+		if err != nil {
+			return nil, err
+		}
+	}
 	cloudoser, err := cloudos.New(cfg)
 	if err != nil {
-		return fmt.Errorf("error creating cloudoser: %v", err)
+		return nil, fmt.Errorf("error creating cloudoser: %v", err)
+	}
+	return cloudoser, nil
+}
+
+func (b *BackupAPPRestore) downloadFromS3(sourceDir string) error {
+	cloudoser, err := b.newCloudOSer()
+	if err != nil {
+		return err
 	}
 
 	_, objectKey := filepath.Split(sourceDir)
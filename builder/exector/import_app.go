@@ -35,9 +35,11 @@ import (
 	"github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
 	"github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/builder"
+	"github.com/goodrain/rainbond/builder/cloudos"
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/event"
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
 )
 
 func init() {
@@ -53,8 +55,13 @@ type ImportApp struct {
 	ServiceImage  model.ServiceImage `json:"service_image"`
 	Logger        event.Logger
 	DockerClient  *client.Client
+	kubeClient    kubernetes.Interface
+	rbdNamespace  string
 	oldAPPPath    map[string]string
 	oldPluginPath map[string]string
+
+	//Encryption decrypt the app packages end with .enc
+	Encryption cloudos.EncryptionConfig `json:"encryption"`
 }
 
 //NewImportApp create
@@ -71,6 +78,8 @@ func NewImportApp(in []byte, m *exectorManager) (TaskWorker, error) {
 	logrus.Infof("load app image to hub %s", importApp.ServiceImage.HubURL)
 	importApp.Logger = event.GetManager().GetLogger(importApp.EventID)
 	importApp.DockerClient = m.DockerClient
+	importApp.kubeClient = m.KubeClient
+	importApp.rbdNamespace = m.cfg.RbdNamespace
 	importApp.oldAPPPath = make(map[string]string)
 	importApp.oldPluginPath = make(map[string]string)
 	return &importApp, nil
@@ -113,6 +122,13 @@ func (i *ImportApp) Run(timeout time.Duration) error {
 // support batch import
 func (i *ImportApp) importApp() error {
 	oldSourceDir := i.SourceDir
+	var crypter cloudos.CloudOSer
+	if i.Encryption.Enabled() {
+		var err error
+		if crypter, err = newPackageCrypter(i.kubeClient, i.rbdNamespace, &i.Encryption, oldSourceDir); err != nil {
+			return err
+		}
+	}
 	var datas []v1alpha1.RainbondApplicationConfig
 	var wait sync.WaitGroup
 	for _, app := range i.Apps {
@@ -125,6 +141,20 @@ func (i *ImportApp) importApp() error {
 			if err := i.updateStatusForApp(app, "importing"); err != nil {
 				logrus.Errorf("Failed to update status to importing for app %s: %v", app, err)
 			}
+			if strings.HasSuffix(app, ".enc") {
+				if crypter == nil {
+					logrus.Errorf("Failed to load app %s: the package is encrypted, the encryption secret is required", appFile)
+					i.updateStatusForApp(app, "failed")
+					return
+				}
+				appFile = filepath.Join(oldSourceDir, strings.TrimSuffix(app, ".enc"))
+				if err := crypter.GetObject(app, appFile); err != nil {
+					logrus.Errorf("Failed to decrypt app %s: %v", app, err)
+					i.updateStatusForApp(app, "failed")
+					return
+				}
+				defer os.Remove(appFile)
+			}
 			ram, err := li.Import(appFile, v1alpha1.ImageInfo{
 				HubURL:      i.ServiceImage.HubURL,
 				HubUser:     i.ServiceImage.HubUser,
@@ -136,7 +166,7 @@ func (i *ImportApp) importApp() error {
 				i.updateStatusForApp(app, "failed")
 				return
 			}
-			os.Rename(appFile, appFile+".success")
+			os.Rename(filepath.Join(oldSourceDir, app), filepath.Join(oldSourceDir, app)+".success")
 			datas = append(datas, *ram)
 			logrus.Infof("Successful import app: %s", appFile)
 			os.Remove(tmpDir)
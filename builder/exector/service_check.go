@@ -42,6 +42,8 @@ type ServiceCheckInput struct {
 	// 代码： https://github.com/shurcooL/githubql.git master
 	// docker-run: docker run --name xxx nginx:latest nginx
 	// docker-compose: compose全文
+	// kubernetes: kubernetes manifests
+	// helm-chart: parser.HelmChartSource json
 	SourceBody string `json:"source_body"`
 	Username   string `json:"username"`
 	Password   string `json:"password"`
@@ -119,6 +121,10 @@ func (e *exectorManager) serviceCheck(task *pb.TaskMessage) {
 		pr = parser.CreateSourceCodeParse(input.SourceBody, logger)
 	case "third-party-service":
 		pr = parser.CreateThirdPartyServiceParse(input.SourceBody, logger)
+	case "kubernetes":
+		pr = parser.CreateKubernetesParse(input.SourceBody, logger)
+	case "helm-chart":
+		pr = parser.CreateHelmChartParse(input.SourceBody, input.Username, input.Password, logger)
 	}
 	if pr == nil {
 		logger.Error("Creating component source types is not supported", map[string]string{"step": "callback", "status": "failure"})
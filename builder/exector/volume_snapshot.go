@@ -0,0 +1,524 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package exector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/event"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+//volumeSnapshotGroup the api group of CSI volume snapshot
+const volumeSnapshotGroup = "snapshot.storage.k8s.io"
+
+//volumeSnapshotsFile the file saves the volume snapshots of component in backup package
+const volumeSnapshotsFile = "volume_snapshots.json"
+
+//volumeSnapshotTimeout the timeout of waiting volume snapshot ready
+var volumeSnapshotTimeout = 10 * time.Minute
+
+//volumeSnapshotPollInterval the interval of checking volume snapshot status
+var volumeSnapshotPollInterval = 2 * time.Second
+
+//VolumeSnapshotConfig the volume snapshot options of group app backup
+type VolumeSnapshotConfig struct {
+	//Enable backup the volume data by snapshot if the storage class supports, the files are copied by default
+	Enable bool `json:"enable"`
+	//Hooks the quiesce hooks of components, key is service id
+	Hooks map[string]*SnapshotHook `json:"hooks"`
+}
+
+//SnapshotHook the commands executed in the pods of component to quiesce it before the volumes are snapshotted,
+//and resume it after the snapshots are cut.
+type SnapshotHook struct {
+	//Container the container to execute the commands, default is the first container of pod
+	Container string   `json:"container"`
+	Pre       []string `json:"pre"`
+	Post      []string `json:"post"`
+	//TimeoutSeconds the timeout of every command, default is 30 seconds
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+func (h *SnapshotHook) timeout() time.Duration {
+	if h.TimeoutSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
+
+//volumeSnapshotRecord the snapshot of a volume claim
+type volumeSnapshotRecord struct {
+	VolumeID   uint   `json:"volume_id"`
+	VolumeName string `json:"volume_name"`
+	ClaimName  string `json:"claim_name"`
+	//Ordinal the pod ordinal of statefulset claim, -1 if the claim is not created by statefulset
+	Ordinal       int                                 `json:"ordinal"`
+	Namespace     string                              `json:"namespace"`
+	SnapshotName  string                              `json:"snapshot_name"`
+	SnapshotClass string                              `json:"snapshot_class"`
+	StorageClass  string                              `json:"storage_class"`
+	AccessModes   []corev1.PersistentVolumeAccessMode `json:"access_modes"`
+	Capacity      string                              `json:"capacity"`
+}
+
+//volumeSnapshotter backup and restore the volumes by CSI volume snapshot
+type volumeSnapshotter struct {
+	kubeClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+	version       string
+	logger        event.Logger
+	//exec execute command in the container of pod
+	exec func(namespace, pod, container string, command []string, timeout time.Duration) error
+	//classes the snapshot class of storage class
+	classes map[string]string
+}
+
+//newVolumeSnapshotter return error if the cluster does not support volume snapshot
+func newVolumeSnapshotter(kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, restConfig *rest.Config, logger event.Logger) (*volumeSnapshotter, error) {
+	if kubeClient == nil || dynamicClient == nil {
+		return nil, fmt.Errorf("kubernetes client is not ready")
+	}
+	var version string
+	for _, v := range []string{"v1", "v1beta1"} {
+		if _, err := kubeClient.Discovery().ServerResourcesForGroupVersion(volumeSnapshotGroup + "/" + v); err == nil {
+			version = v
+			break
+		}
+	}
+	if version == "" {
+		return nil, fmt.Errorf("the cluster does not support volume snapshot")
+	}
+	s := &volumeSnapshotter{
+		kubeClient:    kubeClient,
+		dynamicClient: dynamicClient,
+		version:       version,
+		logger:        logger,
+		classes:       make(map[string]string),
+	}
+	s.exec = func(namespace, pod, container string, command []string, timeout time.Duration) error {
+		return podExec(kubeClient, restConfig, namespace, pod, container, command, timeout)
+	}
+	return s, nil
+}
+
+func (s *volumeSnapshotter) gvr(resource string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: volumeSnapshotGroup, Version: s.version, Resource: resource}
+}
+
+//snapshotClass return the snapshot class whose driver is the provisioner of storage class,
+//return empty if the storage class does not support snapshot
+func (s *volumeSnapshotter) snapshotClass(storageClass string) string {
+	if class, ok := s.classes[storageClass]; ok {
+		return class
+	}
+	var class string
+	defer func() { s.classes[storageClass] = class }()
+	sc, err := s.kubeClient.StorageV1().StorageClasses().Get(context.Background(), storageClass, metav1.GetOptions{})
+	if err != nil {
+		logrus.Debugf("get storage class %s failure: %v", storageClass, err)
+		return ""
+	}
+	classes, err := s.dynamicClient.Resource(s.gvr("volumesnapshotclasses")).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		logrus.Warningf("list volume snapshot classes failure: %v", err)
+		return ""
+	}
+	for _, item := range classes.Items {
+		driver, _, _ := unstructured.NestedString(item.Object, "driver")
+		if driver != sc.Provisioner {
+			continue
+		}
+		if item.GetAnnotations()["snapshot.storage.kubernetes.io/is-default-class"] == "true" {
+			class = item.GetName()
+			break
+		}
+		if class == "" {
+			class = item.GetName()
+		}
+	}
+	return class
+}
+
+//backupService snapshot the volumes of component whose storage class supports snapshot,
+//the volumes not snapshotted should be backup by copying files.
+func (s *volumeSnapshotter) backupService(app *RegionServiceSnapshot, backupID string, hook *SnapshotHook) ([]*volumeSnapshotRecord, error) {
+	namespace := app.Service.TenantID
+	var records []*volumeSnapshotRecord
+	for _, volume := range app.ServiceVolume {
+		switch volume.VolumeType {
+		case dbmodel.ShareFileVolumeType.String(), dbmodel.LocalVolumeType.String(),
+			dbmodel.MemoryFSVolumeType.String(), dbmodel.ConfigFileVolumeType.String():
+			continue
+		}
+		class := s.snapshotClass(volume.VolumeType)
+		if class == "" {
+			logrus.Infof("storage class %s does not support snapshot, volume %s will be backup by copying files", volume.VolumeType, volume.VolumeName)
+			continue
+		}
+		claims, err := s.kubeClient.CoreV1().PersistentVolumeClaims(namespace).List(context.Background(), metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("service_id=%s,volume_name=%s", app.ServiceID, volume.VolumeName),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list claims of volume %s: %v", volume.VolumeName, err)
+		}
+		for _, claim := range claims.Items {
+			if claim.Status.Phase != corev1.ClaimBound {
+				continue
+			}
+			capacity := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+			if size, ok := claim.Status.Capacity[corev1.ResourceStorage]; ok {
+				capacity = size
+			}
+			records = append(records, &volumeSnapshotRecord{
+				VolumeID:      volume.ID,
+				VolumeName:    volume.VolumeName,
+				ClaimName:     claim.Name,
+				Ordinal:       claimOrdinal(claim.Name, fmt.Sprintf("manual%d", volume.ID)),
+				Namespace:     namespace,
+				SnapshotName:  fmt.Sprintf("%s-%s", claim.Name, backupID),
+				SnapshotClass: class,
+				StorageClass:  volume.VolumeType,
+				AccessModes:   claim.Spec.AccessModes,
+				Capacity:      capacity.String(),
+			})
+		}
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	if hook != nil && len(hook.Pre) > 0 {
+		s.logger.Info(fmt.Sprintf("Quiesce application(%s) before snapshot", app.Service.ServiceAlias), map[string]string{"step": "backup_builder", "status": "running"})
+		if err := s.runHook(namespace, app.ServiceID, hook, hook.Pre); err != nil {
+			s.resume(namespace, app.ServiceID, hook)
+			return nil, fmt.Errorf("pre snapshot hook of %s: %v", app.Service.ServiceAlias, err)
+		}
+	}
+	err := s.cutSnapshots(records, backupID, app.ServiceID)
+	//resume the component as soon as the snapshots are cut, whether success or not
+	s.resume(namespace, app.ServiceID, hook)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if err := s.waitSnapshot(record.Namespace, record.SnapshotName, true); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+func (s *volumeSnapshotter) cutSnapshots(records []*volumeSnapshotRecord, backupID, serviceID string) error {
+	for _, record := range records {
+		snapshot := s.newSnapshot(record.Namespace, record.SnapshotName, map[string]interface{}{
+			"volumeSnapshotClassName": record.SnapshotClass,
+			"source": map[string]interface{}{
+				"persistentVolumeClaimName": record.ClaimName,
+			},
+		})
+		snapshot.SetLabels(map[string]string{"creator": "Rainbond", "backup_id": backupID, "service_id": serviceID})
+		if _, err := s.dynamicClient.Resource(s.gvr("volumesnapshots")).Namespace(record.Namespace).Create(context.Background(), snapshot, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create snapshot of claim %s: %v", record.ClaimName, err)
+		}
+	}
+	for _, record := range records {
+		if err := s.waitSnapshot(record.Namespace, record.SnapshotName, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *volumeSnapshotter) resume(namespace, serviceID string, hook *SnapshotHook) {
+	if hook == nil || len(hook.Post) == 0 {
+		return
+	}
+	if err := s.runHook(namespace, serviceID, hook, hook.Post); err != nil {
+		logrus.Errorf("post snapshot hook of service %s failure: %v", serviceID, err)
+		s.logger.Error(fmt.Sprintf("Resume application after snapshot failure: %v", err), map[string]string{"step": "backup_builder", "status": "failure"})
+	}
+}
+
+func (s *volumeSnapshotter) newSnapshot(namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	snapshot.SetAPIVersion(volumeSnapshotGroup + "/" + s.version)
+	snapshot.SetKind("VolumeSnapshot")
+	snapshot.SetNamespace(namespace)
+	snapshot.SetName(name)
+	return snapshot
+}
+
+//runHook execute the command in all running pods of component
+func (s *volumeSnapshotter) runHook(namespace, serviceID string, hook *SnapshotHook, command []string) error {
+	pods, err := s.kubeClient.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("service_id=%s", serviceID),
+	})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || len(pod.Spec.Containers) == 0 {
+			continue
+		}
+		container := hook.Container
+		if container == "" {
+			container = pod.Spec.Containers[0].Name
+		}
+		if err := s.exec(namespace, pod.Name, container, command, hook.timeout()); err != nil {
+			return fmt.Errorf("exec %s in pod %s: %v", strings.Join(command, " "), pod.Name, err)
+		}
+	}
+	return nil
+}
+
+//waitSnapshot wait the snapshot is cut or ready to use
+func (s *volumeSnapshotter) waitSnapshot(namespace, name string, ready bool) error {
+	return wait.PollImmediate(volumeSnapshotPollInterval, volumeSnapshotTimeout, func() (bool, error) {
+		snapshot, err := s.dynamicClient.Resource(s.gvr("volumesnapshots")).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if message, ok, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); ok && message != "" {
+			return false, fmt.Errorf("snapshot %s failure: %s", name, message)
+		}
+		readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		if ready || readyToUse {
+			return readyToUse, nil
+		}
+		creationTime, _, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime")
+		return creationTime != "", nil
+	})
+}
+
+//restoreClaims create the claims of the restored component from the snapshots,
+//the claims are named as the worker names them, so they are used by the component directly.
+func (s *volumeSnapshotter) restoreClaims(records []*volumeSnapshotRecord, app *RegionServiceSnapshot, volumeIDMap map[uint]uint, restoreID string) error {
+	namespace := app.Service.TenantID
+	for _, record := range records {
+		volumeID, ok := volumeIDMap[record.VolumeID]
+		if !ok {
+			return fmt.Errorf("volume %s of snapshot %s is not restored", record.VolumeName, record.SnapshotName)
+		}
+		claimName := fmt.Sprintf("manual%d", volumeID)
+		if record.Ordinal >= 0 {
+			//the statefulset is named by the service alias if the service name is empty
+			statefulName := app.Service.ServiceName
+			if statefulName == "" {
+				statefulName = app.Service.ServiceAlias
+			}
+			claimName = fmt.Sprintf("%s-%s-%d", claimName, statefulName, record.Ordinal)
+		}
+		snapshotName := record.SnapshotName
+		if namespace != record.Namespace {
+			var err error
+			if snapshotName, err = s.copySnapshot(record, namespace, restoreID); err != nil {
+				return err
+			}
+		}
+		capacity, err := resource.ParseQuantity(record.Capacity)
+		if err != nil {
+			return fmt.Errorf("invalid capacity of snapshot %s: %v", record.SnapshotName, err)
+		}
+		apiGroup := volumeSnapshotGroup
+		storageClass := record.StorageClass
+		claim := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      claimName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"creator":       "Rainbond",
+					"tenant_id":     namespace,
+					"service_id":    app.ServiceID,
+					"service_alias": app.Service.ServiceAlias,
+					"volume_name":   record.VolumeName,
+				},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      record.AccessModes,
+				StorageClassName: &storageClass,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: capacity},
+				},
+				DataSource: &corev1.TypedLocalObjectReference{
+					APIGroup: &apiGroup,
+					Kind:     "VolumeSnapshot",
+					Name:     snapshotName,
+				},
+			},
+		}
+		if _, err := s.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Create(context.Background(), claim, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create claim %s from snapshot %s: %v", claimName, snapshotName, err)
+		}
+	}
+	return nil
+}
+
+//copySnapshot the snapshot can only be used in its namespace,
+//bind a new snapshot in the namespace to the same storage snapshot by a pre-provisioned snapshot content.
+func (s *volumeSnapshotter) copySnapshot(record *volumeSnapshotRecord, namespace, restoreID string) (string, error) {
+	source, err := s.dynamicClient.Resource(s.gvr("volumesnapshots")).Namespace(record.Namespace).Get(context.Background(), record.SnapshotName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get snapshot %s: %v", record.SnapshotName, err)
+	}
+	contentName, _, _ := unstructured.NestedString(source.Object, "status", "boundVolumeSnapshotContentName")
+	if contentName == "" {
+		return "", fmt.Errorf("snapshot %s is not bound", record.SnapshotName)
+	}
+	content, err := s.dynamicClient.Resource(s.gvr("volumesnapshotcontents")).Get(context.Background(), contentName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get snapshot content %s: %v", contentName, err)
+	}
+	driver, _, _ := unstructured.NestedString(content.Object, "spec", "driver")
+	handle, _, _ := unstructured.NestedString(content.Object, "status", "snapshotHandle")
+	if handle == "" {
+		return "", fmt.Errorf("snapshot content %s has no snapshot handle", contentName)
+	}
+	name := record.SnapshotName
+	newContent := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			// the storage snapshot is still used by the backup
+			"deletionPolicy":          "Retain",
+			"driver":                  driver,
+			"volumeSnapshotClassName": record.SnapshotClass,
+			"source": map[string]interface{}{
+				"snapshotHandle": handle,
+			},
+			"volumeSnapshotRef": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+		},
+	}}
+	newContent.SetAPIVersion(volumeSnapshotGroup + "/" + s.version)
+	newContent.SetKind("VolumeSnapshotContent")
+	newContent.SetName(fmt.Sprintf("%s-%s", name, restoreID))
+	if _, err := s.dynamicClient.Resource(s.gvr("volumesnapshotcontents")).Create(context.Background(), newContent, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("create snapshot content %s: %v", newContent.GetName(), err)
+	}
+	snapshot := s.newSnapshot(namespace, name, map[string]interface{}{
+		"source": map[string]interface{}{
+			"volumeSnapshotContentName": newContent.GetName(),
+		},
+	})
+	if _, err := s.dynamicClient.Resource(s.gvr("volumesnapshots")).Namespace(namespace).Create(context.Background(), snapshot, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("create snapshot %s in %s: %v", name, namespace, err)
+	}
+	return name, s.waitSnapshot(namespace, name, true)
+}
+
+//deleteSnapshots delete the volume snapshots of backup in all namespaces
+func (s *volumeSnapshotter) deleteSnapshots(backupID string) error {
+	snapshots, err := s.dynamicClient.Resource(s.gvr("volumesnapshots")).Namespace(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("backup_id=%s", backupID),
+	})
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots.Items {
+		if err := s.dynamicClient.Resource(s.gvr("volumesnapshots")).Namespace(snapshot.GetNamespace()).Delete(context.Background(), snapshot.GetName(), metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("delete snapshot %s/%s: %v", snapshot.GetNamespace(), snapshot.GetName(), err)
+		}
+	}
+	return nil
+}
+
+//claimOrdinal return the pod ordinal of the statefulset claim named <prefix>-<statefulset>-<ordinal>
+func claimOrdinal(claimName, prefix string) int {
+	if !strings.HasPrefix(claimName, prefix+"-") {
+		return -1
+	}
+	index := strings.LastIndex(claimName, "-")
+	var ordinal int
+	if _, err := fmt.Sscanf(claimName[index+1:], "%d", &ordinal); err != nil || index <= len(prefix) {
+		return -1
+	}
+	return ordinal
+}
+
+func saveVolumeSnapshots(dir string, records []*volumeSnapshotRecord) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(dir, volumeSnapshotsFile), body, 0644)
+}
+
+func readVolumeSnapshots(dir string) ([]*volumeSnapshotRecord, error) {
+	body, err := ioutil.ReadFile(path.Join(dir, volumeSnapshotsFile))
+	if err != nil {
+		return nil, err
+	}
+	var records []*volumeSnapshotRecord
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+//podExec execute the command in container and wait it exit
+func podExec(kubeClient kubernetes.Interface, restConfig *rest.Config, namespace, pod, container string, command []string, timeout time.Duration) error {
+	req := kubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- exec.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	}()
+	select {
+	case err := <-done:
+		if err != nil && strings.TrimSpace(stderr.String()) != "" {
+			return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("timeout after %s", timeout)
+	}
+}
@@ -0,0 +1,208 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package exector
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/event"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestSnapshotter(t *testing.T, objects ...runtime.Object) (*volumeSnapshotter, *[]string) {
+	kubeClient := fake.NewSimpleClientset(objects...)
+	kubeClient.Resources = []*metav1.APIResourceList{{GroupVersion: volumeSnapshotGroup + "/v1beta1"}}
+	class := &unstructured.Unstructured{Object: map[string]interface{}{"driver": "rbd.csi.ceph.com"}}
+	class.SetAPIVersion(volumeSnapshotGroup + "/v1beta1")
+	class.SetKind("VolumeSnapshotClass")
+	class.SetName("rbd-snapshot")
+	listKinds := make(map[schema.GroupVersionResource]string)
+	for resource, kind := range map[string]string{"volumesnapshots": "VolumeSnapshotList", "volumesnapshotclasses": "VolumeSnapshotClassList", "volumesnapshotcontents": "VolumeSnapshotContentList"} {
+		listKinds[schema.GroupVersionResource{Group: volumeSnapshotGroup, Version: "v1beta1", Resource: resource}] = kind
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, class)
+	//the snapshot controller binds the snapshot and makes it ready
+	dynamicClient.PrependReactor("create", "volumesnapshots", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		snapshot := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		unstructured.SetNestedField(snapshot.Object, map[string]interface{}{
+			"creationTime":                   time.Now().Format(time.RFC3339),
+			"readyToUse":                     true,
+			"boundVolumeSnapshotContentName": "snapcontent-" + snapshot.GetName(),
+		}, "status")
+		return false, nil, nil
+	})
+	s, err := newVolumeSnapshotter(kubeClient, dynamicClient, nil, event.GetTestLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var execs []string
+	s.exec = func(namespace, pod, container string, command []string, timeout time.Duration) error {
+		execs = append(execs, pod+"/"+container+":"+strings.Join(command, " "))
+		return nil
+	}
+	return s, &execs
+}
+
+func testClaim(name, volumeName string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "tenant",
+			Labels:    map[string]string{"service_id": "sid", "volume_name": volumeName},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    corev1.ClaimBound,
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+		},
+	}
+}
+
+func TestVolumeSnapshotBackupAndRestore(t *testing.T) {
+	defer func(interval time.Duration) { volumeSnapshotPollInterval = interval }(volumeSnapshotPollInterval)
+	volumeSnapshotPollInterval = time.Millisecond
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "tenant", Labels: map[string]string{"service_id": "sid"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "sid"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	s, execs := newTestSnapshotter(t,
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "ceph-rbd"}, Provisioner: "rbd.csi.ceph.com"},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "nfs"}, Provisioner: "nfs"},
+		testClaim("manual7-grdb-0", "data"), testClaim("manual7-grdb-1", "data"), testClaim("manual8", "logs"), pod)
+	app := &RegionServiceSnapshot{
+		ServiceID: "sid",
+		Service:   &dbmodel.TenantServices{TenantID: "tenant", ServiceID: "sid", ServiceAlias: "grdb"},
+		ServiceVolume: []*dbmodel.TenantServiceVolume{
+			{Model: dbmodel.Model{ID: 7}, VolumeName: "data", VolumeType: "ceph-rbd"},
+			{Model: dbmodel.Model{ID: 8}, VolumeName: "logs", VolumeType: "nfs"},
+			{Model: dbmodel.Model{ID: 9}, VolumeName: "share", VolumeType: "share-file"},
+		},
+	}
+	hook := &SnapshotHook{Pre: []string{"fsfreeze", "-f", "/data"}, Post: []string{"fsfreeze", "-u", "/data"}}
+	records, err := s.backupService(app, "backup1", hook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Ordinal != 0 || records[1].Ordinal != 1 || records[0].SnapshotClass != "rbd-snapshot" || records[0].Capacity != "10Gi" {
+		t.Fatalf("unexpected snapshot records %+v", records)
+	}
+	if expect := []string{"db-0/sid:fsfreeze -f /data", "db-0/sid:fsfreeze -u /data"}; !reflect.DeepEqual(*execs, expect) {
+		t.Errorf("expect hooks %v, got %v", expect, *execs)
+	}
+
+	for _, record := range records {
+		content := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec":   map[string]interface{}{"driver": "rbd.csi.ceph.com"},
+			"status": map[string]interface{}{"snapshotHandle": "handle-" + record.SnapshotName},
+		}}
+		content.SetAPIVersion(volumeSnapshotGroup + "/v1beta1")
+		content.SetKind("VolumeSnapshotContent")
+		content.SetName("snapcontent-" + record.SnapshotName)
+		if _, err := s.dynamicClient.Resource(s.gvr("volumesnapshotcontents")).Create(context.Background(), content, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, _ := ioutil.TempDir("", "volume-snapshots")
+	defer os.RemoveAll(dir)
+	if err := saveVolumeSnapshots(dir, records); err != nil {
+		t.Fatal(err)
+	}
+	records, err = readVolumeSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//restore into other tenant
+	app.ServiceID = "newsid"
+	app.Service = &dbmodel.TenantServices{TenantID: "other", ServiceID: "newsid", ServiceAlias: "grnew"}
+	if err := s.restoreClaims(records, app, map[uint]uint{7: 17}, "restore1"); err != nil {
+		t.Fatal(err)
+	}
+	claim, err := s.kubeClient.CoreV1().PersistentVolumeClaims("other").Get(context.Background(), "manual17-grnew-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claim.Spec.DataSource == nil || claim.Spec.DataSource.Name != "manual7-grdb-1-backup1" || *claim.Spec.StorageClassName != "ceph-rbd" || claim.Labels["service_id"] != "newsid" {
+		t.Errorf("unexpected restored claim %+v", claim)
+	}
+	content, err := s.dynamicClient.Resource(s.gvr("volumesnapshotcontents")).Get(context.Background(), "manual7-grdb-1-backup1-restore1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handle, _, _ := unstructured.NestedString(content.Object, "spec", "source", "snapshotHandle"); handle != "handle-manual7-grdb-1-backup1" {
+		t.Errorf("the copied snapshot should use the same storage snapshot, got %s", handle)
+	}
+
+	if err := s.deleteSnapshots("backup1"); err != nil {
+		t.Fatal(err)
+	}
+	list, _ := s.dynamicClient.Resource(s.gvr("volumesnapshots")).Namespace("tenant").List(context.Background(), metav1.ListOptions{})
+	if len(list.Items) != 0 {
+		t.Errorf("expect snapshots of backup deleted, got %d", len(list.Items))
+	}
+}
+
+func TestClaimOrdinal(t *testing.T) {
+	for name, expect := range map[string]int{"manual7": -1, "manual7-gr1234-2": 2, "manual77-gr1234-2": -1, "manual7-0": -1} {
+		if got := claimOrdinal(name, "manual7"); got != expect {
+			t.Errorf("ordinal of %s: expect %d, got %d", name, expect, got)
+		}
+	}
+}
+
+func TestSnapshotVolumesOptIn(t *testing.T) {
+	s, _ := newTestSnapshotter(t,
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "ceph-rbd"}, Provisioner: "rbd.csi.ceph.com"},
+		testClaim("manual7", "data"))
+	app := &RegionServiceSnapshot{
+		ServiceID:     "sid",
+		Service:       &dbmodel.TenantServices{TenantID: "tenant", ServiceID: "sid", ServiceAlias: "grdb"},
+		ServiceVolume: []*dbmodel.TenantServiceVolume{{Model: dbmodel.Model{ID: 7}, VolumeName: "data", VolumeType: "ceph-rbd"}},
+	}
+	//the snapshotter of scheduled backups only deletes the expired snapshots
+	b := &BackupAPPNew{snapshotter: s}
+	snapshotted, err := b.snapshotVolumes(app)
+	if err != nil || len(snapshotted) != 0 {
+		t.Errorf("expect the volumes copied if snapshot is not enabled, got %v, %v", snapshotted, err)
+	}
+	list, _ := s.dynamicClient.Resource(s.gvr("volumesnapshots")).Namespace("tenant").List(context.Background(), metav1.ListOptions{})
+	if len(list.Items) != 0 {
+		t.Errorf("expect no snapshot created, got %d", len(list.Items))
+	}
+}
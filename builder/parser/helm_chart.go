@@ -0,0 +1,215 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/event"
+	"github.com/sirupsen/logrus"
+)
+
+//helmTemplateTimeout the timeout of rendering chart, the chart may be downloaded from remote repository
+var helmTemplateTimeout = 5 * time.Minute
+
+//helmRepoName the name of the repository added to the temporary repository config
+const helmRepoName = "rainbond-import"
+
+var chartNameReg = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+//HelmChartSource the helm chart to be imported
+type HelmChartSource struct {
+	// the chart name in the repository of repo url, or the oci reference of the chart.
+	// the local paths and chart urls are not allowed.
+	Chart       string `json:"chart"`
+	RepoURL     string `json:"repo_url,omitempty"`
+	Version     string `json:"version,omitempty"`
+	ReleaseName string `json:"release_name,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	// the values yaml used to render the chart
+	Values string `json:"values,omitempty"`
+}
+
+//HelmChartParse helm chart parser,
+//the chart is rendered by helm template and the manifests are parsed by KubernetesParse.
+type HelmChartParse struct {
+	source   string
+	user     string
+	password string
+	errors   []ParseError
+	logger   event.Logger
+	manifest Parser
+}
+
+//CreateHelmChartParse create helm chart parser
+func CreateHelmChartParse(source, user, pass string, logger event.Logger) Parser {
+	return &HelmChartParse{
+		source:   source,
+		user:     user,
+		password: pass,
+		logger:   logger,
+	}
+}
+
+func (h *HelmChartParse) errappend(pe ParseError) {
+	h.errors = append(h.errors, pe)
+}
+
+//Parse render the chart and parse the manifests
+func (h *HelmChartParse) Parse() ParseErrorList {
+	var chart HelmChartSource
+	if err := json.Unmarshal([]byte(h.source), &chart); err != nil || chart.Chart == "" {
+		h.errappend(ErrorAndSolve(FatalError, "helm chart source is invalid", SolveAdvice("modify_helm_chart", "please specify the chart url or name")))
+		return h.errors
+	}
+	for _, arg := range []string{chart.Chart, chart.RepoURL, chart.Version, chart.ReleaseName, chart.Namespace} {
+		if strings.HasPrefix(arg, "-") {
+			h.errappend(ErrorAndSolve(FatalError, fmt.Sprintf("helm chart argument %s is invalid", arg), SolveAdvice("modify_helm_chart", "please check the chart source")))
+			return h.errors
+		}
+	}
+	if err := validateChartRef(&chart); err != nil {
+		h.errappend(ErrorAndSolve(FatalError, err.Error(), SolveAdvice("modify_helm_chart", "please specify the chart name and repo url, or the oci reference of chart")))
+		return h.errors
+	}
+	manifests, err := h.render(&chart)
+	if err != nil {
+		logrus.Warningf("render helm chart %s failure %s", chart.Chart, err.Error())
+		h.errappend(ErrorAndSolve(FatalError, fmt.Sprintf("render helm chart %s failure: %s", chart.Chart, err.Error()),
+			SolveAdvice("modify_helm_chart", "please make sure the chart can be rendered by helm template with the values")))
+		return h.errors
+	}
+	h.manifest = CreateKubernetesParse(manifests, h.logger)
+	h.errors = append(h.errors, h.manifest.Parse()...)
+	return h.errors
+}
+
+//validateChartRef only the charts in repositories are allowed, the files of builder must not be read by helm
+func validateChartRef(chart *HelmChartSource) error {
+	if chart.RepoURL == "" {
+		if !strings.HasPrefix(chart.Chart, "oci://") {
+			return fmt.Errorf("chart %s is not a chart in repository, the repo url or oci reference is required", chart.Chart)
+		}
+		return nil
+	}
+	u, err := url.Parse(chart.RepoURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("helm repo url %s is invalid", chart.RepoURL)
+	}
+	if !chartNameReg.MatchString(chart.Chart) {
+		return fmt.Errorf("chart name %s is invalid", chart.Chart)
+	}
+	return nil
+}
+
+//render render the chart by helm template.
+//the credentials are passed by stdin and kept in the temporary repository or registry config, rather than the args.
+func (h *HelmChartParse) render(chart *HelmChartSource) (string, error) {
+	helm, err := exec.LookPath("helm")
+	if err != nil {
+		return "", fmt.Errorf("helm command not found in builder")
+	}
+	tmpDir, err := ioutil.TempDir("", "helm-chart")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	env := append(os.Environ(),
+		"HELM_REPOSITORY_CONFIG="+filepath.Join(tmpDir, "repositories.yaml"),
+		"HELM_REPOSITORY_CACHE="+filepath.Join(tmpDir, "cache"),
+		"HELM_REGISTRY_CONFIG="+filepath.Join(tmpDir, "registry.json"))
+	ctx, cancel := context.WithTimeout(context.Background(), helmTemplateTimeout)
+	defer cancel()
+	release := chart.ReleaseName
+	if release == "" {
+		release = "rainbond"
+	}
+	args := []string{"template", release}
+	switch {
+	case chart.RepoURL != "" && h.user != "":
+		if _, err := runHelm(ctx, helm, env, h.password, "repo", "add", helmRepoName, chart.RepoURL, "--username", h.user, "--password-stdin"); err != nil {
+			return "", err
+		}
+		args = append(args, helmRepoName+"/"+chart.Chart)
+	case chart.RepoURL != "":
+		args = append(args, chart.Chart, "--repo", chart.RepoURL)
+	default:
+		if h.user != "" {
+			host := strings.SplitN(strings.TrimPrefix(chart.Chart, "oci://"), "/", 2)[0]
+			if _, err := runHelm(ctx, helm, env, h.password, "registry", "login", host, "--username", h.user, "--password-stdin"); err != nil {
+				return "", err
+			}
+		}
+		args = append(args, chart.Chart)
+	}
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+	if chart.Namespace != "" {
+		args = append(args, "--namespace", chart.Namespace)
+	}
+	if chart.Values != "" {
+		valuesFile := filepath.Join(tmpDir, "values.yaml")
+		if err := ioutil.WriteFile(valuesFile, []byte(chart.Values), 0600); err != nil {
+			return "", err
+		}
+		args = append(args, "--values", valuesFile)
+	}
+	return runHelm(ctx, helm, env, "", args...)
+}
+
+//runHelm run the helm command and return the stdout
+func runHelm(ctx context.Context, helm string, env []string, stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, helm, args...)
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+//GetServiceInfo return the components parsed from chart
+func (h *HelmChartParse) GetServiceInfo() []ServiceInfo {
+	if h.manifest == nil {
+		return nil
+	}
+	return h.manifest.GetServiceInfo()
+}
+
+//GetImage there is no single image for helm chart
+func (h *HelmChartParse) GetImage() Image {
+	return Image{}
+}
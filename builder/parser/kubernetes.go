@@ -0,0 +1,677 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/goodrain/rainbond/builder/parser/types"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/event"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+//supportedKinds the kinds of resources which can be imported
+const supportedKinds = "Deployment, StatefulSet, Service, ConfigMap, Secret, PersistentVolumeClaim and Ingress"
+
+//KubernetesParse kubernetes manifests parser,
+//Deployments and StatefulSets are imported as components.
+type KubernetesParse struct {
+	source     string
+	errors     []ParseError
+	logger     event.Logger
+	workloads  []*kubeWorkload
+	services   []*corev1.Service
+	configMaps map[string]map[string]string
+	secrets    map[string]map[string]string
+	claims     map[string]*corev1.PersistentVolumeClaim
+	ingresses  []kubeIngressPath
+	infos      []*ServiceInfo
+}
+
+//kubeWorkload Deployment or StatefulSet
+type kubeWorkload struct {
+	kind           string
+	name           string
+	replicas       *int32
+	template       corev1.PodTemplateSpec
+	claimTemplates []corev1.PersistentVolumeClaim
+}
+
+//kubeIngressPath the path of ingress rule
+type kubeIngressPath struct {
+	ingress string
+	host    string
+	path    string
+	service string
+	port    intstr.IntOrString
+	tls     bool
+}
+
+//CreateKubernetesParse create kubernetes manifests parser
+func CreateKubernetesParse(source string, logger event.Logger) Parser {
+	return &KubernetesParse{
+		source:     source,
+		logger:     logger,
+		configMaps: make(map[string]map[string]string),
+		secrets:    make(map[string]map[string]string),
+		claims:     make(map[string]*corev1.PersistentVolumeClaim),
+	}
+}
+
+func (k *KubernetesParse) errappend(pe ParseError) {
+	k.errors = append(k.errors, pe)
+}
+
+//Parse parse the manifests
+func (k *KubernetesParse) Parse() ParseErrorList {
+	if strings.TrimSpace(k.source) == "" {
+		k.errappend(Errorf(FatalError, "source can not be empty"))
+		return k.errors
+	}
+	decoder := k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(k.source), 4096)
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			logrus.Warningf("decode kubernetes manifests failure %s", err.Error())
+			k.errappend(ErrorAndSolve(FatalError, fmt.Sprintf("kubernetes manifests is invalid: %s", err.Error()), SolveAdvice("modify_kubernetes", "please make sure the manifests are valid yaml or json")))
+			return k.errors
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		if items, ok := doc["items"].([]interface{}); ok && strings.HasSuffix(fmt.Sprint(doc["kind"]), "List") {
+			for _, item := range items {
+				if obj, ok := item.(map[string]interface{}); ok {
+					k.addObject(obj)
+				}
+			}
+			continue
+		}
+		k.addObject(doc)
+	}
+	if len(k.workloads) == 0 {
+		k.errappend(ErrorAndSolve(FatalError, "no Deployment or StatefulSet found in the manifests", SolveAdvice("modify_kubernetes", "please make sure the manifests contain at least one Deployment or StatefulSet")))
+		return k.errors
+	}
+	for _, workload := range k.workloads {
+		if info := k.parseWorkload(workload); info != nil {
+			k.infos = append(k.infos, info)
+		}
+	}
+	k.parseServices()
+	k.parseIngresses()
+	k.parseDependencies()
+	return k.errors
+}
+
+//addObject decode the object by its kind
+func (k *KubernetesParse) addObject(obj map[string]interface{}) {
+	kind, _ := obj["kind"].(string)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	apiVersion, _ := obj["apiVersion"].(string)
+	body, _ := json.Marshal(obj)
+	decode := func(v interface{}) bool {
+		if err := json.Unmarshal(body, v); err != nil {
+			k.errappend(ErrorAndSolve(FatalError, fmt.Sprintf("%s/%s is invalid: %s", kind, name, err.Error()), SolveAdvice("modify_kubernetes", fmt.Sprintf("please check the definition of %s/%s", kind, name))))
+			return false
+		}
+		return true
+	}
+	switch kind {
+	case "Deployment":
+		var deploy appsv1.Deployment
+		if decode(&deploy) {
+			k.workloads = append(k.workloads, &kubeWorkload{kind: kind, name: name, replicas: deploy.Spec.Replicas, template: deploy.Spec.Template})
+		}
+	case "StatefulSet":
+		var sts appsv1.StatefulSet
+		if decode(&sts) {
+			k.workloads = append(k.workloads, &kubeWorkload{kind: kind, name: name, replicas: sts.Spec.Replicas, template: sts.Spec.Template, claimTemplates: sts.Spec.VolumeClaimTemplates})
+		}
+	case "Service":
+		var svc corev1.Service
+		if decode(&svc) {
+			k.services = append(k.services, &svc)
+		}
+	case "ConfigMap":
+		var cm corev1.ConfigMap
+		if decode(&cm) {
+			data := make(map[string]string, len(cm.Data)+len(cm.BinaryData))
+			for key, value := range cm.Data {
+				data[key] = value
+			}
+			for key, value := range cm.BinaryData {
+				data[key] = string(value)
+			}
+			k.configMaps[name] = data
+		}
+	case "Secret":
+		var secret corev1.Secret
+		if decode(&secret) {
+			data := make(map[string]string, len(secret.Data)+len(secret.StringData))
+			for key, value := range secret.Data {
+				data[key] = string(value)
+			}
+			for key, value := range secret.StringData {
+				data[key] = value
+			}
+			k.secrets[name] = data
+		}
+	case "PersistentVolumeClaim":
+		var pvc corev1.PersistentVolumeClaim
+		if decode(&pvc) {
+			k.claims[name] = &pvc
+		}
+	case "Ingress":
+		if apiVersion == "networking.k8s.io/v1" {
+			var ing networkingv1.Ingress
+			if decode(&ing) {
+				k.addIngressV1(&ing)
+			}
+			return
+		}
+		// extensions/v1beta1 has the same schema with networking.k8s.io/v1beta1
+		var ing networkingv1beta1.Ingress
+		if decode(&ing) {
+			k.addIngressV1beta1(&ing)
+		}
+	default:
+		k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("%s/%s is not supported and ignored", kind, name),
+			SolveAdvice("modify_kubernetes", fmt.Sprintf("only %s are imported", supportedKinds))))
+	}
+}
+
+func tlsHosts(tls []networkingv1.IngressTLS) map[string]bool {
+	hosts := make(map[string]bool)
+	for _, t := range tls {
+		for _, host := range t.Hosts {
+			hosts[host] = true
+		}
+	}
+	return hosts
+}
+
+func (k *KubernetesParse) addIngressV1(ing *networkingv1.Ingress) {
+	hosts := tlsHosts(ing.Spec.TLS)
+	if ing.Spec.DefaultBackend != nil {
+		k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the default backend of Ingress/%s is not supported", ing.Name), SolveAdvice("modify_kubernetes", "please add a http rule in gateway instead")))
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			if p.Backend.Service == nil {
+				k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the resource backend of Ingress/%s is not supported", ing.Name), SolveAdvice("modify_kubernetes", "only service backend is imported")))
+				continue
+			}
+			port := intstr.FromInt(int(p.Backend.Service.Port.Number))
+			if p.Backend.Service.Port.Name != "" {
+				port = intstr.FromString(p.Backend.Service.Port.Name)
+			}
+			k.ingresses = append(k.ingresses, kubeIngressPath{
+				ingress: ing.Name,
+				host:    rule.Host,
+				path:    p.Path,
+				service: p.Backend.Service.Name,
+				port:    port,
+				tls:     hosts[rule.Host],
+			})
+		}
+	}
+}
+
+func (k *KubernetesParse) addIngressV1beta1(ing *networkingv1beta1.Ingress) {
+	var tls []networkingv1.IngressTLS
+	for _, t := range ing.Spec.TLS {
+		tls = append(tls, networkingv1.IngressTLS{Hosts: t.Hosts})
+	}
+	hosts := tlsHosts(tls)
+	if ing.Spec.Backend != nil {
+		k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the default backend of Ingress/%s is not supported", ing.Name), SolveAdvice("modify_kubernetes", "please add a http rule in gateway instead")))
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			k.ingresses = append(k.ingresses, kubeIngressPath{
+				ingress: ing.Name,
+				host:    rule.Host,
+				path:    p.Path,
+				service: p.Backend.ServiceName,
+				port:    p.Backend.ServicePort,
+				tls:     hosts[rule.Host],
+			})
+		}
+	}
+}
+
+//unsupportedPodFields return the fields of pod which can not be imported
+func unsupportedPodFields(spec *corev1.PodSpec) (fields []string) {
+	check := func(set bool, field string) {
+		if set {
+			fields = append(fields, field)
+		}
+	}
+	check(len(spec.InitContainers) > 0, "initContainers")
+	check(len(spec.Containers) > 1, "sidecar containers")
+	check(spec.HostNetwork, "hostNetwork")
+	check(len(spec.NodeSelector) > 0, "nodeSelector")
+	check(spec.Affinity != nil, "affinity")
+	check(len(spec.Tolerations) > 0, "tolerations")
+	check(spec.ServiceAccountName != "", "serviceAccountName")
+	check(spec.SecurityContext != nil && (spec.SecurityContext.RunAsUser != nil || spec.SecurityContext.FSGroup != nil), "securityContext")
+	if len(spec.Containers) > 0 {
+		container := spec.Containers[0]
+		check(len(container.Command) > 0, "command")
+		check(container.LivenessProbe != nil || container.ReadinessProbe != nil, "probes")
+		check(container.Lifecycle != nil, "lifecycle")
+		check(container.SecurityContext != nil, "container securityContext")
+	}
+	return
+}
+
+func (k *KubernetesParse) parseWorkload(workload *kubeWorkload) *ServiceInfo {
+	resource := fmt.Sprintf("%s/%s", workload.kind, workload.name)
+	spec := &workload.template.Spec
+	if len(spec.Containers) == 0 || spec.Containers[0].Image == "" {
+		k.errappend(ErrorAndSolve(FatalError, fmt.Sprintf("%s has no image specified", resource), SolveAdvice("modify_kubernetes", fmt.Sprintf("please specify the image of %s", resource))))
+		return nil
+	}
+	container := spec.Containers[0]
+	if fields := unsupportedPodFields(spec); len(fields) > 0 {
+		k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the fields %s of %s are not supported and ignored", strings.Join(fields, ", "), resource),
+			SolveAdvice("modify_kubernetes", fmt.Sprintf("only the first container %s is imported, please configure the ignored fields in the component settings", container.Name))))
+	}
+	info := &ServiceInfo{
+		Name:     workload.name,
+		Cname:    workload.name,
+		Image:    ParseImageName(container.Image),
+		Args:     container.Args,
+		Memory:   512,
+		Replicas: 1,
+		OS:       runtime.GOOS,
+	}
+	if workload.replicas != nil {
+		info.Replicas = int(*workload.replicas)
+	}
+	if workload.kind == "StatefulSet" {
+		info.ServiceType = model.ServiceTypeStateMultiple.String()
+		if info.Replicas == 1 {
+			info.ServiceType = model.ServiceTypeStateSingleton.String()
+		}
+	} else {
+		info.ServiceType = model.ServiceTypeStatelessMultiple.String()
+	}
+	memory := container.Resources.Limits.Memory()
+	if memory.IsZero() {
+		memory = container.Resources.Requests.Memory()
+	}
+	if !memory.IsZero() {
+		info.Memory = int(memory.Value() / 1024 / 1024)
+	}
+	for _, port := range container.Ports {
+		info.addPort(int(port.ContainerPort), port.Protocol)
+	}
+	info.Envs = k.parseEnvs(resource, container)
+	info.Volumes = k.parseVolumes(workload, container)
+	return info
+}
+
+func (s *ServiceInfo) addPort(port int, protocol corev1.Protocol) {
+	for _, p := range s.Ports {
+		if p.ContainerPort == port {
+			return
+		}
+	}
+	pro := "udp"
+	if protocol != corev1.ProtocolUDP {
+		pro = GetPortProtocol(port)
+	}
+	s.Ports = append(s.Ports, types.Port{ContainerPort: port, Protocol: pro})
+}
+
+//lookupData find the data of configmap or secret
+func (k *KubernetesParse) lookupData(resource, kind, name string) (map[string]string, bool) {
+	data, ok := k.configMaps[name]
+	if kind == "Secret" {
+		data, ok = k.secrets[name]
+	}
+	if !ok {
+		k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("%s/%s referenced by %s not found", kind, name, resource),
+			SolveAdvice("modify_kubernetes", fmt.Sprintf("please add %s/%s to the manifests or set it in the component settings", kind, name))))
+	}
+	return data, ok
+}
+
+func (k *KubernetesParse) parseEnvs(resource string, container corev1.Container) (envs []types.Env) {
+	index := make(map[string]int)
+	setEnv := func(name, value string) {
+		if i, ok := index[name]; ok {
+			envs[i].Value = value
+			return
+		}
+		index[name] = len(envs)
+		envs = append(envs, types.Env{Name: name, Value: value})
+	}
+	setEnvs := func(kind, name, prefix string) {
+		data, _ := k.lookupData(resource, kind, name)
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			setEnv(prefix+key, data[key])
+		}
+	}
+	for _, from := range container.EnvFrom {
+		if from.ConfigMapRef != nil {
+			setEnvs("ConfigMap", from.ConfigMapRef.Name, from.Prefix)
+		}
+		if from.SecretRef != nil {
+			setEnvs("Secret", from.SecretRef.Name, from.Prefix)
+		}
+	}
+	for _, env := range container.Env {
+		if env.ValueFrom == nil {
+			setEnv(env.Name, env.Value)
+			continue
+		}
+		var kind, name, key string
+		switch {
+		case env.ValueFrom.ConfigMapKeyRef != nil:
+			kind, name, key = "ConfigMap", env.ValueFrom.ConfigMapKeyRef.Name, env.ValueFrom.ConfigMapKeyRef.Key
+		case env.ValueFrom.SecretKeyRef != nil:
+			kind, name, key = "Secret", env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key
+		default:
+			k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the env %s of %s references the field of pod, it is not supported", env.Name, resource),
+				SolveAdvice("modify_kubernetes", fmt.Sprintf("please set the value of env %s in the component settings", env.Name))))
+			continue
+		}
+		if data, ok := k.lookupData(resource, kind, name); ok {
+			setEnv(env.Name, data[key])
+		}
+	}
+	return
+}
+
+func claimCapacity(pvc *corev1.PersistentVolumeClaim) (capacity int64, accessMode string) {
+	storage := pvc.Spec.Resources.Requests.Storage()
+	if !storage.IsZero() {
+		// round up to GB
+		capacity = (storage.Value() + 1<<30 - 1) >> 30
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		switch mode {
+		case corev1.ReadWriteMany:
+			return capacity, "RWX"
+		case corev1.ReadOnlyMany:
+			accessMode = "ROX"
+		case corev1.ReadWriteOnce:
+			if accessMode == "" {
+				accessMode = "RWO"
+			}
+		}
+	}
+	return
+}
+
+func (k *KubernetesParse) parseVolumes(workload *kubeWorkload, container corev1.Container) (volumes []types.Volume) {
+	resource := fmt.Sprintf("%s/%s", workload.kind, workload.name)
+	podVolumes := make(map[string]corev1.Volume)
+	for _, vol := range workload.template.Spec.Volumes {
+		podVolumes[vol.Name] = vol
+	}
+	claimTemplates := make(map[string]*corev1.PersistentVolumeClaim)
+	for i := range workload.claimTemplates {
+		claimTemplates[workload.claimTemplates[i].Name] = &workload.claimTemplates[i]
+	}
+	persistentVolume := func(mount corev1.VolumeMount, pvc *corev1.PersistentVolumeClaim) types.Volume {
+		volume := types.Volume{VolumeName: mount.Name, VolumePath: mount.MountPath, VolumeType: model.ShareFileVolumeType.String()}
+		if pvc != nil {
+			volume.VolumeCapacity, volume.AccessMode = claimCapacity(pvc)
+		}
+		if workload.kind == "StatefulSet" && volume.AccessMode != "RWX" {
+			volume.VolumeType = model.LocalVolumeType.String()
+		}
+		return volume
+	}
+	for _, mount := range container.VolumeMounts {
+		if pvc, ok := claimTemplates[mount.Name]; ok {
+			volumes = append(volumes, persistentVolume(mount, pvc))
+			continue
+		}
+		vol, ok := podVolumes[mount.Name]
+		if !ok {
+			k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the volume %s mounted by %s not found", mount.Name, resource), SolveAdvice("modify_kubernetes", "please check the volumes of pod")))
+			continue
+		}
+		switch {
+		case vol.PersistentVolumeClaim != nil:
+			pvc, ok := k.claims[vol.PersistentVolumeClaim.ClaimName]
+			if !ok {
+				k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("PersistentVolumeClaim/%s referenced by %s not found, the default capacity is used", vol.PersistentVolumeClaim.ClaimName, resource),
+					SolveAdvice("modify_kubernetes", "please set the capacity of volume in the component settings")))
+			}
+			volumes = append(volumes, persistentVolume(mount, pvc))
+		case vol.ConfigMap != nil, vol.Secret != nil:
+			kind, name, items := "ConfigMap", "", []corev1.KeyToPath(nil)
+			if vol.ConfigMap != nil {
+				name, items = vol.ConfigMap.Name, vol.ConfigMap.Items
+			} else {
+				kind, name, items = "Secret", vol.Secret.SecretName, vol.Secret.Items
+			}
+			data, ok := k.lookupData(resource, kind, name)
+			if !ok {
+				continue
+			}
+			volumes = append(volumes, configFileVolumes(mount, data, items)...)
+		case vol.EmptyDir != nil && vol.EmptyDir.Medium == corev1.StorageMediumMemory:
+			volumes = append(volumes, types.Volume{VolumeName: mount.Name, VolumePath: mount.MountPath, VolumeType: model.MemoryFSVolumeType.String()})
+		default:
+			k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the volume %s of %s is not supported and ignored", mount.Name, resource),
+				SolveAdvice("modify_kubernetes", "only persistentVolumeClaim, configMap, secret and memory emptyDir volumes are imported")))
+		}
+	}
+	return
+}
+
+//configFileVolumes convert the configmap or secret volume to config files
+func configFileVolumes(mount corev1.VolumeMount, data map[string]string, items []corev1.KeyToPath) (volumes []types.Volume) {
+	files := make(map[string]string)
+	if len(items) > 0 {
+		for _, item := range items {
+			files[item.Path] = item.Key
+		}
+	} else {
+		for key := range data {
+			files[key] = key
+		}
+	}
+	if mount.SubPath != "" {
+		if key, ok := files[mount.SubPath]; ok {
+			volumes = append(volumes, types.Volume{VolumeName: mount.Name, VolumePath: mount.MountPath, VolumeType: model.ConfigFileVolumeType.String(), FileContent: data[key]})
+		}
+		return
+	}
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		volumes = append(volumes, types.Volume{
+			VolumeName:  mount.Name + "-" + strings.Replace(p, "/", "-", -1),
+			VolumePath:  path.Join(mount.MountPath, p),
+			VolumeType:  model.ConfigFileVolumeType.String(),
+			FileContent: data[files[p]],
+		})
+	}
+	return
+}
+
+//selectWorkload find the workload selected by the service
+func (k *KubernetesParse) selectWorkload(svc *corev1.Service) (*kubeWorkload, *ServiceInfo) {
+	if len(svc.Spec.Selector) == 0 {
+		return nil, nil
+	}
+	for _, workload := range k.workloads {
+		labels := workload.template.Labels
+		matched := true
+		for key, value := range svc.Spec.Selector {
+			matched = matched && labels[key] == value
+		}
+		if matched {
+			for _, info := range k.infos {
+				if info.Name == workload.name {
+					return workload, info
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+//targetPort resolve the container port of service port
+func targetPort(workload *kubeWorkload, port corev1.ServicePort) int {
+	if port.TargetPort.Type == intstr.String && port.TargetPort.StrVal != "" {
+		for _, container := range workload.template.Spec.Containers[:1] {
+			for _, p := range container.Ports {
+				if p.Name == port.TargetPort.StrVal {
+					return int(p.ContainerPort)
+				}
+			}
+		}
+		return 0
+	}
+	if port.TargetPort.IntVal != 0 {
+		return int(port.TargetPort.IntVal)
+	}
+	return int(port.Port)
+}
+
+func (k *KubernetesParse) parseServices() {
+	for _, svc := range k.services {
+		workload, info := k.selectWorkload(svc)
+		if info == nil {
+			k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("Service/%s does not select any Deployment or StatefulSet, it is ignored", svc.Name),
+				SolveAdvice("modify_kubernetes", "please create a third party component for the external service")))
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			if p := targetPort(workload, port); p > 0 {
+				info.addPort(p, port.Protocol)
+			}
+		}
+	}
+}
+
+func (k *KubernetesParse) parseIngresses() {
+	services := make(map[string]*corev1.Service, len(k.services))
+	for _, svc := range k.services {
+		services[svc.Name] = svc
+	}
+	for _, ing := range k.ingresses {
+		var containerPort int
+		var info *ServiceInfo
+		if svc, ok := services[ing.service]; ok {
+			var workload *kubeWorkload
+			workload, info = k.selectWorkload(svc)
+			for _, port := range svc.Spec.Ports {
+				if workload != nil && ((ing.port.Type == intstr.String && port.Name == ing.port.StrVal) || (ing.port.Type == intstr.Int && port.Port == ing.port.IntVal)) {
+					containerPort = targetPort(workload, port)
+				}
+			}
+		}
+		if info == nil || containerPort == 0 {
+			k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the backend %s:%s of Ingress/%s not found, it is ignored", ing.service, ing.port.String(), ing.ingress),
+				SolveAdvice("modify_kubernetes", "please add the http rule in gateway after the components are created")))
+			continue
+		}
+		if ing.tls {
+			k.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("the certificate of host %s is not imported", ing.host),
+				SolveAdvice("add_certificate", fmt.Sprintf("please upload the certificate of %s in gateway", ing.host))))
+		}
+		p := ing.path
+		if p == "" {
+			p = "/"
+		}
+		info.HTTPRules = append(info.HTTPRules, types.HTTPRule{Domain: ing.host, Path: p, ContainerPort: containerPort, SSL: ing.tls})
+	}
+}
+
+//parseDependencies the component depends on the other component if the env references its service
+func (k *KubernetesParse) parseDependencies() {
+	owners := make(map[string]string)
+	for _, svc := range k.services {
+		if _, info := k.selectWorkload(svc); info != nil {
+			owners[svc.Name] = info.Name
+		}
+	}
+	for _, info := range k.infos {
+		depends := make(map[string]struct{})
+		for host, owner := range owners {
+			if owner == info.Name {
+				continue
+			}
+			reg := regexp.MustCompile(`(^|[/@])` + regexp.QuoteMeta(host) + `([:./]|$)`)
+			for _, env := range info.Envs {
+				if _, ok := depends[owner]; !ok && reg.MatchString(env.Value) {
+					depends[owner] = struct{}{}
+					info.DependServices = append(info.DependServices, owner)
+				}
+			}
+		}
+		sort.Strings(info.DependServices)
+	}
+}
+
+//GetServiceInfo return the components parsed from manifests
+func (k *KubernetesParse) GetServiceInfo() []ServiceInfo {
+	var infos []ServiceInfo
+	for _, info := range k.infos {
+		infos = append(infos, *info)
+	}
+	return infos
+}
+
+//GetImage there is no single image for kubernetes manifests
+func (k *KubernetesParse) GetImage() Image {
+	return Image{}
+}
@@ -0,0 +1,272 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goodrain/rainbond/builder/parser/types"
+)
+
+var testManifests = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  MODE: prod
+  app.conf: |
+    listen 80
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-secret
+data:
+  password: c2VjcmV0
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: web-data
+spec:
+  accessModes: ["ReadWriteMany"]
+  resources:
+    requests:
+      storage: 1500Mi
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels: {app: web}
+  template:
+    metadata:
+      labels: {app: web}
+    spec:
+      nodeSelector: {disk: ssd}
+      containers:
+        - name: web
+          image: nginx:1.19
+          args: ["--debug"]
+          ports:
+            - name: http
+              containerPort: 80
+          envFrom:
+            - configMapRef: {name: web-config}
+          env:
+            - name: DB_URL
+              value: mysql://db:3306/app
+            - name: DB_PASSWORD
+              valueFrom:
+                secretKeyRef: {name: db-secret, key: password}
+            - name: POD_IP
+              valueFrom:
+                fieldRef: {fieldPath: status.podIP}
+          resources:
+            limits:
+              memory: 256Mi
+          volumeMounts:
+            - name: config
+              mountPath: /etc/nginx/app.conf
+              subPath: app.conf
+            - name: data
+              mountPath: /data
+            - name: cache
+              mountPath: /cache
+      volumes:
+        - name: config
+          configMap: {name: web-config}
+        - name: data
+          persistentVolumeClaim: {claimName: web-data}
+        - name: cache
+          hostPath: {path: /tmp}
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  serviceName: db
+  selector:
+    matchLabels: {app: db}
+  template:
+    metadata:
+      labels: {app: db}
+    spec:
+      containers:
+        - name: mysql
+          image: mysql:5.7
+          volumeMounts:
+            - name: data
+              mountPath: /var/lib/mysql
+  volumeClaimTemplates:
+    - metadata:
+        name: data
+      spec:
+        accessModes: ["ReadWriteOnce"]
+        resources:
+          requests:
+            storage: 10Gi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector: {app: web}
+  ports:
+    - port: 8080
+      targetPort: http
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+spec:
+  selector: {app: db}
+  ports:
+    - port: 3306
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  tls:
+    - hosts: [www.example.com]
+  rules:
+    - host: www.example.com
+      http:
+        paths:
+          - path: /
+            pathType: Prefix
+            backend:
+              service:
+                name: web
+                port: {number: 8080}
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: legacy
+spec:
+  rules:
+    - host: api.example.com
+      http:
+        paths:
+          - backend:
+              serviceName: web
+              servicePort: 8080
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+`
+
+func TestKubernetesParse(t *testing.T) {
+	p := CreateKubernetesParse(testManifests, nil)
+	errs := p.Parse()
+	if errs.IsFatalError() {
+		t.Fatalf("unexpected fatal error %v", errs)
+	}
+	for _, expect := range []string{"Job/migrate", "nodeSelector", "POD_IP", "cache", "certificate"} {
+		var found bool
+		for _, err := range errs {
+			found = found || (strings.Contains(err.ErrorInfo, expect) && err.SolveAdvice != "")
+		}
+		if !found {
+			t.Errorf("expect parse error about %s with solve advice, got %v", expect, errs)
+		}
+	}
+	infos := p.GetServiceInfo()
+	if len(infos) != 2 {
+		t.Fatalf("expect 2 components, got %d", len(infos))
+	}
+	web, db := infos[0], infos[1]
+	if web.Name != "web" || web.Replicas != 3 || web.Memory != 256 || web.ServiceType != "stateless_multiple" || web.Image.String() != "docker.io/library/nginx:1.19" {
+		t.Errorf("unexpected web component %+v", web)
+	}
+	envs := make(map[string]string)
+	for _, env := range web.Envs {
+		envs[env.Name] = env.Value
+	}
+	if envs["MODE"] != "prod" || envs["DB_PASSWORD"] != "secret" || envs["DB_URL"] != "mysql://db:3306/app" {
+		t.Errorf("unexpected envs %v", web.Envs)
+	}
+	if len(web.DependServices) != 1 || web.DependServices[0] != "db" {
+		t.Errorf("web should depend on db, got %v", web.DependServices)
+	}
+	if len(web.Ports) != 1 || web.Ports[0].ContainerPort != 80 || web.Ports[0].Protocol != "http" {
+		t.Errorf("unexpected ports %v", web.Ports)
+	}
+	volumes := make(map[string]types.Volume)
+	for _, vol := range web.Volumes {
+		volumes[vol.VolumePath] = vol
+	}
+	if vol := volumes["/etc/nginx/app.conf"]; vol.VolumeType != "config-file" || vol.FileContent != "listen 80\n" {
+		t.Errorf("unexpected config file volume %+v", vol)
+	}
+	if vol := volumes["/data"]; vol.VolumeType != "share-file" || vol.VolumeCapacity != 2 || vol.AccessMode != "RWX" {
+		t.Errorf("unexpected data volume %+v", vol)
+	}
+	if len(web.HTTPRules) != 2 || web.HTTPRules[0] != (types.HTTPRule{Domain: "www.example.com", Path: "/", ContainerPort: 80, SSL: true}) ||
+		web.HTTPRules[1].Domain != "api.example.com" || web.HTTPRules[1].Path != "/" {
+		t.Errorf("unexpected http rules %v", web.HTTPRules)
+	}
+	if db.ServiceType != "state_singleton" || len(db.Ports) != 1 || db.Ports[0].Protocol != "mysql" {
+		t.Errorf("unexpected db component %+v", db)
+	}
+	if len(db.Volumes) != 1 || db.Volumes[0].VolumeType != "local" || db.Volumes[0].VolumeCapacity != 10 {
+		t.Errorf("unexpected db volumes %v", db.Volumes)
+	}
+}
+
+func TestKubernetesParseFatal(t *testing.T) {
+	for _, source := range []string{"", "kind: [", "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"} {
+		if errs := CreateKubernetesParse(source, nil).Parse(); !errs.IsFatalError() {
+			t.Errorf("expect fatal error for %q, got %v", source, errs)
+		}
+	}
+	for _, source := range []string{
+		`{"chart": "--post-renderer=/bin/sh"}`,
+		`{"chart": "/etc/charts/demo"}`,
+		`{"chart": "./demo"}`,
+		`{"chart": "https://charts.example.com/demo-1.0.0.tgz"}`,
+		`{"chart": "../demo", "repo_url": "https://charts.example.com"}`,
+		`{"chart": "demo", "repo_url": "file:///etc/charts"}`,
+	} {
+		if errs := CreateHelmChartParse(source, "", "", nil).Parse(); !errs.IsFatalError() {
+			t.Errorf("expect fatal error for invalid chart %s, got %v", source, errs)
+		}
+	}
+	for _, chart := range []HelmChartSource{
+		{Chart: "demo", RepoURL: "https://charts.example.com"},
+		{Chart: "oci://registry.example.com/charts/demo"},
+	} {
+		if err := validateChartRef(&chart); err != nil {
+			t.Errorf("expect chart %+v valid, got %v", chart, err)
+		}
+	}
+}
@@ -191,6 +191,9 @@ type ServiceInfo struct {
 	Name      string `json:"name,omitempty"`  // module name
 	Cname     string `json:"cname,omitempty"` // service cname
 	Packaging string `json:"packaging,omitempty"`
+	//For kubernetes workloads
+	Replicas  int              `json:"replicas,omitempty"`
+	HTTPRules []types.HTTPRule `json:"http_rules,omitempty"`
 }
 
 //GetServiceInfo GetServiceInfo
@@ -38,6 +38,20 @@ type Port struct {
 type Volume struct {
 	VolumePath string `json:"volume_path"`
 	VolumeType string `json:"volume_type"`
+	VolumeName string `json:"volume_name,omitempty"`
+	// the content of config file volume
+	FileContent string `json:"file_content,omitempty"`
+	// unit GB
+	VolumeCapacity int64  `json:"volume_capacity,omitempty"`
+	AccessMode     string `json:"access_mode,omitempty"`
+}
+
+//HTTPRule the http rule of gateway
+type HTTPRule struct {
+	Domain        string `json:"domain"`
+	Path          string `json:"path"`
+	ContainerPort int    `json:"container_port"`
+	SSL           bool   `json:"ssl"`
 }
 
 //Env env desc
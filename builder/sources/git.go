@@ -61,6 +61,8 @@ type CodeSourceInfo struct {
 	//避免项目之间冲突，代码缓存目录提高到租户
 	TenantID  string `json:"tenant_id"`
 	ServiceID string `json:"service_id"`
+	// CloneOptions depth, submodules, lfs, sparse paths and ref of git clone
+	CloneOptions CloneOptions `json:"clone_options"`
 }
 
 //GetCodeSourceDir get source storage directory
@@ -138,11 +140,11 @@ Loop:
 		Progress:          writer,
 		SingleBranch:      true,
 		Tags:              git.NoTags,
-		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
-		Depth:             1,
+		RecurseSubmodules: csi.CloneOptions.recurseSubmodules(),
+		Depth:             csi.CloneOptions.depth(),
 	}
-	if csi.Branch != "" {
-		opts.ReferenceName = getBranch(csi.Branch)
+	if branch := csi.CloneOptions.refBranch(csi.Branch); branch != "" {
+		opts.ReferenceName = getBranch(branch)
 	}
 	var rs *git.Repository
 	if ep.Protocol == "ssh" {
@@ -236,8 +238,12 @@ Loop:
 			}
 			return rs, err
 		}
+		return rs, err
 	}
-	return rs, err
+	if err := prepareWorktree(ctx, rs, csi, sourceDir, opts.Auth, logger); err != nil {
+		return rs, err
+	}
+	return rs, nil
 }
 func retryAuth(ep *transport.Endpoint, csi CodeSourceInfo) (transport.AuthMethod, error) {
 	switch ep.Protocol {
@@ -270,12 +276,13 @@ Loop:
 	writer := logger.GetWriter("progress", "debug")
 	writer.SetFormat(map[string]interface{}{"progress": "%s", "id": "Pull:"})
 	opts := &git.PullOptions{
-		Progress:     writer,
-		SingleBranch: true,
-		Depth:        1,
+		Progress:          writer,
+		SingleBranch:      true,
+		Depth:             csi.CloneOptions.depth(),
+		RecurseSubmodules: csi.CloneOptions.recurseSubmodules(),
 	}
-	if csi.Branch != "" {
-		opts.ReferenceName = getBranch(csi.Branch)
+	if branch := csi.CloneOptions.refBranch(csi.Branch); branch != "" {
+		opts.ReferenceName = getBranch(branch)
 	}
 	ep, err := transport.NewEndpoint(csi.RepositoryURL)
 	if err != nil {
@@ -370,16 +377,22 @@ Loop:
 			}
 			return rs, err
 		}
-		if err == git.NoErrAlreadyUpToDate {
-			return rs, nil
+		if err != git.NoErrAlreadyUpToDate {
+			return rs, err
 		}
 	}
-	return rs, err
+	if err := prepareWorktree(ctx, rs, csi, sourceDir, opts.Auth, logger); err != nil {
+		return rs, err
+	}
+	return rs, nil
 }
 
 //GitCloneOrPull if code exist in local,use git pull.
+//the pinned commit, eg. pushed by webhook, is checked out after pulling its branch into the local code.
+//the code is always cloned if a tag is pinned, or the work tree is pruned by sparse paths.
 func GitCloneOrPull(csi CodeSourceInfo, sourceDir string, logger event.Logger, timeout int) (*git.Repository, error) {
-	if ok, err := util.FileExists(path.Join(sourceDir, ".git")); err == nil && ok && !strings.HasPrefix(csi.Branch, "tag:") {
+	pinned := strings.HasPrefix(csi.CloneOptions.refBranch(csi.Branch), "tag:") || len(csi.CloneOptions.SparsePaths) > 0
+	if ok, err := util.FileExists(path.Join(sourceDir, ".git")); err == nil && ok && !pinned {
 		re, err := GitPull(csi, sourceDir, logger, timeout)
 		if err == nil && re != nil {
 			return re, nil
@@ -0,0 +1,287 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package sources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goodrain/rainbond/event"
+	"github.com/sirupsen/logrus"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+var commitReg = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+//CloneOptions the clone options of git code source
+type CloneOptions struct {
+	// Depth the clone depth, 0 means 1 and negative means the full history.
+	// the full history is cloned by default if a commit is pinned.
+	Depth int `json:"depth"`
+	// SkipSubmodules do not clone the submodules recursively
+	SkipSubmodules bool `json:"skip_submodules"`
+	// LFS fetch the git lfs objects, git and git-lfs are required in builder
+	LFS bool `json:"lfs"`
+	// SparsePaths only the paths are kept in the work tree
+	SparsePaths []string `json:"sparse_paths"`
+	// Ref the commit, tag(tag:v1.0 or refs/tags/v1.0) or branch to build, overrides the branch of code source
+	Ref string `json:"ref"`
+}
+
+//PinnedCommit return the commit if the ref is a commit
+func (c CloneOptions) PinnedCommit() string {
+	ref := strings.TrimPrefix(c.Ref, "commit:")
+	if commitReg.MatchString(ref) {
+		return ref
+	}
+	return ""
+}
+
+//refBranch return the branch(tag is prefixed with "tag:") of the ref,
+//the branch of code source is returned if the ref is empty or a commit.
+func (c CloneOptions) refBranch(branch string) string {
+	if c.Ref == "" || c.PinnedCommit() != "" {
+		return branch
+	}
+	switch {
+	case strings.HasPrefix(c.Ref, "refs/tags/"):
+		return "tag:" + strings.TrimPrefix(c.Ref, "refs/tags/")
+	case strings.HasPrefix(c.Ref, "refs/heads/"):
+		return strings.TrimPrefix(c.Ref, "refs/heads/")
+	}
+	return c.Ref
+}
+
+func (c CloneOptions) depth() int {
+	switch {
+	case c.Depth < 0:
+		return 0
+	case c.Depth > 0:
+		return c.Depth
+	case c.PinnedCommit() != "":
+		// the pinned commit may be not the head of branch
+		return 0
+	}
+	return 1
+}
+
+func (c CloneOptions) recurseSubmodules() git.SubmoduleRescursivity {
+	// submodules of the pinned commit are updated after checkout
+	if c.SkipSubmodules || c.PinnedCommit() != "" {
+		return git.NoRecurseSubmodules
+	}
+	return git.DefaultSubmoduleRecursionDepth
+}
+
+//prepareWorktree checkout the pinned commit, update submodules, fetch lfs objects and prune the sparse paths
+func prepareWorktree(ctx context.Context, rs *git.Repository, csi CodeSourceInfo, sourceDir string, auth transport.AuthMethod, logger event.Logger) error {
+	options := csi.CloneOptions
+	if commit := options.PinnedCommit(); commit != "" {
+		hash, err := resolveCommit(rs, commit)
+		if err != nil {
+			if logger != nil {
+				logger.Error(fmt.Sprintf("代码提交(%s)不存在。", commit), map[string]string{"step": "clone-code", "status": "failure"})
+			}
+			return err
+		}
+		tree, err := rs.Worktree()
+		if err != nil {
+			return err
+		}
+		if err := tree.Checkout(&git.CheckoutOptions{Hash: hash, Force: true}); err != nil {
+			return fmt.Errorf("checkout commit %s failure: %s", commit, err.Error())
+		}
+		if !options.SkipSubmodules {
+			submodules, err := tree.Submodules()
+			if err != nil {
+				return err
+			}
+			if err := submodules.UpdateContext(ctx, &git.SubmoduleUpdateOptions{
+				Init:              true,
+				RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+				Auth:              auth,
+			}); err != nil {
+				return fmt.Errorf("update submodules failure: %s", err.Error())
+			}
+		}
+	}
+	if options.LFS {
+		if err := gitLFSPull(ctx, csi, sourceDir, auth); err != nil {
+			if logger != nil {
+				logger.Error(fmt.Sprintf("拉取Git LFS文件失败。"), map[string]string{"step": "clone-code", "status": "failure"})
+			}
+			return err
+		}
+	}
+	if len(options.SparsePaths) > 0 {
+		if err := pruneSparsePaths(sourceDir, options.SparsePaths); err != nil {
+			return fmt.Errorf("prune sparse paths failure: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+//resolveCommit resolve the full or short commit hash
+func resolveCommit(rs *git.Repository, commit string) (plumbing.Hash, error) {
+	if len(commit) == 40 {
+		hash := plumbing.NewHash(commit)
+		if _, err := rs.CommitObject(hash); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("commit %s is not found: %s", commit, err.Error())
+		}
+		return hash, nil
+	}
+	iter, err := rs.Log(&git.LogOptions{})
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	defer iter.Close()
+	var hash plumbing.Hash
+	err = iter.ForEach(func(c *object.Commit) error {
+		if strings.HasPrefix(c.Hash.String(), commit) {
+			hash = c.Hash
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if hash.IsZero() {
+		return plumbing.ZeroHash, fmt.Errorf("commit %s is not found", commit)
+	}
+	return hash, nil
+}
+
+//askPassScript answers the username and password prompts of git from the env,
+//so that the credentials are not in the args or the url of the command
+const askPassScript = `#!/bin/sh
+case "$1" in
+Username*) echo "$RAINBOND_GIT_USERNAME" ;;
+*) echo "$RAINBOND_GIT_PASSWORD" ;;
+esac
+`
+
+//gitLFSPull fetch and checkout lfs objects by git-lfs, go-git does not support lfs
+func gitLFSPull(ctx context.Context, csi CodeSourceInfo, sourceDir string, auth transport.AuthMethod) error {
+	if _, err := exec.LookPath("git-lfs"); err != nil {
+		return fmt.Errorf("git-lfs is not installed in builder")
+	}
+	var askPass string
+	if _, ok := auth.(*githttp.BasicAuth); ok {
+		dir, err := ioutil.TempDir("", "askpass")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		askPass = filepath.Join(dir, "askpass.sh")
+		if err := ioutil.WriteFile(askPass, []byte(askPassScript), 0700); err != nil {
+			return err
+		}
+	}
+	cmd := exec.CommandContext(ctx, "git", "lfs", "pull")
+	cmd.Dir = sourceDir
+	cmd.Env = append(os.Environ(), lfsEnv(csi, auth, askPass)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		logrus.Errorf("git lfs pull in %s failure %s", sourceDir, stderr.String())
+		return fmt.Errorf("git lfs pull failure: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+//lfsEnv return the env passing the auth to git lfs
+func lfsEnv(csi CodeSourceInfo, auth transport.AuthMethod, askPass string) []string {
+	switch a := auth.(type) {
+	case *githttp.BasicAuth:
+		return []string{
+			"GIT_ASKPASS=" + askPass,
+			"GIT_TERMINAL_PROMPT=0",
+			"RAINBOND_GIT_USERNAME=" + a.Username,
+			"RAINBOND_GIT_PASSWORD=" + a.Password,
+		}
+	case *ssh.PublicKeys:
+		// the host keys are not verified, the same as the go-git ssh transport cloning the repository,
+		// otherwise lfs fails on the hosts the builder has never seen.
+		command := fmt.Sprintf("ssh -i '%s' -o BatchMode=yes -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null", GetPrivateFile(csi.TenantID))
+		return []string{"GIT_SSH_COMMAND=" + command}
+	}
+	return nil
+}
+
+//pruneSparsePaths remove the files which are not in the sparse paths from the work tree.
+//go-git does not support sparse checkout, so the objects are still fetched.
+func pruneSparsePaths(sourceDir string, sparsePaths []string) error {
+	var keeps []string
+	for _, p := range sparsePaths {
+		p = strings.Trim(path.Clean("/"+p), "/")
+		if p == "" {
+			// the root dir is included
+			return nil
+		}
+		keeps = append(keeps, p)
+	}
+	return filepath.Walk(sourceDir, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(sourceDir, name)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".git" {
+			return filepath.SkipDir
+		}
+		for _, keep := range keeps {
+			if rel == keep || strings.HasPrefix(rel, keep+"/") {
+				// in sparse path
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() && strings.HasPrefix(keep, rel+"/") {
+				// parent of sparse path
+				return nil
+			}
+		}
+		if err := os.RemoveAll(name); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
@@ -0,0 +1,183 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package sources
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/event"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+func TestCloneOptions(t *testing.T) {
+	tests := []struct {
+		options CloneOptions
+		branch  string
+		depth   int
+		pinned  string
+	}{
+		{CloneOptions{}, "master", 1, ""},
+		{CloneOptions{Depth: -1}, "master", 0, ""},
+		{CloneOptions{Ref: "refs/tags/v1.0"}, "tag:v1.0", 1, ""},
+		{CloneOptions{Ref: "refs/heads/dev", Depth: 10}, "dev", 10, ""},
+		{CloneOptions{Ref: "a1b2c3d"}, "master", 0, "a1b2c3d"},
+		{CloneOptions{Ref: "commit:a1b2c3d", Depth: 50}, "master", 50, "a1b2c3d"},
+	}
+	for _, tc := range tests {
+		if branch := tc.options.refBranch("master"); branch != tc.branch {
+			t.Errorf("ref %s: expect branch %s, got %s", tc.options.Ref, tc.branch, branch)
+		}
+		if depth := tc.options.depth(); depth != tc.depth {
+			t.Errorf("ref %s: expect depth %d, got %d", tc.options.Ref, tc.depth, depth)
+		}
+		if pinned := tc.options.PinnedCommit(); pinned != tc.pinned {
+			t.Errorf("ref %s: expect pinned commit %s, got %s", tc.options.Ref, tc.pinned, pinned)
+		}
+	}
+}
+
+func TestPrepareWorktree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-options")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	rs, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, _ := rs.Worktree()
+	commit := func(file, content string) string {
+		os.MkdirAll(path.Dir(path.Join(dir, file)), 0755)
+		if err := ioutil.WriteFile(path.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		tree.Add(file)
+		hash, err := tree.Commit("add "+file, &git.CommitOptions{Author: &object.Signature{Name: "test", When: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash.String()
+	}
+	commit("app/main.go", "package main")
+	pinned := commit("docs/readme.md", "readme")
+	commit("app/v2.go", "package main")
+	csi := CodeSourceInfo{CloneOptions: CloneOptions{Ref: pinned[:8], SkipSubmodules: true, SparsePaths: []string{"app"}}}
+	if err := prepareWorktree(context.Background(), rs, csi, dir, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	head, err := GetLastCommit(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash.String() != pinned {
+		t.Errorf("expect head %s, got %s", pinned, head.Hash.String())
+	}
+	if CheckFileExist(path.Join(dir, "app/v2.go")) {
+		t.Error("the file of later commit should not be checked out")
+	}
+	if !CheckFileExist(path.Join(dir, "app/main.go")) || !CheckFileExist(path.Join(dir, ".git")) {
+		t.Error("the sparse path should be kept")
+	}
+	if CheckFileExist(path.Join(dir, "docs")) {
+		t.Error("the path out of sparse paths should be removed")
+	}
+}
+
+func TestGitCloneOrPullPinnedCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-options")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	upstream, cache := path.Join(dir, "upstream.git"), path.Join(dir, "cache")
+	rs, err := git.PlainInit(upstream, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, _ := rs.Worktree()
+	commit := func(file string) string {
+		if err := ioutil.WriteFile(path.Join(upstream, file), []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+		tree.Add(file)
+		hash, err := tree.Commit("add "+file, &git.CommitOptions{Author: &object.Signature{Name: "test", When: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash.String()
+	}
+	commit("a.txt")
+	csi := CodeSourceInfo{RepositoryURL: upstream, Branch: "master", CloneOptions: CloneOptions{SkipSubmodules: true}}
+	if _, err := GitCloneOrPull(csi, cache, event.GetTestLogger(), 1); err != nil {
+		t.Fatal(err)
+	}
+	// the file is removed if the code is cloned again
+	if err := ioutil.WriteFile(path.Join(cache, ".git", "cached"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	pinned := commit("b.txt")
+	commit("c.txt")
+	csi.CloneOptions.Ref = pinned
+	cached, err := GitCloneOrPull(csi, cache, event.GetTestLogger(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := GetLastCommit(cached)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash.String() != pinned {
+		t.Errorf("expect head %s, got %s", pinned, head.Hash.String())
+	}
+	if !CheckFileExist(path.Join(cache, ".git", "cached")) {
+		t.Error("the pinned commit should be fetched into the cached code")
+	}
+	if !CheckFileExist(path.Join(cache, "b.txt")) || CheckFileExist(path.Join(cache, "c.txt")) {
+		t.Error("the pinned commit should be checked out")
+	}
+}
+
+func TestLFSEnv(t *testing.T) {
+	env := strings.Join(lfsEnv(CodeSourceInfo{}, &githttp.BasicAuth{Username: "user", Password: "pass"}, "/tmp/askpass.sh"), "\n")
+	for _, expect := range []string{"GIT_ASKPASS=/tmp/askpass.sh", "RAINBOND_GIT_USERNAME=user", "RAINBOND_GIT_PASSWORD=pass"} {
+		if !strings.Contains(env, expect) {
+			t.Errorf("expect env %s in %s", expect, env)
+		}
+	}
+	if strings.Contains(env, "Authorization") {
+		t.Errorf("the credentials should not be passed by header, got %s", env)
+	}
+	env = strings.Join(lfsEnv(CodeSourceInfo{TenantID: "tenant"}, &ssh.PublicKeys{}, ""), "\n")
+	if !strings.Contains(env, "StrictHostKeyChecking=no") {
+		t.Errorf("expect the host keys not verified the same as the clone, got %s", env)
+	}
+	if env := lfsEnv(CodeSourceInfo{}, nil, ""); len(env) != 0 {
+		t.Errorf("expect no env without auth, got %v", env)
+	}
+}
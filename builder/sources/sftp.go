@@ -20,6 +20,7 @@ package sources
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"os"
@@ -204,7 +205,9 @@ func (s *SFTPClient) PushFile(src, dst string, logger event.Logger) error {
 
 //DownloadFile DownloadFile
 func (s *SFTPClient) DownloadFile(src, dst string, logger event.Logger) error {
-	logger.Info(fmt.Sprintf("开始从FTP服务器下载代码包"), map[string]string{"step": "slug-share"})
+	if logger != nil {
+		logger.Info(fmt.Sprintf("开始从FTP服务器下载代码包"), map[string]string{"step": "slug-share"})
+	}
 
 	srcFile, err := s.sftpClient.OpenFile(src, 0644)
 	if err != nil {
@@ -243,6 +246,49 @@ func (s *SFTPClient) DownloadFile(src, dst string, logger event.Logger) error {
 	return CopyWithProgress(srcFile, dstFile, allSize, logger)
 }
 
+//UploadFile upload file without md5 checking,
+//the file is written to a temporary file and renamed, so dst is never partially written
+func (s *SFTPClient) UploadFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	if exist, _ := s.FileExist(filepath.Dir(dst)); !exist {
+		if err := s.MkdirAll(filepath.Dir(dst)); err != nil {
+			return err
+		}
+	}
+	tmp := dst + ".uploading"
+	dstFile, err := s.sftpClient.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		s.sftpClient.Remove(tmp)
+		return err
+	}
+	if err := dstFile.Close(); err != nil {
+		s.sftpClient.Remove(tmp)
+		return err
+	}
+	if err := s.sftpClient.PosixRename(tmp, dst); err != nil {
+		//the server does not support posix rename extension
+		s.sftpClient.Remove(dst)
+		return s.sftpClient.Rename(tmp, dst)
+	}
+	return nil
+}
+
+//RemoveFile remove file, it is not an error if the file does not exist
+func (s *SFTPClient) RemoveFile(path string) error {
+	if err := s.sftpClient.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 //FileExist 文件是否存在
 func (s *SFTPClient) FileExist(filepath string) (bool, error) {
 	if _, err := s.sftpClient.Stat(filepath); err != nil {
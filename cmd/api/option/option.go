@@ -21,47 +21,53 @@ package option
 import (
 	"fmt"
 
+	"github.com/goodrain/rainbond/util/terminal"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 )
 
 //Config config
 type Config struct {
-	DBType                 string
-	APIAddr                string
-	APIAddrSSL             string
-	DBConnectionInfo       string
-	EventLogServers        []string
-	NodeAPI                []string
-	BuilderAPI             []string
-	V1API                  string
-	MQAPI                  string
-	EtcdEndpoint           []string
-	EtcdCaFile             string
-	EtcdCertFile           string
-	EtcdKeyFile            string
-	APISSL                 bool
-	APICertFile            string
-	APIKeyFile             string
-	APICaFile              string
-	WebsocketSSL           bool
-	WebsocketCertFile      string
-	WebsocketKeyFile       string
-	WebsocketAddr          string
-	Opentsdb               string
-	RegionTag              string
-	LoggerFile             string
-	EnableFeature          []string
-	Debug                  bool
-	MinExtPort             int // minimum external port
-	LicensePath            string
-	LicSoPath              string
-	LogPath                string
-	KuberentesDashboardAPI string
-	KubeConfigPath         string
-	PrometheusEndpoint     string
-	RbdNamespace           string
-	ShowSQL                bool
+	DBType                   string
+	APIAddr                  string
+	APIAddrSSL               string
+	DBConnectionInfo         string
+	DBMasterKeyFile          string
+	DBPreviousMasterKeyFiles []string
+	DBKMSEndpoint            string
+	EventLogServers          []string
+	NodeAPI                  []string
+	BuilderAPI               []string
+	V1API                    string
+	MQAPI                    string
+	EtcdEndpoint             []string
+	EtcdCaFile               string
+	EtcdCertFile             string
+	EtcdKeyFile              string
+	APISSL                   bool
+	APICertFile              string
+	APIKeyFile               string
+	APICaFile                string
+	WebsocketSSL             bool
+	WebsocketCertFile        string
+	WebsocketKeyFile         string
+	WebsocketAddr            string
+	Opentsdb                 string
+	RegionTag                string
+	LoggerFile               string
+	EnableFeature            []string
+	Debug                    bool
+	MinExtPort               int // minimum external port
+	LicensePath              string
+	LicSoPath                string
+	LogPath                  string
+	KuberentesDashboardAPI   string
+	KubeConfigPath           string
+	PrometheusEndpoint       string
+	RbdNamespace             string
+	ShowSQL                  bool
+	TerminalTicketSecret     string
+	TerminalRecordDir        string
 }
 
 //APIServer  apiserver server
@@ -79,8 +85,11 @@ func NewAPIServer() *APIServer {
 //AddFlags config
 func (a *APIServer) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.LogLevel, "log-level", "info", "the api log level")
-	fs.StringVar(&a.DBType, "db-type", "mysql", "db type mysql or etcd")
-	fs.StringVar(&a.DBConnectionInfo, "mysql", "admin:admin@tcp(127.0.0.1:3306)/region", "mysql db connection info")
+	fs.StringVar(&a.DBType, "db-type", "mysql", "db type mysql, postgres, cockroachdb or sqlite3")
+	fs.StringVar(&a.DBConnectionInfo, "mysql", "admin:admin@tcp(127.0.0.1:3306)/region", "db connection info, the dsn for mysql or postgres, the file path for sqlite3")
+	fs.StringVar(&a.DBMasterKeyFile, "db-master-key-file", "", "the file of the master key encrypting the secrets in db at rest, such as env vars and certificate keys")
+	fs.StringSliceVar(&a.DBPreviousMasterKeyFiles, "db-previous-master-key-files", nil, "the files of the master keys before rotation, used to read the data keys not rewrapped yet")
+	fs.StringVar(&a.DBKMSEndpoint, "db-kms-endpoint", "", "the endpoint of the kms plugin keeping the master key, such as unix:///var/run/kms.sock")
 	fs.StringVar(&a.APIAddr, "api-addr", "127.0.0.1:8888", "the api server listen address")
 	fs.StringVar(&a.APIAddrSSL, "api-addr-ssl", "0.0.0.0:8443", "the api server listen address")
 	fs.StringVar(&a.WebsocketAddr, "ws-addr", "0.0.0.0:6060", "the websocket server listen address")
@@ -114,6 +123,8 @@ func (a *APIServer) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.KuberentesDashboardAPI, "k8s-dashboard-api", "kubernetes-dashboard.rbd-system:443", "The service DNS name of Kubernetes dashboard. Default to kubernetes-dashboard.kubernetes-dashboard")
 	fs.StringVar(&a.PrometheusEndpoint, "prom-api", "rbd-monitor:9999", "The service DNS name of Prometheus api. Default to rbd-monitor:9999")
 	fs.StringVar(&a.RbdNamespace, "rbd-namespace", "rbd-system", "rbd component namespace")
+	fs.StringVar(&a.TerminalTicketSecret, "terminal-ticket-secret", "", "the secret shared with webcli to sign terminal tickets")
+	fs.StringVar(&a.TerminalRecordDir, "terminal-record-dir", terminal.DefaultRecordDir, "the dir of terminal session records saved by webcli")
 	fs.BoolVar(&a.ShowSQL, "show-sql", false, "The trigger for showing sql.")
 }
 
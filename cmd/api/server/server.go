@@ -104,6 +104,12 @@ func Run(s *option.APIServer) error {
 		logrus.Errorf("init all handle error, %v", err)
 		return err
 	}
+	//trigger the scheduled group app backups
+	handler.GetAPPBackupHandler().StartScheduler(ctx)
+	//apply the resource recommendations of components in the low-traffic windows
+	handler.GetServiceManager().StartResourceRecommender(ctx)
+	//fail the orchestrations interrupted by the exit of the api running them
+	handler.GetBatchOperationHandler().StartOrchestrationSweeper(ctx)
 	//创建v2Router manager
 	if err := controller.CreateV2RouterManager(s.Config, cli); err != nil {
 		logrus.Errorf("create v2 route manager error, %v", err)
@@ -29,32 +29,35 @@ import (
 
 //Config config server
 type Config struct {
-	EtcdEndPoints        []string
-	EtcdCaFile           string
-	EtcdCertFile         string
-	EtcdKeyFile          string
-	EtcdTimeout          int
-	EtcdPrefix           string
-	ClusterName          string
-	MysqlConnectionInfo  string
-	DBType               string
-	PrometheusMetricPath string
-	EventLogServers      []string
-	KubeConfig           string
-	MaxTasks             int
-	APIPort              int
-	MQAPI                string
-	DockerEndpoint       string
-	HostIP               string
-	CleanUp              bool
-	Topic                string
-	LogPath              string
-	RbdNamespace         string
-	RbdRepoName          string
-	GRDataPVCName        string
-	CachePVCName         string
-	CacheMode            string
-	CachePath            string
+	EtcdEndPoints            []string
+	EtcdCaFile               string
+	EtcdCertFile             string
+	EtcdKeyFile              string
+	EtcdTimeout              int
+	EtcdPrefix               string
+	ClusterName              string
+	MysqlConnectionInfo      string
+	DBMasterKeyFile          string
+	DBPreviousMasterKeyFiles []string
+	DBKMSEndpoint            string
+	DBType                   string
+	PrometheusMetricPath     string
+	EventLogServers          []string
+	KubeConfig               string
+	MaxTasks                 int
+	APIPort                  int
+	MQAPI                    string
+	DockerEndpoint           string
+	HostIP                   string
+	CleanUp                  bool
+	Topic                    string
+	LogPath                  string
+	RbdNamespace             string
+	RbdRepoName              string
+	GRDataPVCName            string
+	CachePVCName             string
+	CacheMode                string
+	CachePath                string
 }
 
 //Builder  builder server
@@ -79,8 +82,11 @@ func (a *Builder) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&a.EtcdTimeout, "etcd-timeout", 5, "etcd http timeout seconds")
 	fs.StringVar(&a.EtcdPrefix, "etcd-prefix", "/store", "the etcd data save key prefix ")
 	fs.StringVar(&a.PrometheusMetricPath, "metric", "/metrics", "prometheus metrics path")
-	fs.StringVar(&a.DBType, "db-type", "mysql", "db type mysql or etcd")
-	fs.StringVar(&a.MysqlConnectionInfo, "mysql", "root:admin@tcp(127.0.0.1:3306)/region", "mysql db connection info")
+	fs.StringVar(&a.DBType, "db-type", "mysql", "db type mysql, postgres, cockroachdb or sqlite3")
+	fs.StringVar(&a.MysqlConnectionInfo, "mysql", "root:admin@tcp(127.0.0.1:3306)/region", "db connection info, the dsn for mysql or postgres, the file path for sqlite3")
+	fs.StringVar(&a.DBMasterKeyFile, "db-master-key-file", "", "the file of the master key encrypting the secrets in db at rest, such as env vars and certificate keys")
+	fs.StringSliceVar(&a.DBPreviousMasterKeyFiles, "db-previous-master-key-files", nil, "the files of the master keys before rotation, used to read the data keys not rewrapped yet")
+	fs.StringVar(&a.DBKMSEndpoint, "db-kms-endpoint", "", "the endpoint of the kms plugin keeping the master key, such as unix:///var/run/kms.sock")
 	fs.StringSliceVar(&a.EventLogServers, "event-servers", []string{"127.0.0.1:6366"}, "event log server address. simple lb")
 	fs.StringVar(&a.KubeConfig, "kube-config", "", "kubernetes api server config file")
 	fs.IntVar(&a.MaxTasks, "max-tasks", 50, "Maximum number of simultaneous build tasks")
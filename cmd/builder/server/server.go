@@ -48,10 +48,15 @@ func Run(s *option.Builder) error {
 	errChan := make(chan error)
 	//init mysql
 	dbconfig := config.Config{
-		DBType:              s.Config.DBType,
-		MysqlConnectionInfo: s.Config.MysqlConnectionInfo,
-		EtcdEndPoints:       s.Config.EtcdEndPoints,
-		EtcdTimeout:         s.Config.EtcdTimeout,
+		DBType:                 s.Config.DBType,
+		MysqlConnectionInfo:    s.Config.MysqlConnectionInfo,
+		EtcdEndPoints:          s.Config.EtcdEndPoints,
+		EtcdTimeout:            s.Config.EtcdTimeout,
+		MasterKeyFile:          s.Config.DBMasterKeyFile,
+		PreviousMasterKeyFiles: s.Config.DBPreviousMasterKeyFiles,
+		KMSEndpoint:            s.Config.DBKMSEndpoint,
+		// the migrations are applied by the api
+		SkipMigration: true,
 	}
 	if err := db.CreateManager(dbconfig); err != nil {
 		return err
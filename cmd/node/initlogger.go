@@ -19,6 +19,10 @@
 package main
 
 import (
+	_ "github.com/goodrain/rainbond/node/nodem/logger/journald"
+	_ "github.com/goodrain/rainbond/node/nodem/logger/jsonfile"
+	_ "github.com/goodrain/rainbond/node/nodem/logger/kafka"
 	_ "github.com/goodrain/rainbond/node/nodem/logger/streamlog"
+	_ "github.com/goodrain/rainbond/node/nodem/logger/syslog"
 	_ "github.com/goodrain/rainbond/node/nodem/logger/testlog"
 )
@@ -127,6 +127,12 @@ type Conf struct {
 	ImageRepositoryHost string
 	GatewayVIP          string
 	HostsFile           string
+
+	// MeshCACertFile and MeshCAKeyFile is the region ca which issues mesh workload certificates.
+	// If not set, a self signed ca stored in the secret of RbdNamespace is used.
+	MeshCACertFile  string
+	MeshCAKeyFile   string
+	MeshTrustDomain string
 }
 
 //StatsdConfig StatsdConfig
@@ -190,6 +196,9 @@ func (a *Conf) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.RbdNamespace, "rbd-ns", "rbd-system", "The namespace of rainbond applications.")
 	fs.StringVar(&a.ImageRepositoryHost, "image-repo-host", "goodrain.me", "The host of image repository")
 	fs.StringVar(&a.GatewayVIP, "gateway-vip", "", "The vip of gateway")
+	fs.StringVar(&a.MeshCACertFile, "mesh-ca-cert", "", "The ca cert file which issues the workload certificates of service mesh")
+	fs.StringVar(&a.MeshCAKeyFile, "mesh-ca-key", "", "The ca key file which issues the workload certificates of service mesh")
+	fs.StringVar(&a.MeshTrustDomain, "mesh-trust-domain", "cluster.local", "The trust domain of service mesh workload identity")
 	fs.StringVar(&a.HostsFile, "hostsfile", "/newetc/hosts", "/etc/hosts mapped path in the container. eg. /etc/hosts:/tmp/hosts. Do not set hostsfile to /etc/hosts")
 }
 
@@ -21,6 +21,7 @@ package option
 import (
 	"fmt"
 
+	"github.com/goodrain/rainbond/util/terminal"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 )
@@ -38,6 +39,10 @@ type Config struct {
 	SessionKey           string
 	PrometheusMetricPath string
 	K8SConfPath          string
+	TicketSecret         string
+	RecordDir            string
+	MaxUploadSize        int64
+	MaxDownloadSize      int64
 }
 
 //WebCliServer container webcli server
@@ -64,6 +69,10 @@ func (a *WebCliServer) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.K8SConfPath, "kube-conf", "", "absolute path to the kubeconfig file")
 	fs.IntVar(&a.Port, "port", 7171, "server listen port")
 	fs.StringVar(&a.PrometheusMetricPath, "metric", "/metrics", "prometheus metrics path")
+	fs.StringVar(&a.TicketSecret, "ticket-secret", "", "the secret shared with region api to verify terminal tickets")
+	fs.StringVar(&a.RecordDir, "record-dir", terminal.DefaultRecordDir, "the dir to save terminal session records, disable recording if empty")
+	fs.Int64Var(&a.MaxUploadSize, "max-upload-size", 100, "the max size(MB) of archive uploaded to container")
+	fs.Int64Var(&a.MaxDownloadSize, "max-download-size", 1024, "the max size(MB) of archive downloaded from container")
 }
 
 //SetLog 设置log
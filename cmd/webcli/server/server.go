@@ -40,6 +40,10 @@ func Run(s *option.WebCliServer) error {
 	option.Port = strconv.Itoa(s.Port)
 	option.SessionKey = s.SessionKey
 	option.K8SConfPath = s.K8SConfPath
+	option.TicketSecret = s.TicketSecret
+	option.RecordDir = s.RecordDir
+	option.MaxUploadSize = s.MaxUploadSize << 20
+	option.MaxDownloadSize = s.MaxDownloadSize << 20
 	ap, err := app.New(&option)
 	if err != nil {
 		return err
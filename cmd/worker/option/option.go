@@ -21,6 +21,7 @@ package option
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
@@ -29,31 +30,39 @@ import (
 
 //Config config server
 type Config struct {
-	EtcdEndPoints           []string
-	EtcdCaFile              string
-	EtcdCertFile            string
-	EtcdKeyFile             string
-	EtcdTimeout             int
-	EtcdPrefix              string
-	ClusterName             string
-	MysqlConnectionInfo     string
-	DBType                  string
-	PrometheusMetricPath    string
-	EventLogServers         []string
-	KubeConfig              string
-	KubeAPIQPS              int
-	KubeAPIBurst            int
-	MaxTasks                int
-	MQAPI                   string
-	NodeName                string
-	Listen                  string
-	HostIP                  string
-	ServerPort              int
-	KubeClient              kubernetes.Interface
-	LeaderElectionNamespace string
-	LeaderElectionIdentity  string
-	RBDNamespace            string
-	GrdataPVCName           string
+	EtcdEndPoints            []string
+	EtcdCaFile               string
+	EtcdCertFile             string
+	EtcdKeyFile              string
+	EtcdTimeout              int
+	EtcdPrefix               string
+	ClusterName              string
+	MysqlConnectionInfo      string
+	DBMasterKeyFile          string
+	DBPreviousMasterKeyFiles []string
+	DBKMSEndpoint            string
+	DBType                   string
+	PrometheusMetricPath     string
+	EventLogServers          []string
+	KubeConfig               string
+	KubeAPIQPS               int
+	KubeAPIBurst             int
+	MaxTasks                 int
+	MQAPI                    string
+	NodeName                 string
+	Listen                   string
+	HostIP                   string
+	ServerPort               int
+	KubeClient               kubernetes.Interface
+	LeaderElectionNamespace  string
+	LeaderElectionIdentity   string
+	RBDNamespace             string
+	GrdataPVCName            string
+	VolumeUsageThresholds    []int
+	VaultAddr                string
+	VaultToken               string
+	VaultNamespace           string
+	ConfigGroupSyncPeriod    time.Duration
 }
 
 //Worker  worker server
@@ -79,8 +88,11 @@ func (a *Worker) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.EtcdPrefix, "etcd-prefix", "/store", "the etcd data save key prefix ")
 	fs.StringVar(&a.PrometheusMetricPath, "metric", "/metrics", "prometheus metrics path")
 	fs.StringVar(&a.Listen, "listen", ":6369", "prometheus listen host and port")
-	fs.StringVar(&a.DBType, "db-type", "mysql", "db type mysql or etcd")
-	fs.StringVar(&a.MysqlConnectionInfo, "mysql", "root:admin@tcp(127.0.0.1:3306)/region", "mysql db connection info")
+	fs.StringVar(&a.DBType, "db-type", "mysql", "db type mysql, postgres, cockroachdb or sqlite3")
+	fs.StringVar(&a.MysqlConnectionInfo, "mysql", "root:admin@tcp(127.0.0.1:3306)/region", "db connection info, the dsn for mysql or postgres, the file path for sqlite3")
+	fs.StringVar(&a.DBMasterKeyFile, "db-master-key-file", "", "the file of the master key encrypting the secrets in db at rest, such as env vars and certificate keys")
+	fs.StringSliceVar(&a.DBPreviousMasterKeyFiles, "db-previous-master-key-files", nil, "the files of the master keys before rotation, used to read the data keys not rewrapped yet")
+	fs.StringVar(&a.DBKMSEndpoint, "db-kms-endpoint", "", "the endpoint of the kms plugin keeping the master key, such as unix:///var/run/kms.sock")
 	fs.StringSliceVar(&a.EventLogServers, "event-servers", []string{"127.0.0.1:6366"}, "event log server address. simple lb")
 	fs.StringVar(&a.KubeConfig, "kube-config", "", "kubernetes api server config file")
 	fs.IntVar(&a.KubeAPIQPS, "kube-api-qps", 50, "kube client qps")
@@ -95,6 +107,11 @@ func (a *Worker) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.LeaderElectionIdentity, "leader-election-identity", "", "Unique idenity of this attcher. Typically name of the pod where the attacher runs.")
 	fs.StringVar(&a.RBDNamespace, "rbd-system-namespace", "rbd-system", "rbd components kubernetes namespace")
 	fs.StringVar(&a.GrdataPVCName, "grdata-pvc-name", "rbd-cpt-grdata", "The name of grdata persistent volume claim")
+	fs.IntSliceVar(&a.VolumeUsageThresholds, "volume-usage-thresholds", []int{80, 95}, "The percentages of volume capacity at which warnings and events are emitted")
+	fs.StringVar(&a.VaultAddr, "vault-addr", os.Getenv("VAULT_ADDR"), "the address of vault server the config groups are read from, such as http://127.0.0.1:8200")
+	fs.StringVar(&a.VaultToken, "vault-token", os.Getenv("VAULT_TOKEN"), "the token reading the kv secrets of vault")
+	fs.StringVar(&a.VaultNamespace, "vault-namespace", os.Getenv("VAULT_NAMESPACE"), "the namespace of vault enterprise")
+	fs.DurationVar(&a.ConfigGroupSyncPeriod, "config-group-sync-period", time.Minute, "the default period the config groups of external sources are synced")
 }
 
 //SetLog 设置log
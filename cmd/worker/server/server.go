@@ -30,8 +30,10 @@ import (
 	"github.com/goodrain/rainbond/event"
 	etcdutil "github.com/goodrain/rainbond/util/etcd"
 	k8sutil "github.com/goodrain/rainbond/util/k8s"
+	"github.com/goodrain/rainbond/util/vault"
 	"github.com/goodrain/rainbond/worker/appm"
 	"github.com/goodrain/rainbond/worker/appm/controller"
+	"github.com/goodrain/rainbond/worker/appm/conversion"
 	"github.com/goodrain/rainbond/worker/appm/store"
 	"github.com/goodrain/rainbond/worker/discover"
 	"github.com/goodrain/rainbond/worker/gc"
@@ -47,16 +49,24 @@ import (
 func Run(s *option.Worker) error {
 	errChan := make(chan error, 2)
 	dbconfig := config.Config{
-		DBType:              s.Config.DBType,
-		MysqlConnectionInfo: s.Config.MysqlConnectionInfo,
-		EtcdEndPoints:       s.Config.EtcdEndPoints,
-		EtcdTimeout:         s.Config.EtcdTimeout,
+		DBType:                 s.Config.DBType,
+		MysqlConnectionInfo:    s.Config.MysqlConnectionInfo,
+		EtcdEndPoints:          s.Config.EtcdEndPoints,
+		EtcdTimeout:            s.Config.EtcdTimeout,
+		MasterKeyFile:          s.Config.DBMasterKeyFile,
+		PreviousMasterKeyFiles: s.Config.DBPreviousMasterKeyFiles,
+		KMSEndpoint:            s.Config.DBKMSEndpoint,
+		// the migrations are applied by the api
+		SkipMigration: true,
 	}
 	//step 1:db manager init ,event log client init
 	if err := db.CreateManager(dbconfig); err != nil {
 		return err
 	}
 	defer db.CloseManager()
+	if s.Config.VaultAddr != "" {
+		conversion.SetVaultClient(vault.NewClient(s.Config.VaultAddr, s.Config.VaultToken, s.Config.VaultNamespace))
+	}
 	etcdClientArgs := &etcdutil.ClientArgs{
 		Endpoints: s.Config.EtcdEndPoints,
 		CaFile:    s.Config.EtcdCaFile,
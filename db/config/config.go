@@ -28,4 +28,13 @@ type Config struct {
 	EtcdKeyFile         string
 	EtcdTimeout         int
 	ShowSQL             bool
+	//SkipMigration do not apply the pending migrations when the manager is created
+	SkipMigration bool
+	//MasterKeyFile the master key encrypting the secrets at rest, the secrets are stored in plain text
+	//if neither MasterKeyFile nor KMSEndpoint is set
+	MasterKeyFile string
+	//PreviousMasterKeyFiles the master keys before rotation
+	PreviousMasterKeyFiles []string
+	//KMSEndpoint the endpoint of the kms plugin keeping the master key
+	KMSEndpoint string
 }
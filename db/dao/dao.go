@@ -81,6 +81,7 @@ type AppConfigGroupDao interface {
 	Dao
 	GetConfigGroupByID(appID, configGroupName string) (*model.ApplicationConfigGroup, error)
 	ListByServiceID(sid string) ([]*model.ApplicationConfigGroup, error)
+	ListEnableBySourceType(sourceType string) ([]*model.ApplicationConfigGroup, error)
 	GetConfigGroupsByAppID(appID string, page, pageSize int) ([]*model.ApplicationConfigGroup, int64, error)
 	DeleteConfigGroup(appID, configGroupName string) error
 }
@@ -390,6 +391,7 @@ type EventDao interface {
 	GetLastASyncEvent(target, targetID string) (*model.ServiceEvent, error)
 	UnfinishedEvents(target, targetID string, optTypes ...string) ([]*model.ServiceEvent, error)
 	LatestFailurePodEvent(podName string) (*model.ServiceEvent, error)
+	CountByServiceIDAndOptType(serviceID, optType string, since time.Time) (int, error)
 }
 
 //VersionInfoDao VersionInfoDao
@@ -443,6 +445,16 @@ type AppBackupDao interface {
 	GetAppBackup(backupID string) (*model.AppBackup, error)
 	GetDeleteAppBackup(backupID string) (*model.AppBackup, error)
 	GetDeleteAppBackups() ([]*model.AppBackup, error)
+	GetAppBackupsBySchedule(scheduleID string) ([]*model.AppBackup, error)
+}
+
+//AppBackupScheduleDao group app backup schedule
+type AppBackupScheduleDao interface {
+	Dao
+	GetSchedule(scheduleID string) (*model.AppBackupSchedule, error)
+	ListSchedules(groupID string) ([]*model.AppBackupSchedule, error)
+	ListEnabledSchedules() ([]*model.AppBackupSchedule, error)
+	DeleteSchedule(scheduleID string) error
 }
 
 //ServiceSourceDao service source dao
@@ -537,6 +549,24 @@ type TenantServceAutoscalerRuleMetricsDao interface {
 	DeleteByRuleID(ruldID string) error
 }
 
+// TenantServiceScalingScheduleDao -
+type TenantServiceScalingScheduleDao interface {
+	Dao
+	GetByScheduleID(scheduleID string) (*model.TenantServiceScalingSchedule, error)
+	ListByServiceID(serviceID string) ([]*model.TenantServiceScalingSchedule, error)
+	ListEnableOnes() ([]*model.TenantServiceScalingSchedule, error)
+	DeleteByScheduleID(scheduleID string) error
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantServiceResourcePolicyDao -
+type TenantServiceResourcePolicyDao interface {
+	Dao
+	GetByServiceID(serviceID string) (*model.TenantServiceResourcePolicy, error)
+	ListAutoApplyOnes() ([]*model.TenantServiceResourcePolicy, error)
+	DeleteByServiceID(serviceID string) error
+}
+
 // TenantServiceScalingRecordsDao -
 type TenantServiceScalingRecordsDao interface {
 	Dao
@@ -545,6 +575,20 @@ type TenantServiceScalingRecordsDao interface {
 	CountByServiceID(serviceID string) (int, error)
 }
 
+// TenantServiceWebhookDao -
+type TenantServiceWebhookDao interface {
+	Dao
+	GetByServiceID(serviceID string) (*model.TenantServiceWebhook, error)
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantOrchestrationDao -
+type TenantOrchestrationDao interface {
+	Dao
+	GetByEventID(tenantID, eventID string) (*model.TenantOrchestration, error)
+	ListStale(before time.Time) ([]*model.TenantOrchestration, error)
+}
+
 // TenantServiceMonitorDao -
 type TenantServiceMonitorDao interface {
 	Dao
@@ -540,6 +540,19 @@ func (mr *MockAppConfigGroupDaoMockRecorder) ListByServiceID(sid interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByServiceID", reflect.TypeOf((*MockAppConfigGroupDao)(nil).ListByServiceID), sid)
 }
 
+// ListEnableBySourceType mocks base method
+func (m *MockAppConfigGroupDao) ListEnableBySourceType(sourceType string) ([]*model.ApplicationConfigGroup, error) {
+	ret := m.ctrl.Call(m, "ListEnableBySourceType", sourceType)
+	ret0, _ := ret[0].([]*model.ApplicationConfigGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEnableBySourceType indicates an expected call of ListEnableBySourceType
+func (mr *MockAppConfigGroupDaoMockRecorder) ListEnableBySourceType(sourceType interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEnableBySourceType", reflect.TypeOf((*MockAppConfigGroupDao)(nil).ListEnableBySourceType), sourceType)
+}
+
 // GetConfigGroupsByAppID mocks base method
 func (m *MockAppConfigGroupDao) GetConfigGroupsByAppID(appID string, page, pageSize int) ([]*model.ApplicationConfigGroup, int64, error) {
 	ret := m.ctrl.Call(m, "GetConfigGroupsByAppID", appID, page, pageSize)
@@ -1334,6 +1347,19 @@ func (mr *MockTenantServiceDeleteDaoMockRecorder) DeleteTenantServicesDelete(rec
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTenantServicesDelete", reflect.TypeOf((*MockTenantServiceDeleteDao)(nil).DeleteTenantServicesDelete), record)
 }
 
+// List mocks base method
+func (m *MockTenantServiceDeleteDao) List() ([]*model.TenantServicesDelete, error) {
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]*model.TenantServicesDelete)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockTenantServiceDeleteDaoMockRecorder) List() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTenantServiceDeleteDao)(nil).List))
+}
+
 // MockTenantServicesPortDao is a mock of TenantServicesPortDao interface
 type MockTenantServicesPortDao struct {
 	ctrl     *gomock.Controller
@@ -1551,6 +1577,18 @@ func (mr *MockTenantServicesPortDaoMockRecorder) ListByK8sServiceNames(serviceID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByK8sServiceNames", reflect.TypeOf((*MockTenantServicesPortDao)(nil).ListByK8sServiceNames), serviceIDs)
 }
 
+// CreateOrUpdatePortsInBatch mocks base method
+func (m *MockTenantServicesPortDao) CreateOrUpdatePortsInBatch(ports []model.TenantServicesPort) error {
+	ret := m.ctrl.Call(m, "CreateOrUpdatePortsInBatch", ports)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdatePortsInBatch indicates an expected call of CreateOrUpdatePortsInBatch
+func (mr *MockTenantServicesPortDaoMockRecorder) CreateOrUpdatePortsInBatch(ports interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdatePortsInBatch", reflect.TypeOf((*MockTenantServicesPortDao)(nil).CreateOrUpdatePortsInBatch), ports)
+}
+
 // MockTenantPluginDao is a mock of TenantPluginDao interface
 type MockTenantPluginDao struct {
 	ctrl     *gomock.Controller
@@ -2676,6 +2714,18 @@ func (mr *MockTenantServiceEnvVarDaoMockRecorder) DelByServiceIDAndScope(sid, sc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelByServiceIDAndScope", reflect.TypeOf((*MockTenantServiceEnvVarDao)(nil).DelByServiceIDAndScope), sid, scope)
 }
 
+// CreateOrUpdateEnvsInBatch mocks base method
+func (m *MockTenantServiceEnvVarDao) CreateOrUpdateEnvsInBatch(envs []model.TenantServiceEnvVar) error {
+	ret := m.ctrl.Call(m, "CreateOrUpdateEnvsInBatch", envs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdateEnvsInBatch indicates an expected call of CreateOrUpdateEnvsInBatch
+func (mr *MockTenantServiceEnvVarDaoMockRecorder) CreateOrUpdateEnvsInBatch(envs interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateEnvsInBatch", reflect.TypeOf((*MockTenantServiceEnvVarDao)(nil).CreateOrUpdateEnvsInBatch), envs)
+}
+
 // MockTenantServiceMountRelationDao is a mock of TenantServiceMountRelationDao interface
 type MockTenantServiceMountRelationDao struct {
 	ctrl     *gomock.Controller
@@ -3678,6 +3728,18 @@ func (mr *MockEventDaoMockRecorder) UpdateModel(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateModel", reflect.TypeOf((*MockEventDao)(nil).UpdateModel), arg0)
 }
 
+// CreateEventsInBatch mocks base method
+func (m *MockEventDao) CreateEventsInBatch(events []*model.ServiceEvent) error {
+	ret := m.ctrl.Call(m, "CreateEventsInBatch", events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEventsInBatch indicates an expected call of CreateEventsInBatch
+func (mr *MockEventDaoMockRecorder) CreateEventsInBatch(events interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEventsInBatch", reflect.TypeOf((*MockEventDao)(nil).CreateEventsInBatch), events)
+}
+
 // GetEventByEventID mocks base method
 func (m *MockEventDao) GetEventByEventID(eventID string) (*model.ServiceEvent, error) {
 	ret := m.ctrl.Call(m, "GetEventByEventID", eventID)
@@ -3814,6 +3876,19 @@ func (mr *MockEventDaoMockRecorder) LatestFailurePodEvent(podName interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestFailurePodEvent", reflect.TypeOf((*MockEventDao)(nil).LatestFailurePodEvent), podName)
 }
 
+// CountByServiceIDAndOptType mocks base method
+func (m *MockEventDao) CountByServiceIDAndOptType(serviceID, optType string, since time.Time) (int, error) {
+	ret := m.ctrl.Call(m, "CountByServiceIDAndOptType", serviceID, optType, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByServiceIDAndOptType indicates an expected call of CountByServiceIDAndOptType
+func (mr *MockEventDaoMockRecorder) CountByServiceIDAndOptType(serviceID, optType, since interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByServiceIDAndOptType", reflect.TypeOf((*MockEventDao)(nil).CountByServiceIDAndOptType), serviceID, optType, since)
+}
+
 // MockVersionInfoDao is a mock of VersionInfoDao interface
 type MockVersionInfoDao struct {
 	ctrl     *gomock.Controller
@@ -4013,6 +4088,19 @@ func (mr *MockVersionInfoDaoMockRecorder) SearchVersionInfo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchVersionInfo", reflect.TypeOf((*MockVersionInfoDao)(nil).SearchVersionInfo))
 }
 
+// ListByServiceIDStatus mocks base method
+func (m *MockVersionInfoDao) ListByServiceIDStatus(serviceID string, finalStatus *bool) ([]*model.VersionInfo, error) {
+	ret := m.ctrl.Call(m, "ListByServiceIDStatus", serviceID, finalStatus)
+	ret0, _ := ret[0].([]*model.VersionInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByServiceIDStatus indicates an expected call of ListByServiceIDStatus
+func (mr *MockVersionInfoDaoMockRecorder) ListByServiceIDStatus(serviceID, finalStatus interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByServiceIDStatus", reflect.TypeOf((*MockVersionInfoDao)(nil).ListByServiceIDStatus), serviceID, finalStatus)
+}
+
 // MockRegionUserInfoDao is a mock of RegionUserInfoDao interface
 type MockRegionUserInfoDao struct {
 	ctrl     *gomock.Controller
@@ -4393,6 +4481,117 @@ func (mr *MockAppBackupDaoMockRecorder) GetDeleteAppBackups() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleteAppBackups", reflect.TypeOf((*MockAppBackupDao)(nil).GetDeleteAppBackups))
 }
 
+// GetAppBackupsBySchedule mocks base method
+func (m *MockAppBackupDao) GetAppBackupsBySchedule(scheduleID string) ([]*model.AppBackup, error) {
+	ret := m.ctrl.Call(m, "GetAppBackupsBySchedule", scheduleID)
+	ret0, _ := ret[0].([]*model.AppBackup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppBackupsBySchedule indicates an expected call of GetAppBackupsBySchedule
+func (mr *MockAppBackupDaoMockRecorder) GetAppBackupsBySchedule(scheduleID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppBackupsBySchedule", reflect.TypeOf((*MockAppBackupDao)(nil).GetAppBackupsBySchedule), scheduleID)
+}
+
+// MockAppBackupScheduleDao is a mock of AppBackupScheduleDao interface
+type MockAppBackupScheduleDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockAppBackupScheduleDaoMockRecorder
+}
+
+// MockAppBackupScheduleDaoMockRecorder is the mock recorder for MockAppBackupScheduleDao
+type MockAppBackupScheduleDaoMockRecorder struct {
+	mock *MockAppBackupScheduleDao
+}
+
+// NewMockAppBackupScheduleDao creates a new mock instance
+func NewMockAppBackupScheduleDao(ctrl *gomock.Controller) *MockAppBackupScheduleDao {
+	mock := &MockAppBackupScheduleDao{ctrl: ctrl}
+	mock.recorder = &MockAppBackupScheduleDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAppBackupScheduleDao) EXPECT() *MockAppBackupScheduleDaoMockRecorder {
+	return m.recorder
+}
+
+// AddModel mocks base method
+func (m *MockAppBackupScheduleDao) AddModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "AddModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddModel indicates an expected call of AddModel
+func (mr *MockAppBackupScheduleDaoMockRecorder) AddModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddModel", reflect.TypeOf((*MockAppBackupScheduleDao)(nil).AddModel), arg0)
+}
+
+// UpdateModel mocks base method
+func (m *MockAppBackupScheduleDao) UpdateModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "UpdateModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateModel indicates an expected call of UpdateModel
+func (mr *MockAppBackupScheduleDaoMockRecorder) UpdateModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateModel", reflect.TypeOf((*MockAppBackupScheduleDao)(nil).UpdateModel), arg0)
+}
+
+// GetSchedule mocks base method
+func (m *MockAppBackupScheduleDao) GetSchedule(scheduleID string) (*model.AppBackupSchedule, error) {
+	ret := m.ctrl.Call(m, "GetSchedule", scheduleID)
+	ret0, _ := ret[0].(*model.AppBackupSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule
+func (mr *MockAppBackupScheduleDaoMockRecorder) GetSchedule(scheduleID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockAppBackupScheduleDao)(nil).GetSchedule), scheduleID)
+}
+
+// ListSchedules mocks base method
+func (m *MockAppBackupScheduleDao) ListSchedules(groupID string) ([]*model.AppBackupSchedule, error) {
+	ret := m.ctrl.Call(m, "ListSchedules", groupID)
+	ret0, _ := ret[0].([]*model.AppBackupSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules
+func (mr *MockAppBackupScheduleDaoMockRecorder) ListSchedules(groupID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockAppBackupScheduleDao)(nil).ListSchedules), groupID)
+}
+
+// ListEnabledSchedules mocks base method
+func (m *MockAppBackupScheduleDao) ListEnabledSchedules() ([]*model.AppBackupSchedule, error) {
+	ret := m.ctrl.Call(m, "ListEnabledSchedules")
+	ret0, _ := ret[0].([]*model.AppBackupSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEnabledSchedules indicates an expected call of ListEnabledSchedules
+func (mr *MockAppBackupScheduleDaoMockRecorder) ListEnabledSchedules() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEnabledSchedules", reflect.TypeOf((*MockAppBackupScheduleDao)(nil).ListEnabledSchedules))
+}
+
+// DeleteSchedule mocks base method
+func (m *MockAppBackupScheduleDao) DeleteSchedule(scheduleID string) error {
+	ret := m.ctrl.Call(m, "DeleteSchedule", scheduleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule
+func (mr *MockAppBackupScheduleDaoMockRecorder) DeleteSchedule(scheduleID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockAppBackupScheduleDao)(nil).DeleteSchedule), scheduleID)
+}
+
 // MockServiceSourceDao is a mock of ServiceSourceDao interface
 type MockServiceSourceDao struct {
 	ctrl     *gomock.Controller
@@ -4597,6 +4796,18 @@ func (mr *MockRuleExtensionDaoMockRecorder) DeleteRuleExtensionByRuleID(ruleID i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRuleExtensionByRuleID", reflect.TypeOf((*MockRuleExtensionDao)(nil).DeleteRuleExtensionByRuleID), ruleID)
 }
 
+// DeleteByRuleIDs mocks base method
+func (m *MockRuleExtensionDao) DeleteByRuleIDs(ruleIDs []string) error {
+	ret := m.ctrl.Call(m, "DeleteByRuleIDs", ruleIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByRuleIDs indicates an expected call of DeleteByRuleIDs
+func (mr *MockRuleExtensionDaoMockRecorder) DeleteByRuleIDs(ruleIDs interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByRuleIDs", reflect.TypeOf((*MockRuleExtensionDao)(nil).DeleteByRuleIDs), ruleIDs)
+}
+
 // MockHTTPRuleDao is a mock of HTTPRuleDao interface
 type MockHTTPRuleDao struct {
 	ctrl     *gomock.Controller
@@ -4720,6 +4931,19 @@ func (mr *MockHTTPRuleDaoMockRecorder) ListByServiceID(serviceID interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByServiceID", reflect.TypeOf((*MockHTTPRuleDao)(nil).ListByServiceID), serviceID)
 }
 
+// ListByComponentPort mocks base method
+func (m *MockHTTPRuleDao) ListByComponentPort(componentID string, port int) ([]*model.HTTPRule, error) {
+	ret := m.ctrl.Call(m, "ListByComponentPort", componentID, port)
+	ret0, _ := ret[0].([]*model.HTTPRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByComponentPort indicates an expected call of ListByComponentPort
+func (mr *MockHTTPRuleDaoMockRecorder) ListByComponentPort(componentID, port interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByComponentPort", reflect.TypeOf((*MockHTTPRuleDao)(nil).ListByComponentPort), componentID, port)
+}
+
 // ListByCertID mocks base method
 func (m *MockHTTPRuleDao) ListByCertID(certID string) ([]*model.HTTPRule, error) {
 	ret := m.ctrl.Call(m, "ListByCertID", certID)
@@ -4733,6 +4957,18 @@ func (mr *MockHTTPRuleDaoMockRecorder) ListByCertID(certID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCertID", reflect.TypeOf((*MockHTTPRuleDao)(nil).ListByCertID), certID)
 }
 
+// DeleteByComponentPort mocks base method
+func (m *MockHTTPRuleDao) DeleteByComponentPort(componentID string, port int) error {
+	ret := m.ctrl.Call(m, "DeleteByComponentPort", componentID, port)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByComponentPort indicates an expected call of DeleteByComponentPort
+func (mr *MockHTTPRuleDaoMockRecorder) DeleteByComponentPort(componentID, port interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByComponentPort", reflect.TypeOf((*MockHTTPRuleDao)(nil).DeleteByComponentPort), componentID, port)
+}
+
 // MockTCPRuleDao is a mock of TCPRuleDao interface
 type MockTCPRuleDao struct {
 	ctrl     *gomock.Controller
@@ -4869,6 +5105,18 @@ func (mr *MockTCPRuleDaoMockRecorder) GetUsedPortsByIP(ip interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsedPortsByIP", reflect.TypeOf((*MockTCPRuleDao)(nil).GetUsedPortsByIP), ip)
 }
 
+// DeleteByComponentPort mocks base method
+func (m *MockTCPRuleDao) DeleteByComponentPort(componentID string, port int) error {
+	ret := m.ctrl.Call(m, "DeleteByComponentPort", componentID, port)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByComponentPort indicates an expected call of DeleteByComponentPort
+func (mr *MockTCPRuleDaoMockRecorder) DeleteByComponentPort(componentID, port interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByComponentPort", reflect.TypeOf((*MockTCPRuleDao)(nil).DeleteByComponentPort), componentID, port)
+}
+
 // MockEndpointsDao is a mock of EndpointsDao interface
 type MockEndpointsDao struct {
 	ctrl     *gomock.Controller
@@ -5123,6 +5371,18 @@ func (mr *MockGwRuleConfigDaoMockRecorder) ListByRuleID(rid interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByRuleID", reflect.TypeOf((*MockGwRuleConfigDao)(nil).ListByRuleID), rid)
 }
 
+// DeleteByRuleIDs mocks base method
+func (m *MockGwRuleConfigDao) DeleteByRuleIDs(ruleIDs []string) error {
+	ret := m.ctrl.Call(m, "DeleteByRuleIDs", ruleIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByRuleIDs indicates an expected call of DeleteByRuleIDs
+func (mr *MockGwRuleConfigDaoMockRecorder) DeleteByRuleIDs(ruleIDs interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByRuleIDs", reflect.TypeOf((*MockGwRuleConfigDao)(nil).DeleteByRuleIDs), ruleIDs)
+}
+
 // MockTenantServceAutoscalerRulesDao is a mock of TenantServceAutoscalerRulesDao interface
 type MockTenantServceAutoscalerRulesDao struct {
 	ctrl     *gomock.Controller
@@ -5293,6 +5553,201 @@ func (mr *MockTenantServceAutoscalerRuleMetricsDaoMockRecorder) DeleteByRuleID(r
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByRuleID", reflect.TypeOf((*MockTenantServceAutoscalerRuleMetricsDao)(nil).DeleteByRuleID), ruldID)
 }
 
+// MockTenantServiceScalingScheduleDao is a mock of TenantServiceScalingScheduleDao interface
+type MockTenantServiceScalingScheduleDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockTenantServiceScalingScheduleDaoMockRecorder
+}
+
+// MockTenantServiceScalingScheduleDaoMockRecorder is the mock recorder for MockTenantServiceScalingScheduleDao
+type MockTenantServiceScalingScheduleDaoMockRecorder struct {
+	mock *MockTenantServiceScalingScheduleDao
+}
+
+// NewMockTenantServiceScalingScheduleDao creates a new mock instance
+func NewMockTenantServiceScalingScheduleDao(ctrl *gomock.Controller) *MockTenantServiceScalingScheduleDao {
+	mock := &MockTenantServiceScalingScheduleDao{ctrl: ctrl}
+	mock.recorder = &MockTenantServiceScalingScheduleDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTenantServiceScalingScheduleDao) EXPECT() *MockTenantServiceScalingScheduleDaoMockRecorder {
+	return m.recorder
+}
+
+// AddModel mocks base method
+func (m *MockTenantServiceScalingScheduleDao) AddModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "AddModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddModel indicates an expected call of AddModel
+func (mr *MockTenantServiceScalingScheduleDaoMockRecorder) AddModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddModel", reflect.TypeOf((*MockTenantServiceScalingScheduleDao)(nil).AddModel), arg0)
+}
+
+// UpdateModel mocks base method
+func (m *MockTenantServiceScalingScheduleDao) UpdateModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "UpdateModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateModel indicates an expected call of UpdateModel
+func (mr *MockTenantServiceScalingScheduleDaoMockRecorder) UpdateModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateModel", reflect.TypeOf((*MockTenantServiceScalingScheduleDao)(nil).UpdateModel), arg0)
+}
+
+// GetByScheduleID mocks base method
+func (m *MockTenantServiceScalingScheduleDao) GetByScheduleID(scheduleID string) (*model.TenantServiceScalingSchedule, error) {
+	ret := m.ctrl.Call(m, "GetByScheduleID", scheduleID)
+	ret0, _ := ret[0].(*model.TenantServiceScalingSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByScheduleID indicates an expected call of GetByScheduleID
+func (mr *MockTenantServiceScalingScheduleDaoMockRecorder) GetByScheduleID(scheduleID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByScheduleID", reflect.TypeOf((*MockTenantServiceScalingScheduleDao)(nil).GetByScheduleID), scheduleID)
+}
+
+// ListByServiceID mocks base method
+func (m *MockTenantServiceScalingScheduleDao) ListByServiceID(serviceID string) ([]*model.TenantServiceScalingSchedule, error) {
+	ret := m.ctrl.Call(m, "ListByServiceID", serviceID)
+	ret0, _ := ret[0].([]*model.TenantServiceScalingSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByServiceID indicates an expected call of ListByServiceID
+func (mr *MockTenantServiceScalingScheduleDaoMockRecorder) ListByServiceID(serviceID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByServiceID", reflect.TypeOf((*MockTenantServiceScalingScheduleDao)(nil).ListByServiceID), serviceID)
+}
+
+// ListEnableOnes mocks base method
+func (m *MockTenantServiceScalingScheduleDao) ListEnableOnes() ([]*model.TenantServiceScalingSchedule, error) {
+	ret := m.ctrl.Call(m, "ListEnableOnes")
+	ret0, _ := ret[0].([]*model.TenantServiceScalingSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEnableOnes indicates an expected call of ListEnableOnes
+func (mr *MockTenantServiceScalingScheduleDaoMockRecorder) ListEnableOnes() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEnableOnes", reflect.TypeOf((*MockTenantServiceScalingScheduleDao)(nil).ListEnableOnes))
+}
+
+// DeleteByScheduleID mocks base method
+func (m *MockTenantServiceScalingScheduleDao) DeleteByScheduleID(scheduleID string) error {
+	ret := m.ctrl.Call(m, "DeleteByScheduleID", scheduleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByScheduleID indicates an expected call of DeleteByScheduleID
+func (mr *MockTenantServiceScalingScheduleDaoMockRecorder) DeleteByScheduleID(scheduleID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByScheduleID", reflect.TypeOf((*MockTenantServiceScalingScheduleDao)(nil).DeleteByScheduleID), scheduleID)
+}
+
+// DeleteByServiceID mocks base method
+func (m *MockTenantServiceScalingScheduleDao) DeleteByServiceID(serviceID string) error {
+	ret := m.ctrl.Call(m, "DeleteByServiceID", serviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByServiceID indicates an expected call of DeleteByServiceID
+func (mr *MockTenantServiceScalingScheduleDaoMockRecorder) DeleteByServiceID(serviceID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByServiceID", reflect.TypeOf((*MockTenantServiceScalingScheduleDao)(nil).DeleteByServiceID), serviceID)
+}
+
+// MockTenantServiceResourcePolicyDao is a mock of TenantServiceResourcePolicyDao interface
+type MockTenantServiceResourcePolicyDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockTenantServiceResourcePolicyDaoMockRecorder
+}
+
+// MockTenantServiceResourcePolicyDaoMockRecorder is the mock recorder for MockTenantServiceResourcePolicyDao
+type MockTenantServiceResourcePolicyDaoMockRecorder struct {
+	mock *MockTenantServiceResourcePolicyDao
+}
+
+// NewMockTenantServiceResourcePolicyDao creates a new mock instance
+func NewMockTenantServiceResourcePolicyDao(ctrl *gomock.Controller) *MockTenantServiceResourcePolicyDao {
+	mock := &MockTenantServiceResourcePolicyDao{ctrl: ctrl}
+	mock.recorder = &MockTenantServiceResourcePolicyDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTenantServiceResourcePolicyDao) EXPECT() *MockTenantServiceResourcePolicyDaoMockRecorder {
+	return m.recorder
+}
+
+// AddModel mocks base method
+func (m *MockTenantServiceResourcePolicyDao) AddModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "AddModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddModel indicates an expected call of AddModel
+func (mr *MockTenantServiceResourcePolicyDaoMockRecorder) AddModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddModel", reflect.TypeOf((*MockTenantServiceResourcePolicyDao)(nil).AddModel), arg0)
+}
+
+// UpdateModel mocks base method
+func (m *MockTenantServiceResourcePolicyDao) UpdateModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "UpdateModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateModel indicates an expected call of UpdateModel
+func (mr *MockTenantServiceResourcePolicyDaoMockRecorder) UpdateModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateModel", reflect.TypeOf((*MockTenantServiceResourcePolicyDao)(nil).UpdateModel), arg0)
+}
+
+// GetByServiceID mocks base method
+func (m *MockTenantServiceResourcePolicyDao) GetByServiceID(serviceID string) (*model.TenantServiceResourcePolicy, error) {
+	ret := m.ctrl.Call(m, "GetByServiceID", serviceID)
+	ret0, _ := ret[0].(*model.TenantServiceResourcePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByServiceID indicates an expected call of GetByServiceID
+func (mr *MockTenantServiceResourcePolicyDaoMockRecorder) GetByServiceID(serviceID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByServiceID", reflect.TypeOf((*MockTenantServiceResourcePolicyDao)(nil).GetByServiceID), serviceID)
+}
+
+// ListAutoApplyOnes mocks base method
+func (m *MockTenantServiceResourcePolicyDao) ListAutoApplyOnes() ([]*model.TenantServiceResourcePolicy, error) {
+	ret := m.ctrl.Call(m, "ListAutoApplyOnes")
+	ret0, _ := ret[0].([]*model.TenantServiceResourcePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAutoApplyOnes indicates an expected call of ListAutoApplyOnes
+func (mr *MockTenantServiceResourcePolicyDaoMockRecorder) ListAutoApplyOnes() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAutoApplyOnes", reflect.TypeOf((*MockTenantServiceResourcePolicyDao)(nil).ListAutoApplyOnes))
+}
+
+// DeleteByServiceID mocks base method
+func (m *MockTenantServiceResourcePolicyDao) DeleteByServiceID(serviceID string) error {
+	ret := m.ctrl.Call(m, "DeleteByServiceID", serviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByServiceID indicates an expected call of DeleteByServiceID
+func (mr *MockTenantServiceResourcePolicyDaoMockRecorder) DeleteByServiceID(serviceID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByServiceID", reflect.TypeOf((*MockTenantServiceResourcePolicyDao)(nil).DeleteByServiceID), serviceID)
+}
+
 // MockTenantServiceScalingRecordsDao is a mock of TenantServiceScalingRecordsDao interface
 type MockTenantServiceScalingRecordsDao struct {
 	ctrl     *gomock.Controller
@@ -5378,6 +5833,151 @@ func (mr *MockTenantServiceScalingRecordsDaoMockRecorder) CountByServiceID(servi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByServiceID", reflect.TypeOf((*MockTenantServiceScalingRecordsDao)(nil).CountByServiceID), serviceID)
 }
 
+// MockTenantServiceWebhookDao is a mock of TenantServiceWebhookDao interface
+type MockTenantServiceWebhookDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockTenantServiceWebhookDaoMockRecorder
+}
+
+// MockTenantServiceWebhookDaoMockRecorder is the mock recorder for MockTenantServiceWebhookDao
+type MockTenantServiceWebhookDaoMockRecorder struct {
+	mock *MockTenantServiceWebhookDao
+}
+
+// NewMockTenantServiceWebhookDao creates a new mock instance
+func NewMockTenantServiceWebhookDao(ctrl *gomock.Controller) *MockTenantServiceWebhookDao {
+	mock := &MockTenantServiceWebhookDao{ctrl: ctrl}
+	mock.recorder = &MockTenantServiceWebhookDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTenantServiceWebhookDao) EXPECT() *MockTenantServiceWebhookDaoMockRecorder {
+	return m.recorder
+}
+
+// AddModel mocks base method
+func (m *MockTenantServiceWebhookDao) AddModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "AddModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddModel indicates an expected call of AddModel
+func (mr *MockTenantServiceWebhookDaoMockRecorder) AddModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddModel", reflect.TypeOf((*MockTenantServiceWebhookDao)(nil).AddModel), arg0)
+}
+
+// UpdateModel mocks base method
+func (m *MockTenantServiceWebhookDao) UpdateModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "UpdateModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateModel indicates an expected call of UpdateModel
+func (mr *MockTenantServiceWebhookDaoMockRecorder) UpdateModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateModel", reflect.TypeOf((*MockTenantServiceWebhookDao)(nil).UpdateModel), arg0)
+}
+
+// GetByServiceID mocks base method
+func (m *MockTenantServiceWebhookDao) GetByServiceID(serviceID string) (*model.TenantServiceWebhook, error) {
+	ret := m.ctrl.Call(m, "GetByServiceID", serviceID)
+	ret0, _ := ret[0].(*model.TenantServiceWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByServiceID indicates an expected call of GetByServiceID
+func (mr *MockTenantServiceWebhookDaoMockRecorder) GetByServiceID(serviceID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByServiceID", reflect.TypeOf((*MockTenantServiceWebhookDao)(nil).GetByServiceID), serviceID)
+}
+
+// DeleteByServiceID mocks base method
+func (m *MockTenantServiceWebhookDao) DeleteByServiceID(serviceID string) error {
+	ret := m.ctrl.Call(m, "DeleteByServiceID", serviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByServiceID indicates an expected call of DeleteByServiceID
+func (mr *MockTenantServiceWebhookDaoMockRecorder) DeleteByServiceID(serviceID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByServiceID", reflect.TypeOf((*MockTenantServiceWebhookDao)(nil).DeleteByServiceID), serviceID)
+}
+
+// MockTenantOrchestrationDao is a mock of TenantOrchestrationDao interface
+type MockTenantOrchestrationDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockTenantOrchestrationDaoMockRecorder
+}
+
+// MockTenantOrchestrationDaoMockRecorder is the mock recorder for MockTenantOrchestrationDao
+type MockTenantOrchestrationDaoMockRecorder struct {
+	mock *MockTenantOrchestrationDao
+}
+
+// NewMockTenantOrchestrationDao creates a new mock instance
+func NewMockTenantOrchestrationDao(ctrl *gomock.Controller) *MockTenantOrchestrationDao {
+	mock := &MockTenantOrchestrationDao{ctrl: ctrl}
+	mock.recorder = &MockTenantOrchestrationDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTenantOrchestrationDao) EXPECT() *MockTenantOrchestrationDaoMockRecorder {
+	return m.recorder
+}
+
+// AddModel mocks base method
+func (m *MockTenantOrchestrationDao) AddModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "AddModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddModel indicates an expected call of AddModel
+func (mr *MockTenantOrchestrationDaoMockRecorder) AddModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddModel", reflect.TypeOf((*MockTenantOrchestrationDao)(nil).AddModel), arg0)
+}
+
+// UpdateModel mocks base method
+func (m *MockTenantOrchestrationDao) UpdateModel(arg0 model.Interface) error {
+	ret := m.ctrl.Call(m, "UpdateModel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateModel indicates an expected call of UpdateModel
+func (mr *MockTenantOrchestrationDaoMockRecorder) UpdateModel(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateModel", reflect.TypeOf((*MockTenantOrchestrationDao)(nil).UpdateModel), arg0)
+}
+
+// GetByEventID mocks base method
+func (m *MockTenantOrchestrationDao) GetByEventID(tenantID, eventID string) (*model.TenantOrchestration, error) {
+	ret := m.ctrl.Call(m, "GetByEventID", tenantID, eventID)
+	ret0, _ := ret[0].(*model.TenantOrchestration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEventID indicates an expected call of GetByEventID
+func (mr *MockTenantOrchestrationDaoMockRecorder) GetByEventID(tenantID, eventID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEventID", reflect.TypeOf((*MockTenantOrchestrationDao)(nil).GetByEventID), tenantID, eventID)
+}
+
+// ListStale mocks base method
+func (m *MockTenantOrchestrationDao) ListStale(before time.Time) ([]*model.TenantOrchestration, error) {
+	ret := m.ctrl.Call(m, "ListStale", before)
+	ret0, _ := ret[0].([]*model.TenantOrchestration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStale indicates an expected call of ListStale
+func (mr *MockTenantOrchestrationDaoMockRecorder) ListStale(before interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStale", reflect.TypeOf((*MockTenantOrchestrationDao)(nil).ListStale), before)
+}
+
 // MockTenantServiceMonitorDao is a mock of TenantServiceMonitorDao interface
 type MockTenantServiceMonitorDao struct {
 	ctrl     *gomock.Controller
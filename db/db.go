@@ -105,6 +105,7 @@ type Manager interface {
 	NotificationEventDao() dao.NotificationEventDao
 	AppBackupDao() dao.AppBackupDao
 	AppBackupDaoTransactions(db *gorm.DB) dao.AppBackupDao
+	AppBackupScheduleDao() dao.AppBackupScheduleDao
 	ServiceSourceDao() dao.ServiceSourceDao
 
 	// gateway
@@ -131,6 +132,16 @@ type Manager interface {
 	TenantServceAutoscalerRuleMetricsDaoTransactions(db *gorm.DB) dao.TenantServceAutoscalerRuleMetricsDao
 	TenantServiceScalingRecordsDao() dao.TenantServiceScalingRecordsDao
 	TenantServiceScalingRecordsDaoTransactions(db *gorm.DB) dao.TenantServiceScalingRecordsDao
+	TenantServiceScalingScheduleDao() dao.TenantServiceScalingScheduleDao
+	TenantServiceScalingScheduleDaoTransactions(db *gorm.DB) dao.TenantServiceScalingScheduleDao
+	TenantServiceResourcePolicyDao() dao.TenantServiceResourcePolicyDao
+	TenantServiceResourcePolicyDaoTransactions(db *gorm.DB) dao.TenantServiceResourcePolicyDao
+
+	TenantServiceWebhookDao() dao.TenantServiceWebhookDao
+	TenantServiceWebhookDaoTransactions(db *gorm.DB) dao.TenantServiceWebhookDao
+
+	TenantOrchestrationDao() dao.TenantOrchestrationDao
+	TenantOrchestrationDaoTransactions(db *gorm.DB) dao.TenantOrchestrationDao
 
 	TenantServiceMonitorDao() dao.TenantServiceMonitorDao
 	TenantServiceMonitorDaoTransactions(db *gorm.DB) dao.TenantServiceMonitorDao
@@ -144,6 +155,8 @@ func init() {
 	supportDrivers = map[string]struct{}{
 		"mysql":       {},
 		"cockroachdb": {},
+		"postgres":    {},
+		"sqlite3":     {},
 	}
 }
 
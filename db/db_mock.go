@@ -838,6 +838,18 @@ func (mr *MockManagerMockRecorder) AppBackupDaoTransactions(db interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppBackupDaoTransactions", reflect.TypeOf((*MockManager)(nil).AppBackupDaoTransactions), db)
 }
 
+// AppBackupScheduleDao mocks base method
+func (m *MockManager) AppBackupScheduleDao() dao.AppBackupScheduleDao {
+	ret := m.ctrl.Call(m, "AppBackupScheduleDao")
+	ret0, _ := ret[0].(dao.AppBackupScheduleDao)
+	return ret0
+}
+
+// AppBackupScheduleDao indicates an expected call of AppBackupScheduleDao
+func (mr *MockManagerMockRecorder) AppBackupScheduleDao() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppBackupScheduleDao", reflect.TypeOf((*MockManager)(nil).AppBackupScheduleDao))
+}
+
 // ServiceSourceDao mocks base method
 func (m *MockManager) ServiceSourceDao() dao.ServiceSourceDao {
 	ret := m.ctrl.Call(m, "ServiceSourceDao")
@@ -1090,6 +1102,102 @@ func (mr *MockManagerMockRecorder) TenantServiceScalingRecordsDaoTransactions(db
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceScalingRecordsDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServiceScalingRecordsDaoTransactions), db)
 }
 
+// TenantServiceScalingScheduleDao mocks base method
+func (m *MockManager) TenantServiceScalingScheduleDao() dao.TenantServiceScalingScheduleDao {
+	ret := m.ctrl.Call(m, "TenantServiceScalingScheduleDao")
+	ret0, _ := ret[0].(dao.TenantServiceScalingScheduleDao)
+	return ret0
+}
+
+// TenantServiceScalingScheduleDao indicates an expected call of TenantServiceScalingScheduleDao
+func (mr *MockManagerMockRecorder) TenantServiceScalingScheduleDao() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceScalingScheduleDao", reflect.TypeOf((*MockManager)(nil).TenantServiceScalingScheduleDao))
+}
+
+// TenantServiceScalingScheduleDaoTransactions mocks base method
+func (m *MockManager) TenantServiceScalingScheduleDaoTransactions(db *gorm.DB) dao.TenantServiceScalingScheduleDao {
+	ret := m.ctrl.Call(m, "TenantServiceScalingScheduleDaoTransactions", db)
+	ret0, _ := ret[0].(dao.TenantServiceScalingScheduleDao)
+	return ret0
+}
+
+// TenantServiceScalingScheduleDaoTransactions indicates an expected call of TenantServiceScalingScheduleDaoTransactions
+func (mr *MockManagerMockRecorder) TenantServiceScalingScheduleDaoTransactions(db interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceScalingScheduleDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServiceScalingScheduleDaoTransactions), db)
+}
+
+// TenantServiceResourcePolicyDao mocks base method
+func (m *MockManager) TenantServiceResourcePolicyDao() dao.TenantServiceResourcePolicyDao {
+	ret := m.ctrl.Call(m, "TenantServiceResourcePolicyDao")
+	ret0, _ := ret[0].(dao.TenantServiceResourcePolicyDao)
+	return ret0
+}
+
+// TenantServiceResourcePolicyDao indicates an expected call of TenantServiceResourcePolicyDao
+func (mr *MockManagerMockRecorder) TenantServiceResourcePolicyDao() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceResourcePolicyDao", reflect.TypeOf((*MockManager)(nil).TenantServiceResourcePolicyDao))
+}
+
+// TenantServiceResourcePolicyDaoTransactions mocks base method
+func (m *MockManager) TenantServiceResourcePolicyDaoTransactions(db *gorm.DB) dao.TenantServiceResourcePolicyDao {
+	ret := m.ctrl.Call(m, "TenantServiceResourcePolicyDaoTransactions", db)
+	ret0, _ := ret[0].(dao.TenantServiceResourcePolicyDao)
+	return ret0
+}
+
+// TenantServiceResourcePolicyDaoTransactions indicates an expected call of TenantServiceResourcePolicyDaoTransactions
+func (mr *MockManagerMockRecorder) TenantServiceResourcePolicyDaoTransactions(db interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceResourcePolicyDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServiceResourcePolicyDaoTransactions), db)
+}
+
+// TenantServiceWebhookDao mocks base method
+func (m *MockManager) TenantServiceWebhookDao() dao.TenantServiceWebhookDao {
+	ret := m.ctrl.Call(m, "TenantServiceWebhookDao")
+	ret0, _ := ret[0].(dao.TenantServiceWebhookDao)
+	return ret0
+}
+
+// TenantServiceWebhookDao indicates an expected call of TenantServiceWebhookDao
+func (mr *MockManagerMockRecorder) TenantServiceWebhookDao() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceWebhookDao", reflect.TypeOf((*MockManager)(nil).TenantServiceWebhookDao))
+}
+
+// TenantServiceWebhookDaoTransactions mocks base method
+func (m *MockManager) TenantServiceWebhookDaoTransactions(db *gorm.DB) dao.TenantServiceWebhookDao {
+	ret := m.ctrl.Call(m, "TenantServiceWebhookDaoTransactions", db)
+	ret0, _ := ret[0].(dao.TenantServiceWebhookDao)
+	return ret0
+}
+
+// TenantServiceWebhookDaoTransactions indicates an expected call of TenantServiceWebhookDaoTransactions
+func (mr *MockManagerMockRecorder) TenantServiceWebhookDaoTransactions(db interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceWebhookDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServiceWebhookDaoTransactions), db)
+}
+
+// TenantOrchestrationDao mocks base method
+func (m *MockManager) TenantOrchestrationDao() dao.TenantOrchestrationDao {
+	ret := m.ctrl.Call(m, "TenantOrchestrationDao")
+	ret0, _ := ret[0].(dao.TenantOrchestrationDao)
+	return ret0
+}
+
+// TenantOrchestrationDao indicates an expected call of TenantOrchestrationDao
+func (mr *MockManagerMockRecorder) TenantOrchestrationDao() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOrchestrationDao", reflect.TypeOf((*MockManager)(nil).TenantOrchestrationDao))
+}
+
+// TenantOrchestrationDaoTransactions mocks base method
+func (m *MockManager) TenantOrchestrationDaoTransactions(db *gorm.DB) dao.TenantOrchestrationDao {
+	ret := m.ctrl.Call(m, "TenantOrchestrationDaoTransactions", db)
+	ret0, _ := ret[0].(dao.TenantOrchestrationDao)
+	return ret0
+}
+
+// TenantOrchestrationDaoTransactions indicates an expected call of TenantOrchestrationDaoTransactions
+func (mr *MockManagerMockRecorder) TenantOrchestrationDaoTransactions(db interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOrchestrationDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantOrchestrationDaoTransactions), db)
+}
+
 // TenantServiceMonitorDao mocks base method
 func (m *MockManager) TenantServiceMonitorDao() dao.TenantServiceMonitorDao {
 	ret := m.ctrl.Call(m, "TenantServiceMonitorDao")
@@ -22,66 +22,123 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
 
 	dbconfig "github.com/goodrain/rainbond/db/config"
 	"github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/util"
 )
 
-func CreateTestManager() (Manager, error) {
-	dbname := "region"
-	rootpw := "rainbond"
+//testDBTypes returns the db types the db tests run against.
+//set RBD_TEST_DB_TYPES, e.g. "sqlite3,postgres", to run against a subset of them.
+func testDBTypes() []string {
+	types := os.Getenv("RBD_TEST_DB_TYPES")
+	if types == "" {
+		return []string{"sqlite3", "mysql", "postgres"}
+	}
+	return strings.Split(types, ",")
+}
+
+//runWithDB runs f once for every test db type, with a fresh db manager each time
+func runWithDB(t *testing.T, f func(t *testing.T)) {
+	for _, dbType := range testDBTypes() {
+		t.Run(dbType, func(t *testing.T) {
+			_, cleanup, err := CreateTestManager(dbType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cleanup()
+			f(t)
+		})
+	}
+}
+
+//CreateTestManager creates a db manager backed by an empty database of the given type.
+//mysql and postgres run in containers, sqlite3 uses a file in a temp dir.
+//The returned func closes the manager and removes the database.
+func CreateTestManager(dbType string) (Manager, func(), error) {
+	var connInfo string
+	var cleanup func()
+	switch dbType {
+	case "sqlite3":
+		dir, err := ioutil.TempDir("", "region-db")
+		if err != nil {
+			return nil, nil, err
+		}
+		connInfo = filepath.Join(dir, "region.db")
+		cleanup = func() { os.RemoveAll(dir) }
+	case "mysql":
+		container, endpoint, err := startDBContainer(testcontainers.ContainerRequest{
+			Image:        "mariadb",
+			ExposedPorts: []string{"3306/tcp"},
+			Env: map[string]string{
+				"MYSQL_ROOT_PASSWORD": "rainbond",
+				"MYSQL_DATABASE":      "region",
+			},
+			Cmd:        []string{"character-set-server=utf8mb4", "collation-server=utf8mb4_unicode_ci"},
+			WaitingFor: wait.ForListeningPort("3306/tcp"),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		connInfo = fmt.Sprintf("root:rainbond@tcp(%s)/region", endpoint)
+		cleanup = func() { container.Terminate(context.Background()) }
+	case "postgres":
+		container, endpoint, err := startDBContainer(testcontainers.ContainerRequest{
+			Image:        "postgres",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_PASSWORD": "rainbond",
+				"POSTGRES_DB":       "region",
+			},
+			WaitingFor: wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		connInfo = fmt.Sprintf("postgres://postgres:rainbond@%s/region?sslmode=disable", endpoint)
+		cleanup = func() { container.Terminate(context.Background()) }
+	default:
+		return nil, nil, fmt.Errorf("db type %s not supported", dbType)
+	}
 
+	if err := CreateManager(dbconfig.Config{
+		DBType:              dbType,
+		MysqlConnectionInfo: connInfo,
+	}); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("Connect info: %s; error creating db manager: %v", connInfo, err)
+	}
+	return GetManager(), func() {
+		CloseManager()
+		cleanup()
+	}, nil
+}
+
+//startDBContainer starts the db container and returns the host:port of its db port
+func startDBContainer(req testcontainers.ContainerRequest) (testcontainers.Container, string, error) {
 	ctx := context.Background()
-	req := testcontainers.ContainerRequest{
-		Image:        "mariadb",
-		ExposedPorts: []string{"3306/tcp"},
-		Env: map[string]string{
-			"MYSQL_ROOT_PASSWORD": rootpw,
-			"MYSQL_DATABASE":      dbname,
-		},
-		Cmd: []string{"character-set-server=utf8mb4", "collation-server=utf8mb4_unicode_ci"},
-	}
-	mariadb, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: req,
 		Started:          true,
 	})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	defer mariadb.Terminate(ctx)
-
-	host, err := mariadb.Host(ctx)
-	if err != nil {
-		return nil, err
-	}
-	port, err := mariadb.MappedPort(ctx, "3306")
+	endpoint, err := container.Endpoint(ctx, "")
 	if err != nil {
-		return nil, err
-	}
-
-	connInfo := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", "root",
-		rootpw, host, port.Int(), dbname)
-	tryTimes := 3
-	for {
-		if err := CreateManager(dbconfig.Config{
-			DBType:              "mysql",
-			MysqlConnectionInfo: connInfo,
-		}); err != nil {
-			if tryTimes == 0 {
-				return nil, fmt.Errorf("Connect info: %s; error creating db manager: %v", connInfo, err)
-			}
-			tryTimes = tryTimes - 1
-			time.Sleep(10 * time.Second)
-			continue
-		}
-		break
+		container.Terminate(ctx)
+		return nil, "", err
 	}
-	return GetManager(), nil
+	return container, endpoint, nil
 }
 
 func TestTenantDao(t *testing.T) {
@@ -0,0 +1,236 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package encryption
+
+import (
+	"reflect"
+
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	keyringKey = "rainbond:encryption_keyring"
+	//skipKey disables the encryption callbacks, used to read and write the values as they are stored
+	skipKey  = "rainbond:encryption_skip"
+	plainKey = "rainbond:encryption_plain"
+)
+
+//Register registers the callbacks encrypting the secret fields of the models on create and update,
+//and decrypting them after query. It returns the db carrying the keyring, the callbacks do nothing on dbs
+//without a keyring.
+func Register(db *gorm.DB, keyring *Keyring) *gorm.DB {
+	db.Callback().Create().Before("gorm:create").Register("rainbond:encrypt_secrets", encryptCallback)
+	db.Callback().Create().After("gorm:create").Register("rainbond:restore_secrets", restoreCallback)
+	db.Callback().Update().Before("gorm:update").Register("rainbond:encrypt_secrets", encryptCallback)
+	db.Callback().Update().After("gorm:update").Register("rainbond:restore_secrets", restoreCallback)
+	db.Callback().Query().After("gorm:query").Register("rainbond:decrypt_secrets", decryptCallback)
+	return db.Set(keyringKey, keyring)
+}
+
+//GetKeyring returns the keyring carried by the db, nil if the encryption is not enabled
+func GetKeyring(db *gorm.DB) *Keyring {
+	if v, ok := db.Get(keyringKey); ok {
+		return v.(*Keyring)
+	}
+	return nil
+}
+
+//Skip returns a db on which the values are read and written as they are stored
+func Skip(db *gorm.DB) *gorm.DB {
+	return db.Set(skipKey, true)
+}
+
+//Encrypt encrypts the secret fields of the model in place, for the updates the callbacks can not see,
+//such as updating with a map. The returned func restores the plain values.
+func Encrypt(db *gorm.DB, mo interface{}) (func(), error) {
+	keyring := GetKeyring(db)
+	if keyring == nil {
+		return func() {}, nil
+	}
+	secrets := secretFields(mo)
+	plains := make([]string, len(secrets))
+	pending := false
+	for i, s := range secrets {
+		plains[i] = *s
+		// empty values are left as they are, so that they can still be queried
+		if *s != "" && !IsEncrypted(*s) {
+			pending = true
+		}
+	}
+	restore := func() {
+		for i, s := range secrets {
+			*s = plains[i]
+		}
+	}
+	if !pending {
+		return restore, nil
+	}
+	tenantID, err := tenantOf(db, mo)
+	if err != nil {
+		return restore, err
+	}
+	for _, s := range secrets {
+		if *s == "" || IsEncrypted(*s) {
+			continue
+		}
+		enc, err := keyring.Encrypt(db, tenantID, *s)
+		if err != nil {
+			restore()
+			return restore, err
+		}
+		*s = enc
+	}
+	return restore, nil
+}
+
+//secretFields returns the fields to be encrypted of the model
+func secretFields(mo interface{}) []*string {
+	switch m := mo.(type) {
+	case *model.TenantServiceEnvVar:
+		return []*string{&m.AttrValue}
+	case *model.ConfigGroupItem:
+		return []*string{&m.ItemValue}
+	case *model.TenantPluginVersionEnv:
+		return []*string{&m.EnvValue}
+	case *model.Certificate:
+		return []*string{&m.PrivateKey}
+	case *model.TenantServiceWebhook:
+		return []*string{&m.Secret, &m.Password}
+	}
+	return nil
+}
+
+//tenantOf returns the tenant whose data key encrypts the model. Certificates are not owned by a tenant.
+func tenantOf(db *gorm.DB, mo interface{}) (string, error) {
+	switch m := mo.(type) {
+	case *model.TenantServiceEnvVar:
+		if m.TenantID != "" {
+			return m.TenantID, nil
+		}
+		return tenantOfService(db, m.ServiceID)
+	case *model.ConfigGroupItem:
+		var app model.Application
+		if err := Skip(db).Select("tenant_id").Where("app_id = ?", m.AppID).First(&app).Error; err != nil && err != gorm.ErrRecordNotFound {
+			return "", err
+		}
+		return app.TenantID, nil
+	case *model.TenantPluginVersionEnv:
+		return tenantOfService(db, m.ServiceID)
+	case *model.TenantServiceWebhook:
+		return m.TenantID, nil
+	}
+	return "", nil
+}
+
+func tenantOfService(db *gorm.DB, serviceID string) (string, error) {
+	var service model.TenantServices
+	if err := Skip(db).Select("tenant_id").Where("service_id = ?", serviceID).First(&service).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+	return service.TenantID, nil
+}
+
+//models returns the models in the value of the scope, which is a model, or a slice of models or pointers of models
+func models(value interface{}) []interface{} {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Elem().Kind() != reflect.Ptr && v.Elem().Kind() != reflect.Slice {
+			return []interface{}{v.Interface()}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+	var result []interface{}
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if item.Kind() == reflect.Ptr {
+			if !item.IsNil() {
+				result = append(result, item.Interface())
+			}
+		} else if item.CanAddr() {
+			result = append(result, item.Addr().Interface())
+		}
+	}
+	return result
+}
+
+func enabled(scope *gorm.Scope) (*Keyring, bool) {
+	if skip, ok := scope.Get(skipKey); ok && skip.(bool) {
+		return nil, false
+	}
+	keyring := GetKeyring(scope.DB())
+	return keyring, keyring != nil
+}
+
+func encryptCallback(scope *gorm.Scope) {
+	if scope.HasError() {
+		return
+	}
+	if _, ok := enabled(scope); !ok {
+		return
+	}
+	var restores []func()
+	for _, mo := range models(scope.Value) {
+		if secretFields(mo) == nil {
+			continue
+		}
+		restore, err := Encrypt(scope.NewDB(), mo)
+		restores = append(restores, restore)
+		if err != nil {
+			scope.Err(err)
+			break
+		}
+	}
+	scope.InstanceSet(plainKey, restores)
+}
+
+func restoreCallback(scope *gorm.Scope) {
+	if v, ok := scope.InstanceGet(plainKey); ok {
+		for _, restore := range v.([]func()) {
+			restore()
+		}
+	}
+}
+
+func decryptCallback(scope *gorm.Scope) {
+	if scope.HasError() {
+		return
+	}
+	keyring, ok := enabled(scope)
+	if !ok {
+		return
+	}
+	db := scope.NewDB()
+	for _, mo := range models(scope.Value) {
+		for _, s := range secretFields(mo) {
+			plain, err := keyring.Decrypt(db, *s)
+			if err != nil {
+				scope.Err(err)
+				return
+			}
+			*s = plain
+		}
+	}
+}
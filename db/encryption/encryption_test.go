@@ -0,0 +1,276 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	kmstesting "k8s.io/apiserver/pkg/storage/value/encrypt/envelope/testing"
+)
+
+func newTestMasterKey(t *testing.T, dir, name string) MasterKey {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mk, err := NewFileMasterKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mk
+}
+
+func openTestDB(t *testing.T) (*gorm.DB, string, func()) {
+	dir, err := ioutil.TempDir("", "encryption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open("sqlite3", filepath.Join(dir, "region.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	db.DB().SetMaxOpenConns(1)
+	for _, m := range []interface{}{&model.TenantServiceEnvVar{}, &model.ConfigGroupItem{}, &model.Application{},
+		&model.TenantServices{}, &model.TenantPluginVersionEnv{}, &model.Certificate{}, &model.TenantDataKey{},
+		&model.TenantServiceWebhook{}} {
+		if err := db.AutoMigrate(m).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db, dir, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func storedValue(t *testing.T, db *gorm.DB, table, column string, id uint) string {
+	var values []string
+	if err := db.Table(table).Where("ID = ?", id).Pluck(column, &values).Error; err != nil || len(values) != 1 {
+		t.Fatalf("read %s.%s: %v", table, column, err)
+	}
+	return values[0]
+}
+
+func TestNewFileMasterKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "masterkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	raw := filepath.Join(dir, "raw")
+	ioutil.WriteFile(raw, []byte("0123456789abcdef0123456789abcdef"), 0600)
+	mk, err := NewFileMasterKey(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped, err := mk.Wrap([]byte("data key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := mk.Unwrap(wrapped)
+	if err != nil || string(key) != "data key" {
+		t.Errorf("expected data key unwrapped, but got %q, %v", key, err)
+	}
+	if _, err := newTestMasterKey(t, dir, "other").Unwrap(wrapped); err == nil {
+		t.Error("expected an error unwrapping with another master key")
+	}
+
+	short := filepath.Join(dir, "short")
+	ioutil.WriteFile(short, []byte("short"), 0600)
+	if _, err := NewFileMasterKey(short); err == nil {
+		t.Error("expected an error for a short master key")
+	}
+}
+
+func TestCallbacks(t *testing.T) {
+	db, dir, cleanup := openTestDB(t)
+	defer cleanup()
+	db = Register(db, newKeyring(newTestMasterKey(t, dir, "master")))
+
+	env := &model.TenantServiceEnvVar{TenantID: "tenant1", ServiceID: "service1", AttrName: "PASSWORD", AttrValue: "secret"}
+	if err := db.Create(env).Error; err != nil {
+		t.Fatal(err)
+	}
+	if env.AttrValue != "secret" {
+		t.Errorf("expected the plain value kept in the model, but got %s", env.AttrValue)
+	}
+	stored := storedValue(t, db, env.TableName(), "attr_value", env.ID)
+	if !IsEncrypted(stored) {
+		t.Fatalf("expected the value encrypted in db, but got %s", stored)
+	}
+
+	var envs []model.TenantServiceEnvVar
+	if err := db.Where("service_id = ?", "service1").Find(&envs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(envs) != 1 || envs[0].AttrValue != "secret" {
+		t.Errorf("expected the value decrypted, but got %+v", envs)
+	}
+
+	envs[0].AttrValue = "new secret"
+	if err := db.Save(&envs[0]).Error; err != nil {
+		t.Fatal(err)
+	}
+	var got model.TenantServiceEnvVar
+	if err := db.Where("ID = ?", env.ID).First(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.AttrValue != "new secret" {
+		t.Errorf("expected new secret, but got %s", got.AttrValue)
+	}
+
+	// the data key of a config group item belongs to the tenant of its application
+	if err := db.Create(&model.Application{AppID: "app1", TenantID: "tenant2"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	item := &model.ConfigGroupItem{AppID: "app1", ConfigGroupName: "cg", ItemKey: "key", ItemValue: "value"}
+	if err := db.Create(item).Error; err != nil {
+		t.Fatal(err)
+	}
+	tenantID, _, _, err := parse(storedValue(t, db, item.TableName(), "item_value", item.ID))
+	if err != nil || tenantID != "tenant2" {
+		t.Errorf("expected the item encrypted by the data key of tenant2, but got %s, %v", tenantID, err)
+	}
+
+	// both the secret and the password of webhooks are encrypted
+	hook := &model.TenantServiceWebhook{TenantID: "tenant1", ServiceID: "service1", Secret: "hook secret", Password: "git password"}
+	if err := db.Create(hook).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, column := range []string{"secret", "password"} {
+		if stored := storedValue(t, db, hook.TableName(), column, hook.ID); !IsEncrypted(stored) {
+			t.Errorf("expected the webhook %s encrypted in db, but got %s", column, stored)
+		}
+	}
+	var gotHook model.TenantServiceWebhook
+	if err := db.Where("service_id = ?", "service1").First(&gotHook).Error; err != nil {
+		t.Fatal(err)
+	}
+	if gotHook.Secret != "hook secret" || gotHook.Password != "git password" {
+		t.Errorf("expected the webhook secrets decrypted, but got %s, %s", gotHook.Secret, gotHook.Password)
+	}
+
+	// the keyring can not be used without the master key
+	var items []*model.ConfigGroupItem
+	other := Register(db, newKeyring(newTestMasterKey(t, dir, "other")))
+	if err := other.Find(&items).Error; err == nil {
+		t.Error("expected an error decrypting with another master key")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	db, dir, cleanup := openTestDB(t)
+	defer cleanup()
+
+	// secrets written before the encryption is enabled
+	cert := &model.Certificate{UUID: "cert1", PrivateKey: "private key"}
+	env := &model.TenantServiceEnvVar{TenantID: "tenant1", ServiceID: "service1", AttrName: "PASSWORD", AttrValue: "secret"}
+	if err := db.Create(cert).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(env).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	old := newTestMasterKey(t, dir, "old")
+	keyring := newKeyring(old)
+	db = Register(db, keyring)
+	if n, err := keyring.EncryptAll(db); err != nil || n != 2 {
+		t.Fatalf("expected 2 values encrypted, but got %d, %v", n, err)
+	}
+	if n, err := keyring.EncryptAll(db); err != nil || n != 0 {
+		t.Errorf("expected nothing to encrypt again, but got %d, %v", n, err)
+	}
+
+	if n, err := keyring.RotateDataKeys(db); err != nil || n != 2 {
+		t.Fatalf("expected 2 values re-encrypted, but got %d, %v", n, err)
+	}
+	_, version, _, _ := parse(storedValue(t, db, env.TableName(), "attr_value", env.ID))
+	if version != 2 {
+		t.Errorf("expected the value encrypted by data key 2, but got %d", version)
+	}
+
+	// rotate the master key
+	keyring = newKeyring(newTestMasterKey(t, dir, "new"), old)
+	db = Register(db, keyring)
+	if n, err := keyring.RewrapDataKeys(db); err != nil || n != 4 {
+		t.Fatalf("expected 4 data keys rewrapped, but got %d, %v", n, err)
+	}
+	db = Register(db, newKeyring(keyring.active))
+	var got model.Certificate
+	if err := db.Where("uuid = ?", "cert1").First(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.PrivateKey != "private key" {
+		t.Errorf("expected private key, but got %s", got.PrivateKey)
+	}
+
+	if n, err := keyring.DecryptAll(db); err != nil || n != 2 {
+		t.Fatalf("expected 2 values decrypted, but got %d, %v", n, err)
+	}
+	if stored := storedValue(t, db, env.TableName(), "attr_value", env.ID); stored != "secret" {
+		t.Errorf("expected plain value in db, but got %s", stored)
+	}
+}
+
+func TestKMSMasterKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "kms.sock")
+	plugin, err := kmstesting.NewBase64Plugin(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plugin.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.CleanUp()
+	if err := kmstesting.WaitForBase64PluginToBeUp(plugin); err != nil {
+		t.Fatal(err)
+	}
+
+	mk, err := NewKMSMasterKey("unix://" + socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped, err := mk.Wrap([]byte("data key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := mk.Unwrap(wrapped)
+	if err != nil || string(key) != "data key" {
+		t.Errorf("expected data key unwrapped, but got %q, %v", key, err)
+	}
+}
@@ -0,0 +1,246 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+//prefix of the encrypted values, followed by "<tenant id>:<data key version>:<base64 of nonce and cipher text>"
+const prefix = "enc:v1:"
+
+//Config the master keys of the encryption at rest
+type Config struct {
+	//MasterKeyFile the file of the active master key
+	MasterKeyFile string
+	//PreviousMasterKeyFiles the files of the master keys before rotation, only used to unwrap data keys
+	PreviousMasterKeyFiles []string
+	//KMSEndpoint the endpoint of the kms plugin keeping the active master key, used if MasterKeyFile is empty
+	KMSEndpoint string
+}
+
+//Enabled returns whether a master key is configured
+func (c Config) Enabled() bool {
+	return c.MasterKeyFile != "" || c.KMSEndpoint != ""
+}
+
+//Keyring encrypts values with the data keys of tenants
+type Keyring struct {
+	active  MasterKey
+	masters map[string]MasterKey
+	lock    sync.Mutex
+	// tenant id -> data key version -> data key
+	dataKeys map[string]map[int][]byte
+}
+
+//NewKeyring creates the keyring with the master keys of the config
+func NewKeyring(c Config) (*Keyring, error) {
+	var active MasterKey
+	var err error
+	if c.MasterKeyFile != "" {
+		active, err = NewFileMasterKey(c.MasterKeyFile)
+	} else {
+		active, err = NewKMSMasterKey(c.KMSEndpoint)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var previous []MasterKey
+	for _, file := range c.PreviousMasterKeyFiles {
+		key, err := NewFileMasterKey(file)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	return newKeyring(active, previous...), nil
+}
+
+func newKeyring(active MasterKey, previous ...MasterKey) *Keyring {
+	k := &Keyring{
+		active:   active,
+		masters:  map[string]MasterKey{active.ID(): active},
+		dataKeys: make(map[string]map[int][]byte),
+	}
+	for _, m := range previous {
+		if _, ok := k.masters[m.ID()]; !ok {
+			k.masters[m.ID()] = m
+		}
+	}
+	return k
+}
+
+//IsEncrypted returns whether the value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+//Encrypt encrypts the value with the active data key of the tenant, creates the data key if not exists
+func (k *Keyring) Encrypt(db *gorm.DB, tenantID, value string) (string, error) {
+	version, key, err := k.activeDataKey(db, tenantID)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(aead, []byte(value), []byte(tenantID))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s:%d:%s", prefix, tenantID, version, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+//Decrypt decrypts the value, returns it as is if it is not encrypted
+func (k *Keyring) Decrypt(db *gorm.DB, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	tenantID, version, sealed, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	key, err := k.dataKey(db, tenantID, version)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(aead, sealed, []byte(tenantID))
+	if err != nil {
+		return "", fmt.Errorf("decrypt value of tenant %s: %v", tenantID, err)
+	}
+	return string(plain), nil
+}
+
+func parse(value string) (tenantID string, version int, sealed []byte, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(value, prefix), ":", 3)
+	if len(parts) != 3 {
+		return "", 0, nil, fmt.Errorf("malformed encrypted value")
+	}
+	version, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("malformed data key version %s", parts[1])
+	}
+	sealed, err = base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("malformed encrypted value: %v", err)
+	}
+	return parts[0], version, sealed, nil
+}
+
+//activeDataKey returns the active data key of the tenant, the active version is read every time
+//so that a rotation done by another process takes effect.
+func (k *Keyring) activeDataKey(db *gorm.DB, tenantID string) (int, []byte, error) {
+	var dk model.TenantDataKey
+	err := db.Where("tenant_id = ? and active = ?", tenantID, true).Order("version desc").First(&dk).Error
+	if err == gorm.ErrRecordNotFound {
+		dk, err = k.createDataKey(db, tenantID, 1)
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("get data key of tenant %s: %v", tenantID, err)
+	}
+	key, err := k.unwrap(&dk)
+	if err != nil {
+		return 0, nil, err
+	}
+	return dk.Version, key, nil
+}
+
+func (k *Keyring) dataKey(db *gorm.DB, tenantID string, version int) ([]byte, error) {
+	k.lock.Lock()
+	key, ok := k.dataKeys[tenantID][version]
+	k.lock.Unlock()
+	if ok {
+		return key, nil
+	}
+	var dk model.TenantDataKey
+	if err := db.Where("tenant_id = ? and version = ?", tenantID, version).First(&dk).Error; err != nil {
+		return nil, fmt.Errorf("get data key %d of tenant %s: %v", version, tenantID, err)
+	}
+	return k.unwrap(&dk)
+}
+
+func (k *Keyring) unwrap(dk *model.TenantDataKey) ([]byte, error) {
+	k.lock.Lock()
+	key, ok := k.dataKeys[dk.TenantID][dk.Version]
+	k.lock.Unlock()
+	if ok {
+		return key, nil
+	}
+	master, ok := k.masters[dk.MasterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("master key %s of data key %d of tenant %s not configured", dk.MasterKeyID, dk.Version, dk.TenantID)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(dk.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err = master.Unwrap(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key %d of tenant %s: %v", dk.Version, dk.TenantID, err)
+	}
+	k.lock.Lock()
+	if k.dataKeys[dk.TenantID] == nil {
+		k.dataKeys[dk.TenantID] = make(map[int][]byte)
+	}
+	k.dataKeys[dk.TenantID][dk.Version] = key
+	k.lock.Unlock()
+	return key, nil
+}
+
+//createDataKey creates the data key of the version as the active one.
+//If another process creates it at the same time, the one created by that process is returned.
+func (k *Keyring) createDataKey(db *gorm.DB, tenantID string, version int) (model.TenantDataKey, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return model.TenantDataKey{}, err
+	}
+	wrapped, err := k.active.Wrap(key)
+	if err != nil {
+		return model.TenantDataKey{}, fmt.Errorf("wrap data key: %v", err)
+	}
+	dk := model.TenantDataKey{
+		TenantID:    tenantID,
+		Version:     version,
+		WrappedKey:  base64.StdEncoding.EncodeToString(wrapped),
+		MasterKeyID: k.active.ID(),
+		Active:      true,
+	}
+	if err := db.Create(&dk).Error; err != nil {
+		var exist model.TenantDataKey
+		if db.Where("tenant_id = ? and version = ?", tenantID, version).First(&exist).Error != nil {
+			return model.TenantDataKey{}, err
+		}
+		return exist, nil
+	}
+	return dk, nil
+}
@@ -0,0 +1,134 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"k8s.io/apiserver/pkg/storage/value/encrypt/envelope"
+)
+
+//MasterKey wraps and unwraps the data keys
+type MasterKey interface {
+	//ID identifies the master key a data key is wrapped by
+	ID() string
+	Wrap(dataKey []byte) ([]byte, error)
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+//fileMasterKey is an aes-256 key read from a file
+type fileMasterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+//NewFileMasterKey reads the master key from the file, the file contains 32 bytes, raw or base64 encoded.
+func NewFileMasterKey(path string) (MasterKey, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read master key file: %v", err)
+	}
+	key := content
+	if len(key) != 32 {
+		key, err = base64.StdEncoding.DecodeString(string(bytes.TrimSpace(content)))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("master key file %s must contain 32 bytes, raw or base64 encoded", path)
+		}
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &fileMasterKey{id: "file:" + hex.EncodeToString(sum[:8]), aead: aead}, nil
+}
+
+func (f *fileMasterKey) ID() string {
+	return f.id
+}
+
+func (f *fileMasterKey) Wrap(dataKey []byte) ([]byte, error) {
+	return seal(f.aead, dataKey, nil)
+}
+
+func (f *fileMasterKey) Unwrap(wrapped []byte) ([]byte, error) {
+	return open(f.aead, wrapped, nil)
+}
+
+//kmsMasterKey is a master key kept by a kubernetes kms plugin
+type kmsMasterKey struct {
+	endpoint string
+	service  envelope.Service
+}
+
+//NewKMSMasterKey uses the kms plugin listening on the endpoint, e.g. unix:///var/run/kms-plugin/socket.sock,
+//which implements the KeyManagementService of kubernetes kms v1beta1.
+func NewKMSMasterKey(endpoint string) (MasterKey, error) {
+	service, err := envelope.NewGRPCService(endpoint, 3*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &kmsMasterKey{endpoint: endpoint, service: service}, nil
+}
+
+func (k *kmsMasterKey) ID() string {
+	return "kms:" + k.endpoint
+}
+
+func (k *kmsMasterKey) Wrap(dataKey []byte) ([]byte, error) {
+	return k.service.Encrypt(dataKey)
+}
+
+func (k *kmsMasterKey) Unwrap(wrapped []byte) ([]byte, error) {
+	return k.service.Decrypt(wrapped)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//seal encrypts plain, the result is nonce followed by the cipher text
+func seal(aead cipher.AEAD, plain, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, additional), nil
+}
+
+func open(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("cipher text too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additional)
+}
@@ -0,0 +1,194 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package encryption
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+const batchSize = 500
+
+//secretTables the models with secret fields, columns are in the order of secretFields
+var secretTables = []struct {
+	model   interface{}
+	columns []string
+}{
+	{&model.TenantServiceEnvVar{}, []string{"attr_value"}},
+	{&model.ConfigGroupItem{}, []string{"item_value"}},
+	{&model.TenantPluginVersionEnv{}, []string{"env_value"}},
+	{&model.Certificate{}, []string{"private_key"}},
+	{&model.TenantServiceWebhook{}, []string{"secret", "password"}},
+}
+
+//EncryptAll encrypts the plain secrets, and re-encrypts the secrets not encrypted by the active data key of their tenant.
+//It returns the number of the updated values.
+func (k *Keyring) EncryptAll(db *gorm.DB) (int, error) {
+	activeVersions := make(map[string]int)
+	activeVersion := func(tenantID string) (int, error) {
+		if v, ok := activeVersions[tenantID]; ok {
+			return v, nil
+		}
+		v, _, err := k.activeDataKey(db, tenantID)
+		if err != nil {
+			return 0, err
+		}
+		activeVersions[tenantID] = v
+		return v, nil
+	}
+	return eachSecret(db, func(mo interface{}, value string) (string, bool, error) {
+		if value == "" {
+			return "", false, nil
+		}
+		if IsEncrypted(value) {
+			tenantID, version, _, err := parse(value)
+			if err != nil {
+				return "", false, err
+			}
+			active, err := activeVersion(tenantID)
+			if err != nil || version == active {
+				return "", false, err
+			}
+			if value, err = k.Decrypt(db, value); err != nil {
+				return "", false, err
+			}
+			enc, err := k.Encrypt(db, tenantID, value)
+			return enc, err == nil, err
+		}
+		tenantID, err := tenantOf(db, mo)
+		if err != nil {
+			return "", false, err
+		}
+		enc, err := k.Encrypt(db, tenantID, value)
+		return enc, err == nil, err
+	})
+}
+
+//DecryptAll decrypts all the secrets back to plain text, returns the number of the updated values
+func (k *Keyring) DecryptAll(db *gorm.DB) (int, error) {
+	return eachSecret(db, func(mo interface{}, value string) (string, bool, error) {
+		if !IsEncrypted(value) {
+			return "", false, nil
+		}
+		plain, err := k.Decrypt(db, value)
+		return plain, err == nil, err
+	})
+}
+
+//CountEncrypted returns the number of the encrypted secret values
+func CountEncrypted(db *gorm.DB) (int, error) {
+	var count int
+	_, err := eachSecret(db, func(mo interface{}, value string) (string, bool, error) {
+		if IsEncrypted(value) {
+			count++
+		}
+		return "", false, nil
+	})
+	return count, err
+}
+
+//RewrapDataKeys wraps the data keys wrapped by the previous master keys with the active master key,
+//returns the number of rewrapped data keys. The previous master keys are not needed anymore after it.
+func (k *Keyring) RewrapDataKeys(db *gorm.DB) (int, error) {
+	var dks []*model.TenantDataKey
+	if err := db.Where("master_key_id <> ?", k.active.ID()).Find(&dks).Error; err != nil {
+		return 0, err
+	}
+	for i, dk := range dks {
+		key, err := k.unwrap(dk)
+		if err != nil {
+			return i, err
+		}
+		wrapped, err := k.active.Wrap(key)
+		if err != nil {
+			return i, fmt.Errorf("wrap data key %d of tenant %s: %v", dk.Version, dk.TenantID, err)
+		}
+		if err := db.Model(dk).Updates(map[string]interface{}{
+			"wrapped_key":   base64.StdEncoding.EncodeToString(wrapped),
+			"master_key_id": k.active.ID(),
+		}).Error; err != nil {
+			return i, err
+		}
+	}
+	return len(dks), nil
+}
+
+//RotateDataKeys creates a new active data key for every tenant, and re-encrypts the secrets with them.
+//The old data keys are kept, so the values written with them meanwhile can still be decrypted.
+func (k *Keyring) RotateDataKeys(db *gorm.DB) (int, error) {
+	var actives []*model.TenantDataKey
+	if err := db.Where("active = ?", true).Find(&actives).Error; err != nil {
+		return 0, err
+	}
+	for _, dk := range actives {
+		if _, err := k.createDataKey(db, dk.TenantID, dk.Version+1); err != nil {
+			return 0, fmt.Errorf("create data key of tenant %s: %v", dk.TenantID, err)
+		}
+		if err := db.Model(&model.TenantDataKey{}).Where("tenant_id = ? and version <= ?", dk.TenantID, dk.Version).
+			Update("active", false).Error; err != nil {
+			return 0, err
+		}
+	}
+	return k.EncryptAll(db)
+}
+
+//eachSecret calls f with every stored secret value, and updates the value if f returns true
+func eachSecret(db *gorm.DB, f func(mo interface{}, value string) (string, bool, error)) (int, error) {
+	db = Skip(db)
+	id := db.Dialect().Quote("ID")
+	var count int
+	for _, table := range secretTables {
+		var last uint
+		for {
+			list := reflect.New(reflect.SliceOf(reflect.TypeOf(table.model)))
+			if err := db.Where(id+" > ?", last).Order(id).Limit(batchSize).Find(list.Interface()).Error; err != nil {
+				return count, err
+			}
+			items := models(list.Interface())
+			for _, mo := range items {
+				last = uint(reflect.ValueOf(mo).Elem().FieldByName("ID").Uint())
+				for i, s := range secretFields(mo) {
+					value, update, err := f(mo, *s)
+					if err != nil {
+						return count, fmt.Errorf("%s %d: %v", table.columns[i], last, err)
+					}
+					if !update {
+						continue
+					}
+					if err := db.Model(mo).UpdateColumn(table.columns[i], value).Error; err != nil {
+						return count, err
+					}
+					count++
+				}
+			}
+			if len(items) < batchSize {
+				break
+			}
+		}
+	}
+	if count > 0 {
+		logrus.Infof("%d secret values updated", count)
+	}
+	return count, nil
+}
@@ -0,0 +1,1077 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+//Package baseline the tables of the baseline schema, copied from db/model when versioned migrations were introduced.
+//The models are frozen, so the baseline migration always creates the same schema. Never change them,
+//the later changes of db/model are applied by their own migrations.
+package baseline
+
+import "time"
+
+//AppBackup app backup info
+type AppBackup struct {
+	Model
+	EventID  string `gorm:"column:event_id;size:32;" json:"event_id"`
+	BackupID string `gorm:"column:backup_id;size:32;" json:"backup_id"`
+	GroupID  string `gorm:"column:group_id;size:32;" json:"group_id"`
+	//Status in starting,failed,success,restore
+	Status     string `gorm:"column:status;size:32" json:"status"`
+	Version    string `gorm:"column:version;size:32" json:"version"`
+	SourceDir  string `gorm:"column:source_dir;size:255" json:"source_dir"`
+	SourceType string `gorm:"column:source_type;size:255;default:'local'" json:"source_type"`
+	BackupMode string `gorm:"column:backup_mode;size:32" json:"backup_mode"`
+	BuckupSize int64  `gorm:"column:backup_size;type:bigint" json:"backup_size"`
+	Deleted    bool   `gorm:"column:deleted" json:"deleted"`
+	//ScheduleID the schedule which created this backup, empty if backup manually
+	ScheduleID string `gorm:"column:schedule_id;size:32" json:"schedule_id"`
+	//Encrypted the backup data is encrypted by client-side encryption
+	Encrypted bool `gorm:"column:encrypted" json:"encrypted"`
+}
+
+//TableName 表名
+func (t *AppBackup) TableName() string {
+	return "region_app_backup"
+}
+
+//AppBackupSchedule the schedule of group app backup
+type AppBackupSchedule struct {
+	Model
+	ScheduleID string `gorm:"column:schedule_id;size:32;unique_index" json:"schedule_id"`
+	GroupID    string `gorm:"column:group_id;size:32" json:"group_id"`
+	//ServiceIDs service ids joined by comma
+	ServiceIDs string `gorm:"column:service_ids;type:text" json:"service_ids"`
+	//Cron standard cron expression, such as "0 2 * * *"
+	Cron string `gorm:"column:cron;size:64" json:"cron"`
+	//KeepDaily the number of daily backups to keep
+	KeepDaily int `gorm:"column:keep_daily" json:"keep_daily"`
+	//KeepWeekly the number of weekly backups to keep
+	KeepWeekly int `gorm:"column:keep_weekly" json:"keep_weekly"`
+	//Mode full-online or incremental-online
+	Mode  string `gorm:"column:mode;size:32" json:"mode"`
+	Force bool   `gorm:"column:force" json:"force"`
+	//Metadata console level metadata of the group app
+	Metadata     string `gorm:"column:metadata;size:65535" json:"-"`
+	S3Provider   string `gorm:"column:s3_provider;size:32" json:"s3_provider"`
+	S3Endpoint   string `gorm:"column:s3_endpoint;size:255" json:"s3_endpoint"`
+	S3AccessKey  string `gorm:"column:s3_access_key;size:255" json:"s3_access_key"`
+	S3SecretKey  string `gorm:"column:s3_secret_key;type:text" json:"-"`
+	S3BucketName string `gorm:"column:s3_bucket_name;size:255" json:"s3_bucket_name"`
+	//EncryptionSecretName the secret of client-side encryption key, the backups are not encrypted if empty
+	EncryptionSecretName string `gorm:"column:encryption_secret_name;size:253" json:"encryption_secret_name"`
+	EncryptionSecretKey  string `gorm:"column:encryption_secret_key;size:253" json:"encryption_secret_key"`
+	Enable               bool   `gorm:"column:enable" json:"enable"`
+	//LastScheduleTime the last time the backup was triggered
+	LastScheduleTime *time.Time `gorm:"column:last_schedule_time" json:"last_schedule_time"`
+}
+
+//TableName 表名
+func (t *AppBackupSchedule) TableName() string {
+	return "region_app_backup_schedule"
+}
+
+// AppStatus app status
+type AppStatus struct {
+	EventID     string `gorm:"column:event_id;size:32;primary_key" json:"event_id"`
+	Format      string `gorm:"column:format;size:32" json:"format"` // only rainbond-app/docker-compose
+	SourceDir   string `gorm:"column:source_dir;size:255" json:"source_dir"`
+	Apps        string `gorm:"column:apps;type:text" json:"apps"`
+	Status      string `gorm:"column:status;size:32" json:"status"` // only exporting/importing/failed/success/cleaned
+	TarFileHref string `gorm:"column:tar_file_href;size:255" json:"tar_file_href"`
+	Metadata    string `gorm:"column:metadata;type:text" json:"metadata"`
+}
+
+//TableName 表名
+func (t *AppStatus) TableName() string {
+	return "region_app_status"
+}
+
+// Application -
+type Application struct {
+	Model
+	AppName        string `gorm:"column:app_name" json:"app_name"`
+	AppID          string `gorm:"column:app_id" json:"app_id"`
+	TenantID       string `gorm:"column:tenant_id" json:"tenant_id"`
+	GovernanceMode string `gorm:"column:governance_mode;default:'BUILD_IN_SERVICE_MESH'" json:"governance_mode"`
+	// TracingProvider is empty if the tracing is disabled
+	TracingProvider   string `gorm:"column:tracing_provider" json:"tracing_provider"`
+	TracingEndpoint   string `gorm:"column:tracing_endpoint" json:"tracing_endpoint"`
+	TracingSampleRate int    `gorm:"column:tracing_sample_rate;default:100" json:"tracing_sample_rate"`
+}
+
+// TableName return tableName "application"
+func (t *Application) TableName() string {
+	return "applications"
+}
+
+// ApplicationConfigGroup -
+type ApplicationConfigGroup struct {
+	Model
+	AppID           string `gorm:"column:app_id" json:"app_id"`
+	ConfigGroupName string `gorm:"column:config_group_name" json:"config_group_name"`
+	DeployType      string `gorm:"column:deploy_type;default:'env'" json:"deploy_type"`
+	Enable          bool   `gorm:"column:enable" json:"enable"`
+}
+
+// TableName return tableName "application"
+func (t *ApplicationConfigGroup) TableName() string {
+	return "app_config_group"
+}
+
+// Certificate contains TLS information
+type Certificate struct {
+	Model
+	UUID            string `gorm:"column:uuid"`
+	CertificateName string `gorm:"column:certificate_name;size:128"`
+	Certificate     string `gorm:"column:certificate;size:65535"`
+	PrivateKey      string `gorm:"column:private_key;size:65535"`
+}
+
+// TableName returns table name of Certificate
+func (Certificate) TableName() string {
+	return "gateway_certificate"
+}
+
+//CodeCheckResult codecheck result struct
+type CodeCheckResult struct {
+	Model
+	ServiceID       string `gorm:"column:service_id;size:70"`
+	Condition       string `gorm:"column:condition"`
+	Language        string `gorm:"column:language"`
+	CheckType       string `gorm:"column:check_type"`
+	GitURL          string `gorm:"column:git_url"`
+	CodeVersion     string `gorm:"column:code_version"`
+	GitProjectId    string `gorm:"column:git_project_id"`
+	CodeFrom        string `gorm:"column:code_from"`
+	URLRepos        string `gorm:"column:url_repos"`
+	DockerFileReady bool   `gorm:"column:docker_file_ready"`
+	InnerPort       string `gorm:"column:inner_port"`
+	VolumeMountPath string `gorm:"column:volume_mount_path"`
+	BuildImageName  string `gorm:"column:image"`
+	PortList        string `gorm:"column:port_list"`
+	VolumeList      string `gorm:"column:volume_list"`
+}
+
+//TableName 表名
+func (t *CodeCheckResult) TableName() string {
+	return "tenant_services_codecheck"
+}
+
+// ConfigGroupItem -
+type ConfigGroupItem struct {
+	Model
+	AppID           string `gorm:"column:app_id" json:"-"`
+	ConfigGroupName string `gorm:"column:config_group_name" json:"-"`
+	ItemKey         string `gorm:"column:item_key" json:"item_key"`
+	ItemValue       string `gorm:"column:item_value" json:"item_value"`
+}
+
+// TableName return tableName "application"
+func (t *ConfigGroupItem) TableName() string {
+	return "app_config_group_item"
+}
+
+// ConfigGroupService -
+type ConfigGroupService struct {
+	Model
+	AppID           string `gorm:"column:app_id" json:"-"`
+	ConfigGroupName string `gorm:"column:config_group_name" json:"-"`
+	ServiceID       string `gorm:"column:service_id" json:"service_id"`
+	ServiceAlias    string `gorm:"column:service_alias" json:"service_alias"`
+}
+
+// TableName return tableName "application"
+func (t *ConfigGroupService) TableName() string {
+	return "app_config_group_service"
+}
+
+// Endpoint is a persistent object for table 3rd_party_svc_endpoints.
+type Endpoint struct {
+	Model
+	UUID      string `gorm:"column:uuid;size:32" json:"uuid"`
+	ServiceID string `gorm:"column:service_id;size:32;not null" json:"service_id"`
+	IP        string `gorm:"column:ip;not null" json:"ip"`
+	Port      int    `gorm:"column:port;size:65535" json:"port"`
+	//use pointer type, zero values won't be saved into database
+	IsOnline *bool `gorm:"column:is_online;default:true" json:"is_online"`
+}
+
+// TableName returns table name of Endpoint.
+func (Endpoint) TableName() string {
+	return "tenant_service_3rd_party_endpoints"
+}
+
+// GwRuleConfig describes a configuration of gateway rule.
+type GwRuleConfig struct {
+	Model
+	RuleID string `gorm:"column:rule_id;size:32"`
+	Key    string `gorm:"column:key"`
+	Value  string `gorm:"column:value"`
+}
+
+// TableName -
+func (GwRuleConfig) TableName() string {
+	return "gateway_rule_config"
+}
+
+// HTTPRule contains http rule
+type HTTPRule struct {
+	Model
+	UUID          string `gorm:"column:uuid"`
+	ServiceID     string `gorm:"column:service_id"`
+	ContainerPort int    `gorm:"column:container_port"`
+	Domain        string `gorm:"column:domain"`
+	Path          string `gorm:"column:path;size:65535"`
+	Header        string `gorm:"column:header;size:65535"`
+	Cookie        string `gorm:"column:cookie;size:65535"`
+	Weight        int    `gorm:"column:weight"`
+	IP            string `gorm:"column:ip"`
+	CertificateID string `gorm:"column:certificate_id"`
+}
+
+// TableName returns table name of HTTPRule
+func (HTTPRule) TableName() string {
+	return "gateway_http_rule"
+}
+
+//IDModel 默认ID字段
+type IDModel struct {
+	ID uint `gorm:"column:ID;primary_key"`
+}
+
+//LicenseInfo 信息
+type LicenseInfo struct {
+	//Model
+	IDModel
+	Token   string `gorm:"column:token;size:40;" json:"token"`
+	License string `gorm:"column:license;" json:"license"`
+	Label   string `gorm:"column:label" json:"label"`
+}
+
+//TableName 返回license表名称
+func (l *LicenseInfo) TableName() string {
+	return "rainbond_license"
+}
+
+//LocalScheduler 本地调度暂存信息
+type LocalScheduler struct {
+	Model
+	ServiceID string `gorm:"column:service_id;size:32"`
+	NodeIP    string `gorm:"column:node_ip;size:32"`
+	PodName   string `gorm:"column:pod_name;size:32"`
+}
+
+//TableName 表名
+func (t *LocalScheduler) TableName() string {
+	return "local_scheduler"
+}
+
+//Model 默认字段
+type Model struct {
+	ID        uint      `gorm:"column:ID;primary_key"`
+	CreatedAt time.Time `gorm:"column:create_time" json:"create_time"`
+}
+
+//NotificationEvent NotificationEvent
+type NotificationEvent struct {
+	Model
+	//Kind could be service, tenant, cluster, node
+	Kind string `gorm:"column:kind;size:40"`
+	//KindID could be service_id,tenant_id,cluster_id,node_id
+	KindID string `gorm:"column:kind_id;size:40"`
+	Hash   string `gorm:"column:hash;size:100"`
+	//Type could be Normal UnNormal Notification
+	Type          string    `gorm:"column:type;size:40"`
+	Message       string    `gorm:"column:message;size:200"`
+	Reason        string    `gorm:"column:reson;size:200"`
+	Count         int       `gorm:"column:count;"`
+	LastTime      time.Time `gorm:"column:last_time;"`
+	FirstTime     time.Time `gorm:"column:first_time;"`
+	IsHandle      bool      `gorm:"column:is_handle;"`
+	HandleMessage string    `gorm:"column:handle_message;"`
+	ServiceName   string    `gorm:"column:service_name;size:40"`
+	TenantName    string    `gorm:"column:tenant_name;size:40"`
+}
+
+//TableName table name
+func (n *NotificationEvent) TableName() string {
+	return "region_notification_event"
+}
+
+//RegionAPIClass RegionAPIClass
+type RegionAPIClass struct {
+	Model
+	ClassLevel string `gorm:"column:class_level;size:24;" json:"class_level"`
+	Prefix     string `gorm:"column:prefix;size:128;" json:"prefix"`
+	URI        string `gorm:"column:uri;size:256" json:"uri"`
+	Alias      string `gorm:"column:alias;size:64" json:"alias"`
+	Remark     string `gorm:"column:remark;size:64" json:"remark"`
+}
+
+//TableName 表名
+func (t *RegionAPIClass) TableName() string {
+	return "region_api_class"
+}
+
+//RegionProcotols RegionProcotol
+type RegionProcotols struct {
+	Model
+	ProtocolGroup string `gorm:"column:protocol_group;size:32;" json:"protocol_group"`
+	ProtocolChild string `gorm:"column:protocol_child;size:32;" json:"protocol_child"`
+	APIVersion    string `gorm:"column:api_version;size:8" json:"api_version"`
+	IsSupport     bool   `gorm:"column:is_support;default:false" json:"is_support"`
+}
+
+//TableName 表名
+func (t *RegionProcotols) TableName() string {
+	return "region_protocols"
+}
+
+//RegionUserInfo RegionUserInfo
+type RegionUserInfo struct {
+	Model
+	EID            string `gorm:"column:eid;size:34" json:"eid"`
+	APIRange       string `gorm:"column:api_range;size:24" json:"api_range"`
+	RegionTag      string `gorm:"column:region_tag;size:24" json:"region_tag"`
+	ValidityPeriod int    `gorm:"column:validity_period;size:10" json:"validity_period"`
+	Token          string `gorm:"column:token;size:32" json:"token"`
+	CA             string `gorm:"column:ca;size:4096" json:"ca"`
+	Key            string `gorm:"column:key;size:4096" json:"key"`
+}
+
+//TableName 表名
+func (t *RegionUserInfo) TableName() string {
+	return "user_region_info"
+}
+
+// RuleExtension contains rule extensions for http rule or tcp rule
+type RuleExtension struct {
+	Model
+	UUID   string `gorm:"column:uuid"`
+	RuleID string `gorm:"column:rule_id"`
+	Key    string `gorm:"column:key"`
+	Value  string `gorm:"column:value"`
+}
+
+// TableName returns table name of RuleExtension
+func (RuleExtension) TableName() string {
+	return "gateway_rule_extension"
+}
+
+//ServiceEvent event struct
+type ServiceEvent struct {
+	Model
+	EventID     string `gorm:"column:event_id;size:40"`
+	TenantID    string `gorm:"column:tenant_id;size:40;index:tenant_id"`
+	ServiceID   string `gorm:"column:service_id;size:40;index:service_id"`
+	Target      string `gorm:"column:target;size:40"`
+	TargetID    string `gorm:"column:target_id;size:255;index:target_id"`
+	RequestBody string `gorm:"column:request_body;size:1024"`
+	UserName    string `gorm:"column:user_name;size:40"`
+	StartTime   string `gorm:"column:start_time;size:40"`
+	EndTime     string `gorm:"column:end_time;size:40"`
+	OptType     string `gorm:"column:opt_type;size:40"`
+	SynType     int    `gorm:"column:syn_type;size:1"`
+	Status      string `gorm:"column:status;size:40"`
+	FinalStatus string `gorm:"column:final_status;size:40"`
+	Message     string `gorm:"column:message"`
+}
+
+//TableName 表名
+func (t *ServiceEvent) TableName() string {
+	return "tenant_services_event"
+}
+
+// ServiceID -
+type ServiceID struct {
+	ServiceID string `gorm:"column:service_id" json:"-"`
+}
+
+//ServiceSourceConfig service source config info
+//such as deployment、statefulset、configmap
+type ServiceSourceConfig struct {
+	Model
+	ServiceID  string `gorm:"column:service_id;size:32"`
+	SourceType string `gorm:"column:source_type;size:32"`
+	SourceBody string `gorm:"column:source_body;size:2000"`
+}
+
+//TableName 表名
+func (t *ServiceSourceConfig) TableName() string {
+	return "tenant_services_source"
+}
+
+// ServiceType type of service
+type ServiceType string
+
+// TCPRule contain stream rule
+type TCPRule struct {
+	Model
+	UUID          string `gorm:"column:uuid"`
+	ServiceID     string `gorm:"column:service_id"`
+	ContainerPort int    `gorm:"column:container_port"`
+	// external access ip
+	IP string `gorm:"column:ip"`
+	// external access port
+	Port int `gorm:"column:port"`
+}
+
+// TableName returns table name of TCPRule
+func (TCPRule) TableName() string {
+	return "gateway_tcp_rule"
+}
+
+//TenantPlugin plugin model
+type TenantPlugin struct {
+	Model
+	PluginID string `gorm:"column:plugin_id;size:32"`
+	//plugin name
+	PluginName string `gorm:"column:plugin_name;size:32" json:"plugin_name"`
+	//plugin describe
+	PluginInfo string `gorm:"column:plugin_info;size:255" json:"plugin_info"`
+	//plugin build by docker image name
+	ImageURL string `gorm:"column:image_url" json:"image_url"`
+	//plugin build by git code url
+	GitURL string `gorm:"column:git_url" json:"git_url"`
+	//build mode
+	BuildModel string `gorm:"column:build_model" json:"build_model"`
+	//plugin model InitPlugin,InBoundNetPlugin,OutBoundNetPlugin
+	PluginModel string `gorm:"column:plugin_model" json:"plugin_model"`
+	//tenant id
+	TenantID string `gorm:"column:tenant_id" json:"tenant_id"`
+	//tenant_name Used to calculate CPU and Memory.
+	Domain string `gorm:"column:domain" json:"domain"`
+	//gitlab; github The deprecated
+	CodeFrom string `gorm:"column:code_from" json:"code_from"`
+}
+
+//TableName table name
+func (t *TenantPlugin) TableName() string {
+	return "tenant_plugin"
+}
+
+//TenantPluginBuildVersion plugin build version
+type TenantPluginBuildVersion struct {
+	Model
+	//plugin version eg v1.0.0
+	VersionID string `gorm:"column:version_id;size:32" json:"version_id"`
+	//deploy version eg 20180528071717
+	DeployVersion   string `gorm:"column:deploy_version;size:32" json:"deploy_version"`
+	PluginID        string `gorm:"column:plugin_id;size:32" json:"plugin_id"`
+	Kind            string `gorm:"column:kind;size:24" json:"kind"`
+	BaseImage       string `gorm:"column:base_image;size:200" json:"base_image"`
+	BuildLocalImage string `gorm:"column:build_local_image;size:200" json:"build_local_image"`
+	BuildTime       string `gorm:"column:build_time" json:"build_time"`
+	Repo            string `gorm:"column:repo" json:"repo"`
+	GitURL          string `gorm:"column:git_url" json:"git_url"`
+	Info            string `gorm:"column:info" json:"info"`
+	Status          string `gorm:"column:status;size:24" json:"status"`
+	// container default cpu
+	ContainerCPU int `gorm:"column:container_cpu;default:125" json:"container_cpu"`
+	// container default memory
+	ContainerMemory int `gorm:"column:container_memory;default:64" json:"container_memory"`
+	// container args
+	ContainerCMD string `gorm:"column:container_cmd;size:2048" json:"container_cmd"`
+}
+
+//TableName table name
+func (t *TenantPluginBuildVersion) TableName() string {
+	return "tenant_plugin_build_version"
+}
+
+//TenantPluginVersionDiscoverConfig service plugin config that can be dynamic discovery
+type TenantPluginVersionDiscoverConfig struct {
+	Model
+	PluginID  string `gorm:"column:plugin_id;size:32" json:"plugin_id"`
+	ServiceID string `gorm:"column:service_id;size:32" json:"service_id"`
+	ConfigStr string `gorm:"column:config_str;" sql:"type:text;" json:"config_str"`
+}
+
+//TableName table name
+func (t *TenantPluginVersionDiscoverConfig) TableName() string {
+	return "tenant_plugin_version_config"
+}
+
+//TenantPluginVersionEnv TenantPluginVersionEnv
+type TenantPluginVersionEnv struct {
+	Model
+	//VersionID string `gorm:"column:version_id;size:32"`
+	PluginID  string `gorm:"column:plugin_id;size:32" json:"plugin_id"`
+	EnvName   string `gorm:"column:env_name" json:"env_name"`
+	EnvValue  string `gorm:"column:env_value" json:"env_value"`
+	ServiceID string `gorm:"column:service_id" json:"service_id"`
+}
+
+//TableName table name
+func (t *TenantPluginVersionEnv) TableName() string {
+	return "tenant_plugin_version_env"
+}
+
+// TenantServiceAutoscalerRuleMetrics -
+// MetricSelector is the label selector of metric series, such as topic=orders.
+// MetricObject is the name of service monitor whose kubernetes service is described by the object metric.
+type TenantServiceAutoscalerRuleMetrics struct {
+	Model
+	RuleID            string `gorm:"column:rule_id;size:32;not null"`
+	MetricsType       string `gorm:"column:metric_type;not null"`
+	MetricsName       string `gorm:"column:metric_name;not null"`
+	MetricTargetType  string `gorm:"column:metric_target_type;not null"`
+	MetricTargetValue int    `gorm:"column:metric_target_value;not null"`
+	MetricSelector    string `gorm:"column:metric_selector;size:255"`
+	MetricObject      string `gorm:"column:metric_object;size:40"`
+}
+
+// TableName -
+func (t *TenantServiceAutoscalerRuleMetrics) TableName() string {
+	return "tenant_services_autoscaler_rule_metrics"
+}
+
+// TenantServiceAutoscalerRules -
+type TenantServiceAutoscalerRules struct {
+	Model
+	RuleID      string `gorm:"column:rule_id;unique;size:32"`
+	ServiceID   string `gorm:"column:service_id;size:32"`
+	Enable      bool   `gorm:"column:enable"`
+	XPAType     string `gorm:"column:xpa_type;size:3"`
+	MinReplicas int    `gorm:"colume:min_replicas"`
+	MaxReplicas int    `gorm:"colume:max_replicas"`
+}
+
+// TableName -
+func (t *TenantServiceAutoscalerRules) TableName() string {
+	return "tenant_services_autoscaler_rules"
+}
+
+// TenantServiceConfigFile represents a data in configMap which is one of the types of volumes
+type TenantServiceConfigFile struct {
+	Model
+	ServiceID   string `gorm:"column:service_id;size:32" json:"service_id"`
+	VolumeName  string `gorm:"column:volume_name;size:32" json:"volume_name"`
+	FileContent string `gorm:"column:file_content;size:65535" json:"filename"`
+}
+
+// TableName returns table name of TenantServiceConfigFile.
+func (t *TenantServiceConfigFile) TableName() string {
+	return "tenant_service_config_file"
+}
+
+//TenantServiceEnvVar  应用环境变量
+type TenantServiceEnvVar struct {
+	Model
+	TenantID      string `gorm:"column:tenant_id;size:32" validate:"tenant_id|between:30,33" json:"tenant_id"`
+	ServiceID     string `gorm:"column:service_id;size:32" validate:"service_id|between:30,33" json:"service_id"`
+	ContainerPort int    `gorm:"column:container_port" validate:"container_port|numeric_between:1,65535" json:"container_port"`
+	Name          string `gorm:"column:name;size:1024" validate:"name" json:"name"`
+	AttrName      string `gorm:"column:attr_name;size:1024" validate:"env_name|required" json:"attr_name"`
+	AttrValue     string `gorm:"column:attr_value;type:text" validate:"env_value|required" json:"attr_value"`
+	IsChange      bool   `gorm:"column:is_change" validate:"is_change|bool" json:"is_change"`
+	Scope         string `gorm:"column:scope;default:'outer'" validate:"scope|in:outer,inner,both" json:"scope"`
+}
+
+//TableName 表名
+func (t *TenantServiceEnvVar) TableName() string {
+
+	return "tenant_services_envs"
+}
+
+//TenantServiceLBMappingPort stream应用端口映射情况
+type TenantServiceLBMappingPort struct {
+	Model
+	ServiceID string `gorm:"column:service_id;size:32"`
+	//负载均衡VS使用端口
+	Port int `gorm:"column:port;unique_index"`
+	//此字段废除
+	//	IP string `gorm:"column:ip"`
+	//应用原端口
+	ContainerPort int `gorm:"column:container_port"`
+}
+
+//TableName 表名
+func (t *TenantServiceLBMappingPort) TableName() string {
+	return "tenant_lb_mapping_port"
+}
+
+//TenantServiceLable 应用高级标签
+type TenantServiceLable struct {
+	Model
+	ServiceID  string `gorm:"column:service_id;size:32"`
+	LabelKey   string `gorm:"column:label_key;size:50"`
+	LabelValue string `gorm:"column:label_value;size:50"`
+}
+
+//TableName 表名
+func (t *TenantServiceLable) TableName() string {
+	return "tenant_services_label"
+}
+
+//TenantServiceMonitor custom service monitor
+type TenantServiceMonitor struct {
+	Model
+	TenantID        string `gorm:"column:tenant_id;size:40;unique_index:unique_tenant_id_name" json:"tenant_id"`
+	ServiceID       string `gorm:"column:service_id;size:40" json:"service_id"`
+	Name            string `gorm:"column:name;size:40;unique_index:unique_tenant_id_name" json:"name"`
+	ServiceShowName string `gorm:"column:service_show_name" json:"service_show_name"`
+	Port            int    `gorm:"column:port;size:5" json:"port"`
+	Path            string `gorm:"column:path;size:255" json:"path"`
+	Interval        string `gorm:"column:interval;size:20" json:"interval"`
+}
+
+// TableName returns table name of TenantServiceMonitor
+func (TenantServiceMonitor) TableName() string {
+	return "tenant_services_monitor"
+}
+
+//TenantServiceMountRelation 应用挂载依赖纪录
+type TenantServiceMountRelation struct {
+	Model
+	TenantID        string `gorm:"column:tenant_id;size:32" json:"tenant_id" validate:"tenant_id|between:30,33"`
+	ServiceID       string `gorm:"column:service_id;size:32" json:"service_id" validate:"service_id|between:30,33"`
+	DependServiceID string `gorm:"column:dep_service_id;size:32" json:"dep_service_id" validate:"dep_service_id|between:30,33"`
+	//挂载路径(挂载应用可自定义)
+	VolumePath string `gorm:"column:mnt_name" json:"volume_path" validate:"volume_path|required"`
+	//主机路径(依赖应用的共享存储对应的主机路径)
+	HostPath string `gorm:"column:mnt_dir" json:"host_path" validate:"host_path"`
+	//存储名称(依赖应用的共享存储对应的名称)
+	VolumeName string `gorm:"column:volume_name;size:40" json:"volume_name" validate:"volume_name|required"`
+	VolumeType string `gorm:"column:volume_type" json:"volume_type" validate:"volume_type|required"`
+}
+
+//TableName 表名
+func (t *TenantServiceMountRelation) TableName() string {
+	return "tenant_services_mnt_relation"
+}
+
+//TenantServicePluginRelation TenantServicePluginRelation
+type TenantServicePluginRelation struct {
+	Model
+	VersionID   string `gorm:"column:version_id;size:32" json:"version_id"`
+	PluginID    string `gorm:"column:plugin_id;size:32" json:"plugin_id"`
+	ServiceID   string `gorm:"column:service_id;size:32" json:"service_id"`
+	PluginModel string `gorm:"column:plugin_model;size:24" json:"plugin_model"`
+	// container default cpu  v3.5.1 add
+	ContainerCPU int `gorm:"column:container_cpu;default:125" json:"container_cpu"`
+	// container default memory  v3.5.1 add
+	ContainerMemory int  `gorm:"column:container_memory;default:64" json:"container_memory"`
+	Switch          bool `gorm:"column:switch;default:false" json:"switch"`
+}
+
+//TableName table name
+func (t *TenantServicePluginRelation) TableName() string {
+	return "tenant_service_plugin_relation"
+}
+
+//TenantServiceProbe 应用探针信息
+type TenantServiceProbe struct {
+	Model
+	ServiceID string `gorm:"column:service_id;size:32" json:"service_id" validate:"service_id|between:30,33"`
+	ProbeID   string `gorm:"column:probe_id;size:32" json:"probe_id" validate:"probe_id|between:30,33"`
+	Mode      string `gorm:"column:mode;default:'liveness'" json:"mode" validate:"mode"`
+	Scheme    string `gorm:"column:scheme;default:'scheme'" json:"scheme" validate:"scheme"`
+	Path      string `gorm:"column:path" json:"path" validate:"path"`
+	Port      int    `gorm:"column:port;size:5;default:80" json:"port" validate:"port|required|numeric_between:1,65535"`
+	Cmd       string `gorm:"column:cmd;size:150" json:"cmd" validate:"cmd"`
+	//http请求头，key=value,key2=value2
+	HTTPHeader string `gorm:"column:http_header;size:300" json:"http_header" validate:"http_header"`
+	//初始化等候时间
+	InitialDelaySecond int `gorm:"column:initial_delay_second;size:2;default:4" json:"initial_delay_second" validate:"initial_delay_second"`
+	//检测间隔时间
+	PeriodSecond int `gorm:"column:period_second;size:2;default:3" json:"period_second" validate:"period_second"`
+	//检测超时时间
+	TimeoutSecond int `gorm:"column:timeout_second;size:3;default:5" json:"timeout_second" validate:"timeout_second"`
+	//是否启用
+	IsUsed *int `gorm:"column:is_used;size:1;default:1" json:"is_used" validate:"is_used"`
+	//标志为失败的检测次数
+	FailureThreshold int `gorm:"column:failure_threshold;size:2;default:3" json:"failure_threshold" validate:"failure_threshold"`
+	//标志为成功的检测次数
+	SuccessThreshold int    `gorm:"column:success_threshold;size:2;default:1" json:"success_threshold" validate:"success_threshold"`
+	FailureAction    string `gorm:"column:failure_action;" json:"failure_action" validate:"failure_action"`
+}
+
+//TableName 表名
+func (t *TenantServiceProbe) TableName() string {
+	return "tenant_services_probe"
+}
+
+//TenantServiceRelation 应用依赖关系
+type TenantServiceRelation struct {
+	Model
+	TenantID          string `gorm:"column:tenant_id;size:32" validate:"tenant_id" json:"tenant_id"`
+	ServiceID         string `gorm:"column:service_id;size:32" validate:"service_id" json:"service_id"`
+	DependServiceID   string `gorm:"column:dep_service_id;size:32" validate:"depend_service_id" json:"depend_service_id"`
+	DependServiceType string `gorm:"column:dep_service_type" validate:"dep_service_type" json:"dep_service_type"`
+	DependOrder       int    `gorm:"column:dep_order" validate:"dep_order" json:"dep_order"`
+}
+
+//TableName 表名
+func (t *TenantServiceRelation) TableName() string {
+	return "tenant_services_relation"
+}
+
+// TenantServiceResourcePolicy the right-sizing policy of component, the resource recommendations
+// are applied in the low-traffic windows of Cron in the Timezone if AutoApply is true.
+type TenantServiceResourcePolicy struct {
+	Model
+	ServiceID     string     `gorm:"column:service_id;unique;size:32" json:"service_id"`
+	AutoApply     bool       `gorm:"column:auto_apply" json:"auto_apply"`
+	Cron          string     `gorm:"column:cron;size:64" json:"cron"`
+	Timezone      string     `gorm:"column:timezone;size:64" json:"timezone"`
+	LastApplyTime *time.Time `gorm:"column:last_apply_time" json:"last_apply_time"`
+}
+
+// TableName -
+func (t *TenantServiceResourcePolicy) TableName() string {
+	return "tenant_services_resource_policies"
+}
+
+// TenantServiceScalingRecords -
+type TenantServiceScalingRecords struct {
+	Model
+	ServiceID   string    `gorm:"column:service_id" json:"-"`
+	RuleID      string    `gorm:"column:rule_id" json:"rule_id"`
+	EventName   string    `gorm:"column:event_name;not null" json:"record_id"`
+	RecordType  string    `gorm:"column:record_type" json:"record_type"`
+	Reason      string    `gorm:"column:reason" json:"reason"`
+	Count       int32     `gorm:"column:count" json:"count"`
+	Description string    `gorm:"column:description;size:1023" json:"description"`
+	Operator    string    `gorm:"column:operator" json:"operator"`
+	LastTime    time.Time `gorm:"column:last_time" json:"last_time"`
+	// Metric the metric triggered the scaling
+	Metric string `gorm:"column:metric;size:255" json:"metric"`
+}
+
+// TableName -
+func (t *TenantServiceScalingRecords) TableName() string {
+	return "tenant_services_scaling_records"
+}
+
+// TenantServiceScalingSchedule the scheduled scaling rule of component,
+// the replicas of component are set to Replicas at the times of Cron in the Timezone.
+// The min replicas of the enabled autoscaler rules are adjusted instead if the component has hpa.
+type TenantServiceScalingSchedule struct {
+	Model
+	ScheduleID       string     `gorm:"column:schedule_id;unique;size:32" json:"schedule_id"`
+	ServiceID        string     `gorm:"column:service_id;size:32;index" json:"service_id"`
+	Enable           bool       `gorm:"column:enable" json:"enable"`
+	Cron             string     `gorm:"column:cron;size:64" json:"cron"`
+	Timezone         string     `gorm:"column:timezone;size:64" json:"timezone"`
+	Replicas         int        `gorm:"column:replicas" json:"replicas"`
+	LastScheduleTime *time.Time `gorm:"column:last_schedule_time" json:"last_schedule_time"`
+}
+
+// TableName -
+func (t *TenantServiceScalingSchedule) TableName() string {
+	return "tenant_services_scaling_schedules"
+}
+
+//TenantServiceVolume 应用持久化纪录
+type TenantServiceVolume struct {
+	Model
+	ServiceID string `gorm:"column:service_id;size:32" json:"service_id"`
+	//服务类型
+	Category string `gorm:"column:category;size:50" json:"category"`
+	//存储类型（share,local,tmpfs）
+	VolumeType string `gorm:"column:volume_type;size:64" json:"volume_type"`
+	//存储名称
+	VolumeName string `gorm:"column:volume_name;size:40" json:"volume_name"`
+	//主机地址
+	HostPath string `gorm:"column:host_path" json:"host_path"`
+	//挂载地址
+	VolumePath string `gorm:"column:volume_path" json:"volume_path"`
+	//是否只读
+	IsReadOnly bool `gorm:"column:is_read_only;default:false" json:"is_read_only"`
+	// VolumeCapacity 存储大小
+	VolumeCapacity int64 `gorm:"column:volume_capacity" json:"volume_capacity"`
+	// AccessMode 读写模式（Important! A volume can only be mounted using one access mode at a time, even if it supports many. For example, a GCEPersistentDisk can be mounted as ReadWriteOnce by a single node or ReadOnlyMany by many nodes, but not at the same time. #https://kubernetes.io/docs/concepts/storage/persistent-volumes/#access-modes）
+	AccessMode string `gorm:"column:access_mode" json:"access_mode"`
+	// SharePolicy 共享模式
+	SharePolicy string `gorm:"column:share_policy" json:"share_policy"`
+	// BackupPolicy 备份策略
+	BackupPolicy string `gorm:"column:backup_policy" json:"backup_policy"`
+	// ReclaimPolicy 回收策略
+	ReclaimPolicy string `json:"reclaim_policy"`
+	// AllowExpansion 是否支持扩展
+	AllowExpansion bool `gorm:"column:allow_expansion" json:"allow_expansion"`
+	// VolumeProviderName 使用的存储驱动别名
+	VolumeProviderName string `gorm:"collumn:volume_provider_name" json:"volume_provider_name"`
+}
+
+//TableName 表名
+func (t *TenantServiceVolume) TableName() string {
+	return "tenant_services_volume"
+}
+
+// TenantServiceVolumeType tenant service volume type
+type TenantServiceVolumeType struct {
+	Model
+	VolumeType         string `gorm:"column:volume_type; size:64" json:"volume_type"`
+	NameShow           string `gorm:"column:name_show; size:64" json:"name_show"`
+	CapacityValidation string `gorm:"column:capacity_validation; size:1024" json:"capacity_validation"`
+	Description        string `gorm:"column:description; size:1024" json:"description"`
+	AccessMode         string `gorm:"column:access_mode; size:128" json:"access_mode"`
+	BackupPolicy       string `gorm:"column:backup_policy; size:128" json:"backup_policy"`
+	ReclaimPolicy      string `gorm:"column:reclaim_policy; size:20" json:"reclaim_policy"`
+	SharePolicy        string `gorm:"share_policy; size:128" json:"share_policy"`
+	Provisioner        string `gorm:"provisioner; size:128" json:"provisioner"`
+	StorageClassDetail string `gorm:"storage_class_detail; size:2048" json:"storage_class_detail"`
+	Sort               int    `gorm:"sort; default:9999" json:"sort"`
+	Enable             bool   `gorm:"enable" json:"enable"`
+}
+
+//TableName 表名
+func (t *TenantServiceVolumeType) TableName() string {
+	return "tenant_services_volume_type"
+}
+
+//TenantServiceWebhook the git push webhook config of component.
+//the push events matched the branch or tag pattern trigger the source code build of the component.
+type TenantServiceWebhook struct {
+	Model
+	TenantID  string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	ServiceID string `gorm:"column:service_id;size:32;unique_index" json:"service_id"`
+	// Secret used to verify the signature or token of the webhook request
+	Secret string `gorm:"column:secret;size:128" json:"-"`
+	// BranchPattern comma separated glob patterns of branch, the branch of component is used if empty
+	BranchPattern string `gorm:"column:branch_pattern;size:255" json:"branch_pattern"`
+	// TagPattern comma separated glob patterns of tag, tag push is ignored if empty
+	TagPattern string `gorm:"column:tag_pattern;size:255" json:"tag_pattern"`
+	RepoURL    string `gorm:"column:repo_url;size:2047" json:"repo_url"`
+	Branch     string `gorm:"column:branch;size:255" json:"branch"`
+	Lang       string `gorm:"column:lang;size:40" json:"lang"`
+	Runtime    string `gorm:"column:runtime;size:40" json:"runtime"`
+	ServerType string `gorm:"column:server_type;size:40" json:"server_type"`
+	User       string `gorm:"column:user;size:255" json:"user"`
+	Password   string `gorm:"column:password;size:255" json:"-"`
+	// BuildEnvs json encoded build envs
+	BuildEnvs string `gorm:"column:build_envs;type:text" json:"-"`
+	// Action the action after build, eg. upgrade
+	Action string `gorm:"column:action;size:20" json:"action"`
+	Enable bool   `gorm:"column:enable;default:true" json:"enable"`
+}
+
+//TableName 表名
+func (t *TenantServiceWebhook) TableName() string {
+	return "tenant_services_webhook"
+}
+
+//TenantServices app service base info
+type TenantServices struct {
+	Model
+	TenantID  string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	ServiceID string `gorm:"column:service_id;size:32" json:"service_id"`
+	// 服务key
+	ServiceKey string `gorm:"column:service_key;size:32" json:"service_key"`
+	// 服务别名
+	ServiceAlias string `gorm:"column:service_alias;size:30" json:"service_alias"`
+	// service regist endpoint name(host name), used of statefulset
+	ServiceName string `gorm:"column:service_name;size:100" json:"service_name"`
+	// （This field is not currently used, use ExtendMethod）Service type now service support
+	ServiceType string `gorm:"column:service_type;size:32" json:"service_type"`
+	// 服务描述
+	Comment string `gorm:"column:comment" json:"comment"`
+	// 容器CPU权重
+	ContainerCPU int `gorm:"column:container_cpu;default:500" json:"container_cpu"`
+	// 容器最大内存
+	ContainerMemory int `gorm:"column:container_memory;default:128" json:"container_memory"`
+	//UpgradeMethod service upgrade controller type
+	//such as : `Rolling` `OnDelete`
+	UpgradeMethod string `gorm:"column:upgrade_method;default:'Rolling'" json:"upgrade_method"`
+	// 组件类型  component deploy type stateless_singleton/stateless_multiple/state_singleton/state_multiple
+	ExtendMethod string `gorm:"column:extend_method;default:'stateless';" json:"extend_method"`
+	// 节点数
+	Replicas int `gorm:"column:replicas;default:1" json:"replicas"`
+	// 部署版本
+	DeployVersion string `gorm:"column:deploy_version" json:"deploy_version"`
+	// 服务分类：application,cache,store
+	Category string `gorm:"column:category" json:"category"`
+	// 服务当前状态：undeploy,running,closed,unusual,starting,checking,stoping(deprecated)
+	CurStatus string `gorm:"column:cur_status;default:'undeploy'" json:"cur_status"`
+	// 计费状态 为1 计费，为0不计费 (deprecated)
+	Status int `gorm:"column:status;default:0" json:"status"`
+	// 最新操作ID
+	EventID string `gorm:"column:event_id" json:"event_id"`
+	// 租户ID
+	Namespace string `gorm:"column:namespace" json:"namespace"`
+	// 更新时间
+	UpdateTime time.Time `gorm:"column:update_time" json:"update_time"`
+	// 服务创建类型cloud云市服务,assistant云帮服务
+	ServiceOrigin string `gorm:"column:service_origin;default:'assistant'" json:"service_origin"`
+	// kind of service. option: internal, third_party
+	Kind string `gorm:"column:kind;default:'internal'" json:"kind"`
+	// service bind appID
+	AppID string `gorm:"column:app_id" json:"app_id"`
+}
+
+//TableName 表名
+func (t *TenantServices) TableName() string {
+	return "tenant_services"
+}
+
+//TenantServicesDelete 已删除的应用表
+type TenantServicesDelete struct {
+	Model
+	TenantID  string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	ServiceID string `gorm:"column:service_id;size:32" json:"service_id"`
+	// 服务key
+	ServiceKey string `gorm:"column:service_key;size:32" json:"service_key"`
+	// 服务别名
+	ServiceAlias string `gorm:"column:service_alias;size:30" json:"service_alias"`
+	// service regist endpoint name(host name), used of statefulset
+	ServiceName string `gorm:"column:service_name;size:100" json:"service_name"`
+	// Service type now service support stateless_singleton/stateless_multiple/state_singleton/state_multiple
+	ServiceType string `gorm:"column:service_type;size:20" json:"service_type"`
+	// 服务描述
+	Comment string `gorm:"column:comment" json:"comment"`
+	// 容器CPU权重
+	ContainerCPU int `gorm:"column:container_cpu;default:500" json:"container_cpu"`
+	// 容器最大内存
+	ContainerMemory int `gorm:"column:container_memory;default:128" json:"container_memory"`
+	//UpgradeMethod service upgrade controller type
+	//such as : `Rolling` `OnDelete`
+	UpgradeMethod string `gorm:"column:upgrade_method;default:'Rolling'" json:"upgrade_method"`
+	// 扩容方式；0:无状态；1:有状态；2:分区
+	ExtendMethod string `gorm:"column:extend_method;default:'stateless';" json:"extend_method"`
+	// 节点数
+	Replicas int `gorm:"column:replicas;default:1" json:"replicas"`
+	// 部署版本
+	DeployVersion string `gorm:"column:deploy_version" json:"deploy_version"`
+	// 服务分类：application,cache,store
+	Category string `gorm:"column:category" json:"category"`
+	// 服务当前状态：undeploy,running,closed,unusual,starting,checking,stoping(deprecated)
+	CurStatus string `gorm:"column:cur_status;default:'undeploy'" json:"cur_status"`
+	// 计费状态 为1 计费，为0不计费 (deprecated)
+	Status int `gorm:"column:status;default:0" json:"status"`
+	// 最新操作ID
+	EventID string `gorm:"column:event_id" json:"event_id"`
+	// 租户ID
+	Namespace string `gorm:"column:namespace" json:"namespace"`
+	// 更新时间
+	UpdateTime time.Time `gorm:"column:update_time" json:"update_time"`
+	// 服务创建类型cloud云市服务,assistant云帮服务
+	ServiceOrigin string `gorm:"column:service_origin;default:'assistant'" json:"service_origin"`
+	// kind of service. option: internal, third_party
+	Kind string `gorm:"column:kind;default:'internal'" json:"kind"`
+	// service bind appID
+	AppID string `gorm:"column:app_id" json:"app_id"`
+}
+
+//TableName 表名
+func (t *TenantServicesDelete) TableName() string {
+	return "tenant_services_delete"
+}
+
+//TenantServicesPort 应用端口信息
+type TenantServicesPort struct {
+	Model
+	TenantID       string `gorm:"column:tenant_id;size:32" validate:"tenant_id|between:30,33" json:"tenant_id"`
+	ServiceID      string `gorm:"column:service_id;size:32" validate:"service_id|between:30,33" json:"service_id"`
+	ContainerPort  int    `gorm:"column:container_port" validate:"container_port|required|numeric_between:1,65535" json:"container_port"`
+	MappingPort    int    `gorm:"column:mapping_port" validate:"mapping_port|required|numeric_between:1,65535" json:"mapping_port"`
+	Protocol       string `gorm:"column:protocol" validate:"protocol|required|in:http,https,tcp,grpc,udp,mysql" json:"protocol"`
+	PortAlias      string `gorm:"column:port_alias" validate:"port_alias|required|alpha_dash" json:"port_alias"`
+	IsInnerService *bool  `gorm:"column:is_inner_service" validate:"is_inner_service|bool" json:"is_inner_service"`
+	IsOuterService *bool  `gorm:"column:is_outer_service" validate:"is_outer_service|bool" json:"is_outer_service"`
+	K8sServiceName string `gorm:"column:k8s_service_name" json:"k8s_service_name"`
+}
+
+//TableName 表名
+func (t *TenantServicesPort) TableName() string {
+	return "tenant_services_port"
+}
+
+//TenantServicesStreamPluginPort 绑定stream类型插件后端口映射信息
+type TenantServicesStreamPluginPort struct {
+	Model
+	TenantID      string `gorm:"column:tenant_id;size:32" validate:"tenant_id|between:30,33" json:"tenant_id"`
+	ServiceID     string `gorm:"column:service_id;size:32" validate:"service_id|between:30,33" json:"service_id"`
+	PluginModel   string `gorm:"column:plugin_model;size:24" json:"plugin_model"`
+	ContainerPort int    `gorm:"column:container_port" validate:"container_port|required|numeric_between:1,65535" json:"container_port"`
+	PluginPort    int    `gorm:"column:plugin_port" json:"plugin_port"`
+}
+
+//TableName 表名
+func (t *TenantServicesStreamPluginPort) TableName() string {
+	return "tenant_services_stream_plugin_port"
+}
+
+//Tenants 租户信息
+type Tenants struct {
+	Model
+	Name        string `gorm:"column:name;size:40;unique_index"`
+	UUID        string `gorm:"column:uuid;size:33;unique_index"`
+	EID         string `gorm:"column:eid"`
+	LimitMemory int    `gorm:"column:limit_memory"`
+	Status      string `gorm:"column:status;default:'normal'"`
+}
+
+//TableName 返回租户表名称
+func (t *Tenants) TableName() string {
+	return "tenants"
+}
+
+// ThirdPartySvcDiscoveryCfg s a persistent object for table
+// 3rd_party_svc_discovery_cfg. 3rd_party_svc_discovery_cfg contains
+// service discovery center configuration for third party service.
+type ThirdPartySvcDiscoveryCfg struct {
+	Model
+	ServiceID string `gorm:"column:service_id;size:32"`
+	Type      string `gorm:"column:type"`
+	Servers   string `gorm:"column:servers"`
+	Key       string `gorm:"key"`
+	Username  string `gorm:"username"`
+	Password  string `gorm:"password"`
+}
+
+// TableName returns table name of ThirdPartySvcDiscoveryCfg.
+func (ThirdPartySvcDiscoveryCfg) TableName() string {
+	return "tenant_service_3rd_party_discovery_cfg"
+}
+
+//VersionInfo version info struct
+type VersionInfo struct {
+	Model
+	BuildVersion string `gorm:"column:build_version;size:40" json:"build_version"` //唯一
+	EventID      string `gorm:"column:event_id;size:40" json:"event_id"`
+	ServiceID    string `gorm:"column:service_id;size:40" json:"service_id"`
+	Kind         string `gorm:"column:kind;size:40" json:"kind"` //kind
+	//DeliveredType app version delivered type
+	//image: this is a docker image
+	//slug: this is a source code tar file
+	DeliveredType string `gorm:"column:delivered_type;size:40" json:"delivered_type"`  //kind
+	DeliveredPath string `gorm:"column:delivered_path;size:250" json:"delivered_path"` //交付物path
+	ImageName     string `gorm:"column:image_name;size:250" json:"image_name"`         //运行镜像名称
+	Cmd           string `gorm:"column:cmd;size:2048" json:"cmd"`                      //启动命令
+	RepoURL       string `gorm:"column:repo_url;size:2047" json:"repo_url"`
+	CodeVersion   string `gorm:"column:code_version;size:40" json:"code_version"`
+	CodeBranch    string `gorm:"column:code_branch;size:40" json:"code_branch"`
+	CommitMsg     string `gorm:"column:code_commit_msg;size:1024" json:"code_commit_msg"`
+	Author        string `gorm:"column:code_commit_author;size:40" json:"code_commit_author"`
+	//FinalStatus app version status
+	//success: version available
+	//failure: build failure
+	//lost: there is no delivered
+	FinalStatus string    `gorm:"column:final_status;size:40" json:"final_status"`
+	FinishTime  time.Time `gorm:"column:finish_time;" json:"finish_time"`
+	PlanVersion string    `gorm:"column:plan_version;size:250" json:"plan_version"`
+}
+
+//TableName 表名
+func (t *VersionInfo) TableName() string {
+	return "tenant_service_version"
+}
+
+//VolumeType 存储类型
+type VolumeType string
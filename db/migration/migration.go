@@ -0,0 +1,313 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	//lockStaleAfter a lock older than this is considered left over by a crashed process
+	lockStaleAfter = 10 * time.Minute
+	//lockWaitTimeout how long to wait for another process to finish its migrations
+	lockWaitTimeout = 15 * time.Minute
+	lockRetryPeriod = 2 * time.Second
+)
+
+//ErrDeferred is returned by the up of a migration that can not be applied yet,
+//the migration is not recorded and is retried by the next run.
+var ErrDeferred = errors.New("migration deferred")
+
+//Migration is a versioned schema or data change of the region db.
+//Down may be nil, which means the migration can not be reverted.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error
+}
+
+//Status is the state of a migration in the db
+type Status struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   *time.Time
+	//Unknown means the version is applied in the db, but not known by this binary,
+	//usually because the db has been migrated by a newer release.
+	Unknown bool
+}
+
+type schemaMigration struct {
+	Version     int       `gorm:"column:version;primary_key;AUTO_INCREMENT:false"`
+	Description string    `gorm:"column:description;size:255"`
+	AppliedAt   time.Time `gorm:"column:applied_at"`
+}
+
+//TableName returns the table name of schema migration
+func (s *schemaMigration) TableName() string {
+	return "region_schema_migrations"
+}
+
+type schemaLock struct {
+	ID       int       `gorm:"column:id;primary_key;AUTO_INCREMENT:false"`
+	Locked   bool      `gorm:"column:locked"`
+	Owner    string    `gorm:"column:owner;size:255"`
+	LockedAt time.Time `gorm:"column:locked_at"`
+}
+
+//TableName returns the table name of schema lock
+func (s *schemaLock) TableName() string {
+	return "region_schema_lock"
+}
+
+//Migrator applies and reverts migrations
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	owner      string
+}
+
+//NewMigrator creates a migrator with the migrations of the region db
+func NewMigrator(db *gorm.DB) *Migrator {
+	return newMigrator(db, migrations)
+}
+
+func newMigrator(db *gorm.DB, ms []Migration) *Migrator {
+	sorted := make([]Migration, len(ms))
+	copy(sorted, ms)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	hostname, _ := os.Hostname()
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+		owner:      fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}
+}
+
+//Status returns the status of all known migrations and of the applied migrations unknown to this binary
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.ensureTables(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	var status []Status
+	for _, mi := range m.migrations {
+		s := Status{Version: mi.Version, Description: mi.Description}
+		if a, ok := applied[mi.Version]; ok {
+			s.Applied = true
+			at := a.AppliedAt
+			s.AppliedAt = &at
+			delete(applied, mi.Version)
+		}
+		status = append(status, s)
+	}
+	for _, a := range applied {
+		at := a.AppliedAt
+		status = append(status, Status{Version: a.Version, Description: a.Description, Applied: true, AppliedAt: &at, Unknown: true})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+//Up applies the pending migrations up to and including version target, 0 means all of them.
+func (m *Migrator) Up(target int) error {
+	if err := m.ensureTables(); err != nil {
+		return err
+	}
+	if err := m.lock(); err != nil {
+		return err
+	}
+	defer m.unlock()
+
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	for version := range applied {
+		if !m.known(version) {
+			logrus.Warningf("migration %d is applied, but unknown by this binary", version)
+		}
+	}
+	for _, mi := range m.migrations {
+		if target > 0 && mi.Version > target {
+			break
+		}
+		if _, ok := applied[mi.Version]; ok {
+			continue
+		}
+		logrus.Infof("applying migration %d: %s", mi.Version, mi.Description)
+		if err := m.run(mi, true); err != nil {
+			if err == ErrDeferred {
+				logrus.Warningf("migration %d is deferred, it will be applied by the next run", mi.Version)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+//Down reverts the last steps applied migrations
+func (m *Migrator) Down(steps int) error {
+	if err := m.ensureTables(); err != nil {
+		return err
+	}
+	if err := m.lock(); err != nil {
+		return err
+	}
+	defer m.unlock()
+
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+		mi := m.migrations[i]
+		if _, ok := applied[mi.Version]; !ok {
+			continue
+		}
+		if mi.Down == nil {
+			return fmt.Errorf("migration %d(%s) can not be reverted", mi.Version, mi.Description)
+		}
+		logrus.Infof("reverting migration %d: %s", mi.Version, mi.Description)
+		if err := m.run(mi, false); err != nil {
+			return err
+		}
+		steps--
+	}
+	return nil
+}
+
+//run runs the migration and records it in one transaction.
+//mysql commits ddl implicitly, so a failed migration may be left half applied on mysql.
+func (m *Migrator) run(mi Migration, up bool) error {
+	tx := m.db.Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+	var err error
+	if up {
+		if err = mi.Up(tx); err == nil {
+			err = tx.Create(&schemaMigration{Version: mi.Version, Description: mi.Description, AppliedAt: time.Now()}).Error
+		}
+	} else {
+		if err = mi.Down(tx); err == nil {
+			err = tx.Where("version = ?", mi.Version).Delete(&schemaMigration{}).Error
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		if err == ErrDeferred {
+			return err
+		}
+		return fmt.Errorf("migration %d(%s): %v", mi.Version, mi.Description, err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	// a long running migration must not let the lock go stale
+	return m.db.Model(&schemaLock{}).Where("id = ? and owner = ?", 1, m.owner).Update("locked_at", time.Now()).Error
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, mi := range m.migrations {
+		if mi.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) applied() (map[int]schemaMigration, error) {
+	var records []schemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+func (m *Migrator) ensureTables() error {
+	for _, t := range []interface{}{&schemaMigration{}, &schemaLock{}} {
+		if m.db.HasTable(t) {
+			continue
+		}
+		// the table may be created by another component at the same time
+		if err := m.db.CreateTable(t).Error; err != nil && !m.db.HasTable(t) {
+			return err
+		}
+	}
+	var count int
+	if err := m.db.Model(&schemaLock{}).Where("id = ?", 1).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := m.db.Create(&schemaLock{ID: 1, LockedAt: time.Now()}).Error; err != nil {
+			if err := m.db.Model(&schemaLock{}).Where("id = ?", 1).Count(&count).Error; err != nil || count == 0 {
+				return fmt.Errorf("create schema lock: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+//lock takes the migration lock, waits if another process holds it
+func (m *Migrator) lock() error {
+	deadline := time.Now().Add(lockWaitTimeout)
+	for {
+		now := time.Now()
+		res := m.db.Model(&schemaLock{}).
+			Where("id = ? and (locked = ? or locked_at < ?)", 1, false, now.Add(-lockStaleAfter)).
+			Updates(map[string]interface{}{"locked": true, "owner": m.owner, "locked_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 1 {
+			return nil
+		}
+		if now.After(deadline) {
+			var l schemaLock
+			m.db.Where("id = ?", 1).Find(&l)
+			return fmt.Errorf("wait for the migration lock held by %s since %s timeout", l.Owner, l.LockedAt.Format(time.RFC3339))
+		}
+		logrus.Infof("migration lock is held by another process, waiting")
+		time.Sleep(lockRetryPeriod)
+	}
+}
+
+func (m *Migrator) unlock() {
+	if err := m.db.Model(&schemaLock{}).Where("id = ? and owner = ?", 1, m.owner).
+		Updates(map[string]interface{}{"locked": false, "owner": ""}).Error; err != nil {
+		logrus.Errorf("release the migration lock: %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+func openTestDB(t *testing.T) (*gorm.DB, func()) {
+	dir, err := ioutil.TempDir("", "migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open("sqlite3", filepath.Join(dir, "region.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	db.DB().SetMaxOpenConns(1)
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			Version:     2,
+			Description: "add foo.bar",
+			Up:          func(tx *gorm.DB) error { return tx.Exec("alter table foo add column bar varchar(32)").Error },
+			Down:        nil,
+		},
+		{
+			Version:     1,
+			Description: "create foo",
+			Up:          func(tx *gorm.DB) error { return tx.Exec("create table foo (id integer primary key)").Error },
+			Down:        func(tx *gorm.DB) error { return tx.Exec("drop table foo").Error },
+		},
+		{
+			Version:     3,
+			Description: "create baz",
+			Up:          func(tx *gorm.DB) error { return tx.Exec("create table baz (id integer primary key)").Error },
+			Down:        func(tx *gorm.DB) error { return tx.Exec("drop table baz").Error },
+		},
+	}
+}
+
+func appliedVersions(t *testing.T, m *Migrator) []int {
+	status, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int
+	for _, s := range status {
+		if s.Applied {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func TestMigratorUpAndDown(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+	m := newMigrator(db, testMigrations())
+
+	if err := m.Up(2); err != nil {
+		t.Fatal(err)
+	}
+	if got := appliedVersions(t, m); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("expected versions [1 2] applied, but got %v", got)
+	}
+	if db.HasTable("baz") {
+		t.Error("expected baz not created")
+	}
+	// applying again only runs the pending ones
+	if err := m.Up(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := appliedVersions(t, m); len(got) != 3 {
+		t.Fatalf("expected all versions applied, but got %v", got)
+	}
+
+	if err := m.Down(1); err != nil {
+		t.Fatal(err)
+	}
+	if db.HasTable("baz") {
+		t.Error("expected baz dropped")
+	}
+	if err := m.Down(1); err == nil {
+		t.Error("expected an error reverting an irreversible migration")
+	}
+	if got := appliedVersions(t, m); len(got) != 2 {
+		t.Errorf("expected versions [1 2] applied, but got %v", got)
+	}
+}
+
+func TestMigratorFailedMigration(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+	ms := append(testMigrations(), Migration{
+		Version:     4,
+		Description: "broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("create table qux (id integer primary key)").Error; err != nil {
+				return err
+			}
+			return tx.Exec("alter table nothing add column bar varchar(32)").Error
+		},
+	})
+	m := newMigrator(db, ms)
+	if err := m.Up(0); err == nil {
+		t.Fatal("expected an error applying a broken migration")
+	}
+	if got := appliedVersions(t, m); len(got) != 3 {
+		t.Errorf("expected versions [1 2 3] applied, but got %v", got)
+	}
+	if db.HasTable("qux") {
+		t.Error("expected the broken migration rolled back")
+	}
+	var l schemaLock
+	if err := db.Where("id = ?", 1).Find(&l).Error; err != nil {
+		t.Fatal(err)
+	}
+	if l.Locked {
+		t.Error("expected the lock released after a failed migration")
+	}
+}
+
+func TestMigratorDeferredMigration(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+	ready := false
+	ms := append(testMigrations(), Migration{
+		Version:     4,
+		Description: "needs a key",
+		Up: func(tx *gorm.DB) error {
+			if !ready {
+				return ErrDeferred
+			}
+			return tx.Exec("create table qux (id integer primary key)").Error
+		},
+	}, Migration{
+		Version:     5,
+		Description: "create quux",
+		Up:          func(tx *gorm.DB) error { return tx.Exec("create table quux (id integer primary key)").Error },
+	})
+	m := newMigrator(db, ms)
+	if err := m.Up(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := appliedVersions(t, m); len(got) != 4 || got[3] != 5 {
+		t.Fatalf("expected versions [1 2 3 5] applied, but got %v", got)
+	}
+
+	ready = true
+	if err := m.Up(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := appliedVersions(t, m); len(got) != 5 {
+		t.Errorf("expected the deferred migration applied, but got %v", got)
+	}
+	if !db.HasTable("qux") {
+		t.Error("expected qux created")
+	}
+}
+
+func TestMigratorStatusUnknown(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+	if err := newMigrator(db, testMigrations()).Up(0); err != nil {
+		t.Fatal(err)
+	}
+	// an older binary only knows the first migration
+	status, err := newMigrator(db, testMigrations()[1:2]).Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 3 {
+		t.Fatalf("expected 3 status, but got %d", len(status))
+	}
+	if status[0].Unknown || !status[1].Unknown || !status[2].Unknown {
+		t.Errorf("expected versions 2 and 3 unknown, but got %+v", status)
+	}
+}
+
+func TestMigratorLock(t *testing.T) {
+	oldTimeout, oldPeriod := lockWaitTimeout, lockRetryPeriod
+	lockWaitTimeout, lockRetryPeriod = 100*time.Millisecond, 10*time.Millisecond
+	defer func() { lockWaitTimeout, lockRetryPeriod = oldTimeout, oldPeriod }()
+
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+	holder := newMigrator(db, testMigrations())
+	if err := holder.ensureTables(); err != nil {
+		t.Fatal(err)
+	}
+	if err := holder.lock(); err != nil {
+		t.Fatal(err)
+	}
+	m := newMigrator(db, testMigrations())
+	m.owner = "another"
+	if err := m.Up(0); err == nil {
+		t.Fatal("expected an error while the lock is held")
+	}
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Errorf("expected nothing applied, but got %v", got)
+	}
+
+	// a stale lock is taken over
+	if err := db.Model(&schemaLock{}).Where("id = ?", 1).Update("locked_at", time.Now().Add(-2*lockStaleAfter)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(0); err != nil {
+		t.Fatal(err)
+	}
+	holder.unlock()
+}
+
+func TestBaseline(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+	m := NewMigrator(db)
+	if err := m.Up(0); err != nil {
+		t.Fatal(err)
+	}
+	for _, md := range baseModels() {
+		if !db.HasTable(md) {
+			t.Errorf("expected table %s created", md.TableName())
+		}
+	}
+	// baseline must be idempotent for the tables created by old releases
+	if err := applyBaseline(db); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBaselineFrozen(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+	m := NewMigrator(db)
+	if err := m.Up(1); err != nil {
+		t.Fatal(err)
+	}
+	// the columns added after the baseline are created by their own migrations
+	columns := []struct{ table, column string }{
+		{"app_config_group", "source_type"},
+		{"applications", "tracing_opencensus_port"},
+	}
+	for _, c := range columns {
+		if db.Dialect().HasColumn(c.table, c.column) {
+			t.Errorf("expected column %s.%s not created by the baseline", c.table, c.column)
+		}
+	}
+	if err := m.Up(0); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range columns {
+		if !db.Dialect().HasColumn(c.table, c.column) {
+			t.Errorf("expected column %s.%s created", c.table, c.column)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"fmt"
+
+	"github.com/goodrain/rainbond/db/encryption"
+	"github.com/goodrain/rainbond/db/migration/baseline"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+//migrations of the region db, ordered by version.
+//Append a new migration with the next version for every change of db/model, never change a released one.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "baseline schema",
+		Up:          applyBaseline,
+	},
+	{
+		Version:     2,
+		Description: "fill the default governance mode of applications",
+		Up: func(tx *gorm.DB) error {
+			return tx.Model(&model.Application{}).Where("governance_mode is null or governance_mode = ?", "").
+				UpdateColumn("governance_mode", model.GovernanceModeBuildInServiceMesh).Error
+		},
+		// the filled rows can not be told apart from the ones set by users, and the default is harmless
+		Down: func(tx *gorm.DB) error { return nil },
+	},
+	{
+		Version:     3,
+		Description: "create tenant data keys and widen the columns of secrets",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.CreateTable(&model.TenantDataKey{}).Error; err != nil {
+				return err
+			}
+			// encrypted values are longer than the plain ones
+			if err := modifyColumn(tx, "app_config_group_item", "item_value", "text"); err != nil {
+				return err
+			}
+			return modifyColumn(tx, "tenant_plugin_version_env", "env_value", "text")
+		},
+		// the widened columns are kept, narrowing them may truncate values
+		Down: func(tx *gorm.DB) error {
+			count, err := encryption.CountEncrypted(tx)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("%d secrets are still encrypted by the data keys", count)
+			}
+			return tx.DropTable(&model.TenantDataKey{}).Error
+		},
+	},
+	{
+		Version:     4,
+		Description: "encrypt the secrets at rest",
+		Up: func(tx *gorm.DB) error {
+			keyring := encryption.GetKeyring(tx)
+			if keyring == nil {
+				// not recorded, so the secrets are encrypted once the master key is configured
+				logrus.Warningf("no master key configured, the secrets are kept in plain text until it is configured")
+				return ErrDeferred
+			}
+			_, err := keyring.EncryptAll(tx)
+			return err
+		},
+		Down: func(tx *gorm.DB) error {
+			keyring := encryption.GetKeyring(tx)
+			if keyring == nil {
+				count, err := encryption.CountEncrypted(tx)
+				if err != nil {
+					return err
+				}
+				if count > 0 {
+					return fmt.Errorf("the master key is required to decrypt %d secrets", count)
+				}
+				return nil
+			}
+			_, err := keyring.DecryptAll(tx)
+			return err
+		},
+	},
+	{
+		Version:     5,
+		Description: "add the external source and file deploy type of config groups",
+		Up: func(tx *gorm.DB) error {
+			for _, c := range []struct{ column, typ string }{
+				{"source_type", "varchar(32) default 'db'"},
+				{"source_path", "varchar(255)"},
+				{"refresh_interval", "integer"},
+				{"mount_path", "varchar(255)"},
+				{"restart_on_change", "boolean"},
+			} {
+				if err := addColumn(tx, "app_config_group", c.column, c.typ); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"source_type", "source_path", "refresh_interval", "mount_path", "restart_on_change"} {
+				if err := dropColumn(tx, "app_config_group", column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     6,
+		Description: "add the opencensus port of the tracing collector of applications",
+		Up: func(tx *gorm.DB) error {
+			return addColumn(tx, "applications", "tracing_opencensus_port", "integer default 55678")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumn(tx, "applications", "tracing_opencensus_port")
+		},
+	},
+	{
+		Version:     7,
+		Description: "widen and encrypt the secret and password of component webhooks",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"secret", "password"} {
+				if err := modifyColumn(tx, "tenant_services_webhook", column, "text"); err != nil {
+					return err
+				}
+			}
+			keyring := encryption.GetKeyring(tx)
+			if keyring == nil {
+				return nil
+			}
+			_, err := keyring.EncryptAll(tx)
+			return err
+		},
+		// the widened columns are kept, the secrets are decrypted by the down of version 4
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	},
+	{
+		Version:     8,
+		Description: "create the plans of orchestrations",
+		Up: func(tx *gorm.DB) error {
+			return tx.CreateTable(&model.TenantOrchestration{}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.DropTable(&model.TenantOrchestration{}).Error
+		},
+	},
+	{
+		Version:     9,
+		Description: "widen the code branch of component versions",
+		Up: func(tx *gorm.DB) error {
+			return modifyColumn(tx, "tenant_service_version", "code_branch", "varchar(255)")
+		},
+		// the widened column is kept, narrowing it may truncate branches
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	},
+}
+
+//baseModels the tables of the baseline schema, the models are frozen copies of db/model
+func baseModels() []model.Interface {
+	return []model.Interface{
+		&baseline.Tenants{},
+		&baseline.TenantServices{},
+		&baseline.TenantServicesPort{},
+		&baseline.TenantServiceRelation{},
+		&baseline.TenantServiceEnvVar{},
+		&baseline.TenantServiceMountRelation{},
+		&baseline.TenantServiceVolume{},
+		&baseline.TenantServiceLable{},
+		&baseline.TenantServiceProbe{},
+		&baseline.LicenseInfo{},
+		&baseline.TenantServicesDelete{},
+		&baseline.TenantServiceLBMappingPort{},
+		&baseline.TenantPlugin{},
+		&baseline.TenantPluginBuildVersion{},
+		&baseline.TenantServicePluginRelation{},
+		&baseline.TenantPluginVersionEnv{},
+		&baseline.TenantPluginVersionDiscoverConfig{},
+		&baseline.CodeCheckResult{},
+		&baseline.ServiceEvent{},
+		&baseline.VersionInfo{},
+		&baseline.RegionUserInfo{},
+		&baseline.TenantServicesStreamPluginPort{},
+		&baseline.RegionAPIClass{},
+		&baseline.RegionProcotols{},
+		&baseline.LocalScheduler{},
+		&baseline.NotificationEvent{},
+		&baseline.AppStatus{},
+		&baseline.AppBackup{},
+		&baseline.AppBackupSchedule{},
+		&baseline.ServiceSourceConfig{},
+		&baseline.Application{},
+		&baseline.ApplicationConfigGroup{},
+		&baseline.ConfigGroupService{},
+		&baseline.ConfigGroupItem{},
+		// gateway
+		&baseline.Certificate{},
+		&baseline.RuleExtension{},
+		&baseline.HTTPRule{},
+		&baseline.TCPRule{},
+		&baseline.TenantServiceConfigFile{},
+		&baseline.Endpoint{},
+		&baseline.ThirdPartySvcDiscoveryCfg{},
+		&baseline.GwRuleConfig{},
+		// volumeType
+		&baseline.TenantServiceVolumeType{},
+		// pod autoscaler
+		&baseline.TenantServiceAutoscalerRules{},
+		&baseline.TenantServiceAutoscalerRuleMetrics{},
+		&baseline.TenantServiceScalingRecords{},
+		&baseline.TenantServiceScalingSchedule{},
+		&baseline.TenantServiceResourcePolicy{},
+		&baseline.TenantServiceMonitor{},
+		&baseline.TenantServiceWebhook{},
+	}
+}
+
+//applyBaseline creates the tables of a new db, and brings the tables created by the releases
+//before versioned migrations to the baseline schema.
+func applyBaseline(tx *gorm.DB) error {
+	isMysql := tx.Dialect().GetName() == "mysql"
+	for _, md := range baseModels() {
+		if tx.HasTable(md) {
+			if err := tx.AutoMigrate(md).Error; err != nil {
+				return fmt.Errorf("migrate table %s: %v", md.TableName(), err)
+			}
+			continue
+		}
+		db := tx
+		if isMysql {
+			db = db.Set("gorm:table_options", "ENGINE=InnoDB charset=utf8")
+		}
+		if err := db.CreateTable(md).Error; err != nil {
+			return fmt.Errorf("create table %s: %v", md.TableName(), err)
+		}
+	}
+
+	// old releases created these columns with smaller sizes
+	for _, c := range []struct{ table, column, typ string }{
+		{"tenant_services_envs", "attr_value", "text"},
+		{"tenant_services_event", "request_body", "varchar(1024)"},
+		{"tenant_services_volume", "volume_type", "varchar(64)"},
+	} {
+		if err := modifyColumn(tx, c.table, c.column, c.typ); err != nil {
+			return err
+		}
+	}
+	return tx.Exec("update gateway_tcp_rule set ip=? where ip=?", "0.0.0.0", "").Error
+}
+
+//modifyColumn change the type of the column. sqlite does not check the length of varchar, so there is nothing to do.
+func modifyColumn(tx *gorm.DB, table, column, typ string) error {
+	var sql string
+	switch tx.Dialect().GetName() {
+	case "mysql":
+		sql = fmt.Sprintf("alter table %s modify column %s %s", table, column, typ)
+	case "postgres":
+		sql = fmt.Sprintf("alter table %s alter column %s type %s", table, column, typ)
+	default:
+		return nil
+	}
+	if err := tx.Exec(sql).Error; err != nil {
+		return fmt.Errorf("alter table %s: %v", table, err)
+	}
+	return nil
+}
+
+//addColumn adds the column if it does not exist. The migrations add columns by ddl instead of
+//migrating the models of db/model, so they are not changed by the later changes of the models.
+func addColumn(tx *gorm.DB, table, column, typ string) error {
+	if tx.Dialect().HasColumn(table, column) {
+		return nil
+	}
+	if err := tx.Exec(fmt.Sprintf("alter table %s add column %s %s", table, column, typ)).Error; err != nil {
+		return fmt.Errorf("alter table %s: %v", table, err)
+	}
+	return nil
+}
+
+//dropColumn drops the column if it exists. sqlite before 3.35 can not drop columns, the column is kept.
+func dropColumn(tx *gorm.DB, table, column string) error {
+	if tx.Dialect().GetName() == "sqlite3" || !tx.Dialect().HasColumn(table, column) {
+		return nil
+	}
+	if err := tx.Exec(fmt.Sprintf("alter table %s drop column %s", table, column)).Error; err != nil {
+		return fmt.Errorf("alter table %s: %v", table, err)
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // AppStatus app status
 type AppStatus struct {
 	EventID     string `gorm:"column:event_id;size:32;primary_key" json:"event_id"`
@@ -30,9 +32,49 @@ type AppBackup struct {
 	BackupMode string `gorm:"column:backup_mode;size:32" json:"backup_mode"`
 	BuckupSize int64  `gorm:"column:backup_size;type:bigint" json:"backup_size"`
 	Deleted    bool   `gorm:"column:deleted" json:"deleted"`
+	//ScheduleID the schedule which created this backup, empty if backup manually
+	ScheduleID string `gorm:"column:schedule_id;size:32" json:"schedule_id"`
+	//Encrypted the backup data is encrypted by client-side encryption
+	Encrypted bool `gorm:"column:encrypted" json:"encrypted"`
 }
 
 //TableName 表名
 func (t *AppBackup) TableName() string {
 	return "region_app_backup"
 }
+
+//AppBackupSchedule the schedule of group app backup
+type AppBackupSchedule struct {
+	Model
+	ScheduleID string `gorm:"column:schedule_id;size:32;unique_index" json:"schedule_id"`
+	GroupID    string `gorm:"column:group_id;size:32" json:"group_id"`
+	//ServiceIDs the components backed up by the last run joined by comma, every run backs up the current components of the group
+	ServiceIDs string `gorm:"column:service_ids;type:text" json:"service_ids"`
+	//Cron standard cron expression, such as "0 2 * * *"
+	Cron string `gorm:"column:cron;size:64" json:"cron"`
+	//KeepDaily the number of daily backups to keep
+	KeepDaily int `gorm:"column:keep_daily" json:"keep_daily"`
+	//KeepWeekly the number of weekly backups to keep
+	KeepWeekly int `gorm:"column:keep_weekly" json:"keep_weekly"`
+	//Mode full-online or incremental-online
+	Mode  string `gorm:"column:mode;size:32" json:"mode"`
+	Force bool   `gorm:"column:force" json:"force"`
+	//Metadata console level metadata of the group app
+	Metadata     string `gorm:"column:metadata;size:65535" json:"-"`
+	S3Provider   string `gorm:"column:s3_provider;size:32" json:"s3_provider"`
+	S3Endpoint   string `gorm:"column:s3_endpoint;size:255" json:"s3_endpoint"`
+	S3AccessKey  string `gorm:"column:s3_access_key;size:255" json:"s3_access_key"`
+	S3SecretKey  string `gorm:"column:s3_secret_key;type:text" json:"-"`
+	S3BucketName string `gorm:"column:s3_bucket_name;size:255" json:"s3_bucket_name"`
+	//EncryptionSecretName the secret of client-side encryption key, the backups are not encrypted if empty
+	EncryptionSecretName string `gorm:"column:encryption_secret_name;size:253" json:"encryption_secret_name"`
+	EncryptionSecretKey  string `gorm:"column:encryption_secret_key;size:253" json:"encryption_secret_key"`
+	Enable               bool   `gorm:"column:enable" json:"enable"`
+	//LastScheduleTime the last time the backup was triggered
+	LastScheduleTime *time.Time `gorm:"column:last_schedule_time" json:"last_schedule_time"`
+}
+
+//TableName 表名
+func (t *AppBackupSchedule) TableName() string {
+	return "region_app_backup_schedule"
+}
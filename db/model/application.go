@@ -5,11 +5,30 @@ const (
 	GovernanceModeBuildInServiceMesh = "BUILD_IN_SERVICE_MESH"
 	// GovernanceModeKubernetesNativeService means the governance mode is KUBERNETES_NATIVE_SERVICE
 	GovernanceModeKubernetesNativeService = "KUBERNETES_NATIVE_SERVICE"
+	// GovernanceModeBuildInServiceMeshMTLS means the governance mode is BUILD_IN_SERVICE_MESH with mutual tls between sidecars
+	GovernanceModeBuildInServiceMeshMTLS = "BUILD_IN_SERVICE_MESH_MTLS"
+)
+
+const (
+	// ConfigGroupDeployTypeEnv means the items of config group are injected as environment variables
+	ConfigGroupDeployTypeEnv = "env"
+	// ConfigGroupDeployTypeFile means the items of config group are mounted as files, the item keys are the file names
+	ConfigGroupDeployTypeFile = "file"
+	// ConfigGroupSourceDB means the items of config group are stored in the region db
+	ConfigGroupSourceDB = "db"
+	// ConfigGroupSourceVault means the items of config group are read from a HashiCorp Vault KV v2 secret
+	ConfigGroupSourceVault = "vault"
 )
 
 // IsGovernanceModeValid checks if the governanceMode is valid.
 func IsGovernanceModeValid(governanceMode string) bool {
-	return governanceMode == GovernanceModeBuildInServiceMesh || governanceMode == GovernanceModeKubernetesNativeService
+	return governanceMode == GovernanceModeBuildInServiceMesh || governanceMode == GovernanceModeKubernetesNativeService ||
+		governanceMode == GovernanceModeBuildInServiceMeshMTLS
+}
+
+// IsBuildInServiceMesh checks if the governanceMode use the build-in service mesh.
+func IsBuildInServiceMesh(governanceMode string) bool {
+	return governanceMode == GovernanceModeBuildInServiceMesh || governanceMode == GovernanceModeBuildInServiceMeshMTLS
 }
 
 // Application -
@@ -19,6 +38,17 @@ type Application struct {
 	AppID          string `gorm:"column:app_id" json:"app_id"`
 	TenantID       string `gorm:"column:tenant_id" json:"tenant_id"`
 	GovernanceMode string `gorm:"column:governance_mode;default:'BUILD_IN_SERVICE_MESH'" json:"governance_mode"`
+	// TracingProvider is empty if the tracing is disabled
+	TracingProvider   string `gorm:"column:tracing_provider" json:"tracing_provider"`
+	TracingEndpoint   string `gorm:"column:tracing_endpoint" json:"tracing_endpoint"`
+	TracingSampleRate int    `gorm:"column:tracing_sample_rate;default:100" json:"tracing_sample_rate"`
+	// TracingOpenCensusPort the port of the opencensus receiver of the otlp collector, the sidecars report spans to it
+	TracingOpenCensusPort int `gorm:"column:tracing_opencensus_port;default:55678" json:"tracing_opencensus_port"`
+}
+
+// TracingEnabled checks if the tracing of the application is enabled.
+func (t *Application) TracingEnabled() bool {
+	return t.TracingProvider != "" && t.TracingEndpoint != ""
 }
 
 // TableName return tableName "application"
@@ -61,6 +91,15 @@ type ApplicationConfigGroup struct {
 	ConfigGroupName string `gorm:"column:config_group_name" json:"config_group_name"`
 	DeployType      string `gorm:"column:deploy_type;default:'env'" json:"deploy_type"`
 	Enable          bool   `gorm:"column:enable" json:"enable"`
+	SourceType      string `gorm:"column:source_type;size:32;default:'db'" json:"source_type"`
+	// SourcePath the path of vault secret, <mount>/<secret path>
+	SourcePath string `gorm:"column:source_path;size:255" json:"source_path"`
+	// RefreshInterval the seconds between two syncs of the external source, the default of worker is used if it is 0
+	RefreshInterval int `gorm:"column:refresh_interval" json:"refresh_interval"`
+	// MountPath the directory the items are mounted to if the deploy type is file
+	MountPath string `gorm:"column:mount_path;size:255" json:"mount_path"`
+	// RestartOnChange rolling restarts the components when the values of external source change
+	RestartOnChange bool `gorm:"column:restart_on_change" json:"restart_on_change"`
 }
 
 // TableName return tableName "application"
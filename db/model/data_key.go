@@ -0,0 +1,35 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// TenantDataKey is a data key used to encrypt the secrets of a tenant at rest, wrapped by the master key.
+// TenantID is empty for the data key of the secrets not owned by a tenant, such as certificates.
+type TenantDataKey struct {
+	Model
+	TenantID    string `gorm:"column:tenant_id;size:32;unique_index:tenant_data_key_version"`
+	Version     int    `gorm:"column:version;unique_index:tenant_data_key_version"`
+	WrappedKey  string `gorm:"column:wrapped_key;size:2048"`
+	MasterKeyID string `gorm:"column:master_key_id;size:255"`
+	Active      bool   `gorm:"column:active"`
+}
+
+// TableName returns the table name of TenantDataKey
+func (t *TenantDataKey) TableName() string {
+	return "tenant_data_keys"
+}
@@ -0,0 +1,42 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "time"
+
+//TenantOrchestration the plan of an orchestrated start or upgrade, the statuses of the
+//components are saved as the plan runs, so the progress is kept after the api restarts.
+type TenantOrchestration struct {
+	Model
+	// EventID the parent event of the orchestration
+	EventID   string `gorm:"column:event_id;size:40;unique_index"`
+	TenantID  string `gorm:"column:tenant_id;size:32;index:orchestration_tenant_id"`
+	Operation string `gorm:"column:operation;size:20"`
+	// Plan json encoded plan with the statuses of the components
+	Plan string `gorm:"column:plan;type:longtext"`
+	// Finished the plan is completed, or failed after the api running it exited
+	Finished bool `gorm:"column:finished"`
+	// HeartbeatAt is updated by the api running the plan
+	HeartbeatAt time.Time `gorm:"column:heartbeat_at"`
+}
+
+//TableName 表名
+func (t *TenantOrchestration) TableName() string {
+	return "tenant_orchestration"
+}
@@ -576,7 +576,23 @@ func (t *TenantServiceAutoscalerRules) TableName() string {
 	return "tenant_services_autoscaler_rules"
 }
 
+// The types of autoscaler rule metric
+const (
+	// ResourceMetrics the cpu and memory of containers
+	ResourceMetrics = "resource_metrics"
+	// PodsMetrics the metrics of pods scraped by the service monitor of component
+	PodsMetrics = "pods_metrics"
+	// ObjectMetrics the metrics of the kubernetes service selected by the service monitor of component
+	ObjectMetrics = "object_metrics"
+	// ExternalMetrics the metrics not related to kubernetes objects, such as the depth of MQ topic
+	ExternalMetrics = "external_metrics"
+	// GatewayMetrics the request rate and latency of component measured by the gateway
+	GatewayMetrics = "gateway_metrics"
+)
+
 // TenantServiceAutoscalerRuleMetrics -
+// MetricSelector is the label selector of metric series, such as topic=orders.
+// MetricObject is the name of service monitor whose kubernetes service is described by the object metric.
 type TenantServiceAutoscalerRuleMetrics struct {
 	Model
 	RuleID            string `gorm:"column:rule_id;size:32;not null"`
@@ -584,6 +600,8 @@ type TenantServiceAutoscalerRuleMetrics struct {
 	MetricsName       string `gorm:"column:metric_name;not null"`
 	MetricTargetType  string `gorm:"column:metric_target_type;not null"`
 	MetricTargetValue int    `gorm:"column:metric_target_value;not null"`
+	MetricSelector    string `gorm:"column:metric_selector;size:255"`
+	MetricObject      string `gorm:"column:metric_object;size:40"`
 }
 
 // TableName -
@@ -591,6 +609,41 @@ func (t *TenantServiceAutoscalerRuleMetrics) TableName() string {
 	return "tenant_services_autoscaler_rule_metrics"
 }
 
+// TenantServiceScalingSchedule the scheduled scaling rule of component,
+// the replicas of component are set to Replicas at the times of Cron in the Timezone.
+// The min replicas of the enabled autoscaler rules are adjusted instead if the component has hpa.
+type TenantServiceScalingSchedule struct {
+	Model
+	ScheduleID       string     `gorm:"column:schedule_id;unique;size:32" json:"schedule_id"`
+	ServiceID        string     `gorm:"column:service_id;size:32;index" json:"service_id"`
+	Enable           bool       `gorm:"column:enable" json:"enable"`
+	Cron             string     `gorm:"column:cron;size:64" json:"cron"`
+	Timezone         string     `gorm:"column:timezone;size:64" json:"timezone"`
+	Replicas         int        `gorm:"column:replicas" json:"replicas"`
+	LastScheduleTime *time.Time `gorm:"column:last_schedule_time" json:"last_schedule_time"`
+}
+
+// TableName -
+func (t *TenantServiceScalingSchedule) TableName() string {
+	return "tenant_services_scaling_schedules"
+}
+
+// TenantServiceResourcePolicy the right-sizing policy of component, the resource recommendations
+// are applied in the low-traffic windows of Cron in the Timezone if AutoApply is true.
+type TenantServiceResourcePolicy struct {
+	Model
+	ServiceID     string     `gorm:"column:service_id;unique;size:32" json:"service_id"`
+	AutoApply     bool       `gorm:"column:auto_apply" json:"auto_apply"`
+	Cron          string     `gorm:"column:cron;size:64" json:"cron"`
+	Timezone      string     `gorm:"column:timezone;size:64" json:"timezone"`
+	LastApplyTime *time.Time `gorm:"column:last_apply_time" json:"last_apply_time"`
+}
+
+// TableName -
+func (t *TenantServiceResourcePolicy) TableName() string {
+	return "tenant_services_resource_policies"
+}
+
 // TenantServiceScalingRecords -
 type TenantServiceScalingRecords struct {
 	Model
@@ -603,6 +656,8 @@ type TenantServiceScalingRecords struct {
 	Description string    `gorm:"column:description;size:1023" json:"description"`
 	Operator    string    `gorm:"column:operator" json:"operator"`
 	LastTime    time.Time `gorm:"column:last_time" json:"last_time"`
+	// Metric the metric triggered the scaling
+	Metric string `gorm:"column:metric;size:255" json:"metric"`
 }
 
 // TableName -
@@ -0,0 +1,50 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+//TenantServiceWebhook the git push webhook config of component.
+//the push events matched the branch or tag pattern trigger the source code build of the component.
+type TenantServiceWebhook struct {
+	Model
+	TenantID  string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	ServiceID string `gorm:"column:service_id;size:32;unique_index" json:"service_id"`
+	// Secret used to verify the signature or token of the webhook request
+	Secret string `gorm:"column:secret;type:text" json:"-"`
+	// BranchPattern comma separated glob patterns of branch, the branch of component is used if empty
+	BranchPattern string `gorm:"column:branch_pattern;size:255" json:"branch_pattern"`
+	// TagPattern comma separated glob patterns of tag, tag push is ignored if empty
+	TagPattern string `gorm:"column:tag_pattern;size:255" json:"tag_pattern"`
+	RepoURL    string `gorm:"column:repo_url;size:2047" json:"repo_url"`
+	Branch     string `gorm:"column:branch;size:255" json:"branch"`
+	Lang       string `gorm:"column:lang;size:40" json:"lang"`
+	Runtime    string `gorm:"column:runtime;size:40" json:"runtime"`
+	ServerType string `gorm:"column:server_type;size:40" json:"server_type"`
+	User       string `gorm:"column:user;size:255" json:"user"`
+	Password   string `gorm:"column:password;type:text" json:"-"`
+	// BuildEnvs json encoded build envs
+	BuildEnvs string `gorm:"column:build_envs;type:text" json:"-"`
+	// Action the action after build, eg. upgrade
+	Action string `gorm:"column:action;size:20" json:"action"`
+	Enable bool   `gorm:"column:enable" json:"enable"`
+}
+
+//TableName 表名
+func (t *TenantServiceWebhook) TableName() string {
+	return "tenant_services_webhook"
+}
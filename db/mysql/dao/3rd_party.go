@@ -86,7 +86,7 @@ func (e *EndpointDaoImpl) List(sid string) ([]*model.Endpoint, error) {
 // ListIsOnline lists *model.Endpoint according to sid, and filter out the ones that are not online.
 func (e *EndpointDaoImpl) ListIsOnline(sid string) ([]*model.Endpoint, error) {
 	var eps []*model.Endpoint
-	if err := e.DB.Where("service_id=? and is_online=1", sid).Find(&eps).Error; err != nil {
+	if err := e.DB.Where("service_id=? and is_online=?", sid, true).Find(&eps).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -145,3 +145,74 @@ func (a *AppBackupDaoImpl) GetDeleteAppBackups() ([]*model.AppBackup, error) {
 	}
 	return apps, nil
 }
+
+//GetAppBackupsBySchedule get the backups created by the schedule
+func (a *AppBackupDaoImpl) GetAppBackupsBySchedule(scheduleID string) ([]*model.AppBackup, error) {
+	var apps []*model.AppBackup
+	if err := a.DB.Where("schedule_id = ? and deleted=?", scheduleID, false).Order("create_time desc").Find(&apps).Error; err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+//AppBackupScheduleDaoImpl group app backup schedule store mysql impl
+type AppBackupScheduleDaoImpl struct {
+	DB *gorm.DB
+}
+
+//AddModel AddModel
+func (a *AppBackupScheduleDaoImpl) AddModel(mo model.Interface) error {
+	schedule, ok := mo.(*model.AppBackupSchedule)
+	if !ok {
+		return errors.New("Failed to convert interface to AppBackupSchedule")
+	}
+	var old model.AppBackupSchedule
+	if ok := a.DB.Where("schedule_id = ?", schedule.ScheduleID).Find(&old).RecordNotFound(); ok {
+		return a.DB.Create(schedule).Error
+	}
+	return fmt.Errorf("backup schedule exist with id %s", schedule.ScheduleID)
+}
+
+//UpdateModel UpdateModel
+func (a *AppBackupScheduleDaoImpl) UpdateModel(mo model.Interface) error {
+	schedule, ok := mo.(*model.AppBackupSchedule)
+	if !ok {
+		return errors.New("Failed to convert interface to AppBackupSchedule")
+	}
+	if schedule.ID == 0 {
+		return errors.New("Primary id can not be 0 when update")
+	}
+	return a.DB.Save(schedule).Error
+}
+
+//GetSchedule get backup schedule by id
+func (a *AppBackupScheduleDaoImpl) GetSchedule(scheduleID string) (*model.AppBackupSchedule, error) {
+	var schedule model.AppBackupSchedule
+	if err := a.DB.Where("schedule_id = ?", scheduleID).Find(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+//ListSchedules list the backup schedules of group app
+func (a *AppBackupScheduleDaoImpl) ListSchedules(groupID string) ([]*model.AppBackupSchedule, error) {
+	var schedules []*model.AppBackupSchedule
+	if err := a.DB.Where("group_id = ?", groupID).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+//ListEnabledSchedules list all enabled backup schedules
+func (a *AppBackupScheduleDaoImpl) ListEnabledSchedules() ([]*model.AppBackupSchedule, error) {
+	var schedules []*model.AppBackupSchedule
+	if err := a.DB.Where("enable = ?", true).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+//DeleteSchedule delete backup schedule
+func (a *AppBackupScheduleDaoImpl) DeleteSchedule(scheduleID string) error {
+	return a.DB.Where("schedule_id = ?", scheduleID).Delete(&model.AppBackupSchedule{}).Error
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db/encryption"
 	"github.com/goodrain/rainbond/db/model"
 	"github.com/jinzhu/gorm"
 )
@@ -27,7 +28,13 @@ func (a *AppConfigGroupDaoImpl) AddModel(mo model.Interface) error {
 //UpdateModel -
 func (a *AppConfigGroupDaoImpl) UpdateModel(mo model.Interface) error {
 	updateReq := mo.(*model.ApplicationConfigGroup)
-	return a.DB.Model(&model.ApplicationConfigGroup{}).Where("app_id = ? AND config_group_name = ?", updateReq.AppID, updateReq.ConfigGroupName).Update("enable", updateReq.Enable).Error
+	return a.DB.Model(&model.ApplicationConfigGroup{}).Where("app_id = ? AND config_group_name = ?", updateReq.AppID, updateReq.ConfigGroupName).Updates(map[string]interface{}{
+		"enable":            updateReq.Enable,
+		"source_path":       updateReq.SourcePath,
+		"refresh_interval":  updateReq.RefreshInterval,
+		"mount_path":        updateReq.MountPath,
+		"restart_on_change": updateReq.RestartOnChange,
+	}).Error
 }
 
 // GetConfigGroupByID -
@@ -48,6 +55,15 @@ func (a *AppConfigGroupDaoImpl) ListByServiceID(sid string) ([]*model.Applicatio
 	return groups, nil
 }
 
+// ListEnableBySourceType lists the enabled config groups reading items from the source
+func (a *AppConfigGroupDaoImpl) ListEnableBySourceType(sourceType string) ([]*model.ApplicationConfigGroup, error) {
+	var groups []*model.ApplicationConfigGroup
+	if err := a.DB.Where("source_type = ? and enable = ?", sourceType, true).Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
 // GetConfigGroupsByAppID -
 func (a *AppConfigGroupDaoImpl) GetConfigGroupsByAppID(appID string, page, pageSize int) ([]*model.ApplicationConfigGroup, int64, error) {
 	var oldApp []*model.ApplicationConfigGroup
@@ -132,6 +148,11 @@ func (a *AppConfigGroupItemDaoImpl) AddModel(mo model.Interface) error {
 //UpdateModel -
 func (a *AppConfigGroupItemDaoImpl) UpdateModel(mo model.Interface) error {
 	updateReq := mo.(*model.ConfigGroupItem)
+	restore, err := encryption.Encrypt(a.DB, updateReq)
+	defer restore()
+	if err != nil {
+		return err
+	}
 	return a.DB.Model(&model.ConfigGroupItem{}).
 		Where("app_id = ? AND config_group_name = ? AND item_key = ?", updateReq.AppID, updateReq.ConfigGroupName, updateReq.ItemKey).
 		Update("item_value", updateReq.ItemValue).Error
@@ -146,7 +146,7 @@ func (c *EventDaoImpl) GetEventsByTarget(target, targetID string, offset, limit
 	if err := db.Model(&model.ServiceEvent{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := db.Offset(offset).Limit(limit).Order("create_time DESC, ID DESC").Find(&result).Error; err != nil {
+	if err := db.Offset(offset).Limit(limit).Order("create_time DESC, " + quote(db, "ID") + " DESC").Find(&result).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return result, 0, nil
 		}
@@ -163,7 +163,7 @@ func (c *EventDaoImpl) GetEventsByTenantID(tenantID string, offset, limit int) (
 		return nil, 0, err
 	}
 	var result []*model.ServiceEvent
-	if err := c.DB.Where("tenant_id=?", tenantID).Offset(offset).Limit(limit).Order("start_time DESC, ID DESC").Find(&result).Error; err != nil {
+	if err := c.DB.Where("tenant_id=?", tenantID).Offset(offset).Limit(limit).Order("start_time DESC, " + quote(c.DB, "ID") + " DESC").Find(&result).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return result, 0, nil
 		}
@@ -201,6 +201,16 @@ func (c *EventDaoImpl) LatestFailurePodEvent(podName string) (*model.ServiceEven
 	return &event, nil
 }
 
+//CountByServiceIDAndOptType count the events of the component with the opt type created since the time
+func (c *EventDaoImpl) CountByServiceIDAndOptType(serviceID, optType string, since time.Time) (int, error) {
+	var count int
+	if err := c.DB.Model(&model.ServiceEvent{}).Where("service_id=? and opt_type=? and create_time>=?", serviceID, optType, since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 //NotificationEventDaoImpl NotificationEventDaoImpl
 type NotificationEventDaoImpl struct {
 	DB *gorm.DB
@@ -251,7 +261,7 @@ func (c *NotificationEventDaoImpl) GetNotificationEventByKind(kind, kindID strin
 func (c *NotificationEventDaoImpl) GetNotificationEventByTime(start, end time.Time) ([]*model.NotificationEvent, error) {
 	var result []*model.NotificationEvent
 	if !start.IsZero() && !end.IsZero() {
-		if err := c.DB.Where("last_time>? and last_time<? and is_handle=?", start, end, 0).Find(&result).Order("last_time DESC").Error; err != nil {
+		if err := c.DB.Where("last_time>? and last_time<? and is_handle=?", start, end, false).Find(&result).Order("last_time DESC").Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				return result, nil
 			}
@@ -259,7 +269,7 @@ func (c *NotificationEventDaoImpl) GetNotificationEventByTime(start, end time.Ti
 		}
 		return result, nil
 	}
-	if err := c.DB.Where("last_time<? and is_handle=?", time.Now(), 0).Find(&result).Order("last_time DESC").Error; err != nil {
+	if err := c.DB.Where("last_time<? and is_handle=?", time.Now(), false).Find(&result).Order("last_time DESC").Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return result, nil
 		}
@@ -397,7 +397,7 @@ type GwRuleConfigDaoImpl struct {
 func (t *GwRuleConfigDaoImpl) AddModel(mo model.Interface) error {
 	cfg := mo.(*model.GwRuleConfig)
 	var old model.GwRuleConfig
-	err := t.DB.Where("`rule_id` = ? and `key` = ?", cfg.RuleID, cfg.Key).Find(&old).Error
+	err := t.DB.Where(map[string]interface{}{"rule_id": cfg.RuleID, "key": cfg.Key}).Find(&old).Error
 	if err == gorm.ErrRecordNotFound {
 		if err := t.DB.Create(cfg).Error; err != nil {
 			return err
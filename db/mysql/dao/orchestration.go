@@ -0,0 +1,65 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+//TenantOrchestrationDaoImpl orchestration dao
+type TenantOrchestrationDaoImpl struct {
+	DB *gorm.DB
+}
+
+//AddModel create the orchestration
+func (t *TenantOrchestrationDaoImpl) AddModel(mo model.Interface) error {
+	orchestration := mo.(*model.TenantOrchestration)
+	return t.DB.Create(orchestration).Error
+}
+
+//UpdateModel update the plan and the heartbeat of the orchestration
+func (t *TenantOrchestrationDaoImpl) UpdateModel(mo model.Interface) error {
+	orchestration := mo.(*model.TenantOrchestration)
+	return t.DB.Model(&model.TenantOrchestration{}).Where("event_id=?", orchestration.EventID).Updates(map[string]interface{}{
+		"plan":         orchestration.Plan,
+		"finished":     orchestration.Finished,
+		"heartbeat_at": orchestration.HeartbeatAt,
+	}).Error
+}
+
+//GetByEventID get the orchestration of the tenant by the parent event
+func (t *TenantOrchestrationDaoImpl) GetByEventID(tenantID, eventID string) (*model.TenantOrchestration, error) {
+	var orchestration model.TenantOrchestration
+	if err := t.DB.Where("tenant_id=? and event_id=?", tenantID, eventID).Find(&orchestration).Error; err != nil {
+		return nil, err
+	}
+	return &orchestration, nil
+}
+
+//ListStale list the unfinished orchestrations whose heartbeat is before the given time
+func (t *TenantOrchestrationDaoImpl) ListStale(before time.Time) ([]*model.TenantOrchestration, error) {
+	var orchestrations []*model.TenantOrchestration
+	if err := t.DB.Where("finished=? and heartbeat_at<?", false, before).Find(&orchestrations).Error; err != nil {
+		return nil, err
+	}
+	return orchestrations, nil
+}
@@ -294,7 +294,7 @@ func (t *PluginBuildVersionDaoImpl) GetBuildVersionByDeployVersion(pluginID, ver
 // ListSuccessfulOnesByPluginIDs returns the list of successful build versions,
 func (t *PluginBuildVersionDaoImpl) ListSuccessfulOnesByPluginIDs(pluginIDs []string) ([]*model.TenantPluginBuildVersion, error) {
 	var version []*model.TenantPluginBuildVersion
-	if err := t.DB.Where("ID in (?) ", t.DB.Table("tenant_plugin_build_version").Select("max(id)").Where("plugin_id in (?) and status=?", pluginIDs, "complete").Group("plugin_id").QueryExpr()).Find(&version).Error; err != nil {
+	if err := t.DB.Where(quote(t.DB, "ID")+" in (?) ", t.DB.Table("tenant_plugin_build_version").Select("max("+quote(t.DB, "ID")+")").Where("plugin_id in (?) and status=?", pluginIDs, "complete").Group("plugin_id").QueryExpr()).Find(&version).Error; err != nil {
 		return nil, err
 	}
 	return version, nil
@@ -303,7 +303,7 @@ func (t *PluginBuildVersionDaoImpl) ListSuccessfulOnesByPluginIDs(pluginIDs []st
 //GetLastBuildVersionByVersionID get last success build version
 func (t *PluginBuildVersionDaoImpl) GetLastBuildVersionByVersionID(pluginID, versionID string) (*model.TenantPluginBuildVersion, error) {
 	var version model.TenantPluginBuildVersion
-	if err := t.DB.Where("plugin_id=? and version_id = ? and status=?", pluginID, versionID, "complete").Order("ID desc").Limit("1").Find(&version).Error; err != nil {
+	if err := t.DB.Where("plugin_id=? and version_id = ? and status=?", pluginID, versionID, "complete").Order(quote(t.DB, "ID") + " desc").Limit("1").Find(&version).Error; err != nil {
 		return nil, err
 	}
 	return &version, nil
@@ -28,6 +28,7 @@ import (
 	gormbulkups "github.com/atcdot/gorm-bulk-upsert"
 	"github.com/goodrain/rainbond/api/util/bcode"
 	"github.com/goodrain/rainbond/db/dao"
+	"github.com/goodrain/rainbond/db/encryption"
 	"github.com/goodrain/rainbond/db/errors"
 	"github.com/goodrain/rainbond/db/model"
 	"github.com/jinzhu/gorm"
@@ -108,7 +109,7 @@ func (t *TenantDaoImpl) GetALLTenants(query string) ([]*model.Tenants, error) {
 func (t *TenantDaoImpl) GetTenantByEid(eid, query string) ([]*model.Tenants, error) {
 	var tenants []*model.Tenants
 	if query != "" {
-		if err := t.DB.Where("eid = ? and name like '%?%'", eid, query).Find(&tenants).Error; err != nil {
+		if err := t.DB.Where("eid = ? and name like ?", eid, "%"+query+"%").Find(&tenants).Error; err != nil {
 			return nil, err
 		}
 	} else {
@@ -333,7 +334,7 @@ func (t *TenantServicesDaoImpl) GetPagedTenantService(offset, length int, servic
 		return nil, count, err
 	}
 	count = len(re)
-	rows, err := t.DB.Raw("SELECT tenant_id, SUM(container_cpu * replicas) AS use_cpu, SUM(container_memory * replicas) AS use_memory FROM tenant_services where service_id in (?) GROUP BY tenant_id ORDER BY use_memory DESC LIMIT ?,?", serviceIDs, offset, length).Rows()
+	rows, err := t.DB.Raw("SELECT tenant_id, SUM(container_cpu * replicas) AS use_cpu, SUM(container_memory * replicas) AS use_memory FROM tenant_services where service_id in (?) GROUP BY tenant_id ORDER BY use_memory DESC LIMIT ? OFFSET ?", serviceIDs, length, offset).Rows()
 	if err != nil {
 		return nil, count, err
 	}
@@ -735,7 +736,7 @@ func (t *TenantServicesPortDaoImpl) GetPort(serviceID string, port int) (*model.
 // GetOpenedPorts returns opened ports.
 func (t *TenantServicesPortDaoImpl) GetOpenedPorts(serviceID string) ([]*model.TenantServicesPort, error) {
 	var ports []*model.TenantServicesPort
-	if err := t.DB.Where("service_id = ? and (is_inner_service=1 or is_outer_service=1)", serviceID).
+	if err := t.DB.Where("service_id = ? and (is_inner_service=? or is_outer_service=?)", serviceID, true, true).
 		Find(&ports).Error; err != nil {
 		return nil, err
 	}
@@ -754,7 +755,7 @@ func (t *TenantServicesPortDaoImpl) DELPortsByServiceID(serviceID string) error
 // HasOpenPort checks if the given service(according to sid) has open port.
 func (t *TenantServicesPortDaoImpl) HasOpenPort(sid string) bool {
 	var port model.TenantServicesPort
-	if err := t.DB.Where("service_id = ? and (is_outer_service=1 or is_inner_service=1)", sid).
+	if err := t.DB.Where("service_id = ? and (is_outer_service=? or is_inner_service=?)", sid, true, true).
 		Find(&port).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
 			logrus.Warningf("error getting TenantServicesPort: %v", err)
@@ -936,6 +937,11 @@ func (t *TenantServiceEnvVarDaoImpl) AddModel(mo model.Interface) error {
 //UpdateModel update env support attr_value\is_change\scope
 func (t *TenantServiceEnvVarDaoImpl) UpdateModel(mo model.Interface) error {
 	env := mo.(*model.TenantServiceEnvVar)
+	restore, err := encryption.Encrypt(t.DB, env)
+	defer restore()
+	if err != nil {
+		return err
+	}
 	return t.DB.Table(env.TableName()).Where("service_id=? and attr_name = ?", env.ServiceID, env.AttrName).Update(map[string]interface{}{
 		"attr_value": env.AttrValue,
 		"is_change":  env.IsChange,
@@ -954,6 +960,10 @@ func (t *TenantServiceEnvVarDaoImpl) CreateOrUpdateEnvsInBatch(envs []model.Tena
 		}
 		existEnvs[key] = struct{}{}
 
+		// bulk upsert bypasses the callbacks of gorm, env is a copy, so it can be encrypted in place
+		if _, err := encryption.Encrypt(t.DB, &env); err != nil {
+			return pkgerr.Wrap(err, "encrypt env")
+		}
 		objects = append(objects, env)
 	}
 	if err := gormbulkups.BulkUpsert(t.DB, objects, 2000); err != nil {
@@ -1206,7 +1216,7 @@ func (t *TenantServiceVolumeDaoImpl) GetVolumeByServiceIDAndName(serviceID, name
 //GetVolumeByID get volume by id
 func (t *TenantServiceVolumeDaoImpl) GetVolumeByID(id int) (*model.TenantServiceVolume, error) {
 	var volume model.TenantServiceVolume
-	if err := t.DB.Where("ID=?", id).Find(&volume).Error; err != nil {
+	if err := t.DB.Where(quote(t.DB, "ID")+"=?", id).Find(&volume).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, dao.ErrVolumeNotFound
 		}
@@ -1738,6 +1748,8 @@ func (t *TenantServceAutoscalerRuleMetricsDaoImpl) UpdateOrCreate(metric *model.
 	} else {
 		old.MetricTargetType = metric.MetricTargetType
 		old.MetricTargetValue = metric.MetricTargetValue
+		old.MetricSelector = metric.MetricSelector
+		old.MetricObject = metric.MetricObject
 		if err := t.DB.Save(&old).Error; err != nil {
 			return err
 		}
@@ -1801,6 +1813,9 @@ func (t *TenantServiceScalingRecordsDaoImpl) UpdateOrCreate(new *model.TenantSer
 
 	old.Count = new.Count
 	old.LastTime = new.LastTime
+	if new.Metric != "" {
+		old.Metric = new.Metric
+	}
 	return t.DB.Save(&old).Error
 }
 
@@ -1824,3 +1839,113 @@ func (t *TenantServiceScalingRecordsDaoImpl) CountByServiceID(serviceID string)
 
 	return count, nil
 }
+
+// TenantServiceScalingScheduleDaoImpl -
+type TenantServiceScalingScheduleDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceScalingScheduleDaoImpl) AddModel(mo model.Interface) error {
+	schedule := mo.(*model.TenantServiceScalingSchedule)
+	var old model.TenantServiceScalingSchedule
+	if ok := t.DB.Where("schedule_id = ?", schedule.ScheduleID).Find(&old).RecordNotFound(); ok {
+		if err := t.DB.Create(schedule).Error; err != nil {
+			return err
+		}
+	} else {
+		return errors.ErrRecordAlreadyExist
+	}
+	return nil
+}
+
+// UpdateModel -
+func (t *TenantServiceScalingScheduleDaoImpl) UpdateModel(mo model.Interface) error {
+	schedule := mo.(*model.TenantServiceScalingSchedule)
+	return t.DB.Save(schedule).Error
+}
+
+// GetByScheduleID -
+func (t *TenantServiceScalingScheduleDaoImpl) GetByScheduleID(scheduleID string) (*model.TenantServiceScalingSchedule, error) {
+	var schedule model.TenantServiceScalingSchedule
+	if err := t.DB.Where("schedule_id=?", scheduleID).Find(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListByServiceID -
+func (t *TenantServiceScalingScheduleDaoImpl) ListByServiceID(serviceID string) ([]*model.TenantServiceScalingSchedule, error) {
+	var schedules []*model.TenantServiceScalingSchedule
+	if err := t.DB.Where("service_id=?", serviceID).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ListEnableOnes -
+func (t *TenantServiceScalingScheduleDaoImpl) ListEnableOnes() ([]*model.TenantServiceScalingSchedule, error) {
+	var schedules []*model.TenantServiceScalingSchedule
+	if err := t.DB.Where("enable=?", true).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// DeleteByScheduleID -
+func (t *TenantServiceScalingScheduleDaoImpl) DeleteByScheduleID(scheduleID string) error {
+	return t.DB.Where("schedule_id=?", scheduleID).Delete(&model.TenantServiceScalingSchedule{}).Error
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceScalingScheduleDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceScalingSchedule{}).Error
+}
+
+// TenantServiceResourcePolicyDaoImpl -
+type TenantServiceResourcePolicyDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceResourcePolicyDaoImpl) AddModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceResourcePolicy)
+	var old model.TenantServiceResourcePolicy
+	if ok := t.DB.Where("service_id = ?", policy.ServiceID).Find(&old).RecordNotFound(); ok {
+		if err := t.DB.Create(policy).Error; err != nil {
+			return err
+		}
+	} else {
+		return errors.ErrRecordAlreadyExist
+	}
+	return nil
+}
+
+// UpdateModel -
+func (t *TenantServiceResourcePolicyDaoImpl) UpdateModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceResourcePolicy)
+	return t.DB.Save(policy).Error
+}
+
+// GetByServiceID -
+func (t *TenantServiceResourcePolicyDaoImpl) GetByServiceID(serviceID string) (*model.TenantServiceResourcePolicy, error) {
+	var policy model.TenantServiceResourcePolicy
+	if err := t.DB.Where("service_id=?", serviceID).Find(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListAutoApplyOnes -
+func (t *TenantServiceResourcePolicyDaoImpl) ListAutoApplyOnes() ([]*model.TenantServiceResourcePolicy, error) {
+	var policies []*model.TenantServiceResourcePolicy
+	if err := t.DB.Where("auto_apply=?", true).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceResourcePolicyDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceResourcePolicy{}).Error
+}
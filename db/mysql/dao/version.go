@@ -183,7 +183,7 @@ func (c *VersionInfoDaoImpl) DeleteFailureVersionInfo(timePoint time.Time, statu
 func (c *VersionInfoDaoImpl) SearchVersionInfo() ([]*model.VersionInfo, error) {
 	var result []*model.VersionInfo
 	versionInfo := &model.VersionInfo{}
-	if err := c.DB.Table(versionInfo.TableName()).Select("service_id").Group("service_id").Having("count(ID) > ?", 5).Scan(&result).Error; err != nil {
+	if err := c.DB.Table(versionInfo.TableName()).Select("service_id").Group("service_id").Having("count(*) > ?", 5).Scan(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -0,0 +1,61 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dao
+
+import (
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+//TenantServiceWebhookDaoImpl component webhook dao
+type TenantServiceWebhookDaoImpl struct {
+	DB *gorm.DB
+}
+
+//AddModel create or update the webhook of component
+func (t *TenantServiceWebhookDaoImpl) AddModel(mo model.Interface) error {
+	webhook := mo.(*model.TenantServiceWebhook)
+	var old model.TenantServiceWebhook
+	if ok := t.DB.Where("service_id=?", webhook.ServiceID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(webhook).Error
+	}
+	webhook.ID = old.ID
+	webhook.CreatedAt = old.CreatedAt
+	return t.DB.Save(webhook).Error
+}
+
+//UpdateModel update the webhook of component
+func (t *TenantServiceWebhookDaoImpl) UpdateModel(mo model.Interface) error {
+	webhook := mo.(*model.TenantServiceWebhook)
+	return t.DB.Save(webhook).Error
+}
+
+//GetByServiceID get the webhook of component
+func (t *TenantServiceWebhookDaoImpl) GetByServiceID(serviceID string) (*model.TenantServiceWebhook, error) {
+	var webhook model.TenantServiceWebhook
+	if err := t.DB.Where("service_id=?", serviceID).Find(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+//DeleteByServiceID delete the webhook of component
+func (t *TenantServiceWebhookDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceWebhook{}).Error
+}
@@ -466,6 +466,13 @@ func (m *Manager) AppBackupDaoTransactions(db *gorm.DB) dao.AppBackupDao {
 	}
 }
 
+//AppBackupScheduleDao group app backup schedule
+func (m *Manager) AppBackupScheduleDao() dao.AppBackupScheduleDao {
+	return &mysqldao.AppBackupScheduleDaoImpl{
+		DB: m.db,
+	}
+}
+
 //ServiceSourceDao service source db impl
 func (m *Manager) ServiceSourceDao() dao.ServiceSourceDao {
 	return &mysqldao.ServiceSourceImpl{
@@ -613,6 +620,62 @@ func (m *Manager) TenantServiceScalingRecordsDaoTransactions(db *gorm.DB) dao.Te
 	}
 }
 
+// TenantServiceScalingScheduleDao -
+func (m *Manager) TenantServiceScalingScheduleDao() dao.TenantServiceScalingScheduleDao {
+	return &mysqldao.TenantServiceScalingScheduleDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceScalingScheduleDaoTransactions -
+func (m *Manager) TenantServiceScalingScheduleDaoTransactions(db *gorm.DB) dao.TenantServiceScalingScheduleDao {
+	return &mysqldao.TenantServiceScalingScheduleDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceResourcePolicyDao -
+func (m *Manager) TenantServiceResourcePolicyDao() dao.TenantServiceResourcePolicyDao {
+	return &mysqldao.TenantServiceResourcePolicyDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceResourcePolicyDaoTransactions -
+func (m *Manager) TenantServiceResourcePolicyDaoTransactions(db *gorm.DB) dao.TenantServiceResourcePolicyDao {
+	return &mysqldao.TenantServiceResourcePolicyDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceWebhookDao -
+func (m *Manager) TenantServiceWebhookDao() dao.TenantServiceWebhookDao {
+	return &mysqldao.TenantServiceWebhookDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceWebhookDaoTransactions -
+func (m *Manager) TenantServiceWebhookDaoTransactions(db *gorm.DB) dao.TenantServiceWebhookDao {
+	return &mysqldao.TenantServiceWebhookDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantOrchestrationDao -
+func (m *Manager) TenantOrchestrationDao() dao.TenantOrchestrationDao {
+	return &mysqldao.TenantOrchestrationDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantOrchestrationDaoTransactions -
+func (m *Manager) TenantOrchestrationDaoTransactions(db *gorm.DB) dao.TenantOrchestrationDao {
+	return &mysqldao.TenantOrchestrationDaoImpl{
+		DB: db,
+	}
+}
+
 //TenantServiceMonitorDao monitor dao
 func (m *Manager) TenantServiceMonitorDao() dao.TenantServiceMonitorDao {
 	return &mysqldao.TenantServiceMonitorDaoImpl{
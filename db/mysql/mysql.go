@@ -19,59 +19,87 @@
 package mysql
 
 import (
-	"sync"
+	"fmt"
 
 	"github.com/goodrain/rainbond/db/config"
-	"github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/db/encryption"
+	"github.com/goodrain/rainbond/db/migration"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 
 	// import sql driver manually
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
 )
 
 //Manager db manager
 type Manager struct {
-	db      *gorm.DB
-	config  config.Config
-	initOne sync.Once
-	models  []model.Interface
+	db     *gorm.DB
+	config config.Config
 }
 
 //CreateManager create manager
 func CreateManager(config config.Config) (*Manager, error) {
-	var db *gorm.DB
-	if config.DBType == "mysql" {
-		var err error
-		db, err = gorm.Open("mysql", config.MysqlConnectionInfo+"?charset=utf8&parseTime=True&loc=Local")
-		if err != nil {
-			return nil, err
-		}
-	}
-	if config.DBType == "cockroachdb" {
-		var err error
-		addr := config.MysqlConnectionInfo
-		db, err = gorm.Open("postgres", addr)
-		if err != nil {
-			return nil, err
-		}
+	db, err := openDB(config)
+	if err != nil {
+		return nil, err
 	}
 	if config.ShowSQL {
 		db = db.Debug()
 	}
 	manager := &Manager{
-		db:      db,
-		config:  config,
-		initOne: sync.Once{},
+		db:     db,
+		config: config,
 	}
 	db.SetLogger(manager)
-	manager.RegisterTableModel()
-	manager.CheckTable()
-	logrus.Debug("mysql db driver create")
+	encryptionConfig := encryption.Config{
+		MasterKeyFile:          config.MasterKeyFile,
+		PreviousMasterKeyFiles: config.PreviousMasterKeyFiles,
+		KMSEndpoint:            config.KMSEndpoint,
+	}
+	if encryptionConfig.Enabled() {
+		keyring, err := encryption.NewKeyring(encryptionConfig)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("create keyring: %v", err)
+		}
+		db = encryption.Register(db, keyring)
+		manager.db = db
+	}
+	if !config.SkipMigration {
+		if err := migration.NewMigrator(db).Up(0); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("migrate db: %v", err)
+		}
+	}
+	logrus.Debugf("%s db driver create", config.DBType)
 	return manager, nil
 }
 
+//openDB open the gorm db for the configured db type
+func openDB(config config.Config) (*gorm.DB, error) {
+	switch config.DBType {
+	case "mysql":
+		return gorm.Open("mysql", config.MysqlConnectionInfo+"?charset=utf8&parseTime=True&loc=Local")
+	case "cockroachdb", "postgres":
+		return gorm.Open("postgres", config.MysqlConnectionInfo)
+	case "sqlite3":
+		db, err := gorm.Open("sqlite3", config.MysqlConnectionInfo)
+		if err != nil {
+			return nil, err
+		}
+		// sqlite only allows one writer at a time
+		db.DB().SetMaxOpenConns(1)
+		if err := db.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+			db.Close()
+			return nil, err
+		}
+		return db, nil
+	}
+	return nil, fmt.Errorf("db type %s not supported", config.DBType)
+}
+
 //CloseManager 关闭管理器
 func (m *Manager) CloseManager() error {
 	return m.db.Close()
@@ -101,105 +129,3 @@ func (m *Manager) EnsureEndTransactionFunc() func(tx *gorm.DB) {
 func (m *Manager) Print(v ...interface{}) {
 	logrus.Info(v...)
 }
-
-//RegisterTableModel register table model
-func (m *Manager) RegisterTableModel() {
-	m.models = append(m.models, &model.Tenants{})
-	m.models = append(m.models, &model.TenantServices{})
-	m.models = append(m.models, &model.TenantServicesPort{})
-	m.models = append(m.models, &model.TenantServiceRelation{})
-	m.models = append(m.models, &model.TenantServiceEnvVar{})
-	m.models = append(m.models, &model.TenantServiceMountRelation{})
-	m.models = append(m.models, &model.TenantServiceVolume{})
-	m.models = append(m.models, &model.TenantServiceLable{})
-	m.models = append(m.models, &model.TenantServiceProbe{})
-	m.models = append(m.models, &model.LicenseInfo{})
-	m.models = append(m.models, &model.TenantServicesDelete{})
-	m.models = append(m.models, &model.TenantServiceLBMappingPort{})
-	m.models = append(m.models, &model.TenantPlugin{})
-	m.models = append(m.models, &model.TenantPluginBuildVersion{})
-	m.models = append(m.models, &model.TenantServicePluginRelation{})
-	m.models = append(m.models, &model.TenantPluginVersionEnv{})
-	m.models = append(m.models, &model.TenantPluginVersionDiscoverConfig{})
-	m.models = append(m.models, &model.CodeCheckResult{})
-	m.models = append(m.models, &model.ServiceEvent{})
-	m.models = append(m.models, &model.VersionInfo{})
-	m.models = append(m.models, &model.RegionUserInfo{})
-	m.models = append(m.models, &model.TenantServicesStreamPluginPort{})
-	m.models = append(m.models, &model.RegionAPIClass{})
-	m.models = append(m.models, &model.RegionProcotols{})
-	m.models = append(m.models, &model.LocalScheduler{})
-	m.models = append(m.models, &model.NotificationEvent{})
-	m.models = append(m.models, &model.AppStatus{})
-	m.models = append(m.models, &model.AppBackup{})
-	m.models = append(m.models, &model.ServiceSourceConfig{})
-	m.models = append(m.models, &model.Application{})
-	m.models = append(m.models, &model.ApplicationConfigGroup{})
-	m.models = append(m.models, &model.ConfigGroupService{})
-	m.models = append(m.models, &model.ConfigGroupItem{})
-	// gateway
-	m.models = append(m.models, &model.Certificate{})
-	m.models = append(m.models, &model.RuleExtension{})
-	m.models = append(m.models, &model.HTTPRule{})
-	m.models = append(m.models, &model.TCPRule{})
-	m.models = append(m.models, &model.TenantServiceConfigFile{})
-	m.models = append(m.models, &model.Endpoint{})
-	m.models = append(m.models, &model.ThirdPartySvcDiscoveryCfg{})
-	m.models = append(m.models, &model.GwRuleConfig{})
-
-	// volumeType
-	m.models = append(m.models, &model.TenantServiceVolumeType{})
-	// pod autoscaler
-	m.models = append(m.models, &model.TenantServiceAutoscalerRules{})
-	m.models = append(m.models, &model.TenantServiceAutoscalerRuleMetrics{})
-	m.models = append(m.models, &model.TenantServiceScalingRecords{})
-	m.models = append(m.models, &model.TenantServiceMonitor{})
-}
-
-//CheckTable check and create tables
-func (m *Manager) CheckTable() {
-	m.initOne.Do(func() {
-		for _, md := range m.models {
-			if !m.db.HasTable(md) {
-				if m.config.DBType == "mysql" {
-					err := m.db.Set("gorm:table_options", "ENGINE=InnoDB charset=utf8").CreateTable(md).Error
-					if err != nil {
-						logrus.Errorf("auto create table %s to db error."+err.Error(), md.TableName())
-					} else {
-						logrus.Infof("auto create table %s to db success", md.TableName())
-					}
-				} else { //cockroachdb
-					err := m.db.CreateTable(md).Error
-					if err != nil {
-						logrus.Errorf("auto create cockroachdb table %s to db error."+err.Error(), md.TableName())
-					} else {
-						logrus.Infof("auto create cockroachdb table %s to db success", md.TableName())
-					}
-				}
-			} else {
-				if err := m.db.AutoMigrate(md).Error; err != nil {
-					logrus.Errorf("auto Migrate table %s to db error."+err.Error(), md.TableName())
-				}
-			}
-		}
-		m.patchTable()
-	})
-}
-
-func (m *Manager) patchTable() {
-	//modify tenant service env max size to 1024
-	if err := m.db.Exec("alter table tenant_services_envs modify column attr_value varchar(1024);").Error; err != nil {
-		logrus.Errorf("alter table tenant_services_envs error %s", err.Error())
-	}
-
-	if err := m.db.Exec("alter table tenant_services_event modify column request_body varchar(1024);").Error; err != nil {
-		logrus.Errorf("alter table tenant_services_envent error %s", err.Error())
-	}
-
-	if err := m.db.Exec("update gateway_tcp_rule set ip=? where ip=?", "0.0.0.0", "").Error; err != nil {
-		logrus.Errorf("update gateway_tcp_rule data error %s", err.Error())
-	}
-	if err := m.db.Exec("alter table tenant_services_volume modify column volume_type varchar(64);").Error; err != nil {
-		logrus.Errorf("alter table tenant_services_volume error: %s", err.Error())
-	}
-}
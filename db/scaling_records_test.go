@@ -19,77 +19,25 @@
 package db
 
 import (
-	"context"
-	"fmt"
-	dbconfig "github.com/goodrain/rainbond/db/config"
 	"github.com/goodrain/rainbond/db/model"
-	"github.com/testcontainers/testcontainers-go"
 	"testing"
 	"time"
 )
 
 func TestManager_TenantServiceScalingRecordsDaoImpl_UpdateOrCreate(t *testing.T) {
-	dbname := "region"
-	rootpw := "rainbond"
-
-	ctx := context.Background()
-	req := testcontainers.ContainerRequest{
-		Image:        "mariadb",
-		ExposedPorts: []string{"3306/tcp"},
-		Env: map[string]string{
-			"MYSQL_ROOT_PASSWORD": rootpw,
-			"MYSQL_DATABASE":      dbname,
-		},
-		Cmd: []string{"character-set-server=utf8mb4", "collation-server=utf8mb4_unicode_ci"},
-	}
-	mariadb, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          true,
-	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer mariadb.Terminate(ctx)
-
-	host, err := mariadb.Host(ctx)
-	if err != nil {
-		t.Error(err)
-	}
-	port, err := mariadb.MappedPort(ctx, "3306")
-	if err != nil {
-		t.Error(err)
-	}
-
-	connInfo := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", "root",
-		rootpw, host, port.Int(), dbname)
-	tryTimes := 3
-	for {
-		if err := CreateManager(dbconfig.Config{
-			DBType:              "mysql",
-			MysqlConnectionInfo: connInfo,
-		}); err != nil {
-			if tryTimes == 0 {
-				t.Fatalf("Connect info: %s; error creating db manager: %v", connInfo, err)
-			} else {
-				tryTimes = tryTimes - 1
-				time.Sleep(10 * time.Second)
-				continue
-			}
+	runWithDB(t, func(t *testing.T) {
+		record := &model.TenantServiceScalingRecords{
+			ServiceID:   "45197f4936cf45efa2ac4831ce42025a",
+			RuleID:      "xxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+			EventName:   "xxxxxxxxxxxxxxxxxxxxxxxxxxxxx.15d6ede119c8af35",
+			RecordType:  "hpa",
+			Reason:      "FailedGetResourceMetric",
+			Description: "unable to get metrics for resource memory: no metrics returned from resource metrics API",
+			Count:       2,
+			LastTime:    time.Now(),
 		}
-		break
-	}
-
-	record := &model.TenantServiceScalingRecords{
-		ServiceID:   "45197f4936cf45efa2ac4831ce42025a",
-		RuleID:      "xxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
-		EventName:   "xxxxxxxxxxxxxxxxxxxxxxxxxxxxx.15d6ede119c8af35",
-		RecordType:  "hpa",
-		Reason:      "FailedGetResourceMetric",
-		Description: "unable to get metrics for resource memory: no metrics returned from resource metrics API",
-		Count:       2,
-		LastTime:    time.Now(),
-	}
-	if err := GetManager().TenantServiceScalingRecordsDao().UpdateOrCreate(record); err != nil {
-		t.Fatal(err)
-	}
+		if err := GetManager().TenantServiceScalingRecordsDao().UpdateOrCreate(record); err != nil {
+			t.Fatal(err)
+		}
+	})
 }
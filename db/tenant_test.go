@@ -19,191 +19,119 @@
 package db
 
 import (
-	"context"
-	"fmt"
-	dbconfig "github.com/goodrain/rainbond/db/config"
 	"github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/util"
-	"github.com/testcontainers/testcontainers-go"
 	"testing"
-	"time"
 )
 
 func TestTenantServicesDao_ListThirdPartyServices(t *testing.T) {
-	dbname := "region"
-	rootpw := "rainbond"
-
-	ctx := context.Background()
-	req := testcontainers.ContainerRequest{
-		Image:        "mariadb",
-		ExposedPorts: []string{"3306/tcp"},
-		Env: map[string]string{
-			"MYSQL_ROOT_PASSWORD": rootpw,
-			"MYSQL_DATABASE":      dbname,
-		},
-		Cmd: []string{"character-set-server=utf8mb4", "collation-server=utf8mb4_unicode_ci"},
-	}
-	mariadb, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          true,
-	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer mariadb.Terminate(ctx)
-
-	host, err := mariadb.Host(ctx)
-	if err != nil {
-		t.Error(err)
-	}
-	port, err := mariadb.MappedPort(ctx, "3306")
-	if err != nil {
-		t.Error(err)
-	}
+	runWithDB(t, func(t *testing.T) {
+		svcs, err := GetManager().TenantServiceDao().ListThirdPartyServices()
+		if err != nil {
+			t.Fatalf("error listing third-party service: %v", err)
+		}
+		if len(svcs) != 0 {
+			t.Errorf("Expected 0 for the length of third-party services, but returned %d", len(svcs))
+		}
 
-	connInfo := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", "root",
-		rootpw, host, port.Int(), dbname)
-	tryTimes := 3
-	for {
-		if err := CreateManager(dbconfig.Config{
-			DBType:              "mysql",
-			MysqlConnectionInfo: connInfo,
-		}); err != nil {
-			if tryTimes == 0 {
-				t.Fatalf("Connect info: %s; error creating db manager: %v", connInfo, err)
-			} else {
-				tryTimes = tryTimes - 1
-				time.Sleep(10 * time.Second)
-				continue
+		for i := 0; i < 3; i++ {
+			item1 := &model.TenantServices{
+				TenantID:  util.NewUUID(),
+				ServiceID: util.NewUUID(),
+				Kind:      model.ServiceKindThirdParty.String(),
+			}
+			if err = GetManager().TenantServiceDao().AddModel(item1); err != nil {
+				t.Fatalf("error create third-party service: %v", err)
 			}
 		}
-		break
-	}
-
-	svcs, err := GetManager().TenantServiceDao().ListThirdPartyServices()
-	if err != nil {
-		t.Fatalf("error listing third-party service: %v", err)
-	}
-	if len(svcs) != 0 {
-		t.Errorf("Expected 0 for the length of third-party services, but returned %d", len(svcs))
-	}
-
-	for i := 0; i < 3; i++ {
-		item1 := &model.TenantServices{
-			TenantID:  util.NewUUID(),
-			ServiceID: util.NewUUID(),
-			Kind:      model.ServiceKindThirdParty.String(),
+		svcs, err = GetManager().TenantServiceDao().ListThirdPartyServices()
+		if err != nil {
+			t.Fatalf("error listing third-party service: %v", err)
 		}
-		if err = GetManager().TenantServiceDao().AddModel(item1); err != nil {
-			t.Fatalf("error create third-party service: %v", err)
+		if len(svcs) != 3 {
+			t.Errorf("Expected 3 for the length of third-party services, but returned %d", len(svcs))
 		}
-	}
-	svcs, err = GetManager().TenantServiceDao().ListThirdPartyServices()
-	if err != nil {
-		t.Fatalf("error listing third-party service: %v", err)
-	}
-	if len(svcs) != 3 {
-		t.Errorf("Expected 3 for the length of third-party services, but returned %d", len(svcs))
-	}
+	})
 }
 
 func TestTenantServicesPortDao_HasOpenPort(t *testing.T) {
-	dbname := "region"
-	rootpw := "rainbond"
-
-	ctx := context.Background()
-	req := testcontainers.ContainerRequest{
-		Image:        "mariadb",
-		ExposedPorts: []string{"3306/tcp"},
-		Env: map[string]string{
-			"MYSQL_ROOT_PASSWORD": rootpw,
-			"MYSQL_DATABASE":      dbname,
-		},
-		Cmd: []string{"character-set-server=utf8mb4", "collation-server=utf8mb4_unicode_ci"},
-	}
-	mariadb, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          true,
+	runWithDB(t, func(t *testing.T) {
+		t.Run("service doesn't exist", func(t *testing.T) {
+			hasOpenPort := GetManager().TenantServicesPortDao().HasOpenPort("foobar")
+			if hasOpenPort {
+				t.Error("Expected false for hasOpenPort, but returned true")
+			}
+		})
+		trueVal := true
+		falseVal := false
+		t.Run("outer service", func(t *testing.T) {
+			port := &model.TenantServicesPort{
+				ServiceID:      util.NewUUID(),
+				IsOuterService: &trueVal,
+			}
+			if err := GetManager().TenantServicesPortDao().AddModel(port); err != nil {
+				t.Fatalf("error creating TenantServicesPort: %v", err)
+			}
+			hasOpenPort := GetManager().TenantServicesPortDao().HasOpenPort(port.ServiceID)
+			if !hasOpenPort {
+				t.Errorf("Expected true for hasOpenPort, but returned %v", hasOpenPort)
+			}
+		})
+		t.Run("inner service", func(t *testing.T) {
+			port := &model.TenantServicesPort{
+				ServiceID:      util.NewUUID(),
+				IsInnerService: &trueVal,
+			}
+			if err := GetManager().TenantServicesPortDao().AddModel(port); err != nil {
+				t.Fatalf("error creating TenantServicesPort: %v", err)
+			}
+			hasOpenPort := GetManager().TenantServicesPortDao().HasOpenPort(port.ServiceID)
+			if !hasOpenPort {
+				t.Errorf("Expected true for hasOpenPort, but returned %v", hasOpenPort)
+			}
+		})
+		t.Run("not inner or outer service", func(t *testing.T) {
+			port := &model.TenantServicesPort{
+				ServiceID:      util.NewUUID(),
+				IsInnerService: &falseVal,
+				IsOuterService: &falseVal,
+			}
+			if err := GetManager().TenantServicesPortDao().AddModel(port); err != nil {
+				t.Fatalf("error creating TenantServicesPort: %v", err)
+			}
+			hasOpenPort := GetManager().TenantServicesPortDao().HasOpenPort(port.ServiceID)
+			if hasOpenPort {
+				t.Errorf("Expected false for hasOpenPort, but returned %v", hasOpenPort)
+			}
+		})
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer mariadb.Terminate(ctx)
-
-	host, err := mariadb.Host(ctx)
-	if err != nil {
-		t.Error(err)
-	}
-	port, err := mariadb.MappedPort(ctx, "3306")
-	if err != nil {
-		t.Error(err)
-	}
+}
 
-	connInfo := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", "root",
-		rootpw, host, port.Int(), dbname)
-	tryTimes := 3
-	for {
-		if err := CreateManager(dbconfig.Config{
-			DBType:              "mysql",
-			MysqlConnectionInfo: connInfo,
-		}); err != nil {
-			if tryTimes == 0 {
-				t.Fatalf("Connect info: %s; error creating db manager: %v", connInfo, err)
-			} else {
-				tryTimes = tryTimes - 1
-				time.Sleep(10 * time.Second)
-				continue
+func TestTenantDao_GetTenantByEid(t *testing.T) {
+	runWithDB(t, func(t *testing.T) {
+		eid := util.NewUUID()
+		for _, name := range []string{"foo-dev", "foo-prod", "bar"} {
+			if err := GetManager().TenantDao().AddModel(&model.Tenants{
+				Name: name,
+				UUID: util.NewUUID(),
+				EID:  eid,
+			}); err != nil {
+				t.Fatal(err)
 			}
 		}
-		break
-	}
-
-	t.Run("service doesn't exist", func(t *testing.T) {
-		hasOpenPort := GetManager().TenantServicesPortDao().HasOpenPort("foobar")
-		if hasOpenPort {
-			t.Error("Expected false for hasOpenPort, but returned true")
-		}
-	})
-	trueVal := true
-	falseVal := true
-	t.Run("outer service", func(t *testing.T) {
-		port := &model.TenantServicesPort{
-			ServiceID:      util.NewUUID(),
-			IsOuterService: &trueVal,
-		}
-		if err := GetManager().TenantServicesPortDao().AddModel(port); err != nil {
-			t.Fatalf("error creating TenantServicesPort: %v", err)
-		}
-		hasOpenPort := GetManager().TenantServicesPortDao().HasOpenPort(port.ServiceID)
-		if !hasOpenPort {
-			t.Errorf("Expected true for hasOpenPort, but returned %v", hasOpenPort)
-		}
-	})
-	t.Run("inner service", func(t *testing.T) {
-		port := &model.TenantServicesPort{
-			ServiceID:      util.NewUUID(),
-			IsInnerService: &trueVal,
-		}
-		if err := GetManager().TenantServicesPortDao().AddModel(port); err != nil {
-			t.Fatalf("error creating TenantServicesPort: %v", err)
+		tenants, err := GetManager().TenantDao().GetTenantByEid(eid, "foo")
+		if err != nil {
+			t.Fatal(err)
 		}
-		hasOpenPort := GetManager().TenantServicesPortDao().HasOpenPort(port.ServiceID)
-		if !hasOpenPort {
-			t.Errorf("Expected true for hasOpenPort, but returned %v", hasOpenPort)
-		}
-	})
-	t.Run("not inner or outer service", func(t *testing.T) {
-		port := &model.TenantServicesPort{
-			ServiceID:      util.NewUUID(),
-			IsInnerService: &falseVal,
-			IsOuterService: &falseVal,
+		if len(tenants) != 2 {
+			t.Errorf("Expected 2 tenants matching foo, but returned %d", len(tenants))
 		}
-		if err := GetManager().TenantServicesPortDao().AddModel(port); err != nil {
-			t.Fatalf("error creating TenantServicesPort: %v", err)
+		tenants, err = GetManager().TenantDao().GetTenantByEid(eid, "")
+		if err != nil {
+			t.Fatal(err)
 		}
-		hasOpenPort := GetManager().TenantServicesPortDao().HasOpenPort(port.ServiceID)
-		if hasOpenPort {
-			t.Errorf("Expected false for hasOpenPort, but returned %v", hasOpenPort)
+		if len(tenants) != 3 {
+			t.Errorf("Expected 3 tenants, but returned %d", len(tenants))
 		}
 	})
 }
@@ -38,6 +38,8 @@ func CreateDBManager(conf conf.DBConf) error {
 		if err = db.CreateManager(config.Config{
 			MysqlConnectionInfo: conf.URL,
 			DBType:              conf.Type,
+			// the migrations are applied by the api
+			SkipMigration: true,
 		}); err != nil {
 			logrus.Errorf("get db manager failed, try time is %v,%s", tryTime, err.Error())
 			time.Sleep((5 + tryTime*10) * time.Second)
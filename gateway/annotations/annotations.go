@@ -27,6 +27,7 @@ import (
 	"github.com/goodrain/rainbond/gateway/annotations/proxy"
 	"github.com/goodrain/rainbond/gateway/annotations/resolver"
 	"github.com/goodrain/rainbond/gateway/annotations/rewrite"
+	"github.com/goodrain/rainbond/gateway/annotations/tracing"
 	"github.com/goodrain/rainbond/gateway/annotations/upstreamhashby"
 	weight "github.com/goodrain/rainbond/gateway/annotations/wight"
 	"github.com/goodrain/rainbond/util/ingress-nginx/ingress/errors"
@@ -50,6 +51,7 @@ type Ingress struct {
 	UpstreamHashBy    string
 	LoadBalancingType string
 	Proxy             proxy.Config
+	EnableTracing     bool
 }
 
 // Extractor defines the annotation parsers to be used in the extraction of annotations
@@ -69,6 +71,7 @@ func NewAnnotationExtractor(cfg resolver.Resolver) Extractor {
 			"UpstreamHashBy":    upstreamhashby.NewParser(cfg),
 			"LoadBalancingType": lbtype.NewParser(cfg),
 			"Proxy":             proxy.NewParser(cfg),
+			"EnableTracing":     tracing.NewParser(cfg),
 		},
 	}
 }
@@ -114,6 +114,8 @@ type Location struct {
 	AccessLogPath    string
 	ErrorLogPath     string
 	DisableProxyPass bool
+	//EnableTracing propagates the trace context to the upstream
+	EnableTracing bool
 	//PathRewrite if true, path will not passed to the upstream
 	PathRewrite   bool
 	NameCondition map[string]*v1.Condition
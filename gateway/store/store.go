@@ -604,6 +604,7 @@ func (s *k8sStore) ListVirtualService() (l7vs []*v1.VirtualService, l4vs []*v1.V
 						vs.Locations = append(vs.Locations, location)
 						// the first ingress proxy takes effect
 						location.Proxy = anns.Proxy
+						location.EnableTracing = anns.EnableTracing
 					}
 					// If their ServiceName is the same, then the new one will overwrite the old one.
 					nameCondition := &v1.Condition{}
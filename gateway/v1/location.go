@@ -47,6 +47,8 @@ type Location struct {
 	// +optional
 	Proxy            proxy.Config `json:"proxy,omitempty"`
 	DisableProxyPass bool
+	// EnableTracing propagates the trace context to the backend
+	EnableTracing bool
 }
 
 // Condition is the condition that the traffic can reach the specified backend
@@ -80,6 +82,10 @@ func (l *Location) Equals(c *Location) bool {
 		return false
 	}
 
+	if l.EnableTracing != c.EnableTracing {
+		return false
+	}
+
 	return true
 }
 
@@ -31,6 +31,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
@@ -50,6 +51,9 @@ var K8SClient kubernetes.Interface
 //RainbondKubeClient rainbond custom resource client
 var RainbondKubeClient client.Client
 
+//RestConfig the rest config of kubernetes, which is used to exec into the pods
+var RestConfig *rest.Config
+
 //InitClient init k8s client
 func InitClient(kubeconfig string) error {
 	if kubeconfig == "" {
@@ -68,6 +72,7 @@ func InitClient(kubeconfig string) error {
 	}
 	config.QPS = 50
 	config.Burst = 100
+	RestConfig = config
 
 	K8SClient, err = kubernetes.NewForConfig(config)
 	if err != nil {
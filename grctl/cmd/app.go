@@ -0,0 +1,551 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/region"
+	"github.com/goodrain/rainbond/grctl/clients"
+	"github.com/goodrain/rainbond/util/termtables"
+	"github.com/goodrain/rainbond/webcli/term"
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+	"github.com/urfave/cli"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+//defaultEventLogServer the default address of eventlog websocket server
+const defaultEventLogServer = "127.0.0.1:6363"
+
+var buildKinds = map[string]string{
+	"image":        model.FromImageBuildKing,
+	"code":         model.FromCodeBuildKing,
+	"market_image": model.FromMarketImageBuildKing,
+	"market_slug":  model.FromMarketSlugBuildKing,
+}
+
+//NewCmdApp the lifecycle commands of application component
+func NewCmdApp() cli.Command {
+	tenantFlag := cli.StringFlag{
+		Name:     "tenantAlias,t",
+		Value:    "",
+		Usage:    "Specify the tenant alias",
+		FilePath: GetTenantNamePath(),
+	}
+	followFlag := cli.BoolFlag{
+		Name:  "f",
+		Usage: "Blocks the output operation log",
+	}
+	eventLogFlag := cli.StringFlag{
+		Name:  "event_log_server",
+		Value: defaultEventLogServer,
+		Usage: "event log server address",
+	}
+	outputFlag := cli.StringFlag{
+		Name:  "output,o",
+		Usage: "Output format, support json",
+	}
+	c := cli.Command{
+		Name:  "app",
+		Usage: "build, deploy, scale, logs, exec and rollback the application component, grctl app -h",
+		Subcommands: []cli.Command{
+			{
+				Name:  "build",
+				Usage: "Build the component from image or source code, For example <grctl app build goodrain/gr123456 --kind image --image nginx:latest --deploy>",
+				Flags: []cli.Flag{
+					tenantFlag, followFlag, eventLogFlag, outputFlag,
+					cli.StringFlag{
+						Name:  "kind",
+						Value: "image",
+						Usage: "The build kind, support image, code, market_image and market_slug",
+					},
+					cli.StringFlag{
+						Name:  "image",
+						Usage: "The image to build from",
+					},
+					cli.StringFlag{
+						Name:  "repo-url",
+						Usage: "The source code repository to build from",
+					},
+					cli.StringFlag{
+						Name:  "branch",
+						Value: "master",
+						Usage: "The branch of source code repository",
+					},
+					cli.StringFlag{
+						Name:  "lang",
+						Usage: "The language of source code",
+					},
+					cli.StringFlag{
+						Name:  "user",
+						Usage: "The user of image registry or source code repository",
+					},
+					cli.StringFlag{
+						Name:  "password",
+						Usage: "The password of image registry or source code repository",
+					},
+					cli.StringFlag{
+						Name:  "cmd",
+						Usage: "The start command of component",
+					},
+					cli.StringSliceFlag{
+						Name:  "env,e",
+						Usage: "The build env, KEY=VALUE",
+					},
+					cli.BoolFlag{
+						Name:  "deploy",
+						Usage: "Deploy the component after building success",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return buildApp(c)
+				},
+			},
+			{
+				Name:  "deploy",
+				Usage: "Deploy the build version of component, the current version is redeployed if not specified, For example <grctl app deploy goodrain/gr123456 --version 20210101>",
+				Flags: []cli.Flag{
+					tenantFlag, followFlag, eventLogFlag, outputFlag,
+					cli.StringFlag{
+						Name:  "version",
+						Usage: "The build version to deploy",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return deployApp(c)
+				},
+			},
+			{
+				Name:  "scale",
+				Usage: "Scale the replicas, cpu or memory of component, For example <grctl app scale goodrain/gr123456 --replicas 2>",
+				Flags: []cli.Flag{
+					tenantFlag, followFlag, eventLogFlag, outputFlag,
+					cli.IntFlag{
+						Name:  "replicas",
+						Value: -1,
+						Usage: "The replicas of component",
+					},
+					cli.IntFlag{
+						Name:  "cpu",
+						Usage: "The cpu of container, in millicores",
+					},
+					cli.IntFlag{
+						Name:  "memory",
+						Usage: "The memory of container, in MB",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return scaleApp(c)
+				},
+			},
+			{
+				Name:  "rollback",
+				Usage: "Roll back the component to the build version, For example <grctl app rollback goodrain/gr123456 --version 20210101>",
+				Flags: []cli.Flag{
+					tenantFlag, followFlag, eventLogFlag, outputFlag,
+					cli.StringFlag{
+						Name:  "version",
+						Usage: "The build version to roll back to",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return rollbackApp(c)
+				},
+			},
+			{
+				Name:  "versions",
+				Usage: "List the build versions of component, For example <grctl app versions goodrain/gr123456>",
+				Flags: []cli.Flag{tenantFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return listAppVersions(c)
+				},
+			},
+			{
+				Name:  "logs",
+				Usage: "Follow the container logs of component, or the operation log with --event, For example <grctl app logs goodrain/gr123456>",
+				Flags: []cli.Flag{
+					tenantFlag, eventLogFlag, outputFlag,
+					cli.StringFlag{
+						Name:  "event",
+						Usage: "Follow the log of the operation event",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return appLogs(c)
+				},
+			},
+			{
+				Name:  "exec",
+				Usage: "Execute the command in the pod of component, For example <grctl app exec goodrain/gr123456 -- ls />",
+				Flags: []cli.Flag{
+					tenantFlag,
+					cli.StringFlag{
+						Name:  "pod,p",
+						Usage: "The pod name, the first running pod of component is used if not specified",
+					},
+					cli.StringFlag{
+						Name:  "container,c",
+						Usage: "The container name, the first container of pod is used if not specified",
+					},
+					cli.BoolFlag{
+						Name:  "stdin,i",
+						Usage: "Pass stdin to the container",
+					},
+					cli.BoolFlag{
+						Name:  "tty",
+						Usage: "Stdin is a TTY",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return execApp(c)
+				},
+			},
+		},
+	}
+	return c
+}
+
+//parseAppArgs parse the component from the first argument, the format is tenant/alias or alias with --tenantAlias
+func parseAppArgs(c *cli.Context) (tenantName, serviceAlias string) {
+	serviceAlias = c.Args().First()
+	tenantName = c.String("tenantAlias")
+	if info := strings.Split(serviceAlias, "/"); len(info) >= 2 {
+		tenantName = info[0]
+		serviceAlias = info[1]
+	}
+	if tenantName == "" {
+		showError("tenant alias can not be empty")
+	}
+	if serviceAlias == "" {
+		showError("service alias can not be empty")
+	}
+	return
+}
+
+func getAppService(c *cli.Context) (region.ServiceInterface, string) {
+	tenantName, serviceAlias := parseAppArgs(c)
+	service := clients.RegionClient.Tenants(tenantName).Services(serviceAlias)
+	info, err := service.Get()
+	handleErr(err)
+	if info == nil || info.ServiceID == "" {
+		showError("service not exist: " + serviceAlias)
+	}
+	return service, info.ServiceID
+}
+
+func outputJSON(c *cli.Context) bool {
+	return c.String("output") == "json"
+}
+
+func printJSON(v interface{}) {
+	body, _ := json.MarshalIndent(v, "", "\t")
+	fmt.Println(string(body))
+}
+
+//showOperation prints the result of operation, and follows the event log if -f
+func showOperation(c *cli.Context, result interface{}, eventID string) error {
+	if outputJSON(c) {
+		printJSON(result)
+	} else {
+		fmt.Println("EventID:", eventID)
+	}
+	if c.Bool("f") && eventID != "" {
+		return GetEventLogf(eventID, c.String("event_log_server"))
+	}
+	return nil
+}
+
+func buildApp(c *cli.Context) error {
+	service, serviceID := getAppService(c)
+	kind, ok := buildKinds[c.String("kind")]
+	if !ok {
+		return fmt.Errorf("unsupported build kind %s", c.String("kind"))
+	}
+	req := &model.ComponentBuildReq{
+		ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: serviceID},
+		Kind:                  kind,
+		Operator:              "grctl",
+	}
+	if len(c.StringSlice("env")) > 0 {
+		req.BuildENVs = make(map[string]string)
+		for _, env := range c.StringSlice("env") {
+			kv := strings.SplitN(env, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return fmt.Errorf("invalid env %s, the format is KEY=VALUE", env)
+			}
+			req.BuildENVs[kv[0]] = kv[1]
+		}
+	}
+	if c.Bool("deploy") {
+		req.Action = "upgrade"
+	}
+	switch kind {
+	case model.FromCodeBuildKing:
+		if c.String("repo-url") == "" {
+			return errors.New("the repo-url is required to build from source code")
+		}
+		req.CodeInfo = model.BuildCodeInfo{
+			RepoURL:  c.String("repo-url"),
+			Branch:   c.String("branch"),
+			Lang:     c.String("lang"),
+			User:     c.String("user"),
+			Password: c.String("password"),
+			Cmd:      c.String("cmd"),
+		}
+	case model.FromImageBuildKing, model.FromMarketImageBuildKing:
+		if c.String("image") == "" {
+			return errors.New("the image is required to build from image")
+		}
+		req.ImageInfo = model.BuildImageInfo{
+			ImageURL: c.String("image"),
+			User:     c.String("user"),
+			Password: c.String("password"),
+			Cmd:      c.String("cmd"),
+		}
+	}
+	result, err := service.Build(req)
+	handleErr(err)
+	return showOperation(c, result, result.EventID)
+}
+
+func deployApp(c *cli.Context) error {
+	service, serviceID := getAppService(c)
+	result, err := service.Upgrade(&model.ComponentUpgradeReq{
+		ComponentOpGeneralReq: model.ComponentOpGeneralReq{ServiceID: serviceID},
+		UpgradeVersion:        c.String("version"),
+	})
+	handleErr(err)
+	return showOperation(c, result, result.EventID)
+}
+
+func rollbackApp(c *cli.Context) error {
+	if c.String("version") == "" {
+		showError("the version to roll back to is required")
+	}
+	service, serviceID := getAppService(c)
+	result, err := service.RollBack(&model.RollbackInfoRequestStruct{
+		ServiceID:       serviceID,
+		RollBackVersion: c.String("version"),
+	})
+	handleErr(err)
+	return showOperation(c, result, result.EventID)
+}
+
+func scaleApp(c *cli.Context) error {
+	replicas, cpu, memory := c.Int("replicas"), c.Int("cpu"), c.Int("memory")
+	if replicas < 0 && cpu <= 0 && memory <= 0 {
+		showError("one of replicas, cpu and memory is required")
+	}
+	if (cpu > 0) != (memory > 0) {
+		showError("the cpu and memory must be specified together")
+	}
+	service, serviceID := getAppService(c)
+	result := make(map[string]string)
+	result["service_id"] = serviceID
+	var eventID string
+	if cpu > 0 {
+		id, err := service.Vertical(cpu, memory)
+		handleErr(err)
+		result["vertical_event_id"] = id
+		eventID = id
+	}
+	if replicas >= 0 {
+		id, err := service.Horizontal(int32(replicas))
+		handleErr(err)
+		result["horizontal_event_id"] = id
+		if eventID != "" && !outputJSON(c) {
+			fmt.Println("EventID:", eventID)
+		}
+		eventID = id
+	}
+	return showOperation(c, result, eventID)
+}
+
+func listAppVersions(c *cli.Context) error {
+	tenantName, serviceAlias := parseAppArgs(c)
+	list, err := clients.RegionClient.Tenants(tenantName).Services(serviceAlias).BuildList()
+	handleErr(err)
+	if outputJSON(c) {
+		printJSON(list)
+		return nil
+	}
+	table := termtables.CreateTable()
+	table.AddHeaders("Version", "Kind", "Status", "Source", "Author", "Created", "Current")
+	for _, v := range list.List {
+		var current string
+		if v.BuildVersion == list.DeployVersion {
+			current = "*"
+		}
+		source := v.RepoURL
+		if v.CodeBranch != "" {
+			source += "@" + v.CodeBranch
+		}
+		table.AddRow(v.BuildVersion, v.Kind, v.FinalStatus, source, v.Author, v.CreateTime, current)
+	}
+	fmt.Println(table.Render())
+	return nil
+}
+
+func appLogs(c *cli.Context) error {
+	server := c.String("event_log_server")
+	if eventID := c.String("event"); eventID != "" {
+		if outputJSON(c) {
+			return followLogs(server, "event_log", "event_id="+eventID, true)
+		}
+		return GetEventLogf(eventID, server)
+	}
+	_, serviceID := getAppService(c)
+	return followLogs(server, "docker_log", "service_id="+serviceID, outputJSON(c))
+}
+
+//followLogs subscribes the log messages from the eventlog websocket and prints them until the connection is closed
+func followLogs(server, path, subscribe string, raw bool) error {
+	u := url.URL{Scheme: "ws", Host: server, Path: path}
+	con, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("dial websocket endpoint %s error: %v", u.String(), err)
+	}
+	defer con.Close()
+	if err := con.WriteMessage(websocket.TextMessage, []byte(subscribe)); err != nil {
+		return err
+	}
+	for {
+		_, message, err := con.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return err
+		}
+		//the docker log server confirms the subscription by ok
+		if string(message) == "ok" {
+			continue
+		}
+		if raw || !gjson.ValidBytes(message) {
+			fmt.Println(string(message))
+			continue
+		}
+		if m := gjson.GetBytes(message, "message"); m.Exists() {
+			fmt.Printf("[%s](%s) %s\n", strings.ToUpper(gjson.GetBytes(message, "level").String()), gjson.GetBytes(message, "time").String(), m.String())
+			continue
+		}
+		fmt.Println(string(message))
+	}
+}
+
+func execApp(c *cli.Context) error {
+	if clients.K8SClient == nil || clients.RestConfig == nil {
+		showError("the kubernetes client is not configured")
+	}
+	tenantName, serviceAlias := parseAppArgs(c)
+	service := clients.RegionClient.Tenants(tenantName).Services(serviceAlias)
+	info, apiErr := service.Get()
+	handleErr(apiErr)
+	deployInfo, apiErr := service.GetDeployInfo()
+	handleErr(apiErr)
+	namespace := deployInfo.Namespace
+	if namespace == "" {
+		namespace = info.TenantID
+	}
+	pod, err := getExecPod(namespace, c.String("pod"), deployInfo.Pods)
+	if err != nil {
+		return err
+	}
+	container := c.String("container")
+	if container == "" {
+		container = pod.Spec.Containers[0].Name
+	}
+	command := c.Args().Tail()
+	if len(command) == 0 {
+		command = []string{"sh"}
+	}
+	t := term.TTY{In: os.Stdin, Out: os.Stdout, Raw: true}
+	tty := c.Bool("tty") && t.IsTerminalIn()
+	stdin := c.Bool("stdin") || tty
+	req := clients.K8SClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin,
+			Stdout:    true,
+			Stderr:    !tty,
+			TTY:       tty,
+		}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(clients.RestConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	options := remotecommand.StreamOptions{Stdout: os.Stdout, Tty: tty}
+	if stdin {
+		options.Stdin = os.Stdin
+	}
+	if !tty {
+		options.Stderr = os.Stderr
+		return exec.Stream(options)
+	}
+	options.TerminalSizeQueue = t.MonitorSize(t.GetSize())
+	return t.Safe(func() error {
+		return exec.Stream(options)
+	})
+}
+
+//getExecPod returns the specified pod, or the first running pod of component
+func getExecPod(namespace, podName string, pods map[string]string) (*corev1.Pod, error) {
+	if podName != "" {
+		return clients.K8SClient.CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+	}
+	var names []string
+	for name := range pods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pod, err := clients.K8SClient.CoreV1().Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			return pod, nil
+		}
+	}
+	return nil, errors.New("there is no running pod of component")
+}
@@ -34,6 +34,7 @@ func GetCmds() []cli.Command {
 	cmds := []cli.Command{}
 	cmds = append(cmds, NewCmdInstall())
 	cmds = append(cmds, NewCmdService())
+	cmds = append(cmds, NewCmdApp())
 	cmds = append(cmds, NewCmdTenant())
 	cmds = append(cmds, NewCmdNode())
 	cmds = append(cmds, NewCmdCluster())
@@ -44,6 +45,7 @@ func GetCmds() []cli.Command {
 	cmds = append(cmds, NewCmdEnvoy())
 	cmds = append(cmds, NewCmdConfig())
 	cmds = append(cmds, NewCmdRegistry())
+	cmds = append(cmds, NewCmdDB())
 	return cmds
 }
 
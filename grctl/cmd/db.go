@@ -0,0 +1,224 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	rainbondv1alpha1 "github.com/goodrain/rainbond-operator/api/v1alpha1"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/config"
+	"github.com/goodrain/rainbond/db/encryption"
+	"github.com/goodrain/rainbond/db/migration"
+	"github.com/goodrain/rainbond/grctl/clients"
+	"github.com/goodrain/rainbond/util/termtables"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NewCmdDB db cmd
+func NewCmdDB() cli.Command {
+	dbFlags := []cli.Flag{
+		cli.StringFlag{
+			Name:   "namespace, ns",
+			Usage:  "rainbond namespace",
+			EnvVar: "RBDNamespace",
+			Value:  "rbd-system",
+		},
+		cli.StringFlag{
+			Name:  "db-type",
+			Usage: "db type mysql, postgres, cockroachdb or sqlite3",
+			Value: "mysql",
+		},
+		cli.StringFlag{
+			Name:  "dsn",
+			Usage: "db connection info, read from the rainbond cluster if not set",
+		},
+		cli.StringFlag{
+			Name:  "master-key-file",
+			Usage: "the file of the master key encrypting the secrets in db",
+		},
+		cli.StringSliceFlag{
+			Name:  "previous-master-key-file",
+			Usage: "the file of a master key before rotation, can be repeated",
+		},
+		cli.StringFlag{
+			Name:  "kms-endpoint",
+			Usage: "the endpoint of the kms plugin keeping the master key",
+		},
+	}
+	c := cli.Command{
+		Name:  "db",
+		Usage: "grctl db [command]",
+		Subcommands: []cli.Command{
+			{
+				Name:  "migrate",
+				Usage: "grctl db migrate status|up|down",
+				Subcommands: []cli.Command{
+					{
+						Name:  "status",
+						Usage: "show the applied and pending migrations of the region db",
+						Flags: dbFlags,
+						Action: func(c *cli.Context) error {
+							migrator, err := newDBMigrator(c)
+							if err != nil {
+								return err
+							}
+							status, err := migrator.Status()
+							if err != nil {
+								return errors.Wrap(err, "get migration status")
+							}
+							table := termtables.CreateTable()
+							table.AddHeaders("Version", "Description", "Status", "Applied At")
+							for _, s := range status {
+								state, appliedAt := "pending", ""
+								if s.Applied {
+									state = "applied"
+									appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+								}
+								if s.Unknown {
+									state = "applied(unknown)"
+								}
+								table.AddRow(s.Version, s.Description, state, appliedAt)
+							}
+							fmt.Println(table.Render())
+							return nil
+						},
+					},
+					{
+						Name:  "up",
+						Usage: "apply the pending migrations of the region db",
+						Flags: append([]cli.Flag{
+							cli.IntFlag{
+								Name:  "to",
+								Usage: "the version to migrate up to, all pending migrations if not set",
+							},
+						}, dbFlags...),
+						Action: func(c *cli.Context) error {
+							migrator, err := newDBMigrator(c)
+							if err != nil {
+								return err
+							}
+							if err := migrator.Up(c.Int("to")); err != nil {
+								return errors.Wrap(err, "migrate up")
+							}
+							fmt.Println("Migrate up success")
+							return nil
+						},
+					},
+					{
+						Name:  "down",
+						Usage: "revert the last applied migrations of the region db",
+						Flags: append([]cli.Flag{
+							cli.IntFlag{
+								Name:  "steps",
+								Usage: "the number of migrations to revert",
+								Value: 1,
+							},
+						}, dbFlags...),
+						Action: func(c *cli.Context) error {
+							migrator, err := newDBMigrator(c)
+							if err != nil {
+								return err
+							}
+							if err := migrator.Down(c.Int("steps")); err != nil {
+								return errors.Wrap(err, "migrate down")
+							}
+							fmt.Println("Migrate down success")
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "rotate-keys",
+				Usage: "rewrap the data keys of tenants by the current master key and encrypt the secrets stored in plain text",
+				Flags: append([]cli.Flag{
+					cli.BoolFlag{
+						Name:  "data-keys",
+						Usage: "also create new data keys and re-encrypt all secrets by them",
+					},
+				}, dbFlags...),
+				Action: func(c *cli.Context) error {
+					if err := newDBManager(c); err != nil {
+						return err
+					}
+					gdb := db.GetManager().DB()
+					keyring := encryption.GetKeyring(gdb)
+					if keyring == nil {
+						return fmt.Errorf("no master key, please set --master-key-file or --kms-endpoint")
+					}
+					rewrapped, err := keyring.RewrapDataKeys(gdb)
+					if err != nil {
+						return errors.Wrap(err, "rewrap data keys")
+					}
+					fmt.Printf("Rewrap %d data keys success\n", rewrapped)
+					var encrypted int
+					if c.Bool("data-keys") {
+						encrypted, err = keyring.RotateDataKeys(gdb)
+					} else {
+						encrypted, err = keyring.EncryptAll(gdb)
+					}
+					if err != nil {
+						return errors.Wrap(err, "encrypt secrets")
+					}
+					fmt.Printf("Encrypt %d secrets success\n", encrypted)
+					return nil
+				},
+			},
+		},
+	}
+	return c
+}
+
+func newDBMigrator(c *cli.Context) (*migration.Migrator, error) {
+	if err := newDBManager(c); err != nil {
+		return nil, err
+	}
+	return migration.NewMigrator(db.GetManager().DB()), nil
+}
+
+func newDBManager(c *cli.Context) error {
+	dsn := c.String("dsn")
+	if dsn == "" {
+		CommonWithoutRegion(c)
+		var cluster rainbondv1alpha1.RainbondCluster
+		if err := clients.RainbondKubeClient.Get(context.Background(), types.NamespacedName{Namespace: c.String("namespace"), Name: "rainbondcluster"}, &cluster); err != nil {
+			return errors.Wrap(err, "get configuration from rainbond cluster")
+		}
+		var err error
+		dsn, err = databaseDSN(&cluster)
+		if err != nil {
+			return errors.Wrap(err, "get database dsn")
+		}
+	}
+	if err := db.CreateManager(config.Config{
+		MysqlConnectionInfo:    dsn,
+		DBType:                 c.String("db-type"),
+		SkipMigration:          true,
+		MasterKeyFile:          c.String("master-key-file"),
+		PreviousMasterKeyFiles: c.StringSlice("previous-master-key-file"),
+		KMSEndpoint:            c.String("kms-endpoint"),
+	}); err != nil {
+		return errors.Wrap(err, "create database manager")
+	}
+	return nil
+}
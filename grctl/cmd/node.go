@@ -156,6 +156,19 @@ func handleConditionResult(serviceTable *termtables.Table, conditions []client.N
 	}
 }
 
+func handleEventResult(eventTable *termtables.Table, events []*client.NodeEvent, limit int) {
+	for i, v := range events {
+		if limit > 0 && i >= limit {
+			break
+		}
+		eventType := string(v.Type)
+		if v.Type == client.NodeEventWarning {
+			eventType = fmt.Sprintf("\033[0;31;31m %s \033[0m", v.Type)
+		}
+		eventTable.AddRow(v.CreateTime.Format(time.RFC3339)[:19], eventType, v.Service, v.Action, v.Message, v.Reason)
+	}
+}
+
 func extractReady(serviceTable *termtables.Table, conditions []client.NodeCondition, name string) {
 	for _, v := range conditions {
 		if string(v.Type) == name {
@@ -240,6 +253,13 @@ func NewCmdNode() cli.Command {
 					extractReady(serviceTable, v.NodeStatus.Conditions, "Ready")
 					handleConditionResult(serviceTable, v.NodeStatus.Conditions)
 					fmt.Println(serviceTable.Render())
+					if events, err := clients.RegionClient.Nodes().Events(v.ID); err == nil && len(events) > 0 {
+						fmt.Printf("-------------------Recent events-----------------------\n")
+						eventTable := termtables.CreateTable()
+						eventTable.AddHeaders("Time", "Type", "Service", "Action", "Message", "Reason")
+						handleEventResult(eventTable, events, 5)
+						fmt.Println(eventTable.Render())
+					}
 					return nil
 				},
 			},
@@ -525,6 +545,40 @@ func NewCmdNode() cli.Command {
 					},
 				},
 			},
+			{
+				Name:  "events",
+				Usage: "list the health remediation events of the specified node, events expire after 7 days",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name: "output,o",
+					},
+					cli.IntFlag{
+						Name:  "limit,l",
+						Value: 20,
+						Usage: "the max number of events to show, 0 means all",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					hostID := c.Args().First()
+					if hostID == "" {
+						logrus.Errorf("need hostID")
+						return nil
+					}
+					events, err := clients.RegionClient.Nodes().Events(hostID)
+					handleErr(err)
+					if c.String("output") == "json" {
+						jsIndent, _ := json.MarshalIndent(events, "", "\t")
+						fmt.Print(string(jsIndent))
+						return nil
+					}
+					eventTable := termtables.CreateTable()
+					eventTable.AddHeaders("Time", "Type", "Service", "Action", "Message", "Reason")
+					handleEventResult(eventTable, events, c.Int("limit"))
+					fmt.Println(eventTable.Render())
+					return nil
+				},
+			},
 			// {
 			// 	Name:  "add",
 			// 	Usage: "Add a node into the cluster",
@@ -72,6 +72,7 @@ func NewCmdRegistry() cli.Command {
 					dbCfg := config.Config{
 						MysqlConnectionInfo: dsn,
 						DBType:              "mysql",
+						SkipMigration:       true,
 					}
 					if err := db.CreateManager(dbCfg); err != nil {
 						return errors.Wrap(err, "create database manager")
@@ -20,13 +20,35 @@ type AlertingNameConfig struct {
 	Rules []*RulesConfig `yaml:"rules" json:"rules"`
 }
 
-//RulesConfig rule config
+//RulesConfig rule config, it is the recording rule if Record is set
 type RulesConfig struct {
-	Alert       string            `yaml:"alert" json:"alert"`
+	Record      string            `yaml:"record,omitempty" json:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty" json:"alert"`
 	Expr        string            `yaml:"expr" json:"expr"`
-	For         string            `yaml:"for" json:"for"`
-	Labels      map[string]string `yaml:"labels" json:"labels"`
-	Annotations map[string]string `yaml:"annotations" json:"annotations"`
+	For         string            `yaml:"for,omitempty" json:"for"`
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels"`
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations"`
+}
+
+//AutoscalerRulesName the name of recording rules group of autoscaler metrics
+const AutoscalerRulesName = "AutoscalerMetrics"
+
+//autoscalerRecordingRules records the gateway metrics of components, they are served as the external metrics
+//by the metrics adapter, and the names must be same as the metrics used by the autoscaler rules of worker.
+func autoscalerRecordingRules() *AlertingNameConfig {
+	return &AlertingNameConfig{
+		Name: AutoscalerRulesName,
+		Rules: []*RulesConfig{
+			&RulesConfig{
+				Record: "rbd_gateway_requests_per_second",
+				Expr:   "sum by (namespace, service_id) (rate(gateway_requests[1m]))",
+			},
+			&RulesConfig{
+				Record: "rbd_gateway_upstream_latency_ms",
+				Expr:   "sum by (namespace, service_id) (rate(gateway_upstream_latency_seconds_sum[1m])) / sum by (namespace, service_id) (rate(gateway_upstream_latency_seconds_count[1m])) * 1000",
+			},
+		},
+	}
 }
 
 //AlertingRulesManager alerting rule manage
@@ -463,6 +485,7 @@ func NewRulesManager(config *option.Config) *AlertingRulesManager {
 						},
 					},
 				},
+				autoscalerRecordingRules(),
 			},
 		},
 		config: config,
@@ -524,5 +547,28 @@ func (a *AlertingRulesManager) InitRulesConfig() {
 		a.SaveAlertingRulesConfig()
 		return
 	}
+	a.ensureAutoscalerRules()
 	return
 }
+
+//ensureAutoscalerRules adds the recording rules of autoscaler metrics to the existing rules config file
+func (a *AlertingRulesManager) ensureAutoscalerRules() {
+	config := &AlertingRulesConfig{}
+	content, err := ioutil.ReadFile(a.config.AlertingRulesFile)
+	if err != nil {
+		logrus.Errorf("read alerting rules config file: %v", err)
+		return
+	}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		logrus.Errorf("unmarshal alerting rules config: %v", err)
+		return
+	}
+	for _, group := range config.Groups {
+		if group.Name == AutoscalerRulesName {
+			return
+		}
+	}
+	a.RulesConfig = config
+	a.RulesConfig.Groups = append(a.RulesConfig.Groups, autoscalerRecordingRules())
+	a.SaveAlertingRulesConfig()
+}
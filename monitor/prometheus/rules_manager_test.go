@@ -0,0 +1,68 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/goodrain/rainbond/cmd/monitor/option"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestInitRulesConfigWithAutoscalerRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	rulesFile := path.Join(dir, "rules.yml")
+	old := "groups:\n- name: GatewayHealth\n  rules:\n  - alert: GatewayDown\n    expr: up{job=\"gateway\"}==0\n    for: 20s\n"
+	if err := ioutil.WriteFile(rulesFile, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := NewRulesManager(&option.Config{AlertingRulesFile: rulesFile})
+	a.InitRulesConfig()
+	// init again, the recording rules should not be added twice
+	a.InitRulesConfig()
+
+	content, err := ioutil.ReadFile(rulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config AlertingRulesConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Groups) != 2 || config.Groups[0].Name != "GatewayHealth" || config.Groups[1].Name != AutoscalerRulesName {
+		t.Fatalf("unexpected groups %s", content)
+	}
+	for _, rule := range config.Groups[1].Rules {
+		if rule.Record == "" || rule.Alert != "" {
+			t.Errorf("expect recording rule, got %+v", rule)
+		}
+	}
+	// the recording rule can not have the fields of alerting rule
+	if strings.Contains(string(content), "alert: \"\"") || strings.Contains(string(content), "for: \"\"") {
+		t.Errorf("the empty fields of recording rules should be omitted: %s", content)
+	}
+}
@@ -296,6 +296,17 @@ func GetLabel(w http.ResponseWriter, r *http.Request) {
 	httputil.ReturnSuccess(r, w, labels)
 }
 
+//ListNodeEvents list node remediation events
+func ListNodeEvents(w http.ResponseWriter, r *http.Request) {
+	nodeUID := strings.TrimSpace(chi.URLParam(r, "node_id"))
+	events, err := nodeService.ListNodeEvents(nodeUID)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, events)
+}
+
 //ListNodeCondition list node condition
 func ListNodeCondition(w http.ResponseWriter, r *http.Request) {
 	nodeUID := strings.TrimSpace(chi.URLParam(r, "node_id"))
@@ -24,8 +24,11 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/quota"
 	"github.com/sirupsen/logrus"
 
 	httputil "github.com/goodrain/rainbond/util/http"
@@ -42,16 +45,7 @@ func CreateLocalVolume(w http.ResponseWriter, r *http.Request) {
 	serviceID := requestopt["service_id"]
 	pvcName := requestopt["pvcname"]
 	var volumeHostPath = ""
-	localPath := os.Getenv("LOCAL_DATA_PATH")
-	if runtime.GOOS == "windows" {
-		if localPath == "" {
-			localPath = `c:\`
-		}
-	} else {
-		if localPath == "" {
-			localPath = "/grlocaldata"
-		}
-	}
+	localPath := localDataPath()
 	volumeHostPath = path.Join(localPath, "tenant", tenantID, "service", serviceID, pvcName)
 	volumePath, volumeok := requestopt["volume_path"]
 	podName, podok := requestopt["pod_name"]
@@ -63,9 +57,93 @@ func CreateLocalVolume(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(500)
 		return
 	}
+	if capacity, _ := strconv.ParseInt(requestopt["capacity"], 10, 64); capacity > 0 {
+		if err := setLocalVolumeQuota(volumeHostPath, capacity); err != nil {
+			logrus.Warningf("set quota of local volume %s: %v", volumeHostPath, err)
+		}
+	}
 	httputil.ReturnSuccess(r, w, map[string]string{"path": volumeHostPath})
 }
 
+//SetLocalVolumeQuota set the capacity of local volume dir
+func SetLocalVolumeQuota(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Path     string `json:"path"`
+		Capacity int64  `json:"capacity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Capacity <= 0 {
+		w.WriteHeader(400)
+		return
+	}
+	if !isLocalVolumePath(req.Path) {
+		httputil.ReturnError(r, w, 400, "path is not a local volume")
+		return
+	}
+	err := setLocalVolumeQuota(req.Path, req.Capacity)
+	if err != nil && err != quota.ErrNotSupported {
+		logrus.Errorf("set quota of local volume %s: %v", req.Path, err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, map[string]bool{"enforced": err == nil})
+}
+
+//GetLocalVolumeUsage get the usage of local volume dirs, from the quota accounting if
+//the filesystem supports project quota.
+func GetLocalVolumeUsage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Paths []string `json:"paths"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(400)
+		return
+	}
+	var report map[string]quota.Usage
+	if q, err := quota.New(localDataPath()); err == nil {
+		if report, err = q.Report(); err != nil {
+			logrus.Warningf("get quota report of local volumes: %v", err)
+		}
+	}
+	usages := make(map[string]quota.Usage, len(req.Paths))
+	for _, p := range req.Paths {
+		if !isLocalVolumePath(p) {
+			continue
+		}
+		if usage, ok := quota.GetUsage(report, p); ok {
+			usages[p] = usage
+			continue
+		}
+		usages[p] = quota.Usage{Used: int64(util.GetDirSize(p)) * 1024}
+	}
+	httputil.ReturnSuccess(r, w, usages)
+}
+
+func setLocalVolumeQuota(dir string, capacity int64) error {
+	q, err := quota.New(dir)
+	if err != nil {
+		return err
+	}
+	return q.SetQuota(dir, capacity)
+}
+
+func localDataPath() string {
+	localPath := os.Getenv("LOCAL_DATA_PATH")
+	if runtime.GOOS == "windows" {
+		if localPath == "" {
+			localPath = `c:\`
+		}
+	} else {
+		if localPath == "" {
+			localPath = "/grlocaldata"
+		}
+	}
+	return localPath
+}
+
+func isLocalVolumePath(p string) bool {
+	return p != "" && strings.HasPrefix(path.Clean(p), path.Join(localDataPath(), "tenant")+"/")
+}
+
 // DeleteLocalVolume delete local volume dir
 func DeleteLocalVolume(w http.ResponseWriter, r *http.Request) {
 	var requestopt = make(map[string]string)
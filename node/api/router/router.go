@@ -54,6 +54,8 @@ func Routers(mode string) *chi.Mux {
 		})
 		r.Route("/localvolumes", func(r chi.Router) {
 			r.Post("/create", controller.CreateLocalVolume)
+			r.Put("/quota", controller.SetLocalVolumeQuota)
+			r.Post("/usage", controller.GetLocalVolumeUsage)
 			r.Delete("/", controller.DeleteLocalVolume)
 		})
 		//以下只有管理节点具有的API
@@ -89,6 +91,7 @@ func Routers(mode string) *chi.Mux {
 				r.Get("/{node_id}/resource", controller.Resource)
 				r.Get("/{node_id}/conditions", controller.ListNodeCondition)
 				r.Delete("/{node_id}/conditions/{condition}", controller.DeleteNodeCondition)
+				r.Get("/{node_id}/events", controller.ListNodeEvents)
 				// about node install
 				r.Post("/{node_id}/install", controller.InstallNode)  //install node
 				r.Post("/", controller.AddNode)                       //add node
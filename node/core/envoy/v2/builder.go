@@ -24,6 +24,7 @@ import (
 
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/sirupsen/logrus"
 
@@ -33,6 +34,8 @@ import (
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
 	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
 	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	fault_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/fault/v2"
+	http_fault "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/fault/v2"
 	http_rate_limit "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/rate_limit/v2"
 	http_connection_manager "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 	tcp_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
@@ -175,6 +178,13 @@ func CreateHTTPConnectionManager(name, statPrefix string, rateOpt *RateLimitOpti
 			},
 		})
 	}
+	// routes with fault injection config need the fault filter
+	if hasRouteFilterConfig(wellknown.Fault, routes...) {
+		httpFilters = append(httpFilters, &http_connection_manager.HttpFilter{
+			Name:       wellknown.Fault,
+			ConfigType: &http_connection_manager.HttpFilter_TypedConfig{TypedConfig: Message2Any(&http_fault.HTTPFault{})},
+		})
+	}
 	httpFilters = append(httpFilters, &http_connection_manager.HttpFilter{
 		Name: wellknown.Router,
 	})
@@ -195,6 +205,20 @@ func CreateHTTPConnectionManager(name, statPrefix string, rateOpt *RateLimitOpti
 	return hcm
 }
 
+func hasRouteFilterConfig(filterName string, virtualHosts ...*route.VirtualHost) bool {
+	for _, vh := range virtualHosts {
+		if vh == nil {
+			continue
+		}
+		for _, r := range vh.Routes {
+			if _, ok := r.TypedPerFilterConfig[filterName]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 //CreateHTTPListener create http manager listener
 func CreateHTTPListener(name, address, statPrefix string, port uint32, rateOpt *RateLimitOptions, routes ...*route.VirtualHost) *apiv2.Listener {
 	hcm := CreateHTTPConnectionManager(name, statPrefix, rateOpt, routes...)
@@ -273,6 +297,12 @@ func CreateCircuitBreaker(options RainbondPluginOptions) *cluster.CircuitBreaker
 			},
 		},
 	}
+	if options.RetryBudgetPercent > 0 {
+		circuitBreakers.Thresholds[0].RetryBudget = &cluster.CircuitBreakers_Thresholds_RetryBudget{
+			BudgetPercent:       &_type.Percent{Value: options.RetryBudgetPercent},
+			MinRetryConcurrency: ConversionUInt32(uint32(options.MaxActiveRetries)),
+		}
+	}
 	if err := circuitBreakers.Validate(); err != nil {
 		logrus.Errorf("validate envoy config circuitBreakers failure %s", err.Error())
 		return nil
@@ -310,8 +340,79 @@ func CreateRouteVirtualHost(name string, domains []string, rateLimits []*route.R
 	return pvh
 }
 
+//CreateRetryPolicy create route retry policy, return nil if retry is not enabled
+func CreateRetryPolicy(options RainbondPluginOptions) *route.RetryPolicy {
+	if options.RetryOn == "" {
+		return nil
+	}
+	retryPolicy := &route.RetryPolicy{
+		RetryOn:    options.RetryOn,
+		NumRetries: ConversionUInt32(options.NumRetries),
+	}
+	if options.PerTryTimeoutMS > 0 {
+		retryPolicy.PerTryTimeout = ConverTimeDurationMS(options.PerTryTimeoutMS)
+	}
+	if err := retryPolicy.Validate(); err != nil {
+		logrus.Errorf("validate route retry policy failure %s", err.Error())
+		return nil
+	}
+	return retryPolicy
+}
+
+//CreateHTTPFault create http fault injection config, return nil if fault injection is not enabled
+func CreateHTTPFault(options RainbondPluginOptions) *http_fault.HTTPFault {
+	var fault http_fault.HTTPFault
+	if options.FaultDelayMS > 0 && options.FaultDelayPercent > 0 {
+		fault.Delay = &fault_config.FaultDelay{
+			FaultDelaySecifier: &fault_config.FaultDelay_FixedDelay{
+				FixedDelay: ConverTimeDurationMS(options.FaultDelayMS),
+			},
+			Percentage: &_type.FractionalPercent{
+				Numerator:   options.FaultDelayPercent,
+				Denominator: _type.FractionalPercent_HUNDRED,
+			},
+		}
+	}
+	if options.FaultAbortStatus > 0 && options.FaultAbortPercent > 0 {
+		fault.Abort = &http_fault.FaultAbort{
+			ErrorType: &http_fault.FaultAbort_HttpStatus{
+				HttpStatus: options.FaultAbortStatus,
+			},
+			Percentage: &_type.FractionalPercent{
+				Numerator:   options.FaultAbortPercent,
+				Denominator: _type.FractionalPercent_HUNDRED,
+			},
+		}
+	}
+	if fault.Delay == nil && fault.Abort == nil {
+		return nil
+	}
+	if err := fault.Validate(); err != nil {
+		logrus.Errorf("validate http fault config failure %s", err.Error())
+		return nil
+	}
+	return &fault
+}
+
+//applyRouteGovernance set retry policy, timeout and fault injection of the route
+func applyRouteGovernance(rout *route.Route, options *RainbondPluginOptions) {
+	if options == nil {
+		return
+	}
+	action := rout.Action.(*route.Route_Route).Route
+	action.RetryPolicy = CreateRetryPolicy(*options)
+	if options.TimeoutMS > 0 {
+		action.Timeout = ConverTimeDurationMS(options.TimeoutMS)
+	}
+	if fault := CreateHTTPFault(*options); fault != nil {
+		rout.TypedPerFilterConfig = map[string]*any.Any{
+			wellknown.Fault: Message2Any(fault),
+		}
+	}
+}
+
 //CreateRouteWithHostRewrite create route with hostRewrite
-func CreateRouteWithHostRewrite(host, clusterName, prefix string, headers []*route.HeaderMatcher, weight uint32) *route.Route {
+func CreateRouteWithHostRewrite(host, clusterName, prefix string, headers []*route.HeaderMatcher, weight uint32, options *RainbondPluginOptions) *route.Route {
 	var rout *route.Route
 	if host != "" {
 		var hostRewriteSpecifier *route.RouteAction_HostRewrite
@@ -343,6 +444,7 @@ func CreateRouteWithHostRewrite(host, clusterName, prefix string, headers []*rou
 				},
 			},
 		}
+		applyRouteGovernance(rout, options)
 		if err := rout.Validate(); err != nil {
 			logrus.Errorf("route http route config validate failure %s", err.Error())
 			return nil
@@ -353,7 +455,7 @@ func CreateRouteWithHostRewrite(host, clusterName, prefix string, headers []*rou
 }
 
 //CreateRoute create http route
-func CreateRoute(clusterName, prefix string, headers []*route.HeaderMatcher, weight uint32) *route.Route {
+func CreateRoute(clusterName, prefix string, headers []*route.HeaderMatcher, weight uint32, options *RainbondPluginOptions) *route.Route {
 	rout := &route.Route{
 		Match: &route.RouteMatch{
 			PathSpecifier: &route.RouteMatch_Prefix{
@@ -377,7 +479,7 @@ func CreateRoute(clusterName, prefix string, headers []*route.HeaderMatcher, wei
 			},
 		},
 	}
-
+	applyRouteGovernance(rout, options)
 	if err := rout.Validate(); err != nil {
 		logrus.Errorf("route http route config validate failure %s", err.Error())
 		return nil
@@ -0,0 +1,148 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package v2
+
+import (
+	"testing"
+	"time"
+
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	http_fault "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/fault/v2"
+	http_connection_manager "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	trace "github.com/envoyproxy/go-control-plane/envoy/config/trace/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/goodrain/rainbond/util/tracing"
+)
+
+func TestCreateRouteWithGovernance(t *testing.T) {
+	options := GetOptionValues(map[string]interface{}{
+		KeyRetryOn:           "5xx, connect-failure,unknown",
+		KeyNumRetries:        "3",
+		KeyPerTryTimeoutMS:   "500",
+		KeyTimeoutMS:         "3000",
+		KeyFaultDelayMS:      "200",
+		KeyFaultDelayPercent: "10",
+		KeyFaultAbortStatus:  "503",
+		KeyFaultAbortPercent: "150",
+	})
+	r := CreateRoute("test_cluster", "/", nil, 100, &options)
+	if r == nil {
+		t.Fatal("create route failure")
+	}
+	action := r.Action.(*route.Route_Route).Route
+	if action.RetryPolicy == nil {
+		t.Fatal("retry policy is not set")
+	}
+	if action.RetryPolicy.RetryOn != "5xx,connect-failure" {
+		t.Errorf("unexpected retry on %s", action.RetryPolicy.RetryOn)
+	}
+	if action.RetryPolicy.NumRetries.GetValue() != 3 {
+		t.Errorf("unexpected num retries %d", action.RetryPolicy.NumRetries.GetValue())
+	}
+	if d, _ := ptypes.Duration(action.RetryPolicy.PerTryTimeout); d != 500*time.Millisecond {
+		t.Errorf("unexpected per try timeout %s", d)
+	}
+	if d, _ := ptypes.Duration(action.Timeout); d != 3*time.Second {
+		t.Errorf("unexpected timeout %s", d)
+	}
+	faultConfig, ok := r.TypedPerFilterConfig[wellknown.Fault]
+	if !ok {
+		t.Fatal("fault injection config is not set")
+	}
+	var fault http_fault.HTTPFault
+	if err := ptypes.UnmarshalAny(faultConfig, &fault); err != nil {
+		t.Fatal(err)
+	}
+	if fault.Delay.Percentage.Numerator != 10 {
+		t.Errorf("unexpected delay percentage %d", fault.Delay.Percentage.Numerator)
+	}
+	if fault.Abort.GetHttpStatus() != 503 || fault.Abort.Percentage.Numerator != 100 {
+		t.Errorf("unexpected abort config %v", fault.Abort)
+	}
+	vh := CreateRouteVirtualHost("test", []string{"*"}, nil, r)
+	hcm := CreateHTTPConnectionManager("test", "test", nil, vh)
+	if hcm == nil {
+		t.Fatal("create http connection manager failure")
+	}
+	if len(hcm.HttpFilters) != 2 || hcm.HttpFilters[0].Name != wellknown.Fault || hcm.HttpFilters[1].Name != wellknown.Router {
+		t.Errorf("unexpected http filters %v", hcm.HttpFilters)
+	}
+}
+
+func TestCreateRouteWithoutGovernance(t *testing.T) {
+	options := GetOptionValues(nil)
+	r := CreateRoute("test_cluster", "/", nil, 100, &options)
+	if r == nil {
+		t.Fatal("create route failure")
+	}
+	action := r.Action.(*route.Route_Route).Route
+	if action.RetryPolicy != nil || action.Timeout != nil || len(r.TypedPerFilterConfig) != 0 {
+		t.Errorf("default options should not set governance config: %v", r)
+	}
+	hcm := CreateHTTPConnectionManager("test", "test", nil, CreateRouteVirtualHost("test", []string{"*"}, nil, r))
+	if len(hcm.HttpFilters) != 1 {
+		t.Errorf("unexpected http filters %v", hcm.HttpFilters)
+	}
+}
+
+func TestCreateCircuitBreakerWithRetryBudget(t *testing.T) {
+	options := GetOptionValues(map[string]interface{}{KeyRetryBudgetPercent: "20"})
+	cb := CreateCircuitBreaker(options)
+	if cb == nil {
+		t.Fatal("create circuit breaker failure")
+	}
+	budget := cb.Thresholds[0].RetryBudget
+	if budget == nil || budget.BudgetPercent.Value != 20 || budget.MinRetryConcurrency.GetValue() != 3 {
+		t.Errorf("unexpected retry budget %v", budget)
+	}
+}
+
+func TestApplyListenerTracing(t *testing.T) {
+	r := CreateRoute("test_cluster", "/", nil, 100, &RainbondPluginOptions{})
+	listener := CreateHTTPListener("test", "0.0.0.0", "test", 8080, nil, CreateRouteVirtualHost("test", []string{"*"}, nil, r))
+	listener = ApplyListenerTracing(listener, &TracingOptions{Provider: tracing.ProviderZipkin, Endpoint: "http://zipkin.tracing:9411", SampleRate: 20})
+	if listener == nil {
+		t.Fatal("apply tracing failure")
+	}
+	var hcm http_connection_manager.HttpConnectionManager
+	if err := ptypes.UnmarshalAny(listener.FilterChains[0].Filters[0].GetTypedConfig(), &hcm); err != nil {
+		t.Fatal(err)
+	}
+	if hcm.Tracing == nil || hcm.Tracing.RandomSampling.Value != 20 || hcm.Tracing.Provider.Name != wellknown.Zipkin {
+		t.Fatalf("unexpected tracing config %v", hcm.Tracing)
+	}
+	var zipkin trace.ZipkinConfig
+	if err := ptypes.UnmarshalAny(hcm.Tracing.Provider.GetTypedConfig(), &zipkin); err != nil {
+		t.Fatal(err)
+	}
+	if zipkin.CollectorCluster != DefaultTracingClusterName || zipkin.CollectorEndpoint != tracing.DefaultZipkinCollectorPath {
+		t.Errorf("unexpected zipkin config %v", zipkin)
+	}
+	if cluster := CreateTracingCollectorCluster(&TracingOptions{Provider: tracing.ProviderOTLP, Endpoint: "http://otel-collector:4317"}); cluster == nil || cluster.Http2ProtocolOptions == nil {
+		t.Errorf("unexpected otlp collector cluster %v", cluster)
+	}
+	for _, c := range []struct{ port, want int }{{0, tracing.DefaultOpenCensusPort}, {55679, 55679}} {
+		options := &TracingOptions{Provider: tracing.ProviderOTLP, Endpoint: "http://otel-collector:4317", OpenCensusPort: c.port}
+		if _, _, port, err := options.collector(); err != nil || port != c.want {
+			t.Errorf("want opencensus port %d, but got %d(%v)", c.want, port, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package v2
+
+import (
+	"fmt"
+	"strings"
+
+	apiv2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	network_rbac "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/rbac/v2"
+	rbac "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v2"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/sirupsen/logrus"
+
+	"github.com/goodrain/rainbond/node/utils"
+)
+
+const (
+	//MeshCertificateSecretName the sds secret name of the workload certificate
+	MeshCertificateSecretName = "rainbond_mesh_workload_cert"
+	//MeshValidationSecretName the sds secret name of the region mesh ca
+	MeshValidationSecretName = "rainbond_mesh_root_ca"
+	//MTLSModeStrict only accept mutual tls traffic
+	MTLSModeStrict = "STRICT"
+	//MTLSModePermissive accept both mutual tls and plaintext traffic
+	MTLSModePermissive = "PERMISSIVE"
+)
+
+//DefaultMeshTrustDomain the trust domain of mesh workload identity if it is not specified
+const DefaultMeshTrustDomain = "cluster.local"
+
+//MeshTLSOptions mutual tls options of one envoy node
+type MeshTLSOptions struct {
+	Enable bool
+	// Mode STRICT or PERMISSIVE, default PERMISSIVE
+	Mode string
+	// AllowedCallers service alias list which allowed to call the inbound listener
+	AllowedCallers []string
+	// TrustDomain the trust domain of mesh workload identity, default cluster.local
+	TrustDomain string
+}
+
+//MeshIdentity create the spiffe identity of the component
+func MeshIdentity(trustDomain, namespace, serviceAlias string) string {
+	if trustDomain == "" {
+		trustDomain = DefaultMeshTrustDomain
+	}
+	return fmt.Sprintf("spiffe://%s/ns/%s/sa/%s", trustDomain, namespace, serviceAlias)
+}
+
+//CreateTLSCertificateSecret create sds tls certificate secret
+func CreateTLSCertificateSecret(name string, certPEM, keyPEM []byte) *auth.Secret {
+	secret := &auth.Secret{
+		Name: name,
+		Type: &auth.Secret_TlsCertificate{
+			TlsCertificate: &auth.TlsCertificate{
+				CertificateChain: &core.DataSource{
+					Specifier: &core.DataSource_InlineBytes{InlineBytes: certPEM},
+				},
+				PrivateKey: &core.DataSource{
+					Specifier: &core.DataSource_InlineBytes{InlineBytes: keyPEM},
+				},
+			},
+		},
+	}
+	if err := secret.Validate(); err != nil {
+		logrus.Errorf("validate tls certificate secret failure %s", err.Error())
+		return nil
+	}
+	return secret
+}
+
+//CreateValidationContextSecret create sds validation context secret
+func CreateValidationContextSecret(name string, caPEM []byte) *auth.Secret {
+	secret := &auth.Secret{
+		Name: name,
+		Type: &auth.Secret_ValidationContext{
+			ValidationContext: &auth.CertificateValidationContext{
+				TrustedCa: &core.DataSource{
+					Specifier: &core.DataSource_InlineBytes{InlineBytes: caPEM},
+				},
+			},
+		},
+	}
+	if err := secret.Validate(); err != nil {
+		logrus.Errorf("validate validation context secret failure %s", err.Error())
+		return nil
+	}
+	return secret
+}
+
+//CreateSDSSecretConfig create sds secret config, secrets are discovered from rainbond xds server
+func CreateSDSSecretConfig(name string) *auth.SdsSecretConfig {
+	return &auth.SdsSecretConfig{
+		Name: name,
+		SdsConfig: &core.ConfigSource{
+			ConfigSourceSpecifier: &core.ConfigSource_ApiConfigSource{
+				ApiConfigSource: &core.ApiConfigSource{
+					ApiType: core.ApiConfigSource_GRPC,
+					GrpcServices: []*core.GrpcService{
+						{
+							TargetSpecifier: &core.GrpcService_EnvoyGrpc_{
+								EnvoyGrpc: &core.GrpcService_EnvoyGrpc{
+									ClusterName: "rainbond_xds_cluster",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+//CreateMeshCommonTLSContext create common tls context with workload certificate and region ca
+//peerIdentities limit the spiffe uri san of peer certificate, if empty, any certificate signed by region ca is accepted
+func CreateMeshCommonTLSContext(peerIdentities []string) *auth.CommonTlsContext {
+	commonTLSContext := &auth.CommonTlsContext{
+		TlsParams: &auth.TlsParameters{
+			TlsMinimumProtocolVersion: auth.TlsParameters_TLSv1_2,
+		},
+		TlsCertificateSdsSecretConfigs: []*auth.SdsSecretConfig{CreateSDSSecretConfig(MeshCertificateSecretName)},
+	}
+	if len(peerIdentities) == 0 {
+		commonTLSContext.ValidationContextType = &auth.CommonTlsContext_ValidationContextSdsSecretConfig{
+			ValidationContextSdsSecretConfig: CreateSDSSecretConfig(MeshValidationSecretName),
+		}
+		return commonTLSContext
+	}
+	var sanMatchers []*matcher.StringMatcher
+	for _, identity := range peerIdentities {
+		sanMatchers = append(sanMatchers, &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Exact{Exact: identity},
+		})
+	}
+	commonTLSContext.ValidationContextType = &auth.CommonTlsContext_CombinedValidationContext{
+		CombinedValidationContext: &auth.CommonTlsContext_CombinedCertificateValidationContext{
+			DefaultValidationContext: &auth.CertificateValidationContext{
+				MatchSubjectAltNames: sanMatchers,
+			},
+			ValidationContextSdsSecretConfig: CreateSDSSecretConfig(MeshValidationSecretName),
+		},
+	}
+	return commonTLSContext
+}
+
+//CreateUpstreamMTLSTransportSocket create cluster transport socket with mutual tls
+func CreateUpstreamMTLSTransportSocket(sni string, peerIdentities ...string) *core.TransportSocket {
+	tlsContext := &auth.UpstreamTlsContext{
+		CommonTlsContext: CreateMeshCommonTLSContext(peerIdentities),
+		Sni:              sni,
+	}
+	if err := tlsContext.Validate(); err != nil {
+		logrus.Errorf("validate upstream tls context failure %s", err.Error())
+		return nil
+	}
+	return &core.TransportSocket{
+		Name:       utils.EnvoyTLSSocketName,
+		ConfigType: &core.TransportSocket_TypedConfig{TypedConfig: Message2Any(tlsContext)},
+	}
+}
+
+//CreateDownstreamMTLSTransportSocket create listener transport socket with mutual tls, client certificate is required
+func CreateDownstreamMTLSTransportSocket() *core.TransportSocket {
+	tlsContext := &auth.DownstreamTlsContext{
+		CommonTlsContext:         CreateMeshCommonTLSContext(nil),
+		RequireClientCertificate: &wrappers.BoolValue{Value: true},
+	}
+	if err := tlsContext.Validate(); err != nil {
+		logrus.Errorf("validate downstream tls context failure %s", err.Error())
+		return nil
+	}
+	return &core.TransportSocket{
+		Name:       utils.EnvoyTLSSocketName,
+		ConfigType: &core.TransportSocket_TypedConfig{TypedConfig: Message2Any(tlsContext)},
+	}
+}
+
+//CreateNetworkRBACFilter create network rbac filter, only allow the given identities
+//An allow policy without any identity denies all traffic, so nil is returned if identities is empty.
+func CreateNetworkRBACFilter(statPrefix string, identities []string) *envoy_api_v2_listener.Filter {
+	if len(identities) == 0 {
+		return nil
+	}
+	var principals []*rbac.Principal
+	for _, identity := range identities {
+		principals = append(principals, &rbac.Principal{
+			Identifier: &rbac.Principal_Authenticated_{
+				Authenticated: &rbac.Principal_Authenticated{
+					PrincipalName: &matcher.StringMatcher{
+						MatchPattern: &matcher.StringMatcher_Exact{Exact: identity},
+					},
+				},
+			},
+		})
+	}
+	policies := map[string]*rbac.Policy{
+		"allowed-callers": {
+			Permissions: []*rbac.Permission{{Rule: &rbac.Permission_Any{Any: true}}},
+			Principals:  principals,
+		},
+	}
+	config := &network_rbac.RBAC{
+		StatPrefix: statPrefix,
+		Rules: &rbac.RBAC{
+			Action:   rbac.RBAC_ALLOW,
+			Policies: policies,
+		},
+	}
+	if err := config.Validate(); err != nil {
+		logrus.Errorf("validate network rbac config failure %s", err.Error())
+		return nil
+	}
+	return &envoy_api_v2_listener.Filter{
+		Name:       wellknown.RoleBasedAccessControl,
+		ConfigType: &envoy_api_v2_listener.Filter_TypedConfig{TypedConfig: Message2Any(config)},
+	}
+}
+
+//ApplyListenerMTLS add a mutual tls filter chain with rbac to the listener
+//In PERMISSIVE mode, plaintext filter chain is retained for the traffic that does not come from mesh, like gateway.
+//The rbac filter is only added to the mutual tls filter chain, plaintext traffic carries no identity to match.
+//No rbac filter is added if there is no allowed caller, any workload with a certificate signed by region ca is accepted.
+func ApplyListenerMTLS(listener *apiv2.Listener, namespace string, options *MeshTLSOptions) *apiv2.Listener {
+	if listener == nil || options == nil || !options.Enable || len(listener.FilterChains) == 0 {
+		return listener
+	}
+	plaintext := listener.FilterChains[0]
+	transportSocket := CreateDownstreamMTLSTransportSocket()
+	if transportSocket == nil {
+		return listener
+	}
+	var identities []string
+	for _, caller := range options.AllowedCallers {
+		if caller = strings.TrimSpace(caller); caller != "" {
+			identities = append(identities, MeshIdentity(options.TrustDomain, namespace, caller))
+		}
+	}
+	// the secure filter chain does not share the backing array with the plaintext one
+	filters := make([]*envoy_api_v2_listener.Filter, 0, len(plaintext.Filters)+1)
+	if len(identities) > 0 {
+		rbacFilter := CreateNetworkRBACFilter(listener.Name, identities)
+		if rbacFilter == nil {
+			logrus.Errorf("create rbac filter of listener %s failure, mutual tls is not applied", listener.Name)
+			return listener
+		}
+		filters = append(filters, rbacFilter)
+	}
+	filters = append(filters, plaintext.Filters...)
+	secure := &envoy_api_v2_listener.FilterChain{
+		FilterChainMatch: &envoy_api_v2_listener.FilterChainMatch{TransportProtocol: "tls"},
+		Filters:          filters,
+		TransportSocket:  transportSocket,
+	}
+	if options.Mode == MTLSModeStrict {
+		listener.FilterChains = []*envoy_api_v2_listener.FilterChain{secure}
+	} else {
+		listener.FilterChains = []*envoy_api_v2_listener.FilterChain{secure, plaintext}
+	}
+	listener.ListenerFilters = append(listener.ListenerFilters, &envoy_api_v2_listener.ListenerFilter{
+		Name: wellknown.TlsInspector,
+	})
+	if err := listener.Validate(); err != nil {
+		logrus.Errorf("validate mtls listener config failure %s", err.Error())
+		return nil
+	}
+	return listener
+}
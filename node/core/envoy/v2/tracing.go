@@ -0,0 +1,195 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package v2
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	apiv2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	http_connection_manager "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	trace "github.com/envoyproxy/go-control-plane/envoy/config/trace/v2"
+	_type "github.com/envoyproxy/go-control-plane/envoy/type"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/goodrain/rainbond/node/utils"
+	"github.com/goodrain/rainbond/util/tracing"
+)
+
+//OpenCensusTracerName the name of envoy opencensus tracer
+const OpenCensusTracerName = "envoy.tracers.opencensus"
+
+//DefaultTracingClusterName the cluster name of tracing collector
+var DefaultTracingClusterName = "rainbond_tracing_collector"
+
+//TracingOptions tracing options of one envoy node
+type TracingOptions struct {
+	// Provider zipkin or otlp
+	Provider string
+	// Endpoint the collector endpoint, eg. http://zipkin:9411/api/v2/spans
+	Endpoint string
+	// SampleRate 0-100
+	SampleRate int
+	// OpenCensusPort the port of opencensus receiver of the otlp collector, default 55678
+	OpenCensusPort int
+}
+
+func (t *TracingOptions) collector() (*url.URL, string, int, error) {
+	u, err := url.Parse(t.Endpoint)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	host := u.Hostname()
+	if host == "" {
+		return nil, "", 0, fmt.Errorf("host of tracing endpoint %s is empty", t.Endpoint)
+	}
+	if t.Provider == tracing.ProviderOTLP {
+		if t.OpenCensusPort > 0 {
+			return u, host, t.OpenCensusPort, nil
+		}
+		return u, host, tracing.DefaultOpenCensusPort, nil
+	}
+	port := 9411
+	if u.Port() != "" {
+		port, err = strconv.Atoi(u.Port())
+		if err != nil {
+			return nil, "", 0, err
+		}
+	} else if u.Scheme == "https" {
+		port = 443
+	}
+	return u, host, port, nil
+}
+
+//CreateTracingCollectorCluster create the cluster of tracing collector
+func CreateTracingCollectorCluster(options *TracingOptions) *apiv2.Cluster {
+	if options == nil {
+		return nil
+	}
+	u, host, port, err := options.collector()
+	if err != nil {
+		logrus.Errorf("parse tracing endpoint %s failure %s", options.Endpoint, err.Error())
+		return nil
+	}
+	clusterOption := ClusterOptions{
+		Name:                  DefaultTracingClusterName,
+		ClusterType:           apiv2.Cluster_STRICT_DNS,
+		ConnectionTimeout:     ConverTimeDuration(1),
+		Hosts:                 []*core.Address{CreateSocketAddress("tcp", host, uint32(port))},
+		HealthyPanicThreshold: 50,
+	}
+	if options.Provider == tracing.ProviderOTLP {
+		clusterOption.Protocol = "http2"
+	} else if u.Scheme == "https" {
+		tlsContext, err := ptypes.MarshalAny(&auth.UpstreamTlsContext{Sni: host})
+		if err == nil {
+			clusterOption.TransportSocket = &core.TransportSocket{
+				Name:       utils.EnvoyTLSSocketName,
+				ConfigType: &core.TransportSocket_TypedConfig{TypedConfig: tlsContext},
+			}
+		}
+	}
+	return CreateCluster(clusterOption)
+}
+
+//CreateHTTPTracing create http connection manager tracing config
+func CreateHTTPTracing(options *TracingOptions) *http_connection_manager.HttpConnectionManager_Tracing {
+	if options == nil {
+		return nil
+	}
+	u, _, _, err := options.collector()
+	if err != nil {
+		logrus.Errorf("parse tracing endpoint %s failure %s", options.Endpoint, err.Error())
+		return nil
+	}
+	var provider *trace.Tracing_Http
+	switch options.Provider {
+	case tracing.ProviderZipkin:
+		path := u.Path
+		if path == "" || path == "/" {
+			path = tracing.DefaultZipkinCollectorPath
+		}
+		provider = &trace.Tracing_Http{
+			Name: wellknown.Zipkin,
+			ConfigType: &trace.Tracing_Http_TypedConfig{TypedConfig: Message2Any(&trace.ZipkinConfig{
+				CollectorCluster:         DefaultTracingClusterName,
+				CollectorEndpoint:        path,
+				CollectorEndpointVersion: trace.ZipkinConfig_HTTP_JSON,
+			})},
+		}
+	case tracing.ProviderOTLP:
+		contexts := []trace.OpenCensusConfig_TraceContext{trace.OpenCensusConfig_TRACE_CONTEXT, trace.OpenCensusConfig_B3}
+		provider = &trace.Tracing_Http{
+			Name: OpenCensusTracerName,
+			ConfigType: &trace.Tracing_Http_TypedConfig{TypedConfig: Message2Any(&trace.OpenCensusConfig{
+				OcagentExporterEnabled: true,
+				OcagentGrpcService: &core.GrpcService{
+					TargetSpecifier: &core.GrpcService_EnvoyGrpc_{
+						EnvoyGrpc: &core.GrpcService_EnvoyGrpc{ClusterName: DefaultTracingClusterName},
+					},
+				},
+				IncomingTraceContext: contexts,
+				OutgoingTraceContext: contexts,
+			})},
+		}
+	default:
+		logrus.Warningf("tracing provider %s is not supported", options.Provider)
+		return nil
+	}
+	return &http_connection_manager.HttpConnectionManager_Tracing{
+		RandomSampling: &_type.Percent{Value: float64(options.SampleRate)},
+		Provider:       provider,
+	}
+}
+
+//ApplyListenerTracing enable tracing for all http connection managers of the listener
+func ApplyListenerTracing(listener *apiv2.Listener, options *TracingOptions) *apiv2.Listener {
+	if listener == nil || options == nil {
+		return listener
+	}
+	tracing := CreateHTTPTracing(options)
+	if tracing == nil {
+		return listener
+	}
+	for _, chain := range listener.FilterChains {
+		for _, filter := range chain.Filters {
+			if filter.Name != wellknown.HTTPConnectionManager || filter.GetTypedConfig() == nil {
+				continue
+			}
+			var hcm http_connection_manager.HttpConnectionManager
+			if err := ptypes.UnmarshalAny(filter.GetTypedConfig(), &hcm); err != nil {
+				logrus.Errorf("unmarshal http connection manager of listener %s failure %s", listener.Name, err.Error())
+				continue
+			}
+			hcm.Tracing = tracing
+			filter.ConfigType = &envoy_api_v2_listener.Filter_TypedConfig{TypedConfig: Message2Any(&hcm)}
+		}
+	}
+	if err := listener.Validate(); err != nil {
+		logrus.Errorf("validate tracing listener config failure %s", err.Error())
+		return nil
+	}
+	return listener
+}
@@ -24,6 +24,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/envoyproxy/go-control-plane/pkg/conversion"
 	"github.com/gogo/protobuf/proto"
@@ -83,6 +84,11 @@ func ConverTimeDuration(second int64) *duration.Duration {
 	}
 }
 
+//ConverTimeDurationMS millisecond
+func ConverTimeDurationMS(ms int64) *duration.Duration {
+	return ptypes.DurationProto(time.Duration(ms) * time.Millisecond)
+}
+
 const (
 	//KeyPrefix request path prefix
 	KeyPrefix string = "Prefix"
@@ -134,8 +140,30 @@ const (
 	KeyHealthCheckTimeout string = "HealthCheckTimeout"
 	// cluster health check interval
 	KeyHealthCheckInterval string = "HealthCheckInterval"
+	//KeyRetryOn Specifies the conditions under which retry takes place, comma separated.
+	//support 5xx,gateway-error,connect-failure,retriable-4xx,refused-stream,reset
+	KeyRetryOn string = "RetryOn"
+	//KeyNumRetries Specifies the allowed number of retries. default is 1
+	KeyNumRetries string = "NumRetries"
+	//KeyPerTryTimeoutMS Specifies a non-zero upstream timeout per retry attempt.
+	KeyPerTryTimeoutMS string = "PerTryTimeoutMS"
+	//KeyTimeoutMS Specifies the upstream timeout for the route. If not specified, the default is 15s.
+	KeyTimeoutMS string = "TimeoutMS"
+	//KeyRetryBudgetPercent the percentage of active requests that can be retrying, replace MaxActiveRetries if set.
+	KeyRetryBudgetPercent string = "RetryBudgetPercent"
+	//KeyFaultDelayMS fixed delay of fault injection
+	KeyFaultDelayMS string = "FaultDelayMS"
+	//KeyFaultDelayPercent the percentage of requests which will be delayed
+	KeyFaultDelayPercent string = "FaultDelayPercent"
+	//KeyFaultAbortStatus http status of the requests aborted by fault injection
+	KeyFaultAbortStatus string = "FaultAbortStatus"
+	//KeyFaultAbortPercent the percentage of requests which will be aborted
+	KeyFaultAbortPercent string = "FaultAbortPercent"
 )
 
+//retryOnConditions the retry conditions supported by rainbond mesh
+var retryOnConditions = []string{"5xx", "gateway-error", "connect-failure", "retriable-4xx", "refused-stream", "reset"}
+
 //RainbondPluginOptions rainbond plugin config struct
 type RainbondPluginOptions struct {
 	Prefix                   string
@@ -157,12 +185,23 @@ type RainbondPluginOptions struct {
 	GrpcHealthServiceName    string
 	HealthCheckTimeout       int64
 	HealthCheckInterval      int64
+	RetryOn                  string
+	NumRetries               uint32
+	PerTryTimeoutMS          int64
+	TimeoutMS                int64
+	RetryBudgetPercent       float64
+	FaultDelayMS             int64
+	FaultDelayPercent        uint32
+	FaultAbortStatus         uint32
+	FaultAbortPercent        uint32
 }
 
 //RainbondInboundPluginOptions rainbond inbound plugin options
 type RainbondInboundPluginOptions struct {
 	OpenLimit   bool
 	LimitDomain string
+	// MTLSMode STRICT or PERMISSIVE, only effective if the app enable mutual tls
+	MTLSMode string
 }
 
 //RouteBasicHash get basic hash for weight
@@ -197,6 +236,7 @@ func GetOptionValues(sr map[string]interface{}) RainbondPluginOptions {
 		TCPIdleTimeout:        60 * 60 * 2,
 		HealthCheckTimeout:    5,
 		HealthCheckInterval:   4,
+		NumRetries:            1,
 	}
 	if sr == nil {
 		return rpo
@@ -297,11 +337,65 @@ func GetOptionValues(sr map[string]interface{}) RainbondPluginOptions {
 			}
 		case KeyGrpcHealthServiceName:
 			rpo.GrpcHealthServiceName = strings.TrimSpace(v.(string))
+		case KeyRetryOn:
+			var conditions []string
+			for _, c := range strings.Split(v.(string), ",") {
+				c = strings.TrimSpace(c)
+				for _, support := range retryOnConditions {
+					if c == support {
+						conditions = append(conditions, c)
+					}
+				}
+			}
+			rpo.RetryOn = strings.Join(conditions, ",")
+		case KeyNumRetries:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.NumRetries = uint32(i)
+			}
+		case KeyPerTryTimeoutMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.PerTryTimeoutMS = int64(i)
+			}
+		case KeyTimeoutMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.TimeoutMS = int64(i)
+			}
+		case KeyRetryBudgetPercent:
+			if f, err := strconv.ParseFloat(v.(string), 64); err == nil && f > 0 {
+				if f > 100 {
+					rpo.RetryBudgetPercent = 100
+				} else {
+					rpo.RetryBudgetPercent = f
+				}
+			}
+		case KeyFaultDelayMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.FaultDelayMS = int64(i)
+			}
+		case KeyFaultDelayPercent:
+			rpo.FaultDelayPercent = parsePercent(v.(string))
+		case KeyFaultAbortStatus:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i >= 200 && i < 600 {
+				rpo.FaultAbortStatus = uint32(i)
+			}
+		case KeyFaultAbortPercent:
+			rpo.FaultAbortPercent = parsePercent(v.(string))
 		}
 	}
 	return rpo
 }
 
+func parsePercent(value string) uint32 {
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || i <= 0 {
+		return 0
+	}
+	if i > 100 {
+		return 100
+	}
+	return uint32(i)
+}
+
 //GetRainbondInboundPluginOptions get rainbond inbound plugin options
 func GetRainbondInboundPluginOptions(sr map[string]interface{}) (r RainbondInboundPluginOptions) {
 	for k, v := range sr {
@@ -312,6 +406,13 @@ func GetRainbondInboundPluginOptions(sr map[string]interface{}) (r RainbondInbou
 			}
 		case "LIMIT_DOMAIN":
 			r.LimitDomain = v.(string)
+		case "MTLS_MODE":
+			switch strings.ToUpper(strings.TrimSpace(v.(string))) {
+			case MTLSModeStrict:
+				r.MTLSMode = MTLSModeStrict
+			case MTLSModePermissive:
+				r.MTLSMode = MTLSModePermissive
+			}
 		}
 	}
 	return
@@ -29,6 +29,7 @@ import (
 	"github.com/goodrain/rainbond/cmd/node/option"
 	"github.com/goodrain/rainbond/event"
 	"github.com/goodrain/rainbond/node/api/model"
+	"github.com/goodrain/rainbond/node/core/store"
 	"github.com/goodrain/rainbond/node/kubecache"
 	"github.com/goodrain/rainbond/node/masterserver/node"
 	"github.com/goodrain/rainbond/node/nodem/client"
@@ -233,6 +234,18 @@ func (n *NodeService) GetNode(nodeID string) (*client.HostNode, *utils.APIHandle
 	return node, nil
 }
 
+//ListNodeEvents list the remediation events of the node
+func (n *NodeService) ListNodeEvents(nodeID string) ([]*client.NodeEvent, *utils.APIHandleError) {
+	if _, err := n.GetNode(nodeID); err != nil {
+		return nil, err
+	}
+	events, err := client.ListNodeEvents(store.DefalutClient.Client, nodeID)
+	if err != nil {
+		return nil, utils.CreateAPIHandleError(500, fmt.Errorf("list node events failure %s", err.Error()))
+	}
+	return events, nil
+}
+
 //GetAllNode get all node
 func (n *NodeService) GetAllNode() ([]*client.HostNode, *utils.APIHandleError) {
 	if n.nodecluster == nil {
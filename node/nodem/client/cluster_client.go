@@ -52,6 +52,7 @@ type ClusterClient interface {
 	GetEndpoints(key string) []string
 	SetEndpoints(serviceName, hostIP string, value []string)
 	DelEndpoints(key string)
+	RecordEvent(event *NodeEvent)
 }
 
 //NewClusterClient new cluster client
@@ -104,7 +105,26 @@ func (e *etcdClusterClient) UpdateStatus(n *HostNode, deleteConditions []NodeCon
 }
 
 func (e *etcdClusterClient) GetMasters() ([]*HostNode, error) {
-	return nil, nil
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	res, err := e.conf.EtcdCli.Get(ctx, e.conf.NodePath+"/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	var masters []*HostNode
+	for _, kv := range res.Kvs {
+		if node := GetNodeFromKV(kv); node != nil && node.Role.HasRule(ManageNode) {
+			masters = append(masters, node)
+		}
+	}
+	return masters, nil
+}
+
+//RecordEvent record node event, failure only be logged
+func (e *etcdClusterClient) RecordEvent(event *NodeEvent) {
+	if err := PutNodeEvent(e.conf.EtcdCli, event); err != nil {
+		logrus.Errorf("record node %s event %s failure %s", event.NodeID, event.Action, err.Error())
+	}
 }
 
 func (e *etcdClusterClient) GetDataCenterConfig() (*config.DataCenterConfig, error) {
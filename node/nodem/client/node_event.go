@@ -0,0 +1,114 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+)
+
+//NodeEventPrefix is the prefix of the key of node events in etcd
+const NodeEventPrefix = "/rainbond/node_events"
+
+//NodeEventTTL node events expire after 7 days
+var NodeEventTTL int64 = 7 * 24 * 3600
+
+//NodeEventType node event type
+type NodeEventType string
+
+//NodeEventNormal the action succeeded
+var NodeEventNormal NodeEventType = "Normal"
+
+//NodeEventWarning the action failed or the node is in trouble
+var NodeEventWarning NodeEventType = "Warning"
+
+//NodeEvent record of the remediation action done by node
+type NodeEvent struct {
+	NodeID string        `json:"node_id"`
+	Type   NodeEventType `json:"type"`
+	// Service the service whose health probe triggers the action
+	Service string `json:"service"`
+	// Action restart, cordon or image_gc
+	Action     string    `json:"action"`
+	Reason     string    `json:"reason"`
+	Message    string    `json:"message"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+//NewNodeEvent create a node event
+func NewNodeEvent(nodeID, serviceName, action string, err error, reason string) *NodeEvent {
+	event := &NodeEvent{
+		NodeID:     nodeID,
+		Type:       NodeEventNormal,
+		Service:    serviceName,
+		Action:     action,
+		Reason:     reason,
+		Message:    fmt.Sprintf("%s success", action),
+		CreateTime: time.Now(),
+	}
+	if err != nil {
+		event.Type = NodeEventWarning
+		event.Message = fmt.Sprintf("%s failure %s", action, err.Error())
+	}
+	return event
+}
+
+//PutNodeEvent save node event to etcd
+func PutNodeEvent(cli *clientv3.Client, event *NodeEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	lease, err := cli.Grant(ctx, NodeEventTTL)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s/%s/%d", NodeEventPrefix, event.NodeID, event.CreateTime.UnixNano())
+	_, err = cli.Put(ctx, key, string(body), clientv3.WithLease(lease.ID))
+	return err
+}
+
+//ListNodeEvents list events of the node, the newest first
+func ListNodeEvents(cli *clientv3.Client, nodeID string) ([]*NodeEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	res, err := cli.Get(ctx, fmt.Sprintf("%s/%s/", NodeEventPrefix, nodeID), clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*NodeEvent, 0, len(res.Kvs))
+	for _, kv := range res.Kvs {
+		var event NodeEvent
+		if err := json.Unmarshal(kv.Value, &event); err != nil {
+			continue
+		}
+		events = append(events, &event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreateTime.After(events[j].CreateTime)
+	})
+	return events, nil
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/goodrain/rainbond/cmd/node/option"
 	"github.com/goodrain/rainbond/event"
 	"github.com/goodrain/rainbond/node/nodem/client"
+	"github.com/goodrain/rainbond/node/nodem/gc"
 	"github.com/goodrain/rainbond/node/nodem/healthy"
 	"github.com/goodrain/rainbond/node/nodem/service"
 	"github.com/sirupsen/logrus"
@@ -57,6 +58,9 @@ type ManagerService struct {
 	etcdcli              *clientv3.Client
 	autoStatusController map[string]statusController
 	lock                 sync.Mutex
+	imageGCManager       gc.ImageGCManager
+	lastRemediation      map[string]time.Time
+	remediationLock      sync.Mutex
 }
 
 //GetAllService get all service
@@ -212,9 +216,13 @@ func (m *ManagerService) SyncServiceStatusController() {
 			v.Stop()
 		}
 	}
-	m.autoStatusController = make(map[string]statusController, len(m.services))
-	for _, s := range m.services {
-		if s.ServiceHealth == nil {
+	m.autoStatusController = make(map[string]statusController, len(m.allservice))
+	for _, s := range m.allservice {
+		if s.ServiceHealth == nil || s.Disable {
+			continue
+		}
+		// only health check service is watched when remediation is configured
+		if s.OnlyHealthCheck && len(s.ServiceHealth.Remediation) == 0 {
 			continue
 		}
 		ctx, cancel := context.WithCancel(context.Background())
@@ -225,13 +233,17 @@ func (m *ManagerService) SyncServiceStatusController() {
 			healthyManager: m.healthyManager,
 			watcher:        m.healthyManager.WatchServiceHealthy(s.Name),
 			unhealthHandle: func(event *service.HealthStatus, w healthy.Watcher) {
-				service := m.GetService(event.Name)
-				if service == nil {
+				svc := m.GetService(event.Name)
+				if svc == nil {
 					logrus.Errorf("not found service %s", event.Name)
 					return
 				}
-				m.DownOneServiceEndpoint(service)
-				if service.OnlyHealthCheck {
+				m.DownOneServiceEndpoint(svc)
+				if len(svc.ServiceHealth.Remediation) > 0 {
+					m.remediate(svc, event, w)
+					return
+				}
+				if svc.OnlyHealthCheck {
 					logrus.Warningf("service %s is only check health.so do not auto restart it", event.Name)
 					return
 				}
@@ -239,23 +251,8 @@ func (m *ManagerService) SyncServiceStatusController() {
 					logrus.Errorf("service docker can not auto restart. must artificial processing")
 					return
 				}
-				// disable check healthy status of the service
-				logrus.Infof("service %s not healthy, will restart it", event.Name)
-				m.healthyManager.DisableWatcher(event.Name, w.GetID())
-				_, err := m.ctr.WriteConfig(service)
-				if err == nil {
-					if err := m.ctr.RestartService(service); err != nil {
-						logrus.Errorf("restart service %s failure %s", event.Name, err.Error())
-					} else {
-						if !m.WaitStart(event.Name, time.Minute) {
-							logrus.Errorf("Timeout restart service: %s, will recheck health", event.Name)
-						}
-					}
-				} else {
-					logrus.Errorf("update service %s systemctl config failure where restart it:%s", event.Name, err.Error())
-				}
-				// start check healthy status of the service
-				m.healthyManager.EnableWatcher(event.Name, w.GetID())
+				err := m.restartUnhealthyService(svc, w)
+				m.recordEvent(svc, event, service.RemediationRestart, err)
 			},
 			healthHandle: func(event *service.HealthStatus, w healthy.Watcher) {
 				service := m.GetService(event.Name)
@@ -541,15 +538,17 @@ func (m *ManagerService) ListServiceImages() []string {
 }
 
 //NewManagerService new controller manager
-func NewManagerService(conf *option.Conf, healthyManager healthy.Manager, cluster client.ClusterClient) *ManagerService {
+func NewManagerService(conf *option.Conf, healthyManager healthy.Manager, cluster client.ClusterClient, imageGCManager gc.ImageGCManager) *ManagerService {
 	ctx, cancel := context.WithCancel(context.Background())
 	manager := &ManagerService{
-		ctx:            ctx,
-		cancel:         cancel,
-		conf:           conf,
-		cluster:        cluster,
-		healthyManager: healthyManager,
-		etcdcli:        conf.EtcdCli,
+		ctx:             ctx,
+		cancel:          cancel,
+		conf:            conf,
+		cluster:         cluster,
+		healthyManager:  healthyManager,
+		etcdcli:         conf.EtcdCli,
+		imageGCManager:  imageGCManager,
+		lastRemediation: make(map[string]time.Time),
 	}
 	manager.ctr = NewController(conf, manager)
 	return manager
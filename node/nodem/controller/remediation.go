@@ -0,0 +1,97 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goodrain/rainbond/node/nodem/client"
+	"github.com/goodrain/rainbond/node/nodem/healthy"
+	"github.com/goodrain/rainbond/node/nodem/service"
+	"github.com/sirupsen/logrus"
+)
+
+//RemediationInterval the min interval between two remediation of one service
+var RemediationInterval = time.Minute * 5
+
+//remediate do the configured remediation actions for the unhealthy service.
+//cordon is advised to master by the heartbeat of node manager, so it is not handled here.
+func (m *ManagerService) remediate(s *service.Service, event *service.HealthStatus, w healthy.Watcher) {
+	m.remediationLock.Lock()
+	if last, ok := m.lastRemediation[s.Name]; ok && time.Since(last) < RemediationInterval {
+		m.remediationLock.Unlock()
+		logrus.Debugf("service %s was remediated at %s, skip this time", s.Name, last)
+		return
+	}
+	m.lastRemediation[s.Name] = time.Now()
+	m.remediationLock.Unlock()
+	for _, action := range s.ServiceHealth.Remediation {
+		var err error
+		switch action {
+		case service.RemediationRestart:
+			err = m.restartUnhealthyService(s, w)
+		case service.RemediationImageGC:
+			if m.imageGCManager == nil {
+				err = fmt.Errorf("image gc manager is not ready")
+			} else {
+				err = m.imageGCManager.GarbageCollect()
+			}
+		case service.RemediationCordon:
+			continue
+		default:
+			logrus.Warningf("remediation action %s of service %s is not supported", action, s.Name)
+			continue
+		}
+		m.recordEvent(s, event, action, err)
+	}
+}
+
+//restartUnhealthyService restart the systemd unit of the service and wait it healthy
+func (m *ManagerService) restartUnhealthyService(s *service.Service, w healthy.Watcher) error {
+	logrus.Infof("service %s not healthy, will restart it", s.Name)
+	// disable check healthy status of the service
+	m.healthyManager.DisableWatcher(s.Name, w.GetID())
+	// start check healthy status of the service
+	defer m.healthyManager.EnableWatcher(s.Name, w.GetID())
+	// the unit of only health check service is not managed by node
+	if !s.OnlyHealthCheck {
+		if _, err := m.ctr.WriteConfig(s); err != nil {
+			logrus.Errorf("update service %s systemctl config failure where restart it:%s", s.Name, err.Error())
+			return err
+		}
+	}
+	if err := m.ctr.RestartService(s); err != nil {
+		logrus.Errorf("restart service %s failure %s", s.Name, err.Error())
+		return err
+	}
+	if !m.WaitStart(s.Name, time.Minute) {
+		logrus.Errorf("Timeout restart service: %s, will recheck health", s.Name)
+		return fmt.Errorf("service is still unhealthy after restart")
+	}
+	return nil
+}
+
+func (m *ManagerService) recordEvent(s *service.Service, event *service.HealthStatus, action string, err error) {
+	if m.node == nil {
+		return
+	}
+	reason := fmt.Sprintf("service %s is %s: %s", s.Name, event.Status, event.Info)
+	m.cluster.RecordEvent(client.NewNodeEvent(m.node.ID, s.Name, action, err, reason))
+}
@@ -0,0 +1,231 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envoy
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//MeshCASecretName the name of kubernetes secret which stores the region mesh ca
+var MeshCASecretName = "rbd-mesh-ca"
+
+//WorkloadCertTTL the validity period of workload certificate
+var WorkloadCertTTL = 24 * time.Hour
+
+//CertificateAuthority region ca which issues the workload certificates of mesh sidecar
+type CertificateAuthority struct {
+	certPEM []byte
+	cert    *x509.Certificate
+	key     crypto.Signer
+}
+
+//WorkloadCertificate workload certificate
+type WorkloadCertificate struct {
+	Identity string
+	CertPEM  []byte
+	KeyPEM   []byte
+	NotAfter time.Time
+}
+
+//NeedRenew the certificate need renew when one third of validity period left
+func (w *WorkloadCertificate) NeedRenew() bool {
+	return w == nil || time.Until(w.NotAfter) < WorkloadCertTTL/3
+}
+
+//NewCertificateAuthority create region ca
+//if certFile and keyFile is set, load ca from files. otherwise load ca from kubernetes secret,
+//the secret will be created with a new self signed ca if not exist, so that all nodes share the same ca.
+func NewCertificateAuthority(clientset kubernetes.Interface, namespace, certFile, keyFile string) (*CertificateAuthority, error) {
+	if certFile != "" && keyFile != "" {
+		certPEM, err := ioutil.ReadFile(certFile)
+		if err != nil {
+			return nil, fmt.Errorf("read mesh ca cert file failure %s", err.Error())
+		}
+		keyPEM, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read mesh ca key file failure %s", err.Error())
+		}
+		return parseCertificateAuthority(certPEM, keyPEM)
+	}
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.Background(), MeshCASecretName, metav1.GetOptions{})
+	if err == nil {
+		return parseCertificateAuthority(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	}
+	if !k8sErrors.IsNotFound(err) {
+		return nil, fmt.Errorf("get mesh ca secret failure %s", err.Error())
+	}
+	certPEM, keyPEM, err := createSelfSignedCA()
+	if err != nil {
+		return nil, err
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MeshCASecretName,
+			Namespace: namespace,
+			Labels:    map[string]string{"creator": "Rainbond"},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+	if _, err := clientset.CoreV1().Secrets(namespace).Create(context.Background(), secret, metav1.CreateOptions{}); err != nil {
+		if !k8sErrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("create mesh ca secret failure %s", err.Error())
+		}
+		// other node created the ca at the same time
+		secret, err = clientset.CoreV1().Secrets(namespace).Get(context.Background(), MeshCASecretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get mesh ca secret failure %s", err.Error())
+		}
+		return parseCertificateAuthority(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	}
+	logrus.Infof("create mesh ca secret %s/%s", namespace, MeshCASecretName)
+	return parseCertificateAuthority(certPEM, keyPEM)
+}
+
+func parseCertificateAuthority(certPEM, keyPEM []byte) (*CertificateAuthority, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, fmt.Errorf("mesh ca cert is not pem format")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse mesh ca cert failure %s", err.Error())
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("mesh ca key is not pem format")
+	}
+	var key interface{}
+	switch keyBlock.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(keyBlock.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse mesh ca key failure %s", err.Error())
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("mesh ca key is not a signer")
+	}
+	return &CertificateAuthority{certPEM: certPEM, cert: cert, key: signer}, nil
+}
+
+func createSelfSignedCA() ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "rainbond-mesh-ca", Organization: []string{"Rainbond"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), nil
+}
+
+//CACertPEM return the pem of region ca cert
+func (c *CertificateAuthority) CACertPEM() []byte {
+	return c.certPEM
+}
+
+//IssueWorkloadCertificate issue a workload certificate with spiffe identity as uri san
+func (c *CertificateAuthority) IssueWorkloadCertificate(identity string) (*WorkloadCertificate, error) {
+	uri, err := url.Parse(identity)
+	if err != nil {
+		return nil, fmt.Errorf("parse identity %s failure %s", identity, err.Error())
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	notAfter := time.Now().Add(WorkloadCertTTL)
+	if notAfter.After(c.cert.NotAfter) {
+		notAfter = c.cert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"Rainbond"}},
+		NotBefore:    time.Now().Add(-5 * time.Minute),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		URIs:         []*url.URL{uri},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, &key.PublicKey, c.key)
+	if err != nil {
+		return nil, fmt.Errorf("sign workload certificate failure %s", err.Error())
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return &WorkloadCertificate{
+		Identity: identity,
+		CertPEM:  append(certPEM, c.certPEM...),
+		KeyPEM:   pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		NotAfter: notAfter,
+	}, nil
+}
@@ -0,0 +1,64 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envoy
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIssueWorkloadCertificate(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	ca, err := NewCertificateAuthority(clientset, "rbd-system", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the second node must load the same ca from secret
+	ca2, err := NewCertificateAuthority(clientset, "rbd-system", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ca.CACertPEM()) != string(ca2.CACertPEM()) {
+		t.Fatal("region ca is not shared between nodes")
+	}
+	identity := "spiffe://cluster.local/ns/tenant/sa/gr123456"
+	cert, err := ca2.IssueWorkloadCertificate(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.NeedRenew() {
+		t.Fatal("new certificate should not need renew")
+	}
+	block, _ := pem.Decode(cert.CertPEM)
+	workload, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workload.URIs) != 1 || workload.URIs[0].String() != identity {
+		t.Fatalf("unexpected workload identity %v", workload.URIs)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.CACertPEM())
+	if _, err := workload.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -35,15 +35,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-//OneNodeCluster conver cluster of on envoy node
-func OneNodeCluster(serviceAlias, namespace string, configs *corev1.ConfigMap, services []*corev1.Service) ([]types.Resource, error) {
+//OneNodeCluster conver cluster of on envoy node, trustDomain is the trust domain of mesh workload identity
+func OneNodeCluster(serviceAlias, namespace, trustDomain string, configs *corev1.ConfigMap, services []*corev1.Service) ([]types.Resource, error) {
 	resources, _, err := GetPluginConfigs(configs)
 	if err != nil {
 		return nil, err
 	}
 	var clusters []types.Resource
 	if resources.BaseServices != nil && len(resources.BaseServices) > 0 {
-		for _, cl := range upstreamClusters(serviceAlias, namespace, resources.BaseServices, services) {
+		for _, cl := range upstreamClusters(serviceAlias, namespace, resources.BaseServices, services, GetMeshTLSOptions(configs, trustDomain)) {
 			if err := cl.Validate(); err != nil {
 				logrus.Errorf("cluster validate failure %s", err.Error())
 			} else {
@@ -60,6 +60,11 @@ func OneNodeCluster(serviceAlias, namespace string, configs *corev1.ConfigMap, s
 			}
 		}
 	}
+	if tracingOptions := GetMeshTracingOptions(configs); tracingOptions != nil && len(clusters) > 0 {
+		if cl := envoyv2.CreateTracingCollectorCluster(tracingOptions); cl != nil {
+			clusters = append(clusters, cl)
+		}
+	}
 	if len(clusters) == 0 {
 		logrus.Warningf("configmap name: %s; plugin-config: %s; create clusters zero length", configs.Name, configs.Data["plugin-config"])
 	}
@@ -68,7 +73,7 @@ func OneNodeCluster(serviceAlias, namespace string, configs *corev1.ConfigMap, s
 
 // upstreamClusters handle upstream app cluster
 // handle kubernetes inner service
-func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_model.BaseService, services []*corev1.Service) (cdsClusters []*v2.Cluster) {
+func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_model.BaseService, services []*corev1.Service, tlsOptions *envoyv2.MeshTLSOptions) (cdsClusters []*v2.Cluster) {
 	var clusterConfig = make(map[string]*api_model.BaseService, len(dependsServices))
 	for i, dService := range dependsServices {
 		depServiceIndex := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, dService.DependServiceAlias, dService.Port)
@@ -110,6 +115,10 @@ func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_mod
 			}
 		} else {
 			clusterOption.ClusterType = v2.Cluster_EDS
+			// the dest service terminates mutual tls by its inbound listener
+			if tlsOptions != nil && tlsOptions.Enable && service.Labels["mesh_mtls"] == "true" {
+				clusterOption.TransportSocket = envoyv2.CreateUpstreamMTLSTransportSocket("", envoyv2.MeshIdentity(tlsOptions.TrustDomain, namespace, destServiceAlias))
+			}
 		}
 		clusterOption.HealthyPanicThreshold = options.HealthyPanicThreshold
 		clusterOption.ConnectionTimeout = envoyv2.ConverTimeDuration(options.ConnectionTimeout)
@@ -31,6 +31,7 @@ import (
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	api_model "github.com/goodrain/rainbond/api/model"
 	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
+	"github.com/goodrain/rainbond/util/tracing"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -47,8 +48,44 @@ func GetPluginConfigs(configs *corev1.ConfigMap) (*api_model.ResourceSpec, strin
 	return &rs, configs.Labels["plugin_id"], nil
 }
 
-//OneNodeListerner conver listerner of on envoy node
-func OneNodeListerner(serviceAlias, namespace string, configs *corev1.ConfigMap, services []*corev1.Service) ([]types.Resource, error) {
+//GetMeshTLSOptions get mutual tls options from plugin config annotations
+func GetMeshTLSOptions(configs *corev1.ConfigMap, trustDomain string) *envoyv2.MeshTLSOptions {
+	if configs == nil || configs.Annotations["mesh_mtls"] != "true" {
+		return nil
+	}
+	options := &envoyv2.MeshTLSOptions{
+		Enable:      true,
+		Mode:        envoyv2.MTLSModePermissive,
+		TrustDomain: trustDomain,
+	}
+	if callers := configs.Annotations["mesh_allowed_callers"]; callers != "" {
+		options.AllowedCallers = strings.Split(callers, ",")
+	}
+	return options
+}
+
+//GetMeshTracingOptions get tracing options from plugin config annotations
+func GetMeshTracingOptions(configs *corev1.ConfigMap) *envoyv2.TracingOptions {
+	if configs == nil || configs.Annotations[tracing.ProviderAnnotation] == "" || configs.Annotations[tracing.EndpointAnnotation] == "" {
+		return nil
+	}
+	options := &envoyv2.TracingOptions{
+		Provider:       configs.Annotations[tracing.ProviderAnnotation],
+		Endpoint:       configs.Annotations[tracing.EndpointAnnotation],
+		SampleRate:     100,
+		OpenCensusPort: tracing.DefaultOpenCensusPort,
+	}
+	if rate, err := strconv.Atoi(configs.Annotations[tracing.SampleRateAnnotation]); err == nil && rate >= 0 && rate <= 100 {
+		options.SampleRate = rate
+	}
+	if port, err := strconv.Atoi(configs.Annotations[tracing.OpenCensusPortAnnotation]); err == nil && port > 0 && port <= 65535 {
+		options.OpenCensusPort = port
+	}
+	return options
+}
+
+//OneNodeListerner conver listerner of on envoy node, trustDomain is the trust domain of mesh workload identity
+func OneNodeListerner(serviceAlias, namespace, trustDomain string, configs *corev1.ConfigMap, services []*corev1.Service) ([]types.Resource, error) {
 	resources, _, err := GetPluginConfigs(configs)
 	if err != nil {
 		return nil, err
@@ -63,8 +100,12 @@ func OneNodeListerner(serviceAlias, namespace string, configs *corev1.ConfigMap,
 		}
 		return false
 	}()
+	tracingOptions := GetMeshTracingOptions(configs)
 	if resources.BaseServices != nil && len(resources.BaseServices) > 0 {
 		for _, l := range upstreamListener(serviceAlias, namespace, resources.BaseServices, services, !notCreateCommonHTTPListener) {
+			if l = envoyv2.ApplyListenerTracing(l, tracingOptions); l == nil {
+				continue
+			}
 			if err := l.Validate(); err != nil {
 				logrus.Errorf("listener validate failure %s", err.Error())
 			} else {
@@ -74,7 +115,10 @@ func OneNodeListerner(serviceAlias, namespace string, configs *corev1.ConfigMap,
 		}
 	}
 	if resources.BasePorts != nil && len(resources.BasePorts) > 0 {
-		for _, l := range downstreamListener(serviceAlias, namespace, resources.BasePorts) {
+		for _, l := range downstreamListener(serviceAlias, namespace, resources.BasePorts, GetMeshTLSOptions(configs, trustDomain)) {
+			if l = envoyv2.ApplyListenerTracing(l, tracingOptions); l == nil {
+				continue
+			}
 			if err := l.Validate(); err != nil {
 				logrus.Errorf("listener validate failure %s", err.Error())
 			} else {
@@ -140,7 +184,7 @@ func upstreamListener(serviceAlias, namespace string, dependsServices []*api_mod
 			var listener *v2.Listener
 			protocol := service.Labels["port_protocol"]
 			if domain, ok := service.Annotations["domain"]; ok && domain != "" && (protocol == "https" || protocol == "http" || protocol == "grpc") {
-				route := envoyv2.CreateRouteWithHostRewrite(domain, clusterName, "/", nil, 0)
+				route := envoyv2.CreateRouteWithHostRewrite(domain, clusterName, "/", nil, 0, &options)
 				if route != nil {
 					pvh := envoyv2.CreateRouteVirtualHost(
 						fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, GetServiceAliasByService(service), port),
@@ -197,9 +241,9 @@ func upstreamListener(serviceAlias, namespace string, dependsServices []*api_mod
 					}
 					var route *route.Route
 					if domain, ok := service.Annotations["domain"]; ok && domain != "" {
-						route = envoyv2.CreateRouteWithHostRewrite(domain, clusterName, options.Prefix, headerMatchers, options.Weight)
+						route = envoyv2.CreateRouteWithHostRewrite(domain, clusterName, options.Prefix, headerMatchers, options.Weight, &options)
 					} else {
-						route = envoyv2.CreateRoute(clusterName, options.Prefix, headerMatchers, options.Weight)
+						route = envoyv2.CreateRoute(clusterName, options.Prefix, headerMatchers, options.Weight, &options)
 					}
 
 					if route != nil {
@@ -259,7 +303,7 @@ func upstreamListener(serviceAlias, namespace string, dependsServices []*api_mod
 }
 
 //downstreamListener handle app self port listener
-func downstreamListener(serviceAlias, namespace string, ports []*api_model.BasePort) (ls []*v2.Listener) {
+func downstreamListener(serviceAlias, namespace string, ports []*api_model.BasePort, tlsOptions *envoyv2.MeshTLSOptions) (ls []*v2.Listener) {
 	var portMap = make(map[int32]int, 0)
 	for i := range ports {
 		p := ports[i]
@@ -270,6 +314,15 @@ func downstreamListener(serviceAlias, namespace string, ports []*api_model.BaseP
 		if _, ok := portMap[port]; !ok {
 			inboundConfig := envoyv2.GetRainbondInboundPluginOptions(p.Options)
 			options := envoyv2.GetOptionValues(p.Options)
+			portTLSOptions := tlsOptions
+			if tlsOptions != nil && inboundConfig.MTLSMode != "" {
+				portTLSOptions = &envoyv2.MeshTLSOptions{
+					Enable:         tlsOptions.Enable,
+					Mode:           inboundConfig.MTLSMode,
+					AllowedCallers: tlsOptions.AllowedCallers,
+					TrustDomain:    tlsOptions.TrustDomain,
+				}
+			}
 			if p.Protocol == "http" || p.Protocol == "https" || p.Protocol == "grpc" {
 				var limit []*route.RateLimit
 				if inboundConfig.OpenLimit {
@@ -285,7 +338,7 @@ func downstreamListener(serviceAlias, namespace string, ports []*api_model.BaseP
 						},
 					}
 				}
-				route := envoyv2.CreateRoute(clusterName, "/", nil, 100)
+				route := envoyv2.CreateRoute(clusterName, "/", nil, 100, &options)
 				if route == nil {
 					logrus.Warning("create route cirtual route failure")
 					continue
@@ -301,6 +354,7 @@ func downstreamListener(serviceAlias, namespace string, ports []*api_model.BaseP
 					RateServerClusterName: envoyv2.DefaultRateLimitServerClusterName,
 					Stage:                 0,
 				}, virtuals)
+				listener = envoyv2.ApplyListenerMTLS(listener, namespace, portTLSOptions)
 				if listener != nil {
 					ls = append(ls, listener)
 				}
@@ -314,6 +368,7 @@ func downstreamListener(serviceAlias, namespace string, ports []*api_model.BaseP
 				}
 			} else {
 				listener := envoyv2.CreateTCPListener(listenerName, clusterName, "0.0.0.0", statsPrefix, uint32(p.ListenPort), options.TCPIdleTimeout)
+				listener = envoyv2.ApplyListenerMTLS(listener, namespace, portTLSOptions)
 				if listener != nil {
 					ls = append(ls, listener)
 				} else {
@@ -21,13 +21,66 @@ package conver
 import (
 	"testing"
 
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	api_model "github.com/goodrain/rainbond/api/model"
+	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
 	corev1 "k8s.io/api/core/v1"
 )
 
 func TestOneNodeListerner(t *testing.T) {
-	listeners, err := OneNodeListerner("serviceAlias", "namespace", &corev1.ConfigMap{}, []*corev1.Service{})
+	listeners, err := OneNodeListerner("serviceAlias", "namespace", "", &corev1.ConfigMap{}, []*corev1.Service{})
 	if err != nil {
 		t.Fatal(err)
 	}
 	t.Log(listeners)
 }
+
+func TestDownstreamListenerWithMTLS(t *testing.T) {
+	ports := []*api_model.BasePort{
+		{Port: 5000, ListenPort: 65530, Protocol: "http", Options: map[string]interface{}{"MTLS_MODE": "STRICT"}},
+		{Port: 3306, ListenPort: 65531, Protocol: "mysql"},
+	}
+	tlsOptions := &envoyv2.MeshTLSOptions{Enable: true, Mode: envoyv2.MTLSModePermissive, AllowedCallers: []string{"grcaller"}}
+	listeners := downstreamListener("gr123456", "tenant", ports, tlsOptions)
+	if len(listeners) != 2 {
+		t.Fatalf("expect 2 listeners, got %d", len(listeners))
+	}
+	for _, l := range listeners {
+		secure := l.FilterChains[0]
+		if secure.TransportSocket == nil || secure.FilterChainMatch.TransportProtocol != "tls" {
+			t.Fatalf("listener %s first filter chain is not mutual tls", l.Name)
+		}
+		if secure.Filters[0].Name != wellknown.RoleBasedAccessControl {
+			t.Fatalf("listener %s first filter is not rbac", l.Name)
+		}
+	}
+	if len(listeners[0].FilterChains) != 1 {
+		t.Fatalf("strict listener should not accept plaintext")
+	}
+	if len(listeners[1].FilterChains) != 2 {
+		t.Fatalf("permissive listener should accept plaintext")
+	}
+	for _, filter := range listeners[1].FilterChains[1].Filters {
+		if filter.Name == wellknown.RoleBasedAccessControl {
+			t.Fatalf("plaintext filter chain of permissive listener should not have rbac filter")
+		}
+	}
+}
+
+func TestDownstreamListenerWithMTLSNoCallers(t *testing.T) {
+	ports := []*api_model.BasePort{
+		{Port: 3306, ListenPort: 65531, Protocol: "mysql"},
+	}
+	tlsOptions := &envoyv2.MeshTLSOptions{Enable: true, Mode: envoyv2.MTLSModePermissive}
+	listeners := downstreamListener("gr123456", "tenant", ports, tlsOptions)
+	if len(listeners) != 1 {
+		t.Fatalf("expect 1 listener, got %d", len(listeners))
+	}
+	for _, chain := range listeners[0].FilterChains {
+		for _, filter := range chain.Filters {
+			if filter.Name == wellknown.RoleBasedAccessControl {
+				t.Fatalf("rbac filter without allowed callers denies all traffic")
+			}
+		}
+	}
+}
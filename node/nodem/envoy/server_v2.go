@@ -37,6 +37,7 @@ import (
 	"github.com/envoyproxy/go-control-plane/pkg/server/v2"
 	api_model "github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/cmd/node/option"
+	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
 	"github.com/goodrain/rainbond/node/nodem/envoy/conver"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -64,6 +65,7 @@ type DiscoverServerManager struct {
 	endpoints       cacheHandler
 	configmaps      cacheHandler
 	queue           Queue
+	ca              *CertificateAuthority
 }
 
 // Hasher returns node ID as an ID
@@ -88,6 +90,8 @@ type NodeConfig struct {
 	configModel                    *api_model.ResourceSpec
 	dependServices                 sync.Map
 	listeners, clusters, endpoints []types.Resource
+	secrets                        []types.Resource
+	workloadCert                   *WorkloadCertificate
 }
 
 //GetID get envoy node config id
@@ -214,13 +218,13 @@ func (d *DiscoverServerManager) UpdateNodeConfig(nc *NodeConfig) error {
 			endpoint = append(endpoint, downEndpoint...)
 		}
 	}
-	listeners, err := conver.OneNodeListerner(nc.serviceAlias, nc.namespace, nc.config, services)
+	listeners, err := conver.OneNodeListerner(nc.serviceAlias, nc.namespace, d.conf.MeshTrustDomain, nc.config, services)
 	if err != nil {
 		logrus.Errorf("create envoy listeners failure %s", err.Error())
 	} else {
 		nc.listeners = listeners
 	}
-	clusters, err := conver.OneNodeCluster(nc.serviceAlias, nc.namespace, nc.config, services)
+	clusters, err := conver.OneNodeCluster(nc.serviceAlias, nc.namespace, d.conf.MeshTrustDomain, nc.config, services)
 	if err != nil {
 		logrus.Errorf("create envoy clusters failure %s", err.Error())
 	} else {
@@ -235,6 +239,9 @@ func (d *DiscoverServerManager) UpdateNodeConfig(nc *NodeConfig) error {
 	} else {
 		nc.endpoints = clusterLoadAssignment
 	}
+	if err := d.updateNodeSecrets(nc); err != nil {
+		logrus.Errorf("create envoy secrets failure %s", err.Error())
+	}
 	//Fill the configuration information and inject envoy
 	nc.VersionUpdate()
 	return d.setSnapshot(nc)
@@ -246,6 +253,7 @@ func (d *DiscoverServerManager) setSnapshot(nc *NodeConfig) error {
 		return nil
 	}
 	snapshot := cache.NewSnapshot(nc.GetVersion(), nc.endpoints, nc.clusters, nil, nc.listeners, nil)
+	snapshot.Resources[types.Secret] = cache.NewResources(nc.GetVersion(), nc.secrets)
 	err := d.cacheManager.SetSnapshot(nc.nodeID, snapshot)
 	if err != nil {
 		return err
@@ -254,6 +262,46 @@ func (d *DiscoverServerManager) setSnapshot(nc *NodeConfig) error {
 	return nil
 }
 
+//updateNodeSecrets issue workload certificate for the node which enable mutual tls
+func (d *DiscoverServerManager) updateNodeSecrets(nc *NodeConfig) error {
+	if tlsOptions := conver.GetMeshTLSOptions(nc.config, d.conf.MeshTrustDomain); tlsOptions == nil || !tlsOptions.Enable {
+		nc.secrets = nil
+		nc.workloadCert = nil
+		return nil
+	}
+	if d.ca == nil {
+		return fmt.Errorf("mesh ca is not ready, can not issue certificate for %s", nc.GetID())
+	}
+	identity := envoyv2.MeshIdentity(d.conf.MeshTrustDomain, nc.namespace, nc.serviceAlias)
+	if nc.workloadCert == nil || nc.workloadCert.Identity != identity || nc.workloadCert.NeedRenew() {
+		cert, err := d.ca.IssueWorkloadCertificate(identity)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("issue workload certificate %s for envoy node %s, expire at %s", identity, nc.GetID(), cert.NotAfter)
+		nc.workloadCert = cert
+	}
+	certSecret := envoyv2.CreateTLSCertificateSecret(envoyv2.MeshCertificateSecretName, nc.workloadCert.CertPEM, nc.workloadCert.KeyPEM)
+	caSecret := envoyv2.CreateValidationContextSecret(envoyv2.MeshValidationSecretName, d.ca.CACertPEM())
+	if certSecret == nil || caSecret == nil {
+		return fmt.Errorf("create sds secrets for %s failure", nc.GetID())
+	}
+	nc.secrets = []types.Resource{certSecret, caSecret}
+	return nil
+}
+
+//renewCertificates renew the workload certificates which will expire soon
+func (d *DiscoverServerManager) renewCertificates(obj interface{}, event Event) error {
+	for i, nodeConfig := range d.cacheNodeConfig {
+		if nodeConfig.workloadCert != nil && nodeConfig.workloadCert.NeedRenew() {
+			if err := d.UpdateNodeConfig(d.cacheNodeConfig[i]); err != nil {
+				logrus.Errorf("renew envoy node %s certificate failure %s", nodeConfig.GetID(), err.Error())
+			}
+		}
+	}
+	return nil
+}
+
 //CreateDiscoverServerManager create discover server manager
 func CreateDiscoverServerManager(clientset kubernetes.Interface, conf option.Conf) (*DiscoverServerManager, error) {
 	configcache := cache.NewSnapshotCache(false, Hasher{}, logrus.WithField("module", "config-cache"))
@@ -285,6 +333,12 @@ func CreateDiscoverServerManager(clientset kubernetes.Interface, conf option.Con
 	dsm.configmaps.handler.Append(dsm.configHandle)
 	dsm.endpoints.handler.Append(dsm.resourceSimpleHandle)
 	dsm.services.handler.Append(dsm.resourceSimpleHandle)
+	ca, err := NewCertificateAuthority(clientset, conf.RbdNamespace, conf.MeshCACertFile, conf.MeshCAKeyFile)
+	if err != nil {
+		// mutual tls is not available, but plaintext mesh still works
+		logrus.Warningf("create mesh certificate authority failure %s", err.Error())
+	}
+	dsm.ca = ca
 	return dsm, nil
 }
 
@@ -294,6 +348,7 @@ const grpcMaxConcurrentStreams = 1000000
 func (d *DiscoverServerManager) Start(errch chan error) error {
 	go func() {
 		go d.queue.Run(d.ctx.Done())
+		go d.renewCertificatesLoop()
 		go d.services.informer.Run(d.ctx.Done())
 		go d.endpoints.informer.Run(d.ctx.Done())
 		//waiting service and endpoint resource loading is complete
@@ -329,6 +384,20 @@ func (d *DiscoverServerManager) Start(errch chan error) error {
 	return nil
 }
 
+func (d *DiscoverServerManager) renewCertificatesLoop() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			// handle in queue, avoid concurrent access to node configs
+			d.queue.Push(NewTask(d.renewCertificates, nil, EventUpdate))
+		}
+	}
+}
+
 //Stop stop grpc server
 func (d *DiscoverServerManager) Stop() {
 	//d.grpcServer.GracefulStop()
@@ -57,6 +57,9 @@ type ImageGCManager interface {
 	Start()
 
 	SetServiceImages(seviceImages []string)
+
+	// GarbageCollect frees the space of unused images when the disk usage is over the high threshold.
+	GarbageCollect() error
 }
 
 // ImageGCPolicy is a policy for garbage collecting images. Policy defines an allowed band in
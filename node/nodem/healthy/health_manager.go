@@ -21,6 +21,7 @@ package healthy
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -72,10 +73,11 @@ type probeManager struct {
 	statusChan   chan *service.HealthStatus
 	lock         sync.Mutex
 	hostNode     *client.HostNode
+	cluster      client.ClusterClient
 }
 
 //CreateManager create manager
-func CreateManager() Manager {
+func CreateManager(cluster client.ClusterClient) Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 	statusChan := make(chan *service.HealthStatus, 100)
 	status := make(map[string]*service.HealthStatus)
@@ -87,6 +89,7 @@ func CreateManager() Manager {
 		status:       status,
 		watches:      watches,
 		serviceProbe: make(map[string]probe.Probe),
+		cluster:      cluster,
 	}
 	return m
 }
@@ -125,7 +128,7 @@ func (p *probeManager) updateServiceProbe() {
 		if service.Disable {
 			continue
 		}
-		serviceProbe, err := probe.CreateProbe(p.ctx, p.hostNode, p.statusChan, service)
+		serviceProbe, err := probe.CreateProbe(p.ctx, p.hostNode, p.cluster, p.statusChan, service)
 		if err != nil {
 			logrus.Warningf("create prose for service %s failure,%s", service.Name, err.Error())
 		}
@@ -299,6 +302,15 @@ func (p *probeManager) GetCurrentServiceHealthy(serviceName string) (*service.He
 				}
 				return result, nil
 			}
+			if model := strings.ToLower(v.ServiceHealth.Model); probe.IsNodeProbeModel(model) {
+				statusMap := probe.GetNodeHealth(model, v.ServiceHealth.Address, v.ServiceHealth.Threshold, p.hostNode, p.cluster)
+				result := &service.HealthStatus{
+					Name:   v.Name,
+					Status: statusMap["status"],
+					Info:   statusMap["info"],
+				}
+				return result, nil
+			}
 		}
 	}
 	return nil, errors.New("the service does not exist")
@@ -10,7 +10,7 @@ import (
 )
 
 func TestProbeManager_Start(t *testing.T) {
-	m := CreateManager()
+	m := CreateManager(nil)
 
 	var serviceList []*service.Service
 
@@ -0,0 +1,325 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/node/nodem/client"
+	"github.com/goodrain/rainbond/node/nodem/service"
+	"github.com/shirou/gopsutil/disk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	//DefaultDiskThreshold default max usage percent of disk and inode probe
+	DefaultDiskThreshold = 90
+	//DefaultClockSkewThreshold default max clock skew seconds against the masters
+	DefaultClockSkewThreshold = 5
+	//DefaultDockerSocket default docker daemon socket
+	DefaultDockerSocket = "/var/run/docker.sock"
+	//DefaultContainerdSocket default containerd socket
+	DefaultContainerdSocket = "/run/containerd/containerd.sock"
+)
+
+var nodeProbeTimeout = 10 * time.Second
+
+//NodeProbe node level probe, such as disk, inode, docker, containerd, clock and dns
+type NodeProbe struct {
+	Name         string
+	Model        string
+	Address      string
+	Threshold    float64
+	ResultsChan  chan *service.HealthStatus
+	Ctx          context.Context
+	Cancel       context.CancelFunc
+	TimeInterval int
+	HostNode     *client.HostNode
+	Cluster      client.ClusterClient
+	MaxErrorsNum int
+}
+
+//Check -
+func (h *NodeProbe) Check() {
+	go h.NodeCheck()
+}
+
+//Stop -
+func (h *NodeProbe) Stop() {
+	h.Cancel()
+}
+
+//NodeCheck -
+func (h *NodeProbe) NodeCheck() {
+	if h.TimeInterval == 0 {
+		h.TimeInterval = 5
+	}
+	timer := time.NewTimer(time.Second * time.Duration(h.TimeInterval))
+	defer timer.Stop()
+	for {
+		HealthMap := GetNodeHealth(h.Model, h.Address, h.Threshold, h.HostNode, h.Cluster)
+		result := &service.HealthStatus{
+			Name:   h.Name,
+			Status: HealthMap["status"],
+			Info:   HealthMap["info"],
+		}
+		h.ResultsChan <- result
+		timer.Reset(time.Second * time.Duration(h.TimeInterval))
+		select {
+		case <-h.Ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+//IsNodeProbeModel whether the model is a built-in node probe model
+func IsNodeProbeModel(model string) bool {
+	switch model {
+	case "disk", "inode", "docker", "containerd", "clock", "dns":
+		return true
+	}
+	return false
+}
+
+//GetNodeHealth get health of built-in node probe model
+func GetNodeHealth(model, address string, threshold float64, hostNode *client.HostNode, cluster client.ClusterClient) map[string]string {
+	switch model {
+	case "disk":
+		return GetDiskHealth(address, threshold)
+	case "inode":
+		return GetInodeHealth(address, threshold)
+	case "docker":
+		return GetDockerHealth(address)
+	case "containerd":
+		return GetContainerdHealth(address)
+	case "clock":
+		return GetClockSkewHealth(getMasterEndpoints(address, hostNode, cluster), threshold)
+	case "dns":
+		return GetDNSHealth(address)
+	}
+	return map[string]string{"status": service.Stat_Unknow, "info": fmt.Sprintf("probe model %s not support", model)}
+}
+
+//GetDiskHealth check the disk usage of the path, address is the path, default /
+func GetDiskHealth(path string, threshold float64) map[string]string {
+	if path == "" {
+		path = "/"
+	}
+	if threshold <= 0 {
+		threshold = DefaultDiskThreshold
+	}
+	usage, err := disk.Usage(path)
+	if err != nil {
+		return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("get disk usage of %s failure %s", path, err.Error())}
+	}
+	if usage.UsedPercent >= threshold {
+		return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("disk usage of %s is %.1f%%, more than %.1f%%", path, usage.UsedPercent, threshold)}
+	}
+	return map[string]string{"status": service.Stat_healthy, "info": fmt.Sprintf("disk usage of %s is %.1f%%", path, usage.UsedPercent)}
+}
+
+//GetInodeHealth check the inode usage of the path, address is the path, default /
+func GetInodeHealth(path string, threshold float64) map[string]string {
+	if path == "" {
+		path = "/"
+	}
+	if threshold <= 0 {
+		threshold = DefaultDiskThreshold
+	}
+	usage, err := disk.Usage(path)
+	if err != nil {
+		return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("get inode usage of %s failure %s", path, err.Error())}
+	}
+	if usage.InodesTotal == 0 {
+		return map[string]string{"status": service.Stat_healthy, "info": fmt.Sprintf("filesystem of %s does not report inodes", path)}
+	}
+	if usage.InodesUsedPercent >= threshold {
+		return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("inode usage of %s is %.1f%%, more than %.1f%%", path, usage.InodesUsedPercent, threshold)}
+	}
+	return map[string]string{"status": service.Stat_healthy, "info": fmt.Sprintf("inode usage of %s is %.1f%%", path, usage.InodesUsedPercent)}
+}
+
+func unixDialer(socket string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socket)
+	}
+}
+
+//GetDockerHealth ping the docker daemon, address is the socket path
+func GetDockerHealth(socket string) map[string]string {
+	if socket == "" {
+		socket = DefaultDockerSocket
+	}
+	socket = strings.TrimPrefix(socket, "unix://")
+	c := &http.Client{
+		Timeout:   nodeProbeTimeout,
+		Transport: &http.Transport{DialContext: unixDialer(socket)},
+	}
+	resp, err := c.Get("http://docker/_ping")
+	if err != nil {
+		return map[string]string{"status": service.Stat_death, "info": fmt.Sprintf("ping docker daemon failure %s", err.Error())}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("ping docker daemon return code %d", resp.StatusCode)}
+	}
+	return map[string]string{"status": service.Stat_healthy, "info": "docker daemon is responsive"}
+}
+
+//GetContainerdHealth check containerd by grpc health service, address is the socket path
+func GetContainerdHealth(socket string) map[string]string {
+	if socket == "" {
+		socket = DefaultContainerdSocket
+	}
+	socket = strings.TrimPrefix(socket, "unix://")
+	ctx, cancel := context.WithTimeout(context.Background(), nodeProbeTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, socket, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", addr)
+	}))
+	if err != nil {
+		return map[string]string{"status": service.Stat_death, "info": fmt.Sprintf("connect containerd failure %s", err.Error())}
+	}
+	defer conn.Close()
+	res, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("check containerd health failure %s", err.Error())}
+	}
+	if res.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("containerd status is %s", res.Status.String())}
+	}
+	return map[string]string{"status": service.Stat_healthy, "info": "containerd is responsive"}
+}
+
+//getMasterEndpoints address is comma separated master node api endpoints,
+//if not set, use the api endpoints of all masters in cluster
+func getMasterEndpoints(address string, hostNode *client.HostNode, cluster client.ClusterClient) []string {
+	var endpoints []string
+	for _, ep := range strings.Split(address, ",") {
+		if ep = strings.TrimSpace(ep); ep != "" {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	if len(endpoints) > 0 || cluster == nil {
+		return endpoints
+	}
+	masters, err := cluster.GetMasters()
+	if err != nil {
+		return nil
+	}
+	port := "6100"
+	if cluster.GetOptions() != nil {
+		if _, p, err := net.SplitHostPort(cluster.GetOptions().APIAddr); err == nil && p != "" {
+			port = p
+		}
+	}
+	for _, m := range masters {
+		if hostNode != nil && m.ID == hostNode.ID {
+			continue
+		}
+		endpoints = append(endpoints, net.JoinHostPort(m.InternalIP, port))
+	}
+	return endpoints
+}
+
+//GetClockSkew get clock skew between local and the endpoint by the Date header of http response
+func GetClockSkew(endpoint string) (time.Duration, error) {
+	if !strings.HasPrefix(endpoint, "http") {
+		endpoint = "http://" + endpoint
+	}
+	c := &http.Client{Timeout: nodeProbeTimeout}
+	start := time.Now()
+	resp, err := c.Get(strings.TrimSuffix(endpoint, "/") + "/v2/ping")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	end := time.Now()
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("parse date header failure %s", err.Error())
+	}
+	local := start.Add(end.Sub(start) / 2)
+	// the Date header is truncated to the second
+	return local.Sub(serverTime.Add(500 * time.Millisecond)), nil
+}
+
+//GetClockSkewHealth check clock skew against the masters
+func GetClockSkewHealth(endpoints []string, threshold float64) map[string]string {
+	if threshold <= 0 {
+		threshold = DefaultClockSkewThreshold
+	}
+	if len(endpoints) == 0 {
+		return map[string]string{"status": service.Stat_Unknow, "info": "there is no master to compare clock with"}
+	}
+	var maxSkew float64
+	var maxEndpoint string
+	var errs []string
+	for _, ep := range endpoints {
+		skew, err := GetClockSkew(ep)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", ep, err.Error()))
+			continue
+		}
+		if s := math.Abs(skew.Seconds()); s >= maxSkew {
+			maxSkew = s
+			maxEndpoint = ep
+		}
+	}
+	if len(errs) == len(endpoints) {
+		return map[string]string{"status": service.Stat_Unknow, "info": "can not get time of masters " + strings.Join(errs, ";")}
+	}
+	if maxSkew > threshold {
+		return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("clock skew against master %s is %.1fs, more than %.1fs", maxEndpoint, maxSkew, threshold)}
+	}
+	return map[string]string{"status": service.Stat_healthy, "info": fmt.Sprintf("max clock skew against masters is %.1fs", maxSkew)}
+}
+
+//GetDNSHealth check dns resolution, address is comma separated domain names
+func GetDNSHealth(address string) map[string]string {
+	if strings.TrimSpace(address) == "" {
+		return map[string]string{"status": service.Stat_Unknow, "info": "domain name of dns probe is not set"}
+	}
+	for _, domain := range strings.Split(address, ",") {
+		domain = strings.TrimSpace(domain)
+		if domain == "" {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), nodeProbeTimeout)
+		addrs, err := net.DefaultResolver.LookupHost(ctx, domain)
+		cancel()
+		if err != nil {
+			return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("resolve %s failure %s", domain, err.Error())}
+		}
+		if len(addrs) == 0 {
+			return map[string]string{"status": service.Stat_unhealthy, "info": fmt.Sprintf("resolve %s without any address", domain)}
+		}
+	}
+	return map[string]string{"status": service.Stat_healthy, "info": "dns resolution is normal"}
+}
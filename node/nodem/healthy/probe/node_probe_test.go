@@ -0,0 +1,95 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package probe
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/node/nodem/service"
+)
+
+func TestGetDiskHealth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "disk-probe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if res := GetDiskHealth(dir, 100); res["status"] != service.Stat_healthy {
+		t.Errorf("disk should be healthy with threshold 100, %v", res)
+	}
+	if res := GetDiskHealth(dir, 0.0001); res["status"] != service.Stat_unhealthy {
+		t.Errorf("disk should be unhealthy with threshold 0.0001, %v", res)
+	}
+	if res := GetDiskHealth(filepath.Join(dir, "not-exist"), 90); res["status"] != service.Stat_unhealthy {
+		t.Errorf("disk of not exist path should be unhealthy, %v", res)
+	}
+}
+
+func TestGetClockSkewHealth(t *testing.T) {
+	skew := time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(skew).UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+	if res := GetClockSkewHealth([]string{server.URL}, 5); res["status"] != service.Stat_unhealthy {
+		t.Errorf("clock skew of one hour should be unhealthy, %v", res)
+	}
+	skew = 0
+	if res := GetClockSkewHealth([]string{server.URL}, 5); res["status"] != service.Stat_healthy {
+		t.Errorf("clock without skew should be healthy, %v", res)
+	}
+	if res := GetClockSkewHealth(nil, 5); res["status"] != service.Stat_Unknow {
+		t.Errorf("clock skew without master should be unknow, %v", res)
+	}
+}
+
+func TestGetDockerHealth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-probe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_ping" {
+			w.Write([]byte("OK"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})}
+	go server.Serve(l)
+	defer server.Close()
+	if res := GetDockerHealth("unix://" + socket); res["status"] != service.Stat_healthy {
+		t.Errorf("docker should be healthy, %v", res)
+	}
+	if res := GetDockerHealth(socket + ".not-exist"); res["status"] != service.Stat_death {
+		t.Errorf("docker should be death, %v", res)
+	}
+}
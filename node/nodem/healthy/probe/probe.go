@@ -34,9 +34,24 @@ type Probe interface {
 }
 
 //CreateProbe create probe
-func CreateProbe(ctx context.Context, hostNode *client.HostNode, statusChan chan *service.HealthStatus, v *service.Service) (Probe, error) {
+func CreateProbe(ctx context.Context, hostNode *client.HostNode, cluster client.ClusterClient, statusChan chan *service.HealthStatus, v *service.Service) (Probe, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	model := strings.ToLower(strings.TrimSpace(v.ServiceHealth.Model))
+	if IsNodeProbeModel(model) {
+		return &NodeProbe{
+			Name:         v.ServiceHealth.Name,
+			Model:        model,
+			Address:      v.ServiceHealth.Address,
+			Threshold:    v.ServiceHealth.Threshold,
+			Ctx:          ctx,
+			Cancel:       cancel,
+			ResultsChan:  statusChan,
+			TimeInterval: v.ServiceHealth.TimeInterval,
+			HostNode:     hostNode,
+			Cluster:      cluster,
+			MaxErrorsNum: v.ServiceHealth.MaxErrorsNum,
+		}, nil
+	}
 	switch model {
 	case "http":
 		return &HttpProbe{
@@ -0,0 +1,135 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package journald
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/coreos/go-systemd/journal"
+	"github.com/goodrain/rainbond/node/nodem/logger"
+	"github.com/sirupsen/logrus"
+)
+
+//Name driver name
+const Name = "journald"
+
+// the journal of the host, they are replaced by tests
+var (
+	journalEnabled = journal.Enabled
+	journalSend    = journal.Send
+)
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+//JournaldLogger sends the container logs to the local systemd journal
+type JournaldLogger struct {
+	vars map[string]string
+}
+
+//New create journald logger
+func New(info logger.Info) (logger.Logger, error) {
+	if !journalEnabled() {
+		return nil, fmt.Errorf("journald is not enabled on this host")
+	}
+	tag := info.Config["tag"]
+	if tag == "" {
+		tag = info.ID()
+	}
+	vars := map[string]string{
+		"CONTAINER_ID":      info.ID(),
+		"CONTAINER_ID_FULL": info.FullID(),
+		"CONTAINER_NAME":    info.Name(),
+		"CONTAINER_TAG":     tag,
+		"SYSLOG_IDENTIFIER": tag,
+	}
+	if tenantID := info.EnvValue("TENANT_ID"); tenantID != "" {
+		vars["RAINBOND_TENANT_ID"] = tenantID
+	}
+	if serviceID := info.EnvValue("SERVICE_ID"); serviceID != "" {
+		vars["RAINBOND_SERVICE_ID"] = serviceID
+	}
+	for k, v := range info.ExtraAttributes(sanitizeKeyMod) {
+		if k != "" {
+			vars[k] = v
+		}
+	}
+	return &JournaldLogger{vars: vars}, nil
+}
+
+// sanitizeKeyMod converts the key to the journal field name which
+// only contains uppercase letters, digits and underscores, and not starts with underscore
+func sanitizeKeyMod(s string) string {
+	s = strings.Map(func(r rune) rune {
+		r = unicode.ToUpper(r)
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+	return strings.TrimLeft(s, "_")
+}
+
+//Log sends the message to journal
+func (j *JournaldLogger) Log(msg *logger.Message) error {
+	vars := j.vars
+	if msg.Partial {
+		vars = make(map[string]string, len(j.vars)+1)
+		for k, v := range j.vars {
+			vars[k] = v
+		}
+		vars["CONTAINER_PARTIAL_MESSAGE"] = "true"
+	}
+	line := string(bytes.TrimSuffix(msg.Line, []byte("\n")))
+	if msg.Source == "stderr" {
+		return journalSend(line, journal.PriErr, vars)
+	}
+	return journalSend(line, journal.PriInfo, vars)
+}
+
+//ValidateLogOpt validate journald log options
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case "tag", "labels", "env":
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	return nil
+}
+
+//Close closes the logger
+func (j *JournaldLogger) Close() error {
+	return nil
+}
+
+//Name returns name of this logger
+func (j *JournaldLogger) Name() string {
+	return Name
+}
@@ -0,0 +1,124 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package journald
+
+import (
+	"testing"
+
+	"github.com/coreos/go-systemd/journal"
+	"github.com/goodrain/rainbond/node/nodem/logger"
+)
+
+type sent struct {
+	message  string
+	priority journal.Priority
+	vars     map[string]string
+}
+
+func fakeJournal(enabled bool) (*[]sent, func()) {
+	oldEnabled, oldSend := journalEnabled, journalSend
+	var messages []sent
+	journalEnabled = func() bool { return enabled }
+	journalSend = func(message string, priority journal.Priority, vars map[string]string) error {
+		messages = append(messages, sent{message: message, priority: priority, vars: vars})
+		return nil
+	}
+	return &messages, func() {
+		journalEnabled, journalSend = oldEnabled, oldSend
+	}
+}
+
+func TestJournaldLogger(t *testing.T) {
+	messages, restore := fakeJournal(true)
+	defer restore()
+
+	info := logger.Info{
+		Config:        map[string]string{"tag": "web", "labels": "app.version"},
+		ContainerID:   "0123456789abcdef0123",
+		ContainerName: "/web",
+		ContainerEnv:  []string{"TENANT_ID=tenant1", "SERVICE_ID=service1"},
+		ContainerLabels: map[string]string{
+			"app.version": "v1",
+		},
+	}
+	if err := ValidateLogOpt(info.Config); err != nil {
+		t.Fatal(err)
+	}
+	l, err := New(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Log(&logger.Message{Line: []byte("hello\n"), Source: "stdout"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(&logger.Message{Line: []byte("oops"), Source: "stderr", Partial: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*messages) != 2 {
+		t.Fatalf("want 2 messages, but got %d", len(*messages))
+	}
+	first, second := (*messages)[0], (*messages)[1]
+	if first.message != "hello" || first.priority != journal.PriInfo {
+		t.Errorf("unexpected message %+v", first)
+	}
+	for k, v := range map[string]string{
+		"CONTAINER_TAG":       "web",
+		"SYSLOG_IDENTIFIER":   "web",
+		"RAINBOND_TENANT_ID":  "tenant1",
+		"RAINBOND_SERVICE_ID": "service1",
+		"APP_VERSION":         "v1",
+	} {
+		if first.vars[k] != v {
+			t.Errorf("want %s=%s, but got %s", k, v, first.vars[k])
+		}
+	}
+	if _, ok := first.vars["CONTAINER_PARTIAL_MESSAGE"]; ok {
+		t.Errorf("complete message should not be marked as partial")
+	}
+	if second.priority != journal.PriErr || second.vars["CONTAINER_PARTIAL_MESSAGE"] != "true" {
+		t.Errorf("unexpected message %+v", second)
+	}
+}
+
+func TestJournaldNotEnabled(t *testing.T) {
+	_, restore := fakeJournal(false)
+	defer restore()
+	if _, err := New(logger.Info{Config: map[string]string{}}); err == nil {
+		t.Errorf("want error if journald is not enabled")
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	if err := ValidateLogOpt(map[string]string{"tag": "web", "env": "A"}); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateLogOpt(map[string]string{"syslog-address": "udp://127.0.0.1:514"}); err == nil {
+		t.Error("want error for unknown log opt")
+	}
+}
+
+func TestSanitizeKeyMod(t *testing.T) {
+	for in, want := range map[string]string{"app.version": "APP_VERSION", "_io-kube": "IO_KUBE", "Env1": "ENV1"} {
+		if got := sanitizeKeyMod(in); got != want {
+			t.Errorf("sanitize %s: want %s, but got %s", in, want, got)
+		}
+	}
+}
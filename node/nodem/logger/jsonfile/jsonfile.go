@@ -0,0 +1,247 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/daemon/logger/jsonfilelog/jsonlog"
+	units "github.com/docker/go-units"
+	"github.com/goodrain/rainbond/node/nodem/logger"
+	"github.com/sirupsen/logrus"
+)
+
+//Name driver name
+const Name = "json-file"
+
+//DefaultLogPath the default directory of log files
+var DefaultLogPath = "/var/log/rainbond/containers"
+
+const (
+	defaultMaxSize  int64 = 100 * units.MiB
+	defaultMaxFiles       = 5
+)
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+//JSONFileLogger writes the container logs to local json files,
+//the file will be rotated when the size reach max-size, and the rotated files
+//exceed max-file or older than max-age will be removed.
+type JSONFileLogger struct {
+	mu       sync.Mutex
+	file     *os.File
+	path     string
+	size     int64
+	maxSize  int64
+	maxFiles int
+	maxAge   time.Duration
+	extra    map[string]string
+	closed   bool
+}
+
+//New create json file logger
+func New(info logger.Info) (logger.Logger, error) {
+	maxSize, maxFiles, maxAge, err := parseRotateOpts(info.Config)
+	if err != nil {
+		return nil, err
+	}
+	dir := info.Config["log-path"]
+	if dir == "" {
+		dir = DefaultLogPath
+	}
+	serviceID := info.EnvValue("SERVICE_ID")
+	if serviceID == "" {
+		serviceID = "default"
+	}
+	path := filepath.Join(dir, serviceID, info.ContainerID+"-json.log")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create log dir failure %s", err.Error())
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	l := &JSONFileLogger{
+		file:     file,
+		path:     path,
+		size:     stat.Size(),
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		maxAge:   maxAge,
+		extra:    info.ExtraAttributes(nil),
+	}
+	l.removeExpiredFiles()
+	return l, nil
+}
+
+func parseRotateOpts(cfg map[string]string) (maxSize int64, maxFiles int, maxAge time.Duration, err error) {
+	maxSize, maxFiles = defaultMaxSize, defaultMaxFiles
+	if s, ok := cfg["max-size"]; ok {
+		if maxSize, err = units.FromHumanSize(s); err != nil {
+			return 0, 0, 0, fmt.Errorf("max-size %s is invalid %s", s, err.Error())
+		}
+		if maxSize <= 0 {
+			return 0, 0, 0, fmt.Errorf("max-size must be a positive number")
+		}
+	}
+	if s, ok := cfg["max-file"]; ok {
+		if maxFiles, err = strconv.Atoi(s); err != nil || maxFiles < 1 {
+			return 0, 0, 0, fmt.Errorf("max-file must be a positive number")
+		}
+	}
+	if s, ok := cfg["max-age"]; ok {
+		if maxAge, err = time.ParseDuration(s); err != nil || maxAge < 0 {
+			return 0, 0, 0, fmt.Errorf("max-age %s is not a valid duration", s)
+		}
+	}
+	return maxSize, maxFiles, maxAge, nil
+}
+
+//Log writes the message as docker json log line
+func (l *JSONFileLogger) Log(msg *logger.Message) error {
+	attrs := l.extra
+	if len(msg.Attrs) > 0 {
+		attrs = make(map[string]string, len(l.extra)+len(msg.Attrs))
+		for k, v := range l.extra {
+			attrs[k] = v
+		}
+		for k, v := range msg.Attrs {
+			attrs[k] = v
+		}
+	}
+	// the line read from docker json log keeps the newline
+	buf, err := json.Marshal(&jsonlog.JSONLog{
+		Log:     string(msg.Line),
+		Stream:  msg.Source,
+		Created: msg.Timestamp,
+		Attrs:   attrs,
+	})
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return fmt.Errorf("json file logger is closed")
+	}
+	if l.size+int64(len(buf)) > l.maxSize && l.size > 0 {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(buf)
+	l.size += int64(n)
+	return err
+}
+
+func rotatedName(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
+
+// rotate renames path.N-1 to path.N, ..., path to path.1 and opens a new file
+func (l *JSONFileLogger) rotate() error {
+	if err := l.file.Close(); err != nil {
+		logrus.Warningf("close log file %s failure %s", l.path, err.Error())
+	}
+	if l.maxFiles > 1 {
+		os.Remove(rotatedName(l.path, l.maxFiles-1))
+		for i := l.maxFiles - 2; i > 0; i-- {
+			if err := os.Rename(rotatedName(l.path, i), rotatedName(l.path, i+1)); err != nil && !os.IsNotExist(err) {
+				logrus.Warningf("rotate log file %s failure %s", l.path, err.Error())
+			}
+		}
+		if err := os.Rename(l.path, rotatedName(l.path, 1)); err != nil {
+			return fmt.Errorf("rotate log file %s failure %s", l.path, err.Error())
+		}
+	}
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+	l.file = file
+	l.size = 0
+	l.removeExpiredFiles()
+	return nil
+}
+
+func (l *JSONFileLogger) removeExpiredFiles() {
+	if l.maxAge <= 0 {
+		return
+	}
+	for i := 1; i < l.maxFiles; i++ {
+		name := rotatedName(l.path, i)
+		stat, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		if time.Since(stat.ModTime()) > l.maxAge {
+			if err := os.Remove(name); err != nil {
+				logrus.Warningf("remove expired log file %s failure %s", name, err.Error())
+			}
+		}
+	}
+}
+
+//ValidateLogOpt validate json file log options
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case "log-path", "max-size", "max-file", "max-age", "labels", "env":
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	_, _, _, err := parseRotateOpts(cfg)
+	return err
+}
+
+//Close closes the log file
+func (l *JSONFileLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.file.Close()
+}
+
+//Name returns name of this logger
+func (l *JSONFileLogger) Name() string {
+	return Name
+}
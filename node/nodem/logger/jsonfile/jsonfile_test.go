@@ -0,0 +1,71 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/node/nodem/logger"
+)
+
+func TestJSONFileLoggerRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := map[string]string{"log-path": dir, "max-size": "200", "max-file": "3"}
+	if err := ValidateLogOpt(cfg); err != nil {
+		t.Fatal(err)
+	}
+	l, err := New(logger.Info{
+		Config:       cfg,
+		ContainerID:  "0123456789abcdef",
+		ContainerEnv: []string{"SERVICE_ID=sid"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < 20; i++ {
+		if err := l.Log(&logger.Message{Line: []byte("hello world\n"), Source: "stdout", Timestamp: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "sid", "0123456789abcdef-json.log")
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		stat, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stat.Size() > 200 {
+			t.Fatalf("log file %s exceed max size", name)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatal("log files exceed max-file should be removed")
+	}
+	if err := ValidateLogOpt(map[string]string{"max-age": "1x"}); err == nil {
+		t.Fatal("invalid max-age should not pass validation")
+	}
+}
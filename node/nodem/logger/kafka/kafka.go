@@ -0,0 +1,292 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/goodrain/rainbond/node/nodem/logger"
+	"github.com/sirupsen/logrus"
+)
+
+//Name driver name
+const Name = "kafka"
+
+// redialInterval the interval of connecting the brokers again after a failure
+var redialInterval = 10 * time.Second
+
+// newProducer creates the producer connecting the brokers, it is replaced by tests
+var newProducer = sarama.NewSyncProducer
+
+var compressions = map[string]sarama.CompressionCodec{
+	"none":   sarama.CompressionNone,
+	"gzip":   sarama.CompressionGZIP,
+	"snappy": sarama.CompressionSnappy,
+	"lz4":    sarama.CompressionLZ4,
+}
+
+var requiredAcks = map[string]sarama.RequiredAcks{
+	"0":   sarama.NoResponse,
+	"1":   sarama.WaitForLocal,
+	"-1":  sarama.WaitForAll,
+	"all": sarama.WaitForAll,
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+//KafkaLogger produces the container logs to kafka topic, one json record for one line.
+//the records of one component use the service id as key, so that they are ordered in one partition.
+//The brokers are connected in background, the logs are dropped until they are connected.
+type KafkaLogger struct {
+	mu       sync.Mutex
+	brokers  []string
+	topic    string
+	config   *sarama.Config
+	producer sarama.SyncProducer
+	key      sarama.Encoder
+	record   record
+	closed   bool
+	done     chan struct{}
+
+	newProducer    func(addrs []string, config *sarama.Config) (sarama.SyncProducer, error)
+	redialInterval time.Duration
+}
+
+type record struct {
+	Log           string            `json:"log"`
+	Stream        string            `json:"stream"`
+	Time          time.Time         `json:"time"`
+	ContainerID   string            `json:"container_id"`
+	ContainerName string            `json:"container_name"`
+	TenantID      string            `json:"tenant_id,omitempty"`
+	ServiceID     string            `json:"service_id,omitempty"`
+	Attrs         map[string]string `json:"attrs,omitempty"`
+	Partial       bool              `json:"partial,omitempty"`
+}
+
+//New create kafka logger
+func New(info logger.Info) (logger.Logger, error) {
+	config, err := createConfig(info.Config)
+	if err != nil {
+		return nil, err
+	}
+	serviceID := info.EnvValue("SERVICE_ID")
+	k := &KafkaLogger{
+		brokers: splitBrokers(info.Config["kafka-brokers"]),
+		topic:   info.Config["kafka-topic"],
+		config:  config,
+		key:     sarama.StringEncoder(serviceID),
+		record: record{
+			ContainerID:   info.ID(),
+			ContainerName: info.Name(),
+			TenantID:      info.EnvValue("TENANT_ID"),
+			ServiceID:     serviceID,
+			Attrs:         info.ExtraAttributes(nil),
+		},
+		done:           make(chan struct{}),
+		newProducer:    newProducer,
+		redialInterval: redialInterval,
+	}
+	// connecting unreachable brokers blocks for a long time, which should not block the setup of container logs
+	go k.dial()
+	return k, nil
+}
+
+func splitBrokers(s string) (brokers []string) {
+	for _, b := range strings.Split(s, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	return
+}
+
+func createConfig(cfg map[string]string) (*sarama.Config, error) {
+	if len(splitBrokers(cfg["kafka-brokers"])) == 0 {
+		return nil, fmt.Errorf("kafka-brokers is required")
+	}
+	if cfg["kafka-topic"] == "" {
+		return nil, fmt.Errorf("kafka-topic is required")
+	}
+	config := sarama.NewConfig()
+	config.ClientID = "rainbond-node"
+	config.Producer.Return.Successes = true
+	config.Producer.Retry.Max = 3
+	config.Producer.RequiredAcks = sarama.WaitForLocal
+	if s, ok := cfg["kafka-version"]; ok {
+		version, err := sarama.ParseKafkaVersion(s)
+		if err != nil {
+			return nil, fmt.Errorf("kafka-version %s is invalid", s)
+		}
+		config.Version = version
+	}
+	if s, ok := cfg["kafka-compression"]; ok {
+		codec, ok := compressions[s]
+		if !ok {
+			return nil, fmt.Errorf("kafka-compression should be one of none, gzip, snappy and lz4")
+		}
+		config.Producer.Compression = codec
+	}
+	if s, ok := cfg["kafka-required-acks"]; ok {
+		acks, ok := requiredAcks[s]
+		if !ok {
+			return nil, fmt.Errorf("kafka-required-acks should be one of 0, 1 and all")
+		}
+		config.Producer.RequiredAcks = acks
+	}
+	if s, ok := cfg["kafka-tls"]; ok {
+		enable, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("kafka-tls must be a boolean")
+		}
+		if enable {
+			skipVerify, err := strconv.ParseBool(cfg["kafka-tls-skip-verify"])
+			if err != nil && cfg["kafka-tls-skip-verify"] != "" {
+				return nil, fmt.Errorf("kafka-tls-skip-verify must be a boolean")
+			}
+			tlsConfig, err := logger.NewTLSConfig(cfg["kafka-tls-ca-cert"], cfg["kafka-tls-cert"], cfg["kafka-tls-key"], skipVerify)
+			if err != nil {
+				return nil, err
+			}
+			config.Net.TLS.Enable = true
+			config.Net.TLS.Config = tlsConfig
+		}
+	}
+	if user := cfg["kafka-sasl-user"]; user != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Handshake = true
+		config.Net.SASL.User = user
+		config.Net.SASL.Password = cfg["kafka-sasl-password"]
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("kafka config is invalid %s", err.Error())
+	}
+	return config, nil
+}
+
+// dial connects the brokers until it succeeds or the logger is closed
+func (k *KafkaLogger) dial() {
+	for {
+		producer, err := k.newProducer(k.brokers, k.config)
+		if err == nil {
+			k.mu.Lock()
+			defer k.mu.Unlock()
+			if k.closed {
+				producer.Close()
+				return
+			}
+			k.producer = producer
+			return
+		}
+		logrus.Warningf("connect kafka brokers %v failure %s, retry after %s", k.brokers, err.Error(), k.redialInterval)
+		select {
+		case <-k.done:
+			return
+		case <-time.After(k.redialInterval):
+		}
+	}
+}
+
+//Log produces the message to kafka, it blocks until the record is acknowledged by the brokers
+func (k *KafkaLogger) Log(msg *logger.Message) error {
+	r := k.record
+	r.Log = string(bytes.TrimSuffix(msg.Line, []byte("\n")))
+	r.Stream = msg.Source
+	r.Time = msg.Timestamp
+	r.Partial = msg.Partial
+	if len(msg.Attrs) > 0 {
+		r.Attrs = make(map[string]string, len(k.record.Attrs)+len(msg.Attrs))
+		for key, v := range k.record.Attrs {
+			r.Attrs[key] = v
+		}
+		for key, v := range msg.Attrs {
+			r.Attrs[key] = v
+		}
+	}
+	value, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return fmt.Errorf("kafka logger is closed")
+	}
+	if k.producer == nil {
+		return fmt.Errorf("kafka brokers %v are not connected", k.brokers)
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:     k.topic,
+		Key:       k.key,
+		Value:     sarama.ByteEncoder(value),
+		Timestamp: msg.Timestamp,
+	})
+	return err
+}
+
+//ValidateLogOpt validate kafka log options
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case "kafka-brokers", "kafka-topic", "kafka-version", "kafka-compression", "kafka-required-acks",
+			"kafka-tls", "kafka-tls-ca-cert", "kafka-tls-cert", "kafka-tls-key", "kafka-tls-skip-verify",
+			"kafka-sasl-user", "kafka-sasl-password", "labels", "env":
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	_, err := createConfig(cfg)
+	return err
+}
+
+//Close closes the producer
+func (k *KafkaLogger) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return nil
+	}
+	k.closed = true
+	close(k.done)
+	if k.producer != nil {
+		err := k.producer.Close()
+		k.producer = nil
+		return err
+	}
+	return nil
+}
+
+//Name returns name of this logger
+func (k *KafkaLogger) Name() string {
+	return Name
+}
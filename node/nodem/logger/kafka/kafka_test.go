@@ -0,0 +1,204 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/goodrain/rainbond/node/nodem/logger"
+)
+
+type fakeProducer struct {
+	mu       sync.Mutex
+	messages []*sarama.ProducerMessage
+	closed   bool
+}
+
+func (f *fakeProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, msg)
+	return 0, int64(len(f.messages)), nil
+}
+
+func (f *fakeProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	for _, msg := range msgs {
+		f.SendMessage(msg)
+	}
+	return nil
+}
+
+func (f *fakeProducer) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+// fakeBrokers replaces the producer factory, the first failures attempts fail
+func fakeBrokers(failures int, producer sarama.SyncProducer) (*int, func()) {
+	oldNew, oldInterval := newProducer, redialInterval
+	var mu sync.Mutex
+	attempts := 0
+	redialInterval = time.Millisecond
+	newProducer = func(addrs []string, config *sarama.Config) (sarama.SyncProducer, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts <= failures {
+			return nil, fmt.Errorf("brokers %v are unreachable", addrs)
+		}
+		return producer, nil
+	}
+	return &attempts, func() {
+		newProducer, redialInterval = oldNew, oldInterval
+	}
+}
+
+func testInfo() logger.Info {
+	return logger.Info{
+		Config:        map[string]string{"kafka-brokers": "kafka-0:9092, kafka-1:9092", "kafka-topic": "logs"},
+		ContainerID:   "0123456789abcdef0123",
+		ContainerName: "/web",
+		ContainerEnv:  []string{"TENANT_ID=tenant1", "SERVICE_ID=service1"},
+	}
+}
+
+func waitConnected(t *testing.T, l logger.Logger) {
+	k := l.(*KafkaLogger)
+	for i := 0; i < 1000; i++ {
+		k.mu.Lock()
+		connected := k.producer != nil
+		k.mu.Unlock()
+		if connected {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("kafka brokers are not connected")
+}
+
+func TestKafkaLoggerRetryConnect(t *testing.T) {
+	producer := &fakeProducer{}
+	attempts, restore := fakeBrokers(2, producer)
+	defer restore()
+
+	l, err := New(testInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	waitConnected(t, l)
+	if *attempts != 3 {
+		t.Errorf("want 3 attempts, but got %d", *attempts)
+	}
+
+	now := time.Now()
+	if err := l.Log(&logger.Message{Line: []byte("hello\n"), Source: "stdout", Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+	if len(producer.messages) != 1 {
+		t.Fatalf("want 1 message, but got %d", len(producer.messages))
+	}
+	msg := producer.messages[0]
+	if msg.Topic != "logs" {
+		t.Errorf("want topic logs, but got %s", msg.Topic)
+	}
+	if key, _ := msg.Key.Encode(); string(key) != "service1" {
+		t.Errorf("want key service1, but got %s", key)
+	}
+	value, _ := msg.Value.Encode()
+	var r record
+	if err := json.Unmarshal(value, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Log != "hello" || r.Stream != "stdout" || r.TenantID != "tenant1" || r.ServiceID != "service1" || r.ContainerName != "web" {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
+
+func TestKafkaLoggerNotBlocked(t *testing.T) {
+	block := make(chan struct{})
+	oldNew := newProducer
+	newProducer = func(addrs []string, config *sarama.Config) (sarama.SyncProducer, error) {
+		<-block
+		return nil, fmt.Errorf("brokers %v are unreachable", addrs)
+	}
+	defer func() { newProducer = oldNew }()
+	defer close(block)
+
+	l, err := New(testInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(&logger.Message{Line: []byte("hello"), Source: "stdout"}); err == nil {
+		t.Error("want error before the brokers are connected")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKafkaLoggerClose(t *testing.T) {
+	producer := &fakeProducer{}
+	_, restore := fakeBrokers(0, producer)
+	defer restore()
+
+	l, err := New(testInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitConnected(t, l)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !producer.closed {
+		t.Error("want producer closed")
+	}
+	if err := l.Log(&logger.Message{Line: []byte("hello"), Source: "stdout"}); err == nil {
+		t.Error("want error after closed")
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("close twice: %v", err)
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	tests := []struct {
+		cfg     map[string]string
+		wantErr bool
+	}{
+		{cfg: map[string]string{"kafka-brokers": "kafka:9092", "kafka-topic": "logs", "kafka-compression": "gzip", "kafka-required-acks": "all"}},
+		{cfg: map[string]string{"kafka-topic": "logs"}, wantErr: true},
+		{cfg: map[string]string{"kafka-brokers": "kafka:9092"}, wantErr: true},
+		{cfg: map[string]string{"kafka-brokers": "kafka:9092", "kafka-topic": "logs", "kafka-compression": "zstd"}, wantErr: true},
+		{cfg: map[string]string{"kafka-brokers": "kafka:9092", "kafka-topic": "logs", "kafka-required-acks": "2"}, wantErr: true},
+		{cfg: map[string]string{"kafka-brokers": "kafka:9092", "kafka-topic": "logs", "tag": "web"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		if err := ValidateLogOpt(tc.cfg); (err != nil) != tc.wantErr {
+			t.Errorf("validate %v: want error %v, but got %v", tc.cfg, tc.wantErr, err)
+		}
+	}
+}
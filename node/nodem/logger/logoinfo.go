@@ -79,6 +79,16 @@ func (info *Info) ExtraAttributes(keyMod func(string) string) map[string]string
 	return extra
 }
 
+// EnvValue returns the value of the container environment variable
+func (info *Info) EnvValue(key string) string {
+	for _, e := range info.ContainerEnv {
+		if kv := strings.SplitN(e, "=", 2); len(kv) == 2 && kv[0] == key {
+			return kv[1]
+		}
+	}
+	return ""
+}
+
 // Hostname returns the hostname from the underlying OS.
 func (info *Info) Hostname() (string, error) {
 	hostname, err := os.Hostname()
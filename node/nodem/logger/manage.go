@@ -372,6 +372,10 @@ func (container *ContainerLog) startLogger() ([]Logger, error) {
 	configs := getLoggerConfig(container.Config.Env)
 	var loggers []Logger
 	for _, config := range configs {
+		if err := ValidateLogOpts(config.Name, config.Options); err != nil {
+			logrus.Warnf("container %s log options is invalid %s", container.Name, err.Error())
+			continue
+		}
 		initDriver, err :=
 			GetLogDriver(config.Name)
 		if err != nil {
@@ -396,7 +400,7 @@ func (container *ContainerLog) startLogger() ([]Logger, error) {
 			logrus.Warnf("init container log driver failure %s", err.Error())
 			continue
 		}
-		loggers = append(loggers, l)
+		loggers = append(loggers, WrapLogMode(l, config.Options))
 	}
 	if len(loggers) == 0 {
 		return nil, ErrNeglectedContainer
@@ -0,0 +1,150 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"sync"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	units "github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+)
+
+//DefaultMaxBufferSize the default buffer size of non-blocking mode
+const DefaultMaxBufferSize int64 = 1e6
+
+//RingLogger buffers the messages in memory and sends them to the driver in background.
+//It is used for the non-blocking mode, the messages are dropped when the buffer is full,
+//so that a slow log driver will not block the log copier.
+type RingLogger struct {
+	driver  Logger
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []*Message
+	size    int64
+	maxSize int64
+	dropped int64
+	closed  bool
+	done    chan struct{}
+}
+
+//WrapLogMode wraps the driver with RingLogger if the mode of log options is non-blocking
+func WrapLogMode(driver Logger, cfg map[string]string) Logger {
+	if containertypes.LogMode(cfg["mode"]) != containertypes.LogModeNonBlock {
+		return driver
+	}
+	maxSize := DefaultMaxBufferSize
+	if s, ok := cfg["max-buffer-size"]; ok {
+		if size, err := units.RAMInBytes(s); err == nil && size > 0 {
+			maxSize = size
+		}
+	}
+	return NewRingLogger(driver, maxSize)
+}
+
+//NewRingLogger create ring logger
+func NewRingLogger(driver Logger, maxSize int64) *RingLogger {
+	r := &RingLogger{
+		driver:  driver,
+		maxSize: maxSize,
+		done:    make(chan struct{}),
+	}
+	r.cond = sync.NewCond(&r.mu)
+	go r.run()
+	return r
+}
+
+//Log puts the message into buffer, the message is dropped if the buffer is full
+func (r *RingLogger) Log(msg *Message) error {
+	// the reader may reuse the message after Log returns
+	copied := &Message{
+		Line:      append(make([]byte, 0, len(msg.Line)), msg.Line...),
+		Source:    msg.Source,
+		Timestamp: msg.Timestamp,
+		Attrs:     msg.Attrs,
+		Partial:   msg.Partial,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	if r.size+int64(len(copied.Line)) > r.maxSize && len(r.queue) > 0 {
+		r.dropped++
+		return nil
+	}
+	r.queue = append(r.queue, copied)
+	r.size += int64(len(copied.Line))
+	r.cond.Signal()
+	return nil
+}
+
+func (r *RingLogger) run() {
+	defer close(r.done)
+	lastReport := time.Now()
+	for {
+		r.mu.Lock()
+		for len(r.queue) == 0 && !r.closed {
+			r.cond.Wait()
+		}
+		if len(r.queue) == 0 && r.closed {
+			r.mu.Unlock()
+			return
+		}
+		msgs := r.queue
+		r.queue = nil
+		r.size = 0
+		dropped := r.dropped
+		if dropped > 0 && time.Since(lastReport) > time.Minute {
+			r.dropped = 0
+			lastReport = time.Now()
+		} else {
+			dropped = 0
+		}
+		r.mu.Unlock()
+		if dropped > 0 {
+			logrus.Warningf("log driver %s is too slow, %d messages were dropped", r.driver.Name(), dropped)
+		}
+		for _, msg := range msgs {
+			if err := r.driver.Log(msg); err != nil {
+				logrus.Debugf("log driver %s send message failure %s", r.driver.Name(), err.Error())
+			}
+		}
+	}
+}
+
+//Name returns the name of the driver
+func (r *RingLogger) Name() string {
+	return r.driver.Name()
+}
+
+//Close flushes the buffered messages and closes the driver
+func (r *RingLogger) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.cond.Broadcast()
+	r.mu.Unlock()
+	<-r.done
+	return r.driver.Close()
+}
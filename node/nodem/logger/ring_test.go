@@ -0,0 +1,74 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"sync"
+	"testing"
+)
+
+type blockedLogger struct {
+	mu      sync.Mutex
+	release chan struct{}
+	lines   []string
+}
+
+func (b *blockedLogger) Log(msg *Message) error {
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, string(msg.Line))
+	return nil
+}
+
+func (b *blockedLogger) Name() string { return "blocked" }
+
+func (b *blockedLogger) Close() error { return nil }
+
+func TestRingLoggerDropWhenFull(t *testing.T) {
+	driver := &blockedLogger{release: make(chan struct{})}
+	l := WrapLogMode(driver, map[string]string{"mode": "non-blocking", "max-buffer-size": "10b"})
+	ring, ok := l.(*RingLogger)
+	if !ok {
+		t.Fatal("non-blocking mode should use ring logger")
+	}
+	for i := 0; i < 20; i++ {
+		msg := &Message{Line: []byte("hello")}
+		if err := ring.Log(msg); err != nil {
+			t.Fatal(err)
+		}
+		// the ring logger must copy the line
+		msg.Line[0] = 'x'
+	}
+	close(driver.release)
+	if err := ring.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(driver.lines) == 0 || len(driver.lines) >= 20 {
+		t.Fatalf("expect part of messages dropped, got %d", len(driver.lines))
+	}
+	for _, line := range driver.lines {
+		if line != "hello" {
+			t.Fatalf("unexpected line %s", line)
+		}
+	}
+	if WrapLogMode(driver, map[string]string{}) != Logger(driver) {
+		t.Fatal("blocking mode should not wrap the driver")
+	}
+}
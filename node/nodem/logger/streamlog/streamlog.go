@@ -211,6 +211,10 @@ func ValidateLogOpt(cfg map[string]string) error {
 			if _, err := strconv.Atoi(value); err != nil {
 				return errors.New("cache error log size must be a number")
 			}
+		case "cache-log-size":
+			if _, err := strconv.Atoi(value); err != nil {
+				return errors.New("cache log size must be a number")
+			}
 		default:
 			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, name)
 		}
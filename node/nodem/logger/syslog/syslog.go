@@ -0,0 +1,334 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package syslog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodrain/rainbond/node/nodem/logger"
+	"github.com/sirupsen/logrus"
+)
+
+//Name driver name
+const Name = "syslog"
+
+const (
+	defaultAddress = "udp://127.0.0.1:514"
+	dialTimeout    = 5 * time.Second
+	writeTimeout   = 10 * time.Second
+	// redialInterval avoid blocking the log copier by dialing an unreachable server for every message
+	redialInterval = 5 * time.Second
+	// sdID the structured data id, 32473 is the private enterprise number reserved for documentation
+	sdID = "rainbond@32473"
+)
+
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+const (
+	severityErr  = 3
+	severityInfo = 6
+)
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+//SysLogger sends the container logs to remote syslog server in RFC5424 format,
+//udp, tcp and tcp+tls transports are supported, the stream transports use octet counting framing.
+type SysLogger struct {
+	mu        sync.Mutex
+	network   string
+	address   string
+	tlsConfig *tls.Config
+	conn      net.Conn
+	lastDial  time.Time
+	facility  int
+	hostname  string
+	tag       string
+	procID    string
+	sd        string
+	closed    bool
+}
+
+//New create syslog logger
+func New(info logger.Info) (logger.Logger, error) {
+	network, address, err := parseAddress(info.Config["syslog-address"])
+	if err != nil {
+		return nil, err
+	}
+	facility, err := parseFacility(info.Config["syslog-facility"])
+	if err != nil {
+		return nil, err
+	}
+	var tlsConfig *tls.Config
+	if network == "tcp+tls" {
+		skipVerify, _ := strconv.ParseBool(info.Config["syslog-tls-skip-verify"])
+		tlsConfig, err = logger.NewTLSConfig(info.Config["syslog-tls-ca-cert"], info.Config["syslog-tls-cert"],
+			info.Config["syslog-tls-key"], skipVerify)
+		if err != nil {
+			return nil, err
+		}
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			tlsConfig.ServerName = host
+		}
+	}
+	hostname, _ := info.Hostname()
+	tag := info.Config["tag"]
+	if tag == "" {
+		tag = info.ID()
+	}
+	params := info.ExtraAttributes(nil)
+	if tenantID := info.EnvValue("TENANT_ID"); tenantID != "" {
+		params["tenant_id"] = tenantID
+	}
+	if serviceID := info.EnvValue("SERVICE_ID"); serviceID != "" {
+		params["service_id"] = serviceID
+	}
+	s := &SysLogger{
+		network:   network,
+		address:   address,
+		tlsConfig: tlsConfig,
+		facility:  facility,
+		hostname:  header(hostname, 255),
+		tag:       header(tag, 48),
+		procID:    header(info.ID(), 128),
+		sd:        structuredData(params),
+	}
+	if err := s.dial(); err != nil {
+		logrus.Warningf("connect syslog server %s failure %s, will retry when sending logs", address, err.Error())
+	}
+	return s, nil
+}
+
+func parseAddress(address string) (string, string, error) {
+	if address == "" {
+		address = defaultAddress
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", "", fmt.Errorf("syslog-address %s is invalid %s", address, err.Error())
+	}
+	switch u.Scheme {
+	case "udp", "tcp", "tcp+tls":
+	default:
+		return "", "", fmt.Errorf("syslog-address should be in form proto://address, proto is one of udp, tcp and tcp+tls")
+	}
+	host := u.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "514")
+	}
+	return u.Scheme, host, nil
+}
+
+func parseFacility(facility string) (int, error) {
+	if facility == "" {
+		return facilities["daemon"], nil
+	}
+	if f, ok := facilities[facility]; ok {
+		return f, nil
+	}
+	f, err := strconv.Atoi(facility)
+	if err != nil || f < 0 || f > 23 {
+		return 0, fmt.Errorf("syslog-facility %s is invalid", facility)
+	}
+	return f, nil
+}
+
+// header returns the printable ascii header field of RFC5424, "-" means nil value
+func header(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func structuredData(params map[string]string) string {
+	if len(params) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	buf.WriteString("[" + sdID)
+	for _, k := range keys {
+		name := strings.Map(func(r rune) rune {
+			if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' || r == ' ' {
+				return '_'
+			}
+			return r
+		}, k)
+		if len(name) > 32 {
+			name = name[:32]
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(params[k])
+		fmt.Fprintf(&buf, ` %s="%s"`, name, value)
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+//format formats the message in RFC5424
+func (s *SysLogger) format(msg *logger.Message) []byte {
+	severity := severityInfo
+	if msg.Source == "stderr" {
+		severity = severityErr
+	}
+	timestamp := msg.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	line := bytes.TrimSuffix(msg.Line, []byte("\n"))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s - %s ", s.facility*8+severity,
+		timestamp.Format("2006-01-02T15:04:05.000000Z07:00"), s.hostname, s.tag, s.procID, s.sd)
+	buf.Write(line)
+	return buf.Bytes()
+}
+
+func (s *SysLogger) dial() error {
+	s.lastDial = time.Now()
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	switch s.network {
+	case "tcp+tls":
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.address, s.tlsConfig)
+	default:
+		conn, err = dialer.Dial(s.network, s.address)
+	}
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *SysLogger) write(data []byte) error {
+	if s.conn == nil {
+		if time.Since(s.lastDial) < redialInterval {
+			return fmt.Errorf("syslog server %s is not connected", s.address)
+		}
+		if err := s.dial(); err != nil {
+			return err
+		}
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := s.conn.Write(data); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+//Log sends the message to syslog server
+func (s *SysLogger) Log(msg *logger.Message) error {
+	data := s.format(msg)
+	if s.network != "udp" {
+		// octet counting framing, RFC6587
+		data = append([]byte(strconv.Itoa(len(data))+" "), data...)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("syslog logger is closed")
+	}
+	err := s.write(data)
+	if err != nil && s.conn == nil && time.Since(s.lastDial) >= redialInterval {
+		// the server may close the idle connection, retry once with a new connection
+		err = s.write(data)
+	}
+	return err
+}
+
+//ValidateLogOpt validate syslog log options
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case "syslog-address", "syslog-facility", "syslog-tls-ca-cert", "syslog-tls-cert", "syslog-tls-key",
+			"syslog-tls-skip-verify", "tag", "labels", "env":
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	network, _, err := parseAddress(cfg["syslog-address"])
+	if err != nil {
+		return err
+	}
+	if _, err := parseFacility(cfg["syslog-facility"]); err != nil {
+		return err
+	}
+	if s, ok := cfg["syslog-tls-skip-verify"]; ok {
+		if _, err := strconv.ParseBool(s); err != nil {
+			return fmt.Errorf("syslog-tls-skip-verify must be a boolean")
+		}
+	}
+	if network != "tcp+tls" && (cfg["syslog-tls-ca-cert"] != "" || cfg["syslog-tls-cert"] != "" || cfg["syslog-tls-key"] != "") {
+		return fmt.Errorf("syslog tls options are only supported with tcp+tls address")
+	}
+	return nil
+}
+
+//Close closes the connection
+func (s *SysLogger) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.conn != nil {
+		err := s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+//Name returns name of this logger
+func (s *SysLogger) Name() string {
+	return Name
+}
@@ -0,0 +1,85 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/node/nodem/logger"
+)
+
+func TestSysLoggerTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		// octet counting framing: MSG-LEN SP SYSLOG-MSG
+		length, err := reader.ReadString(' ')
+		if err != nil {
+			return
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(length))
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(reader, buf); err == nil {
+			received <- string(buf)
+		}
+	}()
+	cfg := map[string]string{"syslog-address": "tcp://" + ln.Addr().String(), "syslog-facility": "local0", "tag": "web"}
+	if err := ValidateLogOpt(cfg); err != nil {
+		t.Fatal(err)
+	}
+	l, err := New(logger.Info{
+		Config:       cfg,
+		ContainerID:  "0123456789abcdef",
+		ContainerEnv: []string{"SERVICE_ID=sid"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	timestamp := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := l.Log(&logger.Message{Line: []byte("oops\n"), Source: "stderr", Timestamp: timestamp}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case line := <-received:
+		// facility local0(16) * 8 + severity err(3)
+		if !strings.HasPrefix(line, "<131>1 2020-01-02T03:04:05.000000Z ") ||
+			!strings.HasSuffix(line, " web 0123456789ab - [rainbond@32473 service_id=\"sid\"] oops") {
+			t.Fatalf("unexpected syslog message %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("syslog message not received")
+	}
+}
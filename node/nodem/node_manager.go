@@ -56,22 +56,19 @@ type NodeManager struct {
 	clm         *logger.ContainerLogManage
 
 	imageGCManager gc.ImageGCManager
+	// cordonAdvised whether unscheduler is advised to master by remediation
+	cordonAdvised bool
 }
 
 //NewNodeManager new a node manager
 func NewNodeManager(ctx context.Context, conf *option.Conf) (*NodeManager, error) {
-	healthyManager := healthy.CreateManager()
 	cluster := client.NewClusterClient(conf)
+	healthyManager := healthy.CreateManager(cluster)
 	monitor, err := monitor.CreateManager(ctx, conf)
 	if err != nil {
 		return nil, err
 	}
 	clm := logger.CreatContainerLogManage(conf)
-	controller := controller.NewManagerService(conf, healthyManager, cluster)
-	if err != nil {
-		return nil, fmt.Errorf("Get host id error:%s", err.Error())
-	}
-
 	imageGCPolicy := gc.ImageGCPolicy{
 		MinAge:               conf.ImageMinimumGCAge,
 		ImageGCPeriod:        conf.ImageGCPeriod,
@@ -82,6 +79,7 @@ func NewNodeManager(ctx context.Context, conf *option.Conf) (*NodeManager, error
 	if err != nil {
 		return nil, fmt.Errorf("create new imageGCManager: %v", err)
 	}
+	controller := controller.NewManagerService(conf, healthyManager, cluster, imageGCManager)
 
 	nodem := &NodeManager{
 		cfg:            conf,
@@ -196,6 +194,7 @@ func (n *NodeManager) heartbeat() {
 	util.Exec(n.ctx, func() error {
 		allServiceHealth := n.healthy.GetServiceHealth()
 		allHealth := true
+		var cordonReasons []string
 		for k, v := range allServiceHealth {
 			if ser := n.controller.GetService(k); ser != nil {
 				status := client.ConditionTrue
@@ -211,6 +210,9 @@ func (n *NodeManager) heartbeat() {
 						status = client.ConditionFalse
 						message = v.Info
 						reason = "NotHealth"
+						if ser.ServiceHealth.HasRemediation(service.RemediationCordon) {
+							cordonReasons = append(cordonReasons, fmt.Sprintf("service %s is %s: %s", ser.Name, v.Status, v.Info))
+						}
 					}
 				}
 				n.currentNode.GetAndUpdateCondition(client.NodeConditionType(ser.Name), status, reason, message)
@@ -244,6 +246,7 @@ func (n *NodeManager) heartbeat() {
 		if allHealth && n.cfg.AutoScheduler {
 			n.currentNode.NodeStatus.AdviceAction = []string{"scheduler"}
 		}
+		n.adviceCordon(cordonReasons)
 		n.currentNode.Status = "running"
 		n.currentNode.NodeStatus.Status = "running"
 		if err := n.cluster.UpdateStatus(n.currentNode, deleteCondition); err != nil {
@@ -256,6 +259,23 @@ func (n *NodeManager) heartbeat() {
 	}, time.Second*time.Duration(n.cfg.TTL))
 }
 
+//adviceCordon advise master to cordon the node when the remediation of unhealthy services contains cordon
+func (n *NodeManager) adviceCordon(reasons []string) {
+	if len(reasons) == 0 {
+		n.cordonAdvised = false
+		return
+	}
+	n.currentNode.NodeStatus.AdviceAction = []string{"unscheduler"}
+	if n.cordonAdvised {
+		return
+	}
+	n.cordonAdvised = true
+	logrus.Warningf("node %s is unhealthy, will send unscheduler advice action to master", n.currentNode.ID)
+	event := client.NewNodeEvent(n.currentNode.ID, "", service.RemediationCordon, nil, strings.Join(reasons, ";"))
+	event.Message = "advise master to mark the node unschedulable"
+	n.cluster.RecordEvent(event)
+}
+
 //init node init
 func (n *NodeManager) init() error {
 	node, err := n.cluster.GetNode(n.currentNode.ID)
@@ -81,6 +81,15 @@ type Endpoint struct {
 	Port     string `yaml:"port"`
 }
 
+//RemediationRestart restart the systemd unit of the service
+const RemediationRestart = "restart"
+
+//RemediationCordon cordon the node, the master will mark it unschedulable
+const RemediationCordon = "cordon"
+
+//RemediationImageGC run the image garbage collection of the node
+const RemediationImageGC = "image_gc"
+
 //Health ServiceHealth
 type Health struct {
 	Name         string `yaml:"name"`
@@ -88,6 +97,22 @@ type Health struct {
 	Address      string `yaml:"address"`
 	TimeInterval int    `yaml:"time_interval"`
 	MaxErrorsNum int    `yaml:"max_errors_num"`
+	// Threshold used by built-in probe models.
+	// disk and inode: max usage percent. clock: max skew seconds.
+	Threshold float64 `yaml:"threshold,omitempty"`
+	// Remediation the actions to do when the service is unhealthy more than MaxErrorsNum times.
+	// support restart, cordon and image_gc. if not set, the service will be restarted.
+	Remediation []string `yaml:"remediation,omitempty"`
+}
+
+//HasRemediation whether the remediation action is configured
+func (h *Health) HasRemediation(action string) bool {
+	for _, r := range h.Remediation {
+		if r == action {
+			return true
+		}
+	}
+	return false
 }
 
 //HealthStatus health status
@@ -0,0 +1,62 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	var tests = []struct {
+		spec, from, next string
+	}{
+		{"30 2 * * *", "2020-03-01 02:29:59", "2020-03-01 02:30:00"},
+		{"30 2 * * *", "2020-03-01 02:30:00", "2020-03-02 02:30:00"},
+		{"*/15 * * * *", "2020-03-01 23:50:00", "2020-03-02 00:00:00"},
+		{"0 9-17/4 * * mon-fri", "2020-03-06 17:00:00", "2020-03-09 09:00:00"},
+		{"@weekly", "2020-03-04 10:00:00", "2020-03-08 00:00:00"},
+		{"0 0 * * 7", "2020-03-04 10:00:00", "2020-03-08 00:00:00"},
+		{"0 0 29 2 *", "2020-03-01 00:00:00", "2024-02-29 00:00:00"},
+		{"0 0 13 * 5", "2020-03-01 00:00:00", "2020-03-06 00:00:00"},
+		{"0 0 1 jan,jul *", "2020-03-01 00:00:00", "2020-07-01 00:00:00"},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.spec)
+		if err != nil {
+			t.Fatalf("parse %s: %v", test.spec, err)
+		}
+		from, _ := time.Parse("2006-01-02 15:04:05", test.from)
+		if next := s.Next(from).Format("2006-01-02 15:04:05"); next != test.next {
+			t.Errorf("%s from %s: expect %s, got %s", test.spec, test.from, test.next, next)
+		}
+	}
+	s, _ := Parse("0 0 30 2 *")
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("expect zero time for impossible schedule, got %s", next)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@every", "* * * foo *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expect error for %q", spec)
+		}
+	}
+}
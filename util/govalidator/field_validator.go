@@ -25,6 +25,7 @@ var rmMap = map[string]string{
 	"bool":            "Boolean",
 	"between":         "Between",
 	"credit_card":     "CreditCard",
+	"cron":            "Cron",
 	"coordinate":      "Coordinate",
 	"css_color":       "ValidateCSSColor",
 	"digits":          "Digits",
@@ -79,6 +80,18 @@ func (v *fieldValidator) Required() {
 	v.errsBag.Add(v.field, fmt.Sprintf("The %s field is required", v.field))
 }
 
+// Cron check the field is a valid cron expression, such as "0 8 * * 1-5"
+func (v *fieldValidator) Cron() {
+	if v.value == "" || IsCron(v.value) {
+		return
+	}
+	if v.message != "" {
+		v.errsBag.Add(v.field, v.message)
+		return
+	}
+	v.errsBag.Add(v.field, fmt.Sprintf("The %s field must be a valid cron expression", v.field))
+}
+
 // Regex check the custom Regex rules
 // Regex:^[a-zA-Z]+$ means this field can only contain alphabet (a-z and A-Z)
 func (v *fieldValidator) Regex() {
@@ -3,6 +3,8 @@ package validator
 import (
 	"encoding/json"
 	"regexp"
+
+	"github.com/goodrain/rainbond/util/cron"
 )
 
 // IsAlpha check the input is letters (a-z,A-Z) or not
@@ -38,6 +40,12 @@ func IsCreditCard(card string) bool {
 	return regexCreditCard.MatchString(card)
 }
 
+// IsCron check the input is a valid cron expression
+func IsCron(str string) bool {
+	_, err := cron.Parse(str)
+	return err == nil
+}
+
 // IsCoordinate is a valid Coordinate or not
 func IsCoordinate(str string) bool {
 	return regexCoordinate.MatchString(str)
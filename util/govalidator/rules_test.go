@@ -0,0 +1,26 @@
+package validator
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCronRule(t *testing.T) {
+	type schedule struct {
+		Cron string `json:"cron" validate:"cron|required|cron"`
+	}
+	for body, valid := range map[string]bool{
+		`{"cron": "0 8 * * 1-5"}`: true,
+		`{"cron": "@daily"}`:      true,
+		`{"cron": "0 25 * * *"}`:  false,
+		`{"cron": "every day"}`:   false,
+		`{}`:                      false,
+	} {
+		var data schedule
+		errs := New(Options{Request: httptest.NewRequest("POST", "/", strings.NewReader(body)), Data: &data}).ValidateStructJSON()
+		if valid != (len(errs) == 0) {
+			t.Errorf("validate %s: expect valid %v, got %v", body, valid, errs)
+		}
+	}
+}
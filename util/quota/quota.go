@@ -0,0 +1,387 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package quota
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//ErrNotSupported the filesystem is not xfs or ext4 mounted with project quota
+var ErrNotSupported = errors.New("project quota is not supported")
+
+//Usage the space used by a quota project, in bytes
+type Usage struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit"`
+}
+
+//Quota enforces the capacity of directories with the project quotas of a filesystem
+type Quota interface {
+	//SetQuota limits the directory to size bytes, the directory is assigned
+	//a project the first time.
+	SetQuota(dir string, size int64) error
+	//Report returns the usage of the directories assigned a project, keyed by
+	//the directory
+	Report() (map[string]Usage, error)
+}
+
+//New returns the quota of the filesystem the directory belongs to,
+//ErrNotSupported if the filesystem does not enable project quota.
+func New(dir string) (Quota, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, ErrNotSupported
+	}
+	defer f.Close()
+	mounts, err := parseMountInfo(f)
+	if err != nil {
+		return nil, err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	mount := findMount(mounts, dir)
+	if mount == nil || !mount.projectQuota() {
+		return nil, ErrNotSupported
+	}
+	switch mount.fsType {
+	case "xfs":
+		x := &xfsQuota{mountPoint: mount.mountPoint}
+		x.projects = projects{mountPoint: mount.mountPoint, report: x.report}
+		return x, nil
+	case "ext4":
+		e := &ext4Quota{mountPoint: mount.mountPoint}
+		e.projects = projects{mountPoint: mount.mountPoint, report: e.report}
+		return e, nil
+	}
+	return nil, ErrNotSupported
+}
+
+//projectsFile the table of the project ids allocated to the directories of
+//a filesystem, kept at the mount point in the format of /etc/projects.
+const projectsFile = ".rainbond_projects"
+
+//the ids below are reserved by the system and the default project 0
+const (
+	minProjectID = 1 << 16
+	maxProjectID = 1<<31 - 1
+)
+
+var allocateLock sync.Mutex
+
+//ProjectID returns the preferred quota project id of the directory, derived
+//from the path. The id actually allocated may differ when it is taken by
+//another directory.
+func ProjectID(dir string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(filepath.Clean(dir)))
+	return h.Sum32()%(maxProjectID-minProjectID) + minProjectID
+}
+
+//GetUsage returns the usage of the directory from the report
+func GetUsage(report map[string]Usage, dir string) (Usage, bool) {
+	usage, ok := report[filepath.Clean(dir)]
+	return usage, ok
+}
+
+//projects the project ids allocated to the directories of a filesystem
+type projects struct {
+	mountPoint string
+	// report returns the usage of all the project ids of the filesystem
+	report func() (map[uint32]Usage, error)
+}
+
+//load reads the allocated project ids, keyed by the directory
+func (p *projects) load() (map[string]uint32, error) {
+	f, err := os.Open(filepath.Join(p.mountPoint, projectsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]uint32{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return parseProjects(f)
+}
+
+//allocate returns the project id of the directory, a free id is allocated
+//and recorded the first time. The allocation probes from the preferred id
+//until the id is neither in the table nor reported by the filesystem.
+func (p *projects) allocate(dir string) (uint32, error) {
+	allocateLock.Lock()
+	defer allocateLock.Unlock()
+	dir = filepath.Clean(dir)
+	table, err := p.load()
+	if err != nil {
+		return 0, err
+	}
+	if id, ok := table[dir]; ok {
+		return id, nil
+	}
+	report, err := p.report()
+	if err != nil {
+		return 0, err
+	}
+	used := make(map[uint32]bool, len(table)+len(report))
+	for _, id := range table {
+		used[id] = true
+	}
+	for id := range report {
+		used[id] = true
+	}
+	id, err := freeProjectID(used, ProjectID(dir))
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(filepath.Join(p.mountPoint, projectsFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "%d:%s\n", id, dir); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+//Report returns the usage of the directories in the table
+func (p *projects) Report() (map[string]Usage, error) {
+	report, err := p.report()
+	if err != nil {
+		return nil, err
+	}
+	table, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	usages := make(map[string]Usage, len(table))
+	for dir, id := range table {
+		if usage, ok := report[id]; ok {
+			usages[dir] = usage
+		}
+	}
+	return usages, nil
+}
+
+//assigned returns whether the directory is assigned the project with limit already,
+//assigning a project walks the whole directory.
+func (p *projects) assigned(id uint32) bool {
+	report, err := p.report()
+	if err != nil {
+		return false
+	}
+	usage, ok := report[id]
+	return ok && usage.Limit > 0
+}
+
+//freeProjectID probes from the preferred id for an id not used
+func freeProjectID(used map[uint32]bool, preferred uint32) (uint32, error) {
+	id := preferred
+	for i := 0; i < maxProjectID-minProjectID; i++ {
+		if !used[id] {
+			return id, nil
+		}
+		if id++; id >= maxProjectID {
+			id = minProjectID
+		}
+	}
+	return 0, errors.New("no free project id")
+}
+
+//parseProjects parses the lines of id:directory
+func parseProjects(r io.Reader) (map[string]uint32, error) {
+	table := make(map[string]uint32)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		id, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			continue
+		}
+		table[filepath.Clean(parts[1])] = uint32(id)
+	}
+	return table, scanner.Err()
+}
+
+type mountInfo struct {
+	mountPoint string
+	fsType     string
+	options    []string
+}
+
+func (m *mountInfo) projectQuota() bool {
+	for _, option := range m.options {
+		switch option {
+		case "prjquota", "pquota":
+			return true
+		}
+	}
+	return false
+}
+
+//parseMountInfo parses the mount points in the format of /proc/self/mountinfo:
+//36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func parseMountInfo(r io.Reader) ([]*mountInfo, error) {
+	var mounts []*mountInfo
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		sep := -1
+		for i, field := range fields {
+			if field == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep < 6 || len(fields) < sep+4 {
+			continue
+		}
+		options := strings.Split(fields[5], ",")
+		options = append(options, strings.Split(fields[sep+3], ",")...)
+		mounts = append(mounts, &mountInfo{
+			mountPoint: fields[4],
+			fsType:     fields[sep+1],
+			options:    options,
+		})
+	}
+	return mounts, scanner.Err()
+}
+
+//findMount returns the innermost mount point containing the directory
+func findMount(mounts []*mountInfo, dir string) *mountInfo {
+	var found *mountInfo
+	for _, mount := range mounts {
+		if dir != mount.mountPoint && mount.mountPoint != "/" && !strings.HasPrefix(dir, mount.mountPoint+"/") {
+			continue
+		}
+		if found == nil || len(mount.mountPoint) >= len(found.mountPoint) {
+			found = mount
+		}
+	}
+	return found
+}
+
+type xfsQuota struct {
+	projects
+	mountPoint string
+}
+
+func (x *xfsQuota) SetQuota(dir string, size int64) error {
+	id, err := x.allocate(dir)
+	if err != nil {
+		return err
+	}
+	if !x.assigned(id) {
+		if err := run("xfs_quota", "-x", "-c", fmt.Sprintf("project -s -p %s %d", dir, id), x.mountPoint); err != nil {
+			return err
+		}
+	}
+	return run("xfs_quota", "-x", "-c", fmt.Sprintf("limit -p bhard=%dk %d", kib(size), id), x.mountPoint)
+}
+
+func (x *xfsQuota) report() (map[uint32]Usage, error) {
+	out, err := exec.Command("xfs_quota", "-x", "-c", "report -p -N -b -n", x.mountPoint).Output()
+	if err != nil {
+		return nil, fmt.Errorf("xfs_quota report: %v", err)
+	}
+	// #67890 1024 0 2048 00 [--------]
+	return parseReport(string(out), 1), nil
+}
+
+type ext4Quota struct {
+	projects
+	mountPoint string
+}
+
+func (e *ext4Quota) SetQuota(dir string, size int64) error {
+	id, err := e.allocate(dir)
+	if err != nil {
+		return err
+	}
+	if !e.assigned(id) {
+		if err := run("chattr", "-R", "+P", "-p", strconv.FormatUint(uint64(id), 10), dir); err != nil {
+			return err
+		}
+	}
+	return run("setquota", "-P", strconv.FormatUint(uint64(id), 10), "0", strconv.FormatInt(kib(size), 10), "0", "0", e.mountPoint)
+}
+
+func (e *ext4Quota) report() (map[uint32]Usage, error) {
+	out, err := exec.Command("repquota", "-P", "-n", e.mountPoint).Output()
+	if err != nil {
+		return nil, fmt.Errorf("repquota: %v", err)
+	}
+	// #67890 -- 1024 0 2048 3 0 0
+	return parseReport(string(out), 2), nil
+}
+
+//parseReport parses the lines of the project ids in a quota report, the used
+//and hard limit blocks are in KiB, the hard limit is two columns after the used.
+func parseReport(out string, usedColumn int) map[uint32]Usage {
+	report := make(map[uint32]Usage)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) <= usedColumn+2 || !strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(fields[0], "#"), 10, 32)
+		if err != nil {
+			continue
+		}
+		used, err := strconv.ParseInt(fields[usedColumn], 10, 64)
+		if err != nil {
+			continue
+		}
+		limit, err := strconv.ParseInt(fields[usedColumn+2], 10, 64)
+		if err != nil {
+			continue
+		}
+		report[uint32(id)] = Usage{Used: used * 1024, Limit: limit * 1024}
+	}
+	return report
+}
+
+func kib(size int64) int64 {
+	return (size + 1023) / 1024
+}
+
+func run(name string, args ...string) error {
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s: %v, %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package quota
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mountinfo = `22 1 253:1 / / rw,relatime shared:1 - ext4 /dev/vda1 rw
+35 22 253:16 / /grdata rw,relatime shared:2 - xfs /dev/vdb rw,attr2,inode64,prjquota
+36 22 253:32 / /grlocaldata rw,relatime shared:3 - ext4 /dev/vdc rw,prjquota
+37 35 0:50 / /grdata/nfs rw,relatime shared:4 - nfs4 192.168.1.2:/data rw,vers=4.1
+`
+
+func TestFindMount(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(mountinfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dir        string
+		mountPoint string
+		fsType     string
+		supported  bool
+	}{
+		{dir: "/grdata/tenant/t1/service/s1", mountPoint: "/grdata", fsType: "xfs", supported: true},
+		{dir: "/grdata", mountPoint: "/grdata", fsType: "xfs", supported: true},
+		{dir: "/grlocaldata/tenant/t1", mountPoint: "/grlocaldata", fsType: "ext4", supported: true},
+		{dir: "/grdata/nfs/tenant", mountPoint: "/grdata/nfs", fsType: "nfs4"},
+		{dir: "/grdatax/tenant", mountPoint: "/", fsType: "ext4"},
+	}
+	for _, tc := range tests {
+		mount := findMount(mounts, tc.dir)
+		if mount == nil {
+			t.Fatalf("%s: no mount found", tc.dir)
+		}
+		if mount.mountPoint != tc.mountPoint || mount.fsType != tc.fsType || mount.projectQuota() != tc.supported {
+			t.Errorf("%s: got %s %s %v", tc.dir, mount.mountPoint, mount.fsType, mount.projectQuota())
+		}
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	xfs := `#0            0          0          0     00 [--------]
+#70001     1024          0    2097152     00 [--------]
+`
+	report := parseReport(xfs, 1)
+	if usage := report[70001]; usage.Used != 1024*1024 || usage.Limit != 2*1024*1024*1024 {
+		t.Errorf("unexpected xfs usage: %+v", usage)
+	}
+
+	ext4 := `*** Report for project quotas on device /dev/vdc
+Block grace time: 7days; Inode grace time: 7days
+                        Block limits                File limits
+Project         used    soft    hard  grace    used  soft  hard  grace
+----------------------------------------------------------------------
+#0        --      20       0       0              2     0     0
+#70002    +-    2100       0    2048  6days       3     0     0
+`
+	report = parseReport(ext4, 2)
+	if usage := report[70002]; usage.Used != 2100*1024 || usage.Limit != 2048*1024 {
+		t.Errorf("unexpected ext4 usage: %+v", usage)
+	}
+	if len(report) != 2 {
+		t.Errorf("want 2 projects, got %d", len(report))
+	}
+}
+
+func TestProjectID(t *testing.T) {
+	a := ProjectID("/grdata/tenant/t1/service/s1/data")
+	if a != ProjectID("/grdata/tenant/t1/service/s1/data/") {
+		t.Error("the project id should not depend on the trailing slash")
+	}
+	if a == ProjectID("/grdata/tenant/t1/service/s2/data") {
+		t.Error("different directories should have different project ids")
+	}
+	if a < 1<<16 || a >= 1<<31 {
+		t.Errorf("project id %d out of range", a)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	mountPoint, err := ioutil.TempDir("", "quota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountPoint)
+	a := filepath.Join(mountPoint, "a")
+	b := filepath.Join(mountPoint, "b")
+	// the preferred id of b is taken by a project unknown to the table
+	reported := map[uint32]Usage{ProjectID(b): {Used: 1024}}
+	p := &projects{mountPoint: mountPoint, report: func() (map[uint32]Usage, error) {
+		return reported, nil
+	}}
+
+	idA, err := p.allocate(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idA != ProjectID(a) {
+		t.Errorf("want the preferred id %d, got %d", ProjectID(a), idA)
+	}
+	idB, err := p.allocate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idB == ProjectID(b) || idB == idA {
+		t.Errorf("id %d of b collides", idB)
+	}
+	if id, _ := p.allocate(a + "/"); id != idA {
+		t.Errorf("want the allocated id %d, got %d", idA, id)
+	}
+
+	reported[idB] = Usage{Used: 2048, Limit: 4096}
+	report, err := p.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage, ok := GetUsage(report, b); !ok || usage.Used != 2048 {
+		t.Errorf("unexpected usage of b: %+v", usage)
+	}
+	if _, ok := GetUsage(report, a); ok {
+		t.Error("a is not reported")
+	}
+	if !p.assigned(idB) || p.assigned(idA) {
+		t.Error("only b is assigned a limit")
+	}
+}
+
+func TestFreeProjectID(t *testing.T) {
+	used := map[uint32]bool{maxProjectID - 1: true}
+	if id, err := freeProjectID(used, maxProjectID-1); err != nil || id != minProjectID {
+		t.Errorf("want the probe to wrap around, got %d %v", id, err)
+	}
+}
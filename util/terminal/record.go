@@ -0,0 +1,279 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+//DefaultRecordDir the default dir of terminal session records, it is shared by webcli and region api
+var DefaultRecordDir = "/grdata/webcli/sessions"
+
+var sessionIDReg = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+//SessionMeta metadata of a terminal session record
+type SessionMeta struct {
+	SessionID     string    `json:"session_id"`
+	User          string    `json:"user"`
+	TenantID      string    `json:"tenant_id"`
+	ServiceID     string    `json:"service_id"`
+	PodName       string    `json:"pod_name"`
+	ContainerName string    `json:"container_name"`
+	RemoteAddr    string    `json:"remote_addr"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time,omitempty"`
+	// Size the size of cast file
+	Size int64 `json:"size"`
+}
+
+func sessionPath(dir, tenantID, serviceID, sessionID string) (string, error) {
+	if !sessionIDReg.MatchString(tenantID) || !sessionIDReg.MatchString(serviceID) || !sessionIDReg.MatchString(sessionID) {
+		return "", fmt.Errorf("invalid session %s/%s/%s", tenantID, serviceID, sessionID)
+	}
+	return path.Join(dir, tenantID, serviceID, sessionID), nil
+}
+
+//ListSessions list session records of the service, the newest first
+func ListSessions(dir, tenantID, serviceID string) ([]*SessionMeta, error) {
+	if !sessionIDReg.MatchString(tenantID) || !sessionIDReg.MatchString(serviceID) {
+		return nil, fmt.Errorf("invalid service %s/%s", tenantID, serviceID)
+	}
+	files, err := ioutil.ReadDir(path.Join(dir, tenantID, serviceID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sessions []*SessionMeta
+	for _, f := range files {
+		if f.IsDir() || path.Ext(f.Name()) != ".json" {
+			continue
+		}
+		meta, err := readMeta(path.Join(dir, tenantID, serviceID, f.Name()))
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, meta)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.After(sessions[j].StartTime)
+	})
+	return sessions, nil
+}
+
+func readMeta(file string) (*SessionMeta, error) {
+	body, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var meta SessionMeta
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+//GetSession get the metadata of the session record
+func GetSession(dir, tenantID, serviceID, sessionID string) (*SessionMeta, error) {
+	p, err := sessionPath(dir, tenantID, serviceID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return readMeta(p + ".json")
+}
+
+//OpenSessionCast open the asciicast file of the session record
+func OpenSessionCast(dir, tenantID, serviceID, sessionID string) (*os.File, error) {
+	p, err := sessionPath(dir, tenantID, serviceID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p + ".cast")
+}
+
+//Recorder record the terminal session in asciicast v2 format
+//https://github.com/asciinema/asciinema/blob/develop/doc/asciicast-v2.md
+type Recorder struct {
+	meta     *SessionMeta
+	metaFile string
+	file     *os.File
+	lock     sync.Mutex
+	header   bool
+	width    int
+	height   int
+	// pending incomplete utf8 bytes of output and input
+	pending map[string][]byte
+	closed  bool
+}
+
+//NewRecorder create the recorder, cast and metadata files are created in dir
+func NewRecorder(dir string, meta *SessionMeta) (*Recorder, error) {
+	p, err := sessionPath(dir, meta.TenantID, meta.ServiceID, meta.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(p+".cast", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return nil, err
+	}
+	if meta.StartTime.IsZero() {
+		meta.StartTime = time.Now()
+	}
+	r := &Recorder{
+		meta:     meta,
+		metaFile: p + ".json",
+		file:     file,
+		width:    80,
+		height:   24,
+		pending:  make(map[string][]byte, 2),
+	}
+	if err := r.writeMeta(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) writeMeta() error {
+	body, err := json.Marshal(r.meta)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.metaFile, body, 0640)
+}
+
+func (r *Recorder) writeHeader() error {
+	if r.header {
+		return nil
+	}
+	r.header = true
+	header, err := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     r.width,
+		"height":    r.height,
+		"timestamp": r.meta.StartTime.Unix(),
+		"title":     fmt.Sprintf("%s/%s", r.meta.PodName, r.meta.ContainerName),
+		"env":       map[string]string{"TERM": "xterm"},
+	})
+	if err != nil {
+		return err
+	}
+	n, err := r.file.Write(append(header, '\n'))
+	r.meta.Size += int64(n)
+	return err
+}
+
+//Resize the terminal size before the first event is used in header
+func (r *Recorder) Resize(width, height int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.header {
+		r.width, r.height = width, height
+	}
+}
+
+//Output record data written to the terminal
+func (r *Recorder) Output(p []byte) error {
+	return r.record("o", p)
+}
+
+//Input record data read from the terminal
+func (r *Recorder) Input(p []byte) error {
+	return r.record("i", p)
+}
+
+func (r *Recorder) record(eventType string, p []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return nil
+	}
+	if err := r.writeHeader(); err != nil {
+		return err
+	}
+	data, pending := splitUTF8(append(r.pending[eventType], p...))
+	r.pending[eventType] = pending
+	if len(data) == 0 {
+		return nil
+	}
+	return r.writeEvent(eventType, string(data))
+}
+
+//Marker record a marker event, it audits the actions which are not terminal io, like file transfers
+func (r *Recorder) Marker(label string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return nil
+	}
+	if err := r.writeHeader(); err != nil {
+		return err
+	}
+	return r.writeEvent("m", label)
+}
+
+func (r *Recorder) writeEvent(eventType, data string) error {
+	event, err := json.Marshal([]interface{}{time.Since(r.meta.StartTime).Seconds(), eventType, data})
+	if err != nil {
+		return err
+	}
+	n, err := r.file.Write(append(event, '\n'))
+	r.meta.Size += int64(n)
+	return err
+}
+
+//splitUTF8 split the trailing incomplete utf8 rune
+func splitUTF8(p []byte) ([]byte, []byte) {
+	for i := 1; i < utf8.UTFMax && i <= len(p); i++ {
+		c := p[len(p)-i]
+		if utf8.RuneStart(c) {
+			if !utf8.FullRune(p[len(p)-i:]) {
+				return p[:len(p)-i], append([]byte(nil), p[len(p)-i:]...)
+			}
+			break
+		}
+	}
+	return p, nil
+}
+
+//Close close the cast file and update the metadata
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.file.Close()
+	r.meta.EndTime = time.Now()
+	return r.writeMeta()
+}
@@ -0,0 +1,115 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTicket(t *testing.T) {
+	secret := []byte("secret")
+	ticket := NewTicket("admin", "tenant", "service", "pod-0", "main", time.Hour)
+	if ticket.Expiration().After(time.Now().Add(MaxTicketTTL)) {
+		t.Errorf("ticket ttl should not more than %s", MaxTicketTTL)
+	}
+	token, err := ticket.Sign(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseTicket(token, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *parsed != *ticket {
+		t.Errorf("parsed ticket %v is not equal to %v", parsed, ticket)
+	}
+	if _, err := ParseTicket(token, []byte("other")); err != ErrTicketInvalid {
+		t.Errorf("ticket signed by other secret should be invalid, %v", err)
+	}
+	if _, err := ParseTicket("x"+token, secret); err != ErrTicketInvalid {
+		t.Errorf("tampered ticket should be invalid, %v", err)
+	}
+	ticket.ExpiresAt = time.Now().Add(-time.Second).Unix()
+	token, _ = ticket.Sign(secret)
+	if _, err := ParseTicket(token, secret); err != ErrTicketExpired {
+		t.Errorf("ticket should be expired, %v", err)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "terminal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	recorder, err := NewRecorder(dir, &SessionMeta{SessionID: "s1", User: "admin", TenantID: "tenant", ServiceID: "service", PodName: "pod-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Resize(120, 40)
+	recorder.Input([]byte("ls\r"))
+	// split a multi-byte rune across two writes
+	word := []byte("中文")
+	recorder.Output(word[:4])
+	recorder.Output(word[4:])
+	recorder.Marker("download /data/a.txt")
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file, err := OpenSessionCast(dir, "tenant", "service", "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 5 {
+		t.Fatalf("expect header and 4 events, got %v", lines)
+	}
+	var header map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header["width"] != float64(120) || header["version"] != float64(2) {
+		t.Errorf("unexpected header %s", lines[0])
+	}
+	var out1, out2 []interface{}
+	json.Unmarshal([]byte(lines[2]), &out1)
+	json.Unmarshal([]byte(lines[3]), &out2)
+	if out1[1] != "o" || out1[2] != "中" || out2[2] != "文" {
+		t.Errorf("unexpected output events %s %s", lines[2], lines[3])
+	}
+	var marker []interface{}
+	json.Unmarshal([]byte(lines[4]), &marker)
+	if marker[1] != "m" || marker[2] != "download /data/a.txt" {
+		t.Errorf("unexpected marker event %s", lines[4])
+	}
+	sessions, err := ListSessions(dir, "tenant", "service")
+	if err != nil || len(sessions) != 1 || sessions[0].EndTime.IsZero() || sessions[0].User != "admin" {
+		t.Errorf("unexpected sessions %v %v", sessions, err)
+	}
+	if _, err := GetSession(dir, "tenant", "service", "../s1"); err == nil {
+		t.Errorf("session id with path should be invalid")
+	}
+}
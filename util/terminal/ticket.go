@@ -0,0 +1,125 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package terminal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/util"
+)
+
+//DefaultTicketTTL default time to live of terminal ticket
+var DefaultTicketTTL = time.Minute
+
+//MaxTicketTTL max time to live of terminal ticket
+var MaxTicketTTL = time.Minute * 10
+
+//ErrTicketInvalid the ticket is malformed or the signature mismatch
+var ErrTicketInvalid = errors.New("terminal ticket is invalid")
+
+//ErrTicketExpired the ticket is expired
+var ErrTicketExpired = errors.New("terminal ticket is expired")
+
+//Ticket short-lived ticket to open a terminal of the container.
+//it is issued by region api and verified by webcli with the shared secret.
+type Ticket struct {
+	ID            string `json:"id"`
+	User          string `json:"user"`
+	TenantID      string `json:"tenant_id"`
+	ServiceID     string `json:"service_id"`
+	PodName       string `json:"pod_name"`
+	ContainerName string `json:"container_name,omitempty"`
+	ExpiresAt     int64  `json:"exp"`
+}
+
+//NewTicket create a ticket expires after ttl
+func NewTicket(user, tenantID, serviceID, podName, containerName string, ttl time.Duration) *Ticket {
+	if ttl <= 0 {
+		ttl = DefaultTicketTTL
+	}
+	if ttl > MaxTicketTTL {
+		ttl = MaxTicketTTL
+	}
+	return &Ticket{
+		ID:            util.NewUUID(),
+		User:          user,
+		TenantID:      tenantID,
+		ServiceID:     serviceID,
+		PodName:       podName,
+		ContainerName: containerName,
+		ExpiresAt:     time.Now().Add(ttl).Unix(),
+	}
+}
+
+//Expiration the expire time of the ticket
+func (t *Ticket) Expiration() time.Time {
+	return time.Unix(t.ExpiresAt, 0)
+}
+
+func sign(payload string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+//Sign encode the ticket as base64url(payload).base64url(hmac-sha256(payload))
+func (t *Ticket) Sign(secret []byte) (string, error) {
+	if len(secret) == 0 {
+		return "", fmt.Errorf("terminal ticket secret is not set")
+	}
+	body, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	return payload + "." + sign(payload, secret), nil
+}
+
+//ParseTicket verify the signature and expiration of the ticket
+func ParseTicket(token string, secret []byte) (*Ticket, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("terminal ticket secret is not set")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrTicketInvalid
+	}
+	if !hmac.Equal([]byte(sign(parts[0], secret)), []byte(parts[1])) {
+		return nil, ErrTicketInvalid
+	}
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrTicketInvalid
+	}
+	var ticket Ticket
+	if err := json.Unmarshal(body, &ticket); err != nil {
+		return nil, ErrTicketInvalid
+	}
+	if time.Now().After(ticket.Expiration()) {
+		return nil, ErrTicketExpired
+	}
+	return &ticket, nil
+}
@@ -0,0 +1,58 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2021 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package tracing
+
+import "net/url"
+
+const (
+	//ProviderZipkin means the spans are reported to a zipkin compatible collector
+	ProviderZipkin = "zipkin"
+	//ProviderOTLP means the spans are reported to an opentelemetry collector
+	ProviderOTLP = "otlp"
+)
+
+//DefaultZipkinCollectorPath the default span api path of zipkin collector
+const DefaultZipkinCollectorPath = "/api/v2/spans"
+
+//DefaultOpenCensusPort the default port of opencensus receiver of opentelemetry collector.
+//envoy v2 api can not export otlp directly, spans are exported to the opencensus receiver of the collector.
+const DefaultOpenCensusPort = 55678
+
+//the annotations of plugin config map telling envoy xds server how to report the spans
+const (
+	ProviderAnnotation       = "mesh_tracing_provider"
+	EndpointAnnotation       = "mesh_tracing_endpoint"
+	SampleRateAnnotation     = "mesh_tracing_sample_rate"
+	OpenCensusPortAnnotation = "mesh_tracing_opencensus_port"
+)
+
+//IsProviderValid checks if the tracing provider is valid
+func IsProviderValid(provider string) bool {
+	return provider == ProviderZipkin || provider == ProviderOTLP
+}
+
+//ZipkinCollectorEndpoint returns the endpoint with the default span api path if it has no path
+func ZipkinCollectorEndpoint(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Path != "" && u.Path != "/") {
+		return endpoint
+	}
+	u.Path = DefaultZipkinCollectorPath
+	return u.String()
+}
@@ -0,0 +1,18 @@
+package tracing
+
+import "testing"
+
+func TestZipkinCollectorEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint, want string
+	}{
+		{endpoint: "http://zipkin:9411", want: "http://zipkin:9411/api/v2/spans"},
+		{endpoint: "http://zipkin:9411/", want: "http://zipkin:9411/api/v2/spans"},
+		{endpoint: "https://zipkin/collect/spans", want: "https://zipkin/collect/spans"},
+	}
+	for _, tc := range tests {
+		if got := ZipkinCollectorEndpoint(tc.endpoint); got != tc.want {
+			t.Errorf("endpoint %s: want %s, but got %s", tc.endpoint, tc.want, got)
+		}
+	}
+}
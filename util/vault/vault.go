@@ -0,0 +1,127 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//ErrNotFound the secret does not exist or is deleted
+var ErrNotFound = errors.New("vault secret not found")
+
+//Client reads the secrets of HashiCorp Vault KV version 2 engines
+type Client struct {
+	addr       string
+	token      string
+	namespace  string
+	httpClient *http.Client
+}
+
+//NewClient new client of the vault server at addr, such as http://127.0.0.1:8200.
+//The namespace is only used by vault enterprise, it can be empty.
+func NewClient(addr, token, namespace string) *Client {
+	return &Client{
+		addr:       strings.TrimRight(addr, "/"),
+		token:      token,
+		namespace:  namespace,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+//Secret the latest version of a kv secret
+type Secret struct {
+	Data    map[string]string
+	Version int
+}
+
+//ReadKV reads the latest version of the secret at path. The first element of path is the mount
+//of the kv engine, "secret/myapp/db" reads the secret "myapp/db" of the engine mounted at "secret".
+//The values not of string are encoded in json.
+func (c *Client) ReadKV(ctx context.Context, path string) (*Secret, error) {
+	path = strings.Trim(path, "/")
+	i := strings.Index(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return nil, fmt.Errorf("invalid vault path %q, it should be <mount>/<secret path>", path)
+	}
+	url := fmt.Sprintf("%s/v1/%s/data/%s", c.addr, path[:i], path[i+1:])
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", c.token)
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("read vault secret %s: %v", path, err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read vault secret %s: %v", path, err)
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return nil, fmt.Errorf("read vault secret %s: status %d %s", path, res.StatusCode, strings.Join(e.Errors, "; "))
+	}
+
+	var kv struct {
+		Data struct {
+			Data     map[string]interface{} `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &kv); err != nil {
+		return nil, fmt.Errorf("decode vault secret %s: %v", path, err)
+	}
+	// the data of a deleted version is null
+	if kv.Data.Data == nil {
+		return nil, ErrNotFound
+	}
+	secret := &Secret{Data: make(map[string]string, len(kv.Data.Data)), Version: kv.Data.Metadata.Version}
+	for k, v := range kv.Data.Data {
+		if s, ok := v.(string); ok {
+			secret.Data[k] = s
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("encode value of %s: %v", k, err)
+		}
+		secret.Data[k] = string(b)
+	}
+	return secret, nil
+}
@@ -0,0 +1,79 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/myapp/db":
+			w.Write([]byte(`{"data":{"data":{"password":"secret","port":3306,"tls":{"enabled":true}},"metadata":{"version":3}}}`))
+		case "/v1/secret/data/deleted":
+			w.Write([]byte(`{"data":{"data":null,"metadata":{"version":2}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+}
+
+func TestReadKV(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	c := NewClient(server.URL+"/", "root", "")
+
+	secret, err := c.ReadKV(context.Background(), "/secret/myapp/db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Version != 3 {
+		t.Errorf("expected version 3, but got %d", secret.Version)
+	}
+	want := map[string]string{"password": "secret", "port": "3306", "tls": `{"enabled":true}`}
+	for k, v := range want {
+		if secret.Data[k] != v {
+			t.Errorf("expected %s of %s, but got %s", v, k, secret.Data[k])
+		}
+	}
+
+	for _, path := range []string{"secret/deleted", "secret/notfound"} {
+		if _, err := c.ReadKV(context.Background(), path); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for %s, but got %v", path, err)
+		}
+	}
+	for _, path := range []string{"secret", "secret/", ""} {
+		if _, err := c.ReadKV(context.Background(), path); err == nil {
+			t.Errorf("expected an error for the invalid path %q", path)
+		}
+	}
+	if _, err := NewClient(server.URL, "wrong", "").ReadKV(context.Background(), "secret/myapp/db"); err == nil {
+		t.Error("expected an error for the wrong token")
+	}
+}
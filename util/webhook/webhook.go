@@ -0,0 +1,320 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//supported git providers
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderGitea     = "gitea"
+	ProviderBitbucket = "bitbucket"
+)
+
+//ref types of push event
+const (
+	RefTypeBranch = "branch"
+	RefTypeTag    = "tag"
+)
+
+//ErrUnsupportedProvider the request is not sent by supported git providers
+var ErrUnsupportedProvider = errors.New("unsupported webhook provider")
+
+//ErrSignatureInvalid the signature or token of the request is invalid
+var ErrSignatureInvalid = errors.New("webhook signature is invalid")
+
+//zeroCommit the commit id of deleted ref
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+//PushEvent the push or tag push event of git providers
+type PushEvent struct {
+	Provider string `json:"provider"`
+	RefType  string `json:"ref_type"`
+	RefName  string `json:"ref_name"`
+	Commit   string `json:"commit"`
+	Message  string `json:"message"`
+	Author   string `json:"author"`
+	RepoURL  string `json:"repo_url"`
+}
+
+//Branch return the branch used by builder, tag is prefixed with "tag:"
+func (p *PushEvent) Branch() string {
+	if p.RefType == RefTypeTag {
+		return "tag:" + p.RefName
+	}
+	return p.RefName
+}
+
+//DetectProvider detect the provider by request headers.
+//gitea sends github compatible headers too, so it must be detected first.
+func DetectProvider(header http.Header) string {
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		return ProviderGitea
+	case header.Get("X-GitHub-Event") != "":
+		return ProviderGitHub
+	case header.Get("X-Gitlab-Event") != "":
+		return ProviderGitLab
+	case header.Get("X-Event-Key") != "":
+		return ProviderBitbucket
+	}
+	return ""
+}
+
+//Verify verify the request by the secret.
+//github, gitea and bitbucket sign the body with hmac, gitlab sends the secret token directly.
+func Verify(provider string, header http.Header, body []byte, secret string) error {
+	if secret == "" {
+		return ErrSignatureInvalid
+	}
+	switch provider {
+	case ProviderGitHub:
+		if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+			return verifyHMAC(sha256.New, secret, body, strings.TrimPrefix(signature, "sha256="))
+		}
+		return verifyHMAC(sha1.New, secret, body, strings.TrimPrefix(header.Get("X-Hub-Signature"), "sha1="))
+	case ProviderGitea:
+		return verifyHMAC(sha256.New, secret, body, header.Get("X-Gitea-Signature"))
+	case ProviderBitbucket:
+		return verifyHMAC(sha256.New, secret, body, strings.TrimPrefix(header.Get("X-Hub-Signature"), "sha256="))
+	case ProviderGitLab:
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+			return ErrSignatureInvalid
+		}
+		return nil
+	}
+	return ErrUnsupportedProvider
+}
+
+func verifyHMAC(h func() hash.Hash, secret string, body []byte, signature string) error {
+	expect, err := hex.DecodeString(signature)
+	if err != nil || len(expect) == 0 {
+		return ErrSignatureInvalid
+	}
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expect) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+//ParsePush parse the push event from the request body.
+//nil is returned if the event is not a push event or the ref is deleted.
+func ParsePush(provider string, header http.Header, body []byte) (*PushEvent, error) {
+	var event *PushEvent
+	var err error
+	switch provider {
+	case ProviderGitHub:
+		if header.Get("X-GitHub-Event") != "push" {
+			return nil, nil
+		}
+		event, err = parseGitHubPush(body)
+	case ProviderGitea:
+		if header.Get("X-Gitea-Event") != "push" {
+			return nil, nil
+		}
+		event, err = parseGitHubPush(body)
+	case ProviderGitLab:
+		if e := header.Get("X-Gitlab-Event"); e != "Push Hook" && e != "Tag Push Hook" {
+			return nil, nil
+		}
+		event, err = parseGitLabPush(body)
+	case ProviderBitbucket:
+		if e := header.Get("X-Event-Key"); e != "repo:push" && e != "repo:refs_changed" {
+			return nil, nil
+		}
+		event, err = parseBitbucketPush(body)
+	default:
+		return nil, ErrUnsupportedProvider
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s push event failure: %s", provider, err.Error())
+	}
+	if event == nil || event.Commit == "" || event.Commit == zeroCommit {
+		return nil, nil
+	}
+	event.Provider = provider
+	return event, nil
+}
+
+//parseRef parse refs/heads/xxx or refs/tags/xxx
+func parseRef(ref string) (string, string) {
+	if strings.HasPrefix(ref, "refs/tags/") {
+		return RefTypeTag, strings.TrimPrefix(ref, "refs/tags/")
+	}
+	return RefTypeBranch, strings.TrimPrefix(ref, "refs/heads/")
+}
+
+type gitCommit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	Author  struct {
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+//parseGitHubPush parse github and gitea push payload
+func parseGitHubPush(body []byte) (*PushEvent, error) {
+	var payload struct {
+		Ref        string      `json:"ref"`
+		After      string      `json:"after"`
+		Deleted    bool        `json:"deleted"`
+		HeadCommit *gitCommit  `json:"head_commit"`
+		Commits    []gitCommit `json:"commits"`
+		Repository struct {
+			CloneURL string `json:"clone_url"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Deleted {
+		return nil, nil
+	}
+	event := &PushEvent{Commit: payload.After, RepoURL: payload.Repository.CloneURL}
+	event.RefType, event.RefName = parseRef(payload.Ref)
+	head := payload.HeadCommit
+	if head == nil && len(payload.Commits) > 0 {
+		head = &payload.Commits[len(payload.Commits)-1]
+	}
+	if head != nil {
+		event.Message, event.Author = head.Message, head.Author.Name
+	}
+	return event, nil
+}
+
+func parseGitLabPush(body []byte) (*PushEvent, error) {
+	var payload struct {
+		Ref         string      `json:"ref"`
+		After       string      `json:"after"`
+		CheckoutSha string      `json:"checkout_sha"`
+		UserName    string      `json:"user_name"`
+		Commits     []gitCommit `json:"commits"`
+		Project     struct {
+			GitHTTPURL string `json:"git_http_url"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.After == zeroCommit {
+		return nil, nil
+	}
+	event := &PushEvent{Commit: payload.CheckoutSha, Author: payload.UserName, RepoURL: payload.Project.GitHTTPURL}
+	event.RefType, event.RefName = parseRef(payload.Ref)
+	for _, commit := range payload.Commits {
+		if commit.ID == event.Commit {
+			event.Message, event.Author = commit.Message, commit.Author.Name
+		}
+	}
+	return event, nil
+}
+
+//parseBitbucketPush parse bitbucket cloud(repo:push) and bitbucket server(repo:refs_changed) payload,
+//the last changed ref is used.
+func parseBitbucketPush(body []byte) (*PushEvent, error) {
+	var payload struct {
+		// bitbucket cloud
+		Push struct {
+			Changes []struct {
+				New *struct {
+					Type   string `json:"type"`
+					Name   string `json:"name"`
+					Target struct {
+						Hash    string `json:"hash"`
+						Message string `json:"message"`
+						Author  struct {
+							Raw string `json:"raw"`
+						} `json:"author"`
+					} `json:"target"`
+				} `json:"new"`
+			} `json:"changes"`
+		} `json:"push"`
+		// bitbucket server
+		Changes []struct {
+			Ref struct {
+				ID string `json:"id"`
+			} `json:"ref"`
+			ToHash string `json:"toHash"`
+			Type   string `json:"type"`
+		} `json:"changes"`
+		Actor struct {
+			DisplayName string `json:"displayName"`
+		} `json:"actor"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	for i := len(payload.Push.Changes) - 1; i >= 0; i-- {
+		change := payload.Push.Changes[i].New
+		if change == nil {
+			continue
+		}
+		refType := RefTypeBranch
+		if change.Type == "tag" {
+			refType = RefTypeTag
+		}
+		return &PushEvent{
+			RefType: refType,
+			RefName: change.Name,
+			Commit:  change.Target.Hash,
+			Message: change.Target.Message,
+			Author:  change.Target.Author.Raw,
+		}, nil
+	}
+	for i := len(payload.Changes) - 1; i >= 0; i-- {
+		change := payload.Changes[i]
+		if change.Type == "DELETE" {
+			continue
+		}
+		event := &PushEvent{Commit: change.ToHash, Author: payload.Actor.DisplayName}
+		event.RefType, event.RefName = parseRef(change.Ref.ID)
+		return event, nil
+	}
+	return nil, nil
+}
+
+//MatchPattern match the name with comma separated glob patterns
+func MatchPattern(patterns, name string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
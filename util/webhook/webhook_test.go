@@ -0,0 +1,148 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGitHubPush(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/master","after":"1f2e3d","head_commit":{"id":"1f2e3d","message":"fix","author":{"name":"dev"}},"repository":{"clone_url":"https://github.com/goodrain/demo.git"}}`)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	header.Set("X-Hub-Signature-256", "sha256="+sign("secret", body))
+	provider := DetectProvider(header)
+	if provider != ProviderGitHub {
+		t.Fatalf("expect github, got %s", provider)
+	}
+	if err := Verify(provider, header, body, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(provider, header, body, "other"); err != ErrSignatureInvalid {
+		t.Errorf("expect invalid signature, got %v", err)
+	}
+	event, err := ParsePush(provider, header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Branch() != "master" || event.Commit != "1f2e3d" || event.Author != "dev" || event.Message != "fix" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	header.Set("X-GitHub-Event", "ping")
+	if event, _ := ParsePush(provider, header, body); event != nil {
+		t.Errorf("ping event should be ignored")
+	}
+}
+
+func TestGiteaTagPush(t *testing.T) {
+	body := []byte(`{"ref":"refs/tags/v1.0.0","after":"abc123","commits":[]}`)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	header.Set("X-Gitea-Event", "push")
+	header.Set("X-Gitea-Signature", sign("secret", body))
+	provider := DetectProvider(header)
+	if provider != ProviderGitea {
+		t.Fatalf("expect gitea, got %s", provider)
+	}
+	if err := Verify(provider, header, body, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	event, err := ParsePush(provider, header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.RefType != RefTypeTag || event.Branch() != "tag:v1.0.0" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestGitLabPush(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/dev","after":"bbb","checkout_sha":"bbb","user_name":"pusher","commits":[{"id":"aaa","message":"a"},{"id":"bbb","message":"b","author":{"name":"dev"}}]}`)
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Push Hook")
+	header.Set("X-Gitlab-Token", "secret")
+	if err := Verify(ProviderGitLab, header, body, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	event, err := ParsePush(DetectProvider(header), header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.RefName != "dev" || event.Commit != "bbb" || event.Message != "b" || event.Author != "dev" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	deleted := []byte(`{"ref":"refs/heads/dev","after":"0000000000000000000000000000000000000000","checkout_sha":null}`)
+	if event, _ := ParsePush(ProviderGitLab, header, deleted); event != nil {
+		t.Errorf("deleted branch should be ignored")
+	}
+}
+
+func TestBitbucketPush(t *testing.T) {
+	body := []byte(`{"push":{"changes":[{"new":{"type":"branch","name":"master","target":{"hash":"ccc","message":"m","author":{"raw":"dev <dev@example.com>"}}}}]}}`)
+	header := http.Header{}
+	header.Set("X-Event-Key", "repo:push")
+	header.Set("X-Hub-Signature", "sha256="+sign("secret", body))
+	if err := Verify(DetectProvider(header), header, body, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	event, err := ParsePush(ProviderBitbucket, header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.RefName != "master" || event.Commit != "ccc" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	header.Set("X-Event-Key", "repo:refs_changed")
+	server := []byte(`{"actor":{"displayName":"dev"},"changes":[{"ref":{"id":"refs/tags/v2"},"toHash":"ddd","type":"ADD"}]}`)
+	event, err = ParsePush(ProviderBitbucket, header, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Branch() != "tag:v2" || event.Commit != "ddd" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		patterns, name string
+		expect         bool
+	}{
+		{"master", "master", true},
+		{"release-*, hotfix/*", "release-1.0", true},
+		{"release-*, hotfix/*", "hotfix/abc", true},
+		{"release-*", "feature/a", false},
+		{"", "master", false},
+	}
+	for _, tc := range tests {
+		if MatchPattern(tc.patterns, tc.name) != tc.expect {
+			t.Errorf("match %s with %s expect %v", tc.name, tc.patterns, tc.expect)
+		}
+	}
+}
@@ -20,15 +20,15 @@ package app
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,8 +36,10 @@ import (
 
 	"github.com/barnettZQG/gotty/server"
 	"github.com/barnettZQG/gotty/webtty"
+	"github.com/goodrain/rainbond/util"
 	httputil "github.com/goodrain/rainbond/util/http"
 	k8sutil "github.com/goodrain/rainbond/util/k8s"
+	"github.com/goodrain/rainbond/util/terminal"
 	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -66,8 +68,15 @@ type App struct {
 
 	onceMutex  *umutex.UnblockingMutex
 	restClient *restclient.RESTClient
-	coreClient *kubernetes.Clientset
+	coreClient kubernetes.Interface
 	config     *restclient.Config
+
+	// usedTickets tickets can only be used once before expired
+	usedTickets map[string]time.Time
+	ticketLock  sync.Mutex
+
+	sessions    map[string]*Session
+	sessionLock sync.Mutex
 }
 
 //Options options
@@ -85,6 +94,14 @@ type Options struct {
 	RawPreferences  map[string]interface{} `hcl:"preferences"`
 	SessionKey      string                 `hcl:"session_key"`
 	K8SConfPath     string
+	// TicketSecret the secret shared with region api to verify terminal tickets
+	TicketSecret string
+	// RecordDir the dir to save session records, disable recording if empty
+	RecordDir string
+	// MaxUploadSize the max size(byte) of archive uploaded to container
+	MaxUploadSize int64
+	// MaxDownloadSize the max size(byte) of archive downloaded from container
+	MaxDownloadSize int64
 }
 
 //Version -
@@ -101,6 +118,9 @@ var DefaultOptions = Options{
 	ReconnectTime:   10,
 	CloseSignal:     1, // syscall.SIGHUP
 	SessionKey:      "_auth_user_id",
+	RecordDir:       terminal.DefaultRecordDir,
+	MaxUploadSize:   DefaultMaxUploadSize,
+	MaxDownloadSize: DefaultMaxDownloadSize,
 }
 
 //InitMessage -
@@ -109,7 +129,10 @@ type InitMessage struct {
 	ServiceID     string `json:"S_id"`
 	PodName       string `json:"C_id"`
 	ContainerName string `json:"containerName"`
-	Md5           string `json:"Md5"`
+	// Ticket signed by region api
+	Ticket string `json:"ticket"`
+	// Session created by ticket, used to open other containers of the pod
+	Session string `json:"session"`
 }
 
 func checkSameOrigin(r *http.Request) bool {
@@ -118,6 +141,9 @@ func checkSameOrigin(r *http.Request) bool {
 
 //New -
 func New(options *Options) (*App, error) {
+	if options.TicketSecret == "" {
+		return nil, errors.New("ticket secret must be set to verify terminal tickets")
+	}
 	titleTemplate, _ := template.New("title").Parse(options.TitleFormat)
 	app := &App{
 		options: options,
@@ -129,6 +155,8 @@ func New(options *Options) (*App, error) {
 		},
 		titleTemplate: titleTemplate,
 		onceMutex:     umutex.New(),
+		usedTickets:   make(map[string]time.Time),
+		sessions:      make(map[string]*Session),
 	}
 	//create kube client and config
 	if err := app.createKubeClient(); err != nil {
@@ -157,6 +185,9 @@ func (app *App) Run() error {
 	wsMux := http.NewServeMux()
 	wsMux.Handle("/", siteHandler)
 	wsMux.Handle("/docker_console", wsHandler)
+	wsMux.Handle("/session", http.HandlerFunc(app.handleSession))
+	wsMux.Handle("/containers", http.HandlerFunc(app.handleContainers))
+	wsMux.Handle("/files", http.HandlerFunc(app.handleFiles))
 	wsMux.Handle("/health", health)
 	wsMux.Handle("/metrics", promhttp.Handler())
 
@@ -210,37 +241,31 @@ func (app *App) handleWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	message := string(stream)
-	logrus.Print("message=", message)
-
 	var init InitMessage
 
 	err = json.Unmarshal(stream, &init)
-
-	//todo auth
-	if init.PodName == "" {
-		logrus.Print("Parameter is error, pod name is empty")
-		conn.WriteMessage(websocket.TextMessage, []byte("pod name can not be empty"))
+	if err != nil {
+		logrus.Print("Parameter is error, " + err.Error())
+		conn.WriteMessage(websocket.TextMessage, []byte("init message is invalid"))
 		conn.Close()
 		return
 	}
-	key := init.TenantID + "_" + init.ServiceID + "_" + init.PodName
-	md5 := md5Func(key)
-	if md5 != init.Md5 {
-		logrus.Print("Auth is not allowed !")
+	ticket, err := app.authInitMessage(&init)
+	if err != nil {
+		logrus.Warningf("client %s auth is not allowed: %s", r.RemoteAddr, err.Error())
 		conn.WriteMessage(websocket.TextMessage, []byte("Auth is not allowed!"))
 		conn.Close()
 		return
 	}
 	// base kubernetes api create exec slave
-	containerName, ip, args, err := app.GetContainerArgs(init.TenantID, init.PodName, init.ContainerName)
+	containerName, ip, args, err := app.GetContainerArgs(ticket.TenantID, ticket.PodName, ticket.ContainerName)
 	if err != nil {
 		logrus.Errorf("get default container failure %s", err.Error())
 		conn.WriteMessage(websocket.TextMessage, []byte("Get default container name failure!"))
 		ExecuteCommandFailed++
 		return
 	}
-	request := app.NewRequest(init.PodName, init.TenantID, containerName, args)
+	request := app.NewRequest(ticket.PodName, ticket.TenantID, containerName, args)
 	var slave server.Slave
 	slave, err = NewExecContext(request, app.config)
 	if err != nil {
@@ -249,6 +274,13 @@ func (app *App) handleWS(w http.ResponseWriter, r *http.Request) {
 		ExecuteCommandFailed++
 		return
 	}
+	slave, err = app.recordSession(slave, ticket, containerName, r.RemoteAddr)
+	if err != nil {
+		logrus.Errorf("create session recorder failure %s", err.Error())
+		conn.WriteMessage(websocket.TextMessage, []byte("record session failure!"))
+		ExecuteCommandFailed++
+		return
+	}
 	defer slave.Close()
 	opts := []webtty.Option{
 		webtty.WithWindowTitle([]byte(ip)),
@@ -330,17 +362,27 @@ func (app *App) GetContainerArgs(namespace, podname, containerName string) (stri
 	}
 	for i, container := range pod.Spec.Containers {
 		if container.Name == containerName || (containerName == "" && i == 0) {
-			for _, env := range container.Env {
-				if env.Name == "ES_DEFAULT_EXEC_ARGS" {
-					args = strings.Split(env.Value, " ")
-				}
-			}
-			return container.Name, pod.Status.PodIP, args, nil
+			return container.Name, pod.Status.PodIP, containerExecArgs(container, args), nil
+		}
+	}
+	// init container can only be exec when it is running
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == containerName {
+			return container.Name, pod.Status.PodIP, containerExecArgs(container, args), nil
 		}
 	}
 	return "", "", args, fmt.Errorf("not have container in pod %s/%s", namespace, podname)
 }
 
+func containerExecArgs(container api.Container, args []string) []string {
+	for _, env := range container.Env {
+		if env.Name == "ES_DEFAULT_EXEC_ARGS" {
+			args = strings.Split(env.Value, " ")
+		}
+	}
+	return args
+}
+
 //NewRequest new exec request
 func (app *App) NewRequest(podName, namespace, containerName string, command []string) *restclient.Request {
 	// TODO: consider abstracting into a client invocation or client helper
@@ -375,9 +417,73 @@ func wrapHeaders(handler http.Handler) http.Handler {
 	})
 }
 
-func md5Func(str string) string {
-	h := md5.New()
-	h.Write([]byte(str))
-	cipherStr := h.Sum(nil)
-	return hex.EncodeToString(cipherStr)
+//authInitMessage auth the init message by ticket or session.
+//with session, any container of the session pod can be opened.
+func (app *App) authInitMessage(init *InitMessage) (*terminal.Ticket, error) {
+	if init.Ticket != "" || init.Session == "" {
+		return app.verifyTicket(init)
+	}
+	session, err := app.getSession(init.Session)
+	if err != nil {
+		return nil, err
+	}
+	if (init.TenantID != "" && init.TenantID != session.Ticket.TenantID) ||
+		(init.PodName != "" && init.PodName != session.Ticket.PodName) {
+		return nil, errors.New("init message does not match the session")
+	}
+	return app.sessionTicket(session, init.ContainerName)
+}
+
+//verifyTicket verify the ticket of init message, the ticket can only be used once.
+func (app *App) verifyTicket(init *InitMessage) (*terminal.Ticket, error) {
+	ticket, err := terminal.ParseTicket(init.Ticket, []byte(app.options.TicketSecret))
+	if err != nil {
+		return nil, err
+	}
+	if ticket.PodName == "" {
+		return nil, errors.New("pod name of ticket is empty")
+	}
+	// the ticket is bound to tenant, service, pod and container
+	if (init.TenantID != "" && init.TenantID != ticket.TenantID) ||
+		(init.ServiceID != "" && init.ServiceID != ticket.ServiceID) ||
+		(init.PodName != "" && init.PodName != ticket.PodName) ||
+		(init.ContainerName != "" && init.ContainerName != ticket.ContainerName) {
+		return nil, errors.New("init message does not match the ticket")
+	}
+	app.ticketLock.Lock()
+	defer app.ticketLock.Unlock()
+	now := time.Now()
+	for id, expiration := range app.usedTickets {
+		if now.After(expiration) {
+			delete(app.usedTickets, id)
+		}
+	}
+	if _, ok := app.usedTickets[ticket.ID]; ok {
+		return nil, errors.New("ticket has been used")
+	}
+	app.usedTickets[ticket.ID] = ticket.Expiration()
+	return ticket, nil
+}
+
+//recordSession record the session if record dir is set.
+//the slave is closed if the recorder can not be created, session without audit trail is not allowed.
+func (app *App) recordSession(slave server.Slave, ticket *terminal.Ticket, containerName, remoteAddr string) (server.Slave, error) {
+	if app.options.RecordDir == "" {
+		return slave, nil
+	}
+	recorder, err := terminal.NewRecorder(app.options.RecordDir, &terminal.SessionMeta{
+		SessionID:     util.NewUUID(),
+		User:          ticket.User,
+		TenantID:      ticket.TenantID,
+		ServiceID:     ticket.ServiceID,
+		PodName:       ticket.PodName,
+		ContainerName: containerName,
+		RemoteAddr:    remoteAddr,
+	})
+	if err != nil {
+		slave.Close()
+		return nil, err
+	}
+	logrus.Infof("user %s open terminal of pod %s/%s", ticket.User, ticket.TenantID, ticket.PodName)
+	return newRecordSlave(slave, recorder), nil
 }
@@ -19,9 +19,137 @@
 package app
 
 import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/util/terminal"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestSendCommand(t *testing.T) {
 
 }
+
+func TestPodContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "probe-mesh-abc"}},
+			Containers: []corev1.Container{
+				{Name: "abc"},
+				{Name: "plugin-123", Env: []corev1.EnvVar{{Name: "PLUGIN_ID", Value: "123"}}},
+			},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "probe-mesh-abc", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}}},
+			ContainerStatuses:     []corev1.ContainerStatus{{Name: "abc", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+		},
+	}
+	containers := podContainers(pod)
+	if len(containers) != 3 {
+		t.Fatalf("expect 3 containers, got %d", len(containers))
+	}
+	expects := []Container{
+		{Name: "probe-mesh-abc", Type: ContainerTypeInit, State: "terminated"},
+		{Name: "abc", Type: ContainerTypeMain, State: "running", Ready: true},
+		{Name: "plugin-123", Type: ContainerTypePlugin, State: "waiting"},
+	}
+	for i, expect := range expects {
+		if *containers[i] != expect {
+			t.Errorf("expect container %+v, got %+v", expect, *containers[i])
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	secret := "secret"
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "tenant", UID: "uid-1"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "abc"}, {Name: "plugin-123"}}},
+	}
+	client := fake.NewSimpleClientset(pod)
+	app := &App{options: &Options{TicketSecret: secret}, coreClient: client, usedTickets: make(map[string]time.Time), sessions: make(map[string]*Session)}
+	token, _ := terminal.NewTicket("admin", "tenant", "service", "pod", "abc", time.Minute).Sign([]byte(secret))
+	session, err := app.createSession(token, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.createSession(token, ""); err == nil {
+		t.Fatal("ticket should only be used once")
+	}
+	ticket, err := app.authInitMessage(&InitMessage{Session: session.ID, ContainerName: "plugin-123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.PodName != "pod" || ticket.ContainerName != "plugin-123" || session.Ticket.ContainerName != "abc" {
+		t.Errorf("unexpected session ticket %+v", ticket)
+	}
+	if _, err := app.authInitMessage(&InitMessage{Session: session.ID, PodName: "other"}); err == nil {
+		t.Error("session should be bound to the pod")
+	}
+	if _, err := app.authInitMessage(&InitMessage{Session: session.ID, ContainerName: "other"}); err == nil {
+		t.Error("container out of the pod should be rejected")
+	}
+	recreated := pod.DeepCopy()
+	recreated.UID = "uid-2"
+	if _, err := client.CoreV1().Pods("tenant").Update(context.Background(), recreated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.authInitMessage(&InitMessage{Session: session.ID, ContainerName: "plugin-123"}); err == nil {
+		t.Error("container of the recreated pod should be rejected")
+	}
+	session.ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := app.getSession(session.ID); err == nil {
+		t.Error("session should be expired")
+	}
+}
+
+func TestSessionRecordEvent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webcli-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	secret := "secret"
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "tenant", UID: "uid-1"}}
+	app := &App{options: &Options{TicketSecret: secret, RecordDir: dir}, coreClient: fake.NewSimpleClientset(pod),
+		usedTickets: make(map[string]time.Time), sessions: make(map[string]*Session)}
+	token, _ := terminal.NewTicket("admin", "tenant", "service", "pod", "abc", time.Minute).Sign([]byte(secret))
+	session, err := app.createSession(token, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.recordEvent("upload archive to %s", "/app")
+	if err := session.recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	casts, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*.cast"))
+	if len(casts) != 1 {
+		t.Fatalf("expect 1 session record, got %v", casts)
+	}
+	content, err := ioutil.ReadFile(casts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"m","upload archive to /app"`) {
+		t.Errorf("file transfer is not recorded: %s", content)
+	}
+}
+
+func TestLimitWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &limitWriter{w: &buf, max: 4}
+	if _, err := w.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("de")); err != ErrSizeLimitExceeded {
+		t.Errorf("expect size limit error, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	httputil "github.com/goodrain/rainbond/util/http"
+	"github.com/sirupsen/logrus"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+//ErrSizeLimitExceeded the transferred size is over the limit
+var ErrSizeLimitExceeded = errors.New("file size exceeds the limit")
+
+//DefaultMaxUploadSize default max size of uploaded archive, 100MB
+var DefaultMaxUploadSize int64 = 100 << 20
+
+//DefaultMaxDownloadSize default max size of downloaded archive, 1GB
+var DefaultMaxDownloadSize int64 = 1 << 30
+
+//limitWriter return ErrSizeLimitExceeded if more than max bytes are written
+type limitWriter struct {
+	w       io.Writer
+	max     int64
+	written int64
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if l.written+int64(len(p)) > l.max {
+		return 0, ErrSizeLimitExceeded
+	}
+	n, err := l.w.Write(p)
+	l.written += int64(n)
+	return n, err
+}
+
+//countReader counts the bytes read
+type countReader struct {
+	r    io.Reader
+	read int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+//tarResponseWriter write response header when first byte of the archive is written,
+//so that the error can still be returned before the archive starts.
+type tarResponseWriter struct {
+	w        http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (t *tarResponseWriter) Write(p []byte) (int, error) {
+	if !t.started {
+		t.started = true
+		t.w.Header().Set("Content-Type", "application/x-tar")
+		t.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", t.filename))
+		t.w.WriteHeader(http.StatusOK)
+	}
+	return t.w.Write(p)
+}
+
+//cleanContainerPath the path in container must be absolute
+func cleanContainerPath(p string) (string, error) {
+	if p == "" || !strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("path %s must be absolute", p)
+	}
+	return path.Clean(p), nil
+}
+
+//NewStreamRequest new exec request without tty, stderr is always returned
+func (app *App) NewStreamRequest(podName, namespace, containerName string, stdin bool, command []string) *restclient.Request {
+	req := app.restClient.Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		Param("container", containerName).
+		Param("stdin", fmt.Sprint(stdin)).
+		Param("stdout", "true").
+		Param("stderr", "true").
+		Param("tty", "false")
+	for _, c := range command {
+		req.Param("command", c)
+	}
+	return req
+}
+
+//execStream exec the command in container and stream stdin and stdout like kubectl cp
+func (app *App) execStream(namespace, podName, containerName string, command []string, stdin io.Reader, stdout io.Writer) error {
+	req := app.NewStreamRequest(podName, namespace, containerName, stdin != nil, command)
+	exec, err := remotecommand.NewSPDYExecutor(app.config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	err = exec.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &limitWriter{w: &stderr, max: 4096},
+	})
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", err.Error(), msg)
+		}
+		return err
+	}
+	return nil
+}
+
+//handleFiles download(GET) or upload(POST) files of the container as tar archive.
+//download: GET /files?container=&path=/data/file, the file or dir is archived in the response.
+//upload: POST /files?container=&path=/data, the tar archive in body is extracted into the dir.
+func (app *App) handleFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	session, err := app.getSession(r.Header.Get(SessionHeader))
+	if err != nil {
+		httputil.ReturnError(r, w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	ticket, err := app.sessionTicket(session, r.URL.Query().Get("container"))
+	if err != nil {
+		httputil.ReturnError(r, w, http.StatusForbidden, err.Error())
+		return
+	}
+	containerName, _, _, err := app.GetContainerArgs(ticket.TenantID, ticket.PodName, ticket.ContainerName)
+	if err != nil {
+		httputil.ReturnError(r, w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filePath, err := cleanContainerPath(r.URL.Query().Get("path"))
+	if err != nil {
+		httputil.ReturnError(r, w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if r.Method == http.MethodGet {
+		size, err := app.downloadFile(w, r, ticket.TenantID, ticket.PodName, containerName, filePath)
+		session.recordEvent("download %s from container %s, %d bytes%s", filePath, containerName, size, transferResult(err))
+		return
+	}
+	size, err := app.uploadFile(w, r, ticket.TenantID, ticket.PodName, containerName, filePath)
+	session.recordEvent("upload archive to %s of container %s, %d bytes%s", filePath, containerName, size, transferResult(err))
+}
+
+func transferResult(err error) string {
+	if err != nil {
+		return ", failure: " + err.Error()
+	}
+	return ""
+}
+
+func (app *App) downloadFile(w http.ResponseWriter, r *http.Request, namespace, podName, containerName, filePath string) (int64, error) {
+	dir, base := path.Split(filePath)
+	out := &tarResponseWriter{w: w, filename: base + ".tar"}
+	if base == "" {
+		// download the root dir
+		base, out.filename = ".", "root.tar"
+	}
+	limit := &limitWriter{w: out, max: app.options.MaxDownloadSize}
+	// the file name must not be parsed as an option of tar
+	err := app.execStream(namespace, podName, containerName, []string{"tar", "cf", "-", "-C", dir, "--", base}, nil, limit)
+	if err != nil {
+		logrus.Errorf("download %s from container %s of pod %s failure %s", filePath, containerName, podName, err.Error())
+		if !out.started {
+			code := http.StatusInternalServerError
+			if strings.Contains(err.Error(), ErrSizeLimitExceeded.Error()) {
+				code = http.StatusRequestEntityTooLarge
+			}
+			httputil.ReturnError(r, w, code, err.Error())
+		}
+		// the archive is truncated, the client will find the tar is broken.
+	}
+	return limit.written, err
+}
+
+func (app *App) uploadFile(w http.ResponseWriter, r *http.Request, namespace, podName, containerName, dir string) (int64, error) {
+	if r.ContentLength > app.options.MaxUploadSize {
+		httputil.ReturnError(r, w, http.StatusRequestEntityTooLarge, ErrSizeLimitExceeded.Error())
+		return 0, ErrSizeLimitExceeded
+	}
+	body := &countReader{r: http.MaxBytesReader(w, r.Body, app.options.MaxUploadSize)}
+	defer r.Body.Close()
+	err := app.execStream(namespace, podName, containerName, []string{"tar", "xmf", "-", "-C", dir}, body, ioutil.Discard)
+	if err != nil {
+		logrus.Errorf("upload archive to %s of container %s of pod %s failure %s", dir, containerName, podName, err.Error())
+		code := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "request body too large") {
+			code = http.StatusRequestEntityTooLarge
+		}
+		httputil.ReturnError(r, w, code, err.Error())
+		return body.read, err
+	}
+	httputil.ReturnSuccess(r, w, nil)
+	return body.read, nil
+}
@@ -0,0 +1,67 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"github.com/barnettZQG/gotty/server"
+	"github.com/goodrain/rainbond/util/terminal"
+	"github.com/sirupsen/logrus"
+)
+
+//recordSlave record all input and output of the terminal
+type recordSlave struct {
+	server.Slave
+	recorder *terminal.Recorder
+}
+
+func newRecordSlave(slave server.Slave, recorder *terminal.Recorder) server.Slave {
+	return &recordSlave{Slave: slave, recorder: recorder}
+}
+
+func (r *recordSlave) Read(p []byte) (n int, err error) {
+	n, err = r.Slave.Read(p)
+	if n > 0 {
+		if rerr := r.recorder.Output(p[:n]); rerr != nil {
+			logrus.Warningf("record terminal output failure %s", rerr.Error())
+		}
+	}
+	return n, err
+}
+
+func (r *recordSlave) Write(p []byte) (n int, err error) {
+	n, err = r.Slave.Write(p)
+	if n > 0 {
+		if rerr := r.recorder.Input(p[:n]); rerr != nil {
+			logrus.Warningf("record terminal input failure %s", rerr.Error())
+		}
+	}
+	return n, err
+}
+
+func (r *recordSlave) ResizeTerminal(columns int, rows int) error {
+	r.recorder.Resize(columns, rows)
+	return r.Slave.ResizeTerminal(columns, rows)
+}
+
+func (r *recordSlave) Close() error {
+	if err := r.recorder.Close(); err != nil {
+		logrus.Warningf("close terminal recorder failure %s", err.Error())
+	}
+	return r.Slave.Close()
+}
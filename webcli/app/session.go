@@ -0,0 +1,282 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/util"
+	httputil "github.com/goodrain/rainbond/util/http"
+	"github.com/goodrain/rainbond/util/terminal"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+//SessionIdleTimeout the session is expired if it is not used during the timeout
+var SessionIdleTimeout = 30 * time.Minute
+
+//SessionHeader the header carrying session id of file transfer and container list requests
+var SessionHeader = "X-Webcli-Session"
+
+//container types
+const (
+	ContainerTypeInit   = "init"
+	ContainerTypeMain   = "main"
+	ContainerTypePlugin = "plugin"
+)
+
+//Session one authorized terminal session, it is bound to the pod of the ticket.
+//all containers of the pod can be opened, and files can be transferred within the session.
+//The file transfers are recorded as marker events of the session record.
+type Session struct {
+	ID         string           `json:"session_id"`
+	Ticket     *terminal.Ticket `json:"-"`
+	ExpiresAt  time.Time        `json:"expires_at"`
+	Containers []*Container     `json:"containers"`
+
+	// podUID the uid of the authorized pod, the pod recreated with the same name is not authorized
+	podUID   types.UID
+	recorder *terminal.Recorder
+}
+
+//Container container of the session pod
+type Container struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Image string `json:"image"`
+	Ready bool   `json:"ready"`
+	State string `json:"state"`
+}
+
+type sessionReq struct {
+	Ticket string `json:"ticket"`
+}
+
+//createSession consume the ticket and create a session
+func (app *App) createSession(token, remoteAddr string) (*Session, error) {
+	ticket, err := app.verifyTicket(&InitMessage{Ticket: token})
+	if err != nil {
+		return nil, err
+	}
+	pod, err := app.coreClient.CoreV1().Pods(ticket.TenantID).Get(context.Background(), ticket.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{
+		ID:        strings.Replace(util.NewUUID(), "-", "", -1),
+		Ticket:    ticket,
+		ExpiresAt: time.Now().Add(SessionIdleTimeout),
+		podUID:    pod.UID,
+	}
+	// session without audit trail is not allowed
+	if app.options.RecordDir != "" {
+		session.recorder, err = terminal.NewRecorder(app.options.RecordDir, &terminal.SessionMeta{
+			SessionID:  session.ID,
+			User:       ticket.User,
+			TenantID:   ticket.TenantID,
+			ServiceID:  ticket.ServiceID,
+			PodName:    ticket.PodName,
+			RemoteAddr: remoteAddr,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	app.sessionLock.Lock()
+	defer app.sessionLock.Unlock()
+	app.cleanSessions()
+	app.sessions[session.ID] = session
+	return session, nil
+}
+
+//getSession get the session and refresh the expiration
+func (app *App) getSession(id string) (*Session, error) {
+	if id == "" {
+		return nil, errors.New("session id is empty")
+	}
+	app.sessionLock.Lock()
+	defer app.sessionLock.Unlock()
+	app.cleanSessions()
+	session, ok := app.sessions[id]
+	if !ok {
+		return nil, errors.New("session is not exist or expired")
+	}
+	session.ExpiresAt = time.Now().Add(SessionIdleTimeout)
+	return session, nil
+}
+
+func (app *App) cleanSessions() {
+	now := time.Now()
+	for id, session := range app.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(app.sessions, id)
+			if session.recorder != nil {
+				if err := session.recorder.Close(); err != nil {
+					logrus.Warningf("close session recorder failure %s", err.Error())
+				}
+			}
+		}
+	}
+}
+
+//recordEvent record the action of the session, like file transfers
+func (session *Session) recordEvent(format string, args ...interface{}) {
+	label := fmt.Sprintf(format, args...)
+	logrus.Infof("user %s %s of pod %s/%s", session.Ticket.User, label, session.Ticket.TenantID, session.Ticket.PodName)
+	if session.recorder == nil {
+		return
+	}
+	if err := session.recorder.Marker(label); err != nil {
+		logrus.Errorf("record session event failure %s", err.Error())
+	}
+}
+
+//sessionTicket return the ticket of the container in the session pod.
+//The container other than the one of the ticket must belong to the authorized pod.
+func (app *App) sessionTicket(session *Session, containerName string) (*terminal.Ticket, error) {
+	ticket := *session.Ticket
+	if containerName == "" || containerName == ticket.ContainerName {
+		return &ticket, nil
+	}
+	pod, err := app.coreClient.CoreV1().Pods(ticket.TenantID).Get(context.Background(), ticket.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pod.UID != session.podUID {
+		return nil, errors.New("the pod of the session has been recreated")
+	}
+	for _, c := range podContainers(pod) {
+		if c.Name == containerName {
+			ticket.ContainerName = containerName
+			return &ticket, nil
+		}
+	}
+	return nil, fmt.Errorf("container %s does not belong to pod %s", containerName, ticket.PodName)
+}
+
+//ListContainers list the init, main and plugin containers of the pod
+func (app *App) ListContainers(namespace, podName string) ([]*Container, error) {
+	pod, err := app.coreClient.CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return podContainers(pod), nil
+}
+
+func podContainers(pod *corev1.Pod) []*Container {
+	status := make(map[string]corev1.ContainerStatus)
+	for _, s := range pod.Status.InitContainerStatuses {
+		status[s.Name] = s
+	}
+	for _, s := range pod.Status.ContainerStatuses {
+		status[s.Name] = s
+	}
+	var containers []*Container
+	newContainer := func(c corev1.Container, ctype string) *Container {
+		container := &Container{Name: c.Name, Type: ctype, Image: c.Image, State: "waiting"}
+		if s, ok := status[c.Name]; ok {
+			container.Ready = s.Ready
+			switch {
+			case s.State.Running != nil:
+				container.State = "running"
+			case s.State.Terminated != nil:
+				container.State = "terminated"
+			}
+		}
+		return container
+	}
+	for _, c := range pod.Spec.InitContainers {
+		containers = append(containers, newContainer(c, ContainerTypeInit))
+	}
+	for _, c := range pod.Spec.Containers {
+		ctype := ContainerTypeMain
+		if isPluginContainer(c) {
+			ctype = ContainerTypePlugin
+		}
+		containers = append(containers, newContainer(c, ctype))
+	}
+	return containers
+}
+
+func isPluginContainer(c corev1.Container) bool {
+	if strings.HasPrefix(c.Name, "plugin-") || strings.HasPrefix(c.Name, "default-tcpmesh") {
+		return true
+	}
+	for _, env := range c.Env {
+		if env.Name == "PLUGIN_ID" {
+			return true
+		}
+	}
+	return false
+}
+
+//handleSession create a session by ticket, the ticket is consumed.
+func (app *App) handleSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req sessionReq
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 8*1024)).Decode(&req); err != nil {
+		httputil.ReturnError(r, w, http.StatusBadRequest, "request body is invalid")
+		return
+	}
+	session, err := app.createSession(req.Ticket, r.RemoteAddr)
+	if err != nil {
+		logrus.Warningf("client %s create session failure: %s", r.RemoteAddr, err.Error())
+		httputil.ReturnError(r, w, http.StatusUnauthorized, "Auth is not allowed!")
+		return
+	}
+	containers, err := app.ListContainers(session.Ticket.TenantID, session.Ticket.PodName)
+	if err != nil {
+		logrus.Errorf("list containers of pod %s failure %s", session.Ticket.PodName, err.Error())
+		httputil.ReturnError(r, w, http.StatusInternalServerError, "list containers failure")
+		return
+	}
+	logrus.Infof("user %s create webcli session of pod %s/%s", session.Ticket.User, session.Ticket.TenantID, session.Ticket.PodName)
+	httputil.ReturnSuccess(r, w, &Session{ID: session.ID, ExpiresAt: session.ExpiresAt, Containers: containers})
+}
+
+//handleContainers list containers of the session pod
+func (app *App) handleContainers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	session, err := app.getSession(r.Header.Get(SessionHeader))
+	if err != nil {
+		httputil.ReturnError(r, w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	containers, err := app.ListContainers(session.Ticket.TenantID, session.Ticket.PodName)
+	if err != nil {
+		logrus.Errorf("list containers of pod %s failure %s", session.Ticket.PodName, err.Error())
+		httputil.ReturnError(r, w, http.StatusInternalServerError, "list containers failure")
+		return
+	}
+	httputil.ReturnSuccess(r, w, containers)
+}
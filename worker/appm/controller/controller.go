@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"sync"
 
+	dbmodel "github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/util"
 	"github.com/goodrain/rainbond/worker/appm/store"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
@@ -168,6 +169,23 @@ func (m *Manager) StartController(controllerType TypeController, apps ...v1.AppS
 	return nil
 }
 
+//StartResizeVolumeController create and start the controller resizing the claims of the volume
+func (m *Manager) StartResizeVolumeController(app v1.AppService, volume *dbmodel.TenantServiceVolume, capacity int64) {
+	controllerID := util.NewUUID()
+	controller := &resizeVolumeController{
+		controllerID: controllerID,
+		appService:   app,
+		volume:       volume,
+		capacity:     capacity,
+		manager:      m,
+		stopChan:     make(chan struct{}),
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.controllers[controllerID] = controller
+	go controller.Begin()
+}
+
 func (m *Manager) callback(controllerID string, err error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
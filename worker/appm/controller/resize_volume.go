@@ -0,0 +1,247 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/event"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type resizeVolumeController struct {
+	controllerID string
+	appService   v1.AppService
+	volume       *dbmodel.TenantServiceVolume
+	capacity     int64
+	manager      *Manager
+	stopChan     chan struct{}
+}
+
+//Begin begins resizing the claims of the volume
+func (r *resizeVolumeController) Begin() {
+	logger := r.appService.Logger
+	logger.Info(fmt.Sprintf("App runtime begin resizing volume %s to %dGi", r.volume.VolumeName, r.capacity), event.GetLoggerOption("starting"))
+	if err := r.resize(); err != nil {
+		if err == ErrWaitTimeOut {
+			logger.Error(fmt.Sprintf("waiting for volume %s to be resized timeout, it will go on in the background", r.volume.VolumeName), event.GetTimeoutLoggerOption())
+		} else {
+			logrus.Errorf("resize volume %s of service %s failure %s", r.volume.VolumeName, r.appService.ServiceAlias, err.Error())
+			logger.Error(fmt.Sprintf("resize volume %s failure: %v", r.volume.VolumeName, err), event.GetCallbackLoggerOption())
+		}
+	} else {
+		logger.Info(fmt.Sprintf("resize volume %s to %dGi success", r.volume.VolumeName, r.capacity), event.GetLastLoggerOption())
+	}
+	r.manager.callback(r.controllerID, nil)
+}
+
+func (r *resizeVolumeController) resize() error {
+	quantity := resource.MustParse(fmt.Sprintf("%dGi", r.capacity))
+	volumeMountName := fmt.Sprintf("manual%d", r.volume.ID)
+
+	if statefulset := r.appService.GetStatefulSet(); statefulset != nil {
+		if err := r.resizeClaimTemplates(statefulset, volumeMountName, quantity); err != nil {
+			return fmt.Errorf("update volume claim templates: %v", err)
+		}
+	}
+
+	claims, err := filterVolumeClaims(r.appService.GetClaims(), r.volume)
+	if err != nil {
+		return err
+	}
+	for _, claim := range claims {
+		patch := []byte(fmt.Sprintf(`{"spec":{"resources":{"requests":{"storage":"%s"}}}}`, quantity.String()))
+		_, err := r.manager.client.CoreV1().PersistentVolumeClaims(claim.Namespace).Patch(context.Background(),
+			claim.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("patch claim %s: %v", claim.Name, err)
+		}
+		r.appService.Logger.Info(fmt.Sprintf("claim %s begins resizing", claim.Name), event.GetLoggerOption("running"))
+	}
+
+	// the claims have requested the new capacity, save it so that the claims
+	// created from now on use it as well.
+	r.volume.VolumeCapacity = r.capacity
+	if err := db.GetManager().TenantServiceVolumeDao().UpdateModel(r.volume); err != nil {
+		return fmt.Errorf("update volume capacity: %v", err)
+	}
+
+	return r.waitResized(claims, quantity, 5*time.Minute)
+}
+
+//resizeClaimTemplates updates the volume claim template of the statefulset. The templates
+//can not be updated in place, so the statefulset is deleted with its pods orphaned and
+//created again, the new statefulset adopts the pods.
+func (r *resizeVolumeController) resizeClaimTemplates(statefulset *appsv1.StatefulSet, name string, quantity resource.Quantity) error {
+	stsClient := r.manager.client.AppsV1().StatefulSets(statefulset.Namespace)
+	current, err := stsClient.Get(context.Background(), statefulset.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	newStatefulSet := current.DeepCopy()
+	if !setClaimTemplateCapacity(newStatefulSet, name, quantity) {
+		return nil
+	}
+
+	orphan := metav1.DeletePropagationOrphan
+	err = stsClient.Delete(context.Background(), current.Name, metav1.DeleteOptions{PropagationPolicy: &orphan})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	err = wait.PollImmediate(time.Second, time.Minute, func() (bool, error) {
+		_, err := stsClient.Get(context.Background(), current.Name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("wait for statefulset %s to be deleted: %v", current.Name, err)
+	}
+
+	newStatefulSet.ResourceVersion = ""
+	newStatefulSet.UID = ""
+	newStatefulSet.CreationTimestamp = metav1.Time{}
+	newStatefulSet.ManagedFields = nil
+	newStatefulSet.Status = appsv1.StatefulSetStatus{}
+	_, err = stsClient.Create(context.Background(), newStatefulSet, metav1.CreateOptions{})
+	return err
+}
+
+//waitResized waits for the capacity of the claims to reach the quantity, and reports the
+//resize conditions of the claims.
+func (r *resizeVolumeController) waitResized(claims []*corev1.PersistentVolumeClaim, quantity resource.Quantity, timeout time.Duration) error {
+	pending := make(map[string]string, len(claims))
+	for _, claim := range claims {
+		pending[claim.Name] = claim.Namespace
+	}
+	reported := make(map[string]corev1.PersistentVolumeClaimConditionType)
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(pending) > 0 {
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			return ErrWaitTimeOut
+		case <-r.stopChan:
+			return ErrWaitCancel
+		}
+		for name, namespace := range pending {
+			claim, err := r.manager.client.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), name, metav1.GetOptions{})
+			if err != nil {
+				logrus.Warningf("get claim %s/%s: %v", namespace, name, err)
+				continue
+			}
+			resized, condition := claimResizeStatus(claim, quantity)
+			if resized {
+				delete(pending, name)
+				r.appService.Logger.Info(fmt.Sprintf("claim %s is resized to %s", name, quantity.String()), event.GetLoggerOption("running"))
+				continue
+			}
+			if condition != "" && reported[name] != condition {
+				reported[name] = condition
+				msg := fmt.Sprintf("claim %s is being resized by the storage provider", name)
+				if condition == corev1.PersistentVolumeClaimFileSystemResizePending {
+					msg = fmt.Sprintf("claim %s is waiting for the file system to be resized on the node", name)
+				}
+				r.appService.Logger.Info(msg, event.GetLoggerOption("running"))
+			}
+		}
+	}
+	return nil
+}
+
+func (r *resizeVolumeController) Stop() error {
+	close(r.stopChan)
+	return nil
+}
+
+//filterVolumeClaims returns the claims created for the volume. The claims of share-file volumes are labeled
+//with the volume mount name, and the claims of local and storage class volumes are labeled with the volume name.
+func filterVolumeClaims(claims []*corev1.PersistentVolumeClaim, volume *dbmodel.TenantServiceVolume) ([]*corev1.PersistentVolumeClaim, error) {
+	volumeMountName := fmt.Sprintf("manual%d", volume.ID)
+	var result []*corev1.PersistentVolumeClaim
+	for _, claim := range claims {
+		// the claims of statefulset are named as <template>-<statefulset>-<ordinal>
+		if claim.Name != volumeMountName && !strings.HasPrefix(claim.Name, volumeMountName+"-") {
+			continue
+		}
+		if label := claim.Labels["volume_name"]; label == volumeMountName || label == volume.VolumeName {
+			result = append(result, claim)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no claim is found for volume %s", volume.VolumeName)
+	}
+	return result, nil
+}
+
+//setClaimTemplateCapacity sets the storage request of the named claim template,
+//returns false if the template is absent or already large enough.
+func setClaimTemplateCapacity(statefulset *appsv1.StatefulSet, name string, quantity resource.Quantity) bool {
+	var changed bool
+	for i := range statefulset.Spec.VolumeClaimTemplates {
+		template := &statefulset.Spec.VolumeClaimTemplates[i]
+		if template.Name != name {
+			continue
+		}
+		if current, ok := template.Spec.Resources.Requests[corev1.ResourceStorage]; ok && current.Cmp(quantity) >= 0 {
+			continue
+		}
+		if template.Spec.Resources.Requests == nil {
+			template.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		template.Spec.Resources.Requests[corev1.ResourceStorage] = quantity
+		changed = true
+	}
+	return changed
+}
+
+//claimResizeStatus returns whether the capacity of the claim reaches the quantity,
+//and the resize condition of the claim if it is still resizing.
+func claimResizeStatus(claim *corev1.PersistentVolumeClaim, quantity resource.Quantity) (bool, corev1.PersistentVolumeClaimConditionType) {
+	if capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]; ok && capacity.Cmp(quantity) >= 0 {
+		return true, ""
+	}
+	var condition corev1.PersistentVolumeClaimConditionType
+	for _, c := range claim.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		if c.Type == corev1.PersistentVolumeClaimResizing || c.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
+			condition = c.Type
+		}
+	}
+	return false, condition
+}
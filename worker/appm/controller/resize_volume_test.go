@@ -0,0 +1,120 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"testing"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newClaim(name, volumeName, request string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"volume_name": volumeName}},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(request)},
+			},
+		},
+	}
+}
+
+func TestFilterVolumeClaims(t *testing.T) {
+	claims := []*corev1.PersistentVolumeClaim{
+		newClaim("manual1-app-0", "manual1", "10Gi"),
+		newClaim("manual2-app-0", "manual2", "10Gi"),
+		newClaim("manual1-app-1", "manual1", "10Gi"),
+	}
+	shareFile := &dbmodel.TenantServiceVolume{Model: dbmodel.Model{ID: 1}, VolumeName: "data", VolumeType: "share-file"}
+	got, err := filterVolumeClaims(claims, shareFile)
+	if err != nil || len(got) != 2 || got[0].Name != "manual1-app-0" || got[1].Name != "manual1-app-1" {
+		t.Errorf("unexpected claims: %v, %v", got, err)
+	}
+	// local and storage class volumes label the claims with the volume name
+	claims = []*corev1.PersistentVolumeClaim{
+		newClaim("manual7-app-0", "data", "10Gi"),
+		newClaim("manual8-app-0", "logs", "10Gi"),
+		newClaim("manual7-app-1", "data", "10Gi"),
+		newClaim("manual70-app-0", "data", "10Gi"),
+	}
+	for _, volumeType := range []string{"local", "ceph-rbd"} {
+		volume := &dbmodel.TenantServiceVolume{Model: dbmodel.Model{ID: 7}, VolumeName: "data", VolumeType: volumeType}
+		got, err := filterVolumeClaims(claims, volume)
+		if err != nil || len(got) != 2 || got[0].Name != "manual7-app-0" || got[1].Name != "manual7-app-1" {
+			t.Errorf("unexpected claims of %s volume: %v, %v", volumeType, got, err)
+		}
+	}
+	if _, err := filterVolumeClaims(claims, &dbmodel.TenantServiceVolume{Model: dbmodel.Model{ID: 9}, VolumeName: "cache"}); err == nil {
+		t.Error("expect error if no claim is found")
+	}
+}
+
+func TestSetClaimTemplateCapacity(t *testing.T) {
+	statefulset := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				*newClaim("manual1", "manual1", "10Gi"),
+				*newClaim("manual2", "manual2", "10Gi"),
+			},
+		},
+	}
+	if !setClaimTemplateCapacity(statefulset, "manual1", resource.MustParse("20Gi")) {
+		t.Fatal("the template should be changed")
+	}
+	request := statefulset.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+	if request.String() != "20Gi" {
+		t.Errorf("want 20Gi, got %s", request.String())
+	}
+	other := statefulset.Spec.VolumeClaimTemplates[1].Spec.Resources.Requests[corev1.ResourceStorage]
+	if other.String() != "10Gi" {
+		t.Errorf("other templates should not be changed, got %s", other.String())
+	}
+	if setClaimTemplateCapacity(statefulset, "manual1", resource.MustParse("20Gi")) {
+		t.Error("the template is large enough")
+	}
+	if setClaimTemplateCapacity(statefulset, "manual3", resource.MustParse("20Gi")) {
+		t.Error("the template does not exist")
+	}
+}
+
+func TestClaimResizeStatus(t *testing.T) {
+	quantity := resource.MustParse("20Gi")
+	claim := newClaim("manual1", "manual1", "20Gi")
+	claim.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	if resized, condition := claimResizeStatus(claim, quantity); resized || condition != "" {
+		t.Errorf("want pending without condition, got %v %s", resized, condition)
+	}
+
+	claim.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+		{Type: corev1.PersistentVolumeClaimResizing, Status: corev1.ConditionFalse},
+		{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+	}
+	if resized, condition := claimResizeStatus(claim, quantity); resized || condition != corev1.PersistentVolumeClaimFileSystemResizePending {
+		t.Errorf("want file system resize pending, got %v %s", resized, condition)
+	}
+
+	claim.Status.Capacity[corev1.ResourceStorage] = quantity
+	if resized, _ := claimResizeStatus(claim, quantity); !resized {
+		t.Error("the claim should be resized")
+	}
+}
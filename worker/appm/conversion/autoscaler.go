@@ -20,7 +20,9 @@ package conversion
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 
 	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
@@ -59,11 +61,31 @@ func newHPAs(as *v1.AppService, dbmanager db.Manager) ([]*autoscalingv2.Horizont
 	}
 
 	var hpas []*autoscalingv2.HorizontalPodAutoscaler
+	var monitorServices map[string]string
 	for _, rule := range xpaRules {
 		metrics, err := dbmanager.TenantServceAutoscalerRuleMetricsDao().ListByRuleID(rule.RuleID)
 		if err != nil {
 			return nil, err
 		}
+		for _, metric := range metrics {
+			if metric.MetricsType != model.ObjectMetrics {
+				continue
+			}
+			if monitorServices == nil {
+				if monitorServices, err = serviceMonitorServices(as, dbmanager); err != nil {
+					return nil, err
+				}
+			}
+			// the object metric describes the kubernetes service selected by the service monitor,
+			// the only service monitor is used if not specified
+			service, ok := monitorServices[metric.MetricObject]
+			if !ok && metric.MetricObject == "" && len(monitorServices) == 1 {
+				for _, name := range monitorServices {
+					service = name
+				}
+			}
+			metric.MetricObject = service
+		}
 
 		var kind, name string
 		if as.GetStatefulSet() != nil {
@@ -113,6 +135,127 @@ func createResourceMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) aut
 	return ms
 }
 
+// GatewayExternalMetrics the gateway metrics of component, the key is the metric name of rule,
+// and the value is the external metric recorded by rbd-monitor and served by the metrics adapter.
+var GatewayExternalMetrics = map[string]string{
+	"requests_per_second": "rbd_gateway_requests_per_second",
+	"response_time":       "rbd_gateway_upstream_latency_ms",
+}
+
+// ScalingTriggerMetric returns the metric which triggered the scaling from the message of HPA event,
+// such as "New size: 3; reason: pods metric http_requests above target".
+func ScalingTriggerMetric(message string) string {
+	idx := strings.Index(message, "reason: ")
+	if idx < 0 {
+		return ""
+	}
+	reason := strings.TrimSpace(message[idx+len("reason: "):])
+	if strings.HasPrefix(reason, "All metrics") {
+		return reason
+	}
+	reason = strings.TrimSuffix(strings.TrimSuffix(reason, " above target"), " below target")
+	if !strings.HasPrefix(reason, "external metric ") {
+		return reason
+	}
+	// the selector of external metric is too long to show, such as rbd_gateway_requests_per_second(&LabelSelector{...})
+	name := strings.TrimPrefix(reason, "external metric ")
+	if i := strings.Index(name, "("); i > 0 {
+		name = name[:i]
+	}
+	for gatewayMetric, externalMetric := range GatewayExternalMetrics {
+		if externalMetric == name {
+			return "gateway metric " + gatewayMetric
+		}
+	}
+	return "external metric " + name
+}
+
+func metricTarget(metric *model.TenantServiceAutoscalerRuleMetrics) (autoscalingv2.MetricTarget, error) {
+	value := resource.NewQuantity(int64(metric.MetricTargetValue), resource.DecimalSI)
+	switch metric.MetricTargetType {
+	case "average_value":
+		return autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: value}, nil
+	case "value":
+		return autoscalingv2.MetricTarget{Type: autoscalingv2.ValueMetricType, Value: value}, nil
+	}
+	return autoscalingv2.MetricTarget{}, fmt.Errorf("unsupported target type %s", metric.MetricTargetType)
+}
+
+// createCustomMetrics creates the metric spec served by the custom or external metrics api.
+func createCustomMetrics(serviceID string, metric *model.TenantServiceAutoscalerRuleMetrics) (*autoscalingv2.MetricSpec, error) {
+	target, err := metricTarget(metric)
+	if err != nil {
+		return nil, err
+	}
+	var selector *metav1.LabelSelector
+	if metric.MetricSelector != "" {
+		selector, err = metav1.ParseToLabelSelector(metric.MetricSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %s: %v", metric.MetricSelector, err)
+		}
+	}
+	identifier := autoscalingv2.MetricIdentifier{Name: metric.MetricsName, Selector: selector}
+
+	switch metric.MetricsType {
+	case model.PodsMetrics:
+		if target.Type != autoscalingv2.AverageValueMetricType {
+			return nil, fmt.Errorf("the target type of pods metric must be average_value")
+		}
+		return &autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{Metric: identifier, Target: target},
+		}, nil
+	case model.ObjectMetrics:
+		if metric.MetricObject == "" {
+			return nil, fmt.Errorf("the service of object metric not found")
+		}
+		return &autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ObjectMetricSourceType,
+			Object: &autoscalingv2.ObjectMetricSource{
+				DescribedObject: autoscalingv2.CrossVersionObjectReference{
+					Kind:       "Service",
+					Name:       metric.MetricObject,
+					APIVersion: "v1",
+				},
+				Metric: identifier,
+				Target: target,
+			},
+		}, nil
+	case model.GatewayMetrics:
+		name, ok := GatewayExternalMetrics[metric.MetricsName]
+		if !ok {
+			return nil, fmt.Errorf("unsupported gateway metric")
+		}
+		identifier = autoscalingv2.MetricIdentifier{
+			Name: name,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"service_id": serviceID},
+			},
+		}
+	}
+	return &autoscalingv2.MetricSpec{
+		Type:     autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{Metric: identifier, Target: target},
+	}, nil
+}
+
+// serviceMonitorServices returns the kubernetes services selected by the service monitors of component,
+// the key is the name of service monitor.
+func serviceMonitorServices(as *v1.AppService, dbmanager db.Manager) (map[string]string, error) {
+	tsms, err := dbmanager.TenantServiceMonitorDao().GetByServiceID(as.ServiceID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	portService := servicesByPort(as)
+	services := make(map[string]string, len(tsms))
+	for _, tsm := range tsms {
+		if service, ok := portService[int32(tsm.Port)]; ok {
+			services[tsm.Name] = service.GetName()
+		}
+	}
+	return services, nil
+}
+
 func newHPA(namespace, kind, name string, labels map[string]string, rule *model.TenantServiceAutoscalerRules, metrics []*model.TenantServiceAutoscalerRuleMetrics) *autoscalingv2.HorizontalPodAutoscaler {
 	hpa := &autoscalingv2.HorizontalPodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
@@ -133,18 +276,27 @@ func newHPA(namespace, kind, name string, labels map[string]string, rule *model.
 	}
 
 	for _, metric := range metrics {
-		if metric.MetricsType != "resource_metrics" {
-			logrus.Warningf("rule id:  %s; unsupported metric type: %s", rule.RuleID, metric.MetricsType)
-			continue
-		}
 		if metric.MetricTargetValue <= 0 {
-			// TODO: If the target value of cpu and memory is 0, it will not take effect.
-			// TODO: The target value of the custom indicator can be 0.
+			// If the target value is 0, it will not take effect.
 			continue
 		}
 
-		ms := createResourceMetrics(metric)
-		spec.Metrics = append(spec.Metrics, ms)
+		var ms *autoscalingv2.MetricSpec
+		var err error
+		switch metric.MetricsType {
+		case model.ResourceMetrics:
+			resourceMetric := createResourceMetrics(metric)
+			ms = &resourceMetric
+		case model.PodsMetrics, model.ObjectMetrics, model.ExternalMetrics, model.GatewayMetrics:
+			ms, err = createCustomMetrics(rule.ServiceID, metric)
+		default:
+			err = fmt.Errorf("unsupported metric type: %s", metric.MetricsType)
+		}
+		if err != nil {
+			logrus.Warningf("rule id: %s; metric %s: %v", rule.RuleID, metric.MetricsName, err)
+			continue
+		}
+		spec.Metrics = append(spec.Metrics, *ms)
 	}
 	if len(spec.Metrics) == 0 {
 		return nil
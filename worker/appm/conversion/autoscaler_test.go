@@ -6,6 +6,7 @@ import (
 
 	"github.com/goodrain/rainbond/db/model"
 	k8sutil "github.com/goodrain/rainbond/util/k8s"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -58,3 +59,160 @@ func TestNewHPA(t *testing.T) {
 		t.Fatalf("create hpa: %v", err)
 	}
 }
+
+func TestCreateCustomMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		metric  *model.TenantServiceAutoscalerRuleMetrics
+		check   func(ms *autoscalingv2.MetricSpec) bool
+		wantErr bool
+	}{
+		{
+			name: "pods metric",
+			metric: &model.TenantServiceAutoscalerRuleMetrics{
+				MetricsType: model.PodsMetrics, MetricsName: "http_requests_per_second",
+				MetricTargetType: "average_value", MetricTargetValue: 100,
+			},
+			check: func(ms *autoscalingv2.MetricSpec) bool {
+				return ms.Type == autoscalingv2.PodsMetricSourceType && ms.Pods.Metric.Name == "http_requests_per_second" &&
+					ms.Pods.Target.AverageValue.Value() == 100
+			},
+		},
+		{
+			name: "pods metric with value target",
+			metric: &model.TenantServiceAutoscalerRuleMetrics{
+				MetricsType: model.PodsMetrics, MetricsName: "http_requests_per_second",
+				MetricTargetType: "value", MetricTargetValue: 100,
+			},
+			wantErr: true,
+		},
+		{
+			name: "object metric",
+			metric: &model.TenantServiceAutoscalerRuleMetrics{
+				MetricsType: model.ObjectMetrics, MetricsName: "queue_length", MetricObject: "gr123456-80",
+				MetricTargetType: "value", MetricTargetValue: 30,
+			},
+			check: func(ms *autoscalingv2.MetricSpec) bool {
+				return ms.Type == autoscalingv2.ObjectMetricSourceType && ms.Object.DescribedObject.Kind == "Service" &&
+					ms.Object.DescribedObject.Name == "gr123456-80" && ms.Object.Target.Value.Value() == 30
+			},
+		},
+		{
+			name: "object metric without service",
+			metric: &model.TenantServiceAutoscalerRuleMetrics{
+				MetricsType: model.ObjectMetrics, MetricsName: "queue_length",
+				MetricTargetType: "value", MetricTargetValue: 30,
+			},
+			wantErr: true,
+		},
+		{
+			name: "external metric",
+			metric: &model.TenantServiceAutoscalerRuleMetrics{
+				MetricsType: model.ExternalMetrics, MetricsName: "rabbitmq_queue_messages", MetricSelector: "queue=orders",
+				MetricTargetType: "average_value", MetricTargetValue: 50,
+			},
+			check: func(ms *autoscalingv2.MetricSpec) bool {
+				return ms.Type == autoscalingv2.ExternalMetricSourceType && ms.External.Metric.Name == "rabbitmq_queue_messages" &&
+					ms.External.Metric.Selector.MatchLabels["queue"] == "orders"
+			},
+		},
+		{
+			name: "external metric with invalid selector",
+			metric: &model.TenantServiceAutoscalerRuleMetrics{
+				MetricsType: model.ExternalMetrics, MetricsName: "rabbitmq_queue_messages", MetricSelector: "queue in (",
+				MetricTargetType: "average_value", MetricTargetValue: 50,
+			},
+			wantErr: true,
+		},
+		{
+			name: "gateway metric",
+			metric: &model.TenantServiceAutoscalerRuleMetrics{
+				MetricsType: model.GatewayMetrics, MetricsName: "response_time",
+				MetricTargetType: "value", MetricTargetValue: 200,
+			},
+			check: func(ms *autoscalingv2.MetricSpec) bool {
+				return ms.Type == autoscalingv2.ExternalMetricSourceType && ms.External.Metric.Name == "rbd_gateway_upstream_latency_ms" &&
+					ms.External.Metric.Selector.MatchLabels["service_id"] == "45197f4936cf45efa2ac4831ce42025a"
+			},
+		},
+		{
+			name: "unknown gateway metric",
+			metric: &model.TenantServiceAutoscalerRuleMetrics{
+				MetricsType: model.GatewayMetrics, MetricsName: "bytes_sent",
+				MetricTargetType: "value", MetricTargetValue: 200,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, err := createCustomMetrics("45197f4936cf45efa2ac4831ce42025a", tc.metric)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if err == nil && !tc.check(ms) {
+				t.Errorf("unexpected metric spec %#v", ms)
+			}
+		})
+	}
+}
+
+func TestNewHPAWithCustomMetrics(t *testing.T) {
+	rule := &model.TenantServiceAutoscalerRules{
+		RuleID:      "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+		ServiceID:   "45197f4936cf45efa2ac4831ce42025a",
+		MinReplicas: 1,
+		MaxReplicas: 10,
+	}
+	metrics := []*model.TenantServiceAutoscalerRuleMetrics{
+		{MetricsType: model.ResourceMetrics, MetricsName: "cpu", MetricTargetType: "utilization", MetricTargetValue: 50},
+		{MetricsType: model.GatewayMetrics, MetricsName: "requests_per_second", MetricTargetType: "average_value", MetricTargetValue: 100},
+		{MetricsType: "unknown_metrics", MetricsName: "foo", MetricTargetType: "value", MetricTargetValue: 1},
+	}
+	hpa := newHPA("bab18e6b1c8640979b91f8dfdd211226", "Deployment", "45197f4936cf45efa2ac4831ce42025a-deployment", nil, rule, metrics)
+	if hpa == nil || len(hpa.Spec.Metrics) != 2 {
+		t.Fatalf("expect the hpa with 2 metrics, got %#v", hpa)
+	}
+	if hpa.Spec.Metrics[1].Type != autoscalingv2.ExternalMetricSourceType {
+		t.Errorf("expect the external metric of gateway, got %s", hpa.Spec.Metrics[1].Type)
+	}
+}
+
+func TestScalingTriggerMetric(t *testing.T) {
+	tests := []struct {
+		message, want string
+	}{
+		{
+			message: "New size: 3; reason: cpu resource utilization (percentage of request) above target",
+			want:    "cpu resource utilization (percentage of request)",
+		},
+		{
+			message: "New size: 4; reason: pods metric http_requests_per_second above target",
+			want:    "pods metric http_requests_per_second",
+		},
+		{
+			message: "New size: 2; reason: Service metric queue_length above target",
+			want:    "Service metric queue_length",
+		},
+		{
+			message: "New size: 5; reason: external metric rabbitmq_queue_messages(&LabelSelector{MatchLabels:map[string]string{queue: orders,},}) above target",
+			want:    "external metric rabbitmq_queue_messages",
+		},
+		{
+			message: "New size: 5; reason: external metric rbd_gateway_requests_per_second(&LabelSelector{MatchLabels:map[string]string{service_id: xxx,},}) above target",
+			want:    "gateway metric requests_per_second",
+		},
+		{
+			message: "New size: 1; reason: All metrics below target",
+			want:    "All metrics below target",
+		},
+		{
+			message: "the HPA was unable to compute the replica count",
+		},
+	}
+	for _, tc := range tests {
+		if got := ScalingTriggerMetric(tc.message); got != tc.want {
+			t.Errorf("message %q: want %q, got %q", tc.message, tc.want, got)
+		}
+	}
+}
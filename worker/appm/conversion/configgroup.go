@@ -1,14 +1,34 @@
 package conversion
 
 import (
+	"context"
+	"crypto/sha256"
 	"fmt"
+	"sort"
+
 	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util/vault"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// ConfigGroupChecksumAnnotation the checksum of the values read from the source of config group
+	ConfigGroupChecksumAnnotation = "configgroup.rainbond.io/checksum"
+	// ConfigGroupMountPathAnnotation the directory the secret of config group is mounted to
+	ConfigGroupMountPathAnnotation = "configgroup.rainbond.io/mount-path"
+)
+
+var vaultClient *vault.Client
+
+// SetVaultClient sets the client reading the config groups whose source is vault
+func SetVaultClient(c *vault.Client) {
+	vaultClient = c
+}
+
 // TenantServiceConfigGroup -
 func TenantServiceConfigGroup(as *v1.AppService, dbm db.Manager) error {
 	logrus.Infof("service id: %s; create config group for service.", as.ServiceID)
@@ -19,8 +39,8 @@ func TenantServiceConfigGroup(as *v1.AppService, dbm db.Manager) error {
 
 	var secrets []*corev1.Secret
 	for _, group := range groups {
-		cg := createConfigGroup(as, as.TenantID, group.AppID, group.ConfigGroupName)
-		secret, err := cg.secretForConfigGroup()
+		cg := createConfigGroup(as, as.TenantID, group)
+		secret, err := cg.secretForConfigGroup(dbm)
 		if err != nil {
 			return fmt.Errorf("create secret for config group: %v", err)
 		}
@@ -32,25 +52,84 @@ func TenantServiceConfigGroup(as *v1.AppService, dbm db.Manager) error {
 	return nil
 }
 
+// ConfigGroupSecretName returns the name of the secret keeping the items of config group
+func ConfigGroupSecretName(group *dbmodel.ApplicationConfigGroup) string {
+	return fmt.Sprintf("%s-%s", group.ConfigGroupName, group.AppID)
+}
+
+// ConfigGroupData reads the items of config group from its source
+func ConfigGroupData(ctx context.Context, group *dbmodel.ApplicationConfigGroup, dbm db.Manager) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	switch group.SourceType {
+	case "", dbmodel.ConfigGroupSourceDB:
+		items, err := dbm.AppConfigGroupItemDao().GetConfigGroupItemsByID(group.AppID, group.ConfigGroupName)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			data[item.ItemKey] = []byte(item.ItemValue)
+		}
+	case dbmodel.ConfigGroupSourceVault:
+		if vaultClient == nil {
+			return nil, fmt.Errorf("vault is not configured for config group %s", group.ConfigGroupName)
+		}
+		secret, err := vaultClient.ReadKV(ctx, group.SourcePath)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range secret.Data {
+			data[k] = []byte(v)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported source type %s of config group %s", group.SourceType, group.ConfigGroupName)
+	}
+	return data, nil
+}
+
+// ConfigGroupChecksum returns the checksum of the items of config group
+func ConfigGroupChecksum(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%d:%s%d:", len(k), k, len(data[k]))
+		h.Write(data[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// splitConfigGroupSecrets splits the secrets of config groups into the ones injected as envs and the ones mounted as files
+func splitConfigGroupSecrets(secrets []*corev1.Secret) (envSecrets, fileSecrets []*corev1.Secret) {
+	for _, secret := range secrets {
+		if secret.Annotations[ConfigGroupMountPathAnnotation] != "" {
+			fileSecrets = append(fileSecrets, secret)
+			continue
+		}
+		envSecrets = append(envSecrets, secret)
+	}
+	return
+}
+
 type configGroup struct {
 	as *v1.AppService
 
-	namespace       string
-	appID           string
-	configGroupName string
+	namespace string
+	group     *dbmodel.ApplicationConfigGroup
 }
 
-func createConfigGroup(as *v1.AppService, ns, appID, configGroupName string) *configGroup {
+func createConfigGroup(as *v1.AppService, ns string, group *dbmodel.ApplicationConfigGroup) *configGroup {
 	return &configGroup{
-		as:              as,
-		namespace:       ns,
-		appID:           appID,
-		configGroupName: configGroupName,
+		as:        as,
+		namespace: ns,
+		group:     group,
 	}
 }
 
-func (c *configGroup) secretForConfigGroup() (*corev1.Secret, error) {
-	items, err := db.GetManager().AppConfigGroupItemDao().GetConfigGroupItemsByID(c.appID, c.configGroupName)
+func (c *configGroup) secretForConfigGroup(dbm db.Manager) (*corev1.Secret, error) {
+	data, err := ConfigGroupData(context.Background(), c.group, dbm)
 	if err != nil {
 		return nil, err
 	}
@@ -59,16 +138,19 @@ func (c *configGroup) secretForConfigGroup() (*corev1.Secret, error) {
 	delete(labels, "service_id")
 	delete(labels, "service_alias")
 
-	data := make(map[string][]byte)
-	for _, item := range items {
-		data[item.ItemKey] = []byte(item.ItemValue)
+	annotations := map[string]string{
+		ConfigGroupChecksumAnnotation: ConfigGroupChecksum(data),
+	}
+	if c.group.DeployType == dbmodel.ConfigGroupDeployTypeFile {
+		annotations[ConfigGroupMountPathAnnotation] = c.group.MountPath
 	}
 
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-%s", c.configGroupName, c.appID),
-			Namespace: c.namespace,
-			Labels:    labels,
+			Name:        ConfigGroupSecretName(c.group),
+			Namespace:   c.namespace,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Data: data,
 		Type: corev1.SecretTypeOpaque,
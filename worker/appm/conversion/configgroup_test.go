@@ -0,0 +1,85 @@
+package conversion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/dao"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util/vault"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+)
+
+func TestTenantServiceConfigGroup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/app/db" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"password":"secret"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+	SetVaultClient(vault.NewClient(server.URL, "root", ""))
+	defer SetVaultClient(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dbm := db.NewMockManager(ctrl)
+	groupDao := dao.NewMockAppConfigGroupDao(ctrl)
+	groupDao.EXPECT().ListByServiceID("sid").Return([]*dbmodel.ApplicationConfigGroup{
+		{AppID: "appid", ConfigGroupName: "envs", DeployType: "env"},
+		{AppID: "appid", ConfigGroupName: "db", DeployType: "file", MountPath: "/etc/db", SourceType: "vault", SourcePath: "secret/app/db"},
+	}, nil)
+	dbm.EXPECT().AppConfigGroupDao().Return(groupDao)
+	itemDao := dao.NewMockAppConfigGroupItemDao(ctrl)
+	itemDao.EXPECT().GetConfigGroupItemsByID("appid", "envs").Return([]*dbmodel.ConfigGroupItem{
+		{ItemKey: "LOG_LEVEL", ItemValue: "debug"},
+	}, nil)
+	dbm.EXPECT().AppConfigGroupItemDao().Return(itemDao)
+
+	as := &v1.AppService{AppServiceBase: v1.AppServiceBase{ServiceID: "sid", TenantID: "tid"}}
+	if err := TenantServiceConfigGroup(as, dbm); err != nil {
+		t.Fatal(err)
+	}
+	envSecrets, fileSecrets := splitConfigGroupSecrets(as.GetEnvVarSecrets(true))
+	if len(envSecrets) != 1 || len(fileSecrets) != 1 {
+		t.Fatalf("expected 1 env secret and 1 file secret, but got %d and %d", len(envSecrets), len(fileSecrets))
+	}
+	if string(envSecrets[0].Data["LOG_LEVEL"]) != "debug" {
+		t.Errorf("expected LOG_LEVEL debug, but got %s", envSecrets[0].Data["LOG_LEVEL"])
+	}
+	file := fileSecrets[0]
+	if file.Name != "db-appid" || file.Namespace != "tid" || string(file.Data["password"]) != "secret" {
+		t.Errorf("unexpected secret of vault: %+v", file)
+	}
+	if file.Annotations[ConfigGroupMountPathAnnotation] != "/etc/db" {
+		t.Errorf("expected mount path /etc/db, but got %s", file.Annotations[ConfigGroupMountPathAnnotation])
+	}
+	if file.Annotations[ConfigGroupChecksumAnnotation] != ConfigGroupChecksum(file.Data) {
+		t.Error("expected the checksum of data in annotations")
+	}
+}
+
+func TestConfigGroupChecksum(t *testing.T) {
+	a := ConfigGroupChecksum(map[string][]byte{"a": []byte("1"), "b": []byte("2")})
+	if b := ConfigGroupChecksum(map[string][]byte{"b": []byte("2"), "a": []byte("1")}); a != b {
+		t.Error("expected the checksum independent of the order of keys")
+	}
+	if b := ConfigGroupChecksum(map[string][]byte{"a": []byte("12")}); a == b {
+		t.Error("expected different checksums for different data")
+	}
+	if b := ConfigGroupChecksum(map[string][]byte{"a1": []byte(""), "b": []byte("2")}); a == b {
+		t.Error("expected different checksums for different keys")
+	}
+}
+
+func TestConfigGroupDataWithoutVault(t *testing.T) {
+	_, err := ConfigGroupData(context.Background(), &dbmodel.ApplicationConfigGroup{SourceType: "vault", SourcePath: "secret/app"}, nil)
+	if err == nil {
+		t.Error("expected an error if vault is not configured")
+	}
+}
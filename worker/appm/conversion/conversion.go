@@ -80,13 +80,13 @@ func InitAppService(dbmanager db.Manager, serviceID string, configs map[string]s
 		UpgradePatch: make(map[string][]byte, 2),
 	}
 
-	// setup governance mode
+	// setup governance mode and tracing
 	app, err := dbmanager.ApplicationDao().GetByServiceID(serviceID)
 	if err != nil && err != bcode.ErrApplicationNotFound {
 		return nil, fmt.Errorf("get app based on service id(%s)", serviceID)
 	}
 	if app != nil {
-		appService.AppServiceBase.GovernanceMode = app.GovernanceMode
+		setupAppConfig(appService, app)
 	}
 
 	for _, c := range conversionList {
@@ -99,6 +99,18 @@ func InitAppService(dbmanager db.Manager, serviceID string, configs map[string]s
 	return appService, nil
 }
 
+func setupAppConfig(as *v1.AppService, app *model.Application) {
+	as.GovernanceMode = app.GovernanceMode
+	if app.TracingEnabled() {
+		as.Tracing = &v1.TracingConfig{
+			Provider:       app.TracingProvider,
+			Endpoint:       app.TracingEndpoint,
+			SampleRate:     app.TracingSampleRate,
+			OpenCensusPort: app.TracingOpenCensusPort,
+		}
+	}
+}
+
 //InitCacheAppService init cache app service.
 //if store manager receive a kube model belong with service and not find in store,will create
 func InitCacheAppService(dbm db.Manager, serviceID, creatorID string) (*v1.AppService, error) {
@@ -112,13 +124,13 @@ func InitCacheAppService(dbm db.Manager, serviceID, creatorID string) (*v1.AppSe
 		UpgradePatch: make(map[string][]byte, 2),
 	}
 
-	// setup governance mode
+	// setup governance mode and tracing
 	app, err := dbm.ApplicationDao().GetByServiceID(serviceID)
 	if err != nil && err != bcode.ErrApplicationNotFound {
 		return nil, fmt.Errorf("get app based on service id(%s)", serviceID)
 	}
 	if app != nil {
-		appService.AppServiceBase.GovernanceMode = app.GovernanceMode
+		setupAppConfig(appService, app)
 	}
 
 	if err := TenantServiceBase(appService, dbm); err != nil {
@@ -151,7 +151,12 @@ func (a *AppServiceBuild) Build() (*v1.K8sResources, error) {
 				case model.GovernanceModeKubernetesNativeService:
 					services = append(services, a.createKubernetesNativeService(port))
 				default:
-					services = append(services, a.createInnerService(port))
+					service := a.createInnerService(port)
+					// the inbound plugin terminates mutual tls for the service
+					if crt && a.appService.GovernanceMode == model.GovernanceModeBuildInServiceMeshMTLS {
+						service.Labels["mesh_mtls"] = "true"
+					}
+					services = append(services, service)
 				}
 			}
 			if *port.IsOuterService {
@@ -290,6 +295,10 @@ func (a *AppServiceBuild) applyHTTPRule(rule *model.HTTPRule, containerPort, plu
 	if rule.Cookie != "" {
 		annos[parser.GetAnnotationWithPrefix("cookie")] = rule.Cookie
 	}
+	// propagate the trace context if the tracing of the application is enabled
+	if a.appService.Tracing != nil {
+		annos[parser.GetAnnotationWithPrefix("enable-tracing")] = "true"
+	}
 	// certificate
 	if rule.CertificateID != "" {
 		cert, err := a.dbmanager.CertificateDao().GetCertificateByID(rule.CertificateID)
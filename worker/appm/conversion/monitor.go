@@ -23,15 +23,8 @@ func TenantServiceMonitor(as *v1.AppService, dbmanager db.Manager) error {
 	return nil
 }
 
-func createServiceMonitor(as *v1.AppService, dbmanager db.Manager) []*mv1.ServiceMonitor {
-	tsms, err := dbmanager.TenantServiceMonitorDao().GetByServiceID(as.ServiceID)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		logrus.Errorf("get service %s monitor config failure %s", as.ServiceID, err.Error())
-		return nil
-	}
-	if tsms == nil || len(tsms) == 0 {
-		return nil
-	}
+//servicesByPort returns the kubernetes services of component by port, the inner service is preferred
+func servicesByPort(as *v1.AppService) map[int32]*corev1.Service {
 	services := as.GetServices(false)
 	var portService = make(map[int32]*corev1.Service, len(services))
 	for i, s := range services {
@@ -45,6 +38,19 @@ func createServiceMonitor(as *v1.AppService, dbmanager db.Manager) []*mv1.Servic
 			}
 		}
 	}
+	return portService
+}
+
+func createServiceMonitor(as *v1.AppService, dbmanager db.Manager) []*mv1.ServiceMonitor {
+	tsms, err := dbmanager.TenantServiceMonitorDao().GetByServiceID(as.ServiceID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logrus.Errorf("get service %s monitor config failure %s", as.ServiceID, err.Error())
+		return nil
+	}
+	if tsms == nil || len(tsms) == 0 {
+		return nil
+	}
+	portService := servicesByPort(as)
 	var re []*mv1.ServiceMonitor
 	for _, tsm := range tsms {
 		if tsm.Name == "" {
@@ -35,6 +35,7 @@ import (
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/tracing"
 	typesv1 "github.com/goodrain/rainbond/worker/appm/types/v1"
 )
 
@@ -91,7 +92,7 @@ func conversionServicePlugin(as *typesv1.AppService, dbmanager db.Manager) ([]v1
 			return nil, nil, nil, err
 		}
 		var envFromSecrets []corev1.EnvFromSource
-		envVarSecrets := as.GetEnvVarSecrets(true)
+		envVarSecrets, _ := splitConfigGroupSecrets(as.GetEnvVarSecrets(true))
 		for _, secret := range envVarSecrets {
 			envFromSecrets = append(envFromSecrets, corev1.EnvFromSource{
 				SecretRef: &corev1.SecretEnvSource{
@@ -153,13 +154,15 @@ func conversionServicePlugin(as *typesv1.AppService, dbmanager db.Manager) ([]v1
 	}
 	//create plugin config to configmap
 	for i := range appPlugins {
-		ApplyPluginConfig(as, appPlugins[i], dbmanager, inboundPluginConfig)
+		if err := ApplyPluginConfig(as, appPlugins[i], dbmanager, inboundPluginConfig); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 	//if need proxy but not install net plugin
 	if as.NeedProxy && !netPlugin {
 		pluginID, err := applyDefaultMeshPluginConfig(as, dbmanager)
 		if err != nil {
-			logrus.Errorf("apply default mesh plugin config failure %s", err.Error())
+			return nil, nil, nil, fmt.Errorf("apply default mesh plugin config failure %s", err.Error())
 		}
 		c2 := createTCPDefaultPluginContainer(as, pluginID, mainContainer.Env)
 		containers = append(containers, c2)
@@ -204,7 +207,7 @@ func createProbeMeshInitContainer(as *typesv1.AppService, pluginID, serviceAlias
 
 //ApplyPluginConfig applyPluginConfig
 func ApplyPluginConfig(as *typesv1.AppService, servicePluginRelation *model.TenantServicePluginRelation,
-	dbmanager db.Manager, inboundPluginConfig *api_model.ResourceSpec) {
+	dbmanager db.Manager, inboundPluginConfig *api_model.ResourceSpec) error {
 	config, err := dbmanager.TenantPluginVersionConfigDao().GetPluginConfig(servicePluginRelation.ServiceID,
 		servicePluginRelation.PluginID)
 	if err != nil && err != gorm.ErrRecordNotFound {
@@ -241,8 +244,13 @@ func ApplyPluginConfig(as *typesv1.AppService, servicePluginRelation *model.Tena
 				"plugin-model":  servicePluginRelation.PluginModel,
 			},
 		}
+		if err := applyMeshMTLSAnnotations(as, cm, dbmanager); err != nil {
+			return err
+		}
+		applyMeshTracingAnnotations(as, cm)
 		as.SetConfigMap(cm)
 	}
+	return nil
 }
 
 //applyDefaultMeshPluginConfig applyDefaultMeshPluginConfig
@@ -296,10 +304,64 @@ func applyDefaultMeshPluginConfig(as *typesv1.AppService, dbmanager db.Manager)
 			"plugin-model":  model.OutBoundNetPlugin,
 		},
 	}
+	if err := applyMeshMTLSAnnotations(as, cm, dbmanager); err != nil {
+		return "", err
+	}
+	applyMeshTracingAnnotations(as, cm)
 	as.SetConfigMap(cm)
 	return pluginID, nil
 }
 
+//applyMeshMTLSAnnotations tell envoy xds server to enable mutual tls for the service,
+//the components depend on this service are allowed to call it.
+//The error is returned rather than annotating an incomplete caller list, which would reject the legal callers.
+func applyMeshMTLSAnnotations(as *typesv1.AppService, cm *v1.ConfigMap, dbmanager db.Manager) error {
+	if as.GovernanceMode != model.GovernanceModeBuildInServiceMeshMTLS {
+		return nil
+	}
+	if cm.Annotations == nil {
+		cm.Annotations = make(map[string]string)
+	}
+	cm.Annotations["mesh_mtls"] = "true"
+	relations, err := dbmanager.TenantServiceRelationDao().GetTenantServiceRelationsByDependServiceID(as.ServiceID)
+	if err != nil {
+		return fmt.Errorf("get service reverse depend service info failure %s", err.Error())
+	}
+	var callerIDs []string
+	for _, r := range relations {
+		callerIDs = append(callerIDs, r.ServiceID)
+	}
+	if len(callerIDs) == 0 {
+		return nil
+	}
+	callers, err := dbmanager.TenantServiceDao().GetServiceAliasByIDs(callerIDs)
+	if err != nil {
+		return fmt.Errorf("get reverse depend service alias failure %s", err.Error())
+	}
+	var aliases []string
+	for _, caller := range callers {
+		aliases = append(aliases, caller.ServiceAlias)
+	}
+	cm.Annotations["mesh_allowed_callers"] = strings.Join(aliases, ",")
+	return nil
+}
+
+//applyMeshTracingAnnotations tell envoy xds server to report the spans of the service to the collector of the application
+func applyMeshTracingAnnotations(as *typesv1.AppService, cm *v1.ConfigMap) {
+	if as.Tracing == nil {
+		return
+	}
+	if cm.Annotations == nil {
+		cm.Annotations = make(map[string]string)
+	}
+	cm.Annotations[tracing.ProviderAnnotation] = as.Tracing.Provider
+	cm.Annotations[tracing.EndpointAnnotation] = as.Tracing.Endpoint
+	cm.Annotations[tracing.SampleRateAnnotation] = strconv.Itoa(as.Tracing.SampleRate)
+	if as.Tracing.OpenCensusPort > 0 {
+		cm.Annotations[tracing.OpenCensusPortAnnotation] = strconv.Itoa(as.Tracing.OpenCensusPort)
+	}
+}
+
 func getPluginModel(pluginID, tenantID string, dbmanager db.Manager) (string, error) {
 	plugin, err := dbmanager.TenantPluginDao().GetPluginByID(pluginID, tenantID)
 	if err != nil {
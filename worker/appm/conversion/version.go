@@ -33,6 +33,7 @@ import (
 	"github.com/goodrain/rainbond/node/nodem/client"
 	"github.com/goodrain/rainbond/util"
 	"github.com/goodrain/rainbond/util/envutil"
+	"github.com/goodrain/rainbond/util/tracing"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
 	"github.com/goodrain/rainbond/worker/appm/volume"
 	"github.com/jinzhu/gorm"
@@ -48,7 +49,7 @@ func TenantServiceVersion(as *v1.AppService, dbmanager db.Manager) error {
 	if err != nil {
 		return fmt.Errorf("get service deploy version %s failure %s", as.DeployVersion, err.Error())
 	}
-	envVarSecrets := as.GetEnvVarSecrets(true)
+	envVarSecrets, fileSecrets := splitConfigGroupSecrets(as.GetEnvVarSecrets(true))
 	logrus.Debugf("[getMainContainer] %d secrets as envs were found.", len(envVarSecrets))
 
 	envs, err := createEnv(as, dbmanager, envVarSecrets)
@@ -60,6 +61,9 @@ func TenantServiceVersion(as *v1.AppService, dbmanager db.Manager) error {
 	if err != nil {
 		return fmt.Errorf("create volume in pod template error :%s", err.Error())
 	}
+	for _, secret := range fileSecrets {
+		dv.SetVolumeSecret(secret, secret.Annotations[ConfigGroupMountPathAnnotation])
+	}
 	container, err := getMainContainer(as, version, dv, envs, envVarSecrets, dbmanager)
 	if err != nil {
 		return fmt.Errorf("conv service main container failure %s", err.Error())
@@ -240,7 +244,7 @@ func createEnv(as *v1.AppService, dbmanager db.Manager, envVarSecrets []*corev1.
 		envs = append(envs, corev1.EnvVar{Name: "DEPEND_SERVICE_COUNT", Value: strconv.Itoa(len(serviceAliases))})
 		envs = append(envs, corev1.EnvVar{Name: "STARTUP_SEQUENCE_DEPENDENCIES", Value: strings.Join(startupSequenceDependencies, ",")})
 
-		if as.GovernanceMode == model.GovernanceModeBuildInServiceMesh {
+		if model.IsBuildInServiceMesh(as.GovernanceMode) {
 			as.NeedProxy = true
 		}
 	}
@@ -325,6 +329,7 @@ func createEnv(as *v1.AppService, dbmanager db.Manager, envVarSecrets []*corev1.
 			FieldPath: "status.podIP",
 		},
 	}})
+	envs = append(envs, createTracingEnvs(as, envs)...)
 	var config = make(map[string]string, len(envs))
 	for _, sec := range envVarSecrets {
 		for k, v := range sec.Data {
@@ -357,6 +362,40 @@ func createEnv(as *v1.AppService, dbmanager db.Manager, envVarSecrets []*corev1.
 	return envs, nil
 }
 
+//createTracingEnvs create the standard opentelemetry sdk envs if the tracing of the application is enabled.
+//the envs that have been defined by the user will not be overwritten.
+func createTracingEnvs(as *v1.AppService, envs []corev1.EnvVar) (re []corev1.EnvVar) {
+	if as.Tracing == nil {
+		return nil
+	}
+	exists := make(map[string]struct{}, len(envs))
+	for _, env := range envs {
+		exists[env.Name] = struct{}{}
+	}
+	add := func(name, value string) {
+		if _, ok := exists[name]; !ok {
+			re = append(re, corev1.EnvVar{Name: name, Value: value})
+		}
+	}
+	add("OTEL_SERVICE_NAME", as.ServiceAlias)
+	add("OTEL_RESOURCE_ATTRIBUTES", fmt.Sprintf("service.namespace=%s,rainbond.app_id=%s,rainbond.service_id=%s", as.TenantID, as.AppID, as.ServiceID))
+	add("OTEL_PROPAGATORS", "tracecontext,baggage,b3")
+	add("OTEL_TRACES_SAMPLER", "parentbased_traceidratio")
+	add("OTEL_TRACES_SAMPLER_ARG", strconv.FormatFloat(float64(as.Tracing.SampleRate)/100, 'f', -1, 64))
+	add("OTEL_METRICS_EXPORTER", "none")
+	add("OTEL_LOGS_EXPORTER", "none")
+	switch as.Tracing.Provider {
+	case tracing.ProviderZipkin:
+		add("OTEL_TRACES_EXPORTER", "zipkin")
+		// the zipkin exporter posts the spans to the endpoint as it is
+		add("OTEL_EXPORTER_ZIPKIN_ENDPOINT", tracing.ZipkinCollectorEndpoint(as.Tracing.Endpoint))
+	default:
+		add("OTEL_TRACES_EXPORTER", "otlp")
+		add("OTEL_EXPORTER_OTLP_ENDPOINT", as.Tracing.Endpoint)
+	}
+	return re
+}
+
 func convertRulesToEnvs(as *v1.AppService, dbmanager db.Manager, ports []*dbmodel.TenantServicesPort) (re []corev1.EnvVar) {
 	defDomain := fmt.Sprintf(".%s.%s.", as.ServiceAlias, as.TenantName)
 	httpRules, _ := dbmanager.HTTPRuleDao().ListByServiceID(as.ServiceID)
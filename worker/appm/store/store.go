@@ -1202,6 +1202,7 @@ func (a *appRuntimeStore) evtEventHandler() cache.ResourceEventHandlerFuncs {
 				Description: evt.Message,
 				Operator:    "system",
 				LastTime:    evt.LastTimestamp.Time,
+				Metric:      scalingRecordMetric(evt),
 			}
 			logrus.Debugf("received add record: %#v", record)
 
@@ -1234,6 +1235,7 @@ func (a *appRuntimeStore) evtEventHandler() cache.ResourceEventHandlerFuncs {
 				Reason:      cevt.Reason,
 				LastTime:    cevt.LastTimestamp.Time,
 				Description: cevt.Message,
+				Metric:      scalingRecordMetric(cevt),
 			}
 			logrus.Debugf("received update record: %#v", record)
 
@@ -1265,6 +1267,14 @@ func (a *appRuntimeStore) nsEventHandler() cache.ResourceEventHandlerFuncs {
 	}
 }
 
+//scalingRecordMetric returns the metric which triggered the autoscaling
+func scalingRecordMetric(evt *corev1.Event) string {
+	if evt.InvolvedObject.Kind != "HorizontalPodAutoscaler" || evt.Reason != "SuccessfulRescale" {
+		return ""
+	}
+	return conversion.ScalingTriggerMetric(evt.Message)
+}
+
 func (a *appRuntimeStore) scalingRecordServiceAndRuleID(evt *corev1.Event) (string, string) {
 	var ruleID, serviceID string
 	switch evt.InvolvedObject.Kind {
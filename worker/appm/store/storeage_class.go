@@ -29,13 +29,22 @@ import (
 //InitStorageclass init storage class
 func (a *appRuntimeStore) initStorageclass() error {
 	for _, storageclass := range v1.GetInitStorageClass() {
-		if _, err := a.conf.KubeClient.StorageV1().StorageClasses().Get(context.Background(), storageclass.Name, metav1.GetOptions{}); err != nil {
+		old, err := a.conf.KubeClient.StorageV1().StorageClasses().Get(context.Background(), storageclass.Name, metav1.GetOptions{})
+		if err != nil {
 			if errors.IsNotFound(err) {
 				_, err = a.conf.KubeClient.StorageV1().StorageClasses().Create(context.Background(), storageclass, metav1.CreateOptions{})
 			}
 			if err != nil {
 				return err
 			}
+			continue
+		}
+		// storage classes created by previous versions do not allow volume expansion
+		if old.AllowVolumeExpansion == nil || !*old.AllowVolumeExpansion {
+			old.AllowVolumeExpansion = storageclass.AllowVolumeExpansion
+			if _, err := a.conf.KubeClient.StorageV1().StorageClasses().Update(context.Background(), old, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -43,21 +43,24 @@ func init() {
 	var volumeBindingImmediate = storagev1.VolumeBindingImmediate
 	var columeWaitForFirstConsumer = storagev1.VolumeBindingWaitForFirstConsumer
 	var Retain = v1.PersistentVolumeReclaimRetain
+	var allowVolumeExpansion = true
 	initStorageClass = append(initStorageClass, &storagev1.StorageClass{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: RainbondStatefuleShareStorageClass,
 		},
-		Provisioner:       "rainbond.io/provisioner-sssc",
-		VolumeBindingMode: &volumeBindingImmediate,
-		ReclaimPolicy:     &Retain,
+		Provisioner:          "rainbond.io/provisioner-sssc",
+		VolumeBindingMode:    &volumeBindingImmediate,
+		ReclaimPolicy:        &Retain,
+		AllowVolumeExpansion: &allowVolumeExpansion,
 	})
 	initStorageClass = append(initStorageClass, &storagev1.StorageClass{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: RainbondStatefuleLocalStorageClass,
 		},
-		Provisioner:       "rainbond.io/provisioner-sslc",
-		VolumeBindingMode: &columeWaitForFirstConsumer,
-		ReclaimPolicy:     &Retain,
+		Provisioner:          "rainbond.io/provisioner-sslc",
+		VolumeBindingMode:    &columeWaitForFirstConsumer,
+		ReclaimPolicy:        &Retain,
+		AllowVolumeExpansion: &allowVolumeExpansion,
 	})
 }
 
@@ -99,6 +99,16 @@ type AppServiceBase struct {
 	Dependces      []string
 	ExtensionSet   map[string]string
 	GovernanceMode string
+	// Tracing is nil if the tracing of the application is disabled
+	Tracing *TracingConfig
+}
+
+//TracingConfig the tracing config of the application which the service belongs to
+type TracingConfig struct {
+	Provider       string
+	Endpoint       string
+	SampleRate     int
+	OpenCensusPort int
 }
 
 //AppService a service of rainbond app state in kubernetes
@@ -19,6 +19,7 @@
 package volume
 
 import (
+	"crypto/md5"
 	"fmt"
 	"os"
 	"path"
@@ -242,6 +243,33 @@ func (v *Define) SetVolumeCMap(cmap *corev1.ConfigMap, k, p string, isReadOnly b
 	v.volumes = append(v.volumes, vo)
 }
 
+// SetVolumeSecret mounts all the keys of the secret as files in the directory p
+func (v *Define) SetVolumeSecret(secret *corev1.Secret, p string) {
+	for _, m := range v.volumeMounts {
+		if m.MountPath == p {
+			logrus.Warningf("mount path %s of secret %s is used by another volume, skip it", p, secret.Name)
+			return
+		}
+	}
+	// the name of secret may be longer than the limit of volume name
+	name := fmt.Sprintf("secret-%x", md5.Sum([]byte(secret.Name)))[:23]
+	v.volumeMounts = append(v.volumeMounts, corev1.VolumeMount{
+		MountPath: p,
+		Name:      name,
+		ReadOnly:  true,
+	})
+	var defaultMode int32 = 0644
+	v.volumes = append(v.volumes, corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  secret.Name,
+				DefaultMode: &defaultMode,
+			},
+		},
+	})
+}
+
 func convertRulesToEnvs(as *v1.AppService, dbmanager db.Manager, ports []*dbmodel.TenantServicesPort) (re []corev1.EnvVar) {
 	defDomain := fmt.Sprintf(".%s.%s.", as.ServiceAlias, as.TenantName)
 	httpRules, _ := dbmanager.HTTPRuleDao().ListByServiceID(as.ServiceID)
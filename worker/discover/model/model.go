@@ -165,6 +165,13 @@ func NewTaskBody(taskType string, body []byte) TaskBody {
 			return nil
 		}
 		return b
+	case "resize_volume":
+		b := &ResizeVolumeTaskBody{}
+		err := ffjson.Unmarshal(body, &b)
+		if err != nil {
+			return nil
+		}
+		return b
 	default:
 		return DefaultTaskBody{}
 	}
@@ -197,6 +204,8 @@ func CreateTaskBody(taskType string) TaskBody {
 		return DeleteTenantTaskBody{}
 	case "refreshhpa":
 		return RefreshHPATaskBody{}
+	case "resize_volume":
+		return ResizeVolumeTaskBody{}
 	default:
 		return DefaultTaskBody{}
 	}
@@ -232,6 +241,8 @@ type HorizontalScalingTaskBody struct {
 	Replicas  int32  `json:"replicas"`
 	EventID   string `json:"event_id"`
 	Username  string `json:"username"`
+	//RecordType the type of scaling record, default is manual
+	RecordType string `json:"record_type,omitempty"`
 }
 
 //VerticalScalingTaskBody 垂直伸缩操作任务主体
@@ -358,5 +369,16 @@ type RefreshHPATaskBody struct {
 	EventID   string `json:"eventID"`
 }
 
+// ResizeVolumeTaskBody holds the request body to resize a component volume.
+type ResizeVolumeTaskBody struct {
+	TenantID   string `json:"tenant_id"`
+	ServiceID  string `json:"service_id"`
+	VolumeID   int    `json:"volume_id"`
+	VolumeName string `json:"volume_name"`
+	// Capacity the new capacity of the volume, in GiB
+	Capacity int64  `json:"capacity"`
+	EventID  string `json:"event_id"`
+}
+
 //DefaultTaskBody 默认操作任务主体
 type DefaultTaskBody map[string]interface{}
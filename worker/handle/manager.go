@@ -130,6 +130,9 @@ func (m *Manager) AnalystToExec(task *model.Task) error {
 	case "refreshhpa":
 		logrus.Info("start a 'refreshhpa' task worker")
 		return m.ExecRefreshHPATask(task)
+	case "resize_volume":
+		logrus.Info("start a 'resize_volume' task worker")
+		return m.ExecResizeVolumeTask(task)
 	default:
 		logrus.Warning("task can not execute because no type is identified")
 		return nil
@@ -261,10 +264,14 @@ func (m *Manager) horizontalScalingExec(task *model.Task) (err error) {
 			desc = fmt.Sprintf(desc, oldReplicas, newReplicas, err)
 			reason = "FailedRescale"
 		}
+		recordType := body.RecordType
+		if recordType == "" {
+			recordType = "manual"
+		}
 		scalingRecord := &dbmodel.TenantServiceScalingRecords{
 			ServiceID:   body.ServiceID,
 			EventName:   util.NewUUID(),
-			RecordType:  "manual",
+			RecordType:  recordType,
 			Reason:      reason,
 			Count:       1,
 			Description: desc,
@@ -577,3 +584,41 @@ func (m *Manager) ExecRefreshHPATask(task *model.Task) error {
 	logrus.Infof("rule id: %s; successfully refresh hpa", body.RuleID)
 	return nil
 }
+
+// ExecResizeVolumeTask executes a 'resize volume' task.
+func (m *Manager) ExecResizeVolumeTask(task *model.Task) error {
+	body, ok := task.Body.(*model.ResizeVolumeTaskBody)
+	if !ok {
+		logrus.Errorf("exec task 'resize_volume'; wrong type: %v", reflect.TypeOf(task))
+		return fmt.Errorf("exec task 'resize_volume': wrong input")
+	}
+
+	logger := event.GetManager().GetLogger(body.EventID)
+	volume, err := m.dbmanager.TenantServiceVolumeDao().GetVolumeByID(body.VolumeID)
+	if err != nil {
+		logrus.Errorf("get volume %d: %v", body.VolumeID, err)
+		logger.Error("Get volume info failure", event.GetCallbackLoggerOption())
+		event.GetManager().ReleaseLogger(logger)
+		return fmt.Errorf("resize volume: %v", err)
+	}
+
+	appService := m.store.GetAppService(body.ServiceID)
+	if appService == nil || appService.IsClosed() {
+		// no claims exist yet, they are created with the new capacity on the next start.
+		volume.VolumeCapacity = body.Capacity
+		if err := m.dbmanager.TenantServiceVolumeDao().UpdateModel(volume); err != nil {
+			logrus.Errorf("update capacity of volume %d: %v", body.VolumeID, err)
+			logger.Error("Update volume capacity failure", event.GetCallbackLoggerOption())
+			event.GetManager().ReleaseLogger(logger)
+			return fmt.Errorf("resize volume: %v", err)
+		}
+		logger.Info("service is closed, the new capacity takes effect on next start", event.GetLastLoggerOption())
+		event.GetManager().ReleaseLogger(logger)
+		return nil
+	}
+
+	appService.Logger = logger
+	m.controllerManager.StartResizeVolumeController(*appService, volume, body.Capacity)
+	logrus.Infof("service(%s) %s working is running.", body.ServiceID, "resize volume")
+	return nil
+}
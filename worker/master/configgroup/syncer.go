@@ -0,0 +1,184 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package configgroup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/worker/appm/conversion"
+	"github.com/goodrain/rainbond/worker/appm/store"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	"github.com/sirupsen/logrus"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+//RestartedAtAnnotation the annotation of pod template changed to rolling restart the components
+const RestartedAtAnnotation = "configgroup.rainbond.io/restartedAt"
+
+var (
+	checkInterval      = 10 * time.Second
+	minRefreshInterval = 10 * time.Second
+)
+
+//appServiceGetter gets the app services of the running components, it is implemented by store.Storer
+type appServiceGetter interface {
+	GetAppService(serviceID string) *v1.AppService
+}
+
+//Syncer syncs the secrets of the config groups read from external sources such as vault, it should run on the leader only.
+//The secret is updated when the values of source change, the secrets not created yet are left to the components starting.
+//The values are written as they are, the variables in them are not parsed until the components are upgraded.
+type Syncer struct {
+	dbmanager  db.Manager
+	kubeClient kubernetes.Interface
+	store      appServiceGetter
+	period     time.Duration
+	lastSyncs  map[string]time.Time
+}
+
+//NewSyncer new syncer, period is the default refresh interval of config groups
+func NewSyncer(dbmanager db.Manager, kubeClient kubernetes.Interface, store store.Storer, period time.Duration) *Syncer {
+	return &Syncer{
+		dbmanager:  dbmanager,
+		kubeClient: kubeClient,
+		store:      store,
+		period:     period,
+		lastSyncs:  make(map[string]time.Time),
+	}
+}
+
+//Run syncs the config groups until the ctx is done
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.syncAll(ctx, now)
+		}
+	}
+}
+
+func (s *Syncer) syncAll(ctx context.Context, now time.Time) {
+	groups, err := s.dbmanager.AppConfigGroupDao().ListEnableBySourceType(dbmodel.ConfigGroupSourceVault)
+	if err != nil {
+		logrus.Errorf("list config groups of vault: %v", err)
+		return
+	}
+	for _, group := range groups {
+		key := group.AppID + "/" + group.ConfigGroupName
+		if last, ok := s.lastSyncs[key]; ok && now.Sub(last) < s.refreshInterval(group) {
+			continue
+		}
+		s.lastSyncs[key] = now
+		if err := s.sync(ctx, group); err != nil {
+			logrus.Warningf("sync config group %s of app %s: %v", group.ConfigGroupName, group.AppID, err)
+		}
+	}
+}
+
+func (s *Syncer) refreshInterval(group *dbmodel.ApplicationConfigGroup) time.Duration {
+	interval := s.period
+	if group.RefreshInterval > 0 {
+		interval = time.Duration(group.RefreshInterval) * time.Second
+	}
+	if interval < minRefreshInterval {
+		interval = minRefreshInterval
+	}
+	return interval
+}
+
+func (s *Syncer) sync(ctx context.Context, group *dbmodel.ApplicationConfigGroup) error {
+	app, err := s.dbmanager.ApplicationDao().GetAppByID(group.AppID)
+	if err != nil {
+		return fmt.Errorf("get app: %v", err)
+	}
+	data, err := conversion.ConfigGroupData(ctx, group, s.dbmanager)
+	if err != nil {
+		return err
+	}
+	checksum := conversion.ConfigGroupChecksum(data)
+
+	secrets := s.kubeClient.CoreV1().Secrets(app.TenantID)
+	secret, err := secrets.Get(ctx, conversion.ConfigGroupSecretName(group), metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get secret: %v", err)
+	}
+	if secret.Annotations[conversion.ConfigGroupChecksumAnnotation] == checksum {
+		return nil
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	secret.Annotations[conversion.ConfigGroupChecksumAnnotation] = checksum
+	secret.Data = data
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update secret: %v", err)
+	}
+	logrus.Infof("the values of config group %s of app %s changed, secret %s updated", group.ConfigGroupName, group.AppID, secret.Name)
+
+	if !group.RestartOnChange {
+		return nil
+	}
+	services, err := s.dbmanager.AppConfigGroupServiceDao().GetConfigGroupServicesByID(group.AppID, group.ConfigGroupName)
+	if err != nil {
+		return fmt.Errorf("list services: %v", err)
+	}
+	for _, service := range services {
+		if err := s.restart(ctx, service.ServiceID); err != nil {
+			logrus.Warningf("rolling restart component %s for config group %s: %v", service.ServiceID, group.ConfigGroupName, err)
+		}
+	}
+	return nil
+}
+
+//restart rolling restarts the component by changing the annotation of pod template, the closed components are skipped
+func (s *Syncer) restart(ctx context.Context, serviceID string) error {
+	as := s.store.GetAppService(serviceID)
+	if as == nil {
+		return nil
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		RestartedAtAnnotation, time.Now().Format(time.RFC3339)))
+	if deployment := as.GetDeployment(); deployment != nil {
+		if _, err := s.kubeClient.AppsV1().Deployments(deployment.Namespace).Patch(ctx, deployment.Name,
+			types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return err
+		}
+	}
+	if statefulset := as.GetStatefulSet(); statefulset != nil {
+		if _, err := s.kubeClient.AppsV1().StatefulSets(statefulset.Namespace).Patch(ctx, statefulset.Name,
+			types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
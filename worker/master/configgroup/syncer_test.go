@@ -0,0 +1,154 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package configgroup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/dao"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util/vault"
+	"github.com/goodrain/rainbond/worker/appm/conversion"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeStore map[string]*v1.AppService
+
+func (f fakeStore) GetAppService(serviceID string) *v1.AppService {
+	return f[serviceID]
+}
+
+func TestSyncer(t *testing.T) {
+	var version int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&version) == 1 {
+			w.Write([]byte(`{"data":{"data":{"password":"v1"},"metadata":{"version":1}}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"password":"v2"},"metadata":{"version":2}}}`))
+	}))
+	defer server.Close()
+	conversion.SetVaultClient(vault.NewClient(server.URL, "root", ""))
+	defer conversion.SetVaultClient(nil)
+
+	group := &dbmodel.ApplicationConfigGroup{AppID: "appid", ConfigGroupName: "db", SourceType: "vault",
+		SourcePath: "secret/app/db", RefreshInterval: 30, RestartOnChange: true}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dbm := db.NewMockManager(ctrl)
+	groupDao := dao.NewMockAppConfigGroupDao(ctrl)
+	groupDao.EXPECT().ListEnableBySourceType("vault").Return([]*dbmodel.ApplicationConfigGroup{group}, nil).AnyTimes()
+	dbm.EXPECT().AppConfigGroupDao().Return(groupDao).AnyTimes()
+	appDao := dao.NewMockApplicationDao(ctrl)
+	appDao.EXPECT().GetAppByID("appid").Return(&dbmodel.Application{AppID: "appid", TenantID: "tid"}, nil).AnyTimes()
+	dbm.EXPECT().ApplicationDao().Return(appDao).AnyTimes()
+	serviceDao := dao.NewMockAppConfigGroupServiceDao(ctrl)
+	serviceDao.EXPECT().GetConfigGroupServicesByID("appid", "db").Return([]*dbmodel.ConfigGroupService{{ServiceID: "sid"}}, nil).AnyTimes()
+	dbm.EXPECT().AppConfigGroupServiceDao().Return(serviceDao).AnyTimes()
+
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "sid-deployment", Namespace: "tid"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	as := &v1.AppService{}
+	as.SetDeployment(deployment)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-appid", Namespace: "tid"},
+		Data:       map[string][]byte{"password": []byte("v1")},
+	}
+	kubeClient := fake.NewSimpleClientset(secret, deployment)
+	s := NewSyncer(dbm, kubeClient, nil, time.Minute)
+	s.store = fakeStore{"sid": as}
+	ctx := context.Background()
+	getSecret := func() *corev1.Secret {
+		secret, err := kubeClient.CoreV1().Secrets("tid").Get(ctx, "db-appid", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+	restartedAt := func() string {
+		deployment, err := kubeClient.AppsV1().Deployments("tid").Get(ctx, "sid-deployment", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return deployment.Spec.Template.Annotations[RestartedAtAnnotation]
+	}
+
+	// the secret created by an old worker has no checksum
+	now := time.Now()
+	s.syncAll(ctx, now)
+	if got := getSecret().Annotations[conversion.ConfigGroupChecksumAnnotation]; got == "" {
+		t.Fatal("expected the checksum annotated")
+	}
+	if restartedAt() == "" {
+		t.Fatal("expected the component restarted")
+	}
+
+	// not due
+	atomic.StoreInt32(&version, 2)
+	s.syncAll(ctx, now.Add(10*time.Second))
+	if got := string(getSecret().Data["password"]); got != "v1" {
+		t.Errorf("expected v1 before the refresh interval, but got %s", got)
+	}
+
+	s.syncAll(ctx, now.Add(30*time.Second))
+	if got := string(getSecret().Data["password"]); got != "v2" {
+		t.Errorf("expected v2 synced, but got %s", got)
+	}
+}
+
+func TestSyncerSecretNotCreated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"data":{"password":"v1"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+	conversion.SetVaultClient(vault.NewClient(server.URL, "root", ""))
+	defer conversion.SetVaultClient(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dbm := db.NewMockManager(ctrl)
+	appDao := dao.NewMockApplicationDao(ctrl)
+	appDao.EXPECT().GetAppByID("appid").Return(&dbmodel.Application{AppID: "appid", TenantID: "tid"}, nil)
+	dbm.EXPECT().ApplicationDao().Return(appDao)
+
+	kubeClient := fake.NewSimpleClientset()
+	s := NewSyncer(dbm, kubeClient, nil, time.Minute)
+	group := &dbmodel.ApplicationConfigGroup{AppID: "appid", ConfigGroupName: "db", SourceType: "vault", SourcePath: "secret/app/db"}
+	if err := s.sync(context.Background(), group); err != nil {
+		t.Fatal(err)
+	}
+	if secrets, _ := kubeClient.CoreV1().Secrets("tid").List(context.Background(), metav1.ListOptions{}); len(secrets.Items) != 0 {
+		t.Error("expected the secret left to the components starting")
+	}
+}
@@ -26,10 +26,13 @@ import (
 
 	"github.com/goodrain/rainbond/cmd/worker/option"
 	"github.com/goodrain/rainbond/db"
-	"github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/mq/client"
+	etcdutil "github.com/goodrain/rainbond/util/etcd"
 	"github.com/goodrain/rainbond/util/leader"
 	"github.com/goodrain/rainbond/worker/appm/store"
+	"github.com/goodrain/rainbond/worker/master/configgroup"
 	"github.com/goodrain/rainbond/worker/master/podevent"
+	"github.com/goodrain/rainbond/worker/master/scaling"
 	"github.com/goodrain/rainbond/worker/master/volumes/provider"
 	"github.com/goodrain/rainbond/worker/master/volumes/provider/lib/controller"
 	"github.com/goodrain/rainbond/worker/master/volumes/statistical"
@@ -146,7 +149,7 @@ func NewMasterController(conf option.Config, kubecfg *rest.Config, store store.S
 			Name:      "cpu_limit",
 			Help:      "total cpu limit in namespace",
 		}, []string{"namespace"}),
-		diskCache:       statistical.CreatDiskCache(ctx),
+		diskCache:       statistical.CreatDiskCache(ctx, kubeClient, conf.VolumeUsageThresholds),
 		podEvent:        podevent.New(conf.KubeClient, stopCh),
 		volumeTypeEvent: sync.New(stopCh),
 		kubeClient:      kubeClient,
@@ -184,6 +187,22 @@ func (m *Controller) Start() error {
 		defer m.store.UnRegisterVolumeTypeListener("volumeTypeEvent")
 		go m.volumeTypeEvent.Handle()
 
+		mqclient, err := client.NewMqClient(&etcdutil.ClientArgs{
+			Endpoints: m.conf.EtcdEndPoints,
+			CaFile:    m.conf.EtcdCaFile,
+			CertFile:  m.conf.EtcdCertFile,
+			KeyFile:   m.conf.EtcdKeyFile,
+		}, m.conf.MQAPI)
+		if err != nil {
+			logrus.Errorf("new mq client for scaling scheduler: %v", err)
+		} else {
+			defer mqclient.Close()
+			go scaling.NewScheduler(m.dbmanager, mqclient).Run(ctx)
+		}
+		if m.conf.VaultAddr != "" {
+			go configgroup.NewSyncer(m.dbmanager, m.kubeClient, m.store, m.conf.ConfigGroupSyncPeriod).Run(ctx)
+		}
+
 		select {
 		case <-ctx.Done():
 		case <-m.ctx.Done():
@@ -248,11 +267,12 @@ func (m *Controller) Scrape(ch chan<- prometheus.Metric, scrapeDurationDesc *pro
 	}
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), "collect.memory")
 	scrapeTime = time.Now()
-	diskcache := m.diskCache.Get()
-	for k, v := range diskcache {
-		key := strings.Split(k, "_")
-		if len(key) == 3 {
-			m.fsUse.WithLabelValues(key[2], key[1], key[0], string(model.ShareFileVolumeType)).Set(v)
+	for volumeType, diskcache := range m.diskCache.GetByVolumeType() {
+		for k, v := range diskcache {
+			key := strings.Split(k, "_")
+			if len(key) == 3 {
+				m.fsUse.WithLabelValues(key[2], key[1], key[0], volumeType).Set(v)
+			}
 		}
 	}
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), "collect.fs")
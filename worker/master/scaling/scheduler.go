@@ -0,0 +1,261 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/mq/client"
+	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/cron"
+	"github.com/goodrain/rainbond/worker/discover/model"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RecordType the record type of the scaling executed by the schedules
+	RecordType = "schedule"
+	operator   = "system"
+)
+
+var (
+	checkInterval = 30 * time.Second
+	// the activations missed longer than catchUpWindow, when the worker is down for example, are ignored
+	catchUpWindow = 7 * 24 * time.Hour
+)
+
+//Scheduler executes the scheduled scaling rules of components, it should run on the leader only.
+//The replicas of component are changed by the horizontal_scaling task, if the component has
+//enabled autoscaler rules, the min replicas of the rules are adjusted instead of fighting with the hpa.
+type Scheduler struct {
+	dbmanager db.Manager
+	mqclient  client.MQClient
+}
+
+//NewScheduler new scheduler
+func NewScheduler(dbmanager db.Manager, mqclient client.MQClient) *Scheduler {
+	return &Scheduler{dbmanager: dbmanager, mqclient: mqclient}
+}
+
+//Run executes the schedules until the ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.runSchedules(now)
+		}
+	}
+}
+
+type dueSchedule struct {
+	schedule *dbmodel.TenantServiceScalingSchedule
+	due      time.Time
+}
+
+func (s *Scheduler) runSchedules(now time.Time) {
+	schedules, err := s.dbmanager.TenantServiceScalingScheduleDao().ListEnableOnes()
+	if err != nil {
+		logrus.Errorf("list scaling schedules: %v", err)
+		return
+	}
+	var dues []dueSchedule
+	for _, schedule := range schedules {
+		due, err := lastActivation(schedule, now)
+		if err != nil {
+			logrus.Warningf("scaling schedule %s is invalid: %v", schedule.ScheduleID, err)
+			continue
+		}
+		if !due.IsZero() {
+			dues = append(dues, dueSchedule{schedule: schedule, due: due})
+		}
+	}
+	// the latest activation of a component wins if several schedules are due
+	sort.SliceStable(dues, func(i, j int) bool {
+		return dues[i].due.Before(dues[j].due)
+	})
+	services := make(map[string][]dueSchedule)
+	var order []string
+	for _, d := range dues {
+		if _, ok := services[d.schedule.ServiceID]; !ok {
+			order = append(order, d.schedule.ServiceID)
+		}
+		services[d.schedule.ServiceID] = append(services[d.schedule.ServiceID], d)
+	}
+	for _, serviceID := range order {
+		ds := services[serviceID]
+		latest := ds[len(ds)-1].schedule
+		// the schedules are retried in the next check if the scaling fails
+		if err := s.execute(latest); err != nil {
+			logrus.Errorf("execute scaling schedule %s: %v", latest.ScheduleID, err)
+			continue
+		}
+		// the earlier activations are overridden by the latest one
+		for _, d := range ds {
+			d.schedule.LastScheduleTime = &now
+			if err := s.dbmanager.TenantServiceScalingScheduleDao().UpdateModel(d.schedule); err != nil {
+				logrus.Errorf("update last schedule time of scaling schedule %s: %v", d.schedule.ScheduleID, err)
+			}
+		}
+	}
+}
+
+//lastActivation returns the latest activation of the schedule between the last schedule time and now,
+//the activations are computed in the timezone of schedule. It returns the zero time if nothing is due.
+func lastActivation(schedule *dbmodel.TenantServiceScalingSchedule, now time.Time) (time.Time, error) {
+	sched, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc := time.UTC
+	if schedule.Timezone != "" {
+		if loc, err = time.LoadLocation(schedule.Timezone); err != nil {
+			return time.Time{}, err
+		}
+	}
+	last := schedule.CreatedAt
+	if schedule.LastScheduleTime != nil {
+		last = *schedule.LastScheduleTime
+	}
+	if last.Before(now.Add(-catchUpWindow)) {
+		last = now.Add(-catchUpWindow)
+	}
+	var due time.Time
+	for next := sched.Next(last.In(loc)); !next.IsZero() && !next.After(now); next = sched.Next(next) {
+		due = next
+	}
+	return due, nil
+}
+
+//adjustRule sets the min replicas of autoscaler rule to the replicas,
+//the max replicas is raised if it is less than the replicas.
+func adjustRule(rule *dbmodel.TenantServiceAutoscalerRules, replicas int) {
+	rule.MinReplicas = replicas
+	if rule.MaxReplicas < replicas {
+		rule.MaxReplicas = replicas
+	}
+}
+
+func (s *Scheduler) execute(schedule *dbmodel.TenantServiceScalingSchedule) error {
+	rules, err := s.dbmanager.TenantServceAutoscalerRulesDao().ListEnableOnesByServiceID(schedule.ServiceID)
+	if err != nil {
+		return fmt.Errorf("list autoscaler rules: %v", err)
+	}
+	if len(rules) > 0 {
+		var failed int
+		for _, rule := range rules {
+			if err := s.adjustHPA(schedule, rule); err != nil {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("adjust %d of %d autoscaler rules failure", failed, len(rules))
+		}
+		return nil
+	}
+	service, err := s.dbmanager.TenantServiceDao().GetServiceByID(schedule.ServiceID)
+	if err != nil {
+		return fmt.Errorf("get component: %v", err)
+	}
+	if service.Replicas == schedule.Replicas {
+		logrus.Debugf("the replicas of component %s is %d already", service.ServiceID, service.Replicas)
+		return nil
+	}
+	oldReplicas := service.Replicas
+	service.Replicas = schedule.Replicas
+	if err := s.dbmanager.TenantServiceDao().UpdateModel(service); err != nil {
+		return fmt.Errorf("update replicas of component: %v", err)
+	}
+	err = s.mqclient.SendBuilderTopic(client.TaskStruct{
+		TaskType: "horizontal_scaling",
+		TaskBody: model.HorizontalScalingTaskBody{
+			TenantID:   service.TenantID,
+			ServiceID:  service.ServiceID,
+			Replicas:   int32(schedule.Replicas),
+			EventID:    util.NewUUID(),
+			Username:   operator,
+			RecordType: RecordType,
+		},
+		Topic: client.WorkerTopic,
+	})
+	if err != nil {
+		service.Replicas = oldReplicas
+		_ = s.dbmanager.TenantServiceDao().UpdateModel(service)
+		return fmt.Errorf("send horizontal_scaling task: %v", err)
+	}
+	logrus.Infof("scaling schedule %s scales component %s from %d to %d", schedule.ScheduleID, service.ServiceID, oldReplicas, schedule.Replicas)
+	return nil
+}
+
+func (s *Scheduler) adjustHPA(schedule *dbmodel.TenantServiceScalingSchedule, rule *dbmodel.TenantServiceAutoscalerRules) error {
+	oldMin, oldMax := rule.MinReplicas, rule.MaxReplicas
+	adjustRule(rule, schedule.Replicas)
+	if rule.MinReplicas == oldMin && rule.MaxReplicas == oldMax {
+		return nil
+	}
+	err := s.dbmanager.TenantServceAutoscalerRulesDao().UpdateModel(rule)
+	if err == nil {
+		err = s.mqclient.SendBuilderTopic(client.TaskStruct{
+			TaskType: "refreshhpa",
+			TaskBody: map[string]interface{}{
+				"service_id": rule.ServiceID,
+				"rule_id":    rule.RuleID,
+			},
+			Topic: client.WorkerTopic,
+		})
+		if err != nil {
+			// the rule is adjusted again when the schedule is retried
+			newMin, newMax := rule.MinReplicas, rule.MaxReplicas
+			rule.MinReplicas, rule.MaxReplicas = oldMin, oldMax
+			_ = s.dbmanager.TenantServceAutoscalerRulesDao().UpdateModel(rule)
+			rule.MinReplicas, rule.MaxReplicas = newMin, newMax
+		}
+	}
+	desc := fmt.Sprintf("the replicas range of autoscaler rule is adjusted from [%d, %d] to [%d, %d] by schedule %s",
+		oldMin, oldMax, rule.MinReplicas, rule.MaxReplicas, schedule.Cron)
+	reason := "SuccessfulRescale"
+	if err != nil {
+		desc = fmt.Sprintf("%s: %v", desc, err)
+		reason = "FailedRescale"
+		logrus.Errorf("adjust autoscaler rule %s: %v", rule.RuleID, err)
+	}
+	record := &dbmodel.TenantServiceScalingRecords{
+		ServiceID:   rule.ServiceID,
+		RuleID:      rule.RuleID,
+		EventName:   util.NewUUID(),
+		RecordType:  RecordType,
+		Reason:      reason,
+		Count:       1,
+		Description: desc,
+		Operator:    operator,
+		LastTime:    time.Now(),
+	}
+	if err := s.dbmanager.TenantServiceScalingRecordsDao().AddModel(record); err != nil {
+		logrus.Warningf("save scaling record: %v", err)
+	}
+	return err
+}
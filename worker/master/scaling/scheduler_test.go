@@ -0,0 +1,166 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scaling
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/dao"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+func TestLastActivation(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("timezone database is not available: %v", err)
+	}
+	created := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		cron     string
+		timezone string
+		last     *time.Time
+		now      time.Time
+		want     time.Time
+	}{
+		{
+			name:     "weekdays morning in timezone",
+			cron:     "0 8 * * 1-5",
+			timezone: "Asia/Shanghai",
+			now:      time.Date(2021, 3, 2, 0, 30, 0, 0, time.UTC),
+			want:     time.Date(2021, 3, 2, 8, 0, 0, 0, shanghai),
+		},
+		{
+			name: "not due",
+			cron: "0 20 * * *",
+			now:  time.Date(2021, 3, 1, 19, 59, 0, 0, time.UTC),
+		},
+		{
+			name: "latest of missed activations",
+			cron: "0 20 * * *",
+			now:  time.Date(2021, 3, 4, 8, 0, 0, 0, time.UTC),
+			want: time.Date(2021, 3, 3, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "executed already",
+			cron: "0 20 * * *",
+			last: timePtr(time.Date(2021, 3, 3, 20, 0, 10, 0, time.UTC)),
+			now:  time.Date(2021, 3, 4, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekend is skipped",
+			cron: "0 8 * * 1-5",
+			last: timePtr(time.Date(2021, 3, 5, 8, 0, 10, 0, time.UTC)),
+			now:  time.Date(2021, 3, 7, 23, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule := &dbmodel.TenantServiceScalingSchedule{
+				Cron:             tc.cron,
+				Timezone:         tc.timezone,
+				LastScheduleTime: tc.last,
+			}
+			schedule.CreatedAt = created
+			got, err := lastActivation(schedule, tc.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLastActivationInvalid(t *testing.T) {
+	for _, schedule := range []*dbmodel.TenantServiceScalingSchedule{
+		{Cron: "0 25 * * *"},
+		{Cron: "0 8 * * *", Timezone: "Mars/Olympus"},
+	} {
+		if _, err := lastActivation(schedule, time.Now()); err == nil {
+			t.Errorf("expect error of schedule %s %s", schedule.Cron, schedule.Timezone)
+		}
+	}
+}
+
+func TestAdjustRule(t *testing.T) {
+	tests := []struct {
+		min, max, replicas int
+		wantMin, wantMax   int
+	}{
+		{min: 2, max: 10, replicas: 6, wantMin: 6, wantMax: 10},
+		{min: 6, max: 10, replicas: 2, wantMin: 2, wantMax: 10},
+		{min: 2, max: 4, replicas: 6, wantMin: 6, wantMax: 6},
+	}
+	for _, tc := range tests {
+		rule := &dbmodel.TenantServiceAutoscalerRules{MinReplicas: tc.min, MaxReplicas: tc.max}
+		adjustRule(rule, tc.replicas)
+		if rule.MinReplicas != tc.wantMin || rule.MaxReplicas != tc.wantMax {
+			t.Errorf("adjust [%d, %d] to %d: want [%d, %d], got [%d, %d]", tc.min, tc.max, tc.replicas,
+				tc.wantMin, tc.wantMax, rule.MinReplicas, rule.MaxReplicas)
+		}
+	}
+}
+
+func TestRunSchedules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := time.Date(2021, 3, 4, 8, 0, 0, 0, time.UTC)
+	newSchedule := func(id, serviceID, cron string) *dbmodel.TenantServiceScalingSchedule {
+		schedule := &dbmodel.TenantServiceScalingSchedule{ScheduleID: id, ServiceID: serviceID, Cron: cron, Replicas: 2}
+		schedule.CreatedAt = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		return schedule
+	}
+	failed := newSchedule("failed", "s1", "0 20 * * *")
+	earlier := newSchedule("earlier", "s2", "0 6 * * *")
+	latest := newSchedule("latest", "s2", "0 7 * * *")
+
+	dbmanager := db.NewMockManager(ctrl)
+	scheduleDao := dao.NewMockTenantServiceScalingScheduleDao(ctrl)
+	ruleDao := dao.NewMockTenantServceAutoscalerRulesDao(ctrl)
+	serviceDao := dao.NewMockTenantServiceDao(ctrl)
+	dbmanager.EXPECT().TenantServiceScalingScheduleDao().Return(scheduleDao).AnyTimes()
+	dbmanager.EXPECT().TenantServceAutoscalerRulesDao().Return(ruleDao).AnyTimes()
+	dbmanager.EXPECT().TenantServiceDao().Return(serviceDao).AnyTimes()
+	scheduleDao.EXPECT().ListEnableOnes().Return([]*dbmodel.TenantServiceScalingSchedule{failed, earlier, latest}, nil)
+	ruleDao.EXPECT().ListEnableOnesByServiceID("s1").Return(nil, errors.New("db is down"))
+	ruleDao.EXPECT().ListEnableOnesByServiceID("s2").Return(nil, nil)
+	// the replicas is the same as the latest schedule, nothing to scale
+	serviceDao.EXPECT().GetServiceByID("s2").Return(&dbmodel.TenantServices{ServiceID: "s2", Replicas: 2}, nil)
+	// only the schedules of the succeeded scaling are advanced
+	scheduleDao.EXPECT().UpdateModel(earlier).Return(nil)
+	scheduleDao.EXPECT().UpdateModel(latest).Return(nil)
+
+	NewScheduler(dbmanager, nil).runSchedules(now)
+	if failed.LastScheduleTime != nil {
+		t.Errorf("the failed schedule should be retried, got last schedule time %v", failed.LastScheduleTime)
+	}
+	if latest.LastScheduleTime == nil || !latest.LastScheduleTime.Equal(now) || earlier.LastScheduleTime == nil {
+		t.Errorf("the executed schedules should be advanced")
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -782,6 +782,11 @@ func (ctrl *ProvisionController) syncClaim(obj interface{}) error {
 		ctrl.updateProvisionStats(claim, err, startTime)
 		return err
 	}
+	if ctrl.shouldExpand(claim) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
+		defer cancel()
+		return ctrl.expandClaimOperation(ctx, claim)
+	}
 	return nil
 }
 
@@ -828,6 +833,74 @@ func (ctrl *ProvisionController) shouldProvision(claim *v1.PersistentVolumeClaim
 	return false
 }
 
+// shouldExpand returns whether a bound claim requests more storage than it
+// has and its provisioner is able to expand the volume in place.
+func (ctrl *ProvisionController) shouldExpand(claim *v1.PersistentVolumeClaim) bool {
+	if claim.Spec.VolumeName == "" || claim.Status.Phase != v1.ClaimBound {
+		return false
+	}
+	pr, ok := ctrl.provisioners[claim.Annotations[annStorageProvisioner]]
+	if !ok {
+		return false
+	}
+	if _, ok := pr.(Expander); !ok {
+		return false
+	}
+	return claimNeedsExpansion(claim)
+}
+
+func claimNeedsExpansion(claim *v1.PersistentVolumeClaim) bool {
+	request, ok := claim.Spec.Resources.Requests[v1.ResourceStorage]
+	if !ok {
+		return false
+	}
+	capacity, ok := claim.Status.Capacity[v1.ResourceStorage]
+	if !ok {
+		return false
+	}
+	return request.Cmp(capacity) > 0
+}
+
+// expandClaimOperation expands the volume bound to the claim, then records the
+// new size on the volume and the claim status.
+func (ctrl *ProvisionController) expandClaimOperation(ctx context.Context, claim *v1.PersistentVolumeClaim) error {
+	expander := ctrl.provisioners[claim.Annotations[annStorageProvisioner]].(Expander)
+	newSize := claim.Spec.Resources.Requests[v1.ResourceStorage]
+	volume, err := ctrl.client.CoreV1().PersistentVolumes().Get(ctx, claim.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	ctrl.eventRecorder.Event(claim, v1.EventTypeNormal, "Resizing", fmt.Sprintf("External resizer is resizing volume %s", volume.Name))
+	if err := expander.Expand(volume, newSize); err != nil {
+		ctrl.eventRecorder.Event(claim, v1.EventTypeWarning, "VolumeResizeFailed", err.Error())
+		return err
+	}
+
+	if capacity := volume.Spec.Capacity[v1.ResourceStorage]; capacity.Cmp(newSize) < 0 {
+		newVolume := volume.DeepCopy()
+		if newVolume.Spec.Capacity == nil {
+			newVolume.Spec.Capacity = v1.ResourceList{}
+		}
+		newVolume.Spec.Capacity[v1.ResourceStorage] = newSize
+		if _, err := ctrl.client.CoreV1().PersistentVolumes().Update(ctx, newVolume, metav1.UpdateOptions{}); err != nil {
+			ctrl.eventRecorder.Event(claim, v1.EventTypeWarning, "VolumeResizeFailed", err.Error())
+			return err
+		}
+	}
+
+	newClaim := claim.DeepCopy()
+	newClaim.Status.Capacity[v1.ResourceStorage] = newSize
+	newClaim.Status.Conditions = nil
+	if _, err := ctrl.client.CoreV1().PersistentVolumeClaims(claim.Namespace).UpdateStatus(ctx, newClaim, metav1.UpdateOptions{}); err != nil {
+		ctrl.eventRecorder.Event(claim, v1.EventTypeWarning, "VolumeResizeFailed", err.Error())
+		return err
+	}
+	ctrl.eventRecorder.Event(claim, v1.EventTypeNormal, "VolumeResizeSuccessful", fmt.Sprintf("Volume %s is resized to %s", volume.Name, newSize.String()))
+	logrus.Infof("volume %q for claim %q resized to %s", volume.Name, claimToClaimKey(claim), newSize.String())
+	return nil
+}
+
 // shouldDelete returns whether a volume should have its backing volume
 // deleted, i.e. whether a Delete is "desired"
 func (ctrl *ProvisionController) shouldDelete(volume *v1.PersistentVolume) bool {
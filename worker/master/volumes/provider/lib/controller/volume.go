@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // Provisioner is an interface that creates templates for PersistentVolumes
@@ -56,6 +57,15 @@ type BlockProvisioner interface {
 	SupportsBlock() bool
 }
 
+// Expander is an optional interface implemented by provisioners that can
+// expand the storage asset backing a bound volume in place.
+type Expander interface {
+	Provisioner
+	// Expand grows the storage asset backing the PV to the new size. The
+	// controller updates the capacity of the PV and its claim afterwards.
+	Expand(volume *v1.PersistentVolume, newSize resource.Quantity) error
+}
+
 // IgnoredError is the value for Delete to return to indicate that the call has
 // been ignored and no action taken. In case multiple provisioners are serving
 // the same storage class, provisioners may ignore PVs they are not responsible
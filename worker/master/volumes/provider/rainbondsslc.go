@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -41,6 +42,7 @@ import (
 	httputil "github.com/goodrain/rainbond/util/http"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -61,6 +63,7 @@ func NewRainbondsslcProvisioner(kubecli kubernetes.Interface, store store.Storer
 }
 
 var _ controller.Provisioner = &rainbondsslcProvisioner{}
+var _ controller.Expander = &rainbondsslcProvisioner{}
 
 //selectNode select an appropriate node with the largest resource surplus
 func (p *rainbondsslcProvisioner) selectNode(ctx context.Context, nodeOS, ignore string) (*v1.Node, error) {
@@ -148,12 +151,10 @@ func (p *rainbondsslcProvisioner) createPath(options controller.VolumeOptions) (
 			"volume_name": volume.VolumeName,
 			"pod_name":    getPodNameByPVCName(options.PVC.Name),
 		}
-		var ip string
-		for _, address := range options.SelectedNode.Status.Addresses {
-			if address.Type == v1.NodeInternalIP {
-				ip = address.Address
-			}
+		if capacity, ok := options.PVC.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+			reqoptions["capacity"] = strconv.FormatInt(capacity.Value(), 10)
 		}
+		ip := getNodeInternalIP(options.SelectedNode)
 		if ip == "" {
 			return "", fmt.Errorf("do not find node ip")
 		}
@@ -268,6 +269,75 @@ func (p *rainbondsslcProvisioner) Delete(volume *v1.PersistentVolume) error {
 	return nil
 }
 
+// Expand expands the storage asset represented by the given PV. The quota of the
+// local volume directory is updated by the node the volume is located on.
+func (p *rainbondsslcProvisioner) Expand(volume *v1.PersistentVolume, newSize resource.Quantity) error {
+	logrus.Infof("[rainbondsslcProvisioner] expand volume %s to %s", volume.Name, newSize.String())
+	if volume.Spec.HostPath == nil {
+		return nil
+	}
+	hostname := getVolumeHostname(volume)
+	if hostname == "" {
+		return fmt.Errorf("can not find the node of volume %s", volume.Name)
+	}
+	nodes, err := p.kubecli.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{
+		LabelSelector: "kubernetes.io/hostname=" + hostname,
+	})
+	if err != nil {
+		return err
+	}
+	if len(nodes.Items) == 0 {
+		return fmt.Errorf("node %s of volume %s not found", hostname, volume.Name)
+	}
+	ip := getNodeInternalIP(&nodes.Items[0])
+	if ip == "" {
+		return fmt.Errorf("do not find node ip")
+	}
+
+	body := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(body).Encode(map[string]interface{}{"path": volume.Spec.HostPath.Path, "capacity": newSize.Value()}); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s:6100/v2/localvolumes/quota", ip), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request node api failure %s", err.Error())
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return fmt.Errorf("set quota of local volume failure code:%d", res.StatusCode)
+	}
+	return nil
+}
+
 func (p *rainbondsslcProvisioner) Name() string {
 	return p.name
 }
+
+func getNodeInternalIP(node *v1.Node) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == v1.NodeInternalIP {
+			return address.Address
+		}
+	}
+	return ""
+}
+
+// getVolumeHostname returns the hostname of the node that the local volume is located on
+func getVolumeHostname(volume *v1.PersistentVolume) string {
+	if volume.Spec.NodeAffinity == nil || volume.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range volume.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == "kubernetes.io/hostname" && expr.Operator == v1.NodeSelectorOpIn && len(expr.Values) > 0 {
+				return expr.Values[0]
+			}
+		}
+	}
+	return ""
+}
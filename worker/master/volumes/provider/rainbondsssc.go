@@ -27,12 +27,17 @@ import (
 
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/quota"
 	"github.com/goodrain/rainbond/worker/master/volumes/provider/lib/controller"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// annHostPath the directory of the share volume on the share storage
+const annHostPath = "rainbond.io/host-path"
+
 type rainbondssscProvisioner struct {
 	// The directory to create PV-backing directories in
 	pvDir string
@@ -52,6 +57,7 @@ func NewRainbondssscProvisioner() controller.Provisioner {
 }
 
 var _ controller.Provisioner = &rainbondssscProvisioner{}
+var _ controller.Expander = &rainbondssscProvisioner{}
 
 // Provision creates a storage asset and returns a PV object representing it.
 func (p *rainbondssscProvisioner) Provision(options controller.VolumeOptions) (*v1.PersistentVolume, error) {
@@ -86,6 +92,11 @@ func (p *rainbondssscProvisioner) Provision(options controller.VolumeOptions) (*
 	if err := util.CheckAndCreateDirByMode(hostpath, 0777); err != nil {
 		return nil, err
 	}
+	if capacity, ok := options.PVC.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+		if err := setShareVolumeQuota(hostpath, capacity.Value()); err != nil {
+			logrus.Warningf("set quota of share volume %s: %v", hostpath, err)
+		}
+	}
 	// new volume path
 	persistentVolumeSource, err := updatePathForPersistentVolumeSource(&options.PersistentVolumeSource, hostpath)
 	if err != nil {
@@ -94,8 +105,9 @@ func (p *rainbondssscProvisioner) Provision(options controller.VolumeOptions) (*
 
 	pv := &v1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   options.PVName,
-			Labels: options.PVC.Labels,
+			Name:        options.PVName,
+			Labels:      options.PVC.Labels,
+			Annotations: map[string]string{annHostPath: hostpath},
 		},
 		Spec: v1.PersistentVolumeSpec{
 			PersistentVolumeReclaimPolicy: options.PersistentVolumeReclaimPolicy,
@@ -118,10 +130,35 @@ func (p *rainbondssscProvisioner) Delete(volume *v1.PersistentVolume) error {
 	return nil
 }
 
+// Expand expands the storage asset represented by the given PV. The quota of the
+// share volume directory is updated if the share storage supports project quota.
+func (p *rainbondssscProvisioner) Expand(volume *v1.PersistentVolume, newSize resource.Quantity) error {
+	logrus.Infof("[rainbondssscProvisioner] expand volume %s to %s", volume.Name, newSize.String())
+	hostpath := volume.Annotations[annHostPath]
+	if hostpath == "" {
+		// created by previous versions, without quota
+		return nil
+	}
+	return setShareVolumeQuota(hostpath, newSize.Value())
+}
+
 func (p *rainbondssscProvisioner) Name() string {
 	return p.name
 }
 
+// setShareVolumeQuota limits the share volume directory to the capacity,
+// share storages without project quota, such as nfs, are not limited.
+func setShareVolumeQuota(hostpath string, capacity int64) error {
+	q, err := quota.New(hostpath)
+	if err != nil {
+		if err == quota.ErrNotSupported {
+			return nil
+		}
+		return err
+	}
+	return q.SetQuota(hostpath, capacity)
+}
+
 func getPodNameByPVCName(pvcName string) string {
 	pvcNames := strings.SplitN(pvcName, "-", 2)
 	if len(pvcNames) == 2 {
@@ -19,36 +19,63 @@
 package statistical
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"path"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/quota"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
+//EventTypeVolumeUsageHigh the usage of the volume reaches a threshold of its capacity
+var EventTypeVolumeUsageHigh = "VolumeUsageHigh"
+
+type diskUsage struct {
+	Key        string
+	VolumeType string
+	Value      float64
+}
+
 //DiskCache 磁盘异步统计
 type DiskCache struct {
-	cache []struct {
-		Key   string
-		Value float64
-	}
-	dbmanager db.Manager
-	ctx       context.Context
-	cancel    context.CancelFunc
+	cache      []diskUsage
+	dbmanager  db.Manager
+	kubeClient kubernetes.Interface
+	// percentages of the volume capacity at which warnings are emitted
+	thresholds []int
+	// the highest threshold reached by each volume directory
+	levels map[string]int
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 //CreatDiskCache 创建
-func CreatDiskCache(ctx context.Context) *DiskCache {
+func CreatDiskCache(ctx context.Context, kubeClient kubernetes.Interface, thresholds []int) *DiskCache {
 	cctx, cancel := context.WithCancel(ctx)
+	thresholds = append([]int(nil), thresholds...)
+	sort.Ints(thresholds)
 	return &DiskCache{
-		dbmanager: db.GetManager(),
-		ctx:       cctx,
-		cancel:    cancel,
+		dbmanager:  db.GetManager(),
+		kubeClient: kubeClient,
+		thresholds: thresholds,
+		levels:     make(map[string]int),
+		ctx:        cctx,
+		cancel:     cancel,
 	}
 }
 
@@ -76,16 +103,13 @@ func (d *DiskCache) Stop() {
 func (d *DiskCache) setcache() {
 	logrus.Info("start get all service disk size")
 	start := time.Now()
-	var diskcache []struct {
-		Key   string
-		Value float64
-	}
+	var diskcache []diskUsage
 	services, err := d.dbmanager.TenantServiceDao().GetAllServicesID()
 	if err != nil {
 		logrus.Errorln("Error get tenant service when select db :", err)
 		return
 	}
-	_, err = d.dbmanager.TenantServiceVolumeDao().GetAllVolumes()
+	volumes, err := d.dbmanager.TenantServiceVolumeDao().GetAllVolumes()
 	if err != nil {
 		logrus.Errorln("Error get tenant service volume when select db :", err)
 		return
@@ -96,23 +120,231 @@ func (d *DiskCache) setcache() {
 	}
 	var cache = make(map[string]*model.TenantServices)
 	for _, service := range services {
-		//service nfs volume
-		size := util.GetDirSize(fmt.Sprintf("%s/tenant/%s/service/%s", sharePath, service.TenantID, service.ServiceID))
-		if size != 0 {
-			diskcache = append(diskcache, struct {
-				Key   string
-				Value float64
-			}{
-				Key:   service.ServiceID + "_" + service.AppID + "_" + service.TenantID,
-				Value: size,
-			})
-		}
 		cache[service.ServiceID] = service
 	}
+
+	var report map[string]quota.Usage
+	if q, err := quota.New(sharePath); err == nil {
+		if report, err = q.Report(); err != nil {
+			logrus.Warningf("get quota report of share volumes: %v", err)
+		}
+	}
+	if report == nil {
+		for _, service := range services {
+			//service nfs volume
+			size := util.GetDirSize(fmt.Sprintf("%s/tenant/%s/service/%s", sharePath, service.TenantID, service.ServiceID))
+			if size != 0 {
+				diskcache = append(diskcache, diskUsage{
+					Key:        service.ServiceID + "_" + service.AppID + "_" + service.TenantID,
+					VolumeType: string(model.ShareFileVolumeType),
+					Value:      size,
+				})
+			}
+		}
+	} else {
+		// the usage of the volume directories comes from the quota accounting
+		for _, volume := range volumes {
+			service, ok := cache[volume.ServiceID]
+			if !ok || volume.HostPath == "" || !strings.HasPrefix(volume.HostPath, sharePath+"/") {
+				continue
+			}
+			var size float64
+			for _, dir := range volumeDirs(report, volume.HostPath) {
+				if usage, ok := quota.GetUsage(report, dir); ok {
+					size += float64(usage.Used / 1024)
+					d.checkThreshold(service, volume.VolumeName, dir, usage)
+				} else {
+					size += util.GetDirSize(dir)
+				}
+			}
+			if size != 0 {
+				diskcache = append(diskcache, diskUsage{
+					Key:        service.ServiceID + "_" + service.AppID + "_" + service.TenantID,
+					VolumeType: string(model.ShareFileVolumeType),
+					Value:      size,
+				})
+			}
+		}
+	}
+	diskcache = append(diskcache, d.localVolumeUsages(cache)...)
 	d.cache = diskcache
 	logrus.Infof("end get all service disk size,time consum %2.f s", time.Since(start).Seconds())
 }
 
+//volumeDirs returns the directories of the share volume, the stateful components
+//have a directory per pod under the volume host path.
+func volumeDirs(report map[string]quota.Usage, hostPath string) []string {
+	if _, ok := quota.GetUsage(report, hostPath); ok {
+		return []string{hostPath}
+	}
+	entries, err := ioutil.ReadDir(hostPath)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		dir := path.Join(hostPath, entry.Name())
+		if _, ok := quota.GetUsage(report, dir); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return []string{hostPath}
+	}
+	return dirs
+}
+
+//localVolumeUsages gets the usage of the local volumes from the nodes they are located on
+func (d *DiskCache) localVolumeUsages(services map[string]*model.TenantServices) []diskUsage {
+	if d.kubeClient == nil {
+		return nil
+	}
+	pvs, err := d.kubeClient.CoreV1().PersistentVolumes().List(d.ctx, metav1.ListOptions{})
+	if err != nil {
+		logrus.Warningf("list persistent volumes: %v", err)
+		return nil
+	}
+	nodes, err := d.kubeClient.CoreV1().Nodes().List(d.ctx, metav1.ListOptions{})
+	if err != nil {
+		logrus.Warningf("list nodes: %v", err)
+		return nil
+	}
+	nodeIPs := make(map[string]string)
+	for _, node := range nodes.Items {
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				nodeIPs[node.Labels["kubernetes.io/hostname"]] = address.Address
+			}
+		}
+	}
+
+	volumesByNode := make(map[string][]*corev1.PersistentVolume)
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Spec.StorageClassName != v1.RainbondStatefuleLocalStorageClass || pv.Spec.HostPath == nil {
+			continue
+		}
+		if ip := nodeIPs[localVolumeHostname(pv)]; ip != "" {
+			volumesByNode[ip] = append(volumesByNode[ip], pv)
+		}
+	}
+
+	var usages []diskUsage
+	for ip, pvs := range volumesByNode {
+		var paths []string
+		for _, pv := range pvs {
+			paths = append(paths, pv.Spec.HostPath.Path)
+		}
+		report, err := getLocalVolumeUsage(ip, paths)
+		if err != nil {
+			logrus.Warningf("get local volume usage from node %s: %v", ip, err)
+			continue
+		}
+		for _, pv := range pvs {
+			service, ok := services[pv.Labels["service_id"]]
+			if !ok {
+				continue
+			}
+			usage, ok := report[pv.Spec.HostPath.Path]
+			if !ok {
+				continue
+			}
+			volumeName := pv.Name
+			if pv.Spec.ClaimRef != nil {
+				volumeName = pv.Spec.ClaimRef.Name
+			}
+			d.checkThreshold(service, volumeName, ip+":"+pv.Spec.HostPath.Path, usage)
+			if usage.Used != 0 {
+				usages = append(usages, diskUsage{
+					Key:        service.ServiceID + "_" + service.AppID + "_" + service.TenantID,
+					VolumeType: string(model.LocalVolumeType),
+					Value:      float64(usage.Used / 1024),
+				})
+			}
+		}
+	}
+	return usages
+}
+
+func getLocalVolumeUsage(ip string, paths []string) (map[string]quota.Usage, error) {
+	body := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(body).Encode(map[string][]string{"paths": paths}); err != nil {
+		return nil, err
+	}
+	res, err := http.Post(fmt.Sprintf("http://%s:6100/v2/localvolumes/usage", ip), "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("status code %d", res.StatusCode)
+	}
+	var result struct {
+		Bean map[string]quota.Usage `json:"bean"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Bean, nil
+}
+
+func localVolumeHostname(pv *corev1.PersistentVolume) string {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == "kubernetes.io/hostname" && len(expr.Values) > 0 {
+				return expr.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+//checkThreshold emits a warning and a service event when the usage of the volume
+//directory reaches a higher threshold than it did last time.
+func (d *DiskCache) checkThreshold(service *model.TenantServices, volumeName, dir string, usage quota.Usage) {
+	level := reachedThreshold(d.thresholds, usage)
+	last := d.levels[dir]
+	d.levels[dir] = level
+	if level <= last {
+		return
+	}
+	msg := fmt.Sprintf("volume %s has used %d%% of its capacity, used: %dMB, capacity: %dMB",
+		volumeName, usage.Used*100/usage.Limit, usage.Used/1024/1024, usage.Limit/1024/1024)
+	logrus.Warningf("service %s: %s", service.ServiceID, msg)
+	event := &model.ServiceEvent{
+		EventID:     util.NewUUID(),
+		TenantID:    service.TenantID,
+		ServiceID:   service.ServiceID,
+		Target:      model.TargetTypeService,
+		TargetID:    service.ServiceID,
+		UserName:    model.UsernameSystem,
+		OptType:     EventTypeVolumeUsageHigh,
+		Status:      model.EventStatusFailure.String(),
+		FinalStatus: model.EventFinalStatusComplete.String(),
+		Message:     msg,
+	}
+	if err := d.dbmanager.ServiceEventDao().AddModel(event); err != nil {
+		logrus.Warningf("create volume usage event for service %s: %v", service.ServiceID, err)
+	}
+}
+
+//reachedThreshold returns the highest threshold the usage reaches, 0 if none.
+func reachedThreshold(thresholds []int, usage quota.Usage) int {
+	if usage.Limit <= 0 {
+		return 0
+	}
+	var level int
+	for _, threshold := range thresholds {
+		if usage.Used*100 >= int64(threshold)*usage.Limit {
+			level = threshold
+		}
+	}
+	return level
+}
+
 //Get 获取磁盘统计结果
 func (d *DiskCache) Get() map[string]float64 {
 	newcache := make(map[string]float64)
@@ -122,6 +354,18 @@ func (d *DiskCache) Get() map[string]float64 {
 	return newcache
 }
 
+//GetByVolumeType 获取按存储类型区分的磁盘统计结果
+func (d *DiskCache) GetByVolumeType() map[string]map[string]float64 {
+	newcache := make(map[string]map[string]float64)
+	for _, v := range d.cache {
+		if newcache[v.VolumeType] == nil {
+			newcache[v.VolumeType] = make(map[string]float64)
+		}
+		newcache[v.VolumeType][v.Key] += v.Value
+	}
+	return newcache
+}
+
 //GetTenantDisk GetTenantDisk
 func (d *DiskCache) GetTenantDisk(tenantID string) float64 {
 	var value float64
@@ -0,0 +1,83 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package statistical
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/goodrain/rainbond/util/quota"
+)
+
+func TestReachedThreshold(t *testing.T) {
+	thresholds := []int{80, 95}
+	tests := []struct {
+		name  string
+		usage quota.Usage
+		want  int
+	}{
+		{name: "below", usage: quota.Usage{Used: 50, Limit: 100}, want: 0},
+		{name: "warning", usage: quota.Usage{Used: 80, Limit: 100}, want: 80},
+		{name: "critical", usage: quota.Usage{Used: 99, Limit: 100}, want: 95},
+		{name: "over limit", usage: quota.Usage{Used: 120, Limit: 100}, want: 95},
+		{name: "unlimited", usage: quota.Usage{Used: 120}, want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := reachedThreshold(thresholds, tc.usage); got != tc.want {
+				t.Errorf("want %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestVolumeDirs(t *testing.T) {
+	hostPath, err := ioutil.TempDir("", "volume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(hostPath)
+	for _, pod := range []string{"app-0", "app-1"} {
+		if err := os.Mkdir(path.Join(hostPath, pod), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// stateless volume, the host path is the quota project
+	report := map[string]quota.Usage{hostPath: {Used: 1024}}
+	if dirs := volumeDirs(report, hostPath); len(dirs) != 1 || dirs[0] != hostPath {
+		t.Errorf("unexpected dirs: %v", dirs)
+	}
+
+	// stateful volume, a quota project per pod
+	report = map[string]quota.Usage{
+		path.Join(hostPath, "app-0"): {Used: 1024},
+		path.Join(hostPath, "app-1"): {Used: 1024},
+	}
+	if dirs := volumeDirs(report, hostPath); len(dirs) != 2 {
+		t.Errorf("unexpected dirs: %v", dirs)
+	}
+
+	// volume without quota
+	if dirs := volumeDirs(map[string]quota.Usage{}, hostPath); len(dirs) != 1 || dirs[0] != hostPath {
+		t.Errorf("unexpected dirs: %v", dirs)
+	}
+}